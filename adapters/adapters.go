@@ -21,6 +21,9 @@ import (
 // It calls the provided Provider.GenSync() method, processes any tool calls using Message.DoToolCalls(),
 // and continues the conversation in a loop until the LLM's response has no more tool calls.
 //
+// Set GenOptionTools.Concurrency to run multiple tool calls requested in a single reply concurrently. Set
+// GenOptionTools.Allow and GenOptionTools.Deny to restrict which tools may be executed.
+//
 // Warning: If opts.Force == ToolCallRequired, it will be mutated to ToolCallAny after the first
 // tool call.
 //
@@ -40,7 +43,6 @@ func GenSyncWithToolCallLoop(ctx context.Context, p genai.Provider, msgs genai.M
 	if toolsOpts == nil {
 		return out, usage, errors.New("no tools found")
 	}
-	tools := toolsOpts.Tools
 	for {
 		res, err := p.GenSync(ctx, workMsgs, opts...)
 		usage.InputTokens += res.Usage.InputTokens
@@ -56,7 +58,7 @@ func GenSyncWithToolCallLoop(ctx context.Context, p genai.Provider, msgs genai.M
 		if !slices.ContainsFunc(res.Replies, func(r genai.Reply) bool { return !r.ToolCall.IsZero() }) {
 			return out, usage, nil
 		}
-		tr, err := res.DoToolCalls(ctx, tools)
+		tr, err := doToolCalls(ctx, &res.Message, toolsOpts)
 		if err != nil {
 			return out, usage, err
 		}
@@ -80,6 +82,9 @@ func GenSyncWithToolCallLoop(ctx context.Context, p genai.Provider, msgs genai.M
 // all the messages including the original ones, the LLM's responses, and the tool call result
 // messages.
 //
+// Set GenOptionTools.Concurrency to run multiple tool calls requested in a single reply concurrently. Set
+// GenOptionTools.Allow and GenOptionTools.Deny to restrict which tools may be executed.
+//
 // Warning: If opts.Force == ToolCallRequired, it will be mutated to ToolCallAny after the first
 // tool call.
 //
@@ -102,7 +107,6 @@ func GenStreamWithToolCallLoop(ctx context.Context, p genai.Provider, msgs genai
 			finalErr = errors.New("no tools found")
 			return
 		}
-		tools := toolsOpts.Tools
 		for {
 			fragments, finish := p.GenStream(ctx, workMsgs, opts...)
 			send := true
@@ -130,7 +134,7 @@ func GenStreamWithToolCallLoop(ctx context.Context, p genai.Provider, msgs genai
 			if !slices.ContainsFunc(res.Replies, func(r genai.Reply) bool { return !r.ToolCall.IsZero() }) {
 				return
 			}
-			tr, err := res.DoToolCalls(ctx, tools)
+			tr, err := doToolCalls(ctx, &res.Message, toolsOpts)
 			if err != nil {
 				finalErr = err
 				return
@@ -152,6 +156,21 @@ func GenStreamWithToolCallLoop(ctx context.Context, p genai.Provider, msgs genai
 	return fnFragments, fnFinish
 }
 
+// doToolCalls runs the tool calls sequentially or concurrently, depending on opts.Concurrency.
+//
+// A call to a tool not allowed by opts.Allow and opts.Deny is returned to the LLM as an error.
+func doToolCalls(ctx context.Context, m *genai.Message, opts *genai.GenOptionTools) (genai.Message, error) {
+	tools := opts.AllowedTools()
+	switch opts.Concurrency {
+	case 0, 1:
+		return m.DoToolCalls(ctx, tools)
+	case -1:
+		return m.DoToolCallsConcurrently(ctx, tools, 0)
+	default:
+		return m.DoToolCallsConcurrently(ctx, tools, opts.Concurrency)
+	}
+}
+
 //
 
 // ProviderUsage wraps a Provider and accumulates Usage values
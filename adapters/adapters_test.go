@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"slices"
 	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -79,6 +80,96 @@ func TestGenSyncWithToolCallLoop(t *testing.T) {
 	}
 }
 
+func TestGenSyncWithToolCallLoop_concurrency(t *testing.T) {
+	provider := &mockProviderGenSync{
+		responses: []genai.Result{
+			{
+				Message: genai.Message{
+					Replies: []genai.Reply{
+						{ToolCall: genai.ToolCall{ID: "1", Name: "wait", Arguments: `{}`}},
+						{ToolCall: genai.ToolCall{ID: "2", Name: "wait", Arguments: `{}`}},
+					},
+				},
+			},
+			{Message: genai.Message{Replies: []genai.Reply{{Text: "Done."}}}},
+		},
+	}
+	// Both calls must be running at the same time for any of them to return.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	type empty struct{}
+	opts := &genai.GenOptionTools{
+		Tools: []genai.ToolDef{
+			{
+				Name:        "wait",
+				Description: "Waits for the other call",
+				Callback: func(ctx context.Context, _ *empty) (string, error) {
+					wg.Done()
+					wg.Wait()
+					return "ok", nil
+				},
+			},
+		},
+		Concurrency: -1,
+	}
+	respMsgs, _, err := adapters.GenSyncWithToolCallLoop(t.Context(), provider, genai.Messages{genai.NewTextMessage("Go")}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []genai.ToolCallResult{{ID: "1", Name: "wait", Result: "ok"}, {ID: "2", Name: "wait", Result: "ok"}}
+	if diff := cmp.Diff(want, respMsgs[1].ToolCallResults); diff != "" {
+		t.Fatalf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestGenSyncWithToolCallLoop_deny(t *testing.T) {
+	provider := &mockProviderGenSync{
+		responses: []genai.Result{
+			{
+				Message: genai.Message{
+					Replies: []genai.Reply{
+						{ToolCall: genai.ToolCall{ID: "1", Name: "read", Arguments: `{}`}},
+						{ToolCall: genai.ToolCall{ID: "2", Name: "delete", Arguments: `{}`}},
+					},
+				},
+			},
+			{Message: genai.Message{Replies: []genai.Reply{{Text: "Done"}}}},
+		},
+	}
+	type empty struct{}
+	opts := &genai.GenOptionTools{
+		Tools: []genai.ToolDef{
+			{
+				Name:        "read",
+				Description: "Reads",
+				Callback: func(ctx context.Context, _ *empty) (string, error) {
+					return "content", nil
+				},
+			},
+			{
+				Name:        "delete",
+				Description: "Deletes",
+				Callback: func(ctx context.Context, _ *empty) (string, error) {
+					t.Error("unexpected call")
+					return "", nil
+				},
+			},
+		},
+		Deny: []string{"delete"},
+	}
+	respMsgs, _, err := adapters.GenSyncWithToolCallLoop(t.Context(), provider, genai.Messages{genai.NewTextMessage("Go")}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []genai.ToolCallResult{
+		{ID: "1", Name: "read", Result: "content"},
+		{ID: "2", Name: "delete", Result: `error: tool "delete" is not available`},
+	}
+	if diff := cmp.Diff(want, respMsgs[1].ToolCallResults); diff != "" {
+		t.Fatalf("(-want +got):\n%s", diff)
+	}
+}
+
 func TestGenStreamWithToolCallLoop(t *testing.T) {
 	provider := &mockProviderGenStream{
 		streamResponses: []streamResponse{
@@ -0,0 +1,126 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Polling helpers for asynchronous and batch generation.
+
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maruel/genai"
+)
+
+// DefaultPollInterval is the delay between polls used by WaitForJob and GenBatch when pollInterval is 0.
+const DefaultPollInterval = 30 * time.Second
+
+// WaitForJob polls the job returned by Provider.GenAsync every pollInterval until it completed.
+//
+// It returns the result of the first PokeResult call that is not pending, or its error.
+func WaitForJob(ctx context.Context, p genai.Provider, job genai.Job, pollInterval time.Duration) (genai.Result, error) {
+	for {
+		res, err := p.PokeResult(ctx, job)
+		if err != nil || res.Usage.FinishReason != genai.Pending {
+			return res, err
+		}
+		if err := sleep(ctx, pollInterval); err != nil {
+			return genai.Result{}, err
+		}
+	}
+}
+
+// GenBatch submits all the requests asynchronously, polls every pollInterval until they all completed and
+// returns the results in the same order as msgs.
+//
+// When p, or one of the providers it wraps, implements genai.ProviderGenAsyncBatch, all the requests are
+// submitted as a single batch job. Otherwise each request is submitted with Provider.GenAsync.
+//
+// Requests that failed have a zero Result and their errors are joined in the returned error. If a
+// submission fails, the jobs already submitted are not waited for.
+func GenBatch(ctx context.Context, p genai.Provider, msgs []genai.Messages, pollInterval time.Duration, opts ...genai.GenOption) ([]genai.Result, error) {
+	if b := asGenAsyncBatch(p); b != nil {
+		job, err := b.GenAsyncBatch(ctx, msgs, opts...)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			res, err := b.PokeBatchResults(ctx, job)
+			if err != nil || res != nil {
+				return res, err
+			}
+			if err := sleep(ctx, pollInterval); err != nil {
+				return nil, err
+			}
+		}
+	}
+	jobs := make([]genai.Job, len(msgs))
+	for i := range msgs {
+		var err error
+		if jobs[i], err = p.GenAsync(ctx, msgs[i], opts...); err != nil {
+			return nil, fmt.Errorf("request %d: %w", i, err)
+		}
+	}
+	out := make([]genai.Result, len(msgs))
+	errs := make([]error, len(msgs))
+	done := make([]bool, len(msgs))
+	for remaining := len(msgs); ; {
+		for i := range jobs {
+			if done[i] {
+				continue
+			}
+			res, err := p.PokeResult(ctx, jobs[i])
+			if err == nil && res.Usage.FinishReason == genai.Pending {
+				continue
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				errs[i] = fmt.Errorf("request %d: %w", i, err)
+			} else {
+				out[i] = res
+			}
+			done[i] = true
+			remaining--
+		}
+		if remaining == 0 {
+			return out, errors.Join(errs...)
+		}
+		if err := sleep(ctx, pollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func asGenAsyncBatch(p genai.Provider) genai.ProviderGenAsyncBatch {
+	for p != nil {
+		if b, ok := p.(genai.ProviderGenAsyncBatch); ok {
+			return b
+		}
+		u, ok := p.(genai.ProviderUnwrap)
+		if !ok {
+			return nil
+		}
+		p = u.Unwrap()
+	}
+	return nil
+}
+
+// sleep waits for d, DefaultPollInterval when 0, or until the context is canceled.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d == 0 {
+		d = DefaultPollInterval
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
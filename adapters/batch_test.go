@@ -0,0 +1,135 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the asynchronous and batch polling helpers.
+
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+)
+
+func TestWaitForJob(t *testing.T) {
+	m := &mockProviderAsync{pending: 2}
+	job, err := m.GenAsync(t.Context(), genai.Messages{genai.NewTextMessage("a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := adapters.WaitForJob(t.Context(), m, job, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.String(); got != "reply a" {
+		t.Fatalf("got %q", got)
+	}
+	if m.polls != 3 {
+		t.Fatalf("got %d polls, want 3", m.polls)
+	}
+	t.Run("canceled", func(t *testing.T) {
+		m := &mockProviderAsync{pending: 1000}
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		if _, err := adapters.WaitForJob(ctx, m, "0", time.Hour); !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestGenBatch(t *testing.T) {
+	msgs := []genai.Messages{
+		{genai.NewTextMessage("a")},
+		{genai.NewTextMessage("fail")},
+		{genai.NewTextMessage("c")},
+	}
+	check := func(t *testing.T, res []genai.Result, err error) {
+		if err == nil || err.Error() != "request 1: failed" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(res) != 3 || res[0].String() != "reply a" || !res[1].IsZero() || res[2].String() != "reply c" {
+			t.Fatalf("unexpected results: %v", res)
+		}
+	}
+	t.Run("GenAsync", func(t *testing.T) {
+		m := &mockProviderAsync{pending: 1}
+		res, err := adapters.GenBatch(t.Context(), m, msgs, time.Millisecond)
+		check(t, res, err)
+	})
+	t.Run("GenAsyncBatch", func(t *testing.T) {
+		m := &mockProviderBatch{pending: 2}
+		res, err := adapters.GenBatch(t.Context(), &adapters.ProviderUsage{Provider: m}, msgs, time.Millisecond)
+		check(t, res, err)
+		if m.polls != 3 {
+			t.Fatalf("got %d polls, want 3", m.polls)
+		}
+	})
+}
+
+// mockProviderAsync replies "reply <text>" to each GenAsync request after pending polls.
+type mockProviderAsync struct {
+	mockProviderGenSync
+	pending int
+	polls   int
+	jobs    []genai.Messages
+}
+
+func (m *mockProviderAsync) GenAsync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Job, error) {
+	m.jobs = append(m.jobs, msgs)
+	return genai.Job(strconv.Itoa(len(m.jobs) - 1)), nil
+}
+
+func (m *mockProviderAsync) PokeResult(ctx context.Context, job genai.Job) (genai.Result, error) {
+	m.polls++
+	if m.polls <= m.pending {
+		return genai.Result{Usage: genai.Usage{FinishReason: genai.Pending}}, nil
+	}
+	i, _ := strconv.Atoi(string(job))
+	return mockReply(m.jobs[i])
+}
+
+// mockProviderBatch implements genai.ProviderGenAsyncBatch.
+type mockProviderBatch struct {
+	mockProviderGenSync
+	pending int
+	polls   int
+	msgs    []genai.Messages
+}
+
+func (m *mockProviderBatch) GenAsyncBatch(ctx context.Context, msgs []genai.Messages, opts ...genai.GenOption) (genai.Job, error) {
+	m.msgs = msgs
+	return "batch", nil
+}
+
+func (m *mockProviderBatch) PokeBatchResults(ctx context.Context, job genai.Job) ([]genai.Result, error) {
+	m.polls++
+	if m.polls <= m.pending {
+		return nil, nil
+	}
+	out := make([]genai.Result, len(m.msgs))
+	var errs []error
+	for i := range m.msgs {
+		var err error
+		if out[i], err = mockReply(m.msgs[i]); err != nil {
+			errs = append(errs, errors.New("request "+strconv.Itoa(i)+": "+err.Error()))
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+func mockReply(msgs genai.Messages) (genai.Result, error) {
+	in := msgs[0].String()
+	if in == "fail" {
+		return genai.Result{}, errors.New("failed")
+	}
+	return genai.Result{
+		Message: genai.Message{Replies: []genai.Reply{{Text: "reply " + in}}},
+		Usage:   genai.Usage{FinishReason: genai.FinishedStop},
+	}, nil
+}
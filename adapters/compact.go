@@ -0,0 +1,178 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Conversation history compaction.
+
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/maruel/genai"
+)
+
+// DefaultCompactPrompt is the instruction sent to the summarizer by CompactHistory when
+// CompactOptions.Prompt is empty.
+const DefaultCompactPrompt = "Summarize the following conversation between a user and an assistant. Keep the facts, decisions, " +
+	"open tasks, file names, identifiers and tool results needed to continue the conversation. Reply only with " +
+	"the summary."
+
+// CompactOptions configures CompactHistory.
+type CompactOptions struct {
+	// Summarizer is the provider used to summarize the older turns. Use a cheap model. Defaults to the
+	// provider passed to CompactHistory.
+	Summarizer genai.Provider
+	// ContextTokens is the model's context window. When 0, it is retrieved with ListModels.
+	ContextTokens int64
+	// Threshold is the fraction of ContextTokens above which the history is compacted. Defaults to 0.8.
+	Threshold float64
+	// KeepRecent is the number of most recent messages kept verbatim. Defaults to 4. More may be kept to avoid
+	// separating a tool call from its result.
+	KeepRecent int
+	// Prompt is the instruction sent to the summarizer. Defaults to DefaultCompactPrompt.
+	Prompt string
+	// CountTokens estimates the number of tokens in msgs. Defaults to one token per 4 bytes of text. Pass a
+	// more accurate estimate, like the last Result.Usage, when known.
+	CountTokens func(msgs genai.Messages) int64
+
+	_ struct{}
+}
+
+// Validate implements genai.Validatable.
+func (o *CompactOptions) Validate() error {
+	if o.ContextTokens < 0 {
+		return errors.New("field ContextTokens: must be positive")
+	}
+	if o.Threshold < 0 || o.Threshold > 1 {
+		return errors.New("field Threshold: must be between 0 and 1")
+	}
+	if o.KeepRecent < 0 {
+		return errors.New("field KeepRecent: must be positive")
+	}
+	return nil
+}
+
+// CompactHistory returns msgs with the older turns summarized when the history exceeds the token threshold.
+//
+// The summary replaces the older messages as a single user message prepended to the kept ones, or merged
+// in the first kept message when it is from the user, so the turns keep alternating. A tool call and its
+// result are never separated. msgs is not modified; it is returned as-is when under the threshold.
+//
+// Long-running agents should call it before each request and keep the returned history.
+func CompactHistory(ctx context.Context, p genai.Provider, msgs genai.Messages, opts *CompactOptions) (genai.Messages, error) {
+	var o CompactOptions
+	if opts != nil {
+		if err := opts.Validate(); err != nil {
+			return msgs, err
+		}
+		o = *opts
+	}
+	if o.Summarizer == nil {
+		o.Summarizer = p
+	}
+	if o.Threshold == 0 {
+		o.Threshold = 0.8
+	}
+	if o.KeepRecent == 0 {
+		o.KeepRecent = 4
+	}
+	if o.Prompt == "" {
+		o.Prompt = DefaultCompactPrompt
+	}
+	if o.CountTokens == nil {
+		o.CountTokens = estimateTokens
+	}
+	if o.ContextTokens == 0 {
+		n, err := modelContext(ctx, p)
+		if err != nil {
+			return msgs, fmt.Errorf("failed to get the model context: %w", err)
+		}
+		o.ContextTokens = n
+	}
+	if float64(o.CountTokens(msgs)) <= o.Threshold*float64(o.ContextTokens) {
+		return msgs, nil
+	}
+
+	// Find where the kept messages start, without separating tool call results from their call.
+	split := len(msgs) - o.KeepRecent
+	for split > 0 && len(msgs[split].ToolCallResults) != 0 {
+		split--
+	}
+	if split <= 0 {
+		return msgs, nil
+	}
+	res, err := o.Summarizer.GenSync(ctx, genai.Messages{genai.NewTextMessage(o.Prompt + "\n\n" + transcript(msgs[:split]))})
+	if err != nil {
+		return msgs, fmt.Errorf("failed to summarize the history: %w", err)
+	}
+	summary := "Summary of the earlier conversation:\n" + strings.TrimSpace(res.String())
+	kept := msgs[split:]
+	out := make(genai.Messages, 0, len(kept)+1)
+	if kept[0].Role() == "user" {
+		first := kept[0]
+		first.Requests = append([]genai.Request{{Text: summary}}, first.Requests...)
+		out = append(out, first)
+		kept = kept[1:]
+	} else {
+		out = append(out, genai.NewTextMessage(summary))
+	}
+	return append(out, kept...), nil
+}
+
+//
+
+// modelContext returns the context window of p's model.
+func modelContext(ctx context.Context, p genai.Provider) (int64, error) {
+	mdls, err := p.ListModels(ctx)
+	if err != nil {
+		return 0, err
+	}
+	id := p.ModelID()
+	for _, m := range mdls {
+		if m.GetID() == id {
+			if n := m.Context(); n > 0 {
+				return n, nil
+			}
+			break
+		}
+	}
+	return 0, fmt.Errorf("unknown context size for model %q; set CompactOptions.ContextTokens", id)
+}
+
+// estimateTokens estimates one token per 4 bytes of text.
+func estimateTokens(msgs genai.Messages) int64 {
+	return int64(len(transcript(msgs)) / 4)
+}
+
+// transcript renders msgs as plain text for the summarizer.
+func transcript(msgs genai.Messages) string {
+	var b strings.Builder
+	for i := range msgs {
+		m := &msgs[i]
+		for _, r := range m.Requests {
+			if r.Text != "" {
+				fmt.Fprintf(&b, "user: %s\n", r.Text)
+			} else if !r.Doc.IsZero() {
+				fmt.Fprintf(&b, "user: [document %s]\n", r.Doc.GetFilename())
+			}
+		}
+		for _, r := range m.Replies {
+			switch {
+			case r.Text != "":
+				fmt.Fprintf(&b, "assistant: %s\n", r.Text)
+			case r.ToolCall.Name != "":
+				fmt.Fprintf(&b, "assistant called tool %s(%s)\n", r.ToolCall.Name, r.ToolCall.Arguments)
+			case !r.Doc.IsZero():
+				fmt.Fprintf(&b, "assistant: [document %s]\n", r.Doc.GetFilename())
+			}
+		}
+		for _, r := range m.ToolCallResults {
+			fmt.Fprintf(&b, "tool %s returned: %s\n", r.Name, r.Result)
+		}
+	}
+	return b.String()
+}
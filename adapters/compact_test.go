@@ -0,0 +1,109 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the history compaction.
+
+package adapters_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+)
+
+func TestCompactHistory(t *testing.T) {
+	long := strings.Repeat("a", 400)
+	toolCall := genai.Message{Replies: []genai.Reply{{ToolCall: genai.ToolCall{ID: "1", Name: "ls", Arguments: "{}"}}}}
+	toolResult := genai.Message{ToolCallResults: []genai.ToolCallResult{{ID: "1", Name: "ls", Result: "a.txt"}}}
+	assistant := genai.Message{Replies: []genai.Reply{{Text: long}}}
+	tests := []struct {
+		name  string
+		msgs  genai.Messages
+		keep  int
+		want  []string
+		calls int
+	}{
+		{
+			name: "under_threshold",
+			msgs: genai.Messages{genai.NewTextMessage("hi")},
+			want: []string{"user"},
+		},
+		{
+			name:  "user_first",
+			msgs:  genai.Messages{genai.NewTextMessage(long), assistant, genai.NewTextMessage(long), assistant, genai.NewTextMessage("next")},
+			keep:  1,
+			want:  []string{"user"},
+			calls: 1,
+		},
+		{
+			name:  "assistant_first",
+			msgs:  genai.Messages{genai.NewTextMessage(long), assistant, genai.NewTextMessage(long), assistant},
+			keep:  1,
+			want:  []string{"user", "assistant"},
+			calls: 1,
+		},
+		{
+			name:  "tool_pair",
+			msgs:  genai.Messages{genai.NewTextMessage(long), assistant, genai.NewTextMessage(long), toolCall, toolResult},
+			keep:  1,
+			want:  []string{"user", "assistant", "computer"},
+			calls: 1,
+		},
+		{
+			name: "nothing_to_summarize",
+			msgs: genai.Messages{toolCall, toolResult},
+			keep: 1,
+			want: []string{"assistant", "computer"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &mockProviderModels{models: []genai.Model{&mockModel{id: "llm-sota", ctx: 200}}}
+			summarizer := &mockProviderGenSync{responses: []genai.Result{{Message: genai.Message{Replies: []genai.Reply{{Text: "they talked"}}}}}}
+			orig := append(genai.Messages(nil), tc.msgs...)
+			got, err := adapters.CompactHistory(t.Context(), p, tc.msgs, &adapters.CompactOptions{Summarizer: summarizer, KeepRecent: tc.keep})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var roles []string
+			for i := range got {
+				roles = append(roles, got[i].Role())
+			}
+			if strings.Join(roles, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("got roles %v, want %v", roles, tc.want)
+			}
+			if calls := 1 - len(summarizer.responses); calls != tc.calls {
+				t.Fatalf("got %d summarizer calls, want %d", calls, tc.calls)
+			}
+			if tc.calls != 0 {
+				if s := got[0].Requests[0].Text; s != "Summary of the earlier conversation:\nthey talked" {
+					t.Fatalf("unexpected summary %q", s)
+				}
+				if s := summarizer.msgs[0].String(); !strings.HasPrefix(s, adapters.DefaultCompactPrompt) {
+					t.Fatalf("unexpected prompt %q", s)
+				}
+			}
+			for i := range orig {
+				if len(orig[i].Requests) != len(tc.msgs[i].Requests) {
+					t.Fatal("caller's messages were modified")
+				}
+			}
+		})
+	}
+	t.Run("unknown_context", func(t *testing.T) {
+		p := &mockProviderModels{models: []genai.Model{&mockModel{id: "llm-sota"}}}
+		_, err := adapters.CompactHistory(t.Context(), p, genai.Messages{genai.NewTextMessage("hi")}, nil)
+		if want := "failed to get the model context: unknown context size for model \"llm-sota\"; set CompactOptions.ContextTokens"; err == nil || err.Error() != want {
+			t.Fatalf("error mismatch\nwant %q\ngot  %v", want, err)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := adapters.CompactHistory(t.Context(), &mockProviderModels{}, nil, &adapters.CompactOptions{Threshold: 2})
+		if want := "field Threshold: must be between 0 and 1"; err == nil || err.Error() != want {
+			t.Fatalf("error mismatch\nwant %q\ngot  %v", want, err)
+		}
+	})
+}
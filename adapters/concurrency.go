@@ -0,0 +1,207 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Adaptive concurrency controller for bulk workloads.
+
+package adapters
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"sync"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/httpjson"
+)
+
+// AdaptiveConcurrency is an AIMD (additive increase, multiplicative decrease) concurrency controller.
+//
+// The concurrency limit grows by one for every window of successful requests, like TCP congestion avoidance,
+// until the provider returns a rate limit error (HTTP 429). Then the limit is multiplied by Backoff. Rate
+// limit errors for requests started before the last decrease are ignored so a burst of concurrent 429s only
+// reduces the limit once.
+//
+// The zero value is not usable, use NewAdaptiveConcurrency.
+type AdaptiveConcurrency struct {
+	// Min is the minimum concurrency. It is at least 1.
+	Min int
+	// Max is the maximum concurrency. 0 means no limit.
+	Max int
+	// Backoff is the multiplicative decrease factor, between 0 and 1 exclusive.
+	Backoff float64
+
+	mu       sync.Mutex
+	limit    float64
+	inflight int
+	gen      int
+	changed  chan struct{}
+}
+
+// NewAdaptiveConcurrency returns an AdaptiveConcurrency starting at initial concurrency and never going above
+// max. A max of 0 means no limit.
+//
+// initial must be at least 1. It is capped to max.
+func NewAdaptiveConcurrency(initial, max int) (*AdaptiveConcurrency, error) {
+	if initial < 1 {
+		return nil, errors.New("initial must be at least 1")
+	}
+	if max < 0 {
+		return nil, errors.New("max must be 0 or positive")
+	}
+	if max > 0 {
+		initial = min(initial, max)
+	}
+	return &AdaptiveConcurrency{
+		Min:     1,
+		Max:     max,
+		Backoff: 0.5,
+		limit:   float64(initial),
+		changed: make(chan struct{}),
+	}, nil
+}
+
+// Validate implements genai.Validatable.
+func (a *AdaptiveConcurrency) Validate() error {
+	if a.changed == nil {
+		return errors.New("use NewAdaptiveConcurrency")
+	}
+	if a.Min < 1 {
+		return errors.New("field Min: must be at least 1")
+	}
+	if a.Max != 0 && a.Max < a.Min {
+		return errors.New("field Max: must be 0 or at least Min")
+	}
+	if a.Backoff <= 0 || a.Backoff >= 1 {
+		return errors.New("field Backoff: must be between 0 and 1 exclusive")
+	}
+	return nil
+}
+
+// Limit returns the current concurrency limit.
+func (a *AdaptiveConcurrency) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.limitLocked()
+}
+
+// Acquire blocks until a slot is available or the context is canceled.
+//
+// The returned function must be called exactly once with the result of the request to release the slot and
+// adjust the limit.
+func (a *AdaptiveConcurrency) Acquire(ctx context.Context) (func(err error), error) {
+	for {
+		a.mu.Lock()
+		if a.inflight < a.limitLocked() {
+			a.inflight++
+			gen := a.gen
+			a.mu.Unlock()
+			once := sync.Once{}
+			return func(err error) { once.Do(func() { a.release(gen, err) }) }, nil
+		}
+		ch := a.changed
+		a.mu.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (a *AdaptiveConcurrency) release(gen int, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inflight--
+	if IsRateLimited(err) {
+		if gen == a.gen {
+			a.gen++
+			a.limit *= a.Backoff
+			if a.limit < float64(a.Min) {
+				a.limit = float64(a.Min)
+			}
+		}
+	} else if err == nil {
+		// Grows by 1 after limit successful requests.
+		a.limit += 1 / a.limit
+		if a.Max > 0 && a.limit > float64(a.Max) {
+			a.limit = float64(a.Max)
+		}
+	}
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
+
+func (a *AdaptiveConcurrency) limitLocked() int {
+	l := max(int(a.limit), a.Min)
+	if a.Max > 0 {
+		l = min(l, a.Max)
+	}
+	return l
+}
+
+// IsRateLimited returns true if the error is an HTTP 429 Too Many Requests.
+func IsRateLimited(err error) bool {
+	if herr, ok := errors.AsType[*httpjson.Error](err); ok {
+		return herr.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+//
+
+// ProviderAdaptiveConcurrency wraps a Provider and limits the number of concurrent GenSync and GenStream
+// calls with an AdaptiveConcurrency controller.
+//
+// It is meant to be shared by many goroutines sending requests to the same provider.
+type ProviderAdaptiveConcurrency struct {
+	genai.Provider
+
+	// Limiter is the concurrency controller to use.
+	Limiter *AdaptiveConcurrency
+
+	_ struct{}
+}
+
+// GenSync implements genai.Provider.
+func (c *ProviderAdaptiveConcurrency) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	release, err := c.Limiter.Acquire(ctx)
+	if err != nil {
+		return genai.Result{}, err
+	}
+	res, err := c.Provider.GenSync(ctx, msgs, opts...)
+	release(err)
+	return res, err
+}
+
+// GenStream implements genai.Provider.
+func (c *ProviderAdaptiveConcurrency) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	var res genai.Result
+	var finalErr error
+	fnFragments := func(yield func(genai.Reply) bool) {
+		release, err := c.Limiter.Acquire(ctx)
+		if err != nil {
+			finalErr = err
+			return
+		}
+		fragments, finish := c.Provider.GenStream(ctx, msgs, opts...)
+		for f := range fragments {
+			if !yield(f) {
+				break
+			}
+		}
+		res, finalErr = finish()
+		release(finalErr)
+	}
+	fnFinish := func() (genai.Result, error) {
+		return res, finalErr
+	}
+	return fnFragments, fnFinish
+}
+
+// Unwrap implements genai.ProviderUnwrap.
+func (c *ProviderAdaptiveConcurrency) Unwrap() genai.Provider {
+	return c.Provider
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the adaptive concurrency controller.
+
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+	"github.com/maruel/httpjson"
+)
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	errRate := &httpjson.Error{StatusCode: 429}
+	t.Run("increase", func(t *testing.T) {
+		a := newAdaptiveConcurrency(t, 2, 3)
+		if err := a.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		// A window of successes grows the limit by about 1.
+		for range 3 {
+			release, err := a.Acquire(t.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+			release(nil)
+		}
+		if l := a.Limit(); l != 3 {
+			t.Fatalf("got limit %d, want 3", l)
+		}
+		// Capped at Max.
+		for range 10 {
+			release, err := a.Acquire(t.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+			release(nil)
+		}
+		if l := a.Limit(); l != 3 {
+			t.Fatalf("got limit %d, want 3", l)
+		}
+	})
+	t.Run("decrease_once", func(t *testing.T) {
+		a := newAdaptiveConcurrency(t, 8, 0)
+		var releases []func(error)
+		for range 8 {
+			release, err := a.Acquire(t.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+			releases = append(releases, release)
+		}
+		// All in-flight requests get rate limited; the limit is only halved once.
+		for _, release := range releases {
+			release(errRate)
+		}
+		if l := a.Limit(); l != 4 {
+			t.Fatalf("got limit %d, want 4", l)
+		}
+		release, err := a.Acquire(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release(errRate)
+		if l := a.Limit(); l != 2 {
+			t.Fatalf("got limit %d, want 2", l)
+		}
+	})
+	t.Run("min", func(t *testing.T) {
+		a := newAdaptiveConcurrency(t, 1, 0)
+		release, err := a.Acquire(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release(errRate)
+		if l := a.Limit(); l != 1 {
+			t.Fatalf("got limit %d, want 1", l)
+		}
+	})
+	t.Run("blocks", func(t *testing.T) {
+		a := newAdaptiveConcurrency(t, 1, 0)
+		release, err := a.Acquire(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := a.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		done := make(chan error)
+		go func() {
+			r, err := a.Acquire(t.Context())
+			if err == nil {
+				r(nil)
+			}
+			done <- err
+		}()
+		release(nil)
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("initial", func(t *testing.T) {
+		// initial is capped to max.
+		if l := newAdaptiveConcurrency(t, 8, 2).Limit(); l != 2 {
+			t.Fatalf("got limit %d, want 2", l)
+		}
+		for _, v := range [][2]int{{0, 0}, {-1, 4}, {1, -1}} {
+			if _, err := adapters.NewAdaptiveConcurrency(v[0], v[1]); err == nil {
+				t.Fatalf("%v: expected error", v)
+			}
+		}
+	})
+	t.Run("Validate", func(t *testing.T) {
+		if err := (&adapters.AdaptiveConcurrency{}).Validate(); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func newAdaptiveConcurrency(t testing.TB, initial, max int) *adapters.AdaptiveConcurrency {
+	a, err := adapters.NewAdaptiveConcurrency(initial, max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestProviderAdaptiveConcurrency(t *testing.T) {
+	provider := &mockProviderGenSync{responses: []genai.Result{{}}, err: &httpjson.Error{StatusCode: 429}}
+	wrapped := &adapters.ProviderAdaptiveConcurrency{Provider: provider, Limiter: newAdaptiveConcurrency(t, 4, 0)}
+	if _, err := wrapped.GenSync(t.Context(), nil); !adapters.IsRateLimited(err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l := wrapped.Limiter.Limit(); l != 2 {
+		t.Fatalf("got limit %d, want 2", l)
+	}
+	if wrapped.Unwrap() != provider {
+		t.Fatal("expected unwrapped provider to be the original provider")
+	}
+}
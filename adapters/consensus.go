@@ -0,0 +1,250 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Self-consistency: generate multiple candidates and select one.
+
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+)
+
+// DefaultJudgePrompt is the instruction sent to ConsensusOptions.Judge when JudgePrompt is empty.
+const DefaultJudgePrompt = "You are given a conversation and candidate replies for the last assistant turn. Select the most " +
+	"correct, complete and helpful candidate. Reply only with its number."
+
+// ConsensusOptions configures GenConsensus.
+type ConsensusOptions struct {
+	// N is the number of candidates to generate concurrently. Defaults to 3.
+	N int
+	// Vary returns the options for the candidate i. By default, when a genai.GenOptionSeed is passed, it is
+	// incremented by i so the candidates differ. Use it to vary the temperature or the system prompt.
+	Vary func(i int, opts []genai.GenOption) []genai.GenOption
+	// Judge, when set, selects the best candidate instead of a majority vote. Use a strong model.
+	Judge genai.Provider
+	// JudgePrompt is the instruction sent to Judge. Defaults to DefaultJudgePrompt.
+	JudgePrompt string
+
+	_ struct{}
+}
+
+// Validate implements genai.Validatable.
+func (o *ConsensusOptions) Validate() error {
+	if o.N < 0 {
+		return errors.New("field N: must be positive")
+	}
+	return nil
+}
+
+// ConsensusResult is the result of GenConsensus.
+type ConsensusResult struct {
+	// Selected is the index of the selected candidate.
+	Selected int
+	// Candidates are all the results, in generation order. Failed candidates are zero.
+	Candidates []genai.Result
+	// Errs are the errors of each candidate.
+	Errs []error
+	// Votes is the number of candidates equivalent to each candidate. It is nil when a judge was used.
+	Votes []int
+	// Usage is the sum of the usage of all the candidates and the judge.
+	Usage genai.Usage
+}
+
+// GenConsensus generates N candidates for msgs and selects one, a technique known as self-consistency.
+//
+// Without a judge, the candidates are compared by majority vote. When GenOptionText.DecodeAs or ReplyAsJSON
+// is used, the candidates are decoded and compared as JSON, so formatting differences don't matter. Otherwise
+// the text is compared after trimming spaces. Ties are broken by generation order.
+//
+// It fails only when all the candidates failed or the judge failed.
+func GenConsensus(ctx context.Context, p genai.Provider, msgs genai.Messages, o *ConsensusOptions, opts ...genai.GenOption) (*ConsensusResult, error) {
+	var cfg ConsensusOptions
+	if o != nil {
+		if err := o.Validate(); err != nil {
+			return nil, err
+		}
+		cfg = *o
+	}
+	if cfg.N == 0 {
+		cfg.N = 3
+	}
+	if cfg.Vary == nil {
+		cfg.Vary = varySeed
+	}
+	if cfg.JudgePrompt == "" {
+		cfg.JudgePrompt = DefaultJudgePrompt
+	}
+	out := &ConsensusResult{Candidates: make([]genai.Result, cfg.N), Errs: make([]error, cfg.N)}
+	var wg sync.WaitGroup
+	for i := range cfg.N {
+		wg.Go(func() {
+			out.Candidates[i], out.Errs[i] = p.GenSync(ctx, msgs, cfg.Vary(i, opts)...)
+		})
+	}
+	wg.Wait()
+	var valid []int
+	for i := range out.Candidates {
+		out.Usage.Add(&out.Candidates[i].Usage)
+		if out.Errs[i] == nil {
+			valid = append(valid, i)
+		}
+	}
+	if len(valid) == 0 {
+		return out, fmt.Errorf("all %d candidates failed: %w", cfg.N, errors.Join(out.Errs...))
+	}
+	if cfg.Judge != nil {
+		i, err := judge(ctx, cfg.Judge, cfg.JudgePrompt, msgs, out, valid)
+		if err != nil {
+			return out, err
+		}
+		out.Selected = i
+		return out, nil
+	}
+	out.Votes = vote(out, valid, opts)
+	for _, i := range valid {
+		if out.Votes[i] > out.Votes[out.Selected] || out.Errs[out.Selected] != nil {
+			out.Selected = i
+		}
+	}
+	return out, nil
+}
+
+// ProviderConsensus wraps a Provider and replies with the candidate selected by GenConsensus.
+//
+// The returned Result.Usage is the sum of all the candidates and the judge, since they are all billed.
+type ProviderConsensus struct {
+	genai.Provider
+
+	// Options configures GenConsensus.
+	Options ConsensusOptions
+
+	_ struct{}
+}
+
+// GenSync implements genai.Provider.
+func (c *ProviderConsensus) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	r, err := GenConsensus(ctx, c.Provider, msgs, &c.Options, opts...)
+	if err != nil {
+		return genai.Result{}, err
+	}
+	res := r.Candidates[r.Selected]
+	finish := res.Usage.FinishReason
+	res.Usage = r.Usage
+	res.Usage.FinishReason = finish
+	return res, nil
+}
+
+// GenStream implements genai.Provider.
+//
+// The candidates must all complete before one is selected, so the reply is sent at once.
+func (c *ProviderConsensus) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	return base.SimulateStream(ctx, c, msgs, opts...)
+}
+
+// Unwrap implements genai.ProviderUnwrap.
+func (c *ProviderConsensus) Unwrap() genai.Provider {
+	return c.Provider
+}
+
+//
+
+// varySeed increments the seed by i.
+func varySeed(i int, opts []genai.GenOption) []genai.GenOption {
+	out := make([]genai.GenOption, len(opts))
+	for j, o := range opts {
+		if s, ok := o.(genai.GenOptionSeed); ok {
+			o = s + genai.GenOptionSeed(i)
+		}
+		out[j] = o
+	}
+	return out
+}
+
+// vote returns the number of valid candidates equivalent to each candidate.
+func vote(r *ConsensusResult, valid []int, opts []genai.GenOption) []int {
+	var decodeAs any
+	asJSON := false
+	for _, o := range opts {
+		if t, ok := o.(*genai.GenOptionText); ok {
+			decodeAs = t.DecodeAs
+			asJSON = t.ReplyAsJSON || decodeAs != nil
+		}
+	}
+	keys := make([]string, len(r.Candidates))
+	for _, i := range valid {
+		keys[i] = consensusKey(&r.Candidates[i], decodeAs, asJSON)
+	}
+	votes := make([]int, len(r.Candidates))
+	for _, i := range valid {
+		for _, j := range valid {
+			if keys[i] == keys[j] {
+				votes[i]++
+			}
+		}
+	}
+	return votes
+}
+
+// consensusKey returns the normalized reply used to compare candidates.
+func consensusKey(res *genai.Result, decodeAs any, asJSON bool) string {
+	s := strings.TrimSpace(res.String())
+	if !asJSON {
+		return s
+	}
+	var v any
+	if _, ok := decodeAs.(genai.JSONSchema); !ok && decodeAs != nil {
+		if t := reflect.TypeOf(decodeAs); t.Kind() == reflect.Pointer {
+			v = reflect.New(t.Elem()).Interface()
+		}
+	}
+	if v == nil {
+		v = new(any)
+	}
+	if res.Decode(v) != nil {
+		return s
+	}
+	// encoding/json sorts the map keys so the encoding is canonical.
+	b, err := json.Marshal(v)
+	if err != nil {
+		return s
+	}
+	return string(b)
+}
+
+// judge asks j to select the best of the valid candidates.
+func judge(ctx context.Context, j genai.Provider, prompt string, msgs genai.Messages, r *ConsensusResult, valid []int) (int, error) {
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\nConversation:\n")
+	b.WriteString(transcript(msgs))
+	for n, i := range valid {
+		fmt.Fprintf(&b, "\nCandidate %d:\n%s\n", n+1, strings.TrimSpace(r.Candidates[i].String()))
+	}
+	res, err := j.GenSync(ctx, genai.Messages{genai.NewTextMessage(b.String())})
+	r.Usage.Add(&res.Usage)
+	if err != nil {
+		return 0, fmt.Errorf("judge failed: %w", err)
+	}
+	s := strings.TrimSpace(res.String())
+	end := strings.IndexFunc(s, func(c rune) bool { return c < '0' || c > '9' })
+	if end == -1 {
+		end = len(s)
+	}
+	n, err := strconv.Atoi(s[:end])
+	if err != nil || n < 1 || n > len(valid) {
+		return 0, fmt.Errorf("judge replied with an invalid candidate: %q", s)
+	}
+	return valid[n-1], nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the consensus adapter.
+
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+)
+
+func TestGenConsensus(t *testing.T) {
+	type answer struct {
+		City string `json:"city"`
+		Pop  int    `json:"pop"`
+	}
+	msgs := genai.Messages{genai.NewTextMessage("question")}
+	tests := []struct {
+		name     string
+		replies  []string
+		opts     []genai.GenOption
+		judge    string
+		want     int
+		wantVote []int
+		wantErr  string
+	}{
+		{
+			name:     "majority",
+			replies:  []string{"Paris", " Lyon", "Lyon "},
+			want:     1,
+			wantVote: []int{1, 2, 2},
+		},
+		{
+			name:     "tie",
+			replies:  []string{"a", "b", "c"},
+			want:     0,
+			wantVote: []int{1, 1, 1},
+		},
+		{
+			name:     "decode_as",
+			replies:  []string{`{"city":"Paris","pop":2}`, `{"pop":1, "city":"Lyon"}`, `{"city":"Lyon","pop":1}`},
+			opts:     []genai.GenOption{&genai.GenOptionText{DecodeAs: &answer{}}},
+			want:     1,
+			wantVote: []int{1, 2, 2},
+		},
+		{
+			name:     "reply_as_json",
+			replies:  []string{`{"b":1,"a":2}`, `{"c":3}`, `{ "a": 2, "b": 1 }`},
+			opts:     []genai.GenOption{&genai.GenOptionText{ReplyAsJSON: true}},
+			want:     0,
+			wantVote: []int{2, 1, 2},
+		},
+		{
+			name:     "failed_candidate",
+			replies:  []string{"", "b", "b"},
+			want:     1,
+			wantVote: []int{0, 2, 2},
+		},
+		{
+			name:    "judge",
+			replies: []string{"a", "b", "b"},
+			judge:   "1.",
+			want:    0,
+		},
+		{
+			name:    "judge_skips_failed",
+			replies: []string{"", "b", "c"},
+			judge:   "2",
+			want:    2,
+		},
+		{
+			name:    "judge_invalid",
+			replies: []string{"a", "b", "c"},
+			judge:   "the best",
+			wantErr: `judge replied with an invalid candidate: "the best"`,
+		},
+		{
+			name:    "all_failed",
+			replies: []string{"", "", ""},
+			wantErr: "all 3 candidates failed: failed\nfailed\nfailed",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &mockProviderSeed{replies: tc.replies}
+			o := &adapters.ConsensusOptions{}
+			if tc.judge != "" {
+				o.Judge = &mockProviderSeed{replies: []string{tc.judge}}
+			}
+			got, err := adapters.GenConsensus(t.Context(), p, msgs, o, append(tc.opts, genai.GenOptionSeed(0))...)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("got %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Selected != tc.want {
+				t.Errorf("got candidate %d, want %d", got.Selected, tc.want)
+			}
+			if len(got.Votes) != len(tc.wantVote) {
+				t.Fatalf("got votes %v, want %v", got.Votes, tc.wantVote)
+			}
+			for i := range got.Votes {
+				if got.Votes[i] != tc.wantVote[i] {
+					t.Fatalf("got votes %v, want %v", got.Votes, tc.wantVote)
+				}
+			}
+			if len(got.Candidates) != 3 {
+				t.Fatalf("got %d candidates", len(got.Candidates))
+			}
+		})
+	}
+	t.Run("Validate", func(t *testing.T) {
+		if _, err := adapters.GenConsensus(t.Context(), &mockProviderSeed{}, msgs, &adapters.ConsensusOptions{N: -1}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestProviderConsensus(t *testing.T) {
+	p := &mockProviderSeed{replies: []string{"a", "b", "b"}, usage: 10}
+	c := &adapters.ProviderConsensus{Provider: p}
+	res, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("q")}, genai.GenOptionSeed(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "b" {
+		t.Fatalf("got %q", s)
+	}
+	if res.Usage.OutputTokens != 30 || res.Usage.FinishReason != genai.FinishedStop {
+		t.Fatalf("unexpected usage %+v", res.Usage)
+	}
+	fragments, finish := c.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("q")}, genai.GenOptionSeed(0))
+	for range fragments {
+	}
+	if res, err = finish(); err != nil || res.String() != "b" {
+		t.Fatalf("unexpected %q, %v", res.String(), err)
+	}
+	if c.Unwrap() != p {
+		t.Fatal("expected unwrapped provider to be the original provider")
+	}
+}
+
+// mockProviderSeed replies with replies[seed], or fails when the reply is empty.
+type mockProviderSeed struct {
+	mockProviderGenSync
+	replies []string
+	usage   int64
+}
+
+func (m *mockProviderSeed) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	i := 0
+	for _, o := range opts {
+		if s, ok := o.(genai.GenOptionSeed); ok {
+			i = int(s)
+		}
+	}
+	if m.replies[i] == "" {
+		return genai.Result{}, errors.New("failed")
+	}
+	return genai.Result{
+		Message: genai.Message{Replies: []genai.Reply{{Text: m.replies[i]}}},
+		Usage:   genai.Usage{OutputTokens: m.usage, FinishReason: genai.FinishedStop},
+	}, nil
+}
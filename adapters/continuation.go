@@ -0,0 +1,149 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Automatic continuation of paused or truncated turns.
+
+package adapters
+
+import (
+	"context"
+	"iter"
+	"slices"
+
+	"github.com/maruel/genai"
+)
+
+// DefaultMaxContinuations is the number of continuation requests done by ProviderContinue when
+// MaxContinuations is 0.
+const DefaultMaxContinuations = 3
+
+// ProviderContinue wraps a Provider and transparently issues continuation requests when the model paused its
+// turn, like Anthropic's pause_turn during long running server-side tool calls, and optionally when it ran
+// out of output tokens.
+//
+// The replies of each request are stitched together in a single message and the usage is summed.
+type ProviderContinue struct {
+	genai.Provider
+
+	// MaxContinuations is the maximum number of continuation requests issued for a single call. Defaults to
+	// DefaultMaxContinuations when 0.
+	MaxContinuations int
+	// OnLength also continues when the model hit the output tokens limit (genai.FinishedLength). This is only
+	// done when the last reply is text, as a truncated tool call cannot be resumed.
+	OnLength bool
+	// ContinuePrompt, when set, is sent as a user message to ask the model to continue. Otherwise the partial
+	// reply is sent back as the last message so the model resumes it, which requires the provider to support
+	// assistant prefill.
+	ContinuePrompt string
+
+	_ struct{}
+}
+
+// GenSync implements genai.Provider.
+func (c *ProviderContinue) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	res, err := c.Provider.GenSync(ctx, msgs, opts...)
+	history := msgs
+	last := res.Message
+	for i := 0; i < c.maxContinuations() && err == nil && c.shouldContinue(&res); i++ {
+		history = c.nextMessages(msgs, history, &res.Message, &last)
+		var next genai.Result
+		next, err = c.Provider.GenSync(ctx, history, opts...)
+		last = next.Message
+		stitch(&res, &next)
+	}
+	return res, err
+}
+
+// GenStream implements genai.Provider.
+//
+// Fragments of all the requests are yielded in order, so they can be accumulated as a single reply.
+func (c *ProviderContinue) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	var res genai.Result
+	var finalErr error
+	fnFragments := func(yield func(genai.Reply) bool) {
+		history := msgs
+		for i := 0; ; i++ {
+			fragments, finish := c.Provider.GenStream(ctx, history, opts...)
+			stop := false
+			for f := range fragments {
+				if !stop && !yield(f) {
+					stop = true
+				}
+			}
+			next, err := finish()
+			last := next.Message
+			if i == 0 {
+				res = next
+			} else {
+				stitch(&res, &next)
+			}
+			finalErr = err
+			if stop || err != nil || i >= c.maxContinuations() || !c.shouldContinue(&res) {
+				return
+			}
+			history = c.nextMessages(msgs, history, &res.Message, &last)
+		}
+	}
+	fnFinish := func() (genai.Result, error) {
+		return res, finalErr
+	}
+	return fnFragments, fnFinish
+}
+
+// Unwrap implements genai.ProviderUnwrap.
+func (c *ProviderContinue) Unwrap() genai.Provider {
+	return c.Provider
+}
+
+func (c *ProviderContinue) maxContinuations() int {
+	if c.MaxContinuations == 0 {
+		return DefaultMaxContinuations
+	}
+	return c.MaxContinuations
+}
+
+func (c *ProviderContinue) shouldContinue(res *genai.Result) bool {
+	switch res.Usage.FinishReason {
+	case genai.FinishedPauseTurn:
+		return true
+	case genai.FinishedLength:
+		return c.OnLength && len(res.Replies) != 0 && res.Replies[len(res.Replies)-1].Text != ""
+	default:
+		return false
+	}
+}
+
+// nextMessages returns the messages for the continuation request.
+//
+// acc is the stitched reply so far and last is the reply of the previous request.
+func (c *ProviderContinue) nextMessages(msgs, history genai.Messages, acc, last *genai.Message) genai.Messages {
+	if c.ContinuePrompt == "" {
+		// Prefill: send the partial reply back as-is.
+		return append(msgs[:len(msgs):len(msgs)], genai.Message{Replies: slices.Clone(acc.Replies)})
+	}
+	return append(history[:len(history):len(history)], genai.Message{Replies: slices.Clone(last.Replies)}, genai.NewTextMessage(c.ContinuePrompt))
+}
+
+// stitch appends next to res. Adjacent plain text replies are merged.
+func stitch(res, next *genai.Result) {
+	for i := range next.Replies {
+		r := &next.Replies[i]
+		if n := len(res.Replies); n != 0 && isPlainText(&res.Replies[n-1]) && isPlainText(r) {
+			res.Replies[n-1].Text += r.Text
+			continue
+		}
+		res.Replies = append(res.Replies, *r)
+	}
+	fr := next.Usage.FinishReason
+	res.Usage.Add(&next.Usage)
+	res.Usage.FinishReason = fr
+	if len(next.Usage.Limits) != 0 {
+		res.Usage.Limits = next.Usage.Limits
+	}
+	res.Logprobs = append(res.Logprobs, next.Logprobs...)
+}
+
+func isPlainText(r *genai.Reply) bool {
+	return r.Text != "" && r.Doc.IsZero() && r.Citation.IsZero() && r.ToolCall.IsZero() && len(r.Opaque) == 0
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the continuation adapter.
+
+package adapters_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+)
+
+func TestProviderContinue(t *testing.T) {
+	textResult := func(text string, fr genai.FinishReason) genai.Result {
+		return genai.Result{
+			Message: genai.Message{Replies: []genai.Reply{{Text: text}}},
+			Usage:   genai.Usage{InputTokens: 10, OutputTokens: 5, FinishReason: fr},
+		}
+	}
+	msgs := genai.Messages{genai.NewTextMessage("hi")}
+	t.Run("GenSync", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			p         adapters.ProviderContinue
+			responses []genai.Result
+			want      string
+			wantFR    genai.FinishReason
+			wantMsgs  genai.Messages
+		}{
+			{
+				name:      "pause_turn",
+				responses: []genai.Result{textResult("Hel", genai.FinishedPauseTurn), textResult("lo", genai.FinishedStop)},
+				want:      "Hello",
+				wantFR:    genai.FinishedStop,
+				wantMsgs:  genai.Messages{msgs[0], {Replies: []genai.Reply{{Text: "Hel"}}}},
+			},
+			{
+				name:      "max",
+				p:         adapters.ProviderContinue{MaxContinuations: 1},
+				responses: []genai.Result{textResult("a", genai.FinishedPauseTurn), textResult("b", genai.FinishedPauseTurn)},
+				want:      "ab",
+				wantFR:    genai.FinishedPauseTurn,
+				wantMsgs:  genai.Messages{msgs[0], {Replies: []genai.Reply{{Text: "a"}}}},
+			},
+			{
+				name:      "length_ignored",
+				responses: []genai.Result{textResult("a", genai.FinishedLength)},
+				want:      "a",
+				wantFR:    genai.FinishedLength,
+				wantMsgs:  msgs,
+			},
+			{
+				name:      "length",
+				p:         adapters.ProviderContinue{OnLength: true, ContinuePrompt: "continue"},
+				responses: []genai.Result{textResult("a", genai.FinishedLength), textResult("b", genai.FinishedLength), textResult("c", genai.FinishedStop)},
+				want:      "abc",
+				wantFR:    genai.FinishedStop,
+				wantMsgs: genai.Messages{
+					msgs[0],
+					{Replies: []genai.Reply{{Text: "a"}}},
+					genai.NewTextMessage("continue"),
+					{Replies: []genai.Reply{{Text: "b"}}},
+					genai.NewTextMessage("continue"),
+				},
+			},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				mock := &mockProviderGenSync{responses: tc.responses}
+				c := tc.p
+				c.Provider = mock
+				res, err := c.GenSync(t.Context(), msgs)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(mock.responses) != 0 {
+					t.Fatalf("%d responses not consumed", len(mock.responses))
+				}
+				if got := res.String(); got != tc.want {
+					t.Fatalf("got %q, want %q", got, tc.want)
+				}
+				if res.Usage.FinishReason != tc.wantFR {
+					t.Fatalf("got %q, want %q", res.Usage.FinishReason, tc.wantFR)
+				}
+				if n := int64(len(tc.responses)); res.Usage.InputTokens != 10*n || res.Usage.OutputTokens != 5*n {
+					t.Fatalf("unexpected usage: %v", &res.Usage)
+				}
+				if diff := cmp.Diff(tc.wantMsgs, mock.msgs); diff != "" {
+					t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+				}
+			})
+		}
+	})
+	t.Run("GenStream", func(t *testing.T) {
+		mock := &mockProviderGenStream{streamResponses: []streamResponse{
+			{fragments: []genai.Reply{{Text: "Hel"}}, usage: genai.Usage{FinishReason: genai.FinishedPauseTurn}},
+			{fragments: []genai.Reply{{Text: "lo"}}, usage: genai.Usage{FinishReason: genai.FinishedStop}},
+		}}
+		c := &adapters.ProviderContinue{Provider: mock}
+		fragments, finish := c.GenStream(t.Context(), msgs)
+		var got string
+		for f := range fragments {
+			got += f.Text
+		}
+		res, err := finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "Hello" || res.String() != "Hello" {
+			t.Fatalf("got %q and %q", got, res.String())
+		}
+		if res.Usage.FinishReason != genai.FinishedStop {
+			t.Fatalf("unexpected finish reason %q", res.Usage.FinishReason)
+		}
+		if c.Unwrap() != mock {
+			t.Fatal("expected unwrapped provider to be the original provider")
+		}
+	})
+}
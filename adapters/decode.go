@@ -0,0 +1,197 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Incremental JSON decoding of streamed replies.
+
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"iter"
+
+	"github.com/maruel/genai"
+)
+
+// GenStreamDecode runs GenStream and incrementally decodes the streamed JSON reply into partially populated
+// snapshots of T.
+//
+// It is meant to be used with GenOptionText.DecodeAs or ReplyAsJSON so UIs can render structured data as it
+// arrives. A new snapshot is yielded every time the parsable part of the JSON grows. String values being
+// streamed are included truncated, other incomplete values are omitted.
+//
+// The last snapshot is not guaranteed to be complete, use Result.Decode() on the result returned by finish to
+// get the final value.
+func GenStreamDecode[T any](ctx context.Context, p genai.Provider, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[T], func() (genai.Result, error)) {
+	fragments, finish := p.GenStream(ctx, msgs, opts...)
+	fnSnapshots := func(yield func(T) bool) {
+		for v := range DecodePartialJSON[T](fragments) {
+			if !yield(v) {
+				break
+			}
+		}
+	}
+	return fnSnapshots, finish
+}
+
+// DecodePartialJSON converts a stream of reply fragments containing JSON text into a stream of partially
+// populated snapshots of T.
+//
+// The fragments are always fully consumed so the stream's finish function can be called afterward.
+func DecodePartialJSON[T any](fragments iter.Seq[genai.Reply]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		p := partialJSON{}
+		var last []byte
+		send := true
+		for f := range fragments {
+			if !send || f.Text == "" {
+				continue
+			}
+			p.write(f.Text)
+			b := p.snapshot()
+			if b == nil || bytes.Equal(b, last) {
+				continue
+			}
+			var v T
+			if err := json.Unmarshal(b, &v); err != nil {
+				continue
+			}
+			last = b
+			if !yield(v) {
+				send = false
+			}
+		}
+	}
+}
+
+// partialJSON is a streaming JSON scanner that keeps track of the last position where the document can be
+// truncated and closed to form a valid JSON document.
+type partialJSON struct {
+	buf      []byte
+	stack    []byte // Open containers: '{' or '['.
+	started  bool
+	inString bool
+	isKey    bool // The current string is an object key.
+	escape   int  // -1 right after a backslash, otherwise the number of hex digits left in a \u escape.
+	inLit    bool // In a number or a literal (true, false, null).
+	wantKey  bool // The next string in the current object is a key.
+	safe     int  // Length of buf that can be closed with safeStk.
+	safeStk  []byte
+	done     bool
+}
+
+func (p *partialJSON) write(s string) {
+	for i := 0; i < len(s) && !p.done; i++ {
+		p.add(s[i])
+	}
+}
+
+func (p *partialJSON) add(c byte) {
+	if !p.started {
+		// Skip any preamble, e.g. a markdown code fence.
+		if c != '{' && c != '[' {
+			return
+		}
+		p.started = true
+	}
+	p.buf = append(p.buf, c)
+	if p.inString {
+		switch {
+		case p.escape == -1:
+			if c == 'u' {
+				p.escape = 4
+			} else {
+				p.escape = 0
+			}
+		case p.escape > 0:
+			p.escape--
+		case c == '\\':
+			p.escape = -1
+		case c == '"':
+			p.inString = false
+			if p.isKey {
+				p.isKey = false
+			} else {
+				p.markSafe(len(p.buf))
+			}
+		}
+		return
+	}
+	if p.inLit {
+		switch c {
+		case ',', '}', ']', ' ', '\t', '\n', '\r':
+			p.inLit = false
+			p.markSafe(len(p.buf) - 1)
+		default:
+			return
+		}
+	}
+	switch c {
+	case '{':
+		p.stack = append(p.stack, '{')
+		p.wantKey = true
+		p.markSafe(len(p.buf))
+	case '[':
+		p.stack = append(p.stack, '[')
+		p.wantKey = false
+		p.markSafe(len(p.buf))
+	case '}', ']':
+		if len(p.stack) != 0 {
+			p.stack = p.stack[:len(p.stack)-1]
+		}
+		p.wantKey = false
+		p.markSafe(len(p.buf))
+		if len(p.stack) == 0 {
+			p.done = true
+		}
+	case '"':
+		p.inString = true
+		p.isKey = p.wantKey && len(p.stack) != 0 && p.stack[len(p.stack)-1] == '{'
+	case ',':
+		p.wantKey = len(p.stack) != 0 && p.stack[len(p.stack)-1] == '{'
+	case ':':
+		p.wantKey = false
+	case ' ', '\t', '\n', '\r':
+	default:
+		p.inLit = true
+	}
+}
+
+func (p *partialJSON) markSafe(n int) {
+	p.safe = n
+	p.safeStk = append(p.safeStk[:0], p.stack...)
+}
+
+// snapshot returns a valid JSON document from the data received so far, or nil if there is nothing to decode.
+func (p *partialJSON) snapshot() []byte {
+	if !p.started {
+		return nil
+	}
+	var out []byte
+	stk := p.safeStk
+	if p.inString && !p.isKey {
+		// Include the partial string value, minus any incomplete escape sequence.
+		end := len(p.buf)
+		if p.escape != 0 {
+			end = bytes.LastIndexByte(p.buf, '\\')
+		}
+		out = append(out, p.buf[:end]...)
+		out = append(out, '"')
+		stk = p.stack
+	} else {
+		out = append(out, p.buf[:p.safe]...)
+	}
+	for i := len(stk) - 1; i >= 0; i-- {
+		if stk[i] == '{' {
+			out = append(out, '}')
+		} else {
+			out = append(out, ']')
+		}
+	}
+	if !json.Valid(out) {
+		return nil
+	}
+	return out
+}
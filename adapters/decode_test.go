@@ -0,0 +1,106 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the incremental JSON decoder.
+
+package adapters_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+)
+
+func TestDecodePartialJSON(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	}
+	type order struct {
+		Customer string `json:"customer"`
+		Items    []item `json:"items"`
+		Paid     bool   `json:"paid"`
+	}
+	tests := []struct {
+		name      string
+		fragments []string
+		want      []order
+	}{
+		{
+			name:      "strings",
+			fragments: []string{`{"cust`, `omer": "Ma`, `rc", "pa`, `id": tr`, `ue}`},
+			want: []order{
+				{},
+				{Customer: "Ma"},
+				{Customer: "Marc"},
+				{Customer: "Marc", Paid: true},
+			},
+		},
+		{
+			name:      "arrays",
+			fragments: []string{"```json\n", `{"items": [{"name": "ap`, `ple", "price": 1.`, `5}, {"na`, `me": "pear"`, `}]}`, "\n```"},
+			want: []order{
+				{Items: []item{{Name: "ap"}}},
+				{Items: []item{{Name: "apple"}}},
+				{Items: []item{{Name: "apple", Price: 1.5}, {}}},
+				{Items: []item{{Name: "apple", Price: 1.5}, {Name: "pear"}}},
+			},
+		},
+		{
+			name:      "escape",
+			fragments: []string{`{"customer": "a\`, `"b\u00`, `e9"}`},
+			want: []order{
+				{Customer: "a"},
+				{Customer: `a"b`},
+				{Customer: `a"bé`},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fragments := func(yield func(genai.Reply) bool) {
+				for _, f := range tc.fragments {
+					if !yield(genai.Reply{Text: f}) {
+						return
+					}
+				}
+			}
+			got := slices.Collect(adapters.DecodePartialJSON[order](fragments))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected snapshots (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenStreamDecode(t *testing.T) {
+	type answer struct {
+		Number int `json:"number"`
+	}
+	provider := &mockProviderGenStream{
+		streamResponses: []streamResponse{
+			{fragments: []genai.Reply{{Text: `{"num`}, {Text: `ber": 4`}, {Text: `2}`}}},
+		},
+	}
+	snapshots, finish := adapters.GenStreamDecode[answer](t.Context(), provider, genai.Messages{genai.NewTextMessage("hi")})
+	got := slices.Collect(snapshots)
+	res, err := finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]answer{{}, {Number: 42}}, got); diff != "" {
+		t.Fatalf("unexpected snapshots (-want +got):\n%s", diff)
+	}
+	var final answer
+	if err := res.Decode(&final); err != nil {
+		t.Fatal(err)
+	}
+	if final.Number != 42 {
+		t.Fatalf("unexpected final value: %v", final)
+	}
+}
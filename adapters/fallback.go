@@ -0,0 +1,127 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Fallback adapter to fail over between providers.
+
+package adapters
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+)
+
+// Fallback returns a ProviderFallback that tries each provider in order.
+//
+// The first provider is the preferred one, e.g. a cheap provider, and the following ones are used as
+// fallbacks, e.g. a SOTA provider.
+func Fallback(providers []genai.Provider) *ProviderFallback {
+	f := &ProviderFallback{Providers: providers}
+	if len(providers) != 0 {
+		f.Provider = providers[0]
+	}
+	return f
+}
+
+// ProviderFallback sends GenSync and GenStream requests to the first provider and transparently fails over
+// to the next one when the request fails.
+//
+// The embedded Provider is the primary provider, it is used for all the other methods like Name() and
+// ModelID().
+type ProviderFallback struct {
+	genai.Provider
+
+	// Providers is the ordered list of providers to try.
+	Providers []genai.Provider
+	// ShouldFallback decides if the next provider should be tried given the result of the current one. If nil,
+	// DefaultShouldFallback is used.
+	ShouldFallback func(res *genai.Result, err error) bool
+
+	_ struct{}
+}
+
+// GenSync implements genai.Provider.
+func (c *ProviderFallback) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	if len(c.Providers) == 0 {
+		return genai.Result{}, errors.New("no provider to fallback to")
+	}
+	var res genai.Result
+	var err error
+	for i, p := range c.Providers {
+		res, err = p.GenSync(ctx, msgs, opts...)
+		if i == len(c.Providers)-1 || ctx.Err() != nil || !c.shouldFallback(&res, err) {
+			break
+		}
+	}
+	return res, err
+}
+
+// GenStream implements genai.Provider.
+//
+// It only fails over when the current provider failed before yielding any fragment, since fragments cannot be
+// retracted once sent.
+func (c *ProviderFallback) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	var res genai.Result
+	var finalErr error
+	fnFragments := func(yield func(genai.Reply) bool) {
+		if len(c.Providers) == 0 {
+			finalErr = errors.New("no provider to fallback to")
+			return
+		}
+		for i, p := range c.Providers {
+			fragments, finish := p.GenStream(ctx, msgs, opts...)
+			sent := false
+			stop := false
+			for f := range fragments {
+				if stop {
+					continue
+				}
+				sent = true
+				if !yield(f) {
+					stop = true
+				}
+			}
+			res, finalErr = finish()
+			if stop || sent || i == len(c.Providers)-1 || ctx.Err() != nil || !c.shouldFallback(&res, finalErr) {
+				return
+			}
+		}
+	}
+	fnFinish := func() (genai.Result, error) {
+		return res, finalErr
+	}
+	return fnFragments, fnFinish
+}
+
+// Unwrap implements genai.ProviderUnwrap.
+func (c *ProviderFallback) Unwrap() genai.Provider {
+	return c.Provider
+}
+
+func (c *ProviderFallback) shouldFallback(res *genai.Result, err error) bool {
+	if c.ShouldFallback != nil {
+		return c.ShouldFallback(res, err)
+	}
+	return DefaultShouldFallback(res, err)
+}
+
+// DefaultShouldFallback returns true on errors, including API errors and rate limits, and on content filter
+// finishes.
+//
+// ErrNotSupported is not considered a failure since the result is still valid. A canceled context never
+// falls back.
+func DefaultShouldFallback(res *genai.Result, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		if _, ok := errors.AsType[*base.ErrNotSupported](err); !ok {
+			return true
+		}
+	}
+	return res != nil && res.Usage.FinishReason == genai.FinishedContentFilter
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the fallback adapter.
+
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/httpjson"
+)
+
+func TestProviderFallback(t *testing.T) {
+	t.Run("GenSync", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			first   genai.Result
+			err     error
+			want    string
+			wantErr bool
+		}{
+			{
+				name:  "success",
+				first: genai.Result{Message: genai.Message{Replies: []genai.Reply{{Text: "first"}}}},
+				want:  "first",
+			},
+			{
+				name: "rate_limit",
+				err:  &httpjson.Error{StatusCode: 429},
+				want: "second",
+			},
+			{
+				name:  "content_filter",
+				first: genai.Result{Usage: genai.Usage{FinishReason: genai.FinishedContentFilter}},
+				want:  "second",
+			},
+			{
+				name:    "not_supported",
+				first:   genai.Result{Message: genai.Message{Replies: []genai.Reply{{Text: "first"}}}},
+				err:     &base.ErrNotSupported{Options: []string{"GenOptionSeed"}},
+				want:    "first",
+				wantErr: true,
+			},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				p1 := &mockProviderGenSync{responses: []genai.Result{tc.first}, err: tc.err}
+				p2 := &mockProviderGenSync{responses: []genai.Result{{Message: genai.Message{Replies: []genai.Reply{{Text: "second"}}}}}}
+				f := adapters.Fallback([]genai.Provider{p1, p2})
+				res, err := f.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")})
+				if (err != nil) != tc.wantErr {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got := res.String(); got != tc.want {
+					t.Fatalf("got %q, want %q", got, tc.want)
+				}
+			})
+		}
+	})
+	t.Run("GenSync_last_error", func(t *testing.T) {
+		p1 := &mockProviderGenSync{responses: []genai.Result{{}}, err: errors.New("first")}
+		p2 := &mockProviderGenSync{responses: []genai.Result{{}}, err: errors.New("second")}
+		f := adapters.Fallback([]genai.Provider{p1, p2})
+		if _, err := f.GenSync(t.Context(), nil); err == nil || err.Error() != "second" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("GenSync_canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		p1 := &mockProviderGenSync{responses: []genai.Result{{}}, err: context.Canceled}
+		p2 := &mockProviderGenSync{responses: []genai.Result{{}}}
+		f := adapters.Fallback([]genai.Provider{p1, p2})
+		if _, err := f.GenSync(ctx, nil); !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(p2.responses) != 1 {
+			t.Fatal("second provider should not have been called")
+		}
+	})
+	t.Run("GenStream", func(t *testing.T) {
+		p1 := &mockProviderGenStream{err: errors.New("unavailable")}
+		p2 := &mockProviderGenStream{streamResponses: []streamResponse{{fragments: []genai.Reply{{Text: "second"}}}}}
+		f := adapters.Fallback([]genai.Provider{p1, p2})
+		fragments, finish := f.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("hi")})
+		got := slices.Collect(fragments)
+		res, err := finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].Text != "second" || res.String() != "second" {
+			t.Fatalf("unexpected result: %v %v", got, res)
+		}
+	})
+	t.Run("GenStream_no_fallback_after_fragment", func(t *testing.T) {
+		p1 := &mockProviderGenStream{streamResponses: []streamResponse{{
+			fragments: []genai.Reply{{Text: "first"}},
+			usage:     genai.Usage{FinishReason: genai.FinishedContentFilter},
+		}}}
+		p2 := &mockProviderGenStream{streamResponses: []streamResponse{{fragments: []genai.Reply{{Text: "second"}}}}}
+		f := adapters.Fallback([]genai.Provider{p1, p2})
+		fragments, finish := f.GenStream(t.Context(), nil)
+		for range fragments {
+		}
+		res, err := finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.String() != "first" || p2.callIndex != 0 {
+			t.Fatalf("unexpected result: %v", res)
+		}
+	})
+	t.Run("Unwrap", func(t *testing.T) {
+		p1 := &mockProviderGenSync{}
+		f := adapters.Fallback([]genai.Provider{p1, &mockProviderGenSync{}})
+		if f.Unwrap() != p1 {
+			t.Fatal("expected unwrapped provider to be the first provider")
+		}
+	})
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Bulk generation with a pool of workers.
+
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/httpjson"
+)
+
+// GenManyOptions configures GenMany and GenManySeq.
+type GenManyOptions struct {
+	// Workers is the number of concurrent requests. Defaults to 4.
+	//
+	// When Concurrency is set, it caps the adaptive limit and defaults to Concurrency.Max. It must be set when
+	// Concurrency.Max is 0.
+	Workers int
+	// Concurrency adapts the number of concurrent requests to the provider's rate limits, starting at its
+	// initial limit and backing off on HTTP 429. Share it with a ProviderAdaptiveConcurrency wrapping the same
+	// provider to apply the limit to other callers too. Use NewAdaptiveConcurrency to create it.
+	Concurrency *AdaptiveConcurrency
+	// RPS limits the number of requests per second, including retries. 0 means no limit. Wrap the provider
+	// with WithRateLimit instead to share the limit with other callers.
+	RPS float64
+	// Retries is the number of times a failed request is retried when ShouldRetry returns true.
+	Retries int
+	// RetryDelay is the delay before the first retry. It doubles on each following retry. Defaults to 1s.
+	RetryDelay time.Duration
+	// ShouldRetry decides if a failed request is retried. If nil, DefaultShouldRetry is used.
+	ShouldRetry func(err error) bool
+
+	_ struct{}
+}
+
+// Validate implements genai.Validatable.
+func (o *GenManyOptions) Validate() error {
+	if o.Workers < 0 {
+		return errors.New("field Workers: must be positive")
+	}
+	if o.RPS < 0 {
+		return errors.New("field RPS: must be positive")
+	}
+	if o.Retries < 0 {
+		return errors.New("field Retries: must be positive")
+	}
+	if o.RetryDelay < 0 {
+		return errors.New("field RetryDelay: must be positive")
+	}
+	if o.Concurrency != nil {
+		if err := o.Concurrency.Validate(); err != nil {
+			return fmt.Errorf("field Concurrency: %w", err)
+		}
+		if o.Workers == 0 && o.Concurrency.Max == 0 {
+			return errors.New("field Workers: required when Concurrency.Max is 0")
+		}
+	}
+	return nil
+}
+
+// DefaultShouldRetry returns true for HTTP 429 Too Many Requests and 5xx server errors.
+func DefaultShouldRetry(err error) bool {
+	if herr, ok := errors.AsType[*httpjson.Error](err); ok {
+		return herr.StatusCode == http.StatusTooManyRequests || herr.StatusCode >= 500
+	}
+	return false
+}
+
+// ManyResult is the outcome of one request of GenMany or GenManySeq.
+type ManyResult struct {
+	// Index is the position of the request in the input.
+	Index int
+	// Msgs is the request.
+	Msgs genai.Messages
+	// Result is the response. It is the last one received when Err is set.
+	Result genai.Result
+	// Err is the error of the last attempt.
+	Err error
+	// Attempts is the number of requests sent, including retries.
+	Attempts int
+}
+
+// GenMany runs all the requests against p with a pool of workers and returns the results in the same order
+// as msgs.
+//
+// A failed request doesn't stop the others; check ManyResult.Err for each item. The returned error joins the
+// per-item errors, or is the context's error if it was canceled.
+//
+// This is the synchronous alternative to GenBatch for providers without batch support, or when the results
+// are needed quickly.
+func GenMany(ctx context.Context, p genai.Provider, msgs []genai.Messages, o *GenManyOptions, opts ...genai.GenOption) ([]ManyResult, error) {
+	if o != nil {
+		if err := o.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	out := make([]ManyResult, len(msgs))
+	seq := func(yield func(genai.Messages) bool) {
+		for _, m := range msgs {
+			if !yield(m) {
+				return
+			}
+		}
+	}
+	var errs []error
+	for r := range GenManySeq(ctx, p, seq, o, opts...) {
+		out[r.Index] = r
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("request %d: %w", r.Index, r.Err))
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return out, err
+	}
+	return out, errors.Join(errs...)
+}
+
+// GenManySeq runs the requests read from msgs against p with a pool of workers and yields the results as they
+// complete, not in input order.
+//
+// msgs may be unbounded, e.g. read from a channel, and is consumed as workers become available. Stopping the
+// iteration cancels the requests in flight. If o is invalid, a single ManyResult with the error is yielded.
+func GenManySeq(ctx context.Context, p genai.Provider, msgs iter.Seq[genai.Messages], o *GenManyOptions, opts ...genai.GenOption) iter.Seq[ManyResult] {
+	return func(yield func(ManyResult) bool) {
+		var cfg GenManyOptions
+		if o != nil {
+			if err := o.Validate(); err != nil {
+				yield(ManyResult{Err: err})
+				return
+			}
+			cfg = *o
+		}
+		if cfg.Workers == 0 {
+			cfg.Workers = 4
+			if cfg.Concurrency != nil {
+				cfg.Workers = cfg.Concurrency.Max
+			}
+		}
+		if cfg.RetryDelay == 0 {
+			cfg.RetryDelay = time.Second
+		}
+		if cfg.ShouldRetry == nil {
+			cfg.ShouldRetry = DefaultShouldRetry
+		}
+		if cfg.RPS > 0 {
+			p = WithRateLimit(p, cfg.RPS, 1, 0)
+		}
+		if cfg.Concurrency != nil {
+			p = &ProviderAdaptiveConcurrency{Provider: p, Limiter: cfg.Concurrency}
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type item struct {
+			i    int
+			msgs genai.Messages
+		}
+		in := make(chan item)
+		out := make(chan ManyResult)
+		var wg sync.WaitGroup
+		for range cfg.Workers {
+			wg.Go(func() {
+				for it := range in {
+					r := genOne(ctx, p, it.msgs, &cfg, opts)
+					r.Index = it.i
+					select {
+					case out <- r:
+					case <-ctx.Done():
+						return
+					}
+				}
+			})
+		}
+		go func() {
+			defer func() {
+				close(in)
+				wg.Wait()
+				close(out)
+			}()
+			i := 0
+			for m := range msgs {
+				select {
+				case in <- item{i, m}:
+					i++
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		for r := range out {
+			if !yield(r) {
+				cancel()
+				// Drain so the workers can exit.
+				for range out {
+				}
+				return
+			}
+		}
+	}
+}
+
+// genOne sends a request, retrying it as configured.
+func genOne(ctx context.Context, p genai.Provider, msgs genai.Messages, o *GenManyOptions, opts []genai.GenOption) ManyResult {
+	r := ManyResult{Msgs: msgs}
+	delay := o.RetryDelay
+	for {
+		r.Attempts++
+		r.Result, r.Err = p.GenSync(ctx, msgs, opts...)
+		if r.Err == nil || r.Attempts > o.Retries || ctx.Err() != nil || !o.ShouldRetry(r.Err) {
+			return r
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return r
+		}
+		delay *= 2
+	}
+}
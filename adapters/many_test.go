@@ -0,0 +1,183 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the bulk generation helpers.
+
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+	"github.com/maruel/httpjson"
+)
+
+func TestGenMany(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		p := &mockProviderFunc{fn: func(msgs genai.Messages) (genai.Result, error) {
+			return genai.Result{Message: genai.Message{Replies: []genai.Reply{{Text: "re: " + msgs[0].String()}}}}, nil
+		}}
+		msgs := []genai.Messages{{genai.NewTextMessage("a")}, {genai.NewTextMessage("b")}, {genai.NewTextMessage("c")}}
+		got, err := adapters.GenMany(t.Context(), p, msgs, &adapters.GenManyOptions{Workers: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range []string{"re: a", "re: b", "re: c"} {
+			if got[i].Index != i || got[i].Result.String() != want || got[i].Attempts != 1 {
+				t.Errorf("#%d: unexpected %+v", i, got[i])
+			}
+		}
+	})
+	t.Run("partial_failure", func(t *testing.T) {
+		p := &mockProviderFunc{fn: func(msgs genai.Messages) (genai.Result, error) {
+			if msgs[0].String() == "bad" {
+				return genai.Result{}, errors.New("oops")
+			}
+			return genai.Result{}, nil
+		}}
+		msgs := []genai.Messages{{genai.NewTextMessage("good")}, {genai.NewTextMessage("bad")}}
+		got, err := adapters.GenMany(t.Context(), p, msgs, nil)
+		if err == nil || err.Error() != "request 1: oops" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got[0].Err != nil || got[1].Err == nil || got[1].Msgs[0].String() != "bad" {
+			t.Fatalf("unexpected %+v", got)
+		}
+	})
+	t.Run("retry", func(t *testing.T) {
+		var calls atomic.Int32
+		p := &mockProviderFunc{fn: func(msgs genai.Messages) (genai.Result, error) {
+			if calls.Add(1) < 3 {
+				return genai.Result{}, &httpjson.Error{StatusCode: 503}
+			}
+			return genai.Result{}, nil
+		}}
+		o := &adapters.GenManyOptions{Retries: 2, RetryDelay: time.Millisecond}
+		got, err := adapters.GenMany(t.Context(), p, []genai.Messages{{genai.NewTextMessage("a")}}, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0].Attempts != 3 {
+			t.Fatalf("got %d attempts, want 3", got[0].Attempts)
+		}
+	})
+	t.Run("no_retry", func(t *testing.T) {
+		p := &mockProviderFunc{fn: func(msgs genai.Messages) (genai.Result, error) {
+			return genai.Result{}, &httpjson.Error{StatusCode: 400}
+		}}
+		o := &adapters.GenManyOptions{Retries: 2, RetryDelay: time.Millisecond}
+		got, err := adapters.GenMany(t.Context(), p, []genai.Messages{{genai.NewTextMessage("a")}}, o)
+		if err == nil || got[0].Attempts != 1 {
+			t.Fatalf("unexpected %v, %+v", err, got)
+		}
+	})
+	t.Run("concurrency", func(t *testing.T) {
+		var inflight, peak atomic.Int32
+		p := &mockProviderFunc{fn: func(msgs genai.Messages) (genai.Result, error) {
+			n := inflight.Add(1)
+			for {
+				v := peak.Load()
+				if n <= v || peak.CompareAndSwap(v, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inflight.Add(-1)
+			return genai.Result{}, &httpjson.Error{StatusCode: 429}
+		}}
+		msgs := make([]genai.Messages, 20)
+		for i := range msgs {
+			msgs[i] = genai.Messages{genai.NewTextMessage("a")}
+		}
+		// Workers defaults to Max but the rate limit errors keep the concurrency under the initial limit.
+		o := &adapters.GenManyOptions{Concurrency: newAdaptiveConcurrency(t, 4, 8)}
+		if _, err := adapters.GenMany(t.Context(), p, msgs, o); err == nil {
+			t.Fatal("expected error")
+		}
+		if n := peak.Load(); n > 4 {
+			t.Fatalf("got %d concurrent requests, want at most 4", n)
+		}
+		if l := o.Concurrency.Limit(); l >= 4 {
+			t.Fatalf("got limit %d, want less than 4", l)
+		}
+	})
+	t.Run("Validate", func(t *testing.T) {
+		_, err := adapters.GenMany(t.Context(), &mockProviderFunc{}, []genai.Messages{{genai.NewTextMessage("a")}}, &adapters.GenManyOptions{Workers: -1})
+		if err == nil || err.Error() != "field Workers: must be positive" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = adapters.GenMany(t.Context(), &mockProviderFunc{}, []genai.Messages{{genai.NewTextMessage("a")}}, &adapters.GenManyOptions{Concurrency: &adapters.AdaptiveConcurrency{}})
+		if err == nil || err.Error() != "field Concurrency: use NewAdaptiveConcurrency" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = adapters.GenMany(t.Context(), &mockProviderFunc{}, []genai.Messages{{genai.NewTextMessage("a")}}, &adapters.GenManyOptions{Concurrency: newAdaptiveConcurrency(t, 4, 0)})
+		if err == nil || err.Error() != "field Workers: required when Concurrency.Max is 0" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestGenManySeq(t *testing.T) {
+	var inflight, peak atomic.Int32
+	p := &mockProviderFunc{fn: func(msgs genai.Messages) (genai.Result, error) {
+		n := inflight.Add(1)
+		for {
+			v := peak.Load()
+			if n <= v || peak.CompareAndSwap(v, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inflight.Add(-1)
+		return genai.Result{}, nil
+	}}
+	ch := make(chan genai.Messages)
+	go func() {
+		defer close(ch)
+		for range 10 {
+			ch <- genai.Messages{genai.NewTextMessage("a")}
+		}
+	}()
+	seq := func(yield func(genai.Messages) bool) {
+		for m := range ch {
+			if !yield(m) {
+				return
+			}
+		}
+	}
+	seen := 0
+	for r := range adapters.GenManySeq(t.Context(), p, seq, &adapters.GenManyOptions{Workers: 3}) {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		seen++
+		if seen == 8 {
+			break
+		}
+	}
+	// Unblock the producer.
+	for range ch {
+	}
+	if seen != 8 {
+		t.Fatalf("got %d results", seen)
+	}
+	if n := peak.Load(); n > 3 {
+		t.Fatalf("got %d concurrent requests, want at most 3", n)
+	}
+}
+
+// mockProviderFunc is a concurrency safe provider calling fn.
+type mockProviderFunc struct {
+	mockProviderGenSync
+	fn func(msgs genai.Messages) (genai.Result, error)
+}
+
+func (m *mockProviderFunc) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	return m.fn(msgs)
+}
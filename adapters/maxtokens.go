@@ -0,0 +1,96 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Automatic maximum output tokens based on the model metadata.
+
+package adapters
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"sync"
+
+	"github.com/maruel/genai"
+)
+
+// ProviderAutoMaxTokens wraps a Provider and sets genai.GenOptionText.MaxTokens to the model's output token
+// limit when the caller didn't set it.
+//
+// Some providers default to a small number of output tokens, so replies are silently truncated with
+// genai.FinishedLength. The limit is retrieved once with ListModels, from models implementing
+// genai.ModelOutputTokens. When it is unknown, the options are passed through unchanged.
+type ProviderAutoMaxTokens struct {
+	genai.Provider
+
+	// Max caps the value set, when non-zero. This is useful with providers that reject large values on
+	// non-streaming requests, like Anthropic.
+	Max int64
+
+	mu     sync.Mutex
+	done   bool
+	tokens int64
+}
+
+// GenSync implements genai.Provider.
+func (c *ProviderAutoMaxTokens) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	return c.Provider.GenSync(ctx, msgs, c.options(ctx, opts)...)
+}
+
+// GenStream implements genai.Provider.
+func (c *ProviderAutoMaxTokens) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	return c.Provider.GenStream(ctx, msgs, c.options(ctx, opts)...)
+}
+
+// MaxOutputTokens returns the value that is set when the caller doesn't specify MaxTokens, or 0 if unknown.
+func (c *ProviderAutoMaxTokens) MaxOutputTokens(ctx context.Context) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.done {
+		mdls, err := c.Provider.ListModels(ctx)
+		if err != nil {
+			// Try again on the next request.
+			return 0
+		}
+		c.done = true
+		id := c.Provider.ModelID()
+		for _, m := range mdls {
+			if o, ok := m.(genai.ModelOutputTokens); ok && m.GetID() == id {
+				c.tokens = o.MaxOutputTokens()
+				break
+			}
+		}
+		if c.Max != 0 && (c.tokens == 0 || c.tokens > c.Max) {
+			c.tokens = c.Max
+		}
+	}
+	return c.tokens
+}
+
+func (c *ProviderAutoMaxTokens) Unwrap() genai.Provider {
+	return c.Provider
+}
+
+// options returns opts with MaxTokens set, unless the caller already set it.
+func (c *ProviderAutoMaxTokens) options(ctx context.Context, opts []genai.GenOption) []genai.GenOption {
+	i := slices.IndexFunc(opts, func(o genai.GenOption) bool {
+		_, ok := o.(*genai.GenOptionText)
+		return ok
+	})
+	if i != -1 && opts[i].(*genai.GenOptionText).MaxTokens != 0 {
+		return opts
+	}
+	n := c.MaxOutputTokens(ctx)
+	if n == 0 {
+		return opts
+	}
+	if i == -1 {
+		return append(slices.Clip(opts), &genai.GenOptionText{MaxTokens: n})
+	}
+	o := *opts[i].(*genai.GenOptionText)
+	o.MaxTokens = n
+	opts = slices.Clone(opts)
+	opts[i] = &o
+	return opts
+}
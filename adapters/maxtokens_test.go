@@ -0,0 +1,106 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the automatic max tokens adapter.
+
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+)
+
+func TestProviderAutoMaxTokens(t *testing.T) {
+	models := []genai.Model{&mockModel{id: "llm-cheap", out: 1000}, &mockModel{id: "llm-sota", out: 64000}}
+	tests := []struct {
+		name   string
+		max    int64
+		models []genai.Model
+		opts   []genai.GenOption
+		want   int64
+		temp   float64
+		lists  int
+	}{
+		{name: "no_options", models: models, want: 64000, lists: 1},
+		{name: "text_options", models: models, opts: []genai.GenOption{&genai.GenOptionText{Temperature: 0.5}}, want: 64000, temp: 0.5, lists: 1},
+		{name: "explicit", models: models, opts: []genai.GenOption{&genai.GenOptionText{MaxTokens: 100}}, want: 100},
+		{name: "max", max: 16000, models: models, want: 16000, lists: 1},
+		{name: "max_unknown", max: 16000, want: 16000, lists: 1},
+		{name: "unknown", models: []genai.Model{&mockModel{id: "llm-sota"}}, want: 0, lists: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockProviderModels{models: tc.models}
+			mock.responses = []genai.Result{{}, {}}
+			c := &adapters.ProviderAutoMaxTokens{Provider: mock, Max: tc.max}
+			for range 2 {
+				if _, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}, tc.opts...); err != nil {
+					t.Fatal(err)
+				}
+				got := genai.GenOptionText{}
+				for _, o := range mock.opts {
+					if v, ok := o.(*genai.GenOptionText); ok {
+						got = *v
+					}
+				}
+				if got.MaxTokens != tc.want || got.Temperature != tc.temp {
+					t.Fatalf("got %+v, want MaxTokens %d", got, tc.want)
+				}
+			}
+			if mock.listCalls != tc.lists {
+				t.Fatalf("expected ListModels to be called %d times, got %d", tc.lists, mock.listCalls)
+			}
+			if tc.name == "text_options" && tc.opts[0].(*genai.GenOptionText).MaxTokens != 0 {
+				t.Fatal("caller's options were modified")
+			}
+		})
+	}
+	t.Run("error", func(t *testing.T) {
+		mock := &mockProviderModels{listErr: errors.New("boom")}
+		mock.responses = []genai.Result{{}}
+		c := &adapters.ProviderAutoMaxTokens{Provider: mock}
+		if _, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}); err != nil {
+			t.Fatal(err)
+		}
+		if len(mock.opts) != 0 {
+			t.Fatalf("unexpected options %v", mock.opts)
+		}
+		if c.Unwrap() != mock {
+			t.Fatal("unexpected Unwrap")
+		}
+	})
+}
+
+type mockModel struct {
+	id  string
+	out int64
+	ctx int64
+}
+
+func (m *mockModel) GetID() string          { return m.id }
+func (m *mockModel) String() string         { return m.id }
+func (m *mockModel) Context() int64         { return m.ctx }
+func (m *mockModel) MaxOutputTokens() int64 { return m.out }
+
+type mockProviderModels struct {
+	mockProviderGenSync
+	models    []genai.Model
+	listErr   error
+	listCalls int
+	opts      []genai.GenOption
+}
+
+func (m *mockProviderModels) ListModels(ctx context.Context) ([]genai.Model, error) {
+	m.listCalls++
+	return m.models, m.listErr
+}
+
+func (m *mockProviderModels) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	m.opts = opts
+	return m.mockProviderGenSync.GenSync(ctx, msgs, opts...)
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Race adapter to use the fastest provider.
+
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/maruel/genai"
+)
+
+// Race returns a ProviderRace that sends each request to all the providers concurrently.
+//
+// The first provider is used for the other methods like Name() and ModelID().
+func Race(providers []genai.Provider) *ProviderRace {
+	r := &ProviderRace{Providers: providers}
+	if len(providers) != 0 {
+		r.Provider = providers[0]
+	}
+	return r
+}
+
+// ProviderRace sends GenSync and GenStream requests to all the providers concurrently, returns the first
+// successful result and cancels the other requests.
+//
+// It reduces the latency at the cost of paying for all the requests. The providers can be different models of
+// the same provider.
+type ProviderRace struct {
+	genai.Provider
+
+	// Providers is the list of providers to race.
+	Providers []genai.Provider
+
+	_ struct{}
+}
+
+// GenSync implements genai.Provider.
+//
+// When all the providers fail, the errors are joined.
+func (c *ProviderRace) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	if len(c.Providers) == 0 {
+		return genai.Result{}, errors.New("no provider to race")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		i   int
+		res genai.Result
+		err error
+	}
+	ch := make(chan result, len(c.Providers))
+	for i, p := range c.Providers {
+		go func() {
+			res, err := p.GenSync(ctx, msgs, opts...)
+			ch <- result{i, res, err}
+		}()
+	}
+	errs := make([]error, len(c.Providers))
+	for range c.Providers {
+		r := <-ch
+		if r.err == nil {
+			return r.res, nil
+		}
+		errs[r.i] = fmt.Errorf("%s: %w", c.Providers[r.i].Name(), r.err)
+	}
+	return genai.Result{}, errors.Join(errs...)
+}
+
+// GenStream implements genai.Provider.
+//
+// The first provider to send a fragment, or to succeed without sending any, wins and the others are canceled.
+// The winner's error is returned as-is even if it fails midway, since fragments cannot be retracted once sent.
+func (c *ProviderRace) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	var res genai.Result
+	var finalErr error
+	fnFragments := func(yield func(genai.Reply) bool) {
+		if len(c.Providers) == 0 {
+			finalErr = errors.New("no provider to race")
+			return
+		}
+		type event struct {
+			i    int
+			f    genai.Reply
+			done bool
+			res  genai.Result
+			err  error
+		}
+		events := make(chan event)
+		quit := make(chan struct{})
+		defer close(quit)
+		cancels := make([]context.CancelFunc, len(c.Providers))
+		stops := make([]chan struct{}, len(c.Providers))
+		for i, p := range c.Providers {
+			var pctx context.Context
+			pctx, cancels[i] = context.WithCancel(ctx)
+			stops[i] = make(chan struct{})
+			go func() {
+				fragments, finish := p.GenStream(pctx, msgs, opts...)
+			loop:
+				for f := range fragments {
+					select {
+					case events <- event{i: i, f: f}:
+					case <-stops[i]:
+						break loop
+					}
+				}
+				res, err := finish()
+				select {
+				case events <- event{i: i, done: true, res: res, err: err}:
+				case <-quit:
+				}
+			}()
+		}
+		defer func() {
+			for _, cancel := range cancels {
+				cancel()
+			}
+		}()
+		// stop makes the provider stop streaming. The losers are also canceled.
+		stopped := make([]bool, len(c.Providers))
+		stop := func(i int, abort bool) {
+			if !stopped[i] {
+				stopped[i] = true
+				close(stops[i])
+			}
+			if abort {
+				cancels[i]()
+			}
+		}
+		winner := -1
+		errs := make([]error, len(c.Providers))
+		pending := len(c.Providers)
+		for ev := range events {
+			if winner == -1 {
+				if ev.done && ev.err != nil {
+					errs[ev.i] = fmt.Errorf("%s: %w", c.Providers[ev.i].Name(), ev.err)
+					if pending--; pending == 0 {
+						finalErr = errors.Join(errs...)
+						return
+					}
+					continue
+				}
+				winner = ev.i
+				for i := range c.Providers {
+					if i != winner {
+						stop(i, true)
+					}
+				}
+			}
+			if ev.i != winner {
+				continue
+			}
+			if ev.done {
+				res, finalErr = ev.res, ev.err
+				return
+			}
+			if !stopped[winner] && !yield(ev.f) {
+				// Let the winner finish so its result is returned.
+				stop(winner, false)
+			}
+		}
+	}
+	fnFinish := func() (genai.Result, error) {
+		return res, finalErr
+	}
+	return fnFragments, fnFinish
+}
+
+// Unwrap implements genai.ProviderUnwrap.
+func (c *ProviderRace) Unwrap() genai.Provider {
+	return c.Provider
+}
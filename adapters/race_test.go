@@ -0,0 +1,163 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the race adapter.
+
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+)
+
+func TestProviderRace(t *testing.T) {
+	t.Run("GenSync", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			slow    error
+			fast    error
+			want    string
+			wantErr string
+		}{
+			{name: "fastest", want: "fast"},
+			{name: "fast_fails", fast: errors.New("boom"), want: "slow"},
+			{name: "all_fail", slow: errors.New("a"), fast: errors.New("b"), wantErr: "slow: a\nfast: b"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				slow := &mockProviderDelay{name: "slow", delay: 50 * time.Millisecond, err: tc.slow}
+				fast := &mockProviderDelay{name: "fast", err: tc.fast}
+				r := adapters.Race([]genai.Provider{slow, fast})
+				res, err := r.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")})
+				if tc.wantErr != "" {
+					if err == nil || err.Error() != tc.wantErr {
+						t.Fatalf("got %v, want %q", err, tc.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s := res.String(); s != tc.want {
+					t.Fatalf("got %q, want %q", s, tc.want)
+				}
+				if tc.want == "fast" {
+					// The cancellation is asynchronous.
+					for start := time.Now(); !slow.canceled.Load(); time.Sleep(time.Millisecond) {
+						if time.Since(start) > time.Second {
+							t.Fatal("expected the slow request to be canceled")
+						}
+					}
+				}
+			})
+		}
+	})
+	t.Run("GenStream", func(t *testing.T) {
+		tests := []struct {
+			name string
+			fast error
+			want string
+		}{
+			{name: "fastest", want: "fast"},
+			{name: "fast_fails", fast: errors.New("boom"), want: "slow"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				slow := &mockProviderDelay{name: "slow", delay: 50 * time.Millisecond}
+				fast := &mockProviderDelay{name: "fast", err: tc.fast}
+				r := adapters.Race([]genai.Provider{slow, fast})
+				fragments, finish := r.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("hi")})
+				var b strings.Builder
+				for f := range fragments {
+					b.WriteString(f.Text)
+				}
+				res, err := finish()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s := b.String(); s != tc.want+tc.want {
+					t.Fatalf("got fragments %q", s)
+				}
+				if s := res.String(); s != tc.want+tc.want {
+					t.Fatalf("got %q", s)
+				}
+			})
+		}
+	})
+	t.Run("GenStream/break", func(t *testing.T) {
+		r := adapters.Race([]genai.Provider{&mockProviderDelay{name: "a"}, &mockProviderDelay{name: "b", delay: time.Second}})
+		fragments, finish := r.GenStream(t.Context(), nil)
+		for range fragments {
+			break
+		}
+		if _, err := finish(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("empty", func(t *testing.T) {
+		r := adapters.Race(nil)
+		if _, err := r.GenSync(t.Context(), nil); err == nil {
+			t.Fatal("expected error")
+		}
+		if r.Unwrap() != nil {
+			t.Fatal("expected nil")
+		}
+	})
+}
+
+// mockProviderDelay replies with its name after a delay.
+type mockProviderDelay struct {
+	mockProviderGenSync
+	name     string
+	delay    time.Duration
+	err      error
+	canceled atomic.Bool
+}
+
+func (m *mockProviderDelay) Name() string {
+	return m.name
+}
+
+func (m *mockProviderDelay) wait(ctx context.Context) error {
+	select {
+	case <-time.After(m.delay):
+		return m.err
+	case <-ctx.Done():
+		m.canceled.Store(true)
+		return ctx.Err()
+	}
+}
+
+func (m *mockProviderDelay) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	if err := m.wait(ctx); err != nil {
+		return genai.Result{}, err
+	}
+	return genai.Result{Message: genai.Message{Replies: []genai.Reply{{Text: m.name}}}}, nil
+}
+
+func (m *mockProviderDelay) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	res := genai.Result{}
+	var finalErr error
+	return func(yield func(genai.Reply) bool) {
+			if finalErr = m.wait(ctx); finalErr != nil {
+				return
+			}
+			for range 2 {
+				f := genai.Reply{Text: m.name}
+				if finalErr = res.Accumulate(&f); finalErr != nil || !yield(f) {
+					return
+				}
+			}
+		}, func() (genai.Result, error) {
+			return res, finalErr
+		}
+}
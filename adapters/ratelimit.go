@@ -0,0 +1,195 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Client-side rate limiting.
+
+package adapters
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/maruel/genai"
+)
+
+// DefaultRateLimitBackoff is the pause of ProviderRateLimit after a rate limit error when the provider
+// didn't report when the limit resets.
+const DefaultRateLimitBackoff = time.Second
+
+// WithRateLimit returns p throttled to rps requests per second with bursts of burst requests and at most
+// maxConcurrent requests in flight. 0 disables the corresponding limit. Adaptive slowdown is enabled.
+func WithRateLimit(p genai.Provider, rps float64, burst, maxConcurrent int) *ProviderRateLimit {
+	return &ProviderRateLimit{Provider: p, RPS: rps, Burst: burst, MaxConcurrent: maxConcurrent, Adaptive: true}
+}
+
+// ProviderRateLimit wraps a Provider and throttles the GenSync and GenStream calls on the client side, so
+// bulk workloads don't trigger HTTP 429s.
+//
+// It is meant to be shared by many goroutines sending requests to the same provider. Use
+// ProviderAdaptiveConcurrency instead to discover the concurrency limit automatically.
+type ProviderRateLimit struct {
+	genai.Provider
+
+	// RPS is the sustained number of requests per second. 0 means no limit.
+	RPS float64
+	// Burst is the number of requests that can be sent at once above RPS. Defaults to 1.
+	Burst int
+	// MaxConcurrent is the maximum number of requests in flight. 0 means no limit.
+	MaxConcurrent int
+	// Adaptive slows down based on the rate limits reported by the provider in genai.Usage.Limits, parsed
+	// from headers like X-Ratelimit-Remaining. When a limit is exhausted, requests wait until it resets. When
+	// it is low, requests are spread evenly until it resets. After a rate limit error, requests pause for
+	// DefaultRateLimitBackoff.
+	Adaptive bool
+
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	paused   time.Time
+	inflight int
+	changed  chan struct{}
+}
+
+// Validate implements genai.Validatable.
+func (c *ProviderRateLimit) Validate() error {
+	if c.RPS < 0 {
+		return errors.New("field RPS: must be positive")
+	}
+	if c.Burst < 0 {
+		return errors.New("field Burst: must be positive")
+	}
+	if c.MaxConcurrent < 0 {
+		return errors.New("field MaxConcurrent: must be positive")
+	}
+	return nil
+}
+
+// GenSync implements genai.Provider.
+func (c *ProviderRateLimit) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	if err := c.acquire(ctx); err != nil {
+		return genai.Result{}, err
+	}
+	res, err := c.Provider.GenSync(ctx, msgs, opts...)
+	c.release(&res, err)
+	return res, err
+}
+
+// GenStream implements genai.Provider.
+func (c *ProviderRateLimit) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	var res genai.Result
+	var finalErr error
+	fnFragments := func(yield func(genai.Reply) bool) {
+		if finalErr = c.acquire(ctx); finalErr != nil {
+			return
+		}
+		fragments, finish := c.Provider.GenStream(ctx, msgs, opts...)
+		for f := range fragments {
+			if !yield(f) {
+				break
+			}
+		}
+		res, finalErr = finish()
+		c.release(&res, finalErr)
+	}
+	fnFinish := func() (genai.Result, error) {
+		return res, finalErr
+	}
+	return fnFragments, fnFinish
+}
+
+// Unwrap implements genai.ProviderUnwrap.
+func (c *ProviderRateLimit) Unwrap() genai.Provider {
+	return c.Provider
+}
+
+// acquire blocks until a request can be sent.
+func (c *ProviderRateLimit) acquire(ctx context.Context) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	for {
+		c.mu.Lock()
+		if c.changed == nil {
+			c.changed = make(chan struct{})
+		}
+		if c.MaxConcurrent > 0 && c.inflight >= c.MaxConcurrent {
+			ch := c.changed
+			c.mu.Unlock()
+			select {
+			case <-ch:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		wait := c.reserveLocked(time.Now())
+		if wait == 0 {
+			c.inflight++
+			c.mu.Unlock()
+			return nil
+		}
+		c.mu.Unlock()
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserveLocked consumes a token and returns 0, or returns how long to wait for one.
+func (c *ProviderRateLimit) reserveLocked(now time.Time) time.Duration {
+	if now.Before(c.paused) {
+		return c.paused.Sub(now)
+	}
+	if c.RPS <= 0 {
+		return 0
+	}
+	burst := float64(max(c.Burst, 1))
+	if c.last.IsZero() {
+		c.tokens = burst
+	} else {
+		c.tokens = min(burst, c.tokens+now.Sub(c.last).Seconds()*c.RPS)
+	}
+	c.last = now
+	if c.tokens >= 1 {
+		c.tokens--
+		return 0
+	}
+	return max(time.Duration((1-c.tokens)/c.RPS*float64(time.Second)), time.Millisecond)
+}
+
+func (c *ProviderRateLimit) release(res *genai.Result, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inflight--
+	if c.Adaptive {
+		now := time.Now()
+		until := time.Time{}
+		for _, l := range res.Usage.Limits {
+			if l.Type != genai.Requests || l.Reset.Before(now) {
+				continue
+			}
+			// Spread the remaining requests evenly until the reset. When exhausted, wait for the reset.
+			var u time.Time
+			if l.Remaining <= 0 {
+				u = l.Reset
+			} else if l.Limit > 0 && l.Remaining*10 < l.Limit {
+				u = now.Add(l.Reset.Sub(now) / time.Duration(l.Remaining))
+			}
+			if u.After(until) {
+				until = u
+			}
+		}
+		if until.IsZero() && IsRateLimited(err) {
+			until = now.Add(DefaultRateLimitBackoff)
+		}
+		if until.After(c.paused) {
+			c.paused = until
+		}
+	}
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the client-side rate limiter.
+
+package adapters_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+	"github.com/maruel/httpjson"
+)
+
+func TestProviderRateLimit(t *testing.T) {
+	t.Run("rps", func(t *testing.T) {
+		provider := &mockProviderGenSync{responses: make([]genai.Result, 5)}
+		wrapped := adapters.WithRateLimit(provider, 100, 1, 0)
+		start := time.Now()
+		for range 5 {
+			if _, err := wrapped.GenSync(t.Context(), nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// The first request is immediate, the 4 others wait 10ms each.
+		if d := time.Since(start); d < 35*time.Millisecond {
+			t.Fatalf("took %s, expected at least 40ms", d)
+		}
+	})
+	t.Run("burst", func(t *testing.T) {
+		provider := &mockProviderGenSync{responses: make([]genai.Result, 3)}
+		wrapped := adapters.WithRateLimit(provider, 0.1, 3, 0)
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		for range 3 {
+			if _, err := wrapped.GenSync(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+	t.Run("max_concurrent", func(t *testing.T) {
+		provider := &mockProviderBlocking{release: make(chan struct{})}
+		wrapped := adapters.WithRateLimit(provider, 0, 0, 2)
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Go(func() {
+				if _, err := wrapped.GenSync(t.Context(), nil); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+		time.Sleep(20 * time.Millisecond)
+		if n := provider.inflight.Load(); n != 2 {
+			t.Errorf("got %d requests in flight, want 2", n)
+		}
+		close(provider.release)
+		wg.Wait()
+		if n := provider.peak.Load(); n != 2 {
+			t.Fatalf("got peak %d requests in flight, want 2", n)
+		}
+	})
+	t.Run("adaptive", func(t *testing.T) {
+		reset := time.Now().Add(50 * time.Millisecond)
+		res := genai.Result{Usage: genai.Usage{Limits: []genai.RateLimit{{Type: genai.Requests, Limit: 10, Remaining: 0, Reset: reset}}}}
+		provider := &mockProviderGenSync{responses: []genai.Result{res, {}}}
+		wrapped := adapters.WithRateLimit(provider, 0, 0, 0)
+		for range 2 {
+			if _, err := wrapped.GenSync(t.Context(), nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if now := time.Now(); now.Before(reset) {
+			t.Fatalf("second request sent %s before the reset", reset.Sub(now))
+		}
+	})
+	t.Run("rate_limited", func(t *testing.T) {
+		provider := &mockProviderGenSync{responses: []genai.Result{{}, {}}, err: &httpjson.Error{StatusCode: 429}}
+		wrapped := adapters.WithRateLimit(provider, 0, 0, 0)
+		if _, err := wrapped.GenSync(t.Context(), nil); !adapters.IsRateLimited(err) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The next request waits for DefaultRateLimitBackoff.
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := wrapped.GenSync(ctx, nil); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("stream", func(t *testing.T) {
+		provider := &mockProviderGenStream{streamResponses: []streamResponse{{fragments: []genai.Reply{{Text: "hi"}}}}}
+		wrapped := adapters.WithRateLimit(provider, 10, 1, 1)
+		fragments, finish := wrapped.GenStream(t.Context(), nil)
+		for range fragments {
+		}
+		res, err := finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := res.String(); s != "hi" {
+			t.Fatalf("got %q", s)
+		}
+		if wrapped.Unwrap() != provider {
+			t.Fatal("expected unwrapped provider to be the original provider")
+		}
+	})
+	t.Run("Validate", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			p       *adapters.ProviderRateLimit
+			wantErr string
+		}{
+			{"RPS", &adapters.ProviderRateLimit{RPS: -1}, "field RPS: must be positive"},
+			{"Burst", &adapters.ProviderRateLimit{Burst: -1}, "field Burst: must be positive"},
+			{"MaxConcurrent", &adapters.ProviderRateLimit{MaxConcurrent: -1}, "field MaxConcurrent: must be positive"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				if err := tc.p.Validate(); err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("got %v, want %q", err, tc.wantErr)
+				}
+			})
+		}
+	})
+}
+
+// mockProviderBlocking blocks GenSync until release is closed.
+type mockProviderBlocking struct {
+	mockProviderGenSync
+	release  chan struct{}
+	inflight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (m *mockProviderBlocking) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	n := m.inflight.Add(1)
+	for {
+		p := m.peak.Load()
+		if n <= p || m.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	<-m.release
+	m.inflight.Add(-1)
+	return genai.Result{}, nil
+}
@@ -0,0 +1,271 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Automatic image transcoding and downscaling before upload.
+
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Register the GIF decoder.
+	"image/jpeg"
+	"image/png"
+	"io"
+	"iter"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/scoreboard"
+)
+
+// Transcoder converts an image to a format and size accepted by a model.
+//
+// Implement it to support more formats than ImageTranscoder, e.g. with golang.org/x/image/webp.
+type Transcoder interface {
+	// Transcode returns data encoded as one of formats, and at most maxSize bytes.
+	Transcode(mimeType string, data []byte, formats []string, maxSize int64) (string, []byte, error)
+}
+
+// ProviderTranscode wraps a Provider and converts the inline images that the model would reject before
+// sending them.
+//
+// Images in a format the model doesn't support are re-encoded and images larger than the limit are
+// downscaled, instead of failing in the provider. The supported formats and the size limit are the ones
+// listed for genai.ModalityImage in the scoreboard scenario of the current model, unless overridden. Images
+// referenced by URL and other modalities are passed through unchanged.
+//
+// The caller's messages are not modified.
+type ProviderTranscode struct {
+	genai.Provider
+
+	// Formats are the accepted image MIME types. When empty, they are read from the scoreboard. When the
+	// scoreboard doesn't list them, "image/jpeg" and "image/png" are assumed.
+	Formats []string
+	// MaxSize is the maximum size of an image in bytes. When 0, it is read from the scoreboard, defaulting to
+	// 5MiB which is the lowest limit of the popular providers.
+	MaxSize int64
+	// Transcoder converts the images. Defaults to ImageTranscoder{}.
+	Transcoder Transcoder
+}
+
+// GenSync implements genai.Provider.
+func (c *ProviderTranscode) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	msgs, err := c.Transcode(msgs)
+	if err != nil {
+		return genai.Result{}, err
+	}
+	return c.Provider.GenSync(ctx, msgs, opts...)
+}
+
+// GenStream implements genai.Provider.
+func (c *ProviderTranscode) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	msgs, err := c.Transcode(msgs)
+	if err != nil {
+		return func(yield func(genai.Reply) bool) {}, func() (genai.Result, error) { return genai.Result{}, err }
+	}
+	return c.Provider.GenStream(ctx, msgs, opts...)
+}
+
+// Transcode returns the messages that would be sent for msgs.
+//
+// msgs is returned as-is when no image needs to be converted.
+func (c *ProviderTranscode) Transcode(msgs genai.Messages) (genai.Messages, error) {
+	formats, maxSize := c.limits()
+	t := c.Transcoder
+	if t == nil {
+		t = ImageTranscoder{}
+	}
+	out := msgs
+	copied := false
+	for i := range msgs {
+		cloned := false
+		for j := range msgs[i].Requests {
+			d := &msgs[i].Requests[j].Doc
+			if d.Src == nil {
+				continue
+			}
+			name := d.GetFilename()
+			mimeType := internal.MimeByExt(filepath.Ext(name))
+			if !strings.HasPrefix(mimeType, "image/") {
+				continue
+			}
+			size, err := d.Src.Seek(0, io.SeekEnd)
+			if err != nil {
+				return nil, fmt.Errorf("message %d, request %d: %w", i, j, err)
+			}
+			if _, err = d.Src.Seek(0, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("message %d, request %d: %w", i, j, err)
+			}
+			if size <= maxSize && slices.Contains(formats, mimeType) {
+				continue
+			}
+			data, err := io.ReadAll(d.Src)
+			if err != nil {
+				return nil, fmt.Errorf("message %d, request %d: %w", i, j, err)
+			}
+			newMime, newData, err := t.Transcode(mimeType, data, formats, maxSize)
+			if err != nil {
+				return nil, fmt.Errorf("message %d, request %d: %s: %w", i, j, name, err)
+			}
+			if !copied {
+				out = slices.Clone(msgs)
+				copied = true
+			}
+			if !cloned {
+				out[i].Requests = slices.Clone(msgs[i].Requests)
+				cloned = true
+			}
+			out[i].Requests[j].Doc = genai.Doc{
+				Filename: strings.TrimSuffix(name, filepath.Ext(name)) + imageExts[newMime],
+				Src:      bytes.NewReader(newData),
+				Detail:   d.Detail,
+			}
+		}
+	}
+	return out, nil
+}
+
+func (c *ProviderTranscode) Unwrap() genai.Provider {
+	return c.Provider
+}
+
+// limits returns the accepted image formats and maximum size.
+func (c *ProviderTranscode) limits() ([]string, int64) {
+	formats := c.Formats
+	maxSize := c.MaxSize
+	id := c.Provider.ModelID()
+	sb := c.Provider.Scoreboard()
+	for i := range sb.Scenarios {
+		sc := &sb.Scenarios[i]
+		if !slices.Contains(sc.Models, id) {
+			continue
+		}
+		if m, ok := sc.In[scoreboard.ModalityImage]; ok {
+			if len(formats) == 0 {
+				formats = m.SupportedFormats
+			}
+			if maxSize == 0 {
+				maxSize = m.MaxSize
+			}
+		}
+		break
+	}
+	if len(formats) == 0 {
+		formats = []string{"image/jpeg", "image/png"}
+	}
+	if maxSize == 0 {
+		maxSize = 5 * 1024 * 1024
+	}
+	return formats, maxSize
+}
+
+// imageExts are the file extensions of the formats ImageTranscoder can output.
+var imageExts = map[string]string{
+	"image/gif":  ".gif",
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// ImageTranscoder is a Transcoder that only uses the standard library.
+//
+// It decodes GIF, JPEG and PNG and encodes JPEG or PNG. Oversized images are downscaled until they fit.
+type ImageTranscoder struct {
+	// MaxDimension is the maximum width and height in pixels. Larger images are downscaled preserving their
+	// aspect ratio. 0 means no limit.
+	MaxDimension int
+	// Quality is the JPEG quality, between 1 and 100. Defaults to 85.
+	Quality int
+}
+
+// Transcode implements Transcoder.
+func (t ImageTranscoder) Transcode(mimeType string, data []byte, formats []string, maxSize int64) (string, []byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("can't decode %s: %w", mimeType, err)
+	}
+	// Keep the original format when possible, otherwise prefer JPEG as it is the most compact.
+	out := ""
+	switch {
+	case mimeType != "image/gif" && slices.Contains(formats, mimeType):
+		out = mimeType
+	case slices.Contains(formats, "image/jpeg"):
+		out = "image/jpeg"
+	case slices.Contains(formats, "image/png"):
+		out = "image/png"
+	default:
+		return "", nil, fmt.Errorf("can't encode to any of %s", strings.Join(formats, ", "))
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if t.MaxDimension > 0 && max(w, h) > t.MaxDimension {
+		scale := float64(t.MaxDimension) / float64(max(w, h))
+		w, h = max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale))
+	}
+	for {
+		if w != b.Dx() || h != b.Dy() {
+			img = downscale(img, w, h)
+			b = img.Bounds()
+		}
+		buf := bytes.Buffer{}
+		if out == "image/jpeg" {
+			q := t.Quality
+			if q == 0 {
+				q = 85
+			}
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: q})
+		} else {
+			err = png.Encode(&buf, img)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if int64(buf.Len()) <= maxSize {
+			return out, buf.Bytes(), nil
+		}
+		if w <= 16 || h <= 16 {
+			return "", nil, errors.New("can't downscale the image under the size limit")
+		}
+		// The encoded size is roughly proportional to the number of pixels. Leave some margin to converge
+		// faster.
+		scale := math.Sqrt(float64(maxSize)/float64(buf.Len())) * 0.9
+		w, h = max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale))
+	}
+}
+
+// downscale resizes src to w x h by averaging the source pixels covered by each destination pixel.
+func downscale(src image.Image, w, h int) image.Image {
+	b := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0, y1 := y*b.Dy()/h, max((y+1)*b.Dy()/h, y*b.Dy()/h+1)
+		for x := range w {
+			x0, x1 := x*b.Dx()/w, max((x+1)*b.Dx()/w, x*b.Dx()/w+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := rgba.RGBAAt(sx, sy)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the image transcoding adapter.
+
+package adapters_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+	"github.com/maruel/genai/scoreboard"
+)
+
+func TestProviderTranscode(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	// Noise doesn't compress, so the encoded size is predictable.
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range src.Pix {
+		src.Pix[i] = byte(r.Uint32())
+	}
+	pngData := bytes.Buffer{}
+	if err := png.Encode(&pngData, src); err != nil {
+		t.Fatal(err)
+	}
+	gifData := bytes.Buffer{}
+	if err := gif.Encode(&gifData, src, nil); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		c        adapters.ProviderTranscode
+		filename string
+		data     []byte
+		want     string
+		maxSize  int
+		maxDim   int
+	}{
+		{name: "unchanged", filename: "a.png", data: pngData.Bytes(), want: "a.png", maxSize: pngData.Len()},
+		{name: "format", filename: "a.gif", data: gifData.Bytes(), want: "a.jpg", maxDim: 400},
+		{
+			name:     "scoreboard",
+			c:        adapters.ProviderTranscode{Provider: &mockProviderScoreboard{formats: []string{"image/png"}, maxSize: 100000}},
+			filename: "a.gif",
+			data:     gifData.Bytes(),
+			want:     "a.png",
+			maxSize:  100000,
+			maxDim:   399,
+		},
+		{
+			name:     "downscale",
+			c:        adapters.ProviderTranscode{Formats: []string{"image/png"}, MaxSize: 100000},
+			filename: "a.png",
+			data:     pngData.Bytes(),
+			want:     "a.png",
+			maxSize:  100000,
+			maxDim:   399,
+		},
+		{
+			name:     "max_dimension",
+			c:        adapters.ProviderTranscode{Formats: []string{"image/jpeg"}, Transcoder: adapters.ImageTranscoder{MaxDimension: 100}},
+			filename: "a.png",
+			data:     pngData.Bytes(),
+			want:     "a.jpg",
+			maxDim:   100,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockProviderGenSync{responses: []genai.Result{{}}}
+			if tc.c.Provider == nil {
+				tc.c.Provider = mock
+			} else {
+				mock = &tc.c.Provider.(*mockProviderScoreboard).mockProviderGenSync
+				mock.responses = []genai.Result{{}}
+			}
+			msgs := genai.Messages{
+				genai.NewTextMessage("describe"),
+				{Requests: []genai.Request{{Doc: genai.Doc{Filename: tc.filename, Src: bytes.NewReader(tc.data)}}}},
+			}
+			if _, err := tc.c.GenSync(t.Context(), msgs); err != nil {
+				t.Fatal(err)
+			}
+			if msgs[1].Requests[0].Doc.Filename != tc.filename {
+				t.Fatal("caller's messages were modified")
+			}
+			d := mock.msgs[1].Requests[0].Doc
+			if got := d.GetFilename(); got != tc.want {
+				t.Fatalf("filename = %q, want %q", got, tc.want)
+			}
+			b, err := io.ReadAll(d.Src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.maxSize != 0 && len(b) > tc.maxSize {
+				t.Fatalf("size %d > %d", len(b), tc.maxSize)
+			}
+			if tc.maxDim != 0 {
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(b))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if cfg.Width > tc.maxDim || cfg.Height > tc.maxDim {
+					t.Fatalf("size %dx%d > %d", cfg.Width, cfg.Height, tc.maxDim)
+				}
+			}
+		})
+	}
+	t.Run("error", func(t *testing.T) {
+		c := adapters.ProviderTranscode{Provider: &mockProviderGenSync{}, Formats: []string{"image/webp"}}
+		msgs := genai.Messages{{Requests: []genai.Request{{Doc: genai.Doc{Filename: "a.png", Src: bytes.NewReader(pngData.Bytes())}}}}}
+		if _, err := c.GenSync(t.Context(), msgs); err == nil {
+			t.Fatal("expected error")
+		}
+		_, finish := c.GenStream(t.Context(), msgs)
+		if _, err := finish(); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestImageTranscoder(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	b := bytes.Buffer{}
+	if err := png.Encode(&b, src); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := (adapters.ImageTranscoder{}).Transcode("image/png", b.Bytes(), []string{"image/png"}, 10); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, _, err := (adapters.ImageTranscoder{}).Transcode("image/png", []byte("bad"), []string{"image/png"}, 1000); err == nil {
+		t.Fatal("expected error")
+	}
+	mimeType, data, err := (adapters.ImageTranscoder{MaxDimension: 1}).Transcode("image/png", b.Bytes(), []string{"image/png"}, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mimeType != "image/png" {
+		t.Fatal(mimeType)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The red pixel is averaged with the 3 transparent ones.
+	if got := color.RGBAModel.Convert(img.At(0, 0)).(color.RGBA); got != (color.RGBA{R: 63, A: 63}) {
+		t.Fatalf("unexpected pixel %v", got)
+	}
+}
+
+type mockProviderScoreboard struct {
+	mockProviderGenSync
+	formats []string
+	maxSize int64
+}
+
+func (m *mockProviderScoreboard) Scoreboard() scoreboard.Score {
+	in := map[scoreboard.Modality]scoreboard.ModalCapability{
+		scoreboard.ModalityImage: {Inline: true, MaxSize: m.maxSize, SupportedFormats: m.formats},
+		scoreboard.ModalityText:  {Inline: true},
+	}
+	return scoreboard.Score{Scenarios: []scoreboard.Scenario{{Models: []string{"llm-sota"}, In: in}}}
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Sliding window truncation of the conversation history.
+
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/maruel/genai"
+)
+
+// ProviderSlidingWindow wraps a Provider and drops the older turns of the history so the request fits in the
+// model context.
+//
+// It is a lighter-weight alternative to CompactHistory: nothing is summarized. The first KeepFirst and the
+// last KeepLast messages are always sent and whole turns are dropped in between, oldest first. A tool call is
+// never separated from its result and the roles keep alternating. The system prompt, set with
+// genai.GenOptionText.SystemPrompt, is not part of the messages so it is always preserved.
+type ProviderSlidingWindow struct {
+	genai.Provider
+
+	// KeepFirst is the number of messages kept at the start of the history, generally the initial task.
+	KeepFirst int
+	// KeepLast is the minimum number of most recent messages kept. Defaults to 1.
+	KeepLast int
+	// ContextTokens is the model's context window. When 0, it is retrieved once with ListModels.
+	ContextTokens int64
+	// CountTokens returns the number of input tokens of a request. Defaults to an estimate of one token per 4
+	// bytes of text, plus genai.EstimateImageTokens when the provider supports it.
+	//
+	// Set it to the provider's token counting API to be accurate, e.g. with the anthropic client:
+	//
+	//	CountTokens: func(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (int64, error) {
+	//		resp, err := c.CountTokens(ctx, msgs, opts...)
+	//		if err != nil {
+	//			return 0, err
+	//		}
+	//		return resp.InputTokens, nil
+	//	},
+	CountTokens func(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (int64, error)
+
+	mu     sync.Mutex
+	tokens int64
+}
+
+// GenSync implements genai.Provider.
+func (c *ProviderSlidingWindow) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	msgs, err := c.Truncate(ctx, msgs, opts...)
+	if err != nil {
+		return genai.Result{}, err
+	}
+	return c.Provider.GenSync(ctx, msgs, opts...)
+}
+
+// GenStream implements genai.Provider.
+func (c *ProviderSlidingWindow) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	msgs, err := c.Truncate(ctx, msgs, opts...)
+	if err != nil {
+		return func(yield func(genai.Reply) bool) {}, func() (genai.Result, error) { return genai.Result{}, err }
+	}
+	return c.Provider.GenStream(ctx, msgs, opts...)
+}
+
+// Truncate returns the messages that would be sent for msgs.
+//
+// The output tokens requested with genai.GenOptionText.MaxTokens are reserved from the context. msgs is not
+// modified; it is returned as-is when it fits.
+func (c *ProviderSlidingWindow) Truncate(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Messages, error) {
+	if c.KeepFirst < 0 || c.KeepLast < 0 {
+		return nil, errors.New("KeepFirst and KeepLast must be positive")
+	}
+	limit, err := c.contextTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range opts {
+		if v, ok := o.(*genai.GenOptionText); ok {
+			limit -= v.MaxTokens
+		}
+	}
+	count := c.CountTokens
+	if count == nil {
+		count = func(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (int64, error) {
+			n := estimateTokens(msgs)
+			if i, err := genai.EstimateImageTokens(c.Provider, msgs); err == nil {
+				n += i
+			}
+			return n, nil
+		}
+	}
+	n, err := count(ctx, msgs, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	if n <= limit {
+		return msgs, nil
+	}
+
+	// The candidate start indexes of the tail, from the longest to the shortest history.
+	keepLast := max(c.KeepLast, 1)
+	var starts []int
+	for s := c.KeepFirst + 1; s <= len(msgs)-keepLast; s++ {
+		if len(msgs[s].ToolCallResults) != 0 {
+			continue
+		}
+		if c.KeepFirst == 0 {
+			// Most providers require the first message to be from the user.
+			if msgs[s].Role() != "user" {
+				continue
+			}
+		} else if msgs[s].Role() == msgs[c.KeepFirst-1].Role() {
+			continue
+		}
+		starts = append(starts, s)
+	}
+	// Token counts decrease as more is dropped, so binary search for the longest history that fits. This
+	// minimizes the calls to CountTokens when it is remote.
+	var errCount error
+	i := sort.Search(len(starts), func(i int) bool {
+		if errCount != nil {
+			return true
+		}
+		n, err := count(ctx, window(msgs, c.KeepFirst, starts[i]), opts...)
+		if err != nil {
+			errCount = err
+			return true
+		}
+		return n <= limit
+	})
+	if errCount != nil {
+		return nil, fmt.Errorf("failed to count tokens: %w", errCount)
+	}
+	if i == len(starts) {
+		return nil, fmt.Errorf("history doesn't fit in %d tokens even after dropping the older turns", limit)
+	}
+	return window(msgs, c.KeepFirst, starts[i]), nil
+}
+
+func (c *ProviderSlidingWindow) Unwrap() genai.Provider {
+	return c.Provider
+}
+
+func (c *ProviderSlidingWindow) contextTokens(ctx context.Context) (int64, error) {
+	if c.ContextTokens != 0 {
+		return c.ContextTokens, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == 0 {
+		n, err := modelContext(ctx, c.Provider)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the model context: %w", err)
+		}
+		c.tokens = n
+	}
+	return c.tokens, nil
+}
+
+// window returns the first messages up to first and the messages starting at start.
+func window(msgs genai.Messages, first, start int) genai.Messages {
+	return append(slices.Clip(msgs[:first]), msgs[start:]...)
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the sliding window adapter.
+
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+)
+
+func TestProviderSlidingWindow(t *testing.T) {
+	user := func(s string) genai.Message { return genai.NewTextMessage(s) }
+	asst := func(s string) genai.Message { return genai.Message{Replies: []genai.Reply{{Text: s}}} }
+	call := genai.Message{Replies: []genai.Reply{{ToolCall: genai.ToolCall{ID: "1", Name: "ls", Arguments: "{}"}}}}
+	result := genai.Message{ToolCallResults: []genai.ToolCallResult{{ID: "1", Name: "ls", Result: "r"}}}
+	// Each message counts as one token.
+	count := func(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (int64, error) {
+		return int64(len(msgs)), nil
+	}
+	tests := []struct {
+		name      string
+		first     int
+		last      int
+		limit     int64
+		opts      []genai.GenOption
+		msgs      genai.Messages
+		want      string
+		errMsg    string
+		withCount bool
+	}{
+		{name: "fits", limit: 10, msgs: genai.Messages{user("a"), asst("b")}, want: "a,b"},
+		{name: "drop_oldest", limit: 3, msgs: genai.Messages{user("a"), asst("b"), user("c"), asst("d"), user("e")}, want: "c,d,e"},
+		{name: "keep_first", first: 1, limit: 3, msgs: genai.Messages{user("a"), asst("b"), user("c"), asst("d"), user("e")}, want: "a,d,e"},
+		{name: "max_tokens", first: 1, limit: 5, opts: []genai.GenOption{&genai.GenOptionText{MaxTokens: 2}}, msgs: genai.Messages{user("a"), asst("b"), user("c"), asst("d"), user("e")}, want: "a,d,e"},
+		{
+			name:  "tool_pair",
+			first: 1,
+			limit: 4,
+			msgs:  genai.Messages{user("a"), asst("b"), user("c"), call, result, asst("d")},
+			// Starting at result is invalid so the call is kept.
+			want: "a,ls(),ls=r,d",
+		},
+		{name: "keep_last", limit: 2, last: 3, msgs: genai.Messages{user("a"), asst("b"), user("c"), asst("d"), user("e")}, errMsg: "history doesn't fit in 2 tokens even after dropping the older turns"},
+		{name: "invalid", first: -1, limit: 1, msgs: genai.Messages{user("a")}, errMsg: "KeepFirst and KeepLast must be positive"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &mockProviderModels{mockProviderGenSync: mockProviderGenSync{responses: []genai.Result{{}}}}
+			p := &adapters.ProviderSlidingWindow{Provider: m, KeepFirst: tc.first, KeepLast: tc.last, ContextTokens: tc.limit, CountTokens: count}
+			_, err := p.GenSync(t.Context(), tc.msgs, tc.opts...)
+			if tc.errMsg != "" {
+				if err == nil || err.Error() != tc.errMsg {
+					t.Fatalf("error mismatch\nwant %q\ngot  %v", tc.errMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for i := range m.msgs {
+				msg := &m.msgs[i]
+				switch {
+				case len(msg.ToolCallResults) != 0:
+					got = append(got, msg.ToolCallResults[0].Name+"="+msg.ToolCallResults[0].Result)
+				case len(msg.Replies) != 0 && msg.Replies[0].ToolCall.Name != "":
+					got = append(got, msg.Replies[0].ToolCall.Name+"()")
+				default:
+					got = append(got, msg.String())
+				}
+			}
+			if s := strings.Join(got, ","); s != tc.want {
+				t.Fatalf("got %q, want %q", s, tc.want)
+			}
+		})
+	}
+	t.Run("ListModels", func(t *testing.T) {
+		m := &mockProviderModels{models: []genai.Model{&mockModel{id: "llm-sota", ctx: 10}}}
+		p := &adapters.ProviderSlidingWindow{Provider: m}
+		msgs := genai.Messages{user(strings.Repeat("a", 100)), asst("b"), user("c")}
+		for range 2 {
+			got, err := p.Truncate(t.Context(), msgs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || got[0].String() != "c" {
+				t.Fatalf("unexpected %v", got)
+			}
+		}
+		if m.listCalls != 1 {
+			t.Fatalf("got %d ListModels calls, want 1", m.listCalls)
+		}
+	})
+	t.Run("CountTokens_error", func(t *testing.T) {
+		p := &adapters.ProviderSlidingWindow{
+			Provider:      &mockProviderModels{},
+			ContextTokens: 10,
+			CountTokens: func(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (int64, error) {
+				return 0, errors.New("boom")
+			},
+		}
+		if _, err := p.Truncate(t.Context(), genai.Messages{user("a")}); err == nil || err.Error() != "failed to count tokens: boom" {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
@@ -10,17 +10,25 @@ package base
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"iter"
+	"log/slog"
+	"maps"
 	"math"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"os"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/maruel/httpjson"
@@ -32,18 +40,96 @@ import (
 	"github.com/maruel/genai/internal/sse"
 )
 
+// DefaultHTTPTransport is the connection pool shared by the clients using DefaultTransport.
+//
+// It is a clone of http.DefaultTransport that keeps more idle connections per host, checks the health of
+// HTTP/2 connections and caches TLS sessions for resumption. Tune it before creating the clients, or use
+// genai.ProviderOptionHTTP to give a client its own connection pool.
+var DefaultHTTPTransport = NewHTTPTransport(genai.ProviderOptionHTTP{
+	MaxIdleConnsPerHost:  16,
+	HTTP2SendPingTimeout: 30 * time.Second,
+	HTTP2PingTimeout:     15 * time.Second,
+	TLSSessionCacheSize:  64,
+})
+
 // DefaultTransport integrates HTTP retries.
 //
 // It uses a quite long retry count. If latency matters for you, you may want to use a shorter retry policy.
 // Do this by passing the `wrapper` argument in the `New()` function and ignore the `http.RoundTripper` passed
 // in.
-var DefaultTransport http.RoundTripper = &roundtrippers.Retry{
-	Transport: http.DefaultTransport,
-	Policy: &roundtrippers.ExponentialBackoff{
-		MaxTryCount: 10,
-		MaxDuration: 60 * time.Second,
-		Exp:         1.5,
-	},
+//
+// Each attempt honors genai.GenOptionTimeout.Request and retries are logged when genai.ProviderOptionLogger is
+// set. A custom transport must wrap NewAttemptTransport to do the same.
+var DefaultTransport http.RoundTripper = newRetryTransport(DefaultHTTPTransport)
+
+// NewTransport returns DefaultTransport when neither opts nor a proxy is specified, otherwise a transport
+// with the same retry policy over its own connection pool.
+//
+// opts tunes the connection pool, it defaults to the settings of DefaultHTTPTransport. proxy is the proxy URL;
+// when empty, the environment variable proxyEnvVar is used if set. Otherwise the proxy is selected by the
+// HTTPS_PROXY and NO_PROXY environment variables.
+func NewTransport(opts *genai.ProviderOptionHTTP, proxy genai.ProviderOptionProxyURL, proxyEnvVar string) http.RoundTripper {
+	if proxy == "" && proxyEnvVar != "" {
+		proxy = genai.ProviderOptionProxyURL(os.Getenv(proxyEnvVar))
+	}
+	if opts == nil && proxy == "" {
+		return DefaultTransport
+	}
+	var h *http.Transport
+	if opts != nil {
+		h = NewHTTPTransport(*opts)
+	} else {
+		h = DefaultHTTPTransport.Clone()
+	}
+	if proxy != "" {
+		// Surface an invalid environment variable on the requests instead of silently ignoring the proxy.
+		u, err := url.Parse(string(proxy))
+		if err == nil {
+			err = proxy.Validate()
+		}
+		if err != nil && proxyEnvVar != "" {
+			err = fmt.Errorf("%s: %w", proxyEnvVar, err)
+		}
+		h.Proxy = func(*http.Request) (*url.URL, error) {
+			return u, err
+		}
+	}
+	return newRetryTransport(h)
+}
+
+// NewHTTPTransport returns a clone of http.DefaultTransport tuned with opts.
+func NewHTTPTransport(opts genai.ProviderOptionHTTP) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.MaxIdleConnsPerHost != 0 {
+		t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+		t.MaxIdleConns = max(t.MaxIdleConns, opts.MaxIdleConnsPerHost)
+	}
+	t.MaxConnsPerHost = opts.MaxConnsPerHost
+	if opts.IdleConnTimeout != 0 {
+		t.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	if opts.HTTP2SendPingTimeout != 0 || opts.HTTP2PingTimeout != 0 {
+		t.HTTP2 = &http.HTTP2Config{
+			SendPingTimeout: opts.HTTP2SendPingTimeout,
+			PingTimeout:     opts.HTTP2PingTimeout,
+		}
+	}
+	if opts.TLSSessionCacheSize != 0 {
+		// Setting TLSClientConfig doesn't disable HTTP/2 since ForceAttemptHTTP2 is inherited.
+		t.TLSClientConfig = &tls.Config{ClientSessionCache: tls.NewLRUClientSessionCache(opts.TLSSessionCacheSize)}
+	}
+	return t
+}
+
+func newRetryTransport(t http.RoundTripper) http.RoundTripper {
+	return &roundtrippers.Retry{
+		Transport: NewAttemptTransport(t),
+		Policy: &roundtrippers.ExponentialBackoff{
+			MaxTryCount: 10,
+			MaxDuration: 60 * time.Second,
+			Exp:         1.5,
+		},
+	}
 }
 
 // CheckDuplicateOptions returns an error if the same ProviderOption concrete type appears more than once.
@@ -97,6 +183,33 @@ func (e *ErrNotSupported) Error() string {
 	return "not supported: " + strings.Join(e.Options, ", ")
 }
 
+// ErrIdleTimeout is returned by GenStream when the server didn't send any data for longer than
+// genai.GenOptionTimeout.Idle. The request can be retried.
+type ErrIdleTimeout struct {
+	Idle time.Duration
+}
+
+func (e *ErrIdleTimeout) Error() string {
+	return fmt.Sprintf("stream idle for more than %s", e.Idle)
+}
+
+// ErrUnhandled is returned when the provider's response contains a value the client doesn't handle, e.g. an
+// unknown finish reason or stream event.
+//
+// The result is still usable. It is ignored by lenient clients, the default, and is wrapped in a
+// *internal.BadError by clients created with genai.ProviderOptionStrict(true).
+type ErrUnhandled struct {
+	Err error
+}
+
+func (e *ErrUnhandled) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ErrUnhandled) Unwrap() error {
+	return e.Err
+}
+
 // NotImplemented implements remote genai.Provider methods, all returning ErrNotSupported.
 type NotImplemented struct{}
 
@@ -153,10 +266,10 @@ func (*NotImplemented) Capabilities() genai.ProviderCapabilities {
 type ProviderBase[PErrorResponse ErrAPI] struct {
 	// Client is exported for testing replay purposes.
 	Client http.Client
-	// Lenient allows unknown fields in the response.
+	// Lenient allows unknown fields and unhandled values, like unknown finish reasons, in the response.
 	//
 	// This inhibits from calling DisallowUnknownFields() on the JSON decoder, which will generally return a
-	// *UnknownFieldError.
+	// *UnknownFieldError, and makes StrictErr drop ErrUnhandled.
 	//
 	// Use this in production so that your client doesn't break when the server
 	// add new fields.
@@ -169,6 +282,8 @@ type ProviderBase[PErrorResponse ErrAPI] struct {
 	OutputModalities genai.Modalities
 	// ModelOptional is true if a model name is not required to use the provider.
 	ModelOptional bool
+	// Log is the logger set via genai.ProviderOptionLogger. Logging is disabled when Log.Logger is nil.
+	Log genai.ProviderOptionLogger
 
 	// mu protects errorResponse and lastResp.
 	mu sync.Mutex
@@ -185,7 +300,27 @@ type ProviderBase[PErrorResponse ErrAPI] struct {
 // It initiates the requests and returns the response back for further processing.
 // Buffers post data in memory.
 func (c *ProviderBase[PErrorResponse]) JSONRequest(ctx context.Context, method, url string, in any) (*http.Response, error) {
+	if t, ok := ctx.Value(timeoutKey{}).(*genai.GenOptionTimeout); ok {
+		if err := t.Validate(); err != nil {
+			return nil, fmt.Errorf("GenOptionTimeout: %w", err)
+		}
+		if t.Total > 0 {
+			ctx, cancel := context.WithTimeout(ctx, t.Total)
+			resp, err := c.jsonRequest(ctx, method, url, in)
+			if err != nil {
+				cancel()
+				return resp, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+	}
+	return c.jsonRequest(ctx, method, url, in)
+}
+
+func (c *ProviderBase[PErrorResponse]) jsonRequest(ctx context.Context, method, url string, in any) (*http.Response, error) {
 	var b io.Reader
+	var body []byte
 	if in != nil {
 		buf := &bytes.Buffer{}
 		e := json.NewEncoder(buf)
@@ -194,6 +329,13 @@ func (c *ProviderBase[PErrorResponse]) JSONRequest(ctx context.Context, method,
 		if err := e.Encode(in); err != nil {
 			return nil, fmt.Errorf("internal error: %w", err)
 		}
+		if raw, ok := ctx.Value(rawOptionsKey{}).([]*genai.GenOptionRaw); ok {
+			var err error
+			if buf, err = mergeRawOptions(buf.Bytes(), raw); err != nil {
+				return nil, err
+			}
+		}
+		body = buf.Bytes()
 		b = buf
 	}
 	req, err := http.NewRequestWithContext(ctx, method, url, b)
@@ -201,11 +343,248 @@ func (c *ProviderBase[PErrorResponse]) JSONRequest(ctx context.Context, method,
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if hdrs, ok := ctx.Value(rawHeadersKey{}).([]genai.GenOptionHeaders); ok {
+		for _, h := range hdrs {
+			if err := h.Validate(); err != nil {
+				return nil, fmt.Errorf("GenOptionHeaders: %w", err)
+			}
+			for k, v := range h {
+				req.Header[http.CanonicalHeaderKey(k)] = v
+			}
+		}
+	}
+	capture, ok := ctx.Value(captureKey{}).(genai.GenOptionCapture)
+	if ok {
+		if err := capture.Validate(); err != nil {
+			return nil, fmt.Errorf("GenOptionCapture: %w", err)
+		}
+	}
+	var start time.Time
+	if c.Log.Logger != nil {
+		req = req.WithContext(context.WithValue(ctx, attemptsKey{}, &attempts{log: c.Log}))
+		c.Log.Logger.Log(ctx, c.Log.Level, "http request", "method", method, "url", url)
+		start = time.Now()
+	}
 	resp, err := c.Client.Do(req)
 	// This is a good place to debug if there's an HTTP recording problem.
+	if c.Log.Logger != nil {
+		dur := time.Since(start).Round(time.Millisecond)
+		switch {
+		case err != nil:
+			c.Log.Logger.Log(ctx, slog.LevelError, "http response", "url", url, "dur", dur, "err", err)
+		case resp.StatusCode >= 400:
+			c.Log.Logger.Log(ctx, slog.LevelError, "http response", "url", url, "dur", dur, "status", resp.StatusCode)
+		default:
+			c.Log.Logger.Log(ctx, c.Log.Level, "http response", "url", url, "dur", dur, "status", resp.StatusCode)
+		}
+	}
+	if ok {
+		ex := &genai.HTTPExchange{Method: method, URL: url, Request: body}
+		if err != nil {
+			ex.Err = err
+			capture(ex)
+		} else {
+			ex.StatusCode = resp.StatusCode
+			ex.Header = resp.Header
+			resp.Body = &captureBody{ReadCloser: resp.Body, ex: ex, capture: capture}
+		}
+	}
 	return resp, err
 }
 
+type rawOptionsKey struct{}
+
+type rawHeadersKey struct{}
+
+type captureKey struct{}
+
+type attemptsKey struct{}
+
+// attempts counts the attempts done by the retry transport for a single request.
+type attempts struct {
+	log genai.ProviderOptionLogger
+	n   atomic.Int32
+}
+
+type timeoutKey struct{}
+
+// ExtractRawOptions removes the *genai.GenOptionRaw, genai.GenOptionHeaders, *genai.GenOptionTimeout and
+// genai.GenOptionCapture from opts. The returned context makes JSONRequest merge their fields into the request
+// body, add their headers to the request, enforce the timeouts and capture the exchanges.
+//
+// Providers that do not rely on Provider.GenSync and Provider.GenStream should call it before initializing
+// their request.
+func ExtractRawOptions(ctx context.Context, opts []genai.GenOption) (context.Context, []genai.GenOption) {
+	var raw []*genai.GenOptionRaw
+	var hdrs []genai.GenOptionHeaders
+	var out []genai.GenOption
+	for _, o := range opts {
+		switch v := o.(type) {
+		case *genai.GenOptionRaw:
+			raw = append(raw, v)
+		case genai.GenOptionHeaders:
+			hdrs = append(hdrs, v)
+		case *genai.GenOptionTimeout:
+			ctx = context.WithValue(ctx, timeoutKey{}, v)
+		case genai.GenOptionCapture:
+			ctx = context.WithValue(ctx, captureKey{}, v)
+		default:
+			out = append(out, o)
+		}
+	}
+	if len(raw) != 0 {
+		ctx = context.WithValue(ctx, rawOptionsKey{}, raw)
+	}
+	if len(hdrs) != 0 {
+		ctx = context.WithValue(ctx, rawHeadersKey{}, hdrs)
+	}
+	if len(out) == len(opts) {
+		return ctx, opts
+	}
+	return ctx, out
+}
+
+// mergeRawOptions merges the fields of the options into the JSON object b.
+func mergeRawOptions(b []byte, raw []*genai.GenOptionRaw) (*bytes.Buffer, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var m map[string]any
+	if err := d.Decode(&m); err != nil || m == nil {
+		return nil, errors.New("GenOptionRaw requires a JSON object request")
+	}
+	for _, r := range raw {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("GenOptionRaw: %w", err)
+		}
+		mergeJSON(m, r.Fields)
+	}
+	buf := &bytes.Buffer{}
+	e := json.NewEncoder(buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(m); err != nil {
+		return nil, fmt.Errorf("GenOptionRaw: %w", err)
+	}
+	return buf, nil
+}
+
+// NewAttemptTransport returns a transport enforcing genai.GenOptionTimeout.Request on each request sent
+// through it and logging the retries.
+//
+// It must be wrapped by the retry transport so that each attempt gets its own deadline.
+func NewAttemptTransport(t http.RoundTripper) http.RoundTripper {
+	return &attemptTransport{t: t}
+}
+
+type attemptTransport struct {
+	t http.RoundTripper
+}
+
+func (a *attemptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if l, ok := req.Context().Value(attemptsKey{}).(*attempts); ok {
+		if n := l.n.Add(1); n > 1 {
+			l.log.Logger.Log(req.Context(), l.log.Level, "http retry", "url", req.URL.String(), "attempt", n)
+		}
+	}
+	t, ok := req.Context().Value(timeoutKey{}).(*genai.GenOptionTimeout)
+	if !ok || t.Request <= 0 {
+		return a.t.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.Request)
+	resp, err := a.t.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// idleBody closes the response body when no data was received for the idle duration, unblocking the
+// pending Read.
+type idleBody struct {
+	io.ReadCloser
+	idle  time.Duration
+	timer *time.Timer
+	fired atomic.Bool
+}
+
+func newIdleBody(b io.ReadCloser, idle time.Duration) *idleBody {
+	i := &idleBody{ReadCloser: b, idle: idle}
+	i.timer = time.AfterFunc(idle, func() {
+		i.fired.Store(true)
+		_ = b.Close()
+	})
+	return i
+}
+
+func (i *idleBody) Read(p []byte) (int, error) {
+	n, err := i.ReadCloser.Read(p)
+	if n > 0 {
+		i.timer.Reset(i.idle)
+	}
+	if err != nil && i.fired.Load() {
+		err = &ErrIdleTimeout{Idle: i.idle}
+	}
+	return n, err
+}
+
+func (i *idleBody) Close() error {
+	i.timer.Stop()
+	return i.ReadCloser.Close()
+}
+
+// captureBody records the response body as it is read and reports the exchange once it is closed.
+type captureBody struct {
+	io.ReadCloser
+	ex      *genai.HTTPExchange
+	capture genai.GenOptionCapture
+	buf     bytes.Buffer
+	once    sync.Once
+}
+
+func (c *captureBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.buf.Write(p[:n])
+	return n, err
+}
+
+func (c *captureBody) Close() error {
+	err := c.ReadCloser.Close()
+	c.once.Do(func() {
+		c.ex.Response = c.buf.Bytes()
+		c.capture(c.ex)
+	})
+	return err
+}
+
+// cancelBody releases the context associated with the response once the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelBody) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+func mergeJSON(dst, src map[string]any) {
+	for k, v := range src {
+		if v == nil {
+			delete(dst, k)
+			continue
+		}
+		if sv, ok := v.(map[string]any); ok {
+			if dv, ok := dst[k].(map[string]any); ok {
+				mergeJSON(dv, sv)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
 // Validate checks that the provider is properly configured.
 func (c *ProviderBase[PErrorResponse]) Validate() error {
 	if !c.ModelOptional && c.Model == "" {
@@ -297,6 +676,39 @@ func (c *ProviderBase[PErrorResponse]) DecodeResponse(resp *http.Response, url s
 	return errors.Join(errs...)
 }
 
+// StrictErr processes the errors returned while converting a response.
+//
+// When the client is lenient, ErrUnhandled errors are dropped, otherwise they are wrapped in a
+// *internal.BadError. Other errors are returned as-is.
+func (c *ProviderBase[PErrorResponse]) StrictErr(err error) error {
+	return strictErr(err, c.Lenient)
+}
+
+func strictErr(err error, lenient bool) error {
+	if err == nil {
+		return nil
+	}
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range j.Unwrap() {
+			if e = strictErr(e, lenient); e != nil {
+				errs = append(errs, e)
+			}
+		}
+		if len(errs) == 1 {
+			return errs[0]
+		}
+		return errors.Join(errs...)
+	}
+	if _, ok := errors.AsType[*ErrUnhandled](err); ok {
+		if lenient {
+			return nil
+		}
+		return &internal.BadError{Err: err}
+	}
+	return err
+}
+
 // DecodeError handles HTTP error responses from API calls.
 //
 // It handles JSON decoding of error responses and provides appropriate error messages
@@ -343,6 +755,31 @@ func (c *ProviderBase[PErrorResponse]) DecodeError(url string, resp *http.Respon
 	return errors.Join(errs...)
 }
 
+// LogResult logs the result of a generation started at start, including the model, the token usage and the
+// rate limits.
+//
+// It is a no-op when logging is disabled.
+func (c *ProviderBase[PErrorResponse]) LogResult(ctx context.Context, op string, start time.Time, res *genai.Result, err error) {
+	if c.Log.Logger == nil {
+		return
+	}
+	args := []any{
+		"model", c.Model,
+		"dur", time.Since(start).Round(time.Millisecond),
+		"in", res.Usage.InputTokens,
+		"out", res.Usage.OutputTokens,
+		"finish", res.Usage.FinishReason,
+	}
+	if len(res.Usage.Limits) != 0 {
+		args = append(args, "limits", res.Usage.Limits)
+	}
+	if err != nil {
+		c.Log.Logger.Log(ctx, slog.LevelError, op, append(args, "err", err)...)
+		return
+	}
+	c.Log.Logger.Log(ctx, c.Log.Level, op, args...)
+}
+
 func (c *ProviderBase[PErrorResponse]) lateInit() {
 	// TODO: Figure out how to not use reflection.
 	c.mu.Lock()
@@ -389,11 +826,23 @@ type Provider[PErrorResponse ErrAPI, PGenRequest InitializableRequest, PGenRespo
 	ProcessStream func(it iter.Seq[GenStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error))
 	// ProcessHeaders is the function that processes HTTP headers to extract rate limit information.
 	ProcessHeaders func(http.Header) []genai.RateLimit
+	// DecodeStream decodes the streaming response body into packets. It defaults to decoding Server-Sent
+	// Events. er is used to decode error packets.
+	DecodeStream func(body io.Reader, er error, lenient bool) (iter.Seq[GenStreamChunkResponse], func() error)
 	// LieToolCalls lie the FinishReason on tool calls.
 	LieToolCalls bool
+	// SchemaEnforced is set when the provider constrains the reply to GenOptionText.DecodeAs server side. Otherwise
+	// the reply is validated client side when GenOptionText.StrictSchema is set.
+	SchemaEnforced bool
 	// PreloadedModels is a list of preloaded models provided by the user to save on HTTP requests for
 	// ListModels.
 	PreloadedModels []genai.Model
+	// ModelSelector replaces the provider's heuristics for automatic model selection when set. Use SelectModel.
+	ModelSelector genai.ProviderOptionModelSelector
+	// ModelPin is the file freezing the automatic model selection. Use AutoSelectModel.
+	ModelPin string
+	// Selection is the automatic model selection decision, set by AutoSelectModel.
+	Selection genai.ModelSelection
 
 	// Protected by Base.mu.
 	chatRequest  reflect.Type
@@ -402,8 +851,20 @@ type Provider[PErrorResponse ErrAPI, PGenRequest InitializableRequest, PGenRespo
 
 // GenSync implements genai.Provider.
 func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkResponse]) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	start := time.Now()
+	sink, opts := ExtractOutputOption(opts)
+	res, err := c.genSync(ctx, msgs, opts...)
+	if err == nil {
+		err = sink.Redirect(&res)
+	}
+	c.LogResult(ctx, "GenSync", start, &res, err)
+	return res, err
+}
+
+func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkResponse]) genSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
 	res := genai.Result{}
 	c.lateInit()
+	ctx, opts = ExtractRawOptions(ctx, opts)
 	in := reflect.New(c.chatRequest).Interface().(PGenRequest)
 	if err := in.Init(msgs, c.Model, opts...); err != nil {
 		return res, err
@@ -416,7 +877,7 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 	// request to overwrite lastResp.
 	lastResp := c.LastResponseHeaders()
 	res, err := out.ToResult()
-	if err != nil {
+	if err = c.StrictErr(err); err != nil {
 		return res, err
 	}
 	if err := res.Validate(); err != nil {
@@ -426,7 +887,10 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 	if c.ProcessHeaders != nil && lastResp != nil {
 		res.Usage.Limits = c.ProcessHeaders(lastResp)
 	}
-	return res, nil
+	if c.SchemaEnforced {
+		return res, nil
+	}
+	return res, checkReplySchema(opts, &res)
 }
 
 // GenStream implements genai.Provider.
@@ -435,7 +899,14 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 	var finalErr error
 
 	fnFragments := func(yield func(genai.Reply) bool) {
+		start := time.Now()
 		c.lateInit()
+		ctx, opts := ExtractRawOptions(ctx, opts)
+		sink, opts := ExtractOutputOption(opts)
+		if err := CheckStreamCandidates(opts); err != nil {
+			finalErr = err
+			return
+		}
 		in := reflect.New(c.chatRequest).Interface().(PGenRequest)
 		if err := in.Init(msgs, c.Model, opts...); err != nil {
 			finalErr = err
@@ -460,8 +931,8 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 				finalErr = &internal.BadError{Err: err}
 				break
 			}
-			if err := res.Accumulate(&f); err != nil {
-				finalErr = &internal.BadError{Err: err}
+			if err := sink.Accumulate(&res, &f); err != nil {
+				finalErr = err
 				break
 			}
 			sent = true
@@ -474,7 +945,7 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 		}
 		var err error
 		res.Usage, res.Logprobs, err = finish2()
-		if finalErr == nil {
+		if err = c.StrictErr(err); finalErr == nil {
 			finalErr = err
 		}
 		if !sent && finalErr == nil {
@@ -493,6 +964,7 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 				}
 			}
 		}
+		c.LogResult(ctx, "GenStream", start, &res, finalErr)
 	}
 	fnFinish := func() (genai.Result, error) {
 		if finalErr != nil {
@@ -502,11 +974,30 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 			// Catch provider implementation bugs.
 			return res, &internal.BadError{Err: err}
 		}
-		return res, nil
+		if c.SchemaEnforced {
+			return res, nil
+		}
+		return res, checkReplySchema(opts, &res)
 	}
 	return fnFragments, fnFinish
 }
 
+// checkReplySchema validates the reply against GenOptionText.DecodeAs when GenOptionText.StrictSchema is set.
+func checkReplySchema(opts []genai.GenOption, res *genai.Result) error {
+	for _, opt := range opts {
+		v, ok := opt.(*genai.GenOptionText)
+		if !ok || !v.StrictSchema || v.DecodeAs == nil || res.Usage.FinishReason == genai.FinishedToolCalls {
+			continue
+		}
+		js, err := v.DecodeSchema()
+		if err != nil {
+			return err
+		}
+		return ValidateSchema(js, []byte(res.String()))
+	}
+	return nil
+}
+
 // GenSyncRaw is the generic raw implementation for the generation API endpoint.
 // It sets Stream to false and sends a request to the chat URL.
 func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkResponse]) GenSyncRaw(ctx context.Context, in PGenRequest, out PGenResponse) error {
@@ -518,7 +1009,7 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 }
 
 // GenStreamRaw is the generic raw implementation for streaming Gen API endpoints.
-// It sets Stream to true, enables stream options if available, and handles the SSE response.
+// It sets Stream to true, enables stream options if available, and decodes the response with DecodeStream.
 func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkResponse]) GenStreamRaw(ctx context.Context, in PGenRequest) (iter.Seq[GenStreamChunkResponse], func() error) {
 	// Normally this shouldn't be needed here but gemini calls this function directly.
 	c.lateInit()
@@ -551,6 +1042,11 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 	c.mu.Lock()
 	c.lastResp = resp.Header
 	c.mu.Unlock()
+	var idle *idleBody
+	if t, ok := ctx.Value(timeoutKey{}).(*genai.GenOptionTimeout); ok && t.Idle > 0 {
+		idle = newIdleBody(resp.Body, t.Idle)
+		resp.Body = idle
+	}
 
 	// Process the stream in a separate goroutine to make sure that when the client iterate, there is already a
 	// packet waiting for it. This reduces the overall latency.
@@ -559,12 +1055,20 @@ func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkRespo
 	go func() {
 		defer func() { _ = resp.Body.Close() }()
 		er := reflect.New(c.errorResponse).Interface().(PErrorResponse)
-		it, finish := sse.Process[GenStreamChunkResponse](resp.Body, er, c.Lenient)
+		decode := c.DecodeStream
+		if decode == nil {
+			decode = sse.Process[GenStreamChunkResponse]
+		}
+		it, finish := decode(resp.Body, er, c.Lenient)
 		for pkt := range it {
 			out <- pkt
 		}
 		close(out)
 		err := finish()
+		if idle != nil && idle.fired.Load() {
+			// The decoder may have wrapped the read error.
+			err = &ErrIdleTimeout{Idle: idle.idle}
+		}
 		ch <- err
 	}()
 
@@ -661,6 +1165,157 @@ func (f *Float64) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// ExtractPollOptions removes genai.GenOptionPollInterval and genai.GenOptionProgress from opts.
+//
+// interval is the default poll interval returned when opts doesn't specify one.
+func ExtractPollOptions(opts []genai.GenOption, interval time.Duration) (time.Duration, genai.GenOptionProgress, []genai.GenOption) {
+	var progress genai.GenOptionProgress
+	out := make([]genai.GenOption, 0, len(opts))
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case genai.GenOptionPollInterval:
+			interval = time.Duration(v)
+		case genai.GenOptionProgress:
+			progress = v
+		default:
+			out = append(out, opt)
+		}
+	}
+	return interval, progress, out
+}
+
+// OutputSink writes the generated documents to the files created by genai.GenOptionOutput.
+//
+// A nil *OutputSink keeps the documents in memory.
+type OutputSink struct {
+	opt     *genai.GenOptionOutput
+	cur     io.ReadWriteSeeker
+	written int64
+}
+
+// CheckStreamCandidates returns ErrNotSupported when opts request more than one candidate with
+// genai.GenOptionText.N, since the streamed fragments can only carry one.
+func CheckStreamCandidates(opts []genai.GenOption) error {
+	for _, o := range opts {
+		if v, ok := o.(*genai.GenOptionText); ok && v.N > 1 {
+			return &ErrNotSupported{Options: []string{"GenOptionText.N"}}
+		}
+	}
+	return nil
+}
+
+// ExtractOutputOption removes the *genai.GenOptionOutput from opts. The returned sink is nil when there was
+// none.
+func ExtractOutputOption(opts []genai.GenOption) (*OutputSink, []genai.GenOption) {
+	var sink *OutputSink
+	out := make([]genai.GenOption, 0, len(opts))
+	for _, opt := range opts {
+		if v, ok := opt.(*genai.GenOptionOutput); ok {
+			sink = &OutputSink{opt: v}
+		} else {
+			out = append(out, opt)
+		}
+	}
+	return sink, out
+}
+
+// Accumulate adds the fragment to res like genai.Result.Accumulate, except that the document content is
+// written to the sink's files instead of being accumulated in memory.
+func (o *OutputSink) Accumulate(res *genai.Result, f *genai.Reply) error {
+	if o == nil {
+		return res.Accumulate(f)
+	}
+	if f.Doc.Src == nil {
+		o.cur = nil
+		return res.Accumulate(f)
+	}
+	if o.cur == nil {
+		w, err := o.create(f.Doc.Filename)
+		if err != nil {
+			return err
+		}
+		o.cur = w
+		res.Replies = append(res.Replies, genai.Reply{Doc: genai.Doc{Filename: f.Doc.Filename, Src: w}})
+	}
+	if err := o.write(o.cur, f.Doc.Src); err != nil {
+		return err
+	}
+	// Rewind the fragment so the caller can still read it.
+	_, err := f.Doc.Src.Seek(0, io.SeekStart)
+	return err
+}
+
+// Redirect moves the content of the documents in res to the sink's files.
+func (o *OutputSink) Redirect(res *genai.Result) error {
+	if o == nil {
+		return nil
+	}
+	for i := range res.Replies {
+		d := &res.Replies[i].Doc
+		if d.Src == nil {
+			continue
+		}
+		w, err := o.create(d.Filename)
+		if err != nil {
+			return err
+		}
+		if err = o.write(w, d.Src); err != nil {
+			return err
+		}
+		d.Src = w
+	}
+	return nil
+}
+
+func (o *OutputSink) create(filename string) (io.ReadWriteSeeker, error) {
+	if o.opt.New != nil {
+		return o.opt.New(filename)
+	}
+	return os.CreateTemp("", "genai-*-"+filename)
+}
+
+// write appends the content of src to w, enforcing MaxSize, and leaves w rewound.
+func (o *OutputSink) write(w io.ReadWriteSeeker, src io.Reader) error {
+	if _, err := w.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	if o.opt.MaxSize > 0 {
+		src = io.LimitReader(src, o.opt.MaxSize-o.written+1)
+	}
+	n, err := io.Copy(w, src)
+	o.written += n
+	if err != nil {
+		return err
+	}
+	if o.opt.MaxSize > 0 && o.written > o.opt.MaxSize {
+		return fmt.Errorf("generated documents exceed GenOptionOutput.MaxSize of %d bytes", o.opt.MaxSize)
+	}
+	_, err = w.Seek(0, io.SeekStart)
+	return err
+}
+
+// PollJob calls poke every interval until the job is not pending anymore.
+//
+// poke returns the result, with FinishReason set to genai.Pending while the job is running, and the
+// provider-specific operation metadata. progress is optional and is called after each pending poll.
+func PollJob(ctx context.Context, id genai.Job, interval time.Duration, progress genai.GenOptionProgress, poke func(context.Context, genai.Job) (genai.Result, map[string]any, error)) (genai.Result, error) {
+	start := time.Now()
+	for polls := 1; ; polls++ {
+		select {
+		case <-ctx.Done():
+			return genai.Result{}, ctx.Err()
+		case <-time.After(interval):
+		}
+		res, md, err := poke(ctx, id)
+		if res.Usage.FinishReason != genai.Pending {
+			return res, err
+		}
+		if progress != nil {
+			progress(genai.JobProgress{Job: id, Polls: polls, Elapsed: time.Since(start), Metadata: md})
+		}
+	}
+}
+
 // SimulateStream simulates GenStream for APIs that do not support streaming.
 func SimulateStream(ctx context.Context, c genai.Provider, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
 	res := genai.Result{}
@@ -691,6 +1346,11 @@ func SimulateStream(ctx context.Context, c genai.Provider, msgs genai.Messages,
 								finalErr = err
 								return
 							}
+							// The Result keeps the original source, e.g. a file from GenOptionOutput.
+							if _, err = r.Doc.Src.Seek(0, io.SeekStart); err != nil {
+								finalErr = err
+								return
+							}
 							src = &bb.BytesBuffer{D: raw}
 						}
 						if !yield(genai.Reply{Doc: genai.Doc{Filename: r.Doc.Filename, Src: src}}) {
@@ -713,6 +1373,11 @@ func SimulateStream(ctx context.Context, c genai.Provider, msgs genai.Messages,
 						return
 					}
 				}
+				if !r.ServerToolCall.IsZero() {
+					if !yield(genai.Reply{ServerToolCall: r.ServerToolCall}) {
+						return
+					}
+				}
 			}
 		}
 	}
@@ -722,6 +1387,125 @@ func SimulateStream(ctx context.Context, c genai.Provider, msgs genai.Messages,
 	return fnFragments, fnFinish
 }
 
+// SelectModel calls the user provided selector to automatically select a model among models.
+func SelectModel(ctx context.Context, selector genai.ProviderOptionModelSelector, preference string, models []genai.Model) (string, error) {
+	m, err := selector(ctx, genai.ProviderOptionModel(preference), models)
+	if err != nil {
+		return "", fmt.Errorf("failed to automatically select the model: %w", err)
+	}
+	if m == "" {
+		return "", errors.New("failed to automatically select the model: ProviderOptionModelSelector returned an empty model")
+	}
+	return m, nil
+}
+
+// modelPinMu serializes the accesses to the files specified with genai.ProviderOptionModelPin.
+var modelPinMu sync.Mutex
+
+// AutoSelectModel resolves the automatic model selection preference: genai.ModelCheap, genai.ModelGood or
+// genai.ModelSOTA.
+//
+// It returns the model pinned in ModelPin for the provider name when there is one. Otherwise it calls
+// selectModel, which must honor ModelSelector, and pins the result. The decision is saved in Selection.
+func (c *Provider[PErrorResponse, PGenRequest, PGenResponse, GenStreamChunkResponse]) AutoSelectModel(ctx context.Context, name, preference string, selectModel func(ctx context.Context, preference string) (string, error)) (string, error) {
+	pref := genai.ProviderOptionModel(preference)
+	if c.ModelPin != "" {
+		// Keep the lock while selecting so concurrent clients agree on the model.
+		modelPinMu.Lock()
+		defer modelPinMu.Unlock()
+		pins, err := loadModelPins(c.ModelPin)
+		if err != nil {
+			return "", err
+		}
+		if m := pins[name][preference]; m != "" {
+			c.Selection = genai.ModelSelection{Preference: pref, Model: m, Reason: "pinned in " + c.ModelPin, Pinned: true}
+			return m, nil
+		}
+	}
+	m, err := selectModel(ctx, preference)
+	if err != nil {
+		return "", err
+	}
+	reason := "selected by the provider's heuristics"
+	if c.ModelSelector != nil {
+		reason = "selected by ProviderOptionModelSelector"
+	}
+	if c.ModelPin != "" {
+		if err = saveModelPin(c.ModelPin, name, preference, m); err != nil {
+			return "", err
+		}
+		reason += ", now pinned in " + c.ModelPin
+	}
+	c.Selection = genai.ModelSelection{Preference: pref, Model: m, Reason: reason}
+	return m, nil
+}
+
+// loadModelPins returns the models pinned in path, keyed by provider name and then preference. A missing file
+// is empty.
+func loadModelPins(path string) (map[string]map[string]string, error) {
+	pins := map[string]map[string]string{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return pins, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &pins); err != nil {
+		return nil, fmt.Errorf("failed to decode the model pins %s: %w", path, err)
+	}
+	return pins, nil
+}
+
+func saveModelPin(path, name, preference, model string) error {
+	pins, err := loadModelPins(path)
+	if err != nil {
+		return err
+	}
+	if pins[name] == nil {
+		pins[name] = map[string]string{}
+	}
+	pins[name][preference] = model
+	b, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write atomically so a crash never leaves a truncated file behind.
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ParseModalities converts the modality names returned by a provider's list of models, e.g. "text", "image"
+// or "file", to genai.Modalities. Unknown names are ignored. The result is sorted and deduplicated.
+func ParseModalities(names []string) genai.Modalities {
+	var out genai.Modalities
+	for _, n := range names {
+		var m genai.Modality
+		switch strings.ToLower(n) {
+		case "text":
+			m = genai.ModalityText
+		case "image":
+			m = genai.ModalityImage
+		case "audio":
+			m = genai.ModalityAudio
+		case "video":
+			m = genai.ModalityVideo
+		case "file", "pdf", "document":
+			m = genai.ModalityDocument
+		default:
+			continue
+		}
+		if !slices.Contains(out, m) {
+			out = append(out, m)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
 // MimeByExt wraps mime.TypeByExtension.
 //
 // It overrides audio entries because they vary surprisingly a lot across OSes!
@@ -729,5 +1513,113 @@ func MimeByExt(ext string) string {
 	return internal.MimeByExt(ext)
 }
 
+// MultipartFile returns a multipart/form-data body with the fields, followed by a file named filename
+// read from r under the form field name.
+//
+// r is streamed as the body is read, so large files don't need to fit in memory. getBody returns the body
+// and is meant to be passed to NewBodyRequest; see ReaderBody for its behavior. size is the exact body length
+// when r is an io.Seeker, -1 otherwise, in which case the request is not retried. boundary is optional and is useful to make the body deterministic for
+// HTTP playback.
+func MultipartFile(boundary string, fields map[string]string, name, filename string, r io.Reader) (getBody func() (io.ReadCloser, error), contentType string, size int64, err error) {
+	buf := bytes.Buffer{}
+	w := multipart.NewWriter(&buf)
+	if boundary != "" {
+		if err = w.SetBoundary(boundary); err != nil {
+			return nil, "", 0, err
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		if err = w.WriteField(k, fields[k]); err != nil {
+			return nil, "", 0, err
+		}
+	}
+	if _, err = w.CreateFormFile(name, filename); err != nil {
+		return nil, "", 0, err
+	}
+	head := bytes.Clone(buf.Bytes())
+	buf.Reset()
+	if err = w.Close(); err != nil {
+		return nil, "", 0, err
+	}
+	tail := buf.Bytes()
+	file, size, err := ReaderBody(r)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if size != -1 {
+		size += int64(len(head) + len(tail))
+	}
+	getBody = func() (io.ReadCloser, error) {
+		f, err := file()
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(io.MultiReader(bytes.NewReader(head), f, bytes.NewReader(tail))), nil
+	}
+	return getBody, w.FormDataContentType(), size, nil
+}
+
+// ReaderBody returns a function returning r as an HTTP request body and the number of bytes left in r.
+//
+// getBody is meant to be used as http.Request.GetBody, so that retries and redirects rewind r instead of
+// buffering it in memory. When r is an io.Seeker and an io.ReaderAt, like *os.File or *bytes.Reader, each
+// call returns an independent io.SectionReader starting at the current position of r, so a retry never races
+// with the previous attempt. When r is only an io.Seeker, each call seeks back to the current position of r.
+//
+// Otherwise size is -1 and the body is streamed once: it is not retried, and later calls return an error.
+func ReaderBody(r io.Reader) (getBody func() (io.ReadCloser, error), size int64, err error) {
+	s, ok := r.(io.Seeker)
+	if !ok {
+		var sent atomic.Bool
+		return func() (io.ReadCloser, error) {
+			if sent.Swap(true) {
+				return nil, errors.New("can't resend a body that is not an io.Seeker")
+			}
+			return io.NopCloser(r), nil
+		}, -1, nil
+	}
+	cur, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err = s.Seek(cur, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	if ra, ok := r.(io.ReaderAt); ok {
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(io.NewSectionReader(ra, cur, end-cur)), nil
+		}, end - cur, nil
+	}
+	return func() (io.ReadCloser, error) {
+		if _, err := s.Seek(cur, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(r), nil
+	}, end - cur, nil
+}
+
+// NewBodyRequest returns a request streaming the body returned by getBody, as returned by ReaderBody or
+// MultipartFile. size is the body length, or -1 if unknown.
+//
+// It sets http.Request.GetBody, otherwise DefaultTransport reads the whole body in memory to be able to
+// retry.
+func NewBodyRequest(ctx context.Context, method, url string, getBody func() (io.ReadCloser, error), size int64) (*http.Request, error) {
+	body, err := getBody()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.GetBody = getBody
+	req.ContentLength = size
+	return req, nil
+}
+
 func yieldNothing[T any](yield func(T) bool) {
 }
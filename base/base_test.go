@@ -7,11 +7,27 @@
 package base
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/maruel/roundtrippers"
+
 	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal"
 )
 
 func TestCheckDuplicateOptions(t *testing.T) {
@@ -40,6 +56,61 @@ func TestCheckDuplicateOptions(t *testing.T) {
 	})
 }
 
+func TestNewTransport(t *testing.T) {
+	if NewTransport(nil, "", "") != DefaultTransport {
+		t.Fatal("expected DefaultTransport")
+	}
+	if NewTransport(&genai.ProviderOptionHTTP{}, "", "") == DefaultTransport {
+		t.Fatal("expected a new transport")
+	}
+	h := NewHTTPTransport(genai.ProviderOptionHTTP{
+		MaxIdleConnsPerHost:  200,
+		HTTP2SendPingTimeout: 10 * time.Second,
+		HTTP2PingTimeout:     5 * time.Second,
+		TLSSessionCacheSize:  8,
+	})
+	if h.MaxIdleConnsPerHost != 200 || h.MaxIdleConns < 200 {
+		t.Errorf("unexpected pool size %d/%d", h.MaxIdleConnsPerHost, h.MaxIdleConns)
+	}
+	if h.HTTP2 == nil || h.HTTP2.SendPingTimeout != 10*time.Second || h.HTTP2.PingTimeout != 5*time.Second {
+		t.Errorf("unexpected HTTP/2 config %#v", h.HTTP2)
+	}
+	if h.TLSClientConfig == nil || h.TLSClientConfig.ClientSessionCache == nil || !h.ForceAttemptHTTP2 {
+		t.Error("expected TLS session resumption with HTTP/2")
+	}
+	if h == DefaultHTTPTransport || DefaultHTTPTransport.MaxIdleConnsPerHost != 16 {
+		t.Error("DefaultHTTPTransport must not be modified")
+	}
+}
+
+func TestNewTransport_proxy(t *testing.T) {
+	proxyOf := func(rt http.RoundTripper) (*url.URL, error) {
+		h := rt.(*roundtrippers.Retry).Transport.(*attemptTransport).t.(*http.Transport)
+		req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+		return h.Proxy(req)
+	}
+	t.Run("option", func(t *testing.T) {
+		t.Setenv("TEST_PROXY", "http://env:3128")
+		u, err := proxyOf(NewTransport(nil, "http://option:3128", "TEST_PROXY"))
+		if err != nil || u.String() != "http://option:3128" {
+			t.Fatalf("got %v, %v", u, err)
+		}
+	})
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("TEST_PROXY", "socks5://env:1080")
+		u, err := proxyOf(NewTransport(nil, "", "TEST_PROXY"))
+		if err != nil || u.String() != "socks5://env:1080" {
+			t.Fatalf("got %v, %v", u, err)
+		}
+	})
+	t.Run("invalid_env", func(t *testing.T) {
+		t.Setenv("TEST_PROXY", "ftp://env")
+		if _, err := proxyOf(NewTransport(nil, "", "TEST_PROXY")); err == nil || !strings.HasPrefix(err.Error(), "TEST_PROXY: ") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
 func TestTimeSUnmarshalJSON(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -296,3 +367,582 @@ func TestDurationSAsDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestPollJob(t *testing.T) {
+	var progress []genai.JobProgress
+	opts := []genai.GenOption{
+		&genai.GenOptionText{},
+		genai.GenOptionPollInterval(time.Millisecond),
+		genai.GenOptionProgress(func(p genai.JobProgress) { progress = append(progress, p) }),
+	}
+	interval, cb, rest := ExtractPollOptions(opts, time.Second)
+	if interval != time.Millisecond || cb == nil || len(rest) != 1 || rest[0] != opts[0] {
+		t.Fatalf("unexpected ExtractPollOptions: %v, %v", interval, rest)
+	}
+	polls := 0
+	res, err := PollJob(t.Context(), "job", interval, cb, func(ctx context.Context, id genai.Job) (genai.Result, map[string]any, error) {
+		polls++
+		if polls < 3 {
+			return genai.Result{Usage: genai.Usage{FinishReason: genai.Pending}}, map[string]any{"progress": polls}, nil
+		}
+		return genai.Result{Usage: genai.Usage{FinishReason: genai.FinishedStop}}, nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Usage.FinishReason != genai.FinishedStop {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if len(progress) != 2 || progress[1].Job != "job" || progress[1].Polls != 2 || progress[1].Metadata["progress"] != 2 || progress[1].Elapsed <= 0 {
+		t.Fatalf("unexpected progress %+v", progress)
+	}
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		_, err := PollJob(ctx, "job", time.Hour, nil, func(ctx context.Context, id genai.Job) (genai.Result, map[string]any, error) {
+			t.Fatal("unexpected poll")
+			return genai.Result{}, nil, nil
+		})
+		if err != context.Canceled {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestExtractRawOptions(t *testing.T) {
+	var got string
+	c := ProviderBase[*testErrorResponse]{Client: http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		b, err := io.ReadAll(r.Body)
+		got = string(b)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Request: r}, err
+	})}}
+	in := map[string]any{"model": "m", "config": map[string]any{"a": 1, "b": 2}, "drop": true}
+	opts := []genai.GenOption{
+		&genai.GenOptionText{},
+		&genai.GenOptionRaw{Fields: map[string]any{"config": map[string]any{"b": 3, "c": "<new>"}, "drop": nil}},
+		&genai.GenOptionRaw{Fields: map[string]any{"model": "override"}},
+	}
+	ctx, rest := ExtractRawOptions(t.Context(), opts)
+	if len(rest) != 1 || rest[0] != opts[0] {
+		t.Fatalf("unexpected remaining options: %v", rest)
+	}
+	resp, err := c.JSONRequest(ctx, "POST", "http://localhost/", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if want := "{\"config\":{\"a\":1,\"b\":3,\"c\":\"<new>\"},\"model\":\"override\"}\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	t.Run("invalid", func(t *testing.T) {
+		ctx, _ := ExtractRawOptions(t.Context(), []genai.GenOption{&genai.GenOptionRaw{}})
+		if _, err := c.JSONRequest(ctx, "POST", "http://localhost/", in); err == nil || err.Error() != "GenOptionRaw: field Fields is required" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestExtractRawOptions_headers(t *testing.T) {
+	var got http.Header
+	c := ProviderBase[*testErrorResponse]{Client: http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		got = r.Header
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Request: r}, nil
+	})}}
+	opts := []genai.GenOption{genai.GenOptionHeaders{"x-gateway-route": {"eu"}, "Content-Type": {"application/json"}}}
+	ctx, rest := ExtractRawOptions(t.Context(), opts)
+	if len(rest) != 0 {
+		t.Fatalf("unexpected remaining options: %v", rest)
+	}
+	resp, err := c.JSONRequest(ctx, "POST", "http://localhost/", map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if v := got.Get("X-Gateway-Route"); v != "eu" {
+		t.Fatalf("got %q", v)
+	}
+	if v := got.Get("Content-Type"); v != "application/json" {
+		t.Fatalf("got %q", v)
+	}
+	t.Run("invalid", func(t *testing.T) {
+		ctx, _ := ExtractRawOptions(t.Context(), []genai.GenOption{genai.GenOptionHeaders{"bad name": {"x"}}})
+		if _, err := c.JSONRequest(ctx, "POST", "http://localhost/", nil); err == nil || err.Error() != `GenOptionHeaders: invalid header name "bad name"` {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestExtractRawOptions_timeout(t *testing.T) {
+	hang := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+	t.Run("total", func(t *testing.T) {
+		c := ProviderBase[*testErrorResponse]{Client: http.Client{Transport: hang}}
+		ctx, rest := ExtractRawOptions(t.Context(), []genai.GenOption{&genai.GenOptionTimeout{Total: time.Millisecond}})
+		if len(rest) != 0 {
+			t.Fatalf("unexpected remaining options: %v", rest)
+		}
+		if _, err := c.JSONRequest(ctx, "POST", "http://localhost/", nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("request", func(t *testing.T) {
+		c := ProviderBase[*testErrorResponse]{Client: http.Client{Transport: NewAttemptTransport(hang)}}
+		ctx, _ := ExtractRawOptions(t.Context(), []genai.GenOption{&genai.GenOptionTimeout{Request: time.Millisecond}})
+		if _, err := c.JSONRequest(ctx, "POST", "http://localhost/", nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("body", func(t *testing.T) {
+		var rctx context.Context
+		c := ProviderBase[*testErrorResponse]{Client: http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			rctx = r.Context()
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Request: r}, nil
+		})}}
+		ctx, _ := ExtractRawOptions(t.Context(), []genai.GenOption{&genai.GenOptionTimeout{Total: time.Hour}})
+		resp, err := c.JSONRequest(ctx, "POST", "http://localhost/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rctx.Err() != nil {
+			t.Fatal("context canceled before the body was closed")
+		}
+		_ = resp.Body.Close()
+		if rctx.Err() == nil {
+			t.Fatal("context not canceled after the body was closed")
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		c := ProviderBase[*testErrorResponse]{Client: http.Client{Transport: hang}}
+		ctx, _ := ExtractRawOptions(t.Context(), []genai.GenOption{&genai.GenOptionTimeout{}})
+		if _, err := c.JSONRequest(ctx, "POST", "http://localhost/", nil); err == nil || err.Error() != "GenOptionTimeout: one of Request, Total or Idle is required" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestExtractRawOptions_capture(t *testing.T) {
+	c := ProviderBase[*testErrorResponse]{Client: http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Id": {"1"}}, Body: io.NopCloser(strings.NewReader(`{"ok":true}`)), Request: r}, nil
+	})}}
+	var got []*genai.HTTPExchange
+	ctx, rest := ExtractRawOptions(t.Context(), []genai.GenOption{genai.GenOptionCapture(func(ex *genai.HTTPExchange) {
+		got = append(got, ex)
+	})})
+	if len(rest) != 0 {
+		t.Fatalf("unexpected remaining options: %v", rest)
+	}
+	var out map[string]bool
+	if err := c.DoRequest(ctx, "POST", "http://localhost/", map[string]string{"a": "b"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("unexpected exchanges: %v", got)
+	}
+	ex := got[0]
+	if ex.Method != "POST" || ex.URL != "http://localhost/" || ex.StatusCode != http.StatusOK || ex.Header.Get("X-Id") != "1" {
+		t.Fatalf("unexpected exchange: %+v", ex)
+	}
+	if s := string(ex.Request); s != "{\"a\":\"b\"}\n" {
+		t.Fatalf("unexpected request: %q", s)
+	}
+	if s := string(ex.Response); s != `{"ok":true}` {
+		t.Fatalf("unexpected response: %q", s)
+	}
+	t.Run("invalid", func(t *testing.T) {
+		ctx, _ := ExtractRawOptions(t.Context(), []genai.GenOption{genai.GenOptionCapture(nil)})
+		if _, err := c.JSONRequest(ctx, "POST", "http://localhost/", nil); err == nil || err.Error() != "GenOptionCapture: must not be nil" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestProviderBase_log(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := ProviderBase[*testErrorResponse]{
+		Log: genai.ProviderOptionLogger{Logger: l, Level: slog.LevelDebug},
+		Client: http.Client{Transport: NewAttemptTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusTooManyRequests, Body: io.NopCloser(strings.NewReader("{}")), Request: r}, nil
+		}))},
+	}
+	resp, err := c.JSONRequest(t.Context(), "POST", "http://localhost/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	c.LogResult(t.Context(), "GenSync", time.Now(), &genai.Result{Usage: genai.Usage{InputTokens: 10, OutputTokens: 2}}, nil)
+	got := buf.String()
+	for _, want := range []string{
+		`level=DEBUG msg="http request" method=POST url=http://localhost/`,
+		`level=ERROR msg="http response" url=http://localhost/`,
+		`status=429`,
+		`level=DEBUG msg=GenSync model="" dur=0s in=10 out=2 finish=""`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenStreamRaw_idle(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	go func() {
+		_, _ = w.Write([]byte("data: {\"text\":\"hi\"}\n\n"))
+	}()
+	c := Provider[*testErrorResponse, *testRequest, *testResponse, testChunk]{
+		GenSyncURL: "http://localhost/",
+		ProviderBase: ProviderBase[*testErrorResponse]{
+			Model: "m",
+			Client: http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: r, Request: req}, nil
+			})},
+		},
+	}
+	ctx, _ := ExtractRawOptions(t.Context(), []genai.GenOption{&genai.GenOptionTimeout{Idle: 10 * time.Millisecond}})
+	chunks, finish := c.GenStreamRaw(ctx, &testRequest{})
+	var got []testChunk
+	for pkt := range chunks {
+		got = append(got, pkt)
+	}
+	if len(got) != 1 || got[0].Text != "hi" {
+		t.Fatalf("unexpected chunks: %v", got)
+	}
+	var errIdle *ErrIdleTimeout
+	if err := finish(); !errors.As(err, &errIdle) || errIdle.Idle != 10*time.Millisecond {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStrictErr(t *testing.T) {
+	unhandled := &ErrUnhandled{Err: errors.New("unknown finish reason \"foo\"")}
+	other := errors.New("oops")
+	if err := strictErr(unhandled, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var bad *internal.BadError
+	if err := strictErr(unhandled, false); !errors.As(err, &bad) || err.Error() != `unknown finish reason "foo"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := strictErr(errors.Join(unhandled, other), true); err != other {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := strictErr(errors.Join(unhandled, other), false); err == nil || err.Error() != "unknown finish reason \"foo\"\noops" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := strictErr(other, false); err != other {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+type testRequest struct {
+	Stream bool `json:"stream"`
+}
+
+func (r *testRequest) Init(msgs genai.Messages, model string, opts ...genai.GenOption) error {
+	return nil
+}
+
+func (r *testRequest) SetStream(stream bool) {
+	r.Stream = stream
+}
+
+type testResponse struct{}
+
+func (*testResponse) ToResult() (genai.Result, error) {
+	return genai.Result{}, nil
+}
+
+type testChunk struct {
+	Text string `json:"text"`
+}
+
+type testErrorResponse struct{}
+
+func (*testErrorResponse) Error() string    { return "error" }
+func (*testErrorResponse) IsAPIError() bool { return true }
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestMultipartFile(t *testing.T) {
+	for _, seekable := range []bool{true, false} {
+		var r io.Reader = strings.NewReader("hello")
+		if !seekable {
+			r = io.MultiReader(r)
+		}
+		getBody, contentType, size, err := MultipartFile("boundary", map[string]string{"purpose": "batch"}, "file", "a.txt", r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType != "multipart/form-data; boundary=boundary" {
+			t.Errorf("got content type %q", contentType)
+		}
+		body, err := getBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "--boundary\r\nContent-Disposition: form-data; name=\"purpose\"\r\n\r\nbatch\r\n" +
+			"--boundary\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\r\nContent-Type: application/octet-stream\r\n\r\n" +
+			"hello\r\n--boundary--\r\n"
+		if string(b) != want {
+			t.Errorf("got %q\nwant %q", b, want)
+		}
+		wantSize := int64(len(want))
+		if !seekable {
+			wantSize = -1
+		}
+		if size != wantSize {
+			t.Errorf("seekable=%t: got size %d, want %d", seekable, size, wantSize)
+		}
+		// The body is rewound only when r is seekable.
+		body, err = getBody()
+		if !seekable {
+			if err == nil {
+				t.Error("expected error")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, err = io.ReadAll(body); err != nil || string(b) != want {
+			t.Errorf("got %q, %v", b, err)
+		}
+	}
+}
+
+func TestNewBodyRequest(t *testing.T) {
+	// The body is larger than what the socket buffers can hold, so it can't be fully read before the server
+	// receives the request unless it was buffered in memory.
+	const size = 32 << 20
+	t.Run("Seeker", func(t *testing.T) {
+		r := &zeroReader{size: size}
+		testRetriedUpload(t, r, &r.read, size)
+	})
+	t.Run("ReaderAt", func(t *testing.T) {
+		r := &zeroReaderAt{zeroReader{size: size}}
+		testRetriedUpload(t, r, &r.read, size)
+		if r.off != 0 {
+			t.Fatalf("the body was read through the shared offset: %d", r.off)
+		}
+	})
+	t.Run("Stream", func(t *testing.T) {
+		// A stream can't be rewound so the request is sent once and not retried.
+		r := &zeroReader{size: size}
+		var attempts atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			attempts.Add(1)
+			_, _ = io.Copy(io.Discard, req.Body)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+		getBody, n, err := ReaderBody(struct{ io.Reader }{r})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != -1 {
+			t.Fatalf("got size %d", n)
+		}
+		req, err := NewBodyRequest(t.Context(), http.MethodPut, ts.URL, getBody, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := http.Client{Transport: DefaultTransport}
+		if resp, err := c.Do(req); err == nil {
+			_ = resp.Body.Close()
+			t.Fatal("expected an error")
+		}
+		if got := attempts.Load(); got != 1 {
+			t.Fatalf("got %d attempts", got)
+		}
+		if got := r.read.Load(); got != size {
+			t.Fatalf("read %d bytes, want %d", got, size)
+		}
+	})
+}
+
+// testRetriedUpload uploads r, which the server rejects once, and verifies the retry streamed r again.
+func testRetriedUpload(t *testing.T, r io.Reader, read *atomic.Int64, size int64) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if n := read.Load(); n >= int64(attempts.Load()+1)*size {
+			t.Errorf("the body was read before the request was sent: %d bytes", n)
+		}
+		n, err := io.Copy(io.Discard, req.Body)
+		if err != nil || n != size {
+			t.Errorf("got %d bytes: %v", n, err)
+		}
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+	getBody, n, err := ReaderBody(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != size {
+		t.Fatalf("got size %d", n)
+	}
+	req, err := NewBodyRequest(t.Context(), http.MethodPut, ts.URL, getBody, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := http.Client{Transport: DefaultTransport}
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d", resp.StatusCode)
+	}
+	// The retry rewound the reader instead of replaying a copy in memory.
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("got %d attempts", got)
+	}
+	if got := read.Load(); got != 2*size {
+		t.Fatalf("read %d bytes, want %d", got, 2*size)
+	}
+}
+
+// zeroReader is an io.ReadSeeker of size zero bytes that counts the bytes read.
+type zeroReader struct {
+	size int64
+	off  int64
+	read atomic.Int64
+}
+
+func (z *zeroReader) Read(p []byte) (int, error) {
+	n := min(int64(len(p)), z.size-z.off)
+	if n <= 0 {
+		return 0, io.EOF
+	}
+	clear(p[:n])
+	z.off += n
+	z.read.Add(n)
+	return int(n), nil
+}
+
+func (z *zeroReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += z.off
+	case io.SeekEnd:
+		offset += z.size
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	z.off = offset
+	return offset, nil
+}
+
+// zeroReaderAt is a zeroReader that also implements io.ReaderAt.
+type zeroReaderAt struct {
+	zeroReader
+}
+
+func (z *zeroReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n := min(int64(len(p)), z.size-off)
+	if n <= 0 {
+		return 0, io.EOF
+	}
+	clear(p[:n])
+	z.read.Add(n)
+	if n < int64(len(p)) {
+		return int(n), io.EOF
+	}
+	return int(n), nil
+}
+
+func TestParseModalities(t *testing.T) {
+	got := ParseModalities([]string{"text", "File", "image", "embeddings", "text"})
+	want := genai.Modalities{genai.ModalityDocument, genai.ModalityImage, genai.ModalityText}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := ParseModalities(nil); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
+func TestOutputSink(t *testing.T) {
+	dir := t.TempDir()
+	newFile := func(filename string) (io.ReadWriteSeeker, error) {
+		return os.Create(filepath.Join(dir, filename))
+	}
+	t.Run("Accumulate", func(t *testing.T) {
+		sink, opts := ExtractOutputOption([]genai.GenOption{&genai.GenOptionOutput{New: newFile}, genai.GenOptionSeed(1)})
+		if sink == nil || len(opts) != 1 {
+			t.Fatalf("got %v, %v", sink, opts)
+		}
+		res := genai.Result{}
+		for _, f := range []genai.Reply{
+			{Text: "hi"},
+			{Doc: genai.Doc{Filename: "a.wav", Src: strings.NewReader("abc")}},
+			{Doc: genai.Doc{Filename: "a.wav", Src: strings.NewReader("def")}},
+		} {
+			if err := sink.Accumulate(&res, &f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(res.Replies) != 2 {
+			t.Fatalf("got %d replies", len(res.Replies))
+		}
+		if _, ok := res.Replies[1].Doc.Src.(*os.File); !ok {
+			t.Fatalf("got %T", res.Replies[1].Doc.Src)
+		}
+		b, err := io.ReadAll(res.Replies[1].Doc.Src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "abcdef" {
+			t.Fatalf("got %q", b)
+		}
+		_ = res.Replies[1].Doc.Src.(*os.File).Close()
+	})
+	t.Run("Redirect/MaxSize", func(t *testing.T) {
+		sink, _ := ExtractOutputOption([]genai.GenOption{&genai.GenOptionOutput{New: newFile, MaxSize: 2}})
+		res := genai.Result{Message: genai.Message{Replies: []genai.Reply{{Doc: genai.Doc{Filename: "b.png", Src: strings.NewReader("abc")}}}}}
+		want := "generated documents exceed GenOptionOutput.MaxSize of 2 bytes"
+		if err := sink.Redirect(&res); err == nil || err.Error() != want {
+			t.Fatalf("got %v, want %q", err, want)
+		}
+	})
+	t.Run("nil", func(t *testing.T) {
+		var sink *OutputSink
+		res := genai.Result{}
+		if err := sink.Accumulate(&res, &genai.Reply{Doc: genai.Doc{Filename: "c.png", Src: strings.NewReader("abc")}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Redirect(&res); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := res.Replies[0].Doc.Src.(*os.File); ok {
+			t.Fatal("unexpected file")
+		}
+	})
+}
+
+func TestCheckStreamCandidates(t *testing.T) {
+	if err := CheckStreamCandidates([]genai.GenOption{&genai.GenOptionText{N: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	var e *ErrNotSupported
+	if err := CheckStreamCandidates([]genai.GenOption{&genai.GenOptionText{N: 2}}); !errors.As(err, &e) || e.Options[0] != "GenOptionText.N" {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,588 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Translation of JSON Schema documents to provider specific dialects.
+
+package base
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maruel/genai"
+)
+
+// SchemaDialect describes the subset of JSON Schema accepted by a provider.
+//
+// Use Lower to translate a JSON Schema document to the dialect before sending it, so that unsupported
+// constructs are reported with their location instead of being rejected by the server or silently ignored.
+type SchemaDialect struct {
+	// Name identifies the dialect in error messages.
+	Name string
+	// Unsupported lists the keywords the provider rejects or ignores in a way that changes the meaning of the
+	// schema. Lower returns an ErrSchemaNotSupported when one is found.
+	Unsupported []string
+	// Strip lists the keywords that are removed without error, generally annotations.
+	Strip []string
+	// InlineRefs replaces local "$ref" references to "$defs" or "definitions" with the referenced schema and
+	// removes the definitions. Recursive references are reported as unsupported.
+	InlineRefs bool
+	// ConstAsEnum rewrites "const" as an "enum" with a single value.
+	ConstAsEnum bool
+	// Strict enforces OpenAI's strict mode rules: the root must be an object and every object must have
+	// "additionalProperties": false and list all its properties as required. Optional properties are made
+	// nullable and added to "required".
+	Strict bool
+}
+
+// SchemaDialectOpenAIStrict is the dialect accepted by OpenAI structured outputs with "strict": true.
+//
+// See https://platform.openai.com/docs/guides/structured-outputs#supported-schemas
+var SchemaDialectOpenAIStrict = SchemaDialect{
+	Name:        "openai strict",
+	Unsupported: []string{"allOf", "dependentRequired", "dependentSchemas", "else", "if", "not", "oneOf", "patternProperties", "then"},
+	Strict:      true,
+}
+
+// SchemaDialectGemini is the dialect of Gemini's Schema object, an OpenAPI 3.0 subset.
+//
+// See https://ai.google.dev/api/caching#Schema
+var SchemaDialectGemini = SchemaDialect{
+	Name:        "gemini",
+	Unsupported: []string{"allOf", "contains", "dependentRequired", "dependentSchemas", "else", "if", "not", "oneOf", "patternProperties", "prefixItems", "then"},
+	Strip:       []string{"$comment", "$id", "$schema", "additionalProperties"},
+	InlineRefs:  true,
+	ConstAsEnum: true,
+}
+
+// ErrSchemaNotSupported is returned by SchemaDialect.Lower when the schema uses constructs that the dialect
+// doesn't support.
+type ErrSchemaNotSupported struct {
+	// Dialect is SchemaDialect.Name.
+	Dialect string
+	// Paths are the JSON pointers to the unsupported keywords, e.g. "/properties/shape/oneOf".
+	Paths []string
+}
+
+func (e *ErrSchemaNotSupported) Error() string {
+	return fmt.Sprintf("%s schema: not supported: %s", e.Dialect, strings.Join(e.Paths, ", "))
+}
+
+// Lower translates js to the dialect.
+//
+// js is returned as-is when no change is needed, so the serialization is stable.
+func (d *SchemaDialect) Lower(js genai.JSONSchema) (genai.JSONSchema, error) {
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var root map[string]any
+	if err := dec.Decode(&root); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	if root == nil {
+		return nil, errors.New("invalid JSON schema: must be an object")
+	}
+	l := schemaLowerer{d: d}
+	if d.InlineRefs {
+		l.defs = map[string]any{}
+		for _, k := range []string{"$defs", "definitions"} {
+			if defs, ok := root[k].(map[string]any); ok {
+				for name, def := range defs {
+					l.defs["#/"+k+"/"+escapePointer(name)] = def
+				}
+				delete(root, k)
+				l.changed = true
+			}
+		}
+	}
+	if d.Strict && root["type"] != "object" {
+		l.errs = append(l.errs, "/type")
+	}
+	out := l.walk(root, "")
+	if len(l.errs) != 0 {
+		return nil, &ErrSchemaNotSupported{Dialect: d.Name, Paths: l.errs}
+	}
+	if !l.changed {
+		return js, nil
+	}
+	return json.Marshal(out)
+}
+
+type schemaLowerer struct {
+	d       *SchemaDialect
+	defs    map[string]any
+	refs    []string
+	errs    []string
+	changed bool
+}
+
+// walk lowers the schema n located at path and returns the result, which may be a new node.
+func (l *schemaLowerer) walk(n map[string]any, path string) map[string]any {
+	if l.d.InlineRefs {
+		if ref, ok := n["$ref"].(string); ok {
+			def, ok := l.defs[ref].(map[string]any)
+			if !ok || slices.Contains(l.refs, ref) {
+				l.errs = append(l.errs, path+"/$ref")
+				return n
+			}
+			// Keywords next to $ref, like description, override the definition's.
+			merged := cloneJSON(def).(map[string]any)
+			for k, v := range n {
+				if k != "$ref" {
+					merged[k] = v
+				}
+			}
+			l.changed = true
+			l.refs = append(l.refs, ref)
+			out := l.walk(merged, path)
+			l.refs = l.refs[:len(l.refs)-1]
+			return out
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(n)) {
+		if slices.Contains(l.d.Strip, k) {
+			delete(n, k)
+			l.changed = true
+		} else if slices.Contains(l.d.Unsupported, k) {
+			l.errs = append(l.errs, path+"/"+k)
+		}
+	}
+	if c, ok := n["const"]; ok && l.d.ConstAsEnum {
+		n["enum"] = []any{c}
+		delete(n, "const")
+		l.changed = true
+	}
+
+	// Recurse into subschemas.
+	for _, k := range []string{"additionalProperties", "items", "not"} {
+		if s, ok := n[k].(map[string]any); ok {
+			n[k] = l.walk(s, path+"/"+k)
+		}
+	}
+	for _, k := range []string{"allOf", "anyOf", "oneOf", "prefixItems"} {
+		if arr, ok := n[k].([]any); ok {
+			for i := range arr {
+				if s, ok := arr[i].(map[string]any); ok {
+					arr[i] = l.walk(s, fmt.Sprintf("%s/%s/%d", path, k, i))
+				}
+			}
+		}
+	}
+	for _, k := range []string{"$defs", "definitions", "properties"} {
+		if m, ok := n[k].(map[string]any); ok {
+			for _, name := range slices.Sorted(maps.Keys(m)) {
+				if s, ok := m[name].(map[string]any); ok {
+					m[name] = l.walk(s, path+"/"+k+"/"+escapePointer(name))
+				}
+			}
+		}
+	}
+	if l.d.Strict && isObjectSchema(n) {
+		l.strictObject(n, path)
+	}
+	return n
+}
+
+// strictObject applies OpenAI's strict mode rules to the object schema n.
+func (l *schemaLowerer) strictObject(n map[string]any, path string) {
+	switch v := n["additionalProperties"].(type) {
+	case nil:
+		n["additionalProperties"] = false
+		l.changed = true
+	case bool:
+		if v {
+			l.errs = append(l.errs, path+"/additionalProperties")
+		}
+	default:
+		l.errs = append(l.errs, path+"/additionalProperties")
+	}
+	props, _ := n["properties"].(map[string]any)
+	req, _ := n["required"].([]any)
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		if slices.Contains(req, any(name)) {
+			continue
+		}
+		if s, ok := props[name].(map[string]any); ok {
+			props[name] = makeNullable(s)
+		}
+		req = append(req, name)
+		l.changed = true
+	}
+	if len(req) != 0 {
+		n["required"] = req
+	}
+}
+
+// makeNullable returns a schema that also accepts null.
+func makeNullable(s map[string]any) map[string]any {
+	switch t := s["type"].(type) {
+	case string:
+		if t != "null" {
+			s["type"] = []any{t, "null"}
+			if e, ok := s["enum"].([]any); ok {
+				s["enum"] = append(e, nil)
+			}
+		}
+		return s
+	case []any:
+		if !slices.Contains(t, any("null")) {
+			s["type"] = append(t, "null")
+			if e, ok := s["enum"].([]any); ok {
+				s["enum"] = append(e, nil)
+			}
+		}
+		return s
+	default:
+		return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+	}
+}
+
+func isObjectSchema(n map[string]any) bool {
+	switch t := n["type"].(type) {
+	case string:
+		return t == "object"
+	case []any:
+		return slices.Contains(t, any("object"))
+	default:
+		return false
+	}
+}
+
+func cloneJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, v := range t {
+			m[k] = cloneJSON(v)
+		}
+		return m
+	case []any:
+		a := make([]any, len(t))
+		for i, v := range t {
+			a[i] = cloneJSON(v)
+		}
+		return a
+	default:
+		return v
+	}
+}
+
+// ErrSchemaValidation is returned when a reply doesn't conform to the schema requested with
+// genai.GenOptionText.StrictSchema.
+type ErrSchemaValidation struct {
+	// Path is the JSON pointer to the offending value in the reply, e.g. "/items/0/name". It is empty for the
+	// root value.
+	Path string
+	// Reason describes the violation.
+	Reason string
+}
+
+func (e *ErrSchemaValidation) Error() string {
+	return fmt.Sprintf("reply doesn't match the JSON schema at %q: %s", e.Path, e.Reason)
+}
+
+// ValidateSchema validates the JSON document data against the JSON Schema js.
+//
+// It supports the subset of JSON Schema used for structured outputs: type, enum, const, properties, required,
+// additionalProperties, items, prefixItems, allOf, anyOf, oneOf, not, the numeric, string and array bounds,
+// pattern and local $ref to "$defs" or "definitions". Other keywords, like format, are ignored.
+//
+// It returns an *ErrSchemaValidation for the first violation found.
+func ValidateSchema(js genai.JSONSchema, data []byte) error {
+	var root map[string]any
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	if err := dec.Decode(&root); err != nil {
+		return fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	var v any
+	dec = json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return &ErrSchemaValidation{Reason: "invalid JSON: " + err.Error()}
+	}
+	if dec.More() {
+		return &ErrSchemaValidation{Reason: "invalid JSON: trailing data"}
+	}
+	if err := (&schemaValidator{root: root}).validate(root, v, ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+type schemaValidator struct {
+	root  map[string]any
+	depth int
+}
+
+// validate validates the value v located at path against the schema n.
+func (s *schemaValidator) validate(n map[string]any, v any, path string) *ErrSchemaValidation {
+	if ref, ok := n["$ref"].(string); ok {
+		def, ok := s.resolve(ref)
+		if !ok {
+			return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("unresolvable $ref %q", ref)}
+		}
+		if s.depth++; s.depth > 100 {
+			return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("$ref %q is too deeply nested", ref)}
+		}
+		err := s.validate(def, v, path)
+		s.depth--
+		if err != nil {
+			return err
+		}
+	}
+	if t, ok := n["type"]; ok && !matchesType(t, v) {
+		return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("want type %v, got %s", t, jsonType(v))}
+	}
+	if e, ok := n["enum"].([]any); ok && !slices.ContainsFunc(e, func(x any) bool { return equalJSON(x, v) }) {
+		return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("value %s is not one of the enum values", marshalValue(v))}
+	}
+	if c, ok := n["const"]; ok && !equalJSON(c, v) {
+		return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("want %s, got %s", marshalValue(c), marshalValue(v))}
+	}
+	if err := s.validateCombinators(n, v, path); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		return s.validateObject(n, t, path)
+	case []any:
+		return s.validateArray(n, t, path)
+	case string:
+		l := float64(utf8.RuneCountInString(t))
+		if m, ok := number(n["minLength"]); ok && l < m {
+			return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("length %g is less than minLength %g", l, m)}
+		}
+		if m, ok := number(n["maxLength"]); ok && l > m {
+			return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("length %g is greater than maxLength %g", l, m)}
+		}
+		if p, ok := n["pattern"].(string); ok {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("invalid pattern %q: %v", p, err)}
+			}
+			if !re.MatchString(t) {
+				return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("%q doesn't match pattern %q", t, p)}
+			}
+		}
+	case json.Number:
+		f, _ := t.Float64()
+		if m, ok := number(n["minimum"]); ok && f < m {
+			return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("%s is less than minimum %g", t, m)}
+		}
+		if m, ok := number(n["maximum"]); ok && f > m {
+			return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("%s is greater than maximum %g", t, m)}
+		}
+		if m, ok := number(n["exclusiveMinimum"]); ok && f <= m {
+			return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("%s is not greater than exclusiveMinimum %g", t, m)}
+		}
+		if m, ok := number(n["exclusiveMaximum"]); ok && f >= m {
+			return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("%s is not less than exclusiveMaximum %g", t, m)}
+		}
+	}
+	return nil
+}
+
+func (s *schemaValidator) validateCombinators(n map[string]any, v any, path string) *ErrSchemaValidation {
+	if arr, ok := n["allOf"].([]any); ok {
+		for _, sub := range arr {
+			if m, ok := sub.(map[string]any); ok {
+				if err := s.validate(m, v, path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if arr, ok := n["anyOf"].([]any); ok {
+		if !slices.ContainsFunc(arr, func(sub any) bool {
+			m, ok := sub.(map[string]any)
+			return !ok || s.validate(m, v, path) == nil
+		}) {
+			return &ErrSchemaValidation{Path: path, Reason: "doesn't match any schema in anyOf"}
+		}
+	}
+	if arr, ok := n["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range arr {
+			if m, ok := sub.(map[string]any); ok && s.validate(m, v, path) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("matches %d schemas in oneOf, want exactly 1", matched)}
+		}
+	}
+	if m, ok := n["not"].(map[string]any); ok && s.validate(m, v, path) == nil {
+		return &ErrSchemaValidation{Path: path, Reason: "matches the schema in not"}
+	}
+	return nil
+}
+
+func (s *schemaValidator) validateObject(n map[string]any, v map[string]any, path string) *ErrSchemaValidation {
+	req, _ := n["required"].([]any)
+	for _, r := range req {
+		if name, ok := r.(string); ok {
+			if _, ok := v[name]; !ok {
+				return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("missing required property %q", name)}
+			}
+		}
+	}
+	props, _ := n["properties"].(map[string]any)
+	for _, k := range slices.Sorted(maps.Keys(v)) {
+		p := path + "/" + escapePointer(k)
+		if sub, ok := props[k].(map[string]any); ok {
+			if err := s.validate(sub, v[k], p); err != nil {
+				return err
+			}
+			continue
+		}
+		switch a := n["additionalProperties"].(type) {
+		case bool:
+			if !a {
+				return &ErrSchemaValidation{Path: p, Reason: "additional property is not allowed"}
+			}
+		case map[string]any:
+			if err := s.validate(a, v[k], p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *schemaValidator) validateArray(n map[string]any, v []any, path string) *ErrSchemaValidation {
+	l := float64(len(v))
+	if m, ok := number(n["minItems"]); ok && l < m {
+		return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("%g items is less than minItems %g", l, m)}
+	}
+	if m, ok := number(n["maxItems"]); ok && l > m {
+		return &ErrSchemaValidation{Path: path, Reason: fmt.Sprintf("%g items is greater than maxItems %g", l, m)}
+	}
+	prefix, _ := n["prefixItems"].([]any)
+	for i := range v {
+		p := fmt.Sprintf("%s/%d", path, i)
+		var sub map[string]any
+		if i < len(prefix) {
+			sub, _ = prefix[i].(map[string]any)
+		} else {
+			sub, _ = n["items"].(map[string]any)
+		}
+		if sub != nil {
+			if err := s.validate(sub, v[i], p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema referenced by a local $ref.
+func (s *schemaValidator) resolve(ref string) (map[string]any, bool) {
+	for _, k := range []string{"$defs", "definitions"} {
+		prefix := "#/" + k + "/"
+		if name, ok := strings.CutPrefix(ref, prefix); ok {
+			name = strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+			defs, _ := s.root[k].(map[string]any)
+			def, ok := defs[name].(map[string]any)
+			return def, ok
+		}
+	}
+	if ref == "#" {
+		return s.root, true
+	}
+	return nil, false
+}
+
+// matchesType returns true if v is of the JSON Schema type t, either a string or a list of strings.
+func matchesType(t, v any) bool {
+	switch t := t.(type) {
+	case string:
+		return matchesTypeName(t, v)
+	case []any:
+		return slices.ContainsFunc(t, func(x any) bool {
+			name, ok := x.(string)
+			return ok && matchesTypeName(name, v)
+		})
+	default:
+		return true
+	}
+}
+
+func matchesTypeName(name string, v any) bool {
+	got := jsonType(v)
+	if name == "integer" && got == "number" {
+		f, err := v.(json.Number).Float64()
+		return err == nil && f == math.Trunc(f)
+	}
+	return name == got
+}
+
+// jsonType returns the JSON Schema type of a value decoded with UseNumber.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// equalJSON compares two values decoded with UseNumber, comparing numbers by value.
+func equalJSON(a, b any) bool {
+	if x, ok := a.(json.Number); ok {
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, err1 := x.Float64()
+		fy, err2 := y.Float64()
+		return err1 == nil && err2 == nil && fx == fy
+	}
+	switch x := a.(type) {
+	case []any:
+		y, ok := b.([]any)
+		return ok && slices.EqualFunc(x, y, equalJSON)
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		return ok && maps.EqualFunc(x, y, equalJSON)
+	default:
+		return a == b
+	}
+}
+
+func number(v any) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+func marshalValue(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// escapePointer escapes a JSON pointer reference token per RFC 6901.
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
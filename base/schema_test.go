@@ -0,0 +1,174 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the JSON Schema dialect translation.
+
+package base
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/maruel/genai"
+)
+
+func TestSchemaDialect_Lower(t *testing.T) {
+	tests := []struct {
+		name string
+		d    *SchemaDialect
+		in   string
+		want string
+	}{
+		{
+			name: "openai_unchanged",
+			d:    &SchemaDialectOpenAIStrict,
+			in:   `{"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": false, "required": ["a"]}`,
+			want: `{"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": false, "required": ["a"]}`,
+		},
+		{
+			name: "openai_optional",
+			d:    &SchemaDialectOpenAIStrict,
+			in:   `{"type":"object","properties":{"a":{"type":"string","enum":["x"]},"b":{"type":"integer"},"c":{"$ref":"#/$defs/C"}},"required":["b"]}`,
+			want: `{"additionalProperties":false,"properties":{"a":{"enum":["x",null],"type":["string","null"]},"b":{"type":"integer"},"c":{"anyOf":[{"$ref":"#/$defs/C"},{"type":"null"}]}},"required":["b","a","c"],"type":"object"}`,
+		},
+		{
+			name: "openai_nested",
+			d:    &SchemaDialectOpenAIStrict,
+			in:   `{"type":"object","properties":{"l":{"type":"array","items":{"type":"object","properties":{"x":{"type":"number"}}}}},"required":["l"],"additionalProperties":false}`,
+			want: `{"additionalProperties":false,"properties":{"l":{"items":{"additionalProperties":false,"properties":{"x":{"type":["number","null"]}},"required":["x"],"type":"object"},"type":"array"}},"required":["l"],"type":"object"}`,
+		},
+		{
+			name: "gemini_unchanged",
+			d:    &SchemaDialectGemini,
+			in:   `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+			want: `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+		},
+		{
+			name: "gemini_refs",
+			d:    &SchemaDialectGemini,
+			in:   `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/Root","$defs":{"Root":{"type":"object","properties":{"p":{"$ref":"#/$defs/Point","description":"where"}},"additionalProperties":false},"Point":{"type":"object","properties":{"x":{"const":1}},"description":"a point"}}}`,
+			want: `{"properties":{"p":{"description":"where","properties":{"x":{"enum":[1]}},"type":"object"}},"type":"object"}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.d.Lower(genai.JSONSchema(tc.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSchemaDialect_Lower_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     *SchemaDialect
+		in    string
+		paths []string
+	}{
+		{
+			name:  "openai_root",
+			d:     &SchemaDialectOpenAIStrict,
+			in:    `{"type":"array","items":{"type":"string"}}`,
+			paths: []string{"/type"},
+		},
+		{
+			name:  "openai_keywords",
+			d:     &SchemaDialectOpenAIStrict,
+			in:    `{"type":"object","properties":{"a":{"oneOf":[{"type":"string"},{"not":{"type":"integer"}}]},"m":{"type":"object","additionalProperties":{"type":"string"}}},"required":["a","m"],"additionalProperties":false}`,
+			paths: []string{"/properties/a/oneOf", "/properties/a/oneOf/1/not", "/properties/m/additionalProperties"},
+		},
+		{
+			name:  "gemini_recursive",
+			d:     &SchemaDialectGemini,
+			in:    `{"$ref":"#/$defs/Node","$defs":{"Node":{"type":"object","properties":{"next":{"$ref":"#/$defs/Node"}}}}}`,
+			paths: []string{"/properties/next/$ref"},
+		},
+		{
+			name:  "gemini_missing_ref",
+			d:     &SchemaDialectGemini,
+			in:    `{"type":"object","properties":{"a/b":{"$ref":"#/$defs/Missing"},"c":{"allOf":[{"type":"string"}]}}}`,
+			paths: []string{"/properties/a~1b/$ref", "/properties/c/allOf"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.d.Lower(genai.JSONSchema(tc.in))
+			e, ok := errors.AsType[*ErrSchemaNotSupported](err)
+			if !ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(e.Paths, tc.paths) {
+				t.Fatalf("got %q, want %q", e.Paths, tc.paths)
+			}
+		})
+	}
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := SchemaDialectGemini.Lower(genai.JSONSchema(`[]`)); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestValidateSchema(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"age": {"type": "integer", "minimum": 0},
+			"shape": {"enum": ["circle", "square"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+			"pet": {"$ref": "#/$defs/Pet"},
+			"note": {"anyOf": [{"type": "string"}, {"type": "null"}]}
+		},
+		"required": ["name", "age"],
+		"additionalProperties": false,
+		"$defs": {"Pet": {"type": "object", "properties": {"kind": {"const": "cat"}}, "required": ["kind"]}}
+	}`
+	t.Run("valid", func(t *testing.T) {
+		for _, in := range []string{
+			`{"name":"bob","age":3}`,
+			`{"name":"bob","age":3.0,"shape":"circle","tags":["a","b"],"pet":{"kind":"cat"},"note":null}`,
+		} {
+			if err := ValidateSchema(genai.JSONSchema(schema), []byte(in)); err != nil {
+				t.Errorf("%s: %v", in, err)
+			}
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		tests := []struct {
+			in   string
+			path string
+		}{
+			{`{"name":"bob"}`, ""},
+			{`{"name":"","age":3}`, "/name"},
+			{`{"name":"bob","age":3.5}`, "/age"},
+			{`{"name":"bob","age":-1}`, "/age"},
+			{`{"name":"bob","age":3,"shape":"triangle"}`, "/shape"},
+			{`{"name":"bob","age":3,"tags":["a",1]}`, "/tags/1"},
+			{`{"name":"bob","age":3,"tags":["a","b","c"]}`, "/tags"},
+			{`{"name":"bob","age":3,"pet":{"kind":"dog"}}`, "/pet/kind"},
+			{`{"name":"bob","age":3,"note":1}`, "/note"},
+			{`{"name":"bob","age":3,"extra":true}`, "/extra"},
+			{`{"name":"bob"`, ""},
+			{`[]`, ""},
+		}
+		for _, tc := range tests {
+			err := ValidateSchema(genai.JSONSchema(schema), []byte(tc.in))
+			e, ok := errors.AsType[*ErrSchemaValidation](err)
+			if !ok {
+				t.Errorf("%s: unexpected error: %v", tc.in, err)
+				continue
+			}
+			if e.Path != tc.path {
+				t.Errorf("%s: got path %q, want %q: %s", tc.in, e.Path, tc.path, e.Reason)
+			}
+		}
+	})
+}
@@ -20,7 +20,6 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/providers"
 )
 
@@ -49,16 +48,17 @@ func mainImpl() error {
 	provider := flag.String("provider", "", "backend to use: "+strings.Join(names, ", "))
 	strict := flag.Bool("strict", false, "assert no unknown fields in the APIs are found")
 	flag.Parse()
-	if *strict {
-		internal.BeLenient = false
-	}
 	if *provider == "" {
 		return errors.New("-provider is required")
 	}
 	if !slices.Contains(names, *provider) {
 		return fmt.Errorf("unknown backend %q", *provider)
 	}
-	c, err := providers.All[*provider].Factory(ctx)
+	var opts []genai.ProviderOption
+	if *strict {
+		opts = append(opts, genai.ProviderOptionStrict(true))
+	}
+	c, err := providers.All[*provider].Factory(ctx, opts...)
 	if err != nil {
 		return err
 	}
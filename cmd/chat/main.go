@@ -0,0 +1,388 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Command chat is an interactive multi-turn chat with any provider.
+//
+// It streams the replies, shows the reasoning, exposes the tools of MCP servers to the model and can save and
+// load the conversation. Type /help for the commands.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+	"github.com/maruel/genai/providers"
+)
+
+const help = `Commands:
+  /model [id]      print or change the model, keeping the conversation
+  /system [prompt] print or change the system prompt; "/system -" clears it
+  /retry           generate the last reply again
+  /save <file>     save the conversation as JSON
+  /load <file>     load a conversation saved with /save
+  /clear           start a new conversation
+  /help            print this help
+  /quit            exit
+`
+
+// session is the state of the chat.
+type session struct {
+	provider      string
+	remote        string
+	strict        bool
+	c             genai.Provider
+	text          genai.GenOptionText
+	tools         []genai.ToolDef
+	owners        map[string]*mcpClient
+	msgs          genai.Messages
+	showReasoning bool
+	w             io.Writer
+}
+
+// connect creates the client. An empty model selects a good default model.
+func (s *session) connect(ctx context.Context, model string) error {
+	if model == "" {
+		model = string(genai.ModelGood)
+	}
+	opts := []genai.ProviderOption{genai.ProviderOptionModel(model)}
+	if s.remote != "" {
+		opts = append(opts, genai.ProviderOptionRemote(s.remote))
+	}
+	if s.strict {
+		opts = append(opts, genai.ProviderOptionStrict(true))
+	}
+	c, err := providers.All[s.provider].Factory(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	// Extract the <think> blocks of the models that don't use a separate reasoning channel.
+	s.c = adapters.WrapReasoning(c)
+	_, err = fmt.Fprintf(s.w, "Using %s %s\n", c.Name(), c.ModelID())
+	return err
+}
+
+func (s *session) genOpts() []genai.GenOption {
+	opts := []genai.GenOption{&s.text}
+	if len(s.tools) != 0 {
+		opts = append(opts, &genai.GenOptionTools{Tools: s.tools})
+	}
+	return opts
+}
+
+// send adds the user message and generates the reply.
+func (s *session) send(ctx context.Context, text string) error {
+	if n := len(s.msgs); n != 0 && s.msgs[n-1].Role() == "user" {
+		// The previous generation failed. Replace the unanswered message.
+		s.msgs = s.msgs[:n-1]
+	}
+	s.msgs = append(s.msgs, genai.NewTextMessage(text))
+	return s.generate(ctx)
+}
+
+// retry drops the replies to the last user message and generates them again.
+func (s *session) retry(ctx context.Context) error {
+	for i := len(s.msgs) - 1; i >= 0; i-- {
+		if len(s.msgs[i].Requests) != 0 {
+			s.msgs = s.msgs[:i+1]
+			return s.generate(ctx)
+		}
+	}
+	return errors.New("nothing to retry")
+}
+
+// generate streams the reply to the conversation, running the tool calls until the model is done.
+func (s *session) generate(ctx context.Context) error {
+	for {
+		fragments, finish := s.c.GenStream(ctx, s.msgs, s.genOpts()...)
+		mode := ""
+		for f := range fragments {
+			switch {
+			case f.Reasoning != "":
+				if !s.showReasoning {
+					continue
+				}
+				if mode != "reasoning" {
+					_, _ = io.WriteString(s.w, "[thinking]\n")
+					mode = "reasoning"
+				}
+				_, _ = io.WriteString(s.w, f.Reasoning)
+			case f.Text != "":
+				if mode == "reasoning" {
+					_, _ = io.WriteString(s.w, "\n[answer]\n")
+				}
+				mode = "text"
+				_, _ = io.WriteString(s.w, f.Text)
+			case !f.ToolCall.IsZero():
+				_, _ = fmt.Fprintf(s.w, "\n[tool] %s(%s)\n", f.ToolCall.Name, f.ToolCall.Arguments)
+				mode = "tool"
+			}
+		}
+		res, err := finish()
+		_, _ = io.WriteString(s.w, "\n")
+		if err != nil {
+			return err
+		}
+		s.msgs = append(s.msgs, res.Message)
+		_, _ = fmt.Fprintf(s.w, "(%s)\n", res.Usage.String())
+		tr, err := s.callTools(ctx, &res.Message)
+		if err != nil {
+			// Drop the tool calls without results so the conversation stays valid.
+			s.msgs = s.msgs[:len(s.msgs)-1]
+			return err
+		}
+		if tr.IsZero() {
+			return nil
+		}
+		s.msgs = append(s.msgs, tr)
+	}
+}
+
+// callTools runs the tool calls requested in m on the MCP servers.
+//
+// A failed tool call is reported to the model instead of aborting the conversation.
+func (s *session) callTools(ctx context.Context, m *genai.Message) (genai.Message, error) {
+	var out genai.Message
+	for i := range m.Replies {
+		tc := &m.Replies[i].ToolCall
+		if tc.IsZero() {
+			continue
+		}
+		srv, ok := s.owners[tc.Name]
+		if !ok {
+			return out, fmt.Errorf("model called unknown tool %q", tc.Name)
+		}
+		res, err := srv.CallTool(ctx, tc.Name, tc.Arguments)
+		if err != nil {
+			if ctx.Err() != nil {
+				return out, err
+			}
+			res = "error: " + err.Error()
+		}
+		out.ToolCallResults = append(out.ToolCallResults, genai.ToolCallResult{ID: tc.ID, Name: tc.Name, Result: res})
+	}
+	return out, nil
+}
+
+// command runs a slash command. It returns true when the user wants to exit.
+func (s *session) command(ctx context.Context, line string) (bool, error) {
+	cmd, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch cmd {
+	case "/quit", "/exit":
+		return true, nil
+	case "/help":
+		_, err := io.WriteString(s.w, help)
+		return false, err
+	case "/model":
+		if arg == "" {
+			_, err := fmt.Fprintf(s.w, "%s\n", s.c.ModelID())
+			return false, err
+		}
+		return false, s.connect(ctx, arg)
+	case "/system":
+		switch arg {
+		case "":
+			_, err := fmt.Fprintf(s.w, "%q\n", s.text.SystemPrompt)
+			return false, err
+		case "-":
+			s.text.SystemPrompt = ""
+		default:
+			s.text.SystemPrompt = arg
+		}
+		return false, nil
+	case "/retry":
+		return false, s.retry(ctx)
+	case "/save":
+		if arg == "" {
+			return false, errors.New("usage: /save <file>")
+		}
+		return false, saveHistory(arg, s.msgs)
+	case "/load":
+		if arg == "" {
+			return false, errors.New("usage: /load <file>")
+		}
+		msgs, err := loadHistory(arg)
+		if err != nil {
+			return false, err
+		}
+		s.msgs = msgs
+		_, err = fmt.Fprintf(s.w, "Loaded %d messages\n", len(msgs))
+		return false, err
+	case "/clear":
+		s.msgs = nil
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown command %s; type /help", cmd)
+	}
+}
+
+func saveHistory(path string, msgs genai.Messages) error {
+	b, err := json.MarshalIndent(msgs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
+
+func loadHistory(path string) (genai.Messages, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var msgs genai.Messages
+	if err = json.Unmarshal(b, &msgs); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if err = msgs.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid history %s: %w", path, err)
+	}
+	return msgs, nil
+}
+
+// run is the read-eval-print loop. Ctrl-C interrupts the generation in progress.
+func (s *session) run(ctx context.Context, r io.Reader, history string) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for {
+		_, _ = io.WriteString(s.w, "> ")
+		if !scanner.Scan() {
+			_, _ = io.WriteString(s.w, "\n")
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		turnCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		var err error
+		if strings.HasPrefix(line, "/") {
+			var quit bool
+			if quit, err = s.command(turnCtx, line); quit {
+				stop()
+				return nil
+			}
+		} else {
+			err = s.send(turnCtx, line)
+		}
+		stop()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			_, _ = fmt.Fprintf(s.w, "error: %s\n", err)
+		}
+		if history != "" {
+			if err = saveHistory(history, s.msgs); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// stringsFlag is a repeatable flag.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func mainImpl() error {
+	ctx := context.Background()
+	names := slices.Sorted(maps.Keys(providers.All))
+	provider := flag.String("provider", "", "backend to use: "+strings.Join(names, ", "))
+	flag.StringVar(provider, "p", "", "alias for -provider")
+	model := flag.String("model", "", "model to use; defaults to "+string(genai.ModelGood)+", can be "+string(genai.ModelCheap)+" or "+string(genai.ModelSOTA))
+	flag.StringVar(model, "m", "", "alias for -model")
+	remote := flag.String("remote", "", "URL of the server, for local providers like llamacpp and ollama")
+	system := flag.String("system", "", "system prompt")
+	effort := flag.String("effort", "", "reasoning effort: off, low, medium or high")
+	reasoning := flag.Bool("reasoning", true, "print the reasoning of the model")
+	history := flag.String("history", "", "file to load the conversation from, and to save it to after each turn")
+	var mcp stringsFlag
+	flag.Var(&mcp, "mcp", "command line of a MCP server communicating over stdio, whose tools are exposed to the model; can be repeated")
+	strict := flag.Bool("strict", false, "assert no unknown fields in the APIs are found")
+	flag.Parse()
+	if flag.NArg() != 0 {
+		return errors.New("unexpected arguments")
+	}
+	if *provider == "" {
+		return errors.New("-provider is required")
+	}
+	if !slices.Contains(names, *provider) {
+		return fmt.Errorf("unknown backend %q", *provider)
+	}
+	s := &session{
+		provider:      *provider,
+		remote:        *remote,
+		strict:        *strict,
+		text:          genai.GenOptionText{SystemPrompt: *system, ReasoningEffort: genai.ReasoningEffort(*effort)},
+		showReasoning: *reasoning,
+		w:             os.Stdout,
+	}
+	if *effort != "" {
+		if err := s.text.ReasoningEffort.Validate(); err != nil {
+			return fmt.Errorf("-effort: %w", err)
+		}
+	}
+	if *history != "" {
+		msgs, err := loadHistory(*history)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		s.msgs = msgs
+	}
+	var servers []*mcpClient
+	defer func() {
+		for _, srv := range servers {
+			_ = srv.Close()
+		}
+	}()
+	for _, cmdline := range mcp {
+		srv, err := startMCP(ctx, cmdline)
+		if err != nil {
+			return err
+		}
+		servers = append(servers, srv)
+	}
+	var err error
+	if s.tools, s.owners, err = mcpTools(ctx, servers); err != nil {
+		return err
+	}
+	if err = s.connect(ctx, *model); err != nil {
+		return err
+	}
+	if len(s.tools) != 0 {
+		_, _ = fmt.Fprintf(s.w, "%d tools available from %d MCP servers\n", len(s.tools), len(servers))
+	}
+	if len(s.msgs) != 0 {
+		_, _ = fmt.Fprintf(s.w, "Loaded %d messages\n", len(s.msgs))
+	}
+	return s.run(ctx, os.Stdin, *history)
+}
+
+func main() {
+	if err := mainImpl(); err != nil {
+		if !errors.Is(err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "chat: %s\n", err)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Minimal Model Context Protocol client to expose the tools of MCP servers to the model.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/maruel/genai"
+)
+
+// mcpProtocolVersion is the MCP revision implemented.
+//
+// See https://modelcontextprotocol.io/specification/2025-06-18/basic/lifecycle
+const mcpProtocolVersion = "2025-06-18"
+
+// mcpClient talks JSON-RPC 2.0 to an MCP server over newline delimited JSON.
+type mcpClient struct {
+	name string
+	w    io.Writer
+	r    *bufio.Reader
+	wait func() error
+
+	mu     sync.Mutex
+	lastID int64
+}
+
+// startMCP starts the MCP server cmdline as a subprocess and initializes the session.
+func startMCP(ctx context.Context, cmdline string) (*mcpClient, error) {
+	args := strings.Fields(cmdline)
+	if len(args) == 0 {
+		return nil, errors.New("empty MCP server command")
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server %q: %w", args[0], err)
+	}
+	c := &mcpClient{
+		name: args[0],
+		w:    stdin,
+		r:    bufio.NewReader(stdout),
+		wait: func() error {
+			_ = stdin.Close()
+			return cmd.Wait()
+		},
+	}
+	if err = c.initialize(ctx); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close stops the server.
+func (c *mcpClient) Close() error {
+	if c.wait == nil {
+		return nil
+	}
+	return c.wait()
+}
+
+func (c *mcpClient) initialize(ctx context.Context) error {
+	in := map[string]any{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]string{"name": "genai-chat", "version": "1.0.0"},
+	}
+	var out struct {
+		ServerInfo struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	if err := c.call(ctx, "initialize", in, &out); err != nil {
+		return fmt.Errorf("failed to initialize MCP server %q: %w", c.name, err)
+	}
+	if out.ServerInfo.Name != "" {
+		c.name = out.ServerInfo.Name
+	}
+	return c.notify("notifications/initialized")
+}
+
+// mcpTool is a tool as returned by tools/list.
+type mcpTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// Tools returns the tools exposed by the server.
+func (c *mcpClient) Tools(ctx context.Context) ([]mcpTool, error) {
+	var all []mcpTool
+	cursor := ""
+	for {
+		in := map[string]any{}
+		if cursor != "" {
+			in["cursor"] = cursor
+		}
+		var out struct {
+			Tools      []mcpTool `json:"tools"`
+			NextCursor string    `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", in, &out); err != nil {
+			return nil, fmt.Errorf("failed to list the tools of MCP server %q: %w", c.name, err)
+		}
+		all = append(all, out.Tools...)
+		if cursor = out.NextCursor; cursor == "" {
+			return all, nil
+		}
+	}
+}
+
+// CallTool runs a tool and returns its text content.
+func (c *mcpClient) CallTool(ctx context.Context, name, arguments string) (string, error) {
+	args := json.RawMessage(arguments)
+	if len(strings.TrimSpace(arguments)) == 0 {
+		args = json.RawMessage("{}")
+	}
+	in := map[string]any{"name": name, "arguments": args}
+	var out struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := c.call(ctx, "tools/call", in, &out); err != nil {
+		return "", err
+	}
+	var texts []string
+	for _, ct := range out.Content {
+		if ct.Type == "text" {
+			texts = append(texts, ct.Text)
+		} else {
+			texts = append(texts, "["+ct.Type+" content omitted]")
+		}
+	}
+	s := strings.Join(texts, "\n")
+	if out.IsError {
+		// Return the error to the model so it can recover.
+		return "error: " + s, nil
+	}
+	return s, nil
+}
+
+type jsonrpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *jsonrpcError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+func (c *mcpClient) notify(method string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.send(&jsonrpcMessage{JSONRPC: "2.0", Method: method})
+}
+
+// call sends a request and waits for its response. Requests are serialized.
+func (c *mcpClient) call(ctx context.Context, method string, in, out any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastID++
+	id := strconv.FormatInt(c.lastID, 10)
+	if err := c.send(&jsonrpcMessage{JSONRPC: "2.0", ID: json.RawMessage(id), Method: method, Params: in}); err != nil {
+		return err
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line, err := c.r.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read from MCP server: %w", err)
+		}
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Result json.RawMessage `json:"result"`
+			Error  *jsonrpcError   `json:"error"`
+		}
+		if err = json.Unmarshal(line, &msg); err != nil {
+			return fmt.Errorf("failed to decode MCP message: %w", err)
+		}
+		if msg.Method != "" {
+			if len(msg.ID) != 0 {
+				// Server to client requests (sampling, roots, elicitation) are not supported.
+				r := &jsonrpcMessage{JSONRPC: "2.0", ID: msg.ID, Error: &jsonrpcError{Code: -32601, Message: "method not found"}}
+				if err = c.send(r); err != nil {
+					return err
+				}
+			}
+			// Notifications are ignored.
+			continue
+		}
+		if string(msg.ID) != id {
+			continue
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if out == nil {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, out)
+	}
+}
+
+func (c *mcpClient) send(msg *jsonrpcMessage) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = c.w.Write(append(b, '\n'))
+	return err
+}
+
+// mcpTools returns the tools exposed by the MCP servers and the server owning each tool.
+func mcpTools(ctx context.Context, servers []*mcpClient) ([]genai.ToolDef, map[string]*mcpClient, error) {
+	var defs []genai.ToolDef
+	owners := map[string]*mcpClient{}
+	for _, s := range servers {
+		tools, err := s.Tools(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, t := range tools {
+			if _, ok := owners[t.Name]; ok {
+				return nil, nil, fmt.Errorf("tool %q is exposed by both %q and %q", t.Name, owners[t.Name].name, s.name)
+			}
+			owners[t.Name] = s
+			desc := t.Description
+			if desc == "" {
+				desc = t.Name
+			}
+			schema := genai.JSONSchema(t.InputSchema)
+			if len(schema) == 0 {
+				schema = genai.JSONSchema(`{"type":"object","properties":{}}`)
+			}
+			d := genai.ToolDef{Name: t.Name, Description: desc, InputSchemaOverride: schema}
+			if err := d.Validate(); err != nil {
+				return nil, nil, fmt.Errorf("MCP server %q: tool %q: %w", s.name, t.Name, err)
+			}
+			defs = append(defs, d)
+		}
+	}
+	return defs, owners, nil
+}
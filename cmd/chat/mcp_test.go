@@ -0,0 +1,98 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the MCP client of the chat command.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+// fakeMCP serves a single "echo" tool. It sends a notification and a server request before each response to
+// exercise the client's handling of interleaved messages.
+func fakeMCP(t *testing.T, r io.Reader, w io.Writer) {
+	d := json.NewDecoder(r)
+	e := json.NewEncoder(w)
+	for {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Name      string          `json:"name"`
+				Arguments json.RawMessage `json:"arguments"`
+			} `json:"params"`
+		}
+		if err := d.Decode(&req); err != nil {
+			return
+		}
+		if len(req.ID) == 0 {
+			continue
+		}
+		var result any
+		switch req.Method {
+		case "initialize":
+			result = map[string]any{"protocolVersion": mcpProtocolVersion, "serverInfo": map[string]string{"name": "fake"}}
+		case "tools/list":
+			result = map[string]any{"tools": []map[string]any{{"name": "echo", "description": "Echoes.", "inputSchema": map[string]any{"type": "object"}}}}
+		case "tools/call":
+			result = map[string]any{"content": []map[string]string{{"type": "text", "text": string(req.Params.Arguments)}}}
+		}
+		_ = e.Encode(map[string]any{"jsonrpc": "2.0", "method": "notifications/message"})
+		_ = e.Encode(map[string]any{"jsonrpc": "2.0", "id": "srv-1", "method": "roots/list"})
+		// Consume the client's error response to the server request.
+		var ack struct {
+			ID    string        `json:"id"`
+			Error *jsonrpcError `json:"error"`
+		}
+		if err := d.Decode(&ack); err != nil {
+			return
+		}
+		if ack.ID != "srv-1" || ack.Error == nil || ack.Error.Code != -32601 {
+			t.Errorf("unexpected reply to server request: %+v", ack)
+		}
+		_ = e.Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}
+}
+
+func TestMCPClient(t *testing.T) {
+	cr, sw := io.Pipe()
+	sr, cw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fakeMCP(t, sr, sw)
+	}()
+	c := &mcpClient{name: "test", w: cw, r: bufio.NewReader(cr)}
+	ctx := t.Context()
+	if err := c.initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.name != "fake" {
+		t.Errorf("name = %q", c.name)
+	}
+	defs, owners, err := mcpTools(ctx, []*mcpClient{c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 1 || defs[0].Name != "echo" || owners["echo"] != c {
+		t.Fatalf("unexpected tools: %+v", defs)
+	}
+	got, err := c.CallTool(ctx, "echo", `{"a":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"a":1}` {
+		t.Errorf("CallTool() = %q", got)
+	}
+	if _, _, err = mcpTools(ctx, []*mcpClient{c, c}); err == nil {
+		t.Error("expected duplicate tool error")
+	}
+	_ = cw.Close()
+	<-done
+	_ = sw.Close()
+}
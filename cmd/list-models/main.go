@@ -7,6 +7,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -20,7 +21,6 @@ import (
 	"syscall"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/providers"
 	"github.com/maruel/genai/providers/huggingface"
 )
@@ -77,53 +77,150 @@ func printStructDense(v any, indent string) string {
 	return strings.Join(fields, "\n")
 }
 
-func getModels(ctx context.Context, provider string) ([]string, map[string]genai.Model, error) {
+// model is a model as listed by the provider.
+type model struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Rank lists the automatic selections resolving to this model: "sota", "good" and "cheap".
+	Rank []string `json:"rank,omitempty"`
+	// Details is the provider specific model description, only included with -all.
+	Details any `json:"details,omitempty"`
+
+	m genai.Model
+}
+
+// label is the name followed by the rank medals.
+func (m *model) label() string {
+	s := m.Name
+	for _, r := range m.Rank {
+		switch r {
+		case "sota":
+			s += " 🥇"
+		case "good":
+			s += " 🥈"
+		case "cheap":
+			s += " 🥉"
+		}
+	}
+	return s
+}
+
+// snapshot is the JSON output, keyed by provider name.
+type snapshot map[string][]model
+
+func getModels(ctx context.Context, provider string, strict bool) ([]model, error) {
 	cfg := providers.All[provider]
-	c, err := cfg.Factory(ctx)
+	var opts []genai.ProviderOption
+	if strict {
+		opts = append(opts, genai.ProviderOptionStrict(true))
+	}
+	c, err := cfg.Factory(ctx, opts...)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	models, err := c.ListModels(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	var cheap, good, sota string
-	if c2, err2 := cfg.Factory(ctx, genai.ModelCheap); err2 == nil {
+	if c2, err2 := cfg.Factory(ctx, append(opts, genai.ModelCheap)...); err2 == nil {
 		cheap = c2.ModelID()
 	}
-	if c2, err2 := cfg.Factory(ctx, genai.ModelGood); err2 == nil {
+	if c2, err2 := cfg.Factory(ctx, append(opts, genai.ModelGood)...); err2 == nil {
 		good = c2.ModelID()
 	}
-	if c2, err2 := cfg.Factory(ctx, genai.ModelSOTA); err2 == nil {
+	if c2, err2 := cfg.Factory(ctx, append(opts, genai.ModelSOTA)...); err2 == nil {
 		sota = c2.ModelID()
 	}
 
-	m := make(map[string]genai.Model, len(models))
-	names := make([]string, 0, len(models))
-	for _, model := range models {
-		if t, ok := model.(*huggingface.Model); ok && t.TrendingScore < 1 {
+	out := make([]model, 0, len(models))
+	for _, m := range models {
+		if t, ok := m.(*huggingface.Model); ok && t.TrendingScore < 1 {
 			continue
 		}
-		name := model.String()
-		id := model.GetID()
+		e := model{ID: m.GetID(), Name: m.String(), m: m}
 		// The same model can be in multiple categories.
-		if id == sota {
-			name += " 🥇"
+		if e.ID == sota {
+			e.Rank = append(e.Rank, "sota")
 		}
-		if id == good {
-			name += " 🥈"
+		if e.ID == good {
+			e.Rank = append(e.Rank, "good")
 		}
-		if id == cheap {
-			name += " 🥉"
+		if e.ID == cheap {
+			e.Rank = append(e.Rank, "cheap")
 		}
-		names = append(names, name)
-		m[name] = model
+		out = append(out, e)
 	}
-	sort.Slice(names, func(i, j int) bool {
-		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
 	})
-	return names, m, nil
+	return out, nil
+}
+
+// getSnapshot lists the models of each provider.
+//
+// When more than one provider is requested, the providers that fail, usually because no API key is
+// configured, are reported to stderr and omitted.
+func getSnapshot(ctx context.Context, names []string, details, strict bool) (snapshot, error) {
+	snap := snapshot{}
+	for _, name := range names {
+		models, err := getModels(ctx, name, strict)
+		if err != nil {
+			if len(names) == 1 || ctx.Err() != nil {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "ignoring provider %s: %v\n", name, err)
+			continue
+		}
+		if details {
+			for i := range models {
+				models[i].Details = models[i].m
+			}
+		}
+		snap[name] = models
+	}
+	return snap, nil
+}
+
+func loadSnapshot(path string) (snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap snapshot
+	if err = json.Unmarshal(b, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// diffSnapshots returns the models added and removed in cur compared to old, as "provider/id".
+//
+// Providers missing from cur are ignored, so a provider that failed to list its models isn't reported as having
+// removed all of them.
+func diffSnapshots(old, cur snapshot) (added, removed []string) {
+	for _, p := range slices.Sorted(maps.Keys(cur)) {
+		before := map[string]struct{}{}
+		for _, m := range old[p] {
+			before[m.ID] = struct{}{}
+		}
+		after := map[string]struct{}{}
+		for _, m := range cur[p] {
+			after[m.ID] = struct{}{}
+			if _, ok := before[m.ID]; !ok {
+				added = append(added, p+"/"+m.ID)
+			}
+		}
+		for _, m := range old[p] {
+			if _, ok := after[m.ID]; !ok {
+				removed = append(removed, p+"/"+m.ID)
+			}
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
 }
 
 func mainImpl() error {
@@ -131,31 +228,77 @@ func mainImpl() error {
 	defer stop()
 
 	names := slices.Sorted(maps.Keys(providers.All))
-	provider := flag.String("provider", "", "backend to use: "+strings.Join(names, ", "))
+	provider := flag.String("provider", "", "backends to use, comma separated, or \"all\": "+strings.Join(names, ", "))
 	flag.StringVar(provider, "p", "", "alias for -provider")
 	all := flag.Bool("all", false, "include all details")
+	asJSON := flag.Bool("json", false, "print the models as JSON; the output can be used as a -diff snapshot")
+	diff := flag.String("diff", "", "compare the models against a snapshot saved with -json, print the added and removed models and exit with an error if any changed")
 	strict := flag.Bool("strict", false, "assert no unknown fields in the APIs are found")
 	flag.Parse()
 	if flag.NArg() != 0 {
 		return errors.New("unexpected arguments")
 	}
-	if *strict {
-		internal.BeLenient = false
-	}
 	if *provider == "" {
 		return errors.New("-provider is required")
 	}
-	if !slices.Contains(names, *provider) {
-		return fmt.Errorf("unknown backend %q", *provider)
+	if *asJSON && *diff != "" {
+		return errors.New("-json and -diff are mutually exclusive")
+	}
+	selected := names
+	if *provider != "all" {
+		selected = strings.Split(*provider, ",")
+		for _, p := range selected {
+			if !slices.Contains(names, p) {
+				return fmt.Errorf("unknown backend %q", p)
+			}
+		}
 	}
-	names, models, err := getModels(ctx, *provider)
+	var old snapshot
+	if *diff != "" {
+		var err error
+		if old, err = loadSnapshot(*diff); err != nil {
+			return err
+		}
+	}
+	snap, err := getSnapshot(ctx, selected, *all, *strict)
 	if err != nil {
 		return err
 	}
-	for _, name := range names {
-		fmt.Printf("%s\n", name)
-		if *all {
-			_, _ = os.Stdout.WriteString(printStructDense(models[name], "  ") + "\n")
+	switch {
+	case *asJSON:
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		return e.Encode(snap)
+	case *diff != "":
+		added, removed := diffSnapshots(old, snap)
+		for _, m := range added {
+			fmt.Printf("+ %s\n", m)
+		}
+		for _, m := range removed {
+			fmt.Printf("- %s\n", m)
+		}
+		if len(added) != 0 || len(removed) != 0 {
+			return fmt.Errorf("%d models added, %d removed", len(added), len(removed))
+		}
+		return nil
+	}
+	indent := ""
+	if len(selected) > 1 {
+		indent = "  "
+	}
+	for _, p := range selected {
+		models, ok := snap[p]
+		if !ok {
+			continue
+		}
+		if indent != "" {
+			fmt.Printf("%s:\n", p)
+		}
+		for i := range models {
+			fmt.Printf("%s%s\n", indent, models[i].label())
+			if *all {
+				_, _ = os.Stdout.WriteString(printStructDense(models[i].m, indent+"  ") + "\n")
+			}
 		}
 	}
 	return nil
@@ -0,0 +1,45 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the list-models command.
+
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	t.Parallel()
+	const raw = `{"a":[{"id":"m1","name":"m1"},{"id":"m2","name":"m2","rank":["good"]}],"b":[{"id":"x","name":"x"}]}`
+	var old snapshot
+	if err := json.Unmarshal([]byte(raw), &old); err != nil {
+		t.Fatal(err)
+	}
+	cur := snapshot{
+		"a": {{ID: "m2", Name: "m2"}, {ID: "m3", Name: "m3"}},
+		// "b" failed to list its models and must not be reported as removed.
+		"c": {{ID: "y", Name: "y"}},
+	}
+	added, removed := diffSnapshots(old, cur)
+	if want := []string{"a/m3", "c/y"}; !slices.Equal(added, want) {
+		t.Errorf("added = %v, want %v", added, want)
+	}
+	if want := []string{"a/m1"}; !slices.Equal(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	if added, removed = diffSnapshots(old, old); len(added) != 0 || len(removed) != 0 {
+		t.Errorf("unexpected diff: %v %v", added, removed)
+	}
+}
+
+func TestModelLabel(t *testing.T) {
+	t.Parallel()
+	m := model{Name: "m", Rank: []string{"sota", "cheap"}}
+	if got := m.label(); got != "m 🥇 🥉" {
+		t.Errorf("label() = %q", got)
+	}
+}
@@ -11,17 +11,37 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 )
 
 func mainImpl() error {
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 	table := flag.Bool("table", false, "output a markdown table")
-	provider := flag.String("provider", "", "output a table only for one provider")
+	provider := flag.String("provider", "", "with -table or -all, only use this provider")
 	model := flag.String("model", "", "run a smoke test on the model and output the JSON")
+	all := flag.Bool("all", false, "run a smoke test on the first model of every scenario of every provider, or only -provider")
+	jobs := flag.Int("j", 8, "with -all, maximum number of smoke tests running at once")
+	perProvider := flag.Int("per-provider", 2, "with -all, maximum number of smoke tests running at once against a single provider")
+	dir := flag.String("results", "scoreboard-results", "with -all, directory where each result is saved as soon as it completes")
+	resume := flag.Bool("resume", false, "with -all, skip the models that already have a result in -results")
 	flag.Parse()
 	if flag.NArg() != 0 {
 		return errors.New("unexpected arguments")
 	}
+	if *all {
+		if *model != "" || *table {
+			return errors.New("-all is incompatible with -model and -table")
+		}
+		j, err := listJobs(ctx, os.Stderr, *provider)
+		if err != nil {
+			return err
+		}
+		return runAll(ctx, os.Stderr, j, &runOptions{Jobs: *jobs, PerProvider: *perProvider, Dir: *dir, Resume: *resume}, runSmoke)
+	}
+	if *resume {
+		return errors.New("-resume requires -all")
+	}
 	if *model != "" {
 		if *provider == "" {
 			return errors.New("-model requires -provider")
@@ -33,7 +53,7 @@ func mainImpl() error {
 	}
 	if *provider != "" {
 		// TODO: Add it to list too.
-		return errors.New("-provider requires -table")
+		return errors.New("-provider requires -table or -all")
 	}
 	return printList(ctx, os.Stdout)
 }
@@ -0,0 +1,188 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Parallel smoke testing of every provider with checkpointing of the results.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/providers"
+	"github.com/maruel/genai/scoreboard"
+)
+
+// runOptions controls runAll.
+type runOptions struct {
+	// Jobs is the maximum number of smoke tests running at once.
+	Jobs int
+	// PerProvider is the maximum number of smoke tests running at once against a single provider, to stay
+	// under its rate limits.
+	PerProvider int
+	// Dir is the directory where each result is saved as soon as it completes.
+	Dir string
+	// Resume skips the models that already have a result in Dir.
+	Resume bool
+}
+
+// job is the smoke test of one model.
+type job struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+// result is the checkpoint of a completed job.
+type result struct {
+	job
+	Scenario scoreboard.Scenario `json:"scenario"`
+	Usage    genai.Usage         `json:"usage"`
+}
+
+// path returns the checkpoint file of the job.
+func (j *job) path(dir string) string {
+	// Model IDs can contain slashes and colons, e.g. "accounts/fireworks/models/x" or "qwen3:8b".
+	name := strings.NewReplacer("/", "_", ":", "_", "\\", "_").Replace(j.Model)
+	return filepath.Join(dir, j.Provider, name+".json")
+}
+
+// listJobs returns the first model of each scenario of the providers. An empty provider selects all of them.
+func listJobs(ctx context.Context, w io.Writer, provider string) ([]job, error) {
+	names := slices.Sorted(maps.Keys(providers.All))
+	if provider != "" {
+		if _, ok := providers.All[provider]; !ok {
+			return nil, fmt.Errorf("unknown provider %q", provider)
+		}
+		names = []string{provider}
+	}
+	var jobs []job
+	for _, name := range names {
+		// The factory returns a client even without an API key, which is enough to read the scoreboard.
+		c, err := providers.All[name].Factory(ctx)
+		if c == nil {
+			_, _ = fmt.Fprintf(w, "ignoring provider %s: %v\n", name, err)
+			continue
+		}
+		var seen []string
+		for _, sc := range c.Scoreboard().Scenarios {
+			if len(sc.Models) == 0 || slices.Contains(seen, sc.Models[0]) {
+				continue
+			}
+			seen = append(seen, sc.Models[0])
+			jobs = append(jobs, job{Provider: name, Model: sc.Models[0]})
+		}
+	}
+	return jobs, nil
+}
+
+// runAll runs the smoke test of every job, saving each result to opts.Dir.
+//
+// A failed job doesn't stop the others and is not saved, so that it is retried with opts.Resume. The errors
+// are returned once all the jobs completed.
+func runAll(ctx context.Context, w io.Writer, jobs []job, opts *runOptions, fn func(context.Context, *job) (scoreboard.Scenario, genai.Usage, error)) error {
+	if opts.Jobs < 1 || opts.PerProvider < 1 {
+		return errors.New("the concurrency limits must be at least 1")
+	}
+	var todo []job
+	for i := range jobs {
+		if opts.Resume {
+			if _, err := loadResult(jobs[i].path(opts.Dir)); err == nil {
+				continue
+			}
+		}
+		todo = append(todo, jobs[i])
+	}
+	if skipped := len(jobs) - len(todo); skipped != 0 {
+		_, _ = fmt.Fprintf(w, "resuming: skipping %d completed models\n", skipped)
+	}
+
+	global := make(chan struct{}, opts.Jobs)
+	perProvider := map[string]chan struct{}{}
+	for i := range todo {
+		if _, ok := perProvider[todo[i].Provider]; !ok {
+			perProvider[todo[i].Provider] = make(chan struct{}, opts.PerProvider)
+		}
+	}
+	var mu sync.Mutex
+	var errs []error
+	var usage genai.Usage
+	done := 0
+	var wg sync.WaitGroup
+	for i := range todo {
+		j := &todo[i]
+		wg.Go(func() {
+			// Take the provider slot first so a rate limited provider doesn't hold global slots while waiting.
+			select {
+			case perProvider[j.Provider] <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-perProvider[j.Provider] }()
+			select {
+			case global <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-global }()
+			sc, u, err := fn(ctx, j)
+			if err == nil {
+				err = saveResult(j.path(opts.Dir), &result{job: *j, Scenario: sc, Usage: u})
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			usage.Add(&u)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s/%s: %w", j.Provider, j.Model, err))
+				_, _ = fmt.Fprintf(w, "[%d/%d] %s/%s: %v\n", done, len(todo), j.Provider, j.Model, err)
+				return
+			}
+			_, _ = fmt.Fprintf(w, "[%d/%d] %s/%s: ok\n", done, len(todo), j.Provider, j.Model)
+		})
+	}
+	wg.Wait()
+	_, _ = fmt.Fprintf(w, "Usage: %s\n", usage.String())
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+func loadResult(path string) (*result, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &result{}
+	if err = json.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// saveResult writes the result atomically so an interrupted run never leaves a truncated checkpoint.
+func saveResult(path string, r *result) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
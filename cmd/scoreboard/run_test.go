@@ -0,0 +1,79 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the parallel smoke testing of the scoreboard command.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/scoreboard"
+)
+
+func TestRunAll(t *testing.T) {
+	t.Parallel()
+	jobs := []job{
+		{Provider: "a", Model: "m1"},
+		{Provider: "a", Model: "org/m2"},
+		{Provider: "a", Model: "m3:8b"},
+		{Provider: "b", Model: "m1"},
+		{Provider: "b", Model: "bad"},
+	}
+	opts := &runOptions{Jobs: 3, PerProvider: 1, Dir: t.TempDir()}
+	var mu sync.Mutex
+	running := map[string]int{}
+	total := 0
+	var ran []string
+	fn := func(ctx context.Context, j *job) (scoreboard.Scenario, genai.Usage, error) {
+		mu.Lock()
+		running[j.Provider]++
+		total++
+		if running[j.Provider] > opts.PerProvider || total > opts.Jobs {
+			t.Errorf("concurrency limit exceeded: %v", running)
+		}
+		ran = append(ran, j.Provider+"/"+j.Model)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running[j.Provider]--
+			total--
+			mu.Unlock()
+		}()
+		if j.Model == "bad" {
+			return scoreboard.Scenario{}, genai.Usage{InputTokens: 1}, errors.New("flaky")
+		}
+		return scoreboard.Scenario{Models: []string{j.Model}}, genai.Usage{InputTokens: 10}, nil
+	}
+	ctx := t.Context()
+	err := runAll(ctx, &internaltest.WriterToLog{T: t}, jobs, opts, fn)
+	if err == nil || err.Error() != "b/bad: flaky" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ran) != len(jobs) {
+		t.Fatalf("ran %v", ran)
+	}
+	r, err := loadResult(jobs[1].path(opts.Dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Provider != "a" || r.Model != "org/m2" || r.Scenario.Models[0] != "org/m2" || r.Usage.InputTokens != 10 {
+		t.Errorf("unexpected checkpoint: %+v", r)
+	}
+
+	// Only the failed job is run again.
+	ran = nil
+	opts.Resume = true
+	if err = runAll(ctx, &internaltest.WriterToLog{T: t}, jobs, opts, fn); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(ran) != 1 || ran[0] != "b/bad" {
+		t.Errorf("ran %v", ran)
+	}
+}
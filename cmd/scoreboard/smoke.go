@@ -15,20 +15,12 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/providers"
+	"github.com/maruel/genai/scoreboard"
 	"github.com/maruel/genai/smoke"
 )
 
 func smokeModel(ctx context.Context, w io.Writer, provider, model string) error {
-	// TODO: We may want to run in strict mode?
-	// TODO: We will want to record the HTTP requests, it's wasteful otherwise.
-	if _, err := providers.All[provider].Factory(ctx, genai.ProviderOptionModel(model)); err != nil {
-		return err
-	}
-	pf := func(name string) genai.Provider {
-		p, _ := providers.All[provider].Factory(ctx, genai.ProviderOptionModel(model))
-		return p
-	}
-	sc, usage, err := smoke.Run(ctx, pf)
+	sc, usage, err := runSmoke(ctx, &job{Provider: provider, Model: model})
 	if err != nil {
 		return err
 	}
@@ -37,3 +29,17 @@ func smokeModel(ctx context.Context, w io.Writer, provider, model string) error
 	_, _ = fmt.Fprintf(os.Stderr, "Usage: %s\n", usage.String())
 	return nil
 }
+
+// runSmoke runs the smoke test on one model.
+func runSmoke(ctx context.Context, j *job) (scoreboard.Scenario, genai.Usage, error) {
+	// TODO: We may want to run in strict mode?
+	// TODO: We will want to record the HTTP requests, it's wasteful otherwise.
+	if _, err := providers.All[j.Provider].Factory(ctx, genai.ProviderOptionModel(j.Model)); err != nil {
+		return scoreboard.Scenario{}, genai.Usage{}, err
+	}
+	pf := func(name string) genai.Provider {
+		p, _ := providers.All[j.Provider].Factory(ctx, genai.ProviderOptionModel(j.Model))
+		return p
+	}
+	return smoke.Run(ctx, pf)
+}
@@ -0,0 +1,367 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package eval runs user-defined evaluations against one or more providers.
+//
+// A Suite is a list of Case, each a prompt with the criteria its reply must meet. The criteria are checked
+// programmatically, e.g. the reply contains a string or matches a regexp, and by a judge model scoring the
+// reply against a rubric. The Report can be written as JSON or markdown to compare models.
+//
+// It generalizes the smoke package, which checks the features of a provider, to the quality of the replies.
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/maruel/genai"
+)
+
+// DefaultJudgePrompt is the instruction sent to Suite.Judge when JudgePrompt is empty.
+const DefaultJudgePrompt = "You are grading the reply of an AI assistant against the criteria below. Reply with a score " +
+	"from 0 to 10 on the first line, 10 meaning the criteria are fully met, followed by a one sentence " +
+	"justification."
+
+// Case is an evaluation case.
+type Case struct {
+	// Name identifies the case in the report.
+	Name string `json:"name"`
+	// Prompt is the user message. It is ignored when Messages is set.
+	Prompt string `json:"prompt,omitzero"`
+	// Messages is the conversation to send, for multi-turn cases.
+	Messages genai.Messages `json:"messages,omitzero"`
+	// SystemPrompt is sent as genai.GenOptionText.SystemPrompt.
+	SystemPrompt string `json:"system_prompt,omitzero"`
+	// Criteria is the rubric given to the judge. The judge is not used when empty.
+	Criteria string `json:"criteria,omitzero"`
+	// Contains are strings that must be in the reply, case insensitive.
+	Contains []string `json:"contains,omitzero"`
+	// NotContains are strings that must not be in the reply, case insensitive.
+	NotContains []string `json:"not_contains,omitzero"`
+	// Regexp must match the reply.
+	Regexp string `json:"regexp,omitzero"`
+	// Checks are additional programmatic checks. An error fails the case.
+	Checks []Check `json:"-"`
+
+	_ struct{}
+}
+
+// Check is a programmatic check of a reply.
+type Check func(res *genai.Result) error
+
+// Validate implements genai.Validatable.
+func (c *Case) Validate() error {
+	if c.Name == "" {
+		return errors.New("field Name: required")
+	}
+	if c.Prompt == "" && len(c.Messages) == 0 {
+		return errors.New("field Prompt: required when Messages is empty")
+	}
+	if len(c.Messages) != 0 {
+		if err := c.Messages.Validate(); err != nil {
+			return fmt.Errorf("field Messages: %w", err)
+		}
+	}
+	if c.Criteria == "" && len(c.Contains) == 0 && len(c.NotContains) == 0 && c.Regexp == "" && len(c.Checks) == 0 {
+		return errors.New("at least one of Criteria, Contains, NotContains, Regexp or Checks is required")
+	}
+	if c.Regexp != "" {
+		if _, err := regexp.Compile(c.Regexp); err != nil {
+			return fmt.Errorf("field Regexp: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Case) messages() genai.Messages {
+	if len(c.Messages) != 0 {
+		return c.Messages
+	}
+	return genai.Messages{genai.NewTextMessage(c.Prompt)}
+}
+
+// Suite is a list of cases and how to score them.
+type Suite struct {
+	// Cases to run.
+	Cases []Case `json:"cases"`
+	// Judge scores the replies against Case.Criteria. It is required when a case has criteria.
+	Judge genai.Provider `json:"-"`
+	// JudgePrompt is the instruction sent to Judge. Defaults to DefaultJudgePrompt.
+	JudgePrompt string `json:"judge_prompt,omitzero"`
+	// Threshold is the minimum judge score, between 0 and 1, for a case to pass. Defaults to 0.7.
+	Threshold float64 `json:"threshold,omitzero"`
+	// Workers is the number of cases run concurrently. Defaults to 4.
+	Workers int `json:"workers,omitzero"`
+	// Options are sent with each request.
+	Options []genai.GenOption `json:"-"`
+
+	_ struct{}
+}
+
+// Validate implements genai.Validatable.
+func (s *Suite) Validate() error {
+	if len(s.Cases) == 0 {
+		return errors.New("field Cases: required")
+	}
+	names := map[string]struct{}{}
+	for i := range s.Cases {
+		if err := s.Cases[i].Validate(); err != nil {
+			return fmt.Errorf("field Cases: item #%d: %w", i, err)
+		}
+		if _, ok := names[s.Cases[i].Name]; ok {
+			return fmt.Errorf("field Cases: item #%d: duplicate name %q", i, s.Cases[i].Name)
+		}
+		names[s.Cases[i].Name] = struct{}{}
+		if s.Cases[i].Criteria != "" && s.Judge == nil {
+			return fmt.Errorf("field Judge: required by case %q", s.Cases[i].Name)
+		}
+	}
+	if s.Threshold < 0 || s.Threshold > 1 {
+		return errors.New("field Threshold: must be between 0 and 1")
+	}
+	if s.Workers < 0 {
+		return errors.New("field Workers: must be positive")
+	}
+	return nil
+}
+
+// LoadSuite loads a JSON encoded Suite. Judge, Options and Case.Checks must be set afterward.
+func LoadSuite(path string) (*Suite, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Suite{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Report is the result of Run.
+type Report struct {
+	Providers []ProviderReport `json:"providers"`
+}
+
+// ProviderReport is the result of a suite for a provider.
+type ProviderReport struct {
+	Provider string       `json:"provider"`
+	Model    string       `json:"model"`
+	Results  []CaseResult `json:"results"`
+	// Passed is the number of cases that passed.
+	Passed int `json:"passed"`
+	// Score is the mean judge score of the cases with criteria.
+	Score float64 `json:"score,omitzero"`
+	// Usage is the usage of the provider, excluding the judge.
+	Usage genai.Usage `json:"usage"`
+}
+
+// CaseResult is the result of a case.
+type CaseResult struct {
+	Case     string        `json:"case"`
+	Reply    string        `json:"reply"`
+	Passed   bool          `json:"passed"`
+	Failures []string      `json:"failures,omitzero"`
+	Score    float64       `json:"score,omitzero"`
+	Judgment string        `json:"judgment,omitzero"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitzero"`
+}
+
+// Run runs the suite against each provider.
+//
+// Errors of individual requests fail the corresponding case and are recorded in the report; only an invalid
+// suite or a canceled context returns an error.
+func Run(ctx context.Context, providers []genai.Provider, s *Suite) (*Report, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	r := &Report{Providers: make([]ProviderReport, len(providers))}
+	eg, ctx := errgroup.WithContext(ctx)
+	workers := s.Workers
+	if workers == 0 {
+		workers = 4
+	}
+	eg.SetLimit(workers)
+	var mu sync.Mutex
+	for i, p := range providers {
+		pr := &r.Providers[i]
+		pr.Provider = p.Name()
+		pr.Model = p.ModelID()
+		pr.Results = make([]CaseResult, len(s.Cases))
+		for j := range s.Cases {
+			eg.Go(func() error {
+				res := runCase(ctx, p, s, &s.Cases[j], &pr.Results[j])
+				mu.Lock()
+				pr.Usage.Add(&res.Usage)
+				mu.Unlock()
+				return ctx.Err()
+			})
+		}
+	}
+	if err := eg.Wait(); err != nil {
+		return r, err
+	}
+	for i := range r.Providers {
+		pr := &r.Providers[i]
+		judged := 0
+		for j := range pr.Results {
+			if pr.Results[j].Passed {
+				pr.Passed++
+			}
+			if s.Cases[j].Criteria != "" {
+				pr.Score += pr.Results[j].Score
+				judged++
+			}
+		}
+		if judged != 0 {
+			pr.Score /= float64(judged)
+		}
+	}
+	return r, nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(r)
+}
+
+// WriteMarkdown writes the report as a markdown summary table followed by the failures.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Provider | Model | Passed | Score | Tokens |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for i := range r.Providers {
+		pr := &r.Providers[i]
+		fmt.Fprintf(&b, "| %s | %s | %d/%d | %.2f | %d |\n", pr.Provider, pr.Model, pr.Passed, len(pr.Results), pr.Score, pr.Usage.InputTokens+pr.Usage.OutputTokens)
+	}
+	for i := range r.Providers {
+		pr := &r.Providers[i]
+		header := false
+		for j := range pr.Results {
+			c := &pr.Results[j]
+			if c.Passed {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(&b, "\n## %s/%s failures\n\n", pr.Provider, pr.Model)
+				header = true
+			}
+			fmt.Fprintf(&b, "- **%s**:", c.Case)
+			if c.Error != "" {
+				fmt.Fprintf(&b, " error: %s", c.Error)
+			}
+			for _, f := range c.Failures {
+				fmt.Fprintf(&b, " %s;", f)
+			}
+			if c.Judgment != "" {
+				fmt.Fprintf(&b, " judge: %s", c.Judgment)
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//
+
+// runCase runs a case and fills out. It returns the provider's result.
+func runCase(ctx context.Context, p genai.Provider, s *Suite, c *Case, out *CaseResult) genai.Result {
+	out.Case = c.Name
+	opts := s.Options
+	if c.SystemPrompt != "" {
+		opts = append(append([]genai.GenOption{}, opts...), &genai.GenOptionText{SystemPrompt: c.SystemPrompt})
+	}
+	start := time.Now()
+	res, err := p.GenSync(ctx, c.messages(), opts...)
+	out.Duration = time.Since(start)
+	if err != nil {
+		out.Error = err.Error()
+		return res
+	}
+	out.Reply = res.String()
+	lower := strings.ToLower(out.Reply)
+	for _, want := range c.Contains {
+		if !strings.Contains(lower, strings.ToLower(want)) {
+			out.Failures = append(out.Failures, fmt.Sprintf("missing %q", want))
+		}
+	}
+	for _, bad := range c.NotContains {
+		if strings.Contains(lower, strings.ToLower(bad)) {
+			out.Failures = append(out.Failures, fmt.Sprintf("unexpected %q", bad))
+		}
+	}
+	if c.Regexp != "" && !regexp.MustCompile(c.Regexp).MatchString(out.Reply) {
+		out.Failures = append(out.Failures, fmt.Sprintf("doesn't match %q", c.Regexp))
+	}
+	for _, check := range c.Checks {
+		if err := check(&res); err != nil {
+			out.Failures = append(out.Failures, err.Error())
+		}
+	}
+	passed := len(out.Failures) == 0
+	if c.Criteria != "" {
+		score, judgment, err := judge(ctx, s, c, out.Reply)
+		if err != nil {
+			out.Error = err.Error()
+			return res
+		}
+		out.Score = score
+		out.Judgment = judgment
+		threshold := s.Threshold
+		if threshold == 0 {
+			threshold = 0.7
+		}
+		passed = passed && score >= threshold
+	}
+	out.Passed = passed
+	return res
+}
+
+// judge returns the judge's score between 0 and 1 and its justification.
+func judge(ctx context.Context, s *Suite, c *Case, reply string) (float64, string, error) {
+	prompt := s.JudgePrompt
+	if prompt == "" {
+		prompt = DefaultJudgePrompt
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\nCriteria:\n%s\n\nConversation:\n", prompt, c.Criteria)
+	for _, m := range c.messages() {
+		fmt.Fprintf(&b, "%s: %s\n", m.Role(), m.String())
+	}
+	fmt.Fprintf(&b, "\nReply to grade:\n%s\n", reply)
+	res, err := s.Judge.GenSync(ctx, genai.Messages{genai.NewTextMessage(b.String())})
+	if err != nil {
+		return 0, "", fmt.Errorf("judge failed: %w", err)
+	}
+	txt := strings.TrimSpace(res.String())
+	first, rest, _ := strings.Cut(txt, "\n")
+	first = strings.TrimSpace(first)
+	end := strings.IndexFunc(first, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if end == -1 {
+		end = len(first)
+	}
+	score, err := strconv.ParseFloat(strings.TrimSuffix(first[:end], "."), 64)
+	if err != nil || score < 0 || score > 10 {
+		return 0, "", fmt.Errorf("judge replied with an invalid score: %q", txt)
+	}
+	if rest = strings.TrimSpace(rest); rest == "" {
+		rest = strings.TrimSpace(first[end:])
+	}
+	return score / 10, rest, nil
+}
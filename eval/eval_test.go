@@ -0,0 +1,209 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package eval_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/eval"
+	"github.com/maruel/genai/providers/fake"
+	"github.com/maruel/genai/scoreboard"
+)
+
+func TestRun(t *testing.T) {
+	p := &mockProvider{replies: map[string]fake.Turn{
+		"What is the capital of France?":   {Text: "Paris is the capital of France.", Usage: genai.Usage{InputTokens: 10, OutputTokens: 5}},
+		"What is the capital of Atlantis?": {Text: "I don't know."},
+		"Reply with a number.":             {Text: "42"},
+		"Hi":                               {Error: "server error"},
+	}}
+	judge := &mockProvider{replies: map[string]fake.Turn{
+		"Paris is the capital of France.": {Text: "9\nAccurate and concise."},
+		"I don't know.":                   {Text: "2. It didn't answer."},
+	}}
+	s := &eval.Suite{
+		Cases: []eval.Case{
+			{Name: "capital", Prompt: "What is the capital of France?", Criteria: "Answers Paris.", Contains: []string{"paris"}},
+			{Name: "unknown", Prompt: "What is the capital of Atlantis?", Criteria: "Says it's a myth."},
+			{
+				Name:   "number",
+				Prompt: "Reply with a number.",
+				Regexp: `^\d+$`,
+				Checks: []eval.Check{func(res *genai.Result) error {
+					if res.String() != "43" {
+						return errors.New("want 43")
+					}
+					return nil
+				}},
+			},
+			{Name: "error", Prompt: "Hi", NotContains: []string{"bye"}},
+		},
+		Judge:   judge,
+		Workers: 1,
+	}
+	r, err := eval.Run(t.Context(), []genai.Provider{p}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr := &r.Providers[0]
+	if pr.Provider != "mock" || pr.Model != "model" || pr.Passed != 1 || pr.Usage.OutputTokens != 5 {
+		t.Fatalf("unexpected report %+v", pr)
+	}
+	if got := pr.Score; got < 0.549 || got > 0.551 {
+		t.Fatalf("got score %f, want 0.55", got)
+	}
+	want := []struct {
+		passed   bool
+		failures string
+		judgment string
+		err      string
+	}{
+		{true, "", "Accurate and concise.", ""},
+		{false, "", "It didn't answer.", ""},
+		{false, "want 43", "", ""},
+		{false, "", "", "server error"},
+	}
+	for i, w := range want {
+		c := &pr.Results[i]
+		if c.Passed != w.passed || strings.Join(c.Failures, ", ") != w.failures || c.Judgment != w.judgment || c.Error != w.err {
+			t.Errorf("#%d: unexpected %+v", i, c)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := r.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	wantMD := "| Provider | Model | Passed | Score | Tokens |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| mock | model | 1/4 | 0.55 | 15 |\n" +
+		"\n## mock/model failures\n\n" +
+		"- **unknown**: judge: It didn't answer.\n" +
+		"- **number**: want 43;\n" +
+		"- **error**: error: server error\n"
+	if s := b.String(); s != wantMD {
+		t.Fatalf("unexpected markdown:\n%s", s)
+	}
+	b.Reset()
+	if err := r.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var r2 eval.Report
+	if err := json.Unmarshal(b.Bytes(), &r2); err != nil {
+		t.Fatal(err)
+	}
+	if r2.Providers[0].Passed != 1 {
+		t.Fatalf("unexpected %+v", r2)
+	}
+}
+
+func TestRun_judge_invalid(t *testing.T) {
+	p, err := fake.New(&fake.Script{Turns: []fake.Turn{{Text: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	judge, err := fake.New(&fake.Script{Turns: []fake.Turn{{Text: "great"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &eval.Suite{Cases: []eval.Case{{Name: "a", Prompt: "hi", Criteria: "greets"}}, Judge: judge}
+	r, err := eval.Run(t.Context(), []genai.Provider{p}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := r.Providers[0].Results[0].Error; e != `judge replied with an invalid score: "great"` {
+		t.Fatalf("unexpected error %q", e)
+	}
+}
+
+func TestSuite_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       eval.Suite
+		wantErr string
+	}{
+		{"empty", eval.Suite{}, "field Cases: required"},
+		{"no_name", eval.Suite{Cases: []eval.Case{{Prompt: "a", Contains: []string{"a"}}}}, "field Cases: item #0: field Name: required"},
+		{"no_prompt", eval.Suite{Cases: []eval.Case{{Name: "a", Contains: []string{"a"}}}}, "field Cases: item #0: field Prompt: required when Messages is empty"},
+		{"no_check", eval.Suite{Cases: []eval.Case{{Name: "a", Prompt: "a"}}}, "field Cases: item #0: at least one of Criteria, Contains, NotContains, Regexp or Checks is required"},
+		{"regexp", eval.Suite{Cases: []eval.Case{{Name: "a", Prompt: "a", Regexp: "("}}}, "field Cases: item #0: field Regexp: error parsing regexp: missing closing ): `(`"},
+		{"duplicate", eval.Suite{Cases: []eval.Case{{Name: "a", Prompt: "a", Regexp: "a"}, {Name: "a", Prompt: "a", Regexp: "a"}}}, "field Cases: item #1: duplicate name \"a\""},
+		{"no_judge", eval.Suite{Cases: []eval.Case{{Name: "a", Prompt: "a", Criteria: "a"}}}, "field Judge: required by case \"a\""},
+		{"threshold", eval.Suite{Cases: []eval.Case{{Name: "a", Prompt: "a", Regexp: "a"}}, Threshold: 2}, "field Threshold: must be between 0 and 1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.s.Validate(); err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("got %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadSuite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suite.json")
+	data := `{"cases": [{"name": "a", "prompt": "hi", "contains": ["hello"]}], "threshold": 0.5}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := eval.LoadSuite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Cases[0].Contains[0] != "hello" || s.Threshold != 0.5 {
+		t.Fatalf("unexpected %+v", s)
+	}
+}
+
+// mockProvider replies with the turn whose key is in the last line of the request.
+type mockProvider struct {
+	base.NotImplemented
+	replies map[string]fake.Turn
+}
+
+func (m *mockProvider) Name() string {
+	return "mock"
+}
+
+func (m *mockProvider) ModelID() string {
+	return "model"
+}
+
+func (m *mockProvider) OutputModalities() genai.Modalities {
+	return genai.Modalities{genai.ModalityText}
+}
+
+func (m *mockProvider) HTTPClient() *http.Client {
+	return nil
+}
+
+func (m *mockProvider) Scoreboard() scoreboard.Score {
+	return scoreboard.Score{}
+}
+
+func (m *mockProvider) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	s := strings.TrimSpace(msgs[len(msgs)-1].String())
+	t, ok := m.replies[s[strings.LastIndexByte(s, '\n')+1:]]
+	if !ok {
+		return genai.Result{}, errors.New("unexpected request " + s)
+	}
+	if t.Error != "" {
+		return genai.Result{}, errors.New(t.Error)
+	}
+	return genai.Result{Message: genai.Message{Replies: []genai.Reply{{Text: t.Text}}}, Usage: t.Usage}, nil
+}
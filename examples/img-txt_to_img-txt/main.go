@@ -10,9 +10,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
 
 	"github.com/maruel/genai"
@@ -45,33 +43,16 @@ func main() {
 		log.Fatal(err)
 	}
 	for i := range res.Replies {
-		r := &res.Replies[i]
-		if r.Doc.IsZero() {
-			fmt.Println(r.Text)
-			continue
+		if res.Replies[i].Doc.IsZero() {
+			fmt.Println(res.Replies[i].Text)
 		}
-		// The image can be returned as an URL or inline, depending on the provider.
-		var src io.Reader
-		if r.Doc.URL != "" {
-			req, err := c.HTTPClient().Get(r.Doc.URL)
-			if err != nil {
-				log.Fatal(err)
-			} else if req.StatusCode != http.StatusOK {
-				log.Fatal(req.StatusCode)
-			}
-			src = req.Body
-			defer func() { _ = req.Body.Close() }()
-		} else {
-			src = r.Doc.Src
-		}
-		b, err := io.ReadAll(src)
-		if err != nil {
-			log.Fatal(err)
-		}
-		name := r.Doc.GetFilename()
+	}
+	// The image can be returned as an URL or inline, depending on the provider. SaveDocs handles both.
+	names, err := res.SaveDocs(ctx, c.HTTPClient(), ".", "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, name := range names {
 		fmt.Printf("Wrote: %s\n", name)
-		if err = os.WriteFile(name, b, 0o644); err != nil {
-			log.Fatal(err)
-		}
 	}
 }
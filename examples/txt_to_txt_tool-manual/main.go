@@ -57,7 +57,7 @@ func main() {
 	msgs = append(msgs, res.Message)
 
 	// Process the tool call from the assistant.
-	msg, err := res.DoToolCalls(ctx, opts.Tools)
+	msg, err := res.DoToolCalls(ctx, opts.AllowedTools())
 	if err != nil {
 		log.Fatalf("Error calling tool: %v", err)
 	}
@@ -39,6 +39,9 @@ func main() {
 	fragments, finish := c.GenStream(ctx, msgs, &opts)
 	firstText := true
 	for f := range fragments {
+		if f.ServerToolCall.Name == genai.ServerToolWebSearch && f.ServerToolCall.Status == genai.ServerToolCallInProgress {
+			fmt.Printf("Searching the web…\n")
+		}
 		if !f.Citation.IsZero() {
 			fmt.Printf("Sources:\n")
 			for i := range f.Citation.Sources {
@@ -0,0 +1,321 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package finetune prepares fine-tuning datasets from genai.Messages.
+//
+// It converts conversations into the chat-format JSONL files expected by the fine-tuning APIs and validates
+// them beforehand, so that a malformed dataset is caught locally instead of after an upload.
+//
+// Supported formats:
+//   - OpenAI: https://platform.openai.com/docs/guides/supervised-fine-tuning
+//   - Mistral: https://docs.mistral.ai/capabilities/finetuning/
+package finetune
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/maruel/genai"
+)
+
+// Format is the target fine-tuning dataset format.
+type Format string
+
+const (
+	// FormatOpenAI is OpenAI's chat fine-tuning format.
+	FormatOpenAI Format = "openai"
+	// FormatMistral is Mistral's instruct fine-tuning format.
+	//
+	// It is mostly the same as OpenAI's but tool call IDs must be exactly 9 alphanumeric characters.
+	FormatMistral Format = "mistral"
+)
+
+// Validate implements genai.Validatable.
+func (f Format) Validate() error {
+	switch f {
+	case FormatOpenAI, FormatMistral:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q", string(f))
+	}
+}
+
+// Writer writes conversations as a fine-tuning JSONL dataset.
+type Writer struct {
+	// Format is the target dataset format.
+	Format Format
+	// SystemPrompt is prepended as a system message to every example when set.
+	SystemPrompt string
+	// Tools is added to every example when set. This is needed when the conversations include tool calls.
+	Tools []genai.ToolDef
+	// MaxTokens is the maximum number of tokens per example. 0 means no limit.
+	//
+	// OpenAI's limit depends on the model, e.g. 65536 for gpt-4o-mini. Mistral's is the model's context.
+	MaxTokens int64
+	// CountTokens returns the number of tokens in an example. If nil, EstimateTokens is used.
+	CountTokens func(e *Example) int64
+
+	_ struct{}
+}
+
+// Validate implements genai.Validatable.
+func (w *Writer) Validate() error {
+	if err := w.Format.Validate(); err != nil {
+		return err
+	}
+	if w.MaxTokens < 0 {
+		return errors.New("field MaxTokens: must be non-negative")
+	}
+	for i := range w.Tools {
+		if w.Tools[i].Name == "" {
+			return fmt.Errorf("tool %d: field Name is required", i)
+		}
+	}
+	return nil
+}
+
+// Write validates all the conversations then writes them to dst, one example per line.
+//
+// Nothing is written if any conversation is invalid.
+func (w *Writer) Write(dst io.Writer, convs []genai.Messages) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	examples := make([]Example, len(convs))
+	var errs []error
+	for i := range convs {
+		var err error
+		if examples[i], err = w.Convert(convs[i]); err != nil {
+			errs = append(errs, fmt.Errorf("conversation %d: %w", i, err))
+		}
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+	e := json.NewEncoder(dst)
+	e.SetEscapeHTML(false)
+	for i := range examples {
+		if err := e.Encode(&examples[i]); err != nil {
+			return fmt.Errorf("conversation %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Convert converts and validates a single conversation.
+func (w *Writer) Convert(msgs genai.Messages) (Example, error) {
+	out := Example{}
+	if err := w.Format.Validate(); err != nil {
+		return out, err
+	}
+	if w.SystemPrompt != "" {
+		out.Messages = append(out.Messages, Message{Role: "system", Content: w.SystemPrompt})
+	}
+	for i := range msgs {
+		if err := out.add(&msgs[i]); err != nil {
+			return out, fmt.Errorf("message %d: %w", i, err)
+		}
+	}
+	for i := range w.Tools {
+		t := Tool{Type: "function"}
+		t.Function.Name = w.Tools[i].Name
+		t.Function.Description = w.Tools[i].Description
+		var err error
+		if t.Function.Parameters, err = w.Tools[i].GetInputSchema(); err != nil {
+			return out, fmt.Errorf("tool %q: %w", w.Tools[i].Name, err)
+		}
+		out.Tools = append(out.Tools, t)
+	}
+	if err := out.validate(w.Format); err != nil {
+		return out, err
+	}
+	if w.MaxTokens > 0 {
+		count := w.CountTokens
+		if count == nil {
+			count = EstimateTokens
+		}
+		if n := count(&out); n > w.MaxTokens {
+			return out, fmt.Errorf("example has %d tokens, more than the limit of %d", n, w.MaxTokens)
+		}
+	}
+	return out, nil
+}
+
+// EstimateTokens returns a rough estimate of the number of tokens in an example, assuming 4 bytes per token
+// plus a small per message overhead.
+func EstimateTokens(e *Example) int64 {
+	var n int64
+	for i := range e.Messages {
+		m := &e.Messages[i]
+		l := len(m.Content)
+		for j := range m.ToolCalls {
+			l += len(m.ToolCalls[j].Function.Name) + len(m.ToolCalls[j].Function.Arguments)
+		}
+		n += int64(l+3)/4 + 4
+	}
+	for i := range e.Tools {
+		n += int64(len(e.Tools[i].Function.Name)+len(e.Tools[i].Function.Description)+len(e.Tools[i].Function.Parameters)+3) / 4
+	}
+	return n
+}
+
+// Example is one line in the dataset.
+type Example struct {
+	Messages []Message `json:"messages"`
+	Tools    []Tool    `json:"tools,omitzero"`
+}
+
+func (e *Example) add(m *genai.Message) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	switch r := m.Role(); r {
+	case "user":
+		var txt []string
+		for i := range m.Requests {
+			if !m.Requests[i].Doc.IsZero() {
+				return errors.New("documents are not supported in fine-tuning datasets")
+			}
+			txt = append(txt, m.Requests[i].Text)
+		}
+		e.Messages = append(e.Messages, Message{Role: "user", Content: strings.Join(txt, "\n")})
+	case "assistant":
+		msg := Message{Role: "assistant"}
+		var txt []string
+		for i := range m.Replies {
+			rep := &m.Replies[i]
+			switch {
+			case rep.Text != "":
+				txt = append(txt, rep.Text)
+			case !rep.ToolCall.IsZero():
+				tc := ToolCall{ID: rep.ToolCall.ID, Type: "function"}
+				tc.Function.Name = rep.ToolCall.Name
+				tc.Function.Arguments = rep.ToolCall.Arguments
+				msg.ToolCalls = append(msg.ToolCalls, tc)
+			case rep.Reasoning != "", len(rep.Opaque) != 0:
+				// Reasoning is not part of the training data.
+			default:
+				return fmt.Errorf("reply %d: only text and tool calls are supported in fine-tuning datasets", i)
+			}
+		}
+		msg.Content = strings.Join(txt, "")
+		e.Messages = append(e.Messages, msg)
+	case "computer":
+		for i := range m.ToolCallResults {
+			e.Messages = append(e.Messages, Message{
+				Role:       "tool",
+				Content:    m.ToolCallResults[i].Result,
+				ToolCallID: m.ToolCallResults[i].ID,
+			})
+		}
+	default:
+		return fmt.Errorf("unsupported role %q", r)
+	}
+	return nil
+}
+
+// validate checks the role ordering.
+func (e *Example) validate(f Format) error {
+	var pending []string
+	last := ""
+	for i := range e.Messages {
+		m := &e.Messages[i]
+		switch m.Role {
+		case "system":
+			if i != 0 {
+				return fmt.Errorf("message %d: system message must be first", i)
+			}
+		case "user":
+			if last == "user" {
+				return fmt.Errorf("message %d: two consecutive user messages", i)
+			}
+			if len(pending) != 0 {
+				return fmt.Errorf("message %d: missing tool results for %s", i, strings.Join(pending, ", "))
+			}
+		case "assistant":
+			if last == "" || last == "system" {
+				return fmt.Errorf("message %d: conversation must start with a user message", i)
+			}
+			if last == "assistant" {
+				return fmt.Errorf("message %d: two consecutive assistant messages", i)
+			}
+			if len(pending) != 0 {
+				return fmt.Errorf("message %d: missing tool results for %s", i, strings.Join(pending, ", "))
+			}
+			if m.Content == "" && len(m.ToolCalls) == 0 {
+				return fmt.Errorf("message %d: empty assistant message", i)
+			}
+			for j := range m.ToolCalls {
+				id := m.ToolCalls[j].ID
+				if id == "" {
+					return fmt.Errorf("message %d: tool call %d: field ID is required", i, j)
+				}
+				if f == FormatMistral && !isMistralToolCallID(id) {
+					return fmt.Errorf("message %d: tool call %d: ID %q must be 9 alphanumeric characters", i, j, id)
+				}
+				pending = append(pending, id)
+			}
+		case "tool":
+			idx := -1
+			for j, id := range pending {
+				if id == m.ToolCallID {
+					idx = j
+					break
+				}
+			}
+			if idx == -1 {
+				return fmt.Errorf("message %d: unexpected tool result for %q", i, m.ToolCallID)
+			}
+			pending = append(pending[:idx], pending[idx+1:]...)
+		}
+		last = m.Role
+	}
+	if last != "assistant" {
+		return errors.New("conversation must end with an assistant message")
+	}
+	return nil
+}
+
+func isMistralToolCallID(id string) bool {
+	if len(id) != 9 {
+		return false
+	}
+	for _, c := range id {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Message is a message in an Example.
+type Message struct {
+	Role       string     `json:"role"` // "system", "user", "assistant", "tool"
+	Content    string     `json:"content,omitzero"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitzero"`
+	ToolCallID string     `json:"tool_call_id,omitzero"`
+}
+
+// ToolCall is a tool call requested by the assistant.
+type ToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"` // "function"
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// Tool is a tool available to the assistant.
+type Tool struct {
+	Type     string `json:"type"` // "function"
+	Function struct {
+		Name        string           `json:"name"`
+		Description string           `json:"description,omitzero"`
+		Parameters  genai.JSONSchema `json:"parameters,omitzero"`
+	} `json:"function"`
+}
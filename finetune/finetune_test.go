@@ -0,0 +1,139 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the finetune package.
+
+package finetune
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+)
+
+func TestWriter_Write(t *testing.T) {
+	toolConv := genai.Messages{
+		genai.NewTextMessage("What's the weather in Paris?"),
+		{Replies: []genai.Reply{{ToolCall: genai.ToolCall{ID: "call12345", Name: "weather", Arguments: `{"city":"Paris"}`}}}},
+		{ToolCallResults: []genai.ToolCallResult{{ID: "call12345", Name: "weather", Result: "sunny"}}},
+		{Replies: []genai.Reply{{Text: "It is sunny."}}},
+	}
+	tests := []struct {
+		name  string
+		w     Writer
+		convs []genai.Messages
+		want  string
+	}{
+		{
+			name: "openai",
+			w:    Writer{Format: FormatOpenAI, SystemPrompt: "Be concise."},
+			convs: []genai.Messages{{
+				genai.NewTextMessage("Hi"),
+				{Replies: []genai.Reply{{Reasoning: "greet"}, {Text: "Hello"}}},
+			}},
+			want: `{"messages":[{"role":"system","content":"Be concise."},{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello"}]}` + "\n",
+		},
+		{
+			name:  "mistral_tools",
+			w:     Writer{Format: FormatMistral},
+			convs: []genai.Messages{toolConv},
+			want:  `{"messages":[{"role":"user","content":"What's the weather in Paris?"},{"role":"assistant","tool_calls":[{"id":"call12345","type":"function","function":{"name":"weather","arguments":"{\"city\":\"Paris\"}"}}]},{"role":"tool","content":"sunny","tool_call_id":"call12345"},{"role":"assistant","content":"It is sunny."}]}` + "\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tc.w.Write(&b, tc.convs); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tc.want {
+				t.Fatalf("unexpected output:\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWriter_Write_error(t *testing.T) {
+	tests := []struct {
+		name  string
+		w     Writer
+		convs []genai.Messages
+		want  string
+	}{
+		{
+			name: "format",
+			w:    Writer{Format: "foo"},
+			want: `unsupported format "foo"`,
+		},
+		{
+			name:  "ends_with_user",
+			w:     Writer{Format: FormatOpenAI},
+			convs: []genai.Messages{{genai.NewTextMessage("Hi")}},
+			want:  "conversation 0: conversation must end with an assistant message",
+		},
+		{
+			name: "starts_with_assistant",
+			w:    Writer{Format: FormatOpenAI},
+			convs: []genai.Messages{{
+				{Replies: []genai.Reply{{Text: "Hello"}}},
+			}},
+			want: "conversation 0: message 0: conversation must start with a user message",
+		},
+		{
+			name: "consecutive_user",
+			w:    Writer{Format: FormatOpenAI},
+			convs: []genai.Messages{{
+				genai.NewTextMessage("Hi"),
+				genai.NewTextMessage("Hi again"),
+				{Replies: []genai.Reply{{Text: "Hello"}}},
+			}},
+			want: "conversation 0: message 1: two consecutive user messages",
+		},
+		{
+			name: "mistral_tool_call_id",
+			w:    Writer{Format: FormatMistral},
+			convs: []genai.Messages{{
+				genai.NewTextMessage("Hi"),
+				{Replies: []genai.Reply{{ToolCall: genai.ToolCall{ID: "call_1", Name: "weather", Arguments: "{}"}}}},
+				{ToolCallResults: []genai.ToolCallResult{{ID: "call_1", Name: "weather", Result: "sunny"}}},
+				{Replies: []genai.Reply{{Text: "Sunny"}}},
+			}},
+			want: `conversation 0: message 1: tool call 0: ID "call_1" must be 9 alphanumeric characters`,
+		},
+		{
+			name: "missing_tool_result",
+			w:    Writer{Format: FormatOpenAI},
+			convs: []genai.Messages{{
+				genai.NewTextMessage("Hi"),
+				{Replies: []genai.Reply{{ToolCall: genai.ToolCall{ID: "call_1", Name: "weather", Arguments: "{}"}}}},
+				genai.NewTextMessage("Hi again"),
+				{Replies: []genai.Reply{{Text: "Hello"}}},
+			}},
+			want: "conversation 0: message 2: missing tool results for call_1",
+		},
+		{
+			name: "max_tokens",
+			w:    Writer{Format: FormatOpenAI, MaxTokens: 10},
+			convs: []genai.Messages{{
+				genai.NewTextMessage(strings.Repeat("word ", 20)),
+				{Replies: []genai.Reply{{Text: "Hello"}}},
+			}},
+			want: "conversation 0: example has 35 tokens, more than the limit of 10",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := tc.w.Write(&b, tc.convs)
+			if err == nil || err.Error() != tc.want {
+				t.Fatalf("unexpected error:\ngot:  %v\nwant: %s", err, tc.want)
+			}
+			if b.Len() != 0 {
+				t.Fatalf("unexpected output: %s", b.String())
+			}
+		})
+	}
+}
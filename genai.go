@@ -23,8 +23,11 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/bb"
@@ -146,6 +149,9 @@ type Result struct {
 	//
 	// Some providers only return the probability for the chosen tokens and not for the candidates.
 	Logprobs [][]Logprob
+	// Candidates are the additional candidates sampled when GenOptionText.N is greater than 1. The first
+	// candidate is the embedded Message.
+	Candidates []Message
 }
 
 // Validate ensures the result is valid.
@@ -166,6 +172,11 @@ func (r *Result) Validate() error {
 			}
 		}
 	}
+	for i := range r.Candidates {
+		if err := r.Candidates[i].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("candidate #%d: %w", i+1, err))
+		}
+	}
 	return errors.Join(errs...)
 }
 
@@ -202,10 +213,24 @@ type Usage struct {
 	TotalTokens       int64
 	// FinishReason indicates why the model stopped generating tokens.
 	FinishReason FinishReason
+	// StopSequence is the entry of GenOptionText.Stop that ended the generation when FinishReason is
+	// FinishedStopSequence. Empty when the provider doesn't report it.
+	StopSequence string
 	// ServiceTier is the quality of service tier used to process the request,
 	// as reported by the provider. Common values include "standard", "batch",
 	// "flex", "default", "auto", etc. Empty when not reported.
 	ServiceTier string
+	// InferenceProvider is the backend that served the request when the provider routes requests to other
+	// inference providers, e.g. "together" for HuggingFace or "DeepInfra" for OpenRouter. Empty when not
+	// reported.
+	InferenceProvider string
+	// Cost is the cost of the request in USD as billed by the provider. Zero when not reported.
+	Cost float64
+	// WebSearchRequests is the number of server-side web searches done by the provider, which are usually
+	// billed per request.
+	WebSearchRequests int64
+	// WebFetchRequests is the number of server-side web page fetches done by the provider.
+	WebFetchRequests int64
 	// Limits contains a list of rate limit details from the provider.
 	Limits []RateLimit
 }
@@ -214,6 +239,18 @@ func (u *Usage) String() string {
 	var s strings.Builder
 	fmt.Fprintf(&s, "in: %d (cached %d), reasoning: %d, out: %d, total: %d",
 		u.InputTokens, u.InputCachedTokens, u.ReasoningTokens, u.OutputTokens, u.TotalTokens)
+	if u.InferenceProvider != "" {
+		fmt.Fprintf(&s, ", served by: %s", u.InferenceProvider)
+	}
+	if u.Cost != 0 {
+		fmt.Fprintf(&s, ", cost: $%g", u.Cost)
+	}
+	if u.WebSearchRequests != 0 {
+		fmt.Fprintf(&s, ", web searches: %d", u.WebSearchRequests)
+	}
+	if u.WebFetchRequests != 0 {
+		fmt.Fprintf(&s, ", web fetches: %d", u.WebFetchRequests)
+	}
 	for _, l := range u.Limits {
 		fmt.Fprintf(&s, ", %s", l.String())
 	}
@@ -227,6 +264,9 @@ func (u *Usage) Add(r *Usage) {
 	u.ReasoningTokens += r.ReasoningTokens
 	u.OutputTokens += r.OutputTokens
 	u.TotalTokens += r.TotalTokens
+	u.Cost += r.Cost
+	u.WebSearchRequests += r.WebSearchRequests
+	u.WebFetchRequests += r.WebFetchRequests
 }
 
 // RateLimitType defines the type of rate limit.
@@ -325,6 +365,9 @@ const (
 	FinishedStopSequence FinishReason = "stop"
 	// FinishedContentFilter means the model stopped because the reply got caught by a content filter.
 	FinishedContentFilter FinishReason = "content_filter"
+	// FinishedPauseTurn means the provider paused a long running turn, generally while doing server-side tool
+	// calls. Send the reply back as-is in a subsequent request to let the model continue its turn.
+	FinishedPauseTurn FinishReason = "pause_turn"
 	// Pending means that it's not finished yet. For use with ProviderGenAsync.
 	Pending FinishReason = "pending"
 )
@@ -480,6 +523,9 @@ func (m *Message) Reasoning() string {
 //
 // Requires using either ReplyAsJSON or DecodeAs in the GenOptionText.
 //
+// When x is a pointer to a non-struct type, like a slice, a reply wrapped in an object with a single "value"
+// property is unwrapped. See GenOptionText.DecodeAs.
+//
 // Note: this doesn't verify the type is the same as specified in
 // GenOptionText.DecodeAs.
 func (m *Message) Decode(x any) error {
@@ -487,6 +533,13 @@ func (m *Message) Decode(x any) error {
 	if s == "" {
 		return fmt.Errorf("only text messages can be decoded as JSON, can't decode %#v", m)
 	}
+	if t := reflect.TypeOf(x); t != nil && t.Kind() == reflect.Pointer && t.Elem().Kind() != reflect.Struct && t.Elem().Kind() != reflect.Interface {
+		// Unwrap the value as generated by GenOptionText.DecodeSchema for non-struct types.
+		var w map[string]json.RawMessage
+		if json.Unmarshal([]byte(s), &w) == nil && len(w) == 1 && w[decodeWrapKey] != nil {
+			s = string(w[decodeWrapKey])
+		}
+	}
 	d := json.NewDecoder(strings.NewReader(s))
 	d.DisallowUnknownFields()
 	d.UseNumber()
@@ -498,6 +551,10 @@ func (m *Message) Decode(x any) error {
 
 // DoToolCalls processes all the ToolCall in the Reply if any.
 //
+// Only the tools in tools are run. Pass GenOptionTools.AllowedTools() to honor GenOptionTools.Allow and
+// GenOptionTools.Deny. A call to a tool not in tools or a callback that panics doesn't abort the processing;
+// it is returned to the LLM as an error in the ToolCallResult instead.
+//
 // Returns a Message to be added back to the list of messages, only if msg.IsZero() is true.
 func (m *Message) DoToolCalls(ctx context.Context, tools []ToolDef) (Message, error) {
 	var out Message
@@ -505,19 +562,52 @@ func (m *Message) DoToolCalls(ctx context.Context, tools []ToolDef) (Message, er
 		if m.Replies[i].ToolCall.IsZero() {
 			continue
 		}
-		res, err := m.Replies[i].ToolCall.Call(ctx, tools)
+		res, err := m.Replies[i].ToolCall.do(ctx, tools)
 		if err != nil {
 			return out, err
 		}
-		out.ToolCallResults = append(out.ToolCallResults, ToolCallResult{
-			ID:     m.Replies[i].ToolCall.ID,
-			Name:   m.Replies[i].ToolCall.Name,
-			Result: res,
-		})
+		out.ToolCallResults = append(out.ToolCallResults, res)
 	}
 	return out, nil
 }
 
+// DoToolCallsConcurrently is like DoToolCalls but runs up to limit tool calls concurrently. There is no limit
+// when limit is 0 or less.
+//
+// The results are in the same order as the tool calls. All the tool calls are run even if some fail; their
+// errors are joined.
+func (m *Message) DoToolCallsConcurrently(ctx context.Context, tools []ToolDef, limit int) (Message, error) {
+	var calls []*ToolCall
+	for i := range m.Replies {
+		if !m.Replies[i].ToolCall.IsZero() {
+			calls = append(calls, &m.Replies[i].ToolCall)
+		}
+	}
+	var out Message
+	if len(calls) == 0 {
+		return out, nil
+	}
+	if limit <= 0 || limit > len(calls) {
+		limit = len(calls)
+	}
+	out.ToolCallResults = make([]ToolCallResult, len(calls))
+	errs := make([]error, len(calls))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, t := range calls {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			var err error
+			if out.ToolCallResults[i], err = t.do(ctx, tools); err != nil {
+				errs[i] = fmt.Errorf("tool call %q: %w", t.Name, err)
+			}
+		})
+	}
+	wg.Wait()
+	return out, errors.Join(errs...)
+}
+
 // UnmarshalJSON adds validation during decoding.
 func (m *Message) UnmarshalJSON(b []byte) error {
 	type Alias Message
@@ -614,6 +704,27 @@ func (m *Message) Accumulate(mf *Reply) error {
 		return nil
 	}
 
+	if !mf.ServerToolCall.IsZero() {
+		// Update the pending call it refers to, if any. A new pending call without ID is always a new call.
+		if mf.ServerToolCall.ID != "" || mf.ServerToolCall.Status != ServerToolCallInProgress {
+			for i := len(m.Replies) - 1; i >= 0; i-- {
+				s := &m.Replies[i].ServerToolCall
+				if s.ID == mf.ServerToolCall.ID && s.Name == mf.ServerToolCall.Name && s.Status == ServerToolCallInProgress {
+					s.Status = mf.ServerToolCall.Status
+					if mf.ServerToolCall.Input != "" {
+						s.Input = mf.ServerToolCall.Input
+					}
+					if mf.ServerToolCall.Output != "" {
+						s.Output = mf.ServerToolCall.Output
+					}
+					return nil
+				}
+			}
+		}
+		m.Replies = append(m.Replies, Reply{ServerToolCall: mf.ServerToolCall})
+		return nil
+	}
+
 	if !mf.Citation.IsZero() {
 		// For now always add a new block.
 		m.Replies = append(m.Replies, Reply{Citation: mf.Citation})
@@ -621,8 +732,9 @@ func (m *Message) Accumulate(mf *Reply) error {
 	}
 	if len(mf.Opaque) != 0 {
 		if len(m.Replies) != 0 {
-			// Only add Opaque to Reasoning block.
-			if lastBlock := &m.Replies[len(m.Replies)-1]; lastBlock.Reasoning != "" {
+			// Only add Opaque to Reasoning block. Start a new block when a key would be overwritten, e.g. for
+			// multiple consecutive redacted thinking blocks.
+			if lastBlock := &m.Replies[len(m.Replies)-1]; lastBlock.Reasoning != "" && !sharesKey(lastBlock.Opaque, mf.Opaque) {
 				if lastBlock.Opaque == nil {
 					lastBlock.Opaque = map[string]any{}
 				}
@@ -705,6 +817,10 @@ type Reply struct {
 	// ToolCall is a tool call that the LLM requested to make.
 	ToolCall ToolCall `json:"tool_call,omitzero"`
 
+	// ServerToolCall is a tool call executed by the provider, e.g. a web search. It is informative and
+	// permits rendering progress.
+	ServerToolCall ServerToolCall `json:"server_tool_call,omitzero"`
+
 	// Opaque is added to keep continuity on the processing. A good example is Anthropic's extended thinking, or
 	// server-side tool calling. It must be kept during an exchange.
 	//
@@ -714,6 +830,12 @@ type Reply struct {
 	// The data must be JSON-serializable.
 	Opaque map[string]any `json:"opaque,omitzero"`
 
+	// Index is the position of the output item this fragment belongs to, as reported by the provider while
+	// streaming. Fragments with the same Index are part of the same item, which permits reconstructing
+	// interleaved outputs without relying on arrival order. It is only set by providers that report it and it
+	// is not kept by Message.Accumulate.
+	Index int64 `json:"index,omitzero"`
+
 	_ struct{}
 }
 
@@ -721,7 +843,7 @@ type Reply struct {
 //
 // An empty reply is not valid.
 func (r *Reply) IsZero() bool {
-	return r.Text == "" && r.Doc.IsZero() && r.Citation.IsZero() && r.Reasoning == "" && len(r.Opaque) == 0 && r.ToolCall.IsZero()
+	return r.Text == "" && r.Doc.IsZero() && r.Citation.IsZero() && r.Reasoning == "" && len(r.Opaque) == 0 && r.ToolCall.IsZero() && r.ServerToolCall.IsZero()
 }
 
 // GoString returns a JSON representation of the reply for debugging purposes.
@@ -732,6 +854,15 @@ func (r *Reply) GoString() string {
 
 // Validate ensures the block is valid.
 func (r *Reply) Validate() error {
+	if r.Index < 0 {
+		return fmt.Errorf("field Index must be non-negative, got %d", r.Index)
+	}
+	if !r.ServerToolCall.IsZero() {
+		if r.Text != "" || !r.Doc.IsZero() || !r.Citation.IsZero() || r.Reasoning != "" || !r.ToolCall.IsZero() {
+			return errors.New("field ServerToolCall can't be used along other fields")
+		}
+		return r.ServerToolCall.Validate()
+	}
 	switch {
 	case r.Text != "":
 		if !r.Doc.IsZero() {
@@ -823,13 +954,40 @@ type Doc struct {
 	Src io.ReadSeeker `json:"bytes,omitzero"`
 	// URL is the reference to the raw data. When set, the mime-type is derived from the URL.
 	URL string `json:"url,omitzero"`
+	// Detail is the fidelity at which an input image is processed. Lower detail uses fewer tokens. It is
+	// ignored for non-image documents and by providers that do not support it.
+	Detail ImageDetail `json:"detail,omitzero"`
 
 	_ struct{}
 }
 
+// ImageDetail is the fidelity at which an input image is processed by the model.
+//
+// It is mapped to OpenAI's image "detail" and Gemini's media resolution.
+type ImageDetail string
+
+const (
+	// ImageDetailAuto lets the provider decide.
+	ImageDetailAuto ImageDetail = ""
+	// ImageDetailLow processes a downscaled image, reducing cost and latency.
+	ImageDetailLow ImageDetail = "low"
+	// ImageDetailHigh processes the image at high resolution.
+	ImageDetailHigh ImageDetail = "high"
+)
+
+// Validate ensures the value is valid.
+func (i ImageDetail) Validate() error {
+	switch i {
+	case ImageDetailAuto, ImageDetailLow, ImageDetailHigh:
+		return nil
+	default:
+		return fmt.Errorf("invalid image detail %q", i)
+	}
+}
+
 // IsZero returns true if the document is empty.
 func (d *Doc) IsZero() bool {
-	return d.Filename == "" && d.Src == nil && d.URL == ""
+	return d.Filename == "" && d.Src == nil && d.URL == "" && d.Detail == ""
 }
 
 // Validate ensures the block is valid.
@@ -837,6 +995,12 @@ func (d *Doc) Validate() error {
 	if d.Src != nil && d.URL != "" {
 		return errors.New("field Src and URL are mutually exclusive")
 	}
+	if err := d.Detail.Validate(); err != nil {
+		return fmt.Errorf("field Detail: %w", err)
+	}
+	if d.Detail != "" && d.Src == nil && d.URL == "" {
+		return errors.New("field Src or URL is required when using Detail")
+	}
 	if d.Filename != "" {
 		if filepath.Base(d.Filename) != d.Filename {
 			return errors.New("field Filename must be a valid filename with no path")
@@ -869,14 +1033,15 @@ func (d *Doc) GetFilename() string {
 }
 
 type serializedDoc struct {
-	Filename string `json:"filename,omitzero"`
-	Bytes    []byte `json:"bytes,omitzero"`
-	URL      string `json:"url,omitzero"`
+	Filename string      `json:"filename,omitzero"`
+	Bytes    []byte      `json:"bytes,omitzero"`
+	URL      string      `json:"url,omitzero"`
+	Detail   ImageDetail `json:"detail,omitzero"`
 }
 
 // MarshalJSON implements the json.Marshaler interface.
 func (d *Doc) MarshalJSON() ([]byte, error) {
-	dd := serializedDoc{Filename: d.GetFilename(), URL: d.URL}
+	dd := serializedDoc{Filename: d.GetFilename(), URL: d.URL, Detail: d.Detail}
 	if d.Src != nil {
 		// Try to seek to the beginning; if that fails (e.g., os.Stdin), buffer the whole input.
 		if _, err := d.Src.Seek(0, io.SeekStart); err != nil {
@@ -909,6 +1074,7 @@ func (d *Doc) UnmarshalJSON(b []byte) error {
 	}
 	d.Filename = dd.Filename
 	d.URL = dd.URL
+	d.Detail = dd.Detail
 	if len(dd.Bytes) != 0 {
 		d.Src = &bb.BytesBuffer{D: dd.Bytes}
 	}
@@ -999,7 +1165,8 @@ func (t *ToolCall) Validate() error {
 
 // Call invokes the ToolDef.Callback with arguments from the ToolCall, returning the result string.
 //
-// It decodes the ToolCall.Arguments and passes it to the ToolDef.Callback.
+// It decodes the ToolCall.Arguments and passes it to the ToolDef.Callback. It enforces ToolDef.Timeout and
+// ToolDef.MaxResultBytes, and a panic in the callback is returned as an error.
 func (t *ToolCall) Call(ctx context.Context, tools []ToolDef) (string, error) {
 	i := 0
 	for ; i < len(tools); i++ {
@@ -1019,8 +1186,80 @@ func (t *ToolCall) Call(ctx context.Context, tools []ToolDef) (string, error) {
 	if err := d.Decode(input.Interface()); err != nil {
 		return "", fmt.Errorf("failed to decode tool call arguments: %w; arguments: %q", err, t.Arguments)
 	}
-	res := reflect.ValueOf(tools[i].Callback).Call([]reflect.Value{reflect.ValueOf(ctx), input})
-	s := res[0].String()
+	td := &tools[i]
+	var s string
+	var err error
+	if td.Timeout <= 0 {
+		s, err = t.callback(ctx, td.Callback, input)
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, td.Timeout)
+		defer cancel()
+		type result struct {
+			s   string
+			err error
+		}
+		ch := make(chan result, 1)
+		go func() {
+			s, err := t.callback(ctx, td.Callback, input)
+			ch <- result{s, err}
+		}()
+		select {
+		case r := <-ch:
+			s, err = r.s, r.err
+		case <-ctx.Done():
+			// The callback keeps running in the background until it returns.
+			return "", fmt.Errorf("tool %q timed out after %s: %w", t.Name, td.Timeout, ctx.Err())
+		}
+	}
+	if td.MaxResultBytes > 0 && len(s) > td.MaxResultBytes {
+		n := td.MaxResultBytes
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n] + fmt.Sprintf(truncatedMarker, len(s)-n)
+	}
+	return s, err
+}
+
+// truncatedMarker is appended to a result truncated by ToolDef.MaxResultBytes.
+const truncatedMarker = "\n[truncated %d bytes]"
+
+// do calls the tool and returns its result. A call to a tool not in tools or a panic is returned as an error
+// message for the LLM.
+func (t *ToolCall) do(ctx context.Context, tools []ToolDef) (ToolCallResult, error) {
+	out := ToolCallResult{ID: t.ID, Name: t.Name}
+	if !slices.ContainsFunc(tools, func(d ToolDef) bool { return d.Name == t.Name }) {
+		out.Result = fmt.Sprintf("error: tool %q is not available", t.Name)
+		return out, nil
+	}
+	var err error
+	out.Result, err = t.Call(ctx, tools)
+	if _, ok := errors.AsType[*toolPanicError](err); ok {
+		out.Result = "error: " + err.Error()
+		return out, nil
+	}
+	return out, err
+}
+
+// toolPanicError is returned by ToolCall.Call when the callback panicked.
+type toolPanicError struct {
+	name string
+	v    any
+}
+
+func (e *toolPanicError) Error() string {
+	return fmt.Sprintf("tool %q panicked: %v", e.name, e.v)
+}
+
+// callback invokes cb, converting a panic into an error.
+func (t *ToolCall) callback(ctx context.Context, cb any, input reflect.Value) (s string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = &toolPanicError{name: t.Name, v: p}
+		}
+	}()
+	res := reflect.ValueOf(cb).Call([]reflect.Value{reflect.ValueOf(ctx), input})
+	s = res[0].String()
 	if e := res[1].Interface(); e != nil {
 		return s, e.(error)
 	}
@@ -1044,6 +1283,9 @@ type ToolCallResult struct {
 	ID     string `json:"id,omitzero"`
 	Name   string `json:"name,omitzero"`
 	Result string `json:"result,omitzero"`
+	// Doc is a document returned by the tool, e.g. the screenshot taken after a ComputerAction. Result is
+	// optional when Doc is set.
+	Doc Doc `json:"doc,omitzero"`
 
 	_ struct{}
 }
@@ -1054,9 +1296,14 @@ func (t *ToolCallResult) Validate() error {
 	if t.ID == "" && t.Name == "" {
 		return errors.New("at least one of field ID or Name is required")
 	}
-	if t.Result == "" {
+	if t.Result == "" && t.Doc.IsZero() {
 		return errors.New("field Result: required")
 	}
+	if !t.Doc.IsZero() {
+		if err := t.Doc.Validate(); err != nil {
+			return fmt.Errorf("field Doc: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -1072,6 +1319,181 @@ func (t *ToolCallResult) UnmarshalJSON(b []byte) error {
 	return t.Validate()
 }
 
+// ComputerToolName is the ToolCall.Name of the actions requested on the computer described by
+// GenOptionTools.Computer. The ToolCall.Arguments is a JSON encoded ComputerAction.
+const ComputerToolName = "computer"
+
+// Computer describes the computer the LLM can control, e.g. a virtual machine or a browser.
+//
+// Each action is returned as a ToolCall named ComputerToolName. Execute the action, then reply with a
+// ToolCallResult with a screenshot of the screen as Doc. The tool call loops in package adapters do not
+// execute computer actions.
+type Computer struct {
+	// Width and Height are the dimensions of the screen in pixels. Screenshots should have this size.
+	Width  int64
+	Height int64
+	// Environment is the kind of computer: "browser", "mac", "windows", "ubuntu" or "linux". It is only used
+	// by OpenAI. Defaults to "browser".
+	Environment string
+}
+
+// Validate ensures the computer description is valid.
+func (c *Computer) Validate() error {
+	if c.Width <= 0 {
+		return errors.New("field Width: must be positive")
+	}
+	if c.Height <= 0 {
+		return errors.New("field Height: must be positive")
+	}
+	switch c.Environment {
+	case "", "browser", "mac", "windows", "ubuntu", "linux":
+		return nil
+	default:
+		return fmt.Errorf("field Environment: invalid value %q", c.Environment)
+	}
+}
+
+// ComputerActionType is the kind of action requested on the computer.
+type ComputerActionType string
+
+// Actions that can be requested on the computer.
+const (
+	// ComputerScreenshot requests a screenshot and nothing else.
+	ComputerScreenshot ComputerActionType = "screenshot"
+	// ComputerClick clicks Button at X, Y.
+	ComputerClick ComputerActionType = "click"
+	// ComputerDoubleClick double clicks at X, Y.
+	ComputerDoubleClick ComputerActionType = "double_click"
+	// ComputerMove moves the pointer to X, Y.
+	ComputerMove ComputerActionType = "move"
+	// ComputerDrag drags the pointer along Path.
+	ComputerDrag ComputerActionType = "drag"
+	// ComputerScroll scrolls by ScrollX, ScrollY with the pointer at X, Y.
+	ComputerScroll ComputerActionType = "scroll"
+	// ComputerType types Text.
+	ComputerType ComputerActionType = "type"
+	// ComputerKeypress presses the combination of Keys.
+	ComputerKeypress ComputerActionType = "keypress"
+	// ComputerWait waits a bit, e.g. for a page to load.
+	ComputerWait ComputerActionType = "wait"
+)
+
+// ComputerAction is an action requested by the LLM on the computer described by GenOptionTools.Computer.
+//
+// Decode it from the ToolCall.Arguments of a ToolCall named ComputerToolName with json.Unmarshal.
+type ComputerAction struct {
+	Type ComputerActionType `json:"type"`
+	// X and Y are the pointer coordinates in pixels.
+	X int64 `json:"x,omitzero"`
+	Y int64 `json:"y,omitzero"`
+	// Button is "left", "right" or "middle".
+	Button string `json:"button,omitzero"`
+	// Path is the list of points to drag along, starting at the current pointer position.
+	Path []ComputerPoint `json:"path,omitzero"`
+	// ScrollX and ScrollY are the scroll amounts, positive to scroll right and down. The unit is pixels for
+	// OpenAI and wheel clicks for Anthropic.
+	ScrollX int64 `json:"scroll_x,omitzero"`
+	ScrollY int64 `json:"scroll_y,omitzero"`
+	// Text is the text to type.
+	Text string `json:"text,omitzero"`
+	// Keys are the keys to press together, e.g. ["ctrl", "s"]. Key names are provider specific.
+	Keys []string `json:"keys,omitzero"`
+
+	_ struct{}
+}
+
+// ComputerPoint is a point on the screen, in pixels.
+type ComputerPoint struct {
+	X int64 `json:"x"`
+	Y int64 `json:"y"`
+}
+
+// Validate ensures the action is valid.
+func (a *ComputerAction) Validate() error {
+	switch a.Type {
+	case ComputerScreenshot, ComputerClick, ComputerDoubleClick, ComputerMove, ComputerScroll, ComputerWait:
+	case ComputerDrag:
+		if len(a.Path) < 2 {
+			return errors.New("field Path: requires at least 2 points")
+		}
+	case ComputerType:
+		if a.Text == "" {
+			return errors.New("field Text: required")
+		}
+	case ComputerKeypress:
+		if len(a.Keys) == 0 {
+			return errors.New("field Keys: required")
+		}
+	default:
+		return fmt.Errorf("field Type: invalid value %q", a.Type)
+	}
+	return nil
+}
+
+// Names of the server-side tools reported in ServerToolCall.Name.
+const (
+	ServerToolWebSearch     = "web_search"
+	ServerToolWebFetch      = "web_fetch"
+	ServerToolCodeExecution = "code_execution"
+	ServerToolFileSearch    = "file_search"
+	ServerToolMCP           = "mcp"
+)
+
+// ServerToolCallStatus is the progress of a ServerToolCall.
+type ServerToolCallStatus string
+
+// Status of a server-side tool call.
+const (
+	ServerToolCallInProgress ServerToolCallStatus = "in_progress"
+	ServerToolCallCompleted  ServerToolCallStatus = "completed"
+	ServerToolCallFailed     ServerToolCallStatus = "failed"
+)
+
+// ServerToolCall is a tool call executed by the provider itself, e.g. a web search or code execution.
+//
+// It is informative so clients can render progress, e.g. "searching the web…". The results, if any, are
+// still returned as separate Citation or Text replies. It is never sent back to the provider.
+//
+// While streaming, a call is generally first reported as ServerToolCallInProgress then updated with the same
+// ID once done. Message.Accumulate merges both.
+type ServerToolCall struct {
+	// ID is the provider's identifier for the call. It may be empty.
+	ID string `json:"id,omitzero"`
+	// Name is the kind of tool, one of the ServerTool* constants.
+	Name string `json:"name,omitzero"`
+	// Status is the progress of the call.
+	Status ServerToolCallStatus `json:"status,omitzero"`
+	// Input is the JSON encoded input of the call, e.g. the search queries or the code to execute. Its format
+	// is provider specific.
+	Input string `json:"input,omitzero"`
+	// Output is the textual output of the call when it is not returned as a separate reply, e.g. the logs of
+	// the code execution.
+	Output string `json:"output,omitzero"`
+
+	_ struct{}
+}
+
+// IsZero returns true if the server tool call is empty.
+func (s *ServerToolCall) IsZero() bool {
+	return s.ID == "" && s.Name == "" && s.Status == "" && s.Input == "" && s.Output == ""
+}
+
+// Validate ensures the server tool call is valid.
+func (s *ServerToolCall) Validate() error {
+	if s.Name == "" {
+		return errors.New("field Name: required")
+	}
+	switch s.Status {
+	case ServerToolCallInProgress, ServerToolCallCompleted, ServerToolCallFailed:
+	default:
+		return fmt.Errorf("field Status: invalid value %q", s.Status)
+	}
+	if s.Input != "" && !json.Valid([]byte(s.Input)) {
+		return fmt.Errorf("field Input: invalid JSON %q", s.Input)
+	}
+	return nil
+}
+
 // Citation represents a reference to source material that supports content.
 // It provides a unified interface for different provider citation formats.
 //
@@ -1153,6 +1575,15 @@ type CitationSource struct {
 	// StartBlockIndex is the starting block index of the citation in the sourced document (0-based).
 	StartBlockIndex int64 `json:"start_block_index,omitzero"`
 	EndBlockIndex   int64 `json:"end_block_index,omitzero"`
+	// DocumentIndex is the position of the cited document among the documents in the request, in order of
+	// appearance (1-based). It is 0 when unknown.
+	DocumentIndex int64 `json:"document_index,omitzero"`
+	// DocumentName is the name of the cited document, e.g. its filename or title. It is only set for
+	// CitationDocument.
+	DocumentName string `json:"document_name,omitzero"`
+	// Confidence is the confidence that the source supports the citation, between 0 and 1. It is 0 when the
+	// provider doesn't return one.
+	Confidence float64 `json:"confidence,omitzero"`
 
 	// Date is the date of the source, if applicable.
 	Date string `json:"date,omitzero"`
@@ -1170,18 +1601,78 @@ func (cs *CitationSource) Validate() error {
 	if cs.ID == "" && cs.URL == "" && cs.Type != CitationWebQuery {
 		return errors.New("citation source must have either ID or URL")
 	}
+	if cs.DocumentIndex < 0 {
+		return fmt.Errorf("document index must be non-negative, got %d", cs.DocumentIndex)
+	}
+	if cs.Confidence < 0 || cs.Confidence > 1 {
+		return fmt.Errorf("confidence must be between 0 and 1, got %g", cs.Confidence)
+	}
 	return nil
 }
 
 // IsZero returns true if the citation source is empty.
 func (cs *CitationSource) IsZero() bool {
 	return cs.Type == 0 && cs.ID == "" && cs.Title == "" && cs.URL == "" &&
-		cs.Snippet == "" && cs.Date == "" && len(cs.Metadata) == 0
+		cs.Snippet == "" && cs.Date == "" && cs.DocumentIndex == 0 && cs.DocumentName == "" && cs.Confidence == 0 &&
+		len(cs.Metadata) == 0
 }
 
 // Job is a pending job.
 type Job string
 
+// ProviderGenAsyncBatch is optionally implemented by providers that can submit multiple requests as a single
+// batch job, e.g. Anthropic's Message Batches API.
+type ProviderGenAsyncBatch interface {
+	// GenAsyncBatch submits all the requests as a single job.
+	GenAsyncBatch(ctx context.Context, msgs []Messages, opts ...GenOption) (Job, error)
+	// PokeBatchResults retrieves the results of a job created by GenAsyncBatch, in the same order as the
+	// requests.
+	//
+	// It returns nil results and no error while the job is pending. Requests that failed have a zero Result
+	// and their errors are joined in the returned error.
+	PokeBatchResults(ctx context.Context, job Job) ([]Result, error)
+}
+
+// ProviderVectorStore is optionally implemented by providers hosting vector stores, enabling retrieval
+// augmented generation without managing embeddings locally.
+//
+// Search the stores in GenSync and GenStream with GenOptionFileSearch.
+type ProviderVectorStore interface {
+	// VectorStoreAdd creates an empty vector store and returns its ID.
+	VectorStoreAdd(ctx context.Context, displayName string) (string, error)
+	// VectorStoreAddDoc uploads a document to a vector store. It returns once the document is indexed and
+	// searchable.
+	VectorStoreAddDoc(ctx context.Context, id string, doc *Doc) error
+	// VectorStoreEntries lists the vector stores.
+	VectorStoreEntries(ctx context.Context) ([]VectorStoreEntry, error)
+	// VectorStoreDelete deletes a vector store.
+	VectorStoreDelete(ctx context.Context, id string) error
+}
+
+// VectorStoreEntry is one vector store hosted on the provider.
+type VectorStoreEntry interface {
+	GetID() string
+	GetDisplayName() string
+}
+
+// ProviderRerank is optionally implemented by providers that can sort documents by relevance to a query. It
+// is commonly used to refine the candidates returned by a vector search before passing them to the LLM.
+type ProviderRerank interface {
+	// Rerank returns the documents sorted by relevance to the query, the most relevant first.
+	//
+	// topN limits the number of results. 0 means all the documents.
+	Rerank(ctx context.Context, query string, documents []string, topN int) ([]RerankResult, error)
+}
+
+// RerankResult is a document ranked by ProviderRerank.
+type RerankResult struct {
+	// Index is the position of the document in the documents passed to Rerank.
+	Index int
+	// Score is the relevance of the document to the query, higher is more relevant. Its scale depends on the
+	// provider and the model, e.g. Cohere returns a value between 0 and 1.
+	Score float64
+}
+
 // CacheEntry is one file (or GenSync request) cached on the provider for reuse.
 type CacheEntry interface {
 	GetID() string
@@ -1201,6 +1692,94 @@ type Model interface {
 	Context() int64
 }
 
+// ModelOutputTokens is optionally implemented by a Model that reports the maximum number of tokens it can
+// generate in a single reply.
+type ModelOutputTokens interface {
+	// MaxOutputTokens returns the maximum number of output tokens, or 0 if unknown.
+	MaxOutputTokens() int64
+}
+
+// ModelCapabilities describes what the model selected by a Provider supports.
+//
+// Use GetModelCapabilities to retrieve it.
+type ModelCapabilities struct {
+	// Context is the number of tokens the model can process as input. 0 if unknown.
+	Context int64
+	// MaxOutputTokens is the maximum number of tokens the model can generate in a single reply. 0 if unknown.
+	MaxOutputTokens int64
+	// Reason is true if the model is a reasoning model.
+	Reason bool
+	// In are the supported input modalities, e.g. ModalityImage for vision.
+	In Modalities
+	// Out are the supported output modalities.
+	Out Modalities
+
+	// The following are the features confirmed by the scoreboard with GenSync. Flaky features are false.
+
+	Tools            bool
+	JSON             bool
+	JSONSchema       bool
+	WebSearch        bool
+	WebFetch         bool
+	Citations        bool
+	Seed             bool
+	TopLogprobs      bool
+	ReportRateLimits bool
+
+	_ struct{}
+}
+
+// GetModelCapabilities returns the capabilities of the model currently selected by p.
+//
+// The features come from the scenario of p.Scoreboard() listing the model, and Context and MaxOutputTokens
+// come from ListModels via GetModelInfo. The returned capabilities are filled as much as possible even when an
+// error is returned. When the model isn't in the scoreboard, only Context, MaxOutputTokens and the modalities
+// known by GetModelInfo are set.
+func GetModelCapabilities(ctx context.Context, p Provider) (ModelCapabilities, error) {
+	out := ModelCapabilities{}
+	id := p.ModelID()
+	sb := p.Scoreboard()
+	for i := range sb.Scenarios {
+		sc := &sb.Scenarios[i]
+		if !slices.Contains(sc.Models, id) || sc.Untested() {
+			continue
+		}
+		out.Reason = sc.Reason
+		out.In = slices.Sorted(maps.Keys(sc.In))
+		out.Out = slices.Sorted(maps.Keys(sc.Out))
+		if f := sc.GenSync; f != nil {
+			out.Tools = f.Tools == scoreboard.True
+			out.JSON = f.JSON
+			out.JSONSchema = f.JSONSchema
+			out.WebSearch = f.WebSearch
+			out.WebFetch = f.WebFetch
+			out.Citations = f.Citations
+			out.Seed = f.Seed
+			out.TopLogprobs = f.TopLogprobs
+			out.ReportRateLimits = f.ReportRateLimits
+		}
+		break
+	}
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return out, err
+	}
+	for _, m := range models {
+		if m.GetID() != id {
+			continue
+		}
+		info := GetModelInfo(m)
+		out.Context = info.InputContext
+		out.MaxOutputTokens = info.OutputContext
+		if len(out.In) == 0 && len(out.Out) == 0 {
+			out.In = info.In
+			out.Out = info.Out
+		}
+		break
+	}
+	return out, nil
+}
+
 // Ping
 
 // ProviderPing represents a provider that you can ping.
@@ -1211,6 +1790,27 @@ type ProviderPing interface {
 	Ping(ctx context.Context) error
 }
 
+// ModelSelection describes how a provider automatically selected its model.
+type ModelSelection struct {
+	// Preference is the requested selection: ModelCheap, ModelGood or ModelSOTA.
+	Preference ProviderOptionModel
+	// Model is the selected model ID.
+	Model string
+	// Reason explains why the model was selected, e.g. it was pinned with ProviderOptionModelPin.
+	Reason string
+	// Pinned is true when the model was read from the file specified with ProviderOptionModelPin.
+	Pinned bool
+
+	_ struct{}
+}
+
+// ProviderModelSelection is optionally implemented by providers that report how the model was selected when
+// ProviderOptionModel is ModelCheap, ModelGood or ModelSOTA.
+type ProviderModelSelection interface {
+	// ModelSelection returns the decision. It is the zero value when the model was specified explicitly.
+	ModelSelection() ModelSelection
+}
+
 // ScoreboardVariant is a named scoreboard for a specific backend or region of a provider.
 type ScoreboardVariant struct {
 	// Name is the display name for this variant, e.g. "Intl", "US".
@@ -1227,6 +1827,16 @@ type ProviderScoreboardVariants interface {
 	ScoreboardVariants() []ScoreboardVariant
 }
 
+// sharesKey returns true if a and b have at least one key in common.
+func sharesKey(a, b map[string]any) bool {
+	for k := range b {
+		if _, ok := a[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 var (
 	_ internal.Validatable = (*Citation)(nil)
 	_ internal.Validatable = (*CitationSource)(nil)
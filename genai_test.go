@@ -16,12 +16,14 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/maruel/genai/internal/bb"
+	"github.com/maruel/genai/scoreboard"
 )
 
 func TestUsage(t *testing.T) {
@@ -52,6 +54,20 @@ func TestUsage(t *testing.T) {
 			t.Fatalf("Usage.String()\nwant %q\ngot  %q", want, got)
 		}
 	})
+	t.Run("String_server_tools", func(t *testing.T) {
+		u := Usage{InputTokens: 10, OutputTokens: 20, TotalTokens: 30, WebSearchRequests: 2, WebFetchRequests: 1}
+		want := "in: 10 (cached 0), reasoning: 0, out: 20, total: 30, web searches: 2, web fetches: 1"
+		if got := u.String(); got != want {
+			t.Fatalf("Usage.String()\nwant %q\ngot  %q", want, got)
+		}
+	})
+	t.Run("String_cost", func(t *testing.T) {
+		u := Usage{InputTokens: 10, OutputTokens: 20, TotalTokens: 30, InferenceProvider: "DeepInfra", Cost: 0.00042}
+		want := "in: 10 (cached 0), reasoning: 0, out: 20, total: 30, served by: DeepInfra, cost: $0.00042"
+		if got := u.String(); got != want {
+			t.Fatalf("Usage.String()\nwant %q\ngot  %q", want, got)
+		}
+	})
 	t.Run("Add", func(t *testing.T) {
 		u1 := Usage{
 			InputTokens:       10,
@@ -59,6 +75,7 @@ func TestUsage(t *testing.T) {
 			ReasoningTokens:   15,
 			OutputTokens:      20,
 			TotalTokens:       50,
+			WebSearchRequests: 1,
 		}
 		u2 := Usage{
 			InputTokens:       20,
@@ -66,6 +83,9 @@ func TestUsage(t *testing.T) {
 			ReasoningTokens:   30,
 			OutputTokens:      40,
 			TotalTokens:       100,
+			Cost:              0.5,
+			WebSearchRequests: 2,
+			WebFetchRequests:  3,
 		}
 		expected := Usage{
 			InputTokens:       30,
@@ -73,6 +93,9 @@ func TestUsage(t *testing.T) {
 			ReasoningTokens:   45,
 			OutputTokens:      60,
 			TotalTokens:       150,
+			Cost:              0.5,
+			WebSearchRequests: 3,
+			WebFetchRequests:  3,
 		}
 		u1.Add(&u2)
 		if diff := cmp.Diff(expected, u1); diff != "" {
@@ -288,6 +311,30 @@ func TestMessage(t *testing.T) {
 				t.Fatalf("unexpected error: %q", err)
 			}
 		})
+		t.Run("wrapped", func(t *testing.T) {
+			tests := []struct {
+				name string
+				text string
+				got  any
+				want any
+			}{
+				{"slice", `{"value":["a","b"]}`, &[]string{}, &[]string{"a", "b"}},
+				{"slice_unwrapped", `["a","b"]`, &[]string{}, &[]string{"a", "b"}},
+				{"int", `{"value":42}`, new(int), func() *int { i := 42; return &i }()},
+				{"map", `{"value":{"a":1}}`, &map[string]int{}, &map[string]int{"a": 1}},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					m := Message{Replies: []Reply{{Text: tt.text}}}
+					if err := m.Decode(tt.got); err != nil {
+						t.Fatal(err)
+					}
+					if diff := cmp.Diff(tt.want, tt.got); diff != "" {
+						t.Fatalf("(-want +got):\n%s", diff)
+					}
+				})
+			}
+		})
 		t.Run("error", func(t *testing.T) {
 			tests := []struct {
 				name   string
@@ -437,6 +484,21 @@ func TestMessage(t *testing.T) {
 				fragment: Reply{Reasoning: "therefore I am"},
 				want:     Message{Replies: []Reply{{Reasoning: "I think therefore I am"}}},
 			},
+			{
+				name:     "Add signature to existing reasoning",
+				message:  Message{Replies: []Reply{{Reasoning: "I think"}}},
+				fragment: Reply{Opaque: map[string]any{"signature": "sig"}},
+				want:     Message{Replies: []Reply{{Reasoning: "I think", Opaque: map[string]any{"signature": "sig"}}}},
+			},
+			{
+				name:     "Opaque already set on reasoning",
+				message:  Message{Replies: []Reply{{Reasoning: "I think", Opaque: map[string]any{"redacted_thinking": "a"}}}},
+				fragment: Reply{Opaque: map[string]any{"redacted_thinking": "b"}},
+				want: Message{Replies: []Reply{
+					{Reasoning: "I think", Opaque: map[string]any{"redacted_thinking": "a"}},
+					{Opaque: map[string]any{"redacted_thinking": "b"}},
+				}},
+			},
 			{
 				name:     "Join assistant text",
 				message:  Message{Replies: []Reply{{Text: "Hello"}}},
@@ -480,6 +542,24 @@ func TestMessage(t *testing.T) {
 					},
 				},
 			},
+			{
+				name:     "Complete server tool call",
+				message:  Message{Replies: []Reply{{ServerToolCall: ServerToolCall{ID: "1", Name: ServerToolWebSearch, Status: ServerToolCallInProgress, Input: `{"query":"q"}`}}, {Text: "Hi"}}},
+				fragment: Reply{ServerToolCall: ServerToolCall{ID: "1", Name: ServerToolWebSearch, Status: ServerToolCallCompleted}},
+				want: Message{Replies: []Reply{
+					{ServerToolCall: ServerToolCall{ID: "1", Name: ServerToolWebSearch, Status: ServerToolCallCompleted, Input: `{"query":"q"}`}},
+					{Text: "Hi"},
+				}},
+			},
+			{
+				name:     "New server tool call without ID",
+				message:  Message{Replies: []Reply{{ServerToolCall: ServerToolCall{Name: ServerToolCodeExecution, Status: ServerToolCallInProgress}}}},
+				fragment: Reply{ServerToolCall: ServerToolCall{Name: ServerToolCodeExecution, Status: ServerToolCallInProgress}},
+				want: Message{Replies: []Reply{
+					{ServerToolCall: ServerToolCall{Name: ServerToolCodeExecution, Status: ServerToolCallInProgress}},
+					{ServerToolCall: ServerToolCall{Name: ServerToolCodeExecution, Status: ServerToolCallInProgress}},
+				}},
+			},
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
@@ -548,16 +628,49 @@ func TestMessage(t *testing.T) {
 			msg := Message{
 				Replies: []Reply{
 					{ToolCall: ToolCall{ID: "call1", Name: "nonexistent", Arguments: `{"a": 5, "b": 3}`}},
+					{ToolCall: ToolCall{ID: "call2", Name: "calculator", Arguments: `{"a": 5, "b": 3}`}},
 				},
 			}
 
-			_, err := msg.DoToolCalls(ctx, []ToolDef{tool})
-			if err == nil {
-				t.Fatal("expected error, got nil")
+			result, err := msg.DoToolCalls(ctx, []ToolDef{tool})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := Message{
+				ToolCallResults: []ToolCallResult{
+					{ID: "call1", Name: "nonexistent", Result: `error: tool "nonexistent" is not available`},
+					{ID: "call2", Name: "calculator", Result: "8"},
+				},
+			}
+			if diff := cmp.Diff(expected, result); diff != "" {
+				t.Fatalf("DoToolCalls() mismatch (-want +got):\n%s", diff)
 			}
+		})
 
-			if !strings.Contains(err.Error(), "failed to find tool named \"nonexistent\"") {
-				t.Fatalf("unexpected error message: %v", err)
+		t.Run("panic", func(t *testing.T) {
+			tool := ToolDef{
+				Name:        "calculator",
+				Description: "A calculator tool",
+				Callback: func(ctx context.Context, input *calculateInput) (string, error) {
+					panic("boom")
+				},
+			}
+			msg := Message{
+				Replies: []Reply{
+					{ToolCall: ToolCall{ID: "call1", Name: "calculator", Arguments: `{"a": 5, "b": 3}`}},
+				},
+			}
+			result, err := msg.DoToolCallsConcurrently(t.Context(), []ToolDef{tool}, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := Message{
+				ToolCallResults: []ToolCallResult{
+					{ID: "call1", Name: "calculator", Result: `error: tool "calculator" panicked: boom`},
+				},
+			}
+			if diff := cmp.Diff(expected, result); diff != "" {
+				t.Fatalf("DoToolCallsConcurrently() mismatch (-want +got):\n%s", diff)
 			}
 		})
 
@@ -578,6 +691,85 @@ func TestMessage(t *testing.T) {
 			}
 		})
 	})
+	t.Run("DoToolCallsConcurrently", func(t *testing.T) {
+		type waitInput struct {
+			ID int `json:"id"`
+		}
+		t.Run("valid", func(t *testing.T) {
+			// All the calls must be running at the same time for any of them to return.
+			var wg sync.WaitGroup
+			wg.Add(3)
+			tool := ToolDef{
+				Name:        "wait",
+				Description: "Waits for the other calls",
+				Callback: func(ctx context.Context, input *waitInput) (string, error) {
+					wg.Done()
+					wg.Wait()
+					return strconv.Itoa(input.ID), nil
+				},
+			}
+			msg := Message{
+				Replies: []Reply{
+					{Text: "Calling"},
+					{ToolCall: ToolCall{ID: "call1", Name: "wait", Arguments: `{"id": 1}`}},
+					{ToolCall: ToolCall{ID: "call2", Name: "wait", Arguments: `{"id": 2}`}},
+					{ToolCall: ToolCall{ID: "call3", Name: "wait", Arguments: `{"id": 3}`}},
+				},
+			}
+			result, err := msg.DoToolCallsConcurrently(t.Context(), []ToolDef{tool}, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := Message{
+				ToolCallResults: []ToolCallResult{
+					{ID: "call1", Name: "wait", Result: "1"},
+					{ID: "call2", Name: "wait", Result: "2"},
+					{ID: "call3", Name: "wait", Result: "3"},
+				},
+			}
+			if diff := cmp.Diff(expected, result); diff != "" {
+				t.Fatalf("DoToolCallsConcurrently() mismatch (-want +got):\n%s", diff)
+			}
+		})
+		t.Run("errors", func(t *testing.T) {
+			var mu sync.Mutex
+			running, maxRunning := 0, 0
+			tool := ToolDef{
+				Name:        "fail",
+				Description: "Fails on even IDs",
+				Callback: func(ctx context.Context, input *waitInput) (string, error) {
+					mu.Lock()
+					running++
+					maxRunning = max(maxRunning, running)
+					mu.Unlock()
+					defer func() {
+						mu.Lock()
+						running--
+						mu.Unlock()
+					}()
+					if input.ID%2 == 0 {
+						return "", fmt.Errorf("id %d", input.ID)
+					}
+					return strconv.Itoa(input.ID), nil
+				},
+			}
+			var msg Message
+			for i := range 6 {
+				msg.Replies = append(msg.Replies, Reply{ToolCall: ToolCall{ID: strconv.Itoa(i), Name: "fail", Arguments: fmt.Sprintf(`{"id": %d}`, i)}})
+			}
+			result, err := msg.DoToolCallsConcurrently(t.Context(), []ToolDef{tool}, 2)
+			const want = "tool call \"fail\": id 0\ntool call \"fail\": id 2\ntool call \"fail\": id 4"
+			if err == nil || err.Error() != want {
+				t.Fatalf("got error %v, want %q", err, want)
+			}
+			if len(result.ToolCallResults) != 6 || result.ToolCallResults[5].Result != "5" {
+				t.Fatalf("unexpected results: %+v", result.ToolCallResults)
+			}
+			if maxRunning > 2 {
+				t.Fatalf("ran %d calls concurrently, limit is 2", maxRunning)
+			}
+		})
+	})
 }
 
 func TestRequest(t *testing.T) {
@@ -758,6 +950,10 @@ func TestReply(t *testing.T) {
 						},
 					},
 				},
+				{
+					name: "server tool call",
+					in:   Reply{ServerToolCall: ServerToolCall{ID: "ws_1", Name: ServerToolWebSearch, Status: ServerToolCallInProgress, Input: `{"query":"q"}`}},
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -798,6 +994,16 @@ func TestReply(t *testing.T) {
 					},
 					errMsg: "field Citation can't be used along Text",
 				},
+				{
+					name:   "server tool call with text",
+					in:     Reply{Text: "Hi", ServerToolCall: ServerToolCall{Name: ServerToolWebSearch, Status: ServerToolCallCompleted}},
+					errMsg: "field ServerToolCall can't be used along other fields",
+				},
+				{
+					name:   "server tool call without status",
+					in:     Reply{ServerToolCall: ServerToolCall{Name: ServerToolWebSearch}},
+					errMsg: "field Status: invalid value \"\"",
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -916,6 +1122,11 @@ func TestReply(t *testing.T) {
 					in:     `{}`,
 					errMsg: "an empty Reply is invalid",
 				},
+				{
+					name:   "Negative index",
+					in:     `{"text": "Hello", "index": -1}`,
+					errMsg: "field Index must be non-negative, got -1",
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -1046,6 +1257,58 @@ func TestToolCall(t *testing.T) {
 			}
 		})
 
+		t.Run("with panicking callback", func(t *testing.T) {
+			tool := ToolDef{
+				Name:        "panicTool",
+				Description: "A tool that panics",
+				Callback: func(ctx context.Context, input *CalculateInput) (string, error) {
+					panic("boom")
+				},
+			}
+			tc := ToolCall{ID: "call6", Name: "panicTool", Arguments: `{}`}
+			if _, err := tc.Call(t.Context(), []ToolDef{tool}); err == nil || err.Error() != `tool "panicTool" panicked: boom` {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		t.Run("with timeout", func(t *testing.T) {
+			done := make(chan struct{})
+			tool := ToolDef{
+				Name:        "slowTool",
+				Description: "A tool that ignores its context",
+				Callback: func(ctx context.Context, input *CalculateInput) (string, error) {
+					<-done
+					return "late", nil
+				},
+				Timeout: time.Millisecond,
+			}
+			defer close(done)
+			tc := ToolCall{ID: "call7", Name: "slowTool", Arguments: `{}`}
+			if _, err := tc.Call(t.Context(), []ToolDef{tool}); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		t.Run("with max result bytes", func(t *testing.T) {
+			tool := ToolDef{
+				Name:        "bigTool",
+				Description: "A tool that returns a large result",
+				Callback: func(ctx context.Context, input *CalculateInput) (string, error) {
+					return "aéé", nil
+				},
+				MaxResultBytes: 4,
+			}
+			tc := ToolCall{ID: "call8", Name: "bigTool", Arguments: `{}`}
+			result, err := tc.Call(t.Context(), []ToolDef{tool})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The second rune is cut in half so it is dropped.
+			if want := "aé\n[truncated 2 bytes]"; result != want {
+				t.Fatalf("unexpected result: got %q, want %q", result, want)
+			}
+		})
+
 		t.Run("with invalid arguments", func(t *testing.T) {
 			ctx := t.Context()
 			structTool := ToolDef{
@@ -1347,6 +1610,10 @@ func TestCitationSource(t *testing.T) {
 					name: "valid with both ID and URL",
 					in:   CitationSource{ID: "doc1", URL: "https://example.com", Type: CitationDocument},
 				},
+				{
+					name: "valid with document and confidence",
+					in:   CitationSource{ID: "0", Type: CitationDocument, DocumentIndex: 1, DocumentName: "a.txt", Confidence: 0.8},
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -1367,6 +1634,16 @@ func TestCitationSource(t *testing.T) {
 					in:     CitationSource{Type: CitationDocument},
 					errMsg: "citation source must have either ID or URL",
 				},
+				{
+					name:   "negative document index",
+					in:     CitationSource{ID: "doc1", DocumentIndex: -1},
+					errMsg: "document index must be non-negative, got -1",
+				},
+				{
+					name:   "confidence out of range",
+					in:     CitationSource{ID: "doc1", Confidence: 1.5},
+					errMsg: "confidence must be between 0 and 1, got 1.5",
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -1414,6 +1691,11 @@ func TestCitationSource(t *testing.T) {
 				in:   CitationSource{Metadata: map[string]any{"key": "value"}},
 				want: false,
 			},
+			{
+				name: "with Confidence",
+				in:   CitationSource{Confidence: 0.5},
+				want: false,
+			},
 		}
 
 		for _, tt := range tests {
@@ -1742,6 +2024,13 @@ func TestDoc(t *testing.T) {
 						Src:      &bb.BytesBuffer{D: []byte("content")},
 					},
 				},
+				{
+					name: "with detail",
+					in: Doc{
+						URL:    "https://example.com/image.jpg",
+						Detail: ImageDetailLow,
+					},
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -1777,6 +2066,16 @@ func TestDoc(t *testing.T) {
 					in:     Doc{Src: strings.NewReader("content")},
 					errMsg: "field Filename is required with Src when not implementing Name()",
 				},
+				{
+					name:   "invalid detail",
+					in:     Doc{URL: "https://example.com/image.jpg", Detail: "medium"},
+					errMsg: "field Detail: invalid image detail \"medium\"",
+				},
+				{
+					name:   "detail without src or URL",
+					in:     Doc{Detail: ImageDetailHigh},
+					errMsg: "field Src or URL is required when using Detail",
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -1880,3 +2179,50 @@ func TestDocUnseekable(t *testing.T) {
 		}
 	})
 }
+
+type capsModel struct{}
+
+func (capsModel) GetID() string          { return "m1" }
+func (capsModel) String() string         { return "m1" }
+func (capsModel) Context() int64         { return 1000 }
+func (capsModel) MaxOutputTokens() int64 { return 100 }
+
+type capsProvider struct {
+	Provider
+}
+
+func (capsProvider) ModelID() string { return "m1" }
+
+func (capsProvider) Scoreboard() scoreboard.Score {
+	return scoreboard.Score{Scenarios: []scoreboard.Scenario{
+		{Models: []string{"m0"}, In: map[Modality]scoreboard.ModalCapability{ModalityText: {Inline: true}}},
+		{
+			Models:  []string{"m1"},
+			In:      map[Modality]scoreboard.ModalCapability{ModalityText: {Inline: true}, ModalityImage: {Inline: true}},
+			Out:     map[Modality]scoreboard.ModalCapability{ModalityText: {Inline: true}},
+			GenSync: &scoreboard.Functionality{Tools: scoreboard.Flaky, JSONSchema: true, ReportRateLimits: true},
+		},
+	}}
+}
+
+func (capsProvider) ListModels(ctx context.Context) ([]Model, error) {
+	return []Model{capsModel{}}, nil
+}
+
+func TestGetModelCapabilities(t *testing.T) {
+	got, err := GetModelCapabilities(t.Context(), capsProvider{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ModelCapabilities{
+		Context:          1000,
+		MaxOutputTokens:  100,
+		In:               Modalities{ModalityImage, ModalityText},
+		Out:              Modalities{ModalityText},
+		JSONSchema:       true,
+		ReportRateLimits: true,
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(ModelCapabilities{})); diff != "" {
+		t.Fatalf("(-want +got):\n%s", diff)
+	}
+}
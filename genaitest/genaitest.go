@@ -0,0 +1,350 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package genaitest records and replays provider interactions for hermetic tests.
+//
+// Wrap any genai.Provider with a Recorder. The first run calls the provider and saves each GenSync and
+// GenStream call to a JSON file. Later runs replay the file without network access, so tests of code built on
+// genai are fast, free and deterministic.
+//
+// Recording is at the genai level, not the HTTP level like httprecord: the files are small, readable and
+// independent of the provider's wire format. Requests are matched on their messages and options.
+package genaitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/scoreboard"
+)
+
+// Mode is the recording mode.
+type Mode int
+
+const (
+	// ModeRecordOnce replays the file when it exists and records a new one otherwise.
+	ModeRecordOnce Mode = iota
+	// ModeReplay only replays. The file must exist and the provider is never called.
+	ModeReplay
+	// ModeRecord always calls the provider and overwrites the file.
+	ModeRecord
+)
+
+// ErrNoInteraction is returned on replay when no recorded interaction matches the request.
+type ErrNoInteraction struct {
+	Method  string
+	Request string
+}
+
+func (e *ErrNoInteraction) Error() string {
+	return fmt.Sprintf("genaitest: no recorded %s interaction matches request %s", e.Method, e.Request)
+}
+
+// Recorder is a genai.Provider that records or replays GenSync and GenStream calls.
+//
+// Other methods are forwarded to the wrapped provider.
+type Recorder struct {
+	genai.Provider
+
+	path      string
+	recording bool
+
+	mu   sync.Mutex
+	c    cassette
+	used []bool
+}
+
+// New returns a Recorder saving to or replaying from path.
+//
+// p may be nil with ModeReplay. In that case Name, ModelID and OutputModalities are loaded from the file and
+// the other methods return base.ErrNotSupported.
+//
+// Don't forget to call Stop!
+func New(path string, p genai.Provider, mode Mode) (*Recorder, error) {
+	r := &Recorder{Provider: p, path: path}
+	b, err := os.ReadFile(path)
+	switch {
+	case mode == ModeRecord || (mode == ModeRecordOnce && errors.Is(err, os.ErrNotExist)):
+		if p == nil {
+			return nil, errors.New("genaitest: a provider is required to record")
+		}
+		r.recording = true
+		r.c = cassette{Version: 1, Provider: p.Name(), Model: p.ModelID(), Modalities: p.OutputModalities()}
+		return r, nil
+	case err != nil:
+		return nil, fmt.Errorf("genaitest: %w", err)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&r.c); err != nil {
+		return nil, fmt.Errorf("genaitest: failed to decode %s: %w", path, err)
+	}
+	if r.c.Version != 1 {
+		return nil, fmt.Errorf("genaitest: %s: unsupported version %d", path, r.c.Version)
+	}
+	r.used = make([]bool, len(r.c.Interactions))
+	if p == nil {
+		r.Provider = &replayed{name: r.c.Provider, model: r.c.Model, modalities: r.c.Modalities}
+	}
+	return r, nil
+}
+
+// ForTest returns a Recorder for the test, saved to testdata/<test name>.json relative to the current
+// directory with ModeRecordOnce. It is stopped at the end of the test.
+func ForTest(t testing.TB, p genai.Provider) *Recorder {
+	t.Helper()
+	r, err := New(filepath.Join("testdata", filepath.FromSlash(t.Name())+".json"), p, ModeRecordOnce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	return r
+}
+
+// Recording returns true if the Recorder calls the provider, false if it replays.
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// Stop saves the recorded interactions. It is a no-op when replaying or if nothing was recorded.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.recording || len(r.c.Interactions) == 0 {
+		return nil
+	}
+	b, err := json.MarshalIndent(&r.c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("genaitest: failed to encode: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("genaitest: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("genaitest: %w", err)
+	}
+	return nil
+}
+
+// GenSync implements genai.Provider.
+func (r *Recorder) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	in, err := newInteraction("GenSync", msgs, opts)
+	if err != nil {
+		return genai.Result{}, err
+	}
+	if !r.recording {
+		rec, err := r.find(in)
+		if err != nil {
+			return genai.Result{}, err
+		}
+		return rec.Result.get(), rec.err()
+	}
+	res, err := r.Provider.GenSync(ctx, msgs, opts...)
+	in.set(&res, err)
+	r.add(in)
+	return res, err
+}
+
+// GenStream implements genai.Provider.
+func (r *Recorder) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	in, err := newInteraction("GenStream", msgs, opts)
+	if err != nil {
+		return func(yield func(genai.Reply) bool) {}, func() (genai.Result, error) { return genai.Result{}, err }
+	}
+	if !r.recording {
+		rec, err := r.find(in)
+		if err != nil {
+			return func(yield func(genai.Reply) bool) {}, func() (genai.Result, error) { return genai.Result{}, err }
+		}
+		return func(yield func(genai.Reply) bool) {
+				for _, f := range rec.Fragments {
+					if !yield(f) {
+						return
+					}
+				}
+			}, func() (genai.Result, error) {
+				return rec.Result.get(), rec.err()
+			}
+	}
+	fragments, finish := r.Provider.GenStream(ctx, msgs, opts...)
+	return func(yield func(genai.Reply) bool) {
+			for f := range fragments {
+				in.Fragments = append(in.Fragments, f)
+				if !yield(f) {
+					return
+				}
+			}
+		}, func() (genai.Result, error) {
+			res, err := finish()
+			in.set(&res, err)
+			r.add(in)
+			return res, err
+		}
+}
+
+// Unwrap implements genai.ProviderUnwrap.
+func (r *Recorder) Unwrap() genai.Provider {
+	return r.Provider
+}
+
+//
+
+type cassette struct {
+	Version      int              `json:"version"`
+	Provider     string           `json:"provider"`
+	Model        string           `json:"model,omitzero"`
+	Modalities   genai.Modalities `json:"modalities,omitzero"`
+	Interactions []*interaction   `json:"interactions"`
+}
+
+type interaction struct {
+	Method    string         `json:"method"`
+	Messages  genai.Messages `json:"messages"`
+	Options   []option       `json:"options,omitzero"`
+	Fragments []genai.Reply  `json:"fragments,omitzero"`
+	Result    result         `json:"result"`
+	Error     string         `json:"error,omitzero"`
+
+	key string
+}
+
+// result is genai.Result serialized. genai.Result can't be decoded directly since it embeds
+// genai.Message, whose UnmarshalJSON is promoted.
+type result struct {
+	Message  genai.Message     `json:"message,omitzero"`
+	Usage    genai.Usage       `json:"usage"`
+	Logprobs [][]genai.Logprob `json:"logprobs,omitzero"`
+}
+
+func (r *result) get() genai.Result {
+	return genai.Result{Message: r.Message, Usage: r.Usage, Logprobs: r.Logprobs}
+}
+
+// option is a GenOption serialized for matching. It is not decoded back.
+type option struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+func newInteraction(method string, msgs genai.Messages, opts []genai.GenOption) (*interaction, error) {
+	in := &interaction{Method: method, Messages: msgs}
+	for _, o := range opts {
+		v := any(o)
+		if t, ok := o.(*genai.GenOptionTools); ok {
+			v = toolsForMatching(t)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("genaitest: failed to encode option %T: %w", o, err)
+		}
+		in.Options = append(in.Options, option{Type: fmt.Sprintf("%T", o), Value: b})
+	}
+	if err := in.computeKey(); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (in *interaction) computeKey() error {
+	b, err := json.Marshal(struct {
+		Method   string         `json:"method"`
+		Messages genai.Messages `json:"messages"`
+		Options  []option       `json:"options,omitzero"`
+	}{in.Method, in.Messages, in.Options})
+	if err != nil {
+		return fmt.Errorf("genaitest: failed to encode request: %w", err)
+	}
+	in.key = string(b)
+	return nil
+}
+
+func (in *interaction) set(res *genai.Result, err error) {
+	in.Result = result{Message: res.Message, Usage: res.Usage, Logprobs: res.Logprobs}
+	if err != nil {
+		in.Error = err.Error()
+	}
+}
+
+func (in *interaction) err() error {
+	if in.Error == "" {
+		return nil
+	}
+	return errors.New(in.Error)
+}
+
+func (r *Recorder) add(in *interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.c.Interactions = append(r.c.Interactions, in)
+}
+
+// find returns the first unused recorded interaction matching in.
+func (r *Recorder) find(in *interaction) (*interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, rec := range r.c.Interactions {
+		if r.used[i] {
+			continue
+		}
+		if rec.key == "" {
+			if err := rec.computeKey(); err != nil {
+				return nil, err
+			}
+		}
+		if rec.key == in.key {
+			r.used[i] = true
+			return rec, nil
+		}
+	}
+	req := in.key
+	if len(req) > 200 {
+		req = req[:200] + "..."
+	}
+	return nil, &ErrNoInteraction{Method: in.Method, Request: strings.TrimSpace(req)}
+}
+
+// toolsForMatching returns a copy of t with the callbacks, which are not serializable, replaced by their
+// schema.
+func toolsForMatching(t *genai.GenOptionTools) *genai.GenOptionTools {
+	c := *t
+	c.Tools = make([]genai.ToolDef, len(t.Tools))
+	for i := range t.Tools {
+		c.Tools[i] = t.Tools[i]
+		if c.Tools[i].Callback != nil {
+			c.Tools[i].InputSchemaOverride, _ = t.Tools[i].GetInputSchema()
+			c.Tools[i].Callback = nil
+		}
+	}
+	return &c
+}
+
+// replayed is the provider used when replaying without a provider.
+type replayed struct {
+	base.NotImplemented
+	name       string
+	model      string
+	modalities genai.Modalities
+}
+
+func (r *replayed) Name() string                       { return r.name }
+func (r *replayed) ModelID() string                    { return r.model }
+func (r *replayed) OutputModalities() genai.Modalities { return r.modalities }
+func (r *replayed) HTTPClient() *http.Client           { return nil }
+func (r *replayed) Scoreboard() scoreboard.Score       { return scoreboard.Score{} }
@@ -0,0 +1,168 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the record and replay provider.
+
+package genaitest_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/genaitest"
+	"github.com/maruel/genai/scoreboard"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "rec.json")
+	type args struct {
+		Path string `json:"path"`
+	}
+	tools := &genai.GenOptionTools{Tools: []genai.ToolDef{{
+		Name:        "ls",
+		Description: "List files",
+		Callback:    func(ctx context.Context, a *args) (string, error) { return "", nil },
+	}}}
+	run := func(t *testing.T, p genai.Provider) {
+		res, err := p.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}, tools)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := res.String(); s != "reply to hi" || res.Usage.OutputTokens != 3 {
+			t.Fatalf("unexpected %q %+v", s, res.Usage)
+		}
+		fragments, finish := p.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("stream")})
+		var got []string
+		for f := range fragments {
+			got = append(got, f.Text)
+		}
+		res, err = finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0] != "reply to " || res.String() != "reply to stream" {
+			t.Fatalf("unexpected %q %q", got, res.String())
+		}
+		if _, err := p.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("fail")}); err == nil || err.Error() != "boom" {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	m := &mockProvider{}
+	r, err := genaitest.New(path, m, genaitest.ModeRecordOnce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Recording() {
+		t.Fatal("expected recording")
+	}
+	run(t, r)
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if m.calls != 3 {
+		t.Fatalf("got %d calls, want 3", m.calls)
+	}
+
+	// Replay without a provider.
+	r, err = genaitest.New(path, nil, genaitest.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Recording() || r.Name() != "mock" || r.ModelID() != "llm-sota" {
+		t.Fatalf("unexpected %t %q %q", r.Recording(), r.Name(), r.ModelID())
+	}
+	run(t, r)
+	_, err = r.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}, tools)
+	if _, ok := errors.AsType[*genaitest.ErrNoInteraction](err); !ok {
+		t.Fatalf("expected ErrNoInteraction since the interaction was used, got %v", err)
+	}
+
+	// ModeRecordOnce replays when the file exists.
+	r, err = genaitest.New(path, m, genaitest.ModeRecordOnce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run(t, r)
+	if m.calls != 3 {
+		t.Fatalf("got %d calls, want 3", m.calls)
+	}
+	if r.Unwrap() != m {
+		t.Fatal("unexpected Unwrap")
+	}
+}
+
+func TestNew_errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := genaitest.New(filepath.Join(dir, "missing.json"), nil, genaitest.ModeReplay); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := genaitest.New(filepath.Join(dir, "missing.json"), nil, genaitest.ModeRecord); err == nil {
+		t.Fatal("expected error")
+	}
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"version":2,"provider":"a","interactions":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := genaitest.New(bad, nil, genaitest.ModeReplay); err == nil || err.Error() != "genaitest: "+bad+": unsupported version 2" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestForTest(t *testing.T) {
+	t.Chdir(t.TempDir())
+	m := &mockProvider{}
+	t.Run("sub", func(t *testing.T) {
+		r := genaitest.ForTest(t, m)
+		if _, err := r.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join("testdata", "TestForTest", "sub.json")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type mockProvider struct {
+	base.NotImplemented
+	calls int
+}
+
+func (m *mockProvider) Name() string    { return "mock" }
+func (m *mockProvider) ModelID() string { return "llm-sota" }
+func (m *mockProvider) OutputModalities() genai.Modalities {
+	return genai.Modalities{genai.ModalityText}
+}
+func (m *mockProvider) HTTPClient() *http.Client     { return nil }
+func (m *mockProvider) Scoreboard() scoreboard.Score { return scoreboard.Score{} }
+
+func (m *mockProvider) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	m.calls++
+	in := msgs[len(msgs)-1].String()
+	if in == "fail" {
+		return genai.Result{}, errors.New("boom")
+	}
+	return genai.Result{
+		Message: genai.Message{Replies: []genai.Reply{{Text: "reply to " + in}}},
+		Usage:   genai.Usage{InputTokens: 1, OutputTokens: 3, FinishReason: genai.FinishedStop},
+	}, nil
+}
+
+func (m *mockProvider) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	res, err := m.GenSync(ctx, msgs, opts...)
+	return func(yield func(genai.Reply) bool) {
+			if err == nil && yield(genai.Reply{Text: "reply to "}) {
+				yield(genai.Reply{Text: msgs[len(msgs)-1].String()})
+			}
+		}, func() (genai.Result, error) {
+			return res, err
+		}
+}
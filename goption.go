@@ -11,9 +11,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/invopop/jsonschema"
@@ -108,6 +113,32 @@ func (p GenOptionPollInterval) Validate() error {
 	return nil
 }
 
+// GenOptionProgress is called after each poll while GenSync waits for an asynchronous job to complete, like
+// video generation.
+//
+// It is called from the goroutine calling GenSync.
+type GenOptionProgress func(JobProgress)
+
+// Validate ensures the callback is set.
+func (p GenOptionProgress) Validate() error {
+	if p == nil {
+		return errors.New("must not be nil")
+	}
+	return nil
+}
+
+// JobProgress is the state of an asynchronous job being polled.
+type JobProgress struct {
+	// Job is the job ID.
+	Job Job
+	// Polls is the number of polls done so far, starting at 1.
+	Polls int
+	// Elapsed is the time since the job was submitted.
+	Elapsed time.Duration
+	// Metadata is the provider-specific operation metadata, if any.
+	Metadata map[string]any
+}
+
 // GenOptionText is a list of frequent options supported by most Provider with text output modality.
 // Each provider is free to support more options through a specialized struct.
 //
@@ -135,21 +166,48 @@ type GenOptionText struct {
 	TopK int64
 	// Stop is the list of tokens to stop generation.
 	Stop []string
+	// ReasoningEffort is the effort a reasoning model puts into thinking before replying.
+	//
+	// Each provider maps it to its native setting and reports it as not supported when there is none. A
+	// provider specific option for the same setting takes precedence.
+	ReasoningEffort ReasoningEffort
+	// N is the number of candidates to sample in a single request. 0 and 1 both request one. The first
+	// candidate is returned as Result.Message and the others in Result.Candidates.
+	//
+	// Only GenSync supports more than one candidate; GenStream returns base.ErrNotSupported.
+	N int64
 
 	// ReplyAsJSON enforces the output to be valid JSON, any JSON. It is
 	// important to tell the model to reply in JSON in the prompt itself.
 	ReplyAsJSON bool
 	// DecodeAs enforces a reply with a specific JSON structure. It must be either a pointer to a struct that can be
-	// decoded by encoding/json and can have jsonschema tags, or a JSONSchema.
+	// decoded by encoding/json and can have jsonschema tags, a JSONSchema or a prebuilt *jsonschema.Schema from
+	// github.com/invopop/jsonschema.
+	//
+	// It can also be a pointer to a slice, a map or a primitive type. Since most providers only accept an object
+	// at the top level, the schema is then wrapped in an object with a single "value" property. Message.Decode
+	// transparently unwraps it.
 	//
 	// It is important to request the model to "reply in JSON" in the prompt itself.
 	//
 	// If you use a JSONSchema, it will be used to validate the reply.
 	//
 	// If you use a pointer to a struct, it is recommended to use jsonschema_description tags to describe each
-	// field or argument. Use jsonschema:"enum=..." to enforce a specific value within a set. Use omitempty to
-	// make the field optional. See https://github.com/invopop/jsonschema#example for more examples.
+	// field or argument. Use jsonschema:"enum=..." to enforce a specific value within a set and
+	// jsonschema:"minimum=...,maximum=..." to bound a number. Use omitempty to make the field optional. Fields of
+	// an interface type registered with RegisterUnion accept any of the registered variants. A type can fully
+	// override its schema by implementing JSONSchema() *jsonschema.Schema. See
+	// https://github.com/invopop/jsonschema#example for more examples.
 	DecodeAs any
+	// Grammar constrains the reply to a GBNF grammar, see
+	// https://github.com/ggml-org/llama.cpp/blob/master/grammars/README.md
+	//
+	// It is only supported by llama.cpp and Fireworks. It can't be used along ReplyAsJSON or DecodeAs.
+	Grammar string
+	// StrictSchema requires the reply to conform to DecodeAs. Providers with constrained decoding (OpenAI's strict
+	// json_schema, Gemini's responseSchema) enforce it server side. With other providers, the reply is validated
+	// client side and a *base.ErrSchemaValidation with the offending path is returned when it doesn't conform.
+	StrictSchema bool
 
 	_ struct{}
 }
@@ -171,30 +229,76 @@ func (o *GenOptionText) Validate() error {
 	if o.TopLogprobs < 0 || o.TopLogprobs > 20 {
 		return errors.New("field TopLogprobs: must be [0, 20]")
 	}
+	if o.N < 0 {
+		return errors.New("field N: must be non-negative")
+	}
 	for i, s := range o.Stop {
 		if s == "" {
 			return fmt.Errorf("field Stop[%d]: must not be empty", i)
 		}
 	}
+	if err := o.ReasoningEffort.Validate(); err != nil {
+		return fmt.Errorf("field ReasoningEffort: %w", err)
+	}
 	if o.DecodeAs != nil {
-		if _, ok := o.DecodeAs.(JSONSchema); !ok {
+		switch v := o.DecodeAs.(type) {
+		case JSONSchema:
+		case *jsonschema.Schema:
+			if v == nil {
+				return errors.New("field DecodeAs: must not be a nil *jsonschema.Schema")
+			}
+		default:
 			if err := validateReflectedToJSON(o.DecodeAs); err != nil {
 				return fmt.Errorf("field DecodeAs: %w", err)
 			}
 		}
+	} else if o.StrictSchema {
+		return errors.New("field StrictSchema: requires DecodeAs")
+	}
+	if o.Grammar != "" && (o.ReplyAsJSON || o.DecodeAs != nil) {
+		return errors.New("field Grammar can't be used along ReplyAsJSON or DecodeAs")
 	}
 	return nil
 }
 
+// ReasoningEffort is the effort a reasoning model puts into thinking before replying.
+type ReasoningEffort string
+
+// Reasoning effort values.
+const (
+	// ReasoningEffortOff disables reasoning for models where it is optional.
+	ReasoningEffortOff    ReasoningEffort = "off"
+	ReasoningEffortLow    ReasoningEffort = "low"
+	ReasoningEffortMedium ReasoningEffort = "medium"
+	ReasoningEffortHigh   ReasoningEffort = "high"
+)
+
+// Validate implements Validatable.
+func (r ReasoningEffort) Validate() error {
+	switch r {
+	case "", ReasoningEffortOff, ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh:
+		return nil
+	default:
+		return fmt.Errorf("invalid value %q", r)
+	}
+}
+
 // DecodeSchema returns the JSONSchema for the DecodeAs field.
 //
-// If DecodeAs is a JSONSchema instance, it is returned directly. Otherwise it uses invopop/jsonschema to
-// construct the schema from the type's fields and tags.
+// If DecodeAs is a JSONSchema instance, it is returned directly and a *jsonschema.Schema is serialized.
+// Otherwise it uses invopop/jsonschema to construct the schema from the type's fields and tags.
 func (o *GenOptionText) DecodeSchema() (JSONSchema, error) {
-	if j, ok := o.DecodeAs.(JSONSchema); ok {
-		return j, nil
+	switch v := o.DecodeAs.(type) {
+	case JSONSchema:
+		return v, nil
+	case *jsonschema.Schema:
+		return json.Marshal(v)
+	}
+	t := reflect.TypeOf(o.DecodeAs)
+	if t.Kind() == reflect.Pointer && t.Elem().Kind() != reflect.Struct {
+		return wrappedJSONSchemaFor(t.Elem())
 	}
-	return jsonSchemaFor(reflect.TypeOf(o.DecodeAs))
+	return jsonSchemaFor(t)
 }
 
 // Tools
@@ -205,24 +309,149 @@ type GenOptionTools struct {
 	Tools []ToolDef
 	// Force tells the LLM a tool call must be done, or not.
 	Force ToolCallRequest
+	// Concurrency is the maximum number of tool calls run concurrently by the tool call loops in package
+	// adapters, when the LLM requests multiple tool calls in a single reply. The default of 0 runs them
+	// sequentially. Use -1 for no limit. It is not sent to the provider.
+	Concurrency int
+	// Allow, when not empty, is the list of tool names that may be executed. The other tools are not sent to the
+	// provider. Pass AllowedTools() to Message.DoToolCalls so a call to any other tool is returned to the LLM as
+	// an error instead of being run.
+	Allow []string
+	// Deny is the list of tool names that must not be executed. It has precedence over Allow. Denied tools are
+	// not sent to the provider.
+	Deny []string
+	// Computer enables the LLM to control a computer. The actions are returned as ToolCall named
+	// ComputerToolName.
+	//
+	// Currently supported by Anthropic and OpenAI Responses.
+	Computer *Computer
 }
 
 // GenOptionWeb specifies web access options.
 type GenOptionWeb struct {
 	// Search specifies if websearch should be enabled. It is generally disabled by default except for
 	// perplexity.
-	//
-	// # Warning
-	//
-	// This will become a structure to provide information about included and excluded domains, and the user's
-	// location.
 	Search bool
+	// SearchOptions refines the web search. It requires Search. Providers return a base.ErrNotSupported
+	// listing the fields they can't honor.
+	SearchOptions *WebSearchOptions
 	// Fetch specifies if web fetch should be enabled. When enabled, the LLM can fetch content from URLs.
 	//
 	// Currently supported by Anthropic and Gemini.
 	Fetch bool
 }
 
+// WebSearchOptions refines the web search enabled with GenOptionWeb.Search.
+type WebSearchOptions struct {
+	// AllowedDomains limits the search to these domains, e.g. "wikipedia.org".
+	//
+	// Supported by Anthropic, Groq, OpenAI Responses and Perplexity.
+	AllowedDomains []string
+	// BlockedDomains excludes these domains from the search. It can't be used along AllowedDomains.
+	//
+	// Supported by Anthropic, Groq and Perplexity.
+	BlockedDomains []string
+	// MaxSearches is the maximum number of searches the LLM can do in one request.
+	//
+	// Supported by Anthropic.
+	MaxSearches int64
+	// ContextSize is the amount of search results to put in the context: "low", "medium" or "high".
+	//
+	// Supported by OpenAI and Perplexity.
+	ContextSize string
+	// UserLocation is the approximate location of the user, to return locally relevant results.
+	UserLocation UserLocation
+}
+
+// Validate ensures the web search options are valid.
+func (w *WebSearchOptions) Validate() error {
+	if len(w.AllowedDomains) != 0 && len(w.BlockedDomains) != 0 {
+		return errors.New("field BlockedDomains can't be used along AllowedDomains")
+	}
+	for i, d := range w.AllowedDomains {
+		if d == "" {
+			return fmt.Errorf("field AllowedDomains: entry %d is empty", i)
+		}
+	}
+	for i, d := range w.BlockedDomains {
+		if d == "" {
+			return fmt.Errorf("field BlockedDomains: entry %d is empty", i)
+		}
+	}
+	if w.MaxSearches < 0 {
+		return errors.New("field MaxSearches: must be non-negative")
+	}
+	switch w.ContextSize {
+	case "", "low", "medium", "high":
+	default:
+		return fmt.Errorf("field ContextSize: invalid value %q", w.ContextSize)
+	}
+	if err := w.UserLocation.Validate(); err != nil {
+		return fmt.Errorf("field UserLocation: %w", err)
+	}
+	return nil
+}
+
+// UserLocation is the approximate location of the user. All the fields are optional.
+type UserLocation struct {
+	// City is the name of the city, e.g. "Montréal".
+	City string
+	// Region is the name of the region or state, e.g. "Québec".
+	Region string
+	// Country is the ISO 3166-1 alpha-2 code of the country, e.g. "CA".
+	Country string
+	// Timezone is the IANA time zone, e.g. "America/Toronto".
+	Timezone string
+	// Latitude and Longitude are in degrees. They are only used when both are set.
+	Latitude  float64
+	Longitude float64
+}
+
+// IsZero returns true if no location is specified.
+func (u *UserLocation) IsZero() bool {
+	return u.City == "" && u.Region == "" && u.Country == "" && u.Timezone == "" && !u.HasLatLng()
+}
+
+// HasLatLng returns true if the coordinates are specified.
+func (u *UserLocation) HasLatLng() bool {
+	return u.Latitude != 0 || u.Longitude != 0
+}
+
+// Validate ensures the location is valid.
+func (u *UserLocation) Validate() error {
+	if u.Country != "" && (len(u.Country) != 2 || strings.ToUpper(u.Country) != u.Country) {
+		return fmt.Errorf("field Country: must be an ISO 3166-1 alpha-2 code, got %q", u.Country)
+	}
+	if u.Latitude < -90 || u.Latitude > 90 {
+		return fmt.Errorf("field Latitude: must be between -90 and 90, got %g", u.Latitude)
+	}
+	if u.Longitude < -180 || u.Longitude > 180 {
+		return fmt.Errorf("field Longitude: must be between -180 and 180, got %g", u.Longitude)
+	}
+	return nil
+}
+
+// GenOptionFileSearch enables searching vector stores hosted on the provider.
+//
+// Create the stores with ProviderVectorStore.
+type GenOptionFileSearch struct {
+	// StoreIDs are the IDs returned by ProviderVectorStore.VectorStoreAdd.
+	StoreIDs []string
+}
+
+// Validate ensures the file search options are valid.
+func (o *GenOptionFileSearch) Validate() error {
+	if len(o.StoreIDs) == 0 {
+		return errors.New("field StoreIDs: required")
+	}
+	for i, id := range o.StoreIDs {
+		if id == "" {
+			return fmt.Errorf("field StoreIDs: entry %d is empty", i)
+		}
+	}
+	return nil
+}
+
 // Validate ensures the completion options are valid.
 func (o *GenOptionTools) Validate() error {
 	names := map[string]int{}
@@ -235,14 +464,68 @@ func (o *GenOptionTools) Validate() error {
 		}
 		names[t.Name] = i
 	}
-	if len(o.Tools) == 0 && o.Force == ToolCallRequired {
+	if o.Computer != nil {
+		if err := o.Computer.Validate(); err != nil {
+			return fmt.Errorf("field Computer: %w", err)
+		}
+		if j, ok := names[ComputerToolName]; ok {
+			return fmt.Errorf("tool %d: has name %q which is reserved when Computer is set", j, ComputerToolName)
+		}
+	}
+	if len(o.Tools) == 0 && o.Computer == nil && o.Force == ToolCallRequired {
 		return errors.New("field Force is ToolCallRequired: Tools are required")
 	}
+	if o.Concurrency < -1 {
+		return fmt.Errorf("field Concurrency must be -1 or greater, got %d", o.Concurrency)
+	}
+	for i, n := range o.Allow {
+		if _, ok := names[n]; !ok {
+			return fmt.Errorf("field Allow[%d]: unknown tool %q", i, n)
+		}
+	}
+	for i, n := range o.Deny {
+		if _, ok := names[n]; !ok {
+			return fmt.Errorf("field Deny[%d]: unknown tool %q", i, n)
+		}
+	}
+	if len(o.Tools) != 0 && o.Computer == nil && o.Force == ToolCallRequired && len(o.AllowedTools()) == 0 {
+		return errors.New("field Force is ToolCallRequired: all Tools are denied")
+	}
 	return nil
 }
 
+// IsAllowed returns true if the tool named name may be executed according to Allow and Deny.
+func (o *GenOptionTools) IsAllowed(name string) bool {
+	if slices.Contains(o.Deny, name) {
+		return false
+	}
+	return len(o.Allow) == 0 || slices.Contains(o.Allow, name)
+}
+
+// AllowedTools returns the tools in Tools that are allowed according to Allow and Deny.
+func (o *GenOptionTools) AllowedTools() []ToolDef {
+	if len(o.Allow) == 0 && len(o.Deny) == 0 {
+		return o.Tools
+	}
+	var out []ToolDef
+	for _, t := range o.Tools {
+		if o.IsAllowed(t.Name) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
 // Validate implements GenOption.
 func (o *GenOptionWeb) Validate() error {
+	if o.SearchOptions != nil {
+		if !o.Search {
+			return errors.New("field SearchOptions: requires Search")
+		}
+		if err := o.SearchOptions.Validate(); err != nil {
+			return fmt.Errorf("field SearchOptions: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -262,7 +545,16 @@ type ToolDef struct {
 	// to describe as struct tags.
 	//
 	// It is okay to initialize Callback, then take the return value of GetInputSchema() to initialize InputSchemaOverride, then mutate it.
+	//
+	// It is sent to the provider as-is, without the conversion some providers apply to the deduced schema.
 	InputSchemaOverride JSONSchema
+	// Timeout is the maximum duration of a Callback invocation. When it expires, the context passed to Callback
+	// is canceled and ToolCall.Call returns an error without waiting for Callback to return. 0 means no timeout.
+	Timeout time.Duration
+	// MaxResultBytes truncates the result returned by Callback to this many bytes, on a UTF-8 rune boundary, to
+	// protect the context window from oversized results. A marker is appended to the truncated result so the LLM
+	// knows it is incomplete. 0 means no limit.
+	MaxResultBytes int
 
 	_ struct{}
 }
@@ -280,6 +572,12 @@ func (t *ToolDef) Validate() error {
 	if t.Description == "" {
 		return errors.New("field Description: required")
 	}
+	if t.Timeout < 0 {
+		return fmt.Errorf("field Timeout: must be positive, got %s", t.Timeout)
+	}
+	if t.MaxResultBytes < 0 {
+		return fmt.Errorf("field MaxResultBytes: must be positive, got %d", t.MaxResultBytes)
+	}
 	if t.Callback != nil {
 		cbType := reflect.TypeOf(t.Callback)
 		if cbType.Kind() != reflect.Func {
@@ -321,6 +619,9 @@ func (t *ToolDef) GetInputSchema() (JSONSchema, error) {
 	if len(t.InputSchemaOverride) != 0 {
 		return t.InputSchemaOverride, nil
 	}
+	if t.Callback == nil {
+		return nil, errors.New("field Callback or InputSchemaOverride is required")
+	}
 	// This function assumes Validate() was called.
 	return jsonSchemaFor(reflect.TypeOf(t.Callback).In(1))
 }
@@ -342,13 +643,32 @@ const (
 
 // Other modalities
 
-// GenOptionAudio specifies audio generation options.
+// GenOptionAudio specifies audio options for models that accept or generate audio, like gpt-4o-audio or
+// voxtral.
 type GenOptionAudio struct {
+	// InputLanguage is a hint for the language spoken in the audio input, as an ISO-639-1 code like "en" or
+	// "fr". When empty, the model detects the language.
+	InputLanguage string
+	// TextOnly requests a text only reply. The audio input is still processed. By default, models that can
+	// generate audio reply with both audio and text.
+	TextOnly bool
+	// OmitTranscript drops the transcript of the generated audio from the replies. By default, it is returned
+	// as a text Reply.
+	OmitTranscript bool
+
 	_ struct{}
 }
 
 // Validate implements Validatable.
 func (o *GenOptionAudio) Validate() error {
+	if l := o.InputLanguage; l != "" {
+		if len(l) < 2 || len(l) > 3 || strings.IndexFunc(l, func(r rune) bool { return r < 'a' || r > 'z' }) != -1 {
+			return fmt.Errorf("field InputLanguage: invalid ISO-639 language code %q", l)
+		}
+	}
+	if o.TextOnly && o.OmitTranscript {
+		return errors.New("field OmitTranscript: cannot be used with TextOnly")
+	}
 	return nil
 }
 
@@ -357,6 +677,8 @@ func (o *GenOptionAudio) Validate() error {
 type GenOptionImage struct {
 	Width  int
 	Height int
+	// Count is the number of images to generate. Each image is returned as a separate Reply. Defaults to 1.
+	Count int
 
 	_ struct{}
 }
@@ -369,6 +691,9 @@ func (o *GenOptionImage) Validate() error {
 	if o.Width < 0 {
 		return errors.New("field Width: must be non-negative")
 	}
+	if o.Count < 0 {
+		return errors.New("field Count: must be non-negative")
+	}
 	return nil
 }
 
@@ -378,31 +703,279 @@ type GenOptionVideo struct {
 	//
 	// Veo 2 supports only between 5 and 8 seconds and Veo 3 only supports 8 seconds.
 	Duration time.Duration
+	// Resolution of the video to generate, e.g. "720p" or "1080p".
+	Resolution string
+	// FPS is the number of frames per second, if supported.
+	FPS int
+	// GenerateAudio requests or suppresses an audio track along the video, if supported. When nil, the
+	// provider's default applies.
+	GenerateAudio *bool
+	// NegativePrompt describes what to avoid in the video.
+	NegativePrompt string
+	// LastFrame is an image to use as the last frame of the video. The first frame is specified as an image Doc
+	// in the message.
+	LastFrame Doc
 
 	_ struct{}
 }
 
 // Validate implements Validatable.
 func (o *GenOptionVideo) Validate() error {
+	if o.Duration < 0 {
+		return errors.New("field Duration: must be non-negative")
+	}
+	if o.FPS < 0 {
+		return errors.New("field FPS: must be non-negative")
+	}
+	if r := o.Resolution; r != "" {
+		if n, err := strconv.Atoi(strings.TrimSuffix(r, "p")); err != nil || n <= 0 || !strings.HasSuffix(r, "p") {
+			return fmt.Errorf("field Resolution: invalid value %q, expected a value like \"720p\"", r)
+		}
+	}
+	if !o.LastFrame.IsZero() {
+		if err := o.LastFrame.Validate(); err != nil {
+			return fmt.Errorf("field LastFrame: %w", err)
+		}
+	}
+	return nil
+}
+
+// GenOptionRaw is an escape hatch to set provider request fields that genai doesn't support yet.
+//
+// Fields are merged into the JSON request body after serialization and take precedence over the fields set by
+// genai. When both values are JSON objects, they are merged recursively. A nil value removes the field.
+//
+// It is only supported by providers sending JSON requests via package base. Use sparingly: the fields are
+// not validated and may break as the provider API evolves.
+type GenOptionRaw struct {
+	// Fields are the top level fields to merge, e.g. {"generationConfig": {"newField": true}}.
+	Fields map[string]any
+
+	_ struct{}
+}
+
+// Validate implements Validatable.
+func (o *GenOptionRaw) Validate() error {
+	if len(o.Fields) == 0 {
+		return errors.New("field Fields is required")
+	}
+	return nil
+}
+
+// GenOptionHeaders adds HTTP headers to the requests sent for this generation, e.g. to enable a provider
+// beta feature or to route the request through a gateway.
+//
+// The headers replace the ones set by genai with the same name, except for Anthropic's "anthropic-beta"
+// which is combined. It is only supported by providers sending JSON requests via package base.
+type GenOptionHeaders http.Header
+
+// Validate implements Validatable.
+func (h GenOptionHeaders) Validate() error {
+	if len(h) == 0 {
+		return errors.New("at least one header is required")
+	}
+	for k, v := range h {
+		if k == "" || strings.ContainsAny(k, " :\r\n") {
+			return fmt.Errorf("invalid header name %q", k)
+		}
+		if len(v) == 0 {
+			return fmt.Errorf("header %q: at least one value is required", k)
+		}
+	}
+	return nil
+}
+
+// GenOptionTimeout limits the time spent on a generation, independently of the context deadline.
+//
+// It is only supported by providers sending JSON requests via package base.
+type GenOptionTimeout struct {
+	// Request is the maximum duration of each HTTP request attempt, including reading the response body. A
+	// timed out attempt may be retried by base.DefaultTransport.
+	Request time.Duration
+	// Total is the maximum duration of all the attempts combined, including the retries and the time spent
+	// streaming the reply.
+	Total time.Duration
+	// Idle is the maximum duration without receiving data while streaming the reply with GenStream. When it
+	// expires, the stream is aborted with a *base.ErrIdleTimeout so the caller can retry.
+	Idle time.Duration
+}
+
+// Validate implements Validatable.
+func (o *GenOptionTimeout) Validate() error {
+	if o.Request < 0 {
+		return errors.New("field Request: must be non-negative")
+	}
+	if o.Total < 0 {
+		return errors.New("field Total: must be non-negative")
+	}
+	if o.Idle < 0 {
+		return errors.New("field Idle: must be non-negative")
+	}
+	if o.Request == 0 && o.Total == 0 && o.Idle == 0 {
+		return errors.New("one of Request, Total or Idle is required")
+	}
+	return nil
+}
+
+// GenOptionOutput writes the generated binary content, like images and audio, to files instead of keeping
+// it in memory. This avoids accumulating large audio or video outputs streamed by GenStream.
+//
+// The Doc.Src of each generated document in the Result is the value returned by New. The caller is
+// responsible for closing and deleting them. The fragments yielded by GenStream are not affected. Providers
+// not supporting it return a base.ErrNotSupported.
+type GenOptionOutput struct {
+	// New returns the file to write the document named filename into. filename may be empty. Defaults to a
+	// temporary file created with os.CreateTemp.
+	New func(filename string) (io.ReadWriteSeeker, error)
+	// MaxSize is the maximum number of bytes written for all the documents of a generation. The generation
+	// fails once it is exceeded. 0 means no limit.
+	MaxSize int64
+}
+
+// Validate implements Validatable.
+func (o *GenOptionOutput) Validate() error {
+	if o.MaxSize < 0 {
+		return errors.New("field MaxSize: must be non-negative")
+	}
+	return nil
+}
+
+// GenOptionCapture is called with each raw HTTP exchange done for a generation, for audit logging and
+// debugging.
+//
+// It is called once the response body is closed, before GenSync returns or before the GenStream finish
+// function returns, so the exchange can be correlated with the Result. For GenStream, Response contains the
+// whole Server-Sent Events transcript. It is only supported by providers sending JSON requests via package
+// base.
+type GenOptionCapture func(*HTTPExchange)
+
+// Validate ensures the callback is set.
+func (c GenOptionCapture) Validate() error {
+	if c == nil {
+		return errors.New("must not be nil")
+	}
 	return nil
 }
 
+// HTTPExchange is a raw HTTP request and its response as captured by GenOptionCapture.
+//
+// The headers added by the transport, like authentication, are not included.
+type HTTPExchange struct {
+	Method string
+	URL    string
+	// Request is the serialized request body, if any.
+	Request []byte
+	// StatusCode, Header and Response are the HTTP response, unset when Err is set.
+	StatusCode int
+	Header     http.Header
+	// Response is the raw response body, as read by the provider.
+	Response []byte
+	// Err is the error returned by the transport.
+	Err error
+}
+
 // Private
 
 func validateReflectedToJSON(r any) error {
 	tp := reflect.TypeOf(r)
-	if tp.Kind() != reflect.Pointer || tp.Elem().Kind() != reflect.Struct {
+	if tp == nil || tp.Kind() != reflect.Pointer {
 		return fmt.Errorf("must be a pointer to a struct, got %T", r)
 	}
-	return nil
+	switch tp.Elem().Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Map:
+		if tp.Elem().Key().Kind() != reflect.String {
+			return fmt.Errorf("map keys must be strings, got %T", r)
+		}
+		return nil
+	default:
+		return fmt.Errorf("must be a pointer to a struct, slice, map or primitive type, got %T", r)
+	}
 }
 
-// jsonSchemaFor returns the JSON schema for the given type as raw JSON.
+// decodeWrapKey is the property used to wrap non-object DecodeAs schemas.
+const decodeWrapKey = "value"
+
+var (
+	unionsMu sync.RWMutex
+	unions   = map[reflect.Type][]reflect.Type{}
+)
+
+// RegisterUnion registers the concrete types implementing the interface I, so fields of type I in
+// GenOptionText.DecodeAs and ToolDef.Callback's input are described as any of the variants' schemas instead of
+// accepting any value.
+//
+// The union is described with "anyOf" since "oneOf" is rejected by many providers. encoding/json can't decode
+// into an interface, so the type containing the field must implement json.Unmarshaler to pick the variant.
+//
+// It is meant to be called from an init function. Registering I again replaces its variants.
+func RegisterUnion[I any](variants ...I) {
+	t := reflect.TypeFor[I]()
+	if t.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("RegisterUnion: %s is not an interface", t))
+	}
+	if len(variants) == 0 {
+		panic(fmt.Sprintf("RegisterUnion: %s has no variant", t))
+	}
+	types := make([]reflect.Type, len(variants))
+	for i, v := range variants {
+		if types[i] = reflect.TypeOf(v); types[i] == nil {
+			panic(fmt.Sprintf("RegisterUnion: %s variant #%d is nil", t, i))
+		}
+	}
+	unionsMu.Lock()
+	defer unionsMu.Unlock()
+	unions[t] = types
+}
+
+// newReflector returns a jsonschema.Reflector that inlines definitions and describes the interfaces registered
+// with RegisterUnion.
 //
 // Many providers (including OpenAI) struggle with $ref that jsonschema package uses by default.
+func newReflector() *jsonschema.Reflector {
+	r := &jsonschema.Reflector{Anonymous: true, DoNotReference: true}
+	r.Mapper = func(t reflect.Type) *jsonschema.Schema {
+		unionsMu.RLock()
+		variants := unions[t]
+		unionsMu.RUnlock()
+		if len(variants) == 0 {
+			return nil
+		}
+		s := &jsonschema.Schema{}
+		for _, v := range variants {
+			vs := r.ReflectFromType(v)
+			vs.Version = ""
+			s.AnyOf = append(s.AnyOf, vs)
+		}
+		return s
+	}
+	return r
+}
+
+// wrappedJSONSchemaFor returns the JSON schema for the given non-struct type, wrapped in an object.
+func wrappedJSONSchemaFor(t reflect.Type) (JSONSchema, error) {
+	schema := newReflector().ReflectFromType(t)
+	schema.Version = ""
+	inner, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]any{
+		"$schema":              jsonschema.Version,
+		"type":                 "object",
+		"properties":           map[string]json.RawMessage{decodeWrapKey: inner},
+		"required":             []string{decodeWrapKey},
+		"additionalProperties": false,
+	})
+}
+
+// jsonSchemaFor returns the JSON schema for the given type as raw JSON.
 func jsonSchemaFor(t reflect.Type) (JSONSchema, error) {
-	r := jsonschema.Reflector{Anonymous: true, DoNotReference: true}
-	schema := r.ReflectFromType(t)
+	schema := newReflector().ReflectFromType(t)
 	b, err := json.Marshal(schema)
 	return b, err
 }
@@ -414,11 +987,16 @@ func isErrorType(t reflect.Type) bool {
 
 var (
 	_ GenOption            = GenOptionPollInterval(time.Second)
+	_ GenOption            = GenOptionProgress(func(JobProgress) {})
+	_ GenOption            = GenOptionCapture(func(*HTTPExchange) {})
 	_ GenOption            = GenOptionSeed(1)
 	_ GenOption            = (*GenOptionAudio)(nil)
 	_ GenOption            = (*GenOptionImage)(nil)
 	_ GenOption            = (*GenOptionVideo)(nil)
+	_ GenOption            = (*GenOptionFileSearch)(nil)
+	_ GenOption            = (*GenOptionOutput)(nil)
 	_ GenOption            = (*GenOptionText)(nil)
+	_ GenOption            = (*GenOptionTimeout)(nil)
 	_ GenOption            = (*GenOptionTools)(nil)
 	_ GenOption            = (*GenOptionWeb)(nil)
 	_ internal.Validatable = (*Modality)(nil)
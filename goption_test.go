@@ -9,8 +9,12 @@ package genai
 import (
 	"context"
 	"encoding/json"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/invopop/jsonschema"
 )
 
 func TestModalities(t *testing.T) {
@@ -87,6 +91,33 @@ func TestGenOptionPollInterval(t *testing.T) {
 	})
 }
 
+func TestGenOptionProgress(t *testing.T) {
+	if err := GenOptionProgress(func(JobProgress) {}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenOptionProgress(nil).Validate(); err == nil || err.Error() != "must not be nil" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGenOptionCapture(t *testing.T) {
+	if err := GenOptionCapture(func(*HTTPExchange) {}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenOptionCapture(nil).Validate(); err == nil || err.Error() != "must not be nil" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGenOptionOutput(t *testing.T) {
+	if err := (&GenOptionOutput{MaxSize: 1024}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&GenOptionOutput{MaxSize: -1}).Validate(); err == nil || err.Error() != "field MaxSize: must be non-negative" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestGenOptionText(t *testing.T) {
 	t.Run("DecodeSchema", func(t *testing.T) {
 		t.Run("JSONSchema passthrough", func(t *testing.T) {
@@ -121,6 +152,62 @@ func TestGenOptionText(t *testing.T) {
 				t.Errorf("missing radius property in schema: %s", got)
 			}
 		})
+		t.Run("slice pointer wraps schema", func(t *testing.T) {
+			opts := GenOptionText{DecodeAs: &[]string{}}
+			got, err := opts.DecodeSchema()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","additionalProperties":false,"properties":{"value":{"items":{"type":"string"},"type":"array"}},"required":["value"],"type":"object"}`
+			if string(got) != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+		t.Run("jsonschema.Schema", func(t *testing.T) {
+			opts := GenOptionText{DecodeAs: &jsonschema.Schema{Type: "object", Required: []string{"x"}}}
+			if err := opts.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := opts.DecodeSchema()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := `{"type":"object","required":["x"]}`; string(got) != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+		t.Run("union", func(t *testing.T) {
+			RegisterUnion[testShape](&testCircle{}, &testSquare{})
+			type drawing struct {
+				Shape testShape `json:"shape" jsonschema:"description=shape to draw"`
+				Size  int       `json:"size" jsonschema:"minimum=1,maximum=10"`
+			}
+			got, err := (&GenOptionText{DecodeAs: &drawing{}}).DecodeSchema()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m struct {
+				Properties struct {
+					Shape struct {
+						Description string           `json:"description"`
+						AnyOf       []map[string]any `json:"anyOf"`
+					} `json:"shape"`
+					Size struct {
+						Minimum json.Number `json:"minimum"`
+						Maximum json.Number `json:"maximum"`
+					} `json:"size"`
+				} `json:"properties"`
+			}
+			if err := json.Unmarshal(got, &m); err != nil {
+				t.Fatal(err)
+			}
+			if s := m.Properties.Shape; s.Description != "shape to draw" || len(s.AnyOf) != 2 || s.AnyOf[0]["$schema"] != nil {
+				t.Errorf("unexpected schema: %s", got)
+			}
+			if s := m.Properties.Size; s.Minimum != "1" || s.Maximum != "10" {
+				t.Errorf("unexpected schema: %s", got)
+			}
+		})
 		t.Run("empty JSONSchema passthrough", func(t *testing.T) {
 			schema := JSONSchema(`{}`)
 			opts := GenOptionText{DecodeAs: schema}
@@ -143,19 +230,24 @@ func TestGenOptionText(t *testing.T) {
 				{
 					name: "Valid options with all fields set",
 					in: GenOptionText{
-						Temperature: 0.5,
-						TopP:        0.5,
-						TopK:        10,
-						MaxTokens:   100,
-						Stop:        []string{"stop"},
-						ReplyAsJSON: true,
-						DecodeAs:    &struct{}{},
+						Temperature:     0.5,
+						TopP:            0.5,
+						TopK:            10,
+						MaxTokens:       100,
+						Stop:            []string{"stop"},
+						ReasoningEffort: ReasoningEffortLow,
+						ReplyAsJSON:     true,
+						DecodeAs:        &struct{}{},
 					},
 				},
 				{
 					name: "Valid options with only DecodeAs pointer",
 					in:   GenOptionText{DecodeAs: &struct{}{}},
 				},
+				{
+					name: "Valid StrictSchema",
+					in:   GenOptionText{DecodeAs: &struct{}{}, StrictSchema: true},
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -191,6 +283,16 @@ func TestGenOptionText(t *testing.T) {
 					in:     GenOptionText{TopK: 1025},
 					errMsg: "field TopK: must be [0, 1024]",
 				},
+				{
+					name:   "Invalid ReasoningEffort",
+					in:     GenOptionText{ReasoningEffort: "max"},
+					errMsg: "field ReasoningEffort: invalid value \"max\"",
+				},
+				{
+					name:   "Invalid N",
+					in:     GenOptionText{N: -1},
+					errMsg: "field N: must be non-negative",
+				},
 				{
 					name:   "Invalid TopLogprobs negative",
 					in:     GenOptionText{TopLogprobs: -1},
@@ -216,6 +318,16 @@ func TestGenOptionText(t *testing.T) {
 					in:     GenOptionText{DecodeAs: "string"},
 					errMsg: "field DecodeAs: must be a pointer to a struct, got string",
 				},
+				{
+					name:   "Grammar with DecodeAs",
+					in:     GenOptionText{Grammar: `root ::= "yes" | "no"`, DecodeAs: &struct{}{}},
+					errMsg: "field Grammar can't be used along ReplyAsJSON or DecodeAs",
+				},
+				{
+					name:   "StrictSchema without DecodeAs",
+					in:     GenOptionText{StrictSchema: true},
+					errMsg: "field StrictSchema: requires DecodeAs",
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -228,6 +340,20 @@ func TestGenOptionText(t *testing.T) {
 	})
 }
 
+type testShape interface{ area() float64 }
+
+type testCircle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c *testCircle) area() float64 { return 3.14 * c.Radius * c.Radius }
+
+type testSquare struct {
+	Side float64 `json:"side"`
+}
+
+func (s *testSquare) area() float64 { return s.Side * s.Side }
+
 func TestGenOptionTools(t *testing.T) {
 	t.Run("Validate", func(t *testing.T) {
 		t.Run("valid", func(t *testing.T) {
@@ -247,6 +373,10 @@ func TestGenOptionTools(t *testing.T) {
 						Force: ToolCallRequired,
 					},
 				},
+				{
+					name: "Computer only",
+					in:   GenOptionTools{Computer: &Computer{Width: 1024, Height: 768}, Force: ToolCallRequired},
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -288,6 +418,43 @@ func TestGenOptionTools(t *testing.T) {
 					},
 					errMsg: "tool 0: field Description: required",
 				},
+				{
+					name: "Allow unknown tool",
+					in: GenOptionTools{
+						Tools: []ToolDef{{Name: "tool1", Description: "desc1"}},
+						Allow: []string{"tool2"},
+					},
+					errMsg: "field Allow[0]: unknown tool \"tool2\"",
+				},
+				{
+					name: "Force with all tools denied",
+					in: GenOptionTools{
+						Tools: []ToolDef{{Name: "tool1", Description: "desc1"}},
+						Force: ToolCallRequired,
+						Deny:  []string{"tool1"},
+					},
+					errMsg: "field Force is ToolCallRequired: all Tools are denied",
+				},
+				{
+					name: "Negative Timeout",
+					in: GenOptionTools{
+						Tools: []ToolDef{{Name: "tool1", Description: "desc1", Timeout: -time.Second}},
+					},
+					errMsg: "tool 0: field Timeout: must be positive, got -1s",
+				},
+				{
+					name:   "Computer without size",
+					in:     GenOptionTools{Computer: &Computer{Width: 1024}},
+					errMsg: "field Computer: field Height: must be positive",
+				},
+				{
+					name: "Computer name conflict",
+					in: GenOptionTools{
+						Tools:    []ToolDef{{Name: ComputerToolName, Description: "desc1"}},
+						Computer: &Computer{Width: 1024, Height: 768},
+					},
+					errMsg: "tool 0: has name \"computer\" which is reserved when Computer is set",
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -298,6 +465,31 @@ func TestGenOptionTools(t *testing.T) {
 			}
 		})
 	})
+	t.Run("IsAllowed", func(t *testing.T) {
+		o := GenOptionTools{Allow: []string{"a", "b"}, Deny: []string{"b"}}
+		for name, want := range map[string]bool{"a": true, "b": false, "c": false} {
+			if got := o.IsAllowed(name); got != want {
+				t.Errorf("IsAllowed(%q) = %t, want %t", name, got, want)
+			}
+		}
+		if !(&GenOptionTools{}).IsAllowed("c") {
+			t.Error("expected all tools to be allowed by default")
+		}
+	})
+	t.Run("AllowedTools", func(t *testing.T) {
+		o := GenOptionTools{
+			Tools: []ToolDef{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			Allow: []string{"a", "b"},
+			Deny:  []string{"b"},
+		}
+		var got []string
+		for _, td := range o.AllowedTools() {
+			got = append(got, td.Name)
+		}
+		if !slices.Equal(got, []string{"a"}) {
+			t.Errorf("AllowedTools() = %v, want [a]", got)
+		}
+	})
 }
 
 func TestToolDef(t *testing.T) {
@@ -449,6 +641,10 @@ func TestToolDef(t *testing.T) {
 		if _, ok := m["properties"].(map[string]any)["value"]; !ok {
 			t.Errorf("Expected schema to have 'value' property, got %s", schema)
 		}
+		tool = ToolDef{Name: "testTool", Description: "A test tool"}
+		if _, err := tool.GetInputSchema(); err == nil || err.Error() != "field Callback or InputSchemaOverride is required" {
+			t.Fatalf("unexpected error: %v", err)
+		}
 	})
 }
 
@@ -459,27 +655,102 @@ func TestGenOptionWeb(t *testing.T) {
 			{Search: true},
 			{Fetch: true},
 			{Search: true, Fetch: true},
+			{Search: true, SearchOptions: &WebSearchOptions{AllowedDomains: []string{"example.com"}, ContextSize: "low", UserLocation: UserLocation{Country: "CA", Latitude: 45.5, Longitude: -73.6}}},
 		} {
 			if err := o.Validate(); err != nil {
 				t.Errorf("Validate(%+v) got unexpected error: %v", o, err)
 			}
 		}
 	})
+	t.Run("error", func(t *testing.T) {
+		tests := []struct {
+			in   *GenOptionWeb
+			want string
+		}{
+			{&GenOptionWeb{SearchOptions: &WebSearchOptions{}}, "field SearchOptions: requires Search"},
+			{&GenOptionWeb{Search: true, SearchOptions: &WebSearchOptions{AllowedDomains: []string{"a.com"}, BlockedDomains: []string{"b.com"}}}, "field SearchOptions: field BlockedDomains can't be used along AllowedDomains"},
+			{&GenOptionWeb{Search: true, SearchOptions: &WebSearchOptions{ContextSize: "huge"}}, "field SearchOptions: field ContextSize: invalid value \"huge\""},
+			{&GenOptionWeb{Search: true, SearchOptions: &WebSearchOptions{UserLocation: UserLocation{Country: "Canada"}}}, "field SearchOptions: field UserLocation: field Country: must be an ISO 3166-1 alpha-2 code, got \"Canada\""},
+		}
+		for _, tt := range tests {
+			if err := tt.in.Validate(); err == nil || err.Error() != tt.want {
+				t.Errorf("Validate(%+v) want %q, got %v", tt.in, tt.want, err)
+			}
+		}
+	})
+}
+
+func TestGenOptionFileSearch(t *testing.T) {
+	t.Run("Validate", func(t *testing.T) {
+		t.Run("valid", func(t *testing.T) {
+			o := &GenOptionFileSearch{StoreIDs: []string{"vs_1", "vs_2"}}
+			if err := o.Validate(); err != nil {
+				t.Errorf("Validate(%+v) got unexpected error: %v", o, err)
+			}
+		})
+		t.Run("error", func(t *testing.T) {
+			tests := []struct {
+				in   *GenOptionFileSearch
+				want string
+			}{
+				{&GenOptionFileSearch{}, "field StoreIDs: required"},
+				{&GenOptionFileSearch{StoreIDs: []string{"vs_1", ""}}, "field StoreIDs: entry 1 is empty"},
+			}
+			for _, tt := range tests {
+				if err := tt.in.Validate(); err == nil || err.Error() != tt.want {
+					t.Errorf("Validate(%+v) want error %q, got %v", tt.in, tt.want, err)
+				}
+			}
+		})
+	})
 }
 
 func TestGenOptionAudio(t *testing.T) {
 	t.Run("Validate", func(t *testing.T) {
-		o := &GenOptionAudio{}
-		if err := o.Validate(); err != nil {
-			t.Errorf("Validate() got unexpected error: %v", err)
-		}
+		t.Run("valid", func(t *testing.T) {
+			for _, o := range []GenOptionAudio{{}, {InputLanguage: "fr", TextOnly: true}, {InputLanguage: "yue", OmitTranscript: true}} {
+				if err := o.Validate(); err != nil {
+					t.Errorf("Validate(%+v) got unexpected error: %v", o, err)
+				}
+			}
+		})
+		t.Run("error", func(t *testing.T) {
+			tests := []struct {
+				name   string
+				in     GenOptionAudio
+				errMsg string
+			}{
+				{
+					name:   "InputLanguage uppercase",
+					in:     GenOptionAudio{InputLanguage: "EN"},
+					errMsg: "field InputLanguage: invalid ISO-639 language code \"EN\"",
+				},
+				{
+					name:   "InputLanguage locale",
+					in:     GenOptionAudio{InputLanguage: "en-US"},
+					errMsg: "field InputLanguage: invalid ISO-639 language code \"en-US\"",
+				},
+				{
+					name:   "TextOnly and OmitTranscript",
+					in:     GenOptionAudio{TextOnly: true, OmitTranscript: true},
+					errMsg: "field OmitTranscript: cannot be used with TextOnly",
+				},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					if err := tt.in.Validate(); err == nil || err.Error() != tt.errMsg {
+						t.Fatalf("Validate() got error %v, want %q", err, tt.errMsg)
+					}
+				})
+			}
+		})
 	})
 }
 
 func TestGenOptionImage(t *testing.T) {
 	t.Run("Validate", func(t *testing.T) {
 		t.Run("valid", func(t *testing.T) {
-			o := &GenOptionImage{Width: 100, Height: 200}
+			o := &GenOptionImage{Width: 100, Height: 200, Count: 2}
 			if err := o.Validate(); err != nil {
 				t.Errorf("Validate() got unexpected error: %v", err)
 			}
@@ -500,6 +771,11 @@ func TestGenOptionImage(t *testing.T) {
 					in:     GenOptionImage{Width: -1},
 					errMsg: "field Width: must be non-negative",
 				},
+				{
+					name:   "Invalid Count",
+					in:     GenOptionImage{Count: -1},
+					errMsg: "field Count: must be non-negative",
+				},
 			}
 			for _, tt := range tests {
 				t.Run(tt.name, func(t *testing.T) {
@@ -518,14 +794,69 @@ func TestGenOptionVideo(t *testing.T) {
 		if err := o.Validate(); err != nil {
 			t.Errorf("Validate() got unexpected error: %v", err)
 		}
+		o = &GenOptionVideo{Duration: 8 * time.Second, Resolution: "1080p", FPS: 24, GenerateAudio: new(true), NegativePrompt: "rain", LastFrame: Doc{Filename: "end.png", Src: strings.NewReader("png")}}
+		if err := o.Validate(); err != nil {
+			t.Errorf("Validate() got unexpected error: %v", err)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			in     GenOptionVideo
+			errMsg string
+		}{
+			{"Duration", GenOptionVideo{Duration: -1}, "field Duration: must be non-negative"},
+			{"FPS", GenOptionVideo{FPS: -1}, "field FPS: must be non-negative"},
+			{"Resolution", GenOptionVideo{Resolution: "hd"}, "field Resolution: invalid value \"hd\", expected a value like \"720p\""},
+			{"Resolution_p", GenOptionVideo{Resolution: "p"}, "field Resolution: invalid value \"p\", expected a value like \"720p\""},
+			{"LastFrame", GenOptionVideo{LastFrame: Doc{Src: strings.NewReader("png")}}, "field LastFrame: field Filename is required with Src when not implementing Name()"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tt.in.Validate(); err == nil || err.Error() != tt.errMsg {
+					t.Fatalf("error mismatch\nwant %q\ngot  %q", tt.errMsg, err)
+				}
+			})
+		}
+	})
+}
+
+func TestGenOptionTimeout(t *testing.T) {
+	t.Run("Validate", func(t *testing.T) {
+		for _, o := range []GenOptionTimeout{{Request: time.Second}, {Total: time.Minute}, {Idle: time.Second}, {Request: time.Second, Total: time.Minute}} {
+			if err := o.Validate(); err != nil {
+				t.Errorf("Validate(%+v) got unexpected error: %v", o, err)
+			}
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			in     GenOptionTimeout
+			errMsg string
+		}{
+			{"empty", GenOptionTimeout{}, "one of Request, Total or Idle is required"},
+			{"Request", GenOptionTimeout{Request: -1}, "field Request: must be non-negative"},
+			{"Total", GenOptionTimeout{Total: -1}, "field Total: must be non-negative"},
+			{"Idle", GenOptionTimeout{Idle: -1}, "field Idle: must be non-negative"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tt.in.Validate(); err == nil || err.Error() != tt.errMsg {
+					t.Fatalf("error mismatch\nwant %q\ngot  %q", tt.errMsg, err)
+				}
+			})
+		}
 	})
 }
 
 func TestValidateReflectedToJSON(t *testing.T) {
 	type testStruct struct{}
 	t.Run("valid", func(t *testing.T) {
-		if err := validateReflectedToJSON(&testStruct{}); err != nil {
-			t.Fatalf("unexpected error: %v", err)
+		for _, v := range []any{&testStruct{}, &[]testStruct{}, &map[string]int{}, new(int), new(string), new(bool)} {
+			if err := validateReflectedToJSON(v); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 		}
 	})
 	t.Run("error", func(t *testing.T) {
@@ -549,6 +880,16 @@ func TestValidateReflectedToJSON(t *testing.T) {
 				in:     123,
 				errMsg: "must be a pointer to a struct, got int",
 			},
+			{
+				name:   "map with int keys",
+				in:     &map[int]string{},
+				errMsg: "map keys must be strings, got *map[int]string",
+			},
+			{
+				name:   "func pointer",
+				in:     new(func()),
+				errMsg: "must be a pointer to a struct, slice, map or primitive type, got *func()",
+			},
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,91 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Image input token estimation.
+
+package genai
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register the GIF decoder for image.DecodeConfig.
+	_ "image/jpeg" // Register the JPEG decoder for image.DecodeConfig.
+	_ "image/png"  // Register the PNG decoder for image.DecodeConfig.
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/maruel/genai/internal"
+)
+
+// ProviderImageTokens is optionally implemented by providers that can estimate the number of input tokens an
+// image consumes, before sending it.
+//
+// Each provider resizes and splits images differently: OpenAI counts 512px tiles, Anthropic counts pixels
+// and Gemini counts 768px crops. The estimation is for the current model.
+type ProviderImageTokens interface {
+	// ImageTokens returns the estimated number of input tokens for an image of width x height pixels.
+	ImageTokens(width, height int) int64
+}
+
+// EstimateImageTokens returns the estimated number of input tokens consumed by the images in msgs.
+//
+// p, or one of the providers it wraps, must implement ProviderImageTokens. PNG, JPEG and GIF images are
+// supported. Images referenced by URL can't be measured and return an error.
+func EstimateImageTokens(p Provider, msgs Messages) (int64, error) {
+	var e ProviderImageTokens
+	for p != nil {
+		if e2, ok := p.(ProviderImageTokens); ok {
+			e = e2
+			break
+		}
+		u, ok := p.(ProviderUnwrap)
+		if !ok {
+			break
+		}
+		p = u.Unwrap()
+	}
+	if e == nil {
+		return 0, errors.New("provider doesn't support image tokens estimation")
+	}
+	total := int64(0)
+	for i := range msgs {
+		for j := range msgs[i].Requests {
+			d := &msgs[i].Requests[j].Doc
+			name := d.GetFilename()
+			if name == "" {
+				name = path.Base(d.URL)
+			}
+			if d.IsZero() || !strings.HasPrefix(internal.MimeByExt(filepath.Ext(name)), "image/") {
+				continue
+			}
+			w, h, err := imageSize(d, name)
+			if err != nil {
+				return 0, fmt.Errorf("message %d, request %d: %w", i, j, err)
+			}
+			total += e.ImageTokens(w, h)
+		}
+	}
+	return total, nil
+}
+
+// imageSize decodes the image header and rewinds d.Src.
+func imageSize(d *Doc, name string) (int, int, error) {
+	if d.Src == nil {
+		return 0, 0, fmt.Errorf("can't determine the size of image %q", name)
+	}
+	if _, err := d.Src.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, fmt.Errorf("failed to seek data at beginning: %w", err)
+	}
+	cfg, _, err := image.DecodeConfig(d.Src)
+	if _, err2 := d.Src.Seek(0, io.SeekStart); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decode image %q: %w", name, err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
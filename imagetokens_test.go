@@ -0,0 +1,91 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for image input token estimation.
+
+package genai
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+type fakeImageTokens struct {
+	Provider
+}
+
+func (fakeImageTokens) ImageTokens(width, height int) int64 {
+	return int64(width + height)
+}
+
+type fakeUnwrap struct {
+	Provider
+}
+
+func (f *fakeUnwrap) Unwrap() Provider {
+	return f.Provider
+}
+
+func TestEstimateImageTokens(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 30, 20))); err != nil {
+		t.Fatal(err)
+	}
+	img := buf.Bytes()
+	t.Run("ok", func(t *testing.T) {
+		msgs := Messages{
+			NewTextMessage("describe"),
+			{Requests: []Request{
+				{Doc: Doc{Filename: "a.png", Src: bytes.NewReader(img)}},
+				{Text: "and"},
+				{Doc: Doc{Filename: "b.png", Src: bytes.NewReader(img)}},
+				{Doc: Doc{Filename: "c.txt", Src: strings.NewReader("ignored")}},
+			}},
+		}
+		got, err := EstimateImageTokens(&fakeUnwrap{Provider: fakeImageTokens{}}, msgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 100 {
+			t.Fatalf("got %d, want 100", got)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			p    Provider
+			msgs Messages
+			want string
+		}{
+			{
+				name: "unsupported_provider",
+				p:    &fakeUnwrap{},
+				want: "provider doesn't support image tokens estimation",
+			},
+			{
+				name: "url",
+				p:    fakeImageTokens{},
+				msgs: Messages{{Requests: []Request{{Doc: Doc{URL: "https://example.com/a.png"}}}}},
+				want: "message 0, request 0: can't determine the size of image \"a.png\"",
+			},
+			{
+				name: "corrupted",
+				p:    fakeImageTokens{},
+				msgs: Messages{{Requests: []Request{{Doc: Doc{Filename: "a.png", Src: bytes.NewReader(img[:10])}}}}},
+				want: "message 0, request 0: failed to decode image \"a.png\": unexpected EOF",
+			},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := EstimateImageTokens(tc.p, tc.msgs)
+				if err == nil || err.Error() != tc.want {
+					t.Fatalf("got %v, want %q", err, tc.want)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package bearer authenticates HTTP requests with OAuth2 access tokens.
+package bearer
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Token is an OAuth2 access token.
+type Token struct {
+	AccessToken string
+	// Expiry is when the token expires. The zero value means it never expires.
+	Expiry time.Time
+}
+
+// Transport sets the Authorization header from a token source, caching the token until it is about to
+// expire.
+type Transport struct {
+	Source    func(ctx context.Context) (Token, error)
+	Transport http.RoundTripper
+
+	mu  sync.Mutex
+	tok Token
+}
+
+// RoundTrip implements http.RoundTripper.
+func (b *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tok, err := b.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+tok)
+	return b.Transport.RoundTrip(req)
+}
+
+// Unwrap returns the wrapped transport.
+func (b *Transport) Unwrap() http.RoundTripper {
+	return b.Transport
+}
+
+// Token returns a valid access token, refreshing it when needed.
+func (b *Transport) Token(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Refresh a minute early to account for clock skew and request latency.
+	if b.tok.AccessToken == "" || (!b.tok.Expiry.IsZero() && time.Until(b.tok.Expiry) < time.Minute) {
+		t, err := b.Source(ctx)
+		if err != nil {
+			return "", err
+		}
+		b.tok = t
+	}
+	return b.tok.AccessToken, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package eventstream decodes the binary AWS event stream encoding "application/vnd.amazon.eventstream".
+//
+// See https://docs.aws.amazon.com/transcribe/latest/dg/streaming-setting-up.html#streaming-event-stream
+// for the framing.
+package eventstream
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// ReadMessage reads one event stream message. Only string headers are returned, the other header types are
+// skipped.
+func ReadMessage(r io.Reader) (map[string]string, []byte, error) {
+	var prelude [12]byte
+	if _, err := io.ReadFull(r, prelude[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, nil, errors.New("truncated event stream message")
+		}
+		return nil, nil, err
+	}
+	total := binary.BigEndian.Uint32(prelude[0:4])
+	hdrLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, nil, errors.New("event stream prelude checksum mismatch")
+	}
+	// A message is limited to 16 MiB.
+	if total < 16 || total > 16<<20 || hdrLen > total-16 {
+		return nil, nil, fmt.Errorf("invalid event stream message length %d", total)
+	}
+	rest := make([]byte, total-12)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, nil, fmt.Errorf("truncated event stream message: %w", err)
+	}
+	h := crc32.NewIEEE()
+	_, _ = h.Write(prelude[:])
+	_, _ = h.Write(rest[:len(rest)-4])
+	if h.Sum32() != binary.BigEndian.Uint32(rest[len(rest)-4:]) {
+		return nil, nil, errors.New("event stream message checksum mismatch")
+	}
+	hdrs, err := parseHeaders(rest[:hdrLen])
+	if err != nil {
+		return nil, nil, err
+	}
+	return hdrs, rest[hdrLen : len(rest)-4], nil
+}
+
+func parseHeaders(b []byte) (map[string]string, error) {
+	out := map[string]string{}
+	for len(b) != 0 {
+		n := int(b[0])
+		if len(b) < 1+n+1 {
+			return nil, errors.New("truncated event stream header")
+		}
+		name := string(b[1 : 1+n])
+		typ := b[1+n]
+		b = b[2+n:]
+		var size int
+		switch typ {
+		case 0, 1: // true, false
+		case 2: // byte
+			size = 1
+		case 3: // short
+			size = 2
+		case 4: // integer
+			size = 4
+		case 5, 8: // long, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // byte array, string
+			if len(b) < 2 {
+				return nil, errors.New("truncated event stream header")
+			}
+			l := int(binary.BigEndian.Uint16(b))
+			if len(b) < 2+l {
+				return nil, errors.New("truncated event stream header")
+			}
+			if typ == 7 {
+				out[name] = string(b[2 : 2+l])
+			}
+			size = 2 + l
+		default:
+			return nil, fmt.Errorf("unknown event stream header type %d", typ)
+		}
+		if len(b) < size {
+			return nil, errors.New("truncated event stream header")
+		}
+		b = b[size:]
+	}
+	return out, nil
+}
@@ -23,11 +23,6 @@ import (
 //go:generate go run regen_readme.go ..
 //go:generate go run regen_scoreboards.go ..
 
-// BeLenient is used by all clients to enable or disable httpjson.Client.Lenient.
-//
-// It is true by default. Tests must manually set it to false.
-var BeLenient = true
-
 // Validatable is an interface to an object that can be validated.
 type Validatable interface {
 	// Validate ensures the object is valid.
@@ -50,15 +45,15 @@ func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
 	return context.WithValue(ctx, contextKey{}, logger)
 }
 
-// UnmarshalJSON is like json.Unmarshal but respects BeLenient.
+// UnmarshalJSON is like json.Unmarshal but respects lenient.
 //
-// When strict (!BeLenient), it uses DisallowUnknownFields and provides
+// When strict (!lenient), it uses DisallowUnknownFields and provides
 // detailed error messages about extra keys via DecodeJSON.
-func UnmarshalJSON(data []byte, out any) error {
+func UnmarshalJSON(data []byte, out any, lenient bool) error {
 	r := bytes.NewReader(data)
 	d := json.NewDecoder(r)
 	var r2 io.ReadSeeker
-	if !BeLenient {
+	if !lenient {
 		d.DisallowUnknownFields()
 		r2 = r
 	}
@@ -125,6 +120,30 @@ func MimeByExt(ext string) string {
 	}
 }
 
+// ExtByMime returns the canonical file extension, including the leading dot, for a mime type.
+//
+// It returns an empty string if the mime type is unknown.
+func ExtByMime(mimeType string) string {
+	switch mimeType {
+	case "image/jpeg":
+		// mime.ExtensionsByType returns ".jfif" first.
+		return ".jpg"
+	case "image/png", "image/gif", "image/webp", "video/mp4", "video/webm", "audio/wav", "audio/flac", "audio/aac", "application/pdf":
+		return "." + mimeType[strings.IndexByte(mimeType, '/')+1:]
+	case "audio/mpeg":
+		return ".mp3"
+	case "text/plain":
+		return ".txt"
+	case "text/markdown":
+		return ".md"
+	default:
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) != 0 {
+			return exts[0]
+		}
+		return ""
+	}
+}
+
 // BadError is a bad error that must stop the smoke test.
 type BadError struct {
 	Err error
@@ -364,4 +364,15 @@ func (tw *WriterToLog) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// RoundTripperFunc implements http.RoundTripper with a function.
+//
+// It is used to fake a server in tests that assert the request sent, when recording a cassette isn't
+// practical.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
 var superVerbose = flag.Bool("superv", false, "super verbose; enables internaltest.Log() to log more")
@@ -44,6 +44,7 @@ require_all cerebras CEREBRAS_API_KEY
 require_all cloudflare CLOUDFLARE_ACCOUNT_ID CLOUDFLARE_API_KEY
 require_all cohere COHERE_API_KEY
 require_all deepseek DEEPSEEK_API_KEY
+require_all fireworks FIREWORKS_API_KEY
 require_all gemini GEMINI_API_KEY
 require_all github GITHUB_TOKEN
 require_all groq GROQ_API_KEY
@@ -26,10 +26,22 @@ import (
 // If decoding into T fails, it tries to decode into er, which the error code path. If this succeeds, the
 // error is returned and the iterator is stopped.
 //
+// A panic in a custom json.Unmarshaler is returned as an *internal.BadError, since the stream is usually
+// processed in a goroutine where the caller cannot recover it.
+//
 // https://developer.mozilla.org/en-US/docs/Web/API/Server-sent%5Fevents/Using%5Fserver-sent%5Fevents
 func Process[T any](body io.Reader, er error, lenient bool) (iter.Seq[T], func() error) {
 	var finalErr error
 	it := func(yield func(T) bool) {
+		yielding := false
+		defer func() {
+			// Do not swallow panics from the caller's loop body.
+			if !yielding {
+				if v := recover(); v != nil {
+					finalErr = &internal.BadError{Err: fmt.Errorf("sse: panic while decoding server response: %v", v)}
+				}
+			}
+		}()
 		for r := bufio.NewReader(body); ; {
 			line, err := r.ReadBytes('\n')
 			if line = bytes.TrimSpace(line); errors.Is(err, io.EOF) {
@@ -46,7 +58,8 @@ func Process[T any](body io.Reader, er error, lenient bool) (iter.Seq[T], func()
 
 			switch {
 			case bytes.HasPrefix(line, dataPrefix):
-				suffix := line[len(dataPrefix):]
+				// The space after the colon is optional.
+				suffix := bytes.TrimPrefix(line[len(dataPrefix):], []byte(" "))
 				if bytes.Equal(suffix, done) {
 					return
 				}
@@ -61,7 +74,10 @@ func Process[T any](body io.Reader, er error, lenient bool) (iter.Seq[T], func()
 				if _, err = internal.DecodeJSON(d, &msg, r2); err == nil {
 					// It may have succeeded but not decoded anything.
 					if v := reflect.ValueOf(&msg); !reflect.DeepEqual(&msg, reflect.Zero(v.Type()).Interface()) {
-						if !yield(msg) {
+						yielding = true
+						ok := yield(msg)
+						yielding = false
+						if !ok {
 							return
 						}
 						continue
@@ -111,7 +127,7 @@ func Process[T any](body io.Reader, er error, lenient bool) (iter.Seq[T], func()
 }
 
 var (
-	dataPrefix           = []byte("data: ")
+	dataPrefix           = []byte("data:")
 	eventPrefix          = []byte("event:")
 	done                 = []byte("[DONE]")
 	keepAlive            = []byte(": keep-alive")
@@ -7,9 +7,12 @@
 package sse
 
 import (
+	"bytes"
 	"errors"
 	"strings"
 	"testing"
+
+	"github.com/maruel/genai/internal"
 )
 
 type testResponse struct {
@@ -44,6 +47,11 @@ func TestProcess(t *testing.T) {
 				input: "event: message\n\ndata: {\"text\":\"message\"}\n\n",
 				want:  []testResponse{{Text: "message"}},
 			},
+			{
+				name:  "no space after data",
+				input: "data:{\"text\":\"message\"}\n\ndata:[DONE]\n\n",
+				want:  []testResponse{{Text: "message"}},
+			},
 		}
 
 		for _, tt := range tests {
@@ -99,6 +107,20 @@ func TestProcess(t *testing.T) {
 		}
 	})
 
+	t.Run("Panic", func(t *testing.T) {
+		it, finish := Process[panicResponse](strings.NewReader("data: {}\n\n"), nil, false)
+		for range it {
+			t.Fatal("unexpected message")
+		}
+		err := finish()
+		if _, ok := errors.AsType[*internal.BadError](err); !ok {
+			t.Fatalf("expected *internal.BadError, got %T: %v", err, err)
+		}
+		if want := "sse: panic while decoding server response: boom"; err.Error() != want {
+			t.Fatalf("unexpected error\ngot:  %q\nwant: %q", err, want)
+		}
+	})
+
 	t.Run("ReaderError", func(t *testing.T) {
 		// Test with a reader that returns an error
 		errorReader := &errorReaderMock{err: errors.New("read error")}
@@ -113,6 +135,46 @@ func TestProcess(t *testing.T) {
 	})
 }
 
+func FuzzProcess(f *testing.F) {
+	for _, s := range []string{
+		"data: {\"text\":\"a\"}\n\ndata: [DONE]\n\n",
+		"event: message\ndata:{\"text\":\"a\"}\n\n",
+		": keep-alive\n\n:\n\ndata: {\"error\":\"bad\"}\n\n",
+		"data: {\"text\":null}\n",
+		"data: [1,2]\n",
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		for _, lenient := range []bool{false, true} {
+			it, finish := Process[testResponse](bytes.NewReader(b), &testError{}, lenient)
+			for range it {
+			}
+			if err := finish(); err != nil {
+				if _, ok := errors.AsType[*internal.BadError](err); !ok {
+					if _, ok := errors.AsType[*testError](err); !ok {
+						t.Fatalf("unexpected error type %T: %v", err, err)
+					}
+				}
+			}
+		}
+	})
+}
+
+type testError struct {
+	Error_ string `json:"error"`
+}
+
+func (e *testError) Error() string {
+	return e.Error_
+}
+
+type panicResponse struct{}
+
+func (p *panicResponse) UnmarshalJSON(b []byte) error {
+	panic("boom")
+}
+
 // Mock implementation of io.Reader that returns an error.
 type errorReaderMock struct {
 	err error
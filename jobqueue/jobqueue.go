@@ -0,0 +1,297 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package jobqueue tracks asynchronous jobs submitted via genai.Provider.GenAsync.
+//
+// The state is persisted to a JSON file after each change, so that polling resumes after a process restart
+// and batch workflows survive crashes.
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/maruel/httpjson"
+
+	"github.com/maruel/genai"
+)
+
+// Status is the state of a tracked job.
+type Status string
+
+const (
+	// StatusPending means the job was submitted and is not completed yet.
+	StatusPending Status = "pending"
+	// StatusCompleted means the result was retrieved and OnDone was called.
+	StatusCompleted Status = "completed"
+	// StatusFailed means the job failed and OnDone was called with the error.
+	StatusFailed Status = "failed"
+)
+
+// Validate implements genai.Validatable.
+func (s Status) Validate() error {
+	switch s {
+	case StatusPending, StatusCompleted, StatusFailed:
+		return nil
+	default:
+		return fmt.Errorf("invalid status %q", string(s))
+	}
+}
+
+// Job is a tracked asynchronous job.
+type Job struct {
+	// Provider is the key in Queue.Providers of the provider that runs the job.
+	Provider string `json:"provider"`
+	// ID is the job ID returned by GenAsync.
+	ID genai.Job `json:"id"`
+	// Submitted is when the job was submitted.
+	Submitted time.Time `json:"submitted"`
+	// Status is the current state of the job.
+	Status Status `json:"status"`
+	// Completed is when the job completed or failed.
+	Completed time.Time `json:"completed,omitzero"`
+	// Error is the error message when Status is StatusFailed.
+	Error string `json:"error,omitzero"`
+	// Metadata is user provided data to associate with the job, e.g. the destination of the result.
+	Metadata map[string]string `json:"metadata,omitzero"`
+}
+
+// Validate implements genai.Validatable.
+func (j *Job) Validate() error {
+	if j.Provider == "" {
+		return errors.New("field Provider is required")
+	}
+	if j.ID == "" {
+		return errors.New("field ID is required")
+	}
+	return j.Status.Validate()
+}
+
+// Queue tracks GenAsync jobs and polls them until completion.
+//
+// It is safe for concurrent use.
+type Queue struct {
+	// Providers maps the Job.Provider key to the provider to poll. The key is generally Provider.Name() but can
+	// be anything, for example to use multiple models of the same provider.
+	Providers map[string]genai.Provider
+	// PollInterval is the delay between polls in Run. Defaults to 30 seconds when 0.
+	PollInterval time.Duration
+	// OnDone is called when a job completed or failed. res is only valid when err is nil.
+	//
+	// The job is marked as done and persisted only after OnDone returns, so OnDone may be called again for the
+	// same job if the process crashed while it was running.
+	OnDone func(ctx context.Context, j *Job, res genai.Result, err error)
+
+	path string
+	mu   sync.Mutex
+	jobs []Job
+}
+
+// Open loads the queue state from path, creating it on first save when it doesn't exist.
+func Open(path string, providers map[string]genai.Provider) (*Queue, error) {
+	q := &Queue{Providers: providers, path: path}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &q.jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	for i := range q.jobs {
+		if err := q.jobs[i].Validate(); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: job %d: %w", path, i, err)
+		}
+	}
+	return q, nil
+}
+
+// Submit calls GenAsync on the provider and tracks the resulting job.
+func (q *Queue) Submit(ctx context.Context, provider string, msgs genai.Messages, metadata map[string]string, opts ...genai.GenOption) (Job, error) {
+	p := q.Providers[provider]
+	if p == nil {
+		return Job{}, fmt.Errorf("unknown provider %q", provider)
+	}
+	id, err := p.GenAsync(ctx, msgs, opts...)
+	if err != nil {
+		return Job{}, err
+	}
+	j := Job{Provider: provider, ID: id, Submitted: time.Now().UTC(), Status: StatusPending, Metadata: metadata}
+	return j, q.add(j)
+}
+
+// Add tracks a job that was submitted separately.
+func (q *Queue) Add(provider string, id genai.Job, metadata map[string]string) error {
+	return q.add(Job{Provider: provider, ID: id, Submitted: time.Now().UTC(), Status: StatusPending, Metadata: metadata})
+}
+
+func (q *Queue) add(j Job) error {
+	if err := j.Validate(); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if slices.ContainsFunc(q.jobs, func(o Job) bool { return o.Provider == j.Provider && o.ID == j.ID }) {
+		return fmt.Errorf("job %s is already tracked", j.ID)
+	}
+	q.jobs = append(q.jobs, j)
+	return q.saveLocked()
+}
+
+// Jobs returns a snapshot of the tracked jobs.
+func (q *Queue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.jobs)
+}
+
+// Pending returns the number of pending jobs.
+func (q *Queue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for i := range q.jobs {
+		if q.jobs[i].Status == StatusPending {
+			n++
+		}
+	}
+	return n
+}
+
+// Remove stops tracking a job, whatever its status.
+func (q *Queue) Remove(provider string, id genai.Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := slices.IndexFunc(q.jobs, func(o Job) bool { return o.Provider == provider && o.ID == id })
+	if i == -1 {
+		return fmt.Errorf("job %s is not tracked", id)
+	}
+	q.jobs = slices.Delete(q.jobs, i, i+1)
+	return q.saveLocked()
+}
+
+// Poll checks all the pending jobs once.
+//
+// Transient errors, like network errors, rate limits and server errors, leave the job pending. Other errors
+// mark the job as failed.
+func (q *Queue) Poll(ctx context.Context) error {
+	var errs []error
+	for _, j := range q.Jobs() {
+		if j.Status != StatusPending {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p := q.Providers[j.Provider]
+		if p == nil {
+			errs = append(errs, fmt.Errorf("job %s: unknown provider %q", j.ID, j.Provider))
+			continue
+		}
+		res, err := p.PokeResult(ctx, j.ID)
+		if err == nil && res.Usage.FinishReason == genai.Pending {
+			continue
+		}
+		if err != nil && (ctx.Err() != nil || isTransient(err)) {
+			errs = append(errs, fmt.Errorf("job %s: %w", j.ID, err))
+			continue
+		}
+		if q.OnDone != nil {
+			q.OnDone(ctx, &j, res, err)
+		}
+		if err := q.finish(&j, err); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run polls the pending jobs every PollInterval until there is no more pending jobs or the context is
+// canceled.
+//
+// Errors from Poll are not fatal; they are returned joined when Run exits.
+func (q *Queue) Run(ctx context.Context) error {
+	interval := q.PollInterval
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+	var errs []error
+	for {
+		if err := q.Poll(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		if q.Pending() == 0 {
+			return errors.Join(errs...)
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (q *Queue) finish(j *Job, err error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := slices.IndexFunc(q.jobs, func(o Job) bool { return o.Provider == j.Provider && o.ID == j.ID })
+	if i == -1 {
+		// Removed concurrently.
+		return nil
+	}
+	q.jobs[i].Completed = time.Now().UTC()
+	if err != nil {
+		q.jobs[i].Status = StatusFailed
+		q.jobs[i].Error = err.Error()
+	} else {
+		q.jobs[i].Status = StatusCompleted
+	}
+	return q.saveLocked()
+}
+
+// saveLocked atomically writes the state to disk.
+func (q *Queue) saveLocked() error {
+	if q.path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(q.jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), q.path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+// isTransient returns true for network errors, rate limits and server errors.
+func isTransient(err error) bool {
+	if e, ok := errors.AsType[*httpjson.Error](err); ok {
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	}
+	_, ok := errors.AsType[*url.Error](err)
+	return ok
+}
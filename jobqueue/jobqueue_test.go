@@ -0,0 +1,173 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the persistent job queue.
+
+package jobqueue_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/maruel/httpjson"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/jobqueue"
+	"github.com/maruel/genai/scoreboard"
+)
+
+func TestQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	p := &mockProvider{}
+	q, err := jobqueue.Open(path, map[string]genai.Provider{"mock": p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j1, err := q.Submit(t.Context(), "mock", genai.Messages{genai.NewTextMessage("a")}, map[string]string{"out": "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j2, err := q.Submit(t.Context(), "mock", genai.Messages{genai.NewTextMessage("b")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Add("mock", j1.ID, nil); err == nil {
+		t.Fatal("expected duplicate error")
+	}
+
+	// Simulate a restart.
+	q, err = jobqueue.Open(path, map[string]genai.Provider{"mock": p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := q.Pending(); n != 2 {
+		t.Fatalf("got %d pending jobs, want 2", n)
+	}
+	var done []string
+	q.OnDone = func(ctx context.Context, j *jobqueue.Job, res genai.Result, err error) {
+		if err != nil {
+			done = append(done, string(j.ID)+":"+err.Error())
+		} else {
+			done = append(done, string(j.ID)+":"+res.String()+":"+j.Metadata["out"])
+		}
+	}
+
+	// Nothing is ready yet.
+	if err := q.Poll(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 0 {
+		t.Fatalf("unexpected completion: %v", done)
+	}
+
+	// First one is rate limited, second one completes.
+	p.results = map[genai.Job]pokeResult{
+		j1.ID: {err: &httpjson.Error{StatusCode: 429}},
+		j2.ID: {text: "B"},
+	}
+	if err := q.Poll(t.Context()); err == nil {
+		t.Fatal("expected transient error")
+	}
+	if want := []string{"job2:B:"}; !slices.Equal(done, want) {
+		t.Fatalf("got %v, want %v", done, want)
+	}
+
+	// First one fails for good.
+	p.results[j1.ID] = pokeResult{err: errors.New("bad request")}
+	q.PollInterval = 1
+	if err := q.Run(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"job2:B:", "job1:bad request"}; !slices.Equal(done, want) {
+		t.Fatalf("got %v, want %v", done, want)
+	}
+
+	// The final state is persisted.
+	q, err = jobqueue.Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs := q.Jobs()
+	if len(jobs) != 2 || jobs[0].Status != jobqueue.StatusFailed || jobs[0].Error != "bad request" || jobs[1].Status != jobqueue.StatusCompleted {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+	if jobs[0].Metadata["out"] != "a.txt" {
+		t.Fatalf("unexpected metadata: %+v", jobs[0].Metadata)
+	}
+	if err := q.Remove("mock", j1.ID); err != nil {
+		t.Fatal(err)
+	}
+	if jobs := q.Jobs(); len(jobs) != 1 || jobs[0].ID != j2.ID {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+}
+
+func TestQueue_errors(t *testing.T) {
+	q := &jobqueue.Queue{Providers: map[string]genai.Provider{}}
+	if _, err := q.Submit(t.Context(), "unknown", genai.Messages{genai.NewTextMessage("a")}, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := q.Add("", "job", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := q.Add("unknown", "job", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Poll(t.Context()); err == nil || err.Error() != `job job: unknown provider "unknown"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+type pokeResult struct {
+	text string
+	err  error
+}
+
+type mockProvider struct {
+	base.NotImplemented
+	n       int
+	results map[genai.Job]pokeResult
+}
+
+func (m *mockProvider) Name() string {
+	return "mock"
+}
+
+func (m *mockProvider) ModelID() string {
+	return "llm"
+}
+
+func (m *mockProvider) OutputModalities() genai.Modalities {
+	return nil
+}
+
+func (m *mockProvider) HTTPClient() *http.Client {
+	return nil
+}
+
+func (m *mockProvider) Scoreboard() scoreboard.Score {
+	return scoreboard.Score{}
+}
+
+func (m *mockProvider) GenAsync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Job, error) {
+	m.n++
+	return genai.Job("job" + strconv.Itoa(m.n)), nil
+}
+
+func (m *mockProvider) PokeResult(ctx context.Context, id genai.Job) (genai.Result, error) {
+	r, ok := m.results[id]
+	if !ok {
+		return genai.Result{Usage: genai.Usage{FinishReason: genai.Pending}}, nil
+	}
+	if r.err != nil {
+		return genai.Result{}, r.err
+	}
+	return genai.Result{Message: genai.Message{Replies: []genai.Reply{{Text: r.text}}}}, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Bidirectional realtime session support.
+
+package genai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+
+	"github.com/maruel/genai/internal"
+)
+
+// ProviderLive is optionally implemented by providers that support bidirectional realtime sessions over a
+// persistent connection, e.g. OpenAI's Realtime API and Gemini's Live API.
+type ProviderLive interface {
+	Provider
+	// Live opens a new bidirectional session with the provider's model.
+	//
+	// Supported options are provider specific. *GenOptionText's SystemPrompt is generally supported.
+	//
+	// The session must be closed when done.
+	Live(ctx context.Context, opts ...GenOption) (LiveSession, error)
+}
+
+// LiveSession is a bidirectional realtime session.
+//
+// Input is sent incrementally with Send while the output is received as a stream of LiveEvent via Recv,
+// interleaving audio frames and text.
+//
+// Audio is raw 16 bits signed little endian mono PCM. The sample rate is provider specific.
+type LiveSession interface {
+	io.Closer
+	// Send sends input to the model. It is safe to call concurrently with Recv.
+	Send(ctx context.Context, in *LiveInput) error
+	// Recv returns the events sent by the server until the session is closed or the context is canceled.
+	//
+	// The returned function must be called after the iteration to retrieve the error, if any.
+	Recv(ctx context.Context) (iter.Seq[LiveEvent], func() error)
+}
+
+// LiveInput is one input sent to a LiveSession.
+//
+// Exactly one of the fields must be set.
+type LiveInput struct {
+	// Audio is a frame of raw PCM audio.
+	Audio []byte
+	// Text is a user text message. It triggers a response.
+	Text string
+	// EndOfTurn marks the end of the user's audio input and requests a response. It is not needed when the
+	// provider does server-side voice activity detection.
+	EndOfTurn bool
+
+	_ struct{}
+}
+
+// Validate implements Validatable.
+func (l *LiveInput) Validate() error {
+	n := 0
+	if len(l.Audio) != 0 {
+		n++
+	}
+	if l.Text != "" {
+		n++
+	}
+	if l.EndOfTurn {
+		n++
+	}
+	if n != 1 {
+		return errors.New("exactly one of Audio, Text or EndOfTurn must be set")
+	}
+	return nil
+}
+
+// LiveEvent is an event received from a LiveSession.
+type LiveEvent struct {
+	// Reply is a fragment of the model's turn, if any.
+	//
+	// Audio frames are returned as Reply.Doc with a ".pcm" filename. Text, including the transcript of the audio
+	// output when available, is returned as Reply.Text.
+	Reply Reply
+	// TurnComplete is set when the model finished its turn. Usage is set along with it when the provider
+	// reports it.
+	TurnComplete bool
+	// Interrupted is set when the user started speaking while the model was generating, and the model stopped
+	// its turn.
+	Interrupted bool
+	// Usage is the token usage of the turn.
+	Usage Usage
+
+	_ struct{}
+}
+
+var _ internal.Validatable = (*LiveInput)(nil)
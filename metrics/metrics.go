@@ -0,0 +1,231 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package metrics exports genai usage as Prometheus metrics.
+//
+// Wrap a provider with Provider to record each request into a Collector, then serve the Collector over HTTP
+// to be scraped. The Prometheus text exposition format is written directly, so it works alongside
+// prometheus/client_golang without depending on it: mount it on its own path or scrape it as a separate
+// target.
+//
+// Exported metrics, all labeled with provider and model:
+//
+//   - genai_requests_total: requests completed, including failed ones.
+//   - genai_errors_total: requests that returned an error.
+//   - genai_request_duration_seconds: summary of the request latency, including streaming.
+//   - genai_tokens_total: tokens used, with the additional label type (input, input_cached, reasoning,
+//     output).
+//   - genai_cost_total: cost as computed by Collector.Cost, when set.
+//   - genai_ratelimit_remaining: the last remaining rate limit reported by the provider, with the additional
+//     labels type (requests, tokens) and period (minute, day, month, other).
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maruel/genai"
+)
+
+// Collector accumulates metrics. The zero value is ready to use and it is safe for concurrent use.
+type Collector struct {
+	// Cost optionally returns the cost of a request. When nil, genai_cost_total is not exported.
+	Cost func(provider, model string, u *genai.Usage) float64
+
+	mu     sync.Mutex
+	series map[seriesKey]*series
+}
+
+// Record records the result of a request.
+func (c *Collector) Record(provider, model string, res *genai.Result, err error, d time.Duration) {
+	var cost float64
+	if c.Cost != nil {
+		cost = c.Cost(provider, model, &res.Usage)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.series == nil {
+		c.series = map[seriesKey]*series{}
+	}
+	k := seriesKey{provider: provider, model: model}
+	s := c.series[k]
+	if s == nil {
+		s = &series{limits: map[limitKey]int64{}}
+		c.series[k] = s
+	}
+	s.requests++
+	if err != nil {
+		s.errors++
+	}
+	s.duration += d.Seconds()
+	u := &res.Usage
+	s.tokens[0] += u.InputTokens
+	s.tokens[1] += u.InputCachedTokens
+	s.tokens[2] += u.ReasoningTokens
+	s.tokens[3] += u.OutputTokens
+	s.cost += cost
+	for _, l := range u.Limits {
+		s.limits[limitKey{l.Type, l.Period}] = l.Remaining
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = c.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := slices.SortedFunc(maps.Keys(c.series), func(a, b seriesKey) int {
+		if x := strings.Compare(a.provider, b.provider); x != 0 {
+			return x
+		}
+		return strings.Compare(a.model, b.model)
+	})
+	b := &strings.Builder{}
+	header := func(name, typ, help string) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	header("genai_requests_total", "counter", "Requests completed, including failed ones.")
+	for _, k := range keys {
+		fmt.Fprintf(b, "genai_requests_total{%s} %d\n", k.labels(), c.series[k].requests)
+	}
+	header("genai_errors_total", "counter", "Requests that returned an error.")
+	for _, k := range keys {
+		fmt.Fprintf(b, "genai_errors_total{%s} %d\n", k.labels(), c.series[k].errors)
+	}
+	header("genai_request_duration_seconds", "summary", "Request latency.")
+	for _, k := range keys {
+		fmt.Fprintf(b, "genai_request_duration_seconds_sum{%s} %s\n", k.labels(), formatFloat(c.series[k].duration))
+		fmt.Fprintf(b, "genai_request_duration_seconds_count{%s} %d\n", k.labels(), c.series[k].requests)
+	}
+	header("genai_tokens_total", "counter", "Tokens used.")
+	for _, k := range keys {
+		for i, t := range tokenTypes {
+			fmt.Fprintf(b, "genai_tokens_total{%s,type=%q} %d\n", k.labels(), t, c.series[k].tokens[i])
+		}
+	}
+	if c.Cost != nil {
+		header("genai_cost_total", "counter", "Cost of the requests.")
+		for _, k := range keys {
+			fmt.Fprintf(b, "genai_cost_total{%s} %s\n", k.labels(), formatFloat(c.series[k].cost))
+		}
+	}
+	header("genai_ratelimit_remaining", "gauge", "Last remaining rate limit reported by the provider.")
+	for _, k := range keys {
+		s := c.series[k]
+		for _, l := range slices.SortedFunc(maps.Keys(s.limits), func(a, b limitKey) int {
+			if a.typ != b.typ {
+				return int(a.typ - b.typ)
+			}
+			return int(a.period - b.period)
+		}) {
+			fmt.Fprintf(b, "genai_ratelimit_remaining{%s,type=%q,period=%q} %d\n", k.labels(), l.typeName(), l.periodName(), s.limits[l])
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Provider wraps a genai.Provider and records each request into Collector.
+type Provider struct {
+	genai.Provider
+	Collector *Collector
+}
+
+// GenSync implements genai.Provider.
+func (p *Provider) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	start := time.Now()
+	res, err := p.Provider.GenSync(ctx, msgs, opts...)
+	p.Collector.Record(p.Provider.Name(), p.Provider.ModelID(), &res, err, time.Since(start))
+	return res, err
+}
+
+// GenStream implements genai.Provider.
+func (p *Provider) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	start := time.Now()
+	fragments, finish := p.Provider.GenStream(ctx, msgs, opts...)
+	return fragments, func() (genai.Result, error) {
+		res, err := finish()
+		p.Collector.Record(p.Provider.Name(), p.Provider.ModelID(), &res, err, time.Since(start))
+		return res, err
+	}
+}
+
+func (p *Provider) Unwrap() genai.Provider {
+	return p.Provider
+}
+
+//
+
+var tokenTypes = [...]string{"input", "input_cached", "reasoning", "output"}
+
+type seriesKey struct {
+	provider string
+	model    string
+}
+
+func (k seriesKey) labels() string {
+	return "provider=" + escape(k.provider) + ",model=" + escape(k.model)
+}
+
+type series struct {
+	requests int64
+	errors   int64
+	duration float64
+	tokens   [len(tokenTypes)]int64
+	cost     float64
+	limits   map[limitKey]int64
+}
+
+type limitKey struct {
+	typ    genai.RateLimitType
+	period genai.RateLimitPeriod
+}
+
+func (l limitKey) typeName() string {
+	switch l.typ {
+	case genai.Requests:
+		return "requests"
+	case genai.Tokens:
+		return "tokens"
+	default:
+		return "other"
+	}
+}
+
+func (l limitKey) periodName() string {
+	switch l.period {
+	case genai.PerMinute:
+		return "minute"
+	case genai.PerDay:
+		return "day"
+	case genai.PerMonth:
+		return "month"
+	default:
+		return "other"
+	}
+}
+
+// escape quotes a label value per the exposition format, which only escapes backslash, double quote and
+// line feed.
+func escape(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the Prometheus metrics exporter.
+
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/metrics"
+	"github.com/maruel/genai/scoreboard"
+)
+
+func TestProvider(t *testing.T) {
+	c := &metrics.Collector{Cost: func(provider, model string, u *genai.Usage) float64 {
+		return float64(u.InputTokens+u.OutputTokens) / 1000
+	}}
+	usage := genai.Usage{
+		InputTokens:       100,
+		InputCachedTokens: 20,
+		ReasoningTokens:   5,
+		OutputTokens:      400,
+		Limits: []genai.RateLimit{
+			{Type: genai.Tokens, Period: genai.PerMinute, Limit: 1000, Remaining: 500},
+			{Type: genai.Requests, Period: genai.PerDay, Limit: 100, Remaining: 99},
+		},
+	}
+	m := &mockProvider{res: genai.Result{Usage: usage}}
+	p := &metrics.Provider{Provider: m, Collector: c}
+	if _, err := p.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}); err != nil {
+		t.Fatal(err)
+	}
+	m.res.Usage.Limits[0].Remaining = 100
+	fragments, finish := p.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("hi")})
+	for range fragments {
+	}
+	if _, err := finish(); err != nil {
+		t.Fatal(err)
+	}
+	m.err = errors.New("boom")
+	m.res = genai.Result{}
+	if _, err := p.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}); err == nil {
+		t.Fatal("expected error")
+	}
+	if p.Unwrap() != m {
+		t.Fatal("unexpected Unwrap")
+	}
+
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	const labels = `provider="mock",model="llm \"sota\""`
+	want := []string{
+		"# TYPE genai_requests_total counter",
+		"genai_requests_total{" + labels + "} 3",
+		"genai_errors_total{" + labels + "} 1",
+		"genai_request_duration_seconds_count{" + labels + "} 3",
+		"genai_tokens_total{" + labels + `,type="input"} 200`,
+		"genai_tokens_total{" + labels + `,type="input_cached"} 40`,
+		"genai_tokens_total{" + labels + `,type="reasoning"} 10`,
+		"genai_tokens_total{" + labels + `,type="output"} 800`,
+		"genai_cost_total{" + labels + "} 1",
+		"# TYPE genai_ratelimit_remaining gauge",
+		"genai_ratelimit_remaining{" + labels + `,type="requests",period="day"} 99`,
+		"genai_ratelimit_remaining{" + labels + `,type="tokens",period="minute"} 100`,
+	}
+	got := strings.Split(string(b), "\n")
+	for _, w := range want {
+		if !slices.Contains(got, w) {
+			t.Errorf("missing line %q in:\n%s", w, b)
+		}
+	}
+}
+
+func TestCollector_noCost(t *testing.T) {
+	c := &metrics.Collector{}
+	c.Record("a", "b", &genai.Result{}, nil, 0)
+	b := &strings.Builder{}
+	if _, err := c.WriteTo(b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "genai_cost_total") {
+		t.Fatalf("unexpected cost metric:\n%s", b)
+	}
+	if !strings.Contains(b.String(), "genai_requests_total{provider=\"a\",model=\"b\"} 1\n") {
+		t.Fatalf("missing request metric:\n%s", b)
+	}
+}
+
+type mockProvider struct {
+	base.NotImplemented
+	res genai.Result
+	err error
+}
+
+func (m *mockProvider) Name() string                       { return "mock" }
+func (m *mockProvider) ModelID() string                    { return `llm "sota"` }
+func (m *mockProvider) OutputModalities() genai.Modalities { return nil }
+func (m *mockProvider) HTTPClient() *http.Client           { return nil }
+func (m *mockProvider) Scoreboard() scoreboard.Score       { return scoreboard.Score{} }
+
+func (m *mockProvider) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	return m.res, m.err
+}
+
+func (m *mockProvider) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	return func(yield func(genai.Reply) bool) {}, func() (genai.Result, error) { return m.res, m.err }
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Model metadata reported by the providers, with a static catalog fallback.
+
+package genai
+
+import (
+	"time"
+)
+
+// ModelModalities is optionally implemented by a Model that reports the modalities it supports.
+type ModelModalities interface {
+	// GetModalities returns the input and output modalities, or nil if unknown.
+	GetModalities() (in, out Modalities)
+}
+
+// ModelCreated is optionally implemented by a Model that reports when it was released.
+type ModelCreated interface {
+	// GetCreated returns the release time, or the zero time if unknown.
+	GetCreated() time.Time
+}
+
+// ModelDeprecated is optionally implemented by a Model that reports whether the provider announced its
+// retirement.
+type ModelDeprecated interface {
+	GetDeprecated() bool
+}
+
+// ModelPricing is optionally implemented by a Model that reports its price.
+type ModelPricing interface {
+	GetPricing() ModelPrice
+}
+
+// ModelPrice is the price of a model in USD per million tokens. 0 if unknown.
+type ModelPrice struct {
+	Input  float64
+	Output float64
+}
+
+// ModelInfo is the metadata of a model.
+//
+// Use GetModelInfo to retrieve it.
+type ModelInfo struct {
+	// InputContext is the number of tokens the model can process as input. 0 if unknown.
+	InputContext int64
+	// OutputContext is the maximum number of tokens the model can generate in a single reply. 0 if unknown.
+	OutputContext int64
+	// In are the supported input modalities. nil if unknown.
+	In Modalities
+	// Out are the supported output modalities. nil if unknown.
+	Out Modalities
+	// Created is when the model was released. Zero if unknown.
+	Created time.Time
+	// Deprecated is true if the provider announced the model's retirement.
+	Deprecated bool
+	// Price is the price of the model.
+	Price ModelPrice
+
+	_ struct{}
+}
+
+// GetModelInfo returns the metadata of a model as returned by Provider.ListModels.
+//
+// The values reported by the provider take precedence. The values the provider doesn't report are filled
+// from a static catalog of well known models, when the model is listed there.
+func GetModelInfo(m Model) ModelInfo {
+	out := ModelInfo{InputContext: m.Context()}
+	if o, ok := m.(ModelOutputTokens); ok {
+		out.OutputContext = o.MaxOutputTokens()
+	}
+	if o, ok := m.(ModelModalities); ok {
+		out.In, out.Out = o.GetModalities()
+	}
+	if o, ok := m.(ModelCreated); ok {
+		out.Created = o.GetCreated()
+	}
+	if o, ok := m.(ModelDeprecated); ok {
+		out.Deprecated = o.GetDeprecated()
+	}
+	if o, ok := m.(ModelPricing); ok {
+		out.Price = o.GetPricing()
+	}
+	c, ok := modelCatalog[m.GetID()]
+	if !ok {
+		return out
+	}
+	if out.InputContext == 0 {
+		out.InputContext = c.InputContext
+	}
+	if out.OutputContext == 0 {
+		out.OutputContext = c.OutputContext
+	}
+	if len(out.In) == 0 {
+		out.In = c.In
+	}
+	if len(out.Out) == 0 {
+		out.Out = c.Out
+	}
+	if out.Created.IsZero() {
+		out.Created = c.Created
+	}
+	out.Deprecated = out.Deprecated || c.Deprecated
+	if out.Price == (ModelPrice{}) {
+		out.Price = c.Price
+	}
+	return out
+}
+
+func catalogDate(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// modelCatalog is the metadata of well known models, keyed by model ID.
+//
+// It is used for the providers that return little to no metadata in their list of models. Keep it sorted.
+var modelCatalog = map[string]ModelInfo{
+	"claude-haiku-4-5": {
+		InputContext: 200000, OutputContext: 64000,
+		In: Modalities{ModalityDocument, ModalityImage, ModalityText}, Out: Modalities{ModalityText},
+		Created: catalogDate(2025, time.October, 15), Price: ModelPrice{Input: 1, Output: 5},
+	},
+	"claude-opus-4-1": {
+		InputContext: 200000, OutputContext: 32000,
+		In: Modalities{ModalityDocument, ModalityImage, ModalityText}, Out: Modalities{ModalityText},
+		Created: catalogDate(2025, time.August, 5), Price: ModelPrice{Input: 15, Output: 75},
+	},
+	"claude-sonnet-4-5": {
+		InputContext: 200000, OutputContext: 64000,
+		In: Modalities{ModalityDocument, ModalityImage, ModalityText}, Out: Modalities{ModalityText},
+		Created: catalogDate(2025, time.September, 29), Price: ModelPrice{Input: 3, Output: 15},
+	},
+	"deepseek-chat": {
+		InputContext: 128000, OutputContext: 8000,
+		In: Modalities{ModalityText}, Out: Modalities{ModalityText},
+	},
+	"deepseek-reasoner": {
+		InputContext: 128000, OutputContext: 64000,
+		In: Modalities{ModalityText}, Out: Modalities{ModalityText},
+	},
+	"gemini-2.5-flash": {
+		InputContext: 1048576, OutputContext: 65536,
+		In:  Modalities{ModalityAudio, ModalityDocument, ModalityImage, ModalityText, ModalityVideo},
+		Out: Modalities{ModalityText},
+	},
+	"gemini-2.5-pro": {
+		InputContext: 1048576, OutputContext: 65536,
+		In:  Modalities{ModalityAudio, ModalityDocument, ModalityImage, ModalityText, ModalityVideo},
+		Out: Modalities{ModalityText},
+	},
+	"gpt-4.1": {
+		InputContext: 1047576, OutputContext: 32768,
+		In: Modalities{ModalityImage, ModalityText}, Out: Modalities{ModalityText},
+		Created: catalogDate(2025, time.April, 14), Price: ModelPrice{Input: 2, Output: 8},
+	},
+	"gpt-4o": {
+		InputContext: 128000, OutputContext: 16384,
+		In: Modalities{ModalityImage, ModalityText}, Out: Modalities{ModalityText},
+		Created: catalogDate(2024, time.May, 13), Price: ModelPrice{Input: 2.5, Output: 10},
+	},
+	"gpt-4o-mini": {
+		InputContext: 128000, OutputContext: 16384,
+		In: Modalities{ModalityImage, ModalityText}, Out: Modalities{ModalityText},
+		Created: catalogDate(2024, time.July, 18), Price: ModelPrice{Input: 0.15, Output: 0.6},
+	},
+	"o3": {
+		InputContext: 200000, OutputContext: 100000,
+		In: Modalities{ModalityImage, ModalityText}, Out: Modalities{ModalityText},
+		Created: catalogDate(2025, time.April, 16), Price: ModelPrice{Input: 2, Output: 8},
+	},
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the model metadata.
+
+package genai
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type infoModel struct {
+	id string
+}
+
+func (m infoModel) GetID() string                       { return m.id }
+func (m infoModel) String() string                      { return m.id }
+func (m infoModel) Context() int64                      { return 1000 }
+func (m infoModel) GetDeprecated() bool                 { return true }
+func (m infoModel) GetPricing() ModelPrice              { return ModelPrice{Input: 1, Output: 2} }
+func (m infoModel) GetCreated() time.Time               { return time.Time{} }
+func (m infoModel) MaxOutputTokens() int64              { return 0 }
+func (m infoModel) GetModalities() (in, out Modalities) { return nil, nil }
+
+func TestGetModelInfo(t *testing.T) {
+	t.Run("provider", func(t *testing.T) {
+		got := GetModelInfo(infoModel{id: "unknown"})
+		want := ModelInfo{InputContext: 1000, Deprecated: true, Price: ModelPrice{Input: 1, Output: 2}}
+		if diff := cmp.Diff(want, got, cmp.AllowUnexported(ModelInfo{})); diff != "" {
+			t.Fatalf("(-want +got):\n%s", diff)
+		}
+	})
+	t.Run("catalog", func(t *testing.T) {
+		// The values reported by the provider take precedence over the catalog.
+		got := GetModelInfo(infoModel{id: "gpt-4o"})
+		want := modelCatalog["gpt-4o"]
+		want.InputContext = 1000
+		want.Deprecated = true
+		want.Price = ModelPrice{Input: 1, Output: 2}
+		if diff := cmp.Diff(want, got, cmp.AllowUnexported(ModelInfo{})); diff != "" {
+			t.Fatalf("(-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestModelCatalog(t *testing.T) {
+	for id, info := range modelCatalog {
+		if id == "" || strings.TrimSpace(id) != id {
+			t.Errorf("invalid ID %q", id)
+		}
+		if info.InputContext <= 0 || info.OutputContext <= 0 {
+			t.Errorf("%s: missing context", id)
+		}
+		for _, m := range []Modalities{info.In, info.Out} {
+			if len(m) == 0 || !slices.IsSorted(m) {
+				t.Errorf("%s: modalities must be set and sorted: %v", id, m)
+			}
+			if err := m.Validate(); err != nil {
+				t.Errorf("%s: %v", id, err)
+			}
+		}
+	}
+}
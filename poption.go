@@ -9,8 +9,12 @@ package genai
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"time"
 )
 
 // ProviderOption is an option for provider constructors.
@@ -103,6 +107,45 @@ func (p ProviderOptionPreloadedModels) Validate() error {
 	return nil
 }
 
+// ProviderOptionModelSelector replaces the provider's heuristics to automatically select the text model when
+// ProviderOptionModel is ModelCheap, ModelGood or ModelSOTA.
+//
+// It is called once by the provider constructor with the requested preference and the models returned by
+// ListModels, and must return the ID of the model to use. The heuristics built into the providers match
+// model names and break when a provider renames its models; use this option to pin your own policy, for
+// example with GetModelInfo to compare the price or the context window.
+//
+// Providers that don't select the model from ListModels, e.g. the ones with a hardcoded list, reject this
+// option.
+type ProviderOptionModelSelector func(ctx context.Context, preference ProviderOptionModel, models []Model) (string, error)
+
+// Validate implements Validatable.
+func (p ProviderOptionModelSelector) Validate() error {
+	if p == nil {
+		return errors.New("ProviderOptionModelSelector cannot be nil")
+	}
+	return nil
+}
+
+// ProviderOptionModelPin is the path to a JSON file freezing the automatic model selection.
+//
+// When ProviderOptionModel is ModelCheap, ModelGood or ModelSOTA, the provider uses the model recorded in the
+// file for this provider and preference. When none is recorded, the provider selects a model as usual and
+// records it. This way a production deployment doesn't silently change models between restarts; delete the
+// entry or the file to select again. The file can be shared by multiple providers.
+//
+// Use ProviderModelSelection to retrieve the decision. Providers that don't select the model from ListModels
+// reject this option.
+type ProviderOptionModelPin string
+
+// Validate implements Validatable.
+func (p ProviderOptionModelPin) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionModelPin cannot be empty")
+	}
+	return nil
+}
+
 // ProviderOptionTransportWrapper wraps the HTTP transport used by the provider.
 //
 // This is useful for adding middleware like logging, tracing, or HTTP recording for tests.
@@ -116,6 +159,112 @@ func (p ProviderOptionTransportWrapper) Validate() error {
 	return nil
 }
 
+// ProviderOptionHTTP tunes the HTTP connection pool used by the provider.
+//
+// When specified, the client uses its own connection pool instead of the one shared by
+// base.DefaultTransport, with the same retry policy. Zero values keep the net/http defaults. To tune the
+// shared pool instead, modify base.DefaultHTTPTransport before creating the clients.
+//
+// It is ignored when ProviderOptionTransportWrapper doesn't use the transport passed to it.
+type ProviderOptionHTTP struct {
+	// MaxIdleConnsPerHost is the number of idle connections kept per host. Raise it when sending many
+	// concurrent requests to the same provider, otherwise connections are closed and reopened.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits the total number of connections per host. Zero means no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept in the pool.
+	IdleConnTimeout time.Duration
+	// HTTP2SendPingTimeout is the idle time after which a ping is sent on an HTTP/2 connection to check its
+	// health. Zero disables the health check.
+	HTTP2SendPingTimeout time.Duration
+	// HTTP2PingTimeout is how long to wait for a ping response before closing the HTTP/2 connection.
+	HTTP2PingTimeout time.Duration
+	// TLSSessionCacheSize is the number of TLS sessions cached for resumption, which saves a round trip when
+	// reconnecting. Zero disables resumption.
+	TLSSessionCacheSize int
+}
+
+// Validate implements Validatable.
+func (p ProviderOptionHTTP) Validate() error {
+	if p.MaxIdleConnsPerHost < 0 {
+		return errors.New("field MaxIdleConnsPerHost: must be non-negative")
+	}
+	if p.MaxConnsPerHost < 0 {
+		return errors.New("field MaxConnsPerHost: must be non-negative")
+	}
+	if p.IdleConnTimeout < 0 {
+		return errors.New("field IdleConnTimeout: must be non-negative")
+	}
+	if p.HTTP2SendPingTimeout < 0 {
+		return errors.New("field HTTP2SendPingTimeout: must be non-negative")
+	}
+	if p.HTTP2PingTimeout < 0 {
+		return errors.New("field HTTP2PingTimeout: must be non-negative")
+	}
+	if p.TLSSessionCacheSize < 0 {
+		return errors.New("field TLSSessionCacheSize: must be non-negative")
+	}
+	return nil
+}
+
+// ProviderOptionProxyURL is the URL of the proxy used to reach the provider, e.g. "http://proxy:3128" or
+// "socks5://proxy:1080".
+//
+// When unspecified, each provider looks at its own environment variable "<PROVIDER>_PROXY", e.g.
+// ANTHROPIC_PROXY, then falls back to the HTTPS_PROXY and NO_PROXY environment variables like net/http.
+// This permits using a different egress proxy per provider.
+type ProviderOptionProxyURL string
+
+// Validate implements Validatable.
+func (p ProviderOptionProxyURL) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionProxyURL cannot be empty")
+	}
+	u, err := url.Parse(string(p))
+	if err != nil {
+		return fmt.Errorf("invalid ProviderOptionProxyURL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("invalid ProviderOptionProxyURL %q: unsupported scheme %q", p, u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid ProviderOptionProxyURL %q: missing host", p)
+	}
+	return nil
+}
+
+// ProviderOptionLogger enables logging of the HTTP requests, the model, the token usage, the retries and the
+// rate limits.
+//
+// Failures are logged at slog.LevelError.
+type ProviderOptionLogger struct {
+	Logger *slog.Logger
+	// Level is the level used for successful events. It defaults to slog.LevelInfo.
+	Level slog.Level
+}
+
+// Validate implements Validatable.
+func (p ProviderOptionLogger) Validate() error {
+	if p.Logger == nil {
+		return errors.New("ProviderOptionLogger.Logger cannot be nil")
+	}
+	return nil
+}
+
+// ProviderOptionStrict controls how strictly the client decodes the provider's responses.
+//
+// When true, unknown fields in the responses and unknown finish reasons are returned as errors. This is
+// useful in tests to detect API changes. When false, they are ignored so the client doesn't break when the
+// provider adds new fields. It only affects this client, and defaults to lenient.
+type ProviderOptionStrict bool
+
+// Validate implements Validatable.
+func (p ProviderOptionStrict) Validate() error {
+	return nil
+}
+
 // Starter launches a subprocess and returns its stdin, stdout, a wait function, and any error.
 //
 // It is the subprocess equivalent of http.RoundTripper: the lowest-level
@@ -7,8 +7,11 @@
 package genai
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 	"testing"
+	"time"
 )
 
 type mockModel struct {
@@ -120,6 +123,28 @@ func TestProviderOptionTransportWrapper(t *testing.T) {
 	})
 }
 
+func TestProviderOptionModelSelector(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		fn := ProviderOptionModelSelector(func(ctx context.Context, preference ProviderOptionModel, models []Model) (string, error) {
+			return "m", nil
+		})
+		if err := fn.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		if err := ProviderOptionModelSelector(nil).Validate(); err == nil || err.Error() != "ProviderOptionModelSelector cannot be nil" {
+			t.Fatalf("want %q, got %q", "ProviderOptionModelSelector cannot be nil", err)
+		}
+	})
+}
+
+func TestProviderOptionModelPin(t *testing.T) {
+	if err := ProviderOptionModelPin("").Validate(); err == nil || err.Error() != "ProviderOptionModelPin cannot be empty" {
+		t.Fatalf("want %q, got %q", "ProviderOptionModelPin cannot be empty", err)
+	}
+}
+
 func TestProviderOptionStarterWrapper(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		fn := ProviderOptionStarterWrapper(func(s Starter) Starter { return s })
@@ -134,6 +159,45 @@ func TestProviderOptionStarterWrapper(t *testing.T) {
 	})
 }
 
+func TestProviderOptionLogger(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		if err := (ProviderOptionLogger{Logger: slog.Default(), Level: slog.LevelDebug}).Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		if err := (ProviderOptionLogger{}).Validate(); err == nil || err.Error() != "ProviderOptionLogger.Logger cannot be nil" {
+			t.Fatalf("want %q, got %q", "ProviderOptionLogger.Logger cannot be nil", err)
+		}
+	})
+}
+
+func TestProviderOptionHTTP(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		if err := (ProviderOptionHTTP{MaxIdleConnsPerHost: 64, HTTP2SendPingTimeout: time.Second}).Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		if err := (ProviderOptionHTTP{HTTP2PingTimeout: -time.Second}).Validate(); err == nil || err.Error() != "field HTTP2PingTimeout: must be non-negative" {
+			t.Fatalf("want %q, got %q", "field HTTP2PingTimeout: must be non-negative", err)
+		}
+	})
+}
+
+func TestProviderOptionProxyURL(t *testing.T) {
+	for _, v := range []ProviderOptionProxyURL{"http://proxy:3128", "socks5://proxy:1080"} {
+		if err := v.Validate(); err != nil {
+			t.Errorf("%s: %v", v, err)
+		}
+	}
+	for _, v := range []ProviderOptionProxyURL{"", "proxy:3128", "ftp://proxy", "http://"} {
+		if err := v.Validate(); err == nil {
+			t.Errorf("%q: expected error", v)
+		}
+	}
+}
+
 func TestProviderOptionInterface(t *testing.T) {
 	// Verify all types implement ProviderOption.
 	opts := []ProviderOption{
@@ -144,6 +208,14 @@ func TestProviderOptionInterface(t *testing.T) {
 		ProviderOptionPreloadedModels{mockModel{id: "m"}},
 		ProviderOptionTransportWrapper(func(rt http.RoundTripper) http.RoundTripper { return rt }),
 		ProviderOptionStarterWrapper(func(s Starter) Starter { return s }),
+		ProviderOptionModelSelector(func(ctx context.Context, preference ProviderOptionModel, models []Model) (string, error) {
+			return "m", nil
+		}),
+		ProviderOptionLogger{Logger: slog.Default()},
+		ProviderOptionHTTP{MaxIdleConnsPerHost: 8},
+		ProviderOptionProxyURL("http://proxy:3128"),
+		ProviderOptionModelPin("models.json"),
+		ProviderOptionStrict(true),
 	}
 	for _, o := range opts {
 		if err := o.Validate(); err != nil {
@@ -113,12 +113,20 @@ func (p ProviderOptionBackend) Validate() error {
 // ProviderOptionBackend selects a named regional endpoint (e.g. BackendUS).
 // When set, the matching DASHSCOPE_API_KEY_<region> is tried first.
 // ProviderOptionRemote overrides all other endpoint selection with a full URL.
+//
+// The proxy defaults to the DASHSCOPE_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model, remote string
 	var backend ProviderOptionBackend
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -135,8 +143,20 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		case genai.ProviderOptionRemote:
 			remote = string(v)
 		case ProviderOptionBackend:
@@ -179,7 +199,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "DASHSCOPE_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -200,9 +220,12 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			GenSyncURL:      remote + "/chat/completions",
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
@@ -216,7 +239,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		switch model {
 		case "":
 		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
-			if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 				return nil, err
 			}
 			c.impl.OutputModalities = mod
@@ -239,13 +262,15 @@ func (c *Client) Name() string {
 // then picks the smallest, middle, or largest model within that family for cheap, good, or SOTA.
 // Only canonical base text models matching `qwen{V}-{N}b-a{M}b` or `qwen{V}-max` are considered.
 //
-// We may want to make this function overridable in the future by the client since this is going to break one
-// day or another.
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
 func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
 	mdls, err := c.ListModels(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	// modelRe matches only canonical base text models, capturing version and param count.
 	// Examples: "qwen3-30b-a3b", "qwen3.5-122b-a10b", "qwen3-max".
 	modelRe := regexp.MustCompile(`^qwen(\d+(?:\.\d+)?)-(?:(\d+)b-a\d+b|max)$`)
@@ -318,6 +343,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 func (c *Client) OutputModalities() genai.Modalities {
 	return c.impl.OutputModalities
@@ -391,7 +421,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.InputTokens = pkt.Usage.PromptTokens
 					u.InputCachedTokens = pkt.Usage.PromptTokensDetails.CachedTokens
 					u.OutputTokens = pkt.Usage.CompletionTokens
-					u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				switch role := pkt.Choices[0].Delta.Role; role {
 				case "assistant", "":
@@ -456,4 +489,7 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 		}
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+)
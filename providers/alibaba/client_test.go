@@ -15,7 +15,6 @@ import (
 	"testing"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/alibaba"
 	"github.com/maruel/genai/scoreboard"
@@ -52,6 +51,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return alibaba.New(t.Context(), opts...)
 }
 
@@ -157,6 +157,7 @@ func TestClient(t *testing.T) {
 					if fn != nil {
 						opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 					}
+					opts = append(opts, genai.ProviderOptionStrict(true))
 					c, err := alibaba.New(t.Context(), opts...)
 					if err != nil {
 						t.Fatal(err)
@@ -268,7 +269,3 @@ func TestGenOption(t *testing.T) {
 		}
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
@@ -72,6 +72,15 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			c.Temperature = v.Temperature
 			c.TopP = v.TopP
 			c.TopK = v.TopK
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			sp = v.SystemPrompt
 			if v.TopLogprobs > 0 {
 				unsupported = append(unsupported, "GenOption.TopLogprobs")
@@ -84,7 +93,11 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				errs = append(errs, errors.New("unsupported option DecodeAs"))
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				switch v.Force {
 				case genai.ToolCallAny:
 					c.ToolChoice = "auto"
@@ -93,8 +106,8 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				case genai.ToolCallNone:
 					c.ToolChoice = "none"
 				}
-				c.Tools = make([]Tool, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
 					c.Tools[i].Type = "function"
 					c.Tools[i].Function.Name = t.Name
 					c.Tools[i].Function.Description = t.Description
@@ -109,6 +122,9 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.Search {
 				c.EnableSearch = true
 			}
+			if v.SearchOptions != nil {
+				unsupported = append(unsupported, "GenOptionWeb.SearchOptions")
+			}
 			if v.Fetch {
 				unsupported = append(unsupported, "GenOptionWeb.Fetch")
 			}
@@ -314,6 +330,7 @@ func (c *Content) FromRequest(in *genai.Request) error {
 		switch {
 		case (in.Doc.URL != "" && mimeType == "") || strings.HasPrefix(mimeType, "image/"):
 			c.Type = ContentImageURL
+			c.ImageURL.Detail = string(in.Doc.Detail)
 			if in.Doc.URL == "" {
 				c.ImageURL.URL = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
 			} else {
@@ -351,6 +368,7 @@ func (c *Content) FromReply(in *genai.Reply) error {
 		switch {
 		case (in.Doc.URL != "" && mimeType == "") || strings.HasPrefix(mimeType, "image/"):
 			c.Type = ContentImageURL
+			c.ImageURL.Detail = string(in.Doc.Detail)
 			if in.Doc.URL == "" {
 				c.ImageURL.URL = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
 			} else {
@@ -448,8 +466,9 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	if len(c.Choices) != 1 {
 		return out, fmt.Errorf("expected 1 choice, got %d", len(c.Choices))
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-	err := c.Choices[0].Message.To(&out.Message)
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
 	return out, err
 }
 
@@ -465,23 +484,22 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case "":
-		return ""
+		return "", nil
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishContentFilter:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -19,7 +19,7 @@ import (
 	"fmt"
 	"io"
 	"iter"
-	"mime/multipart"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -86,10 +86,19 @@ type GenOptionText struct {
 	ThinkingDisplay ThinkingDisplay
 	// MessagesToCache specify the number of messages to cache in the request.
 	//
-	// By default, the system prompt and tools will be cached.
-	//
 	// https://docs.anthropic.com/en/docs/build-with-claude/prompt-caching
 	MessagesToCache int
+	// CacheSystemPrompt adds a cache breakpoint on the system prompt, so a large system prompt repeated across
+	// requests is only processed once. Since tools are before the system prompt in the cache prefix, they are
+	// cached along.
+	//
+	// The prompt must be at least 1024 tokens (2048 for haiku models) to be cached.
+	CacheSystemPrompt bool
+	// CacheTools adds a cache breakpoint on the last tool definition.
+	CacheTools bool
+	// CacheTTL is the lifetime of the cache breakpoints added via MessagesToCache, CacheSystemPrompt and
+	// CacheTools. Defaults to 5 minutes.
+	CacheTTL CacheTTL
 	// Effort controls the quality/latency tradeoff. When empty, the API default is used.
 	//
 	// https://platform.claude.com/docs/en/api/messages#body-output-config
@@ -99,6 +108,11 @@ type GenOptionText struct {
 	//
 	// https://platform.claude.com/docs/en/api/messages/create
 	InferenceGeo string
+	// WebFetchCitations enables citations on the content fetched by the web_fetch server tool. It requires
+	// genai.GenOptionWeb.Fetch. The citations are attributed to the URL of the fetched page.
+	//
+	// https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/web-fetch-tool
+	WebFetchCitations bool
 }
 
 // Effort controls the amount of effort the model puts into its response.
@@ -141,7 +155,10 @@ func (o *GenOptionText) Validate() error {
 	default:
 		return fmt.Errorf("invalid Effort %q", o.Effort)
 	}
-	return nil
+	if o.MessagesToCache < 0 {
+		return errors.New("MessagesToCache must be non-negative")
+	}
+	return o.CacheTTL.Validate()
 }
 
 // Client implements genai.Provider.
@@ -151,6 +168,8 @@ type Client struct {
 	// multipartBoundary overrides the multipart boundary for deterministic HTTP
 	// recordings. Leave empty for production use.
 	multipartBoundary string
+	// platform is set when the requests are sent to a cloud platform instead of the Anthropic API.
+	platform platform
 }
 
 // New creates a new client to talk to the Anthropic platform API.
@@ -161,11 +180,31 @@ type Client struct {
 //
 // To use multiple models, create multiple clients.
 // Use one of the model from https://docs.anthropic.com/en/docs/about-claude/models/all-models
+//
+// The proxy defaults to the ANTHROPIC_PROXY environment variable, see genai.ProviderOptionProxyURL.
+//
+// # Cloud platforms
+//
+// To use Claude on Google Cloud Vertex AI or AWS Bedrock, set genai.ProviderOptionRemote to the Vertex AI
+// location, e.g. "https://us-east5-aiplatform.googleapis.com/v1/projects/<project>/locations/us-east5", or
+// to the Bedrock runtime, e.g. "https://bedrock-runtime.us-east-1.amazonaws.com". Authenticate with
+// ProviderOptionAuth, or with ProviderOptionAPIKey holding an OAuth2 access token for Vertex AI or a Bedrock
+// API key. The model ID is the one from the platform, e.g. "claude-sonnet-4-5@20250929" on Vertex AI or
+// "us.anthropic.claude-sonnet-4-5-20250929-v1:0" on Bedrock.
+//
+// Only GenSync and GenStream are supported on cloud platforms.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
-	var apiKey, model, multipartBoundary string
+	var apiKey, model, multipartBoundary, remote string
+	var auth ProviderOptionAuth
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -182,17 +221,51 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		case ProviderOptionMultipartBoundary:
 			multipartBoundary = string(v)
+		case genai.ProviderOptionRemote:
+			remote = string(v)
+		case ProviderOptionAuth:
+			auth = v
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
 	}
 	const apiKeyURL = "https://console.anthropic.com/settings/keys"
 	var err error
-	if apiKey == "" {
+	var plat platform
+	var platURL string
+	if remote != "" {
+		if plat, platURL, err = parseRemote(remote); err != nil {
+			return nil, err
+		}
+		if auth == nil {
+			if apiKey == "" {
+				return nil, errors.New("ProviderOptionRemote requires ProviderOptionAuth or ProviderOptionAPIKey")
+			}
+			token := apiKey
+			auth = func(r *http.Request) error {
+				r.Header.Set("Authorization", "Bearer "+token)
+				return nil
+			}
+		}
+	} else if auth != nil {
+		return nil, errors.New("ProviderOptionAuth requires ProviderOptionRemote")
+	} else if apiKey == "" {
 		if apiKey = os.Getenv("ANTHROPIC_API_KEY"); apiKey == "" {
 			err = &base.ErrAPIKeyRequired{EnvVar: "ANTHROPIC_API_KEY", URL: apiKeyURL}
 		}
@@ -201,35 +274,46 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "ANTHROPIC_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
+	t = &roundtrippers.RequestID{Transport: t}
+	if plat != "" {
+		t = &platformTransport{platform: plat, baseURL: platURL, auth: auth, transport: t}
+	}
 	// Anthropic allows Opaque fields for thinking signatures
 	c := &Client{
 		multipartBoundary: multipartBoundary,
+		platform:          plat,
 		impl: base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]{
 			GenSyncURL:      "https://api.anthropic.com/v1/messages",
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProcessHeaders:  processHeaders,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    http.Header{"x-api-key": {apiKey}, "anthropic-version": {"2023-06-01"}},
-						Transport: &betaHeader{transport: &roundtrippers.RequestID{Transport: t}},
+						Transport: &betaHeader{transport: t},
 					},
 				},
 			},
 		},
 	}
+	if plat == platformBedrock {
+		c.impl.DecodeStream = decodeBedrockStream
+	}
 	if err == nil {
 		switch model {
 		case "":
 		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
-			if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 				return nil, err
 			}
 			c.impl.OutputModalities = mod
@@ -243,13 +327,15 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 
 // selectBestTextModel selects the most recent model based on the preference (cheap, good, or SOTA).
 //
-// We may want to make this function overridable in the future by the client since this is going to break one
-// day or another.
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
 func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
 	mdls, err := c.ListModels(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	cheap := preference == string(genai.ModelCheap)
 	good := preference == string(genai.ModelGood) || preference == ""
 	selectedModel := ""
@@ -318,26 +404,12 @@ func (c *Client) GenAsyncRaw(ctx context.Context, b BatchRequest) (BatchResponse
 //
 // It retrieves the result for a job ID.
 func (c *Client) PokeResult(ctx context.Context, id genai.Job) (genai.Result, error) {
-	res := genai.Result{}
 	resp, err := c.PokeResultRaw(ctx, id)
 	if err != nil && resp.Result.Type == "not_found_error" {
-		res.Usage.FinishReason = genai.Pending
-		return res, nil
-	}
-	if resp.Result.Type == "errored" {
-		return res, fmt.Errorf("error %s: %s", resp.Result.Error.Error.Type, resp.Result.Error.Error.Message)
-	}
-	err = resp.To(&res.Message)
-	res.Usage.InputTokens = resp.Result.Message.Usage.InputTokens
-	res.Usage.InputCachedTokens = resp.Result.Message.Usage.CacheReadInputTokens
-	res.Usage.OutputTokens = resp.Result.Message.Usage.OutputTokens
-	res.Usage.TotalTokens = res.Usage.InputTokens + res.Usage.InputCachedTokens + res.Usage.OutputTokens
-	res.Usage.FinishReason = resp.Result.Message.StopReason.ToFinishReason()
-	res.Usage.ServiceTier = resp.Result.Message.Usage.ServiceTier
-	if err == nil {
-		err = res.Validate()
+		return genai.Result{Usage: genai.Usage{FinishReason: genai.Pending}}, nil
 	}
-	return res, err
+	res, err := resp.ToResult()
+	return res, c.impl.StrictErr(err)
 }
 
 // PokeResultRaw provides access to the raw API structure.
@@ -360,6 +432,86 @@ func (c *Client) PokeResultRaw(ctx context.Context, id genai.Job) (BatchQueryRes
 	return resp, err
 }
 
+// GenAsyncBatch implements genai.ProviderGenAsyncBatch.
+//
+// All the requests are submitted as a single message batch. The custom_id of each request is its index.
+func (c *Client) GenAsyncBatch(ctx context.Context, msgs []genai.Messages, opts ...genai.GenOption) (genai.Job, error) {
+	if err := c.impl.Validate(); err != nil {
+		return "", err
+	}
+	if len(msgs) == 0 {
+		return "", errors.New("at least one request is required")
+	}
+	c.ensureModelData(ctx)
+	b := BatchRequest{Requests: make([]BatchRequestItem, len(msgs))}
+	for i := range msgs {
+		if err := b.Requests[i].Init(msgs[i], c.impl.Model, opts...); err != nil {
+			return "", fmt.Errorf("request %d: %w", i, err)
+		}
+		b.Requests[i].CustomID = strconv.Itoa(i)
+	}
+	resp, err := c.GenAsyncRaw(ctx, b)
+	return genai.Job(resp.ID), err
+}
+
+// PokeBatchResults implements genai.ProviderGenAsyncBatch.
+func (c *Client) PokeBatchResults(ctx context.Context, id genai.Job) ([]genai.Result, error) {
+	b, err := c.GetBatch(ctx, string(id))
+	if err != nil {
+		return nil, err
+	}
+	if b.ProcessingStatus != "ended" {
+		return nil, nil
+	}
+	items, err := c.PokeBatchResultsRaw(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]genai.Result, len(items))
+	var errs []error
+	for i := range items {
+		j, err := strconv.Atoi(items[i].CustomID)
+		if err != nil || j < 0 || j >= len(out) {
+			return nil, fmt.Errorf("unexpected custom_id %q", items[i].CustomID)
+		}
+		out[j], err = items[i].ToResult()
+		if err = c.impl.StrictErr(err); err != nil {
+			errs = append(errs, fmt.Errorf("request %d: %w", j, err))
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// PokeBatchResultsRaw retrieves all the results of an ended message batch.
+//
+// Unlike PokeResultRaw, it supports batches with multiple requests. The results are in no particular order.
+func (c *Client) PokeBatchResultsRaw(ctx context.Context, id genai.Job) ([]BatchQueryResponse, error) {
+	u := "https://api.anthropic.com/v1/messages/batches/" + url.PathEscape(string(id)) + "/results"
+	resp, err := c.impl.JSONRequest(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.impl.DecodeError(u, resp)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// The response is in JSON Lines format, one result per line.
+	d := json.NewDecoder(resp.Body)
+	if !c.impl.Lenient {
+		d.DisallowUnknownFields()
+	}
+	var out []BatchQueryResponse
+	for {
+		var item BatchQueryResponse
+		if err := d.Decode(&item); err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return out, fmt.Errorf("failed to decode %s: %w", u, err)
+		}
+		out = append(out, item)
+	}
+}
+
 // Cancel implements genai.ProviderBatch.
 func (c *Client) Cancel(ctx context.Context, id genai.Job) error {
 	_, err := c.CancelRaw(ctx, id)
@@ -441,27 +593,107 @@ func (c *Client) fileDoJSON(ctx context.Context, method, u string, out any) erro
 	return c.impl.DecodeResponse(resp, u, out)
 }
 
-// FileUpload uploads a file to the Anthropic files API.
+// filesURL is the prefix of the Files API.
+const filesURL = "https://api.anthropic.com/v1/files/"
+
+// FileURL returns the URL to reference a file uploaded via the Files API in a genai.Doc.
 //
-// https://docs.anthropic.com/en/api/files
-func (c *Client) FileUpload(ctx context.Context, filename string, r io.Reader) (*FileMetadata, error) {
-	buf := bytes.Buffer{}
-	w := multipart.NewWriter(&buf)
-	if c.multipartBoundary != "" {
-		_ = w.SetBoundary(c.multipartBoundary)
+// Set genai.Doc.Filename along the URL so the file is sent as an image when relevant, e.g.
+// genai.Doc{Filename: "photo.jpg", URL: anthropic.FileURL(id)}.
+func FileURL(id string) string {
+	return filesURL + id
+}
+
+// CacheAddRequest implements genai.Provider.
+//
+// It uploads the single document found in msgs to the Files API and returns the file ID. Reference it in
+// subsequent requests with FileURL. Files do not expire so ttl is ignored. displayName overrides the
+// document's filename when set.
+//
+// https://docs.anthropic.com/en/docs/build-with-claude/files
+func (c *Client) CacheAddRequest(ctx context.Context, msgs genai.Messages, name, displayName string, ttl time.Duration, opts ...genai.GenOption) (string, error) {
+	if len(opts) != 0 {
+		unsupported := make([]string, len(opts))
+		for i, o := range opts {
+			unsupported[i] = internal.TypeName(o)
+		}
+		return "", &base.ErrNotSupported{Options: unsupported}
+	}
+	if err := msgs.Validate(); err != nil {
+		return "", err
 	}
-	part, err := w.CreateFormFile("file", filename)
+	var docs []*genai.Doc
+	for i := range msgs {
+		for j := range msgs[i].Requests {
+			if d := &msgs[i].Requests[j].Doc; !d.IsZero() {
+				docs = append(docs, d)
+			}
+		}
+	}
+	if len(docs) != 1 {
+		return "", fmt.Errorf("expected exactly one document to upload, got %d", len(docs))
+	}
+	d := docs[0]
+	if d.URL != "" {
+		return "", errors.New("document must be provided inline, not as a URL")
+	}
+	filename := displayName
+	if filename == "" {
+		filename = d.GetFilename()
+	}
+	if filename == "" {
+		return "", errors.New("a filename is required")
+	}
+	if _, err := d.Src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	f, err := c.FileUpload(ctx, filename, d.Src)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	if _, err = io.Copy(part, r); err != nil {
+	return f.ID, nil
+}
+
+// CacheList implements genai.Provider.
+//
+// It lists the files uploaded via the Files API.
+func (c *Client) CacheList(ctx context.Context) ([]genai.CacheEntry, error) {
+	l, err := c.FileList(ctx)
+	if err != nil {
 		return nil, err
 	}
-	if err := w.Close(); err != nil {
+	out := make([]genai.CacheEntry, len(l))
+	for i := range l {
+		out[i] = &l[i]
+	}
+	return out, nil
+}
+
+// CacheDelete implements genai.Provider.
+//
+// It deletes a file uploaded via the Files API.
+func (c *Client) CacheDelete(ctx context.Context, name string) error {
+	return c.FileDelete(ctx, name)
+}
+
+// FileUpload uploads a file to the Anthropic files API. The file is streamed, so it doesn't need to fit in memory.
+//
+// When r is not an io.Seeker, it can only be read once so the upload is not retried on failure.
+//
+// https://docs.anthropic.com/en/api/files
+func (c *Client) FileUpload(ctx context.Context, filename string, r io.Reader) (*FileMetadata, error) {
+	getBody, contentType, size, err := base.MultipartFile(c.multipartBoundary, nil, "file", filename, r)
+	if err != nil {
 		return nil, err
 	}
 	u := "https://api.anthropic.com/v1/files?beta=true"
-	resp, err := c.fileDo(ctx, "POST", u, &buf, w.FormDataContentType())
+	req, err := base.NewBodyRequest(ctx, http.MethodPost, u, getBody, size)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("anthropic-beta", "files-api-2025-04-14")
+	resp, err := c.impl.Client.Do(req)
 	if err != nil {
 		if resp != nil {
 			_ = resp.Body.Close()
@@ -544,6 +776,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -565,7 +802,7 @@ func (c *Client) HTTPClient() *http.Client {
 // GenSync implements genai.Provider.
 func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
 	c.ensureModelData(ctx)
-	return c.impl.GenSync(ctxWithBeta(ctx, opts), msgs, opts...)
+	return c.impl.GenSync(ctxWithBeta(ctx, msgs, opts), msgs, opts...)
 }
 
 // GenSyncRaw provides access to the raw API.
@@ -576,17 +813,43 @@ func (c *Client) GenSyncRaw(ctx context.Context, in *ChatRequest, out *ChatRespo
 // GenStream implements genai.Provider.
 func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
 	c.ensureModelData(ctx)
-	return c.impl.GenStream(ctxWithBeta(ctx, opts), msgs, opts...)
+	return c.impl.GenStream(ctxWithBeta(ctx, msgs, opts), msgs, opts...)
 }
 
-// ctxWithBeta adds the web-fetch beta header to the context if WebFetch is enabled.
-func ctxWithBeta(ctx context.Context, opts []genai.GenOption) context.Context {
+// ctxWithBeta adds the beta headers to the context if WebFetch or computer use is enabled or if a file
+// uploaded via the Files API is referenced.
+func ctxWithBeta(ctx context.Context, msgs genai.Messages, opts []genai.GenOption) context.Context {
+	var betas []string
 	for _, o := range opts {
-		if v, ok := o.(*genai.GenOptionWeb); ok && v.Fetch {
-			return context.WithValue(ctx, ctxBetaKey{}, "web-fetch-2025-09-10")
+		switch v := o.(type) {
+		case *genai.GenOptionWeb:
+			if v.Fetch {
+				betas = append(betas, "web-fetch-2025-09-10")
+			}
+		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				betas = append(betas, "computer-use-2025-01-24")
+			}
+		}
+	}
+	if referencesFile(msgs) {
+		betas = append(betas, "files-api-2025-04-14")
+	}
+	if len(betas) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxBetaKey{}, strings.Join(betas, ","))
+}
+
+func referencesFile(msgs genai.Messages) bool {
+	for i := range msgs {
+		for j := range msgs[i].Requests {
+			if fileIDFromURL(msgs[i].Requests[j].Doc.URL) != "" {
+				return true
+			}
 		}
 	}
-	return ctx
+	return false
 }
 
 // GenStreamRaw provides access to the raw API.
@@ -597,7 +860,7 @@ func (c *Client) GenStreamRaw(ctx context.Context, in *ChatRequest) (iter.Seq[Ch
 // ensureModelData ensures model capabilities are cached for request validation and defaults.
 // Falls back to ListModels for unknown models.
 func (c *Client) ensureModelData(ctx context.Context) {
-	if c.impl.Model == "" {
+	if c.impl.Model == "" || c.platform != "" {
 		return
 	}
 	if _, ok := getModelData(c.impl.Model); ok {
@@ -625,6 +888,9 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 		}
 		return c.impl.PreloadedModels, nil
 	}
+	if c.platform != "" {
+		return nil, &base.ErrNotSupported{Options: []string{"ListModels on " + string(c.platform)}}
+	}
 	// https://docs.anthropic.com/en/api/models-list
 	var resp ModelsResponse
 	if err := c.impl.DoRequest(ctx, "GET", "https://api.anthropic.com/v1/models?limit=1000", nil, &resp); err != nil {
@@ -684,7 +950,25 @@ func (c *Client) CountTokensRaw(ctx context.Context, in *CountTokensRequest) (*C
 func (c *Client) Capabilities() genai.ProviderCapabilities {
 	return genai.ProviderCapabilities{
 		GenAsync: true,
+		Caching:  true,
+	}
+}
+
+// ImageTokens implements genai.ProviderImageTokens.
+//
+// Images are scaled down so that the longest edge is at most 1568 pixels and the image uses at most about
+// 1600 tokens, then each 750 pixels count as a token.
+//
+// See https://docs.anthropic.com/en/docs/build-with-claude/vision#calculate-image-costs
+func (c *Client) ImageTokens(width, height int) int64 {
+	if width <= 0 || height <= 0 {
+		return 0
 	}
+	w, h := float64(width), float64(height)
+	if r := 1568 / max(w, h); r < 1 {
+		w, h = w*r, h*r
+	}
+	return min(int64(math.Ceil(w*h/750)), 1600)
 }
 
 // ProcessStream converts the raw packets from the streaming API into Reply fragments.
@@ -695,12 +979,14 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 	return func(yield func(genai.Reply) bool) {
 			// At the moment, only supported for server_tool_use / web_search.
 			pendingServerCall := ""
+			pendingServerID := ""
 			pendingJSON := ""
 			pendingToolCall := genai.ToolCall{}
+			fetched := webFetchURLs{}
 			for pkt := range chunks {
-				f := genai.Reply{}
+				// pkt.Index matters here, as the LLM may fill multiple content blocks simultaneously.
+				f := genai.Reply{Index: pkt.Index}
 				// See testdata/TestClient_Chat_thinking/ChatStream.yaml as a great example.
-				// TODO: pkt.Index matters here, as the LLM may fill multiple content blocks simultaneously.
 				switch pkt.Type {
 				case ChunkMessageStart:
 					switch pkt.Message.Role {
@@ -717,6 +1003,20 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.ServiceTier = pkt.Message.Usage.ServiceTier
 					continue
 				case ChunkContentBlockStart:
+					c := Content{
+						Type:       pkt.ContentBlock.Type,
+						ID:         pkt.ContentBlock.ID,
+						Name:       pkt.ContentBlock.Name,
+						Input:      pkt.ContentBlock.Input,
+						ToolUseID:  pkt.ContentBlock.ToolUseID,
+						Content:    pkt.ContentBlock.Content,
+						ServerName: pkt.ContentBlock.ServerName,
+					}
+					if sc := c.serverToolCall(); !sc.IsZero() {
+						if !yield(genai.Reply{Index: pkt.Index, ServerToolCall: sc}) {
+							return
+						}
+					}
 					switch pkt.ContentBlock.Type {
 					case ContentText:
 						f.Text = pkt.ContentBlock.Text
@@ -728,19 +1028,17 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 						pendingToolCall.Arguments = ""
 						// TODO: Is there anything to do with Input? pendingCall.Arguments = pkt.ContentBlock.Input
 					case ContentRedactedThinking:
-						f.Opaque = map[string]any{"redacted_thinking": pkt.ContentBlock.Signature}
+						f.Opaque = map[string]any{"redacted_thinking": pkt.ContentBlock.Data}
 					case ContentServerToolUse:
 						// Discard the data for now. It may be necessary in the future to keep in Opaque.
 						pendingServerCall = pkt.ContentBlock.Name
+						pendingServerID = pkt.ContentBlock.ID
 						switch pendingServerCall {
 						case "web_search", "web_fetch":
 							// Supported server tool calls.
 						default:
 							// Oops, more work to do!
-							if !internal.BeLenient {
-								finalErr = &internal.BadError{Err: fmt.Errorf("implement server tool call %q", pendingServerCall)}
-								return
-							}
+							finalErr = errors.Join(finalErr, &base.ErrUnhandled{Err: fmt.Errorf("implement server tool call %q", pendingServerCall)})
 						}
 					case ContentWebSearchToolResult:
 						f.Citation.Sources = make([]genai.CitationSource, len(pkt.ContentBlock.Content))
@@ -770,6 +1068,7 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 							"server_name": pkt.ContentBlock.ServerName,
 						}}
 					case ContentWebFetchToolResult:
+						fetched.add(&c)
 						for i := range pkt.ContentBlock.Content {
 							cc := &pkt.ContentBlock.Content[i]
 							switch cc.Type {
@@ -812,6 +1111,7 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 							finalErr = &internal.BadError{Err: fmt.Errorf("failed to parse citation: %w", err)}
 							return
 						}
+						fetched.annotate(&f.Citation)
 					default:
 						finalErr = &internal.BadError{Err: fmt.Errorf("implement content block delta %q", pkt.Delta.Type)}
 						return
@@ -819,19 +1119,26 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 				case ChunkContentBlockStop:
 					// Marks a closure of the block pkt.Index. Flush accumulated JSON if appropriate.
 					if pendingToolCall.ID != "" {
-						pendingToolCall.Arguments = pendingJSON
+						args, err := toolCallArguments(pendingToolCall.Name, []byte(pendingJSON))
+						if err != nil {
+							finalErr = err
+							return
+						}
+						pendingToolCall.Arguments = args
 						f.ToolCall = pendingToolCall
 						pendingToolCall = genai.ToolCall{}
 					}
+					if pendingServerCall != "" && pendingJSON != "" {
+						// The input is only known once fully streamed.
+						if !yield(genai.Reply{Index: pkt.Index, ServerToolCall: genai.ServerToolCall{ID: pendingServerID, Name: pendingServerCall, Status: genai.ServerToolCallInProgress, Input: pendingJSON}}) {
+							return
+						}
+					}
 					// Why not web_search_20250305 ??
 					switch pendingServerCall {
 					case "web_search":
 						q := WebSearch{}
-						d := json.NewDecoder(strings.NewReader(pendingJSON))
-						if !internal.BeLenient {
-							d.DisallowUnknownFields()
-						}
-						if err := d.Decode(&q); err != nil {
+						if err := json.Unmarshal([]byte(pendingJSON), &q); err != nil {
 							finalErr = &internal.BadError{Err: fmt.Errorf("failed to decode pending server tool call %s: %w", pendingServerCall, err)}
 							return
 						}
@@ -842,11 +1149,7 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 						pendingServerCall = ""
 					case "web_fetch":
 						q := WebFetch{}
-						d := json.NewDecoder(strings.NewReader(pendingJSON))
-						if !internal.BeLenient {
-							d.DisallowUnknownFields()
-						}
-						if err := d.Decode(&q); err != nil {
+						if err := json.Unmarshal([]byte(pendingJSON), &q); err != nil {
 							finalErr = &internal.BadError{Err: fmt.Errorf("failed to decode pending server tool call %s: %w", pendingServerCall, err)}
 							return
 						}
@@ -858,16 +1161,20 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					case "":
 					default:
 						// Oops, more work to do!
-						if !internal.BeLenient {
-							finalErr = &internal.BadError{Err: fmt.Errorf("implement server tool call %q", pendingServerCall)}
-							return
-						}
+						finalErr = errors.Join(finalErr, &base.ErrUnhandled{Err: fmt.Errorf("implement server tool call %q", pendingServerCall)})
 					}
 					pendingJSON = ""
 				case ChunkMessageDelta:
 					// Includes finish reason and output tokens usage (but not input tokens!)
-					u.FinishReason = pkt.Delta.StopReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Delta.StopReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
+					u.StopSequence = pkt.Delta.StopSequence
 					u.OutputTokens = pkt.Usage.OutputTokens
+					// Server tool usage is cumulative.
+					u.WebSearchRequests = pkt.Usage.ServerToolUse.WebSearchRequests
+					u.WebFetchRequests = pkt.Usage.ServerToolUse.WebFetchRequests
 				case ChunkMessageStop:
 					// Doesn't contain anything.
 					continue
@@ -901,6 +1208,10 @@ type betaHeader struct {
 func (b *betaHeader) RoundTrip(req *http.Request) (*http.Response, error) {
 	if v, _ := req.Context().Value(ctxBetaKey{}).(string); v != "" {
 		req = req.Clone(req.Context())
+		// Keep the betas requested via genai.GenOptionHeaders.
+		if prev := req.Header.Get("anthropic-beta"); prev != "" {
+			v = prev + "," + v
+		}
 		req.Header.Set("anthropic-beta", v)
 	}
 	return b.transport.RoundTrip(req)
@@ -1067,7 +1378,11 @@ func (m *modelData) supportsThinking(t ThinkingType) bool {
 }
 
 var (
-	_ internal.Validatable = &Message{}
-	_ internal.Validatable = &Content{}
-	_ genai.Provider       = &Client{}
+	_ internal.Validatable         = &Message{}
+	_ internal.Validatable         = &Content{}
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+	_ genai.ProviderImageTokens    = &Client{}
+	_ genai.ProviderGenAsyncBatch  = &Client{}
+	_ genai.CacheEntry             = &FileMetadata{}
 )
@@ -8,10 +8,14 @@ package anthropic_test
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"log"
 	"net/http"
@@ -20,10 +24,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/maruel/roundtrippers"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/anthropic"
 	"github.com/maruel/genai/scoreboard"
@@ -44,6 +48,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return anthropic.New(t.Context(), opts...)
 }
 
@@ -199,6 +204,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				popts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, popts...)
 			}
+			popts = append(popts, genai.ProviderOptionStrict(true))
 			c, err := anthropic.New(t.Context(), popts...)
 			if err != nil {
 				t.Fatal(err)
@@ -929,6 +935,591 @@ func getSmitheryServiceToken(apiKey, namespace string) (string, error) {
 
 var updateModels = flag.Bool("update-models", false, "update models.json from ListModels data")
 
-func init() {
-	internal.BeLenient = false
+func TestChatResponse_ToResult(t *testing.T) {
+	const data = `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"Searching."}],"stop_reason":"pause_turn","usage":{"input_tokens":10,"output_tokens":5,"server_tool_use":{"web_search_requests":3,"web_fetch_requests":1}}}`
+	var resp anthropic.ChatResponse
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatal(err)
+	}
+	res, err := resp.ToResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := genai.Usage{InputTokens: 10, OutputTokens: 5, FinishReason: genai.FinishedPauseTurn, WebSearchRequests: 3, WebFetchRequests: 1}
+	if diff := cmp.Diff(want, res.Usage); diff != "" {
+		t.Fatalf("unexpected usage (-want +got):\n%s", diff)
+	}
+}
+
+func TestChatResponse_ToResult_stopSequence(t *testing.T) {
+	const data = `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"One, two"}],"stop_reason":"stop_sequence","stop_sequence":"three","usage":{"input_tokens":10,"output_tokens":5}}`
+	var resp anthropic.ChatResponse
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatal(err)
+	}
+	res, err := resp.ToResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := genai.Usage{InputTokens: 10, OutputTokens: 5, FinishReason: genai.FinishedStopSequence, StopSequence: "three"}
+	if diff := cmp.Diff(want, res.Usage); diff != "" {
+		t.Fatalf("unexpected usage (-want +got):\n%s", diff)
+	}
+}
+
+func TestComputerInput(t *testing.T) {
+	tests := []struct {
+		in   anthropic.ComputerInput
+		want genai.ComputerAction
+	}{
+		{anthropic.ComputerInput{Action: "screenshot"}, genai.ComputerAction{Type: genai.ComputerScreenshot}},
+		{anthropic.ComputerInput{Action: "right_click", Coordinate: []int64{10, 20}}, genai.ComputerAction{Type: genai.ComputerClick, X: 10, Y: 20, Button: "right"}},
+		{anthropic.ComputerInput{Action: "left_click_drag", StartCoordinate: []int64{1, 2}, Coordinate: []int64{3, 4}}, genai.ComputerAction{Type: genai.ComputerDrag, Path: []genai.ComputerPoint{{X: 1, Y: 2}, {X: 3, Y: 4}}}},
+		{anthropic.ComputerInput{Action: "key", Text: "ctrl+s"}, genai.ComputerAction{Type: genai.ComputerKeypress, Keys: []string{"ctrl", "s"}}},
+		{anthropic.ComputerInput{Action: "scroll", Coordinate: []int64{5, 6}, ScrollDirection: "up", ScrollAmount: 3}, genai.ComputerAction{Type: genai.ComputerScroll, X: 5, Y: 6, ScrollY: -3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in.Action, func(t *testing.T) {
+			var got genai.ComputerAction
+			if err := tt.in.To(&got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(genai.ComputerAction{})); diff != "" {
+				t.Fatalf("(-want +got):\n%s", diff)
+			}
+			var back anthropic.ComputerInput
+			if err := back.From(&got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.in, back); diff != "" {
+				t.Fatalf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessStream_index(t *testing.T) {
+	chunks := []anthropic.ChatStreamChunkResponse{
+		{Type: anthropic.ChunkMessageStart, Message: anthropic.StreamMessage{Role: "assistant"}},
+		{Type: anthropic.ChunkContentBlockStart, Index: 0, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentThinking}},
+		{Type: anthropic.ChunkContentBlockDelta, Index: 0, Delta: anthropic.StreamDelta{Type: anthropic.DeltaThinking, Thinking: "Hmm"}},
+		{Type: anthropic.ChunkContentBlockStop, Index: 0},
+		{Type: anthropic.ChunkContentBlockStart, Index: 1, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentText}},
+		{Type: anthropic.ChunkContentBlockDelta, Index: 1, Delta: anthropic.StreamDelta{Type: anthropic.DeltaText, Text: "Hi"}},
+		{Type: anthropic.ChunkContentBlockStop, Index: 1},
+	}
+	fragments, finish := anthropic.ProcessStream(func(yield func(anthropic.ChatStreamChunkResponse) bool) {
+		for _, c := range chunks {
+			if !yield(c) {
+				return
+			}
+		}
+	})
+	var got []genai.Reply
+	for f := range fragments {
+		if !f.IsZero() {
+			got = append(got, f)
+		}
+	}
+	if _, _, err := finish(); err != nil {
+		t.Fatal(err)
+	}
+	want := []genai.Reply{{Reasoning: "Hmm"}, {Text: "Hi", Index: 1}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessStream_serverToolCall(t *testing.T) {
+	chunks := []anthropic.ChatStreamChunkResponse{
+		{Type: anthropic.ChunkMessageStart, Message: anthropic.StreamMessage{Role: "assistant"}},
+		{Type: anthropic.ChunkContentBlockStart, Index: 0, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentServerToolUse, ID: "srvtoolu_1", Name: "web_search", Input: map[string]json.RawMessage{}}},
+		{Type: anthropic.ChunkContentBlockDelta, Index: 0, Delta: anthropic.StreamDelta{Type: anthropic.DeltaInputJSON, PartialJSON: `{"query":"genai"}`}},
+		{Type: anthropic.ChunkContentBlockStop, Index: 0},
+		{Type: anthropic.ChunkContentBlockStart, Index: 1, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentWebSearchToolResult, ToolUseID: "srvtoolu_1", Content: anthropic.Contents{{Type: anthropic.ContentWebSearchResult, URL: "https://example.com"}}}},
+		{Type: anthropic.ChunkContentBlockStop, Index: 1},
+	}
+	fragments, finish := anthropic.ProcessStream(func(yield func(anthropic.ChatStreamChunkResponse) bool) {
+		for _, c := range chunks {
+			if !yield(c) {
+				return
+			}
+		}
+	})
+	var got genai.Message
+	for f := range fragments {
+		if err := got.Accumulate(&f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := finish(); err != nil {
+		t.Fatal(err)
+	}
+	want := genai.ServerToolCall{ID: "srvtoolu_1", Name: genai.ServerToolWebSearch, Status: genai.ServerToolCallCompleted, Input: `{"query":"genai"}`}
+	if len(got.Replies) != 3 {
+		t.Fatalf("got %d replies: %#v", len(got.Replies), got.Replies)
+	}
+	if diff := cmp.Diff(want, got.Replies[0].ServerToolCall); diff != "" {
+		t.Fatalf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestWebFetchCitations(t *testing.T) {
+	cit := anthropic.Citation{
+		Type:           anthropic.CitationCharLocation,
+		CitedText:      "Quack is the capital.",
+		DocumentTitle:  "Example",
+		StartCharIndex: 0,
+		EndCharIndex:   21,
+	}
+	want := genai.Citation{
+		CitedText: "Quack is the capital.",
+		Sources: []genai.CitationSource{{
+			Type:          genai.CitationWeb,
+			ID:            "0",
+			Title:         "Example",
+			URL:           "https://example.com",
+			Snippet:       "Quack is the capital.",
+			EndCharIndex:  21,
+			DocumentIndex: 1,
+			DocumentName:  "Example",
+		}},
+	}
+	opt := cmp.AllowUnexported(genai.Citation{}, genai.CitationSource{})
+	t.Run("GenSync", func(t *testing.T) {
+		const data = `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[` +
+			`{"type":"server_tool_use","id":"srvtoolu_1","name":"web_fetch","input":{"url":"https://example.com"}},` +
+			`{"type":"web_fetch_tool_result","tool_use_id":"srvtoolu_1","content":{"type":"web_fetch_result","url":"https://example.com","retrieved_at":"2026-01-01T00:00:00Z","content":{"type":"document","source":{"type":"text","media_type":"text/plain","data":"Quack is the capital."},"title":"Example"}}},` +
+			`{"type":"text","text":"Quack.","citations":[{"type":"char_location","cited_text":"Quack is the capital.","document_index":0,"document_title":"Example","start_char_index":0,"end_char_index":21}]}` +
+			`],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":5}}`
+		var resp anthropic.ChatResponse
+		if err := json.Unmarshal([]byte(data), &resp); err != nil {
+			t.Fatal(err)
+		}
+		res, err := resp.ToResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := res.Replies[len(res.Replies)-1].Citation
+		if diff := cmp.Diff(want, got, opt); diff != "" {
+			t.Fatalf("(-want +got):\n%s", diff)
+		}
+	})
+	t.Run("GenStream", func(t *testing.T) {
+		chunks := []anthropic.ChatStreamChunkResponse{
+			{Type: anthropic.ChunkMessageStart, Message: anthropic.StreamMessage{Role: "assistant"}},
+			{Type: anthropic.ChunkContentBlockStart, Index: 0, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentWebFetchToolResult, ToolUseID: "srvtoolu_1", Content: anthropic.Contents{{Type: anthropic.ContentWebFetchResult, URL: "https://example.com", Title: "Example"}}}},
+			{Type: anthropic.ChunkContentBlockStop, Index: 0},
+			{Type: anthropic.ChunkContentBlockStart, Index: 1, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentText}},
+			{Type: anthropic.ChunkContentBlockDelta, Index: 1, Delta: anthropic.StreamDelta{Type: anthropic.DeltaCitations, Citation: cit}},
+			{Type: anthropic.ChunkContentBlockStop, Index: 1},
+		}
+		fragments, finish := anthropic.ProcessStream(func(yield func(anthropic.ChatStreamChunkResponse) bool) {
+			for _, c := range chunks {
+				if !yield(c) {
+					return
+				}
+			}
+		})
+		var got []genai.Citation
+		for f := range fragments {
+			if !f.Citation.IsZero() {
+				got = append(got, f.Citation)
+			}
+		}
+		if _, _, err := finish(); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("got %d citations: %#v", len(got), got)
+		}
+		if diff := cmp.Diff(want, got[1], opt); diff != "" {
+			t.Fatalf("(-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestRedactedThinking(t *testing.T) {
+	chunks := []anthropic.ChatStreamChunkResponse{
+		{Type: anthropic.ChunkMessageStart, Message: anthropic.StreamMessage{Role: "assistant"}},
+		{Type: anthropic.ChunkContentBlockStart, Index: 0, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentThinking}},
+		{Type: anthropic.ChunkContentBlockDelta, Index: 0, Delta: anthropic.StreamDelta{Type: anthropic.DeltaThinking, Thinking: "Hmm"}},
+		{Type: anthropic.ChunkContentBlockDelta, Index: 0, Delta: anthropic.StreamDelta{Type: anthropic.DeltaSignature, Signature: []byte("sig")}},
+		{Type: anthropic.ChunkContentBlockStop, Index: 0},
+		{Type: anthropic.ChunkContentBlockStart, Index: 1, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentRedactedThinking, Data: "secret1"}},
+		{Type: anthropic.ChunkContentBlockStop, Index: 1},
+		{Type: anthropic.ChunkContentBlockStart, Index: 2, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentRedactedThinking, Data: "secret2"}},
+		{Type: anthropic.ChunkContentBlockStop, Index: 2},
+		{Type: anthropic.ChunkContentBlockStart, Index: 3, ContentBlock: anthropic.StreamContentBlock{Type: anthropic.ContentText}},
+		{Type: anthropic.ChunkContentBlockDelta, Index: 3, Delta: anthropic.StreamDelta{Type: anthropic.DeltaText, Text: "Hi"}},
+		{Type: anthropic.ChunkContentBlockStop, Index: 3},
+	}
+	fragments, finish := anthropic.ProcessStream(func(yield func(anthropic.ChatStreamChunkResponse) bool) {
+		for _, c := range chunks {
+			if !yield(c) {
+				return
+			}
+		}
+	})
+	var msg genai.Message
+	for f := range fragments {
+		if err := msg.Accumulate(&f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := finish(); err != nil {
+		t.Fatal(err)
+	}
+	// Round trip through JSON like a persisted conversation.
+	b, err := json.Marshal(&msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg2 genai.Message
+	if err := json.Unmarshal(b, &msg2); err != nil {
+		t.Fatal(err)
+	}
+	for name, m := range map[string]genai.Message{"stream": msg, "json": msg2} {
+		t.Run(name, func(t *testing.T) {
+			var req anthropic.ChatRequest
+			if err := req.Init(genai.Messages{genai.NewTextMessage("test"), m}, "claude-sonnet-4-6"); err != nil {
+				t.Fatal(err)
+			}
+			want := []anthropic.Content{
+				{Type: anthropic.ContentThinking, Thinking: "Hmm", Signature: []byte("sig")},
+				{Type: anthropic.ContentRedactedThinking, Data: "secret1"},
+				{Type: anthropic.ContentRedactedThinking, Data: "secret2"},
+				{Type: anthropic.ContentText, Text: "Hi"},
+			}
+			if diff := cmp.Diff(want, req.Messages[1].Content); diff != "" {
+				t.Fatalf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPromptCaching(t *testing.T) {
+	msgs := genai.Messages{genai.NewTextMessage("test")}
+	tools := &genai.GenOptionTools{Tools: []genai.ToolDef{
+		{Name: "a", Description: "a", Callback: func(ctx context.Context, in *struct{}) (string, error) { return "", nil }},
+		{Name: "b", Description: "b", Callback: func(ctx context.Context, in *struct{}) (string, error) { return "", nil }},
+	}}
+	t.Run("valid", func(t *testing.T) {
+		var req anthropic.ChatRequest
+		opts := []genai.GenOption{
+			&anthropic.GenOptionText{CacheSystemPrompt: true, CacheTools: true, CacheTTL: anthropic.CacheTTL1h, MessagesToCache: 1},
+			&genai.GenOptionText{SystemPrompt: "Be nice."},
+			tools,
+		}
+		if err := req.Init(msgs, "claude-sonnet-4-5", opts...); err != nil {
+			t.Fatal(err)
+		}
+		want := anthropic.CacheControl{Type: "ephemeral", TTL: anthropic.CacheTTL1h}
+		if req.System[0].CacheControl != want {
+			t.Errorf("System cache = %+v", req.System[0].CacheControl)
+		}
+		if req.Tools[0].CacheControl != (anthropic.CacheControl{}) || req.Tools[1].CacheControl != want {
+			t.Errorf("Tools cache = %+v, %+v", req.Tools[0].CacheControl, req.Tools[1].CacheControl)
+		}
+		if req.Messages[0].CacheControl != want {
+			t.Errorf("Messages cache = %+v", req.Messages[0].CacheControl)
+		}
+	})
+	t.Run("default", func(t *testing.T) {
+		var req anthropic.ChatRequest
+		if err := req.Init(msgs, "claude-sonnet-4-5", &genai.GenOptionText{SystemPrompt: "Be nice."}, tools); err != nil {
+			t.Fatal(err)
+		}
+		if req.System[0].CacheControl != (anthropic.CacheControl{}) || req.Tools[1].CacheControl != (anthropic.CacheControl{}) {
+			t.Errorf("unexpected cache breakpoints: %+v, %+v", req.System[0].CacheControl, req.Tools[1].CacheControl)
+		}
+	})
+	t.Run("batch", func(t *testing.T) {
+		var req anthropic.BatchRequestItem
+		err := req.Init(msgs, "claude-sonnet-4-5", &anthropic.GenOptionText{CacheSystemPrompt: true})
+		if err == nil || !strings.Contains(err.Error(), "GenOptionText.CacheSystemPrompt") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("invalid_ttl", func(t *testing.T) {
+		var req anthropic.ChatRequest
+		err := req.Init(msgs, "claude-sonnet-4-5", &anthropic.GenOptionText{CacheTTL: "2h"})
+		if err == nil || !strings.Contains(err.Error(), "invalid CacheTTL") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestFileReference(t *testing.T) {
+	msgs := genai.Messages{{Requests: []genai.Request{
+		{Text: "Describe."},
+		{Doc: genai.Doc{Filename: "photo.jpg", URL: anthropic.FileURL("file_img")}},
+		{Doc: genai.Doc{URL: anthropic.FileURL("file_doc")}},
+	}}}
+	var req anthropic.ChatRequest
+	if err := req.Init(msgs, "claude-sonnet-4-5"); err != nil {
+		t.Fatal(err)
+	}
+	c := req.Messages[0].Content
+	if c[1].Type != anthropic.ContentImage || c[1].Source.Type != anthropic.SourceFileID || c[1].Source.FileID != "file_img" {
+		t.Errorf("unexpected image content: %+v", c[1])
+	}
+	if c[2].Type != anthropic.ContentDocument || c[2].Source.Type != anthropic.SourceFileID || c[2].Source.FileID != "file_doc" {
+		t.Errorf("unexpected document content: %+v", c[2])
+	}
+}
+
+func TestCacheAddRequest_errors(t *testing.T) {
+	c, err := anthropic.New(t.Context(), genai.ProviderOptionAPIKey("<insert_api_key_here>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := func(name string) genai.Request {
+		return genai.Request{Doc: genai.Doc{Filename: name, Src: strings.NewReader("hello")}}
+	}
+	tests := []struct {
+		name string
+		msgs genai.Messages
+		opts []genai.GenOption
+		want string
+	}{
+		{"no_doc", genai.Messages{genai.NewTextMessage("hi")}, nil, "expected exactly one document to upload, got 0"},
+		{"two_docs", genai.Messages{{Requests: []genai.Request{doc("a.txt"), doc("b.txt")}}}, nil, "expected exactly one document to upload, got 2"},
+		{"url", genai.Messages{{Requests: []genai.Request{{Doc: genai.Doc{URL: "https://example.com/a.pdf"}}}}}, nil, "document must be provided inline, not as a URL"},
+		{"opts", genai.Messages{{Requests: []genai.Request{doc("a.txt")}}}, []genai.GenOption{&genai.GenOptionText{}}, "not supported: GenOptionText"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.CacheAddRequest(t.Context(), tc.msgs, "", "", 0, tc.opts...)
+			if err == nil || err.Error() != tc.want {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestImageTokens(t *testing.T) {
+	c, err := anthropic.New(t.Context(), genai.ProviderOptionAPIKey("<insert_api_key_here>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []struct {
+		width, height int
+		want          int64
+	}{
+		{200, 200, 54},
+		{1092, 1092, 1590},
+		{2000, 1000, 1600},
+		{0, 0, 0},
+	}
+	for _, tc := range data {
+		if got := c.ImageTokens(tc.width, tc.height); got != tc.want {
+			t.Errorf("%dx%d: got %d, want %d", tc.width, tc.height, got, tc.want)
+		}
+	}
+}
+
+func TestGenAsyncBatch(t *testing.T) {
+	var sent anthropic.BatchRequest
+	c, err := anthropic.New(t.Context(),
+		genai.ProviderOptionAPIKey("<insert_api_key_here>"),
+		genai.ProviderOptionModel("claude-sonnet-4-5"),
+		genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+			return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				status, body := http.StatusOK, ""
+				switch r.Method + " " + r.URL.Path {
+				case "POST /v1/messages/batches":
+					if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+						return nil, err
+					}
+					body = `{"id":"msgbatch_1","type":"message_batch","processing_status":"in_progress"}`
+				case "GET /v1/messages/batches/msgbatch_1":
+					body = `{"id":"msgbatch_1","type":"message_batch","processing_status":"ended"}`
+				case "GET /v1/messages/batches/msgbatch_1/results":
+					body = `{"custom_id":"1","result":{"type":"succeeded","message":{"type":"message","role":"assistant","content":[{"type":"text","text":"b"}],"id":"msg_1","model":"claude-sonnet-4-5","stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":1}}}}
+{"custom_id":"0","result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"bad"}}}}
+`
+				default:
+					status, body = http.StatusNotFound, `{"type":"error","error":{"type":"not_found_error","message":"not found"}}`
+				}
+				return &http.Response{
+					StatusCode: status,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(body)),
+					Request:    r,
+				}, nil
+			})
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := c.GenAsyncBatch(t.Context(), []genai.Messages{{genai.NewTextMessage("a")}, {genai.NewTextMessage("b")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job != "msgbatch_1" || len(sent.Requests) != 2 || sent.Requests[0].CustomID != "0" || sent.Requests[1].CustomID != "1" {
+		t.Fatalf("unexpected batch %q: %+v", job, sent)
+	}
+	res, err := c.PokeBatchResults(t.Context(), job)
+	if err == nil || err.Error() != "request 0: error invalid_request_error: bad" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 2 || !res[0].IsZero() || res[1].String() != "b" || res[1].Usage.InputTokens != 3 {
+		t.Fatalf("unexpected results: %+v", res)
+	}
+}
+
+func TestPlatform(t *testing.T) {
+	t.Run("vertex", func(t *testing.T) {
+		var body []byte
+		fake := internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if want := "https://us-east5-aiplatform.googleapis.com/v1/projects/p/locations/us-east5/publishers/anthropic/models/claude-haiku-4-5@20251001:rawPredict"; r.URL.String() != want {
+				t.Errorf("URL = %q", r.URL)
+			}
+			if got := r.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("Authorization = %q", got)
+			}
+			if got := r.Header.Get("x-api-key"); got != "" {
+				t.Errorf("x-api-key = %q", got)
+			}
+			var err error
+			if body, err = io.ReadAll(r.Body); err != nil {
+				t.Fatal(err)
+			}
+			const resp = `{"id":"msg_1","type":"message","role":"assistant","model":"claude-haiku-4-5-20251001",` +
+				`"content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn","usage":{"input_tokens":8,"output_tokens":2}}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(resp)),
+				Request:    r,
+			}, nil
+		})
+		c, err := anthropic.New(t.Context(),
+			genai.ProviderOptionRemote("https://us-east5-aiplatform.googleapis.com/v1/projects/p/locations/us-east5"),
+			genai.ProviderOptionModel("claude-haiku-4-5@20251001"),
+			anthropic.ProviderOptionAuth(func(r *http.Request) error {
+				r.Header.Set("Authorization", "Bearer token")
+				return nil
+			}),
+			genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Hello")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := got["model"]; ok {
+			t.Errorf("unexpected model in %s", body)
+		}
+		if got["anthropic_version"] != "vertex-2023-10-16" {
+			t.Errorf("unexpected anthropic_version in %s", body)
+		}
+		if res.String() != "Hi" || res.Usage.InputTokens != 8 {
+			t.Errorf("unexpected result: %#v", res)
+		}
+		if _, err := c.ListModels(t.Context()); err == nil {
+			t.Error("expected ListModels to fail")
+		}
+	})
+	t.Run("bedrock", func(t *testing.T) {
+		chunks := []string{
+			`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-haiku-4-5-20251001","content":[],"usage":{"input_tokens":8,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}`,
+			`{"type":"message_stop","amazon-bedrock-invocationMetrics":{"inputTokenCount":8,"outputTokenCount":2}}`,
+		}
+		var stream bytes.Buffer
+		for _, c := range chunks {
+			payload := `{"bytes":"` + base64.StdEncoding.EncodeToString([]byte(c)) + `"}`
+			writeMessage(&stream, map[string]string{":message-type": "event", ":event-type": "chunk"}, payload)
+		}
+		var body []byte
+		fake := internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if want := "https://bedrock-runtime.us-east-1.amazonaws.com/model/us.anthropic.claude-haiku-4-5-20251001-v1:0/invoke-with-response-stream"; r.URL.String() != want {
+				t.Errorf("URL = %q", r.URL)
+			}
+			if got := r.Header.Get("Authorization"); got != "Bearer key" {
+				t.Errorf("Authorization = %q", got)
+			}
+			var err error
+			if body, err = io.ReadAll(r.Body); err != nil {
+				t.Fatal(err)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/vnd.amazon.eventstream"}},
+				Body:       io.NopCloser(&stream),
+				Request:    r,
+			}, nil
+		})
+		c, err := anthropic.New(t.Context(),
+			genai.ProviderOptionRemote("https://bedrock-runtime.us-east-1.amazonaws.com"),
+			genai.ProviderOptionAPIKey("key"),
+			genai.ProviderOptionModel("us.anthropic.claude-haiku-4-5-20251001-v1:0"),
+			genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fragments, finish := c.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("Hello")})
+		var text strings.Builder
+		for f := range fragments {
+			text.WriteString(f.Text)
+		}
+		res, err := finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := got["stream"]; ok {
+			t.Errorf("unexpected stream in %s", body)
+		}
+		if got["anthropic_version"] != "bedrock-2023-05-31" {
+			t.Errorf("unexpected anthropic_version in %s", body)
+		}
+		if text.String() != "Hi" || res.Usage.FinishReason != genai.FinishedStop {
+			t.Errorf("unexpected result: %q %#v", text.String(), res.Usage)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		if _, err := anthropic.New(t.Context(), genai.ProviderOptionRemote("https://example.com")); err == nil {
+			t.Error("expected unsupported remote error")
+		}
+		if _, err := anthropic.New(t.Context(), genai.ProviderOptionRemote("https://bedrock-runtime.us-east-1.amazonaws.com")); err == nil {
+			t.Error("expected missing auth error")
+		}
+	})
+}
+
+// writeMessage encodes an AWS event stream message with string headers.
+func writeMessage(w *bytes.Buffer, hdrs map[string]string, payload string) {
+	var h bytes.Buffer
+	for k, v := range hdrs {
+		h.WriteByte(byte(len(k)))
+		h.WriteString(k)
+		h.WriteByte(7)
+		_ = binary.Write(&h, binary.BigEndian, uint16(len(v)))
+		h.WriteString(v)
+	}
+	var msg bytes.Buffer
+	total := uint32(12 + h.Len() + len(payload) + 4)
+	_ = binary.Write(&msg, binary.BigEndian, total)
+	_ = binary.Write(&msg, binary.BigEndian, uint32(h.Len()))
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(h.Bytes())
+	msg.WriteString(payload)
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	w.Write(msg.Bytes())
 }
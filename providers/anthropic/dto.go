@@ -21,6 +21,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -79,6 +81,12 @@ func (c *ChatRequest) initImpl(msgs genai.Messages, model string, cache bool, op
 	var errs []error
 	var unsupported []string
 	msgToCache := 0
+	cacheSystem := false
+	cacheTools := false
+	var cacheTTL CacheTTL
+	var effort genai.ReasoningEffort
+	thinkingSet := false
+	webFetchCitations := false
 	md, hasModelData := getModelData(model)
 	if hasModelData {
 		c.Thinking = md.defaultThinking()
@@ -95,14 +103,27 @@ func (c *ChatRequest) initImpl(msgs genai.Messages, model string, cache bool, op
 		case *GenOptionText:
 			if cache {
 				msgToCache = v.MessagesToCache
-			} else if v.MessagesToCache != 0 {
-				unsupported = append(unsupported, "GenOptionText.MessagesToCache")
+				cacheSystem = v.CacheSystemPrompt
+				cacheTools = v.CacheTools
+				cacheTTL = v.CacheTTL
+			} else {
+				if v.MessagesToCache != 0 {
+					unsupported = append(unsupported, "GenOptionText.MessagesToCache")
+				}
+				if v.CacheSystemPrompt {
+					unsupported = append(unsupported, "GenOptionText.CacheSystemPrompt")
+				}
+				if v.CacheTools {
+					unsupported = append(unsupported, "GenOptionText.CacheTools")
+				}
 			}
 			c.OutputConfig.Effort = v.Effort
 			if hasModelData && !md.supportsEffort(v.Effort) {
 				unsupported = append(unsupported, "GenOptionText.Effort")
 			}
 			c.InferenceGeo = v.InferenceGeo
+			webFetchCitations = v.WebFetchCitations
+			thinkingSet = v.Thinking != ""
 			switch v.Thinking {
 			case ThinkingAdaptive:
 				c.Thinking = Thinking{Type: ThinkingAdaptive, Display: ThinkingDisplaySummarized}
@@ -126,6 +147,7 @@ func (c *ChatRequest) initImpl(msgs genai.Messages, model string, cache bool, op
 				unsupported = append(unsupported, "GenOptionText.Thinking")
 			}
 		case *genai.GenOptionText:
+			effort = v.ReasoningEffort
 			u, err := c.initOptionsText(v)
 			unsupported = append(unsupported, u...)
 			if err != nil {
@@ -136,12 +158,31 @@ func (c *ChatRequest) initImpl(msgs genai.Messages, model string, cache bool, op
 				errs = append(errs, err)
 			}
 		case *genai.GenOptionWeb:
-			c.initOptionsWeb(v)
+			unsupported = append(unsupported, c.initOptionsWeb(v)...)
 		default:
 			unsupported = append(unsupported, internal.TypeName(opt))
 		}
 	}
 
+	// The generic reasoning effort only applies when GenOptionText didn't already set the native value.
+	switch effort {
+	case "":
+	case genai.ReasoningEffortOff:
+		if !thinkingSet {
+			c.Thinking = Thinking{Type: ThinkingDisabled}
+			if hasModelData && !md.supportsThinking(ThinkingDisabled) {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
+		}
+	default:
+		if c.OutputConfig.Effort == "" {
+			c.OutputConfig.Effort = Effort(effort)
+			if hasModelData && !md.supportsEffort(c.OutputConfig.Effort) {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
+		}
+	}
+
 	// Post process to take into account limitations by the provider.
 	// Forced tool use is incompatible with thinking.
 	// https://docs.anthropic.com/en/docs/build-with-claude/extended-thinking
@@ -149,6 +190,26 @@ func (c *ChatRequest) initImpl(msgs genai.Messages, model string, cache bool, op
 		unsupported = append(unsupported, "GenOptionTools.Force")
 		c.ToolChoice.Type = ToolChoiceAuto
 	}
+	if webFetchCitations {
+		found := false
+		for i := range c.Tools {
+			if c.Tools[i].Name == "web_fetch" {
+				c.Tools[i].Citations = Citations{Enabled: true}
+				found = true
+			}
+		}
+		if !found {
+			errs = append(errs, errors.New("GenOptionText.WebFetchCitations requires genai.GenOptionWeb.Fetch"))
+		}
+	}
+	// The cache prefix order is tools, system then messages. Putting a breakpoint on the last element caches
+	// everything before it.
+	if cacheTools && len(c.Tools) != 0 {
+		c.Tools[len(c.Tools)-1].CacheControl = CacheControl{Type: "ephemeral", TTL: cacheTTL}
+	}
+	if cacheSystem && len(c.System) != 0 {
+		c.System[len(c.System)-1].CacheControl = CacheControl{Type: "ephemeral", TTL: cacheTTL}
+	}
 
 	c.Messages = make([]Message, 0, len(msgs))
 	for i := range msgs {
@@ -157,7 +218,7 @@ func (c *ChatRequest) initImpl(msgs genai.Messages, model string, cache bool, op
 			errs = append(errs, fmt.Errorf("message #%d: %w", i, err))
 		}
 		if i == msgToCache-1 {
-			c.Messages[i].CacheControl.Type = "ephemeral"
+			c.Messages[i].CacheControl = CacheControl{Type: "ephemeral", TTL: cacheTTL}
 		}
 		if len(errs) == 0 {
 			if err := c.Messages[len(c.Messages)-1].Validate(); err != nil {
@@ -188,11 +249,15 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) ([]string, error)
 	c.Temperature = v.Temperature
 	if v.SystemPrompt != "" {
 		c.System = []SystemMessage{{Type: "text", Text: v.SystemPrompt}}
-		// TODO: Add automatic caching.
-		// c.System[0].CacheControl.Type = "ephemeral"
 	}
 	c.TopP = v.TopP
 	c.TopK = v.TopK
+	if v.N > 1 {
+		unsupported = append(unsupported, "GenOptionText.N")
+	}
+	if v.Grammar != "" {
+		unsupported = append(unsupported, "GenOptionText.Grammar")
+	}
 	c.StopSequences = v.Stop
 	if v.DecodeAs != nil {
 		s, err := v.DecodeSchema()
@@ -213,7 +278,17 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) ([]string, error)
 }
 
 func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) error {
-	if len(v.Tools) != 0 {
+	if v.Computer != nil {
+		// https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/computer-use-tool
+		c.Tools = append(c.Tools, Tool{
+			Type:            "computer_20250124",
+			Name:            genai.ComputerToolName,
+			DisplayWidthPX:  v.Computer.Width,
+			DisplayHeightPX: v.Computer.Height,
+		})
+	}
+	tools := v.AllowedTools()
+	if len(tools) != 0 || v.Computer != nil {
 		switch v.Force {
 		case genai.ToolCallAny:
 			c.ToolChoice.Type = ToolChoiceAuto
@@ -222,31 +297,44 @@ func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) error {
 		case genai.ToolCallNone:
 			c.ToolChoice.Type = ToolChoiceNone
 		}
-		c.Tools = make([]Tool, len(v.Tools))
-		for i, t := range v.Tools {
+		for _, t := range tools {
 			// Weirdly enough, we must not set the type. See example at
 			// https://docs.anthropic.com/en/docs/build-with-claude/tool-use/overview
-			// c.Tools[i].Type = "custom"
-			c.Tools[i].Name = t.Name
-			c.Tools[i].Description = t.Description
+			// Type: "custom"
 			s, err := t.GetInputSchema()
 			if err != nil {
 				return err
 			}
-			c.Tools[i].InputSchema = s
+			c.Tools = append(c.Tools, Tool{Name: t.Name, Description: t.Description, InputSchema: s})
 		}
 	}
 	return nil
 }
 
-func (c *ChatRequest) initOptionsWeb(v *genai.GenOptionWeb) {
+func (c *ChatRequest) initOptionsWeb(v *genai.GenOptionWeb) []string {
+	var unsupported []string
 	if v.Search {
 		// https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/web-search-tool
-		c.Tools = append(c.Tools, Tool{
-			Type: "web_search_20250305",
-			Name: "web_search",
-			// MaxUses: 10,
-		})
+		t := Tool{Type: "web_search_20250305", Name: "web_search"}
+		if o := v.SearchOptions; o != nil {
+			t.AllowedDomains = o.AllowedDomains
+			t.BlockedDomains = o.BlockedDomains
+			t.MaxUses = o.MaxSearches
+			if o.ContextSize != "" {
+				unsupported = append(unsupported, "GenOptionWeb.SearchOptions.ContextSize")
+			}
+			if l := &o.UserLocation; !l.IsZero() {
+				t.UserLocation.Type = "approximate"
+				t.UserLocation.City = l.City
+				t.UserLocation.Region = l.Region
+				t.UserLocation.Country = l.Country
+				t.UserLocation.Timezone = l.Timezone
+				if l.HasLatLng() {
+					unsupported = append(unsupported, "GenOptionWeb.SearchOptions.UserLocation.Latitude")
+				}
+			}
+		}
+		c.Tools = append(c.Tools, t)
 	}
 	if v.Fetch {
 		// https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/web-fetch-tool
@@ -255,6 +343,7 @@ func (c *ChatRequest) initOptionsWeb(v *genai.GenOptionWeb) {
 			Name: "web_fetch",
 		})
 	}
+	return unsupported
 }
 
 // MCPServer is documented at https://docs.anthropic.com/en/api/messages#body-mcp-servers
@@ -278,12 +367,10 @@ type ToolConfiguration struct {
 
 // SystemMessage is used in the system prompt.
 type SystemMessage struct {
-	Type         string `json:"type,omitzero"` // "text"
-	Text         string `json:"text,omitzero"`
-	CacheControl struct {
-		Type string `json:"type,omitzero"` // "ephemeral"
-	} `json:"cache_control,omitzero"`
-	Citations []Citation `json:"citations,omitzero"`
+	Type         string       `json:"type,omitzero"` // "text"
+	Text         string       `json:"text,omitzero"`
+	CacheControl CacheControl `json:"cache_control,omitzero"`
+	Citations    []Citation   `json:"citations,omitzero"`
 }
 
 // Message is documented at https://docs.anthropic.com/en/api/messages
@@ -292,10 +379,8 @@ type Message struct {
 	Role string `json:"role"`          // "assistant", "user"
 	// Anthropic's Content doesn't distinguish between actual content (text,
 	// documents) and tool use.
-	Content      []Content `json:"content"`
-	CacheControl struct {
-		Type string `json:"type,omitzero"` // "ephemeral"
-	} `json:"cache_control,omitzero"`
+	Content      []Content    `json:"content"`
+	CacheControl CacheControl `json:"cache_control,omitzero"`
 }
 
 // Validate implements genai.Validatable.
@@ -345,16 +430,20 @@ func (m *Message) From(in *genai.Message) error {
 		}
 	}
 	for i := range in.Replies {
-		c := Content{}
-		if skip, err := c.FromReply(&in.Replies[i]); err != nil {
-			return fmt.Errorf("reply #%d: %w", i, err)
-		} else if !skip {
-			m.Content = append(m.Content, c)
+		for _, r := range splitReasoning(&in.Replies[i]) {
+			c := Content{}
+			if skip, err := c.FromReply(r); err != nil {
+				return fmt.Errorf("reply #%d: %w", i, err)
+			} else if !skip {
+				m.Content = append(m.Content, c)
+			}
 		}
 	}
 	for i := range in.ToolCallResults {
 		m.Content = append(m.Content, Content{})
-		m.Content[len(m.Content)-1].FromToolCallResult(&in.ToolCallResults[i])
+		if err := m.Content[len(m.Content)-1].FromToolCallResult(&in.ToolCallResults[i]); err != nil {
+			return fmt.Errorf("tool call result #%d: %w", i, err)
+		}
 	}
 	return nil
 }
@@ -366,15 +455,72 @@ func (m *Message) To(out *genai.Message) error {
 	if err := m.validate(false); err != nil {
 		return err
 	}
+	fetched := webFetchURLs{}
+	for i := range m.Content {
+		if m.Content[i].Type == ContentWebFetchToolResult {
+			fetched.add(&m.Content[i])
+		}
+	}
 	// We need to split actual content and tool calls.
+	var errs []error
 	for i := range m.Content {
+		if sc := m.Content[i].serverToolCall(); !sc.IsZero() {
+			// The result updates the call it completes.
+			if err := out.Accumulate(&genai.Reply{ServerToolCall: sc}); err != nil {
+				return err
+			}
+		}
 		replies, err := m.Content[i].To()
 		if err != nil {
-			return fmt.Errorf("reply #%d: %w", i, err)
+			err = fmt.Errorf("reply #%d: %w", i, err)
+			if _, ok := errors.AsType[*base.ErrUnhandled](err); !ok {
+				return err
+			}
+			errs = append(errs, err)
+		}
+		for j := range replies {
+			fetched.annotate(&replies[j].Citation)
 		}
 		out.Replies = append(out.Replies, replies...)
 	}
-	return nil
+	return errors.Join(errs...)
+}
+
+// webFetchURLs maps the title of the pages fetched by the web_fetch server tool to their URL.
+//
+// The citations of fetched content are document citations referencing only the page title, so the URL has
+// to be recovered from the tool result.
+type webFetchURLs map[string]string
+
+// add records the pages fetched in a ContentWebFetchToolResult.
+func (w webFetchURLs) add(c *Content) {
+	for i := range c.Content {
+		cc := &c.Content[i]
+		if cc.Type != ContentWebFetchResult {
+			continue
+		}
+		title := cc.Title
+		if title == "" && len(cc.Content) > 0 {
+			title = cc.Content[0].Title
+		}
+		if title != "" {
+			w[title] = cc.URL
+		}
+	}
+}
+
+// annotate converts the document citations referencing a fetched page into web citations.
+func (w webFetchURLs) annotate(c *genai.Citation) {
+	for i := range c.Sources {
+		s := &c.Sources[i]
+		if s.Type != genai.CitationDocument || s.URL != "" || s.Title == "" {
+			continue
+		}
+		if u, ok := w[s.Title]; ok {
+			s.Type = genai.CitationWeb
+			s.URL = u
+		}
+	}
 }
 
 // Contents is a []Content with custom JSON unmarshaling that accepts both a single object and an array.
@@ -416,9 +562,7 @@ type Content struct {
 	Data string `json:"data,omitzero"`
 
 	// Type == ContentText, ContentImage, ContentDocument, ContentToolUse, ContentToolResult.
-	CacheControl struct {
-		Type string `json:"type,omitzero"` // "ephemeral"
-	} `json:"cache_control,omitzero"`
+	CacheControl CacheControl `json:"cache_control,omitzero"`
 
 	// Type == ContentText, ContentDocument.
 	Citations Citations `json:"citations,omitzero"`
@@ -634,6 +778,16 @@ func (c *Content) FromRequest(in *genai.Request) error {
 		if err != nil {
 			return err
 		}
+		if id := fileIDFromURL(in.Doc.URL); id != "" {
+			// A file uploaded via the Files API. Anthropic infers the mime type from the file.
+			c.Type = ContentDocument
+			if strings.HasPrefix(mimeType, "image/") {
+				c.Type = ContentImage
+			}
+			c.Source.Type = SourceFileID
+			c.Source.FileID = id
+			return nil
+		}
 		// Anthropic require a mime-type to determine if image or PDF.
 		if mimeType == "" {
 			return fmt.Errorf("unspecified mime type for URL %q", in.Doc.URL)
@@ -680,8 +834,53 @@ func (c *Content) FromRequest(in *genai.Request) error {
 	return errors.New("unknown Request type")
 }
 
+// splitReasoning returns the replies to convert for in.
+//
+// GenStream accumulates the Opaque fragments that follow a reasoning block into it, e.g. a redacted thinking
+// block. They must be sent back as separate content blocks, otherwise the API rejects the request.
+func splitReasoning(in *genai.Reply) []*genai.Reply {
+	if in.Reasoning == "" || len(in.Opaque) == 0 {
+		return []*genai.Reply{in}
+	}
+	out := []*genai.Reply{{Reasoning: in.Reasoning}}
+	for _, k := range slices.Sorted(maps.Keys(in.Opaque)) {
+		if k == "signature" {
+			out[0].Opaque = map[string]any{k: in.Opaque[k]}
+		} else {
+			out = append(out, &genai.Reply{Opaque: map[string]any{k: in.Opaque[k]}})
+		}
+	}
+	return out
+}
+
+// opaqueBytes returns the bytes stored in an Opaque field. It is a []byte as returned by the API or a base64
+// encoded string once the Messages were serialized as JSON.
+func opaqueBytes(v any) ([]byte, error) {
+	switch t := v.(type) {
+	case []byte:
+		return t, nil
+	case string:
+		return base64.StdEncoding.DecodeString(t)
+	default:
+		return nil, fmt.Errorf("unexpected type %T", v)
+	}
+}
+
+// fileIDFromURL returns the file ID if u is a reference to a file uploaded via the Files API, as returned by
+// FileURL.
+func fileIDFromURL(u string) string {
+	if id, ok := strings.CutPrefix(u, filesURL); ok && id != "" && !strings.Contains(id, "/") {
+		return id
+	}
+	return ""
+}
+
 // FromReply converts from a genai reply.
 func (c *Content) FromReply(in *genai.Reply) (bool, error) {
+	if !in.ServerToolCall.IsZero() {
+		// It is informative only; the server tool call blocks are not kept.
+		return true, nil
+	}
 	if in.Text != "" {
 		c.Type = ContentText
 		c.Text = in.Text
@@ -690,10 +889,12 @@ func (c *Content) FromReply(in *genai.Reply) (bool, error) {
 	if in.Reasoning != "" {
 		c.Type = ContentThinking
 		c.Thinking = in.Reasoning
-		if in.Opaque != nil {
-			if b, ok := in.Opaque["signature"].([]byte); ok {
-				c.Signature = b
+		if v, ok := in.Opaque["signature"]; ok {
+			b, err := opaqueBytes(v)
+			if err != nil {
+				return false, fmt.Errorf("field Opaque.signature: %w", err)
 			}
+			c.Signature = b
 		}
 		return false, nil
 	}
@@ -751,7 +952,18 @@ func (c *Content) FromReply(in *genai.Reply) (bool, error) {
 		c.Type = ContentToolUse
 		c.ID = in.ToolCall.ID
 		c.Name = in.ToolCall.Name
-		if err := json.Unmarshal([]byte(in.ToolCall.Arguments), &c.Input); err != nil {
+		args := []byte(in.ToolCall.Arguments)
+		if a, ok := decodeComputerAction(&in.ToolCall); ok {
+			ci := ComputerInput{}
+			if err := ci.From(&a); err != nil {
+				return false, err
+			}
+			var err error
+			if args, err = json.Marshal(&ci); err != nil {
+				return false, err
+			}
+		}
+		if err := json.Unmarshal(args, &c.Input); err != nil {
 			return false, fmt.Errorf("failed to unmarshal input: %w; for tool call: %#v", err, in)
 		}
 		return false, nil
@@ -812,13 +1024,60 @@ func (c *Content) FromReply(in *genai.Reply) (bool, error) {
 }
 
 // FromToolCallResult converts from a genai tool call result.
-func (c *Content) FromToolCallResult(in *genai.ToolCallResult) {
+func (c *Content) FromToolCallResult(in *genai.ToolCallResult) error {
 	// TODO: Support text citation.
-	// TODO: Support image.
 	c.Type = ContentToolResult
 	c.ToolUseID = in.ID
 	c.IsError = false
-	c.Content = []Content{{Type: ContentText, Text: in.Result}}
+	if in.Result != "" {
+		c.Content = []Content{{Type: ContentText, Text: in.Result}}
+	}
+	if !in.Doc.IsZero() {
+		img := Content{}
+		if _, err := img.FromReply(&genai.Reply{Doc: in.Doc}); err != nil {
+			return err
+		}
+		if img.Type != ContentImage {
+			return fmt.Errorf("unsupported tool result document type %q", img.Type)
+		}
+		// Keep the cache breakpoints for the whole message.
+		img.CacheControl = CacheControl{}
+		c.Content = append(c.Content, img)
+	}
+	return nil
+}
+
+// serverToolCall returns the progress of a server tool call or MCP call, or of its result. It is zero for
+// other content types.
+func (c *Content) serverToolCall() genai.ServerToolCall {
+	switch c.Type {
+	case ContentServerToolUse:
+		s := genai.ServerToolCall{ID: c.ID, Name: c.Name, Status: genai.ServerToolCallInProgress}
+		if len(c.Input) != 0 {
+			b, _ := json.Marshal(c.Input)
+			s.Input = string(b)
+		}
+		return s
+	case ContentMCPToolUse:
+		b, _ := json.Marshal(map[string]any{"server_name": c.ServerName, "name": c.Name, "input": c.Input})
+		return genai.ServerToolCall{ID: c.ID, Name: genai.ServerToolMCP, Status: genai.ServerToolCallInProgress, Input: string(b)}
+	case ContentWebSearchToolResult:
+		return genai.ServerToolCall{ID: c.ToolUseID, Name: genai.ServerToolWebSearch, Status: genai.ServerToolCallCompleted}
+	case ContentWebFetchToolResult:
+		s := genai.ServerToolCall{ID: c.ToolUseID, Name: genai.ServerToolWebFetch, Status: genai.ServerToolCallCompleted}
+		if slices.ContainsFunc(c.Content, func(cc Content) bool { return cc.Type == ContentWebFetchToolError }) {
+			s.Status = genai.ServerToolCallFailed
+		}
+		return s
+	case ContentMCPToolResult:
+		s := genai.ServerToolCall{ID: c.ToolUseID, Name: genai.ServerToolMCP, Status: genai.ServerToolCallCompleted}
+		if c.IsError {
+			s.Status = genai.ServerToolCallFailed
+		}
+		return s
+	default:
+		return genai.ServerToolCall{}
+	}
 }
 
 // To converts to the genai equivalent.
@@ -844,13 +1103,17 @@ func (c *Content) To() ([]genai.Reply, error) {
 	case ContentThinking:
 		out = append(out, genai.Reply{Reasoning: c.Thinking, Opaque: map[string]any{"signature": c.Signature}})
 	case ContentRedactedThinking:
-		out = append(out, genai.Reply{Opaque: map[string]any{"redacted_thinking": c.Signature}})
+		out = append(out, genai.Reply{Opaque: map[string]any{"redacted_thinking": c.Data}})
 	case ContentToolUse:
 		raw, err := json.Marshal(c.Input)
 		if err != nil {
 			return out, &internal.BadError{Err: fmt.Errorf("failed to marshal input: %w; for tool call: %#v", err, c)}
 		}
-		out = append(out, genai.Reply{ToolCall: genai.ToolCall{ID: c.ID, Name: c.Name, Arguments: string(raw)}})
+		args, err := toolCallArguments(c.Name, raw)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, genai.Reply{ToolCall: genai.ToolCall{ID: c.ID, Name: c.Name, Arguments: args}})
 	case ContentWebSearchToolResult:
 		src := make([]genai.CitationSource, len(c.Content))
 		for i := range c.Content {
@@ -875,11 +1138,7 @@ func (c *Content) To() ([]genai.Reply, error) {
 			if err != nil {
 				return out, &internal.BadError{Err: fmt.Errorf("failed to marshal server tool call %s: %w", c.Name, err)}
 			}
-			d := json.NewDecoder(bytes.NewReader(b))
-			if !internal.BeLenient {
-				d.DisallowUnknownFields()
-			}
-			if err := d.Decode(&q); err != nil {
+			if err := json.Unmarshal(b, &q); err != nil {
 				return out, &internal.BadError{Err: fmt.Errorf("failed to decode server tool call %s: %w", c.Name, err)}
 			}
 			out = append(out, genai.Reply{
@@ -891,11 +1150,7 @@ func (c *Content) To() ([]genai.Reply, error) {
 			if err != nil {
 				return out, &internal.BadError{Err: fmt.Errorf("failed to marshal server tool call %s: %w", c.Name, err)}
 			}
-			d := json.NewDecoder(bytes.NewReader(b))
-			if !internal.BeLenient {
-				d.DisallowUnknownFields()
-			}
-			if err := d.Decode(&q); err != nil {
+			if err := json.Unmarshal(b, &q); err != nil {
 				return out, &internal.BadError{Err: fmt.Errorf("failed to decode server tool call %s: %w", c.Name, err)}
 			}
 			out = append(out, genai.Reply{
@@ -903,9 +1158,7 @@ func (c *Content) To() ([]genai.Reply, error) {
 			})
 		default:
 			// Oops, more work to do!
-			if !internal.BeLenient {
-				return out, &internal.BadError{Err: fmt.Errorf("implement server tool call %q", c.Name)}
-			}
+			return out, &base.ErrUnhandled{Err: fmt.Errorf("implement server tool call %q", c.Name)}
 		}
 	case ContentMCPToolUse:
 		opaque := map[string]any{"mcp_tool_use": map[string]any{
@@ -964,6 +1217,178 @@ type WebFetch struct {
 	URL string `json:"url"`
 }
 
+// ComputerInput is the tool use input for the computer tool.
+//
+// https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/computer-use-tool
+type ComputerInput struct {
+	// "screenshot", "left_click", "right_click", "middle_click", "double_click", "mouse_move",
+	// "left_click_drag", "type", "key", "scroll", "wait"
+	Action          string  `json:"action"`
+	Coordinate      []int64 `json:"coordinate,omitzero"`
+	StartCoordinate []int64 `json:"start_coordinate,omitzero"`
+	Text            string  `json:"text,omitzero"`
+	ScrollDirection string  `json:"scroll_direction,omitzero"` // "up", "down", "left", "right"
+	ScrollAmount    int64   `json:"scroll_amount,omitzero"`
+	Duration        float64 `json:"duration,omitzero"` // In seconds
+}
+
+// To converts to the genai equivalent.
+func (ci *ComputerInput) To(out *genai.ComputerAction) error {
+	if len(ci.Coordinate) == 2 {
+		out.X = ci.Coordinate[0]
+		out.Y = ci.Coordinate[1]
+	}
+	switch ci.Action {
+	case "screenshot":
+		out.Type = genai.ComputerScreenshot
+	case "left_click":
+		out.Type = genai.ComputerClick
+		out.Button = "left"
+	case "right_click":
+		out.Type = genai.ComputerClick
+		out.Button = "right"
+	case "middle_click":
+		out.Type = genai.ComputerClick
+		out.Button = "middle"
+	case "double_click":
+		out.Type = genai.ComputerDoubleClick
+	case "mouse_move":
+		out.Type = genai.ComputerMove
+	case "left_click_drag":
+		if len(ci.StartCoordinate) != 2 || len(ci.Coordinate) != 2 {
+			return &internal.BadError{Err: fmt.Errorf("invalid %s coordinates", ci.Action)}
+		}
+		out.Type = genai.ComputerDrag
+		out.Path = []genai.ComputerPoint{{X: ci.StartCoordinate[0], Y: ci.StartCoordinate[1]}, {X: out.X, Y: out.Y}}
+		out.X = 0
+		out.Y = 0
+	case "type":
+		out.Type = genai.ComputerType
+		out.Text = ci.Text
+	case "key":
+		out.Type = genai.ComputerKeypress
+		out.Keys = strings.Split(ci.Text, "+")
+	case "scroll":
+		out.Type = genai.ComputerScroll
+		switch ci.ScrollDirection {
+		case "up":
+			out.ScrollY = -ci.ScrollAmount
+		case "down":
+			out.ScrollY = ci.ScrollAmount
+		case "left":
+			out.ScrollX = -ci.ScrollAmount
+		case "right":
+			out.ScrollX = ci.ScrollAmount
+		default:
+			return &internal.BadError{Err: fmt.Errorf("implement scroll direction %q", ci.ScrollDirection)}
+		}
+	case "wait":
+		out.Type = genai.ComputerWait
+	default:
+		return &internal.BadError{Err: fmt.Errorf("implement computer action %q", ci.Action)}
+	}
+	return nil
+}
+
+// From converts from the genai equivalent.
+func (ci *ComputerInput) From(in *genai.ComputerAction) error {
+	switch in.Type {
+	case genai.ComputerScreenshot:
+		ci.Action = "screenshot"
+		return nil
+	case genai.ComputerClick:
+		switch in.Button {
+		case "", "left":
+			ci.Action = "left_click"
+		case "right":
+			ci.Action = "right_click"
+		case "middle":
+			ci.Action = "middle_click"
+		default:
+			return fmt.Errorf("unsupported button %q", in.Button)
+		}
+	case genai.ComputerDoubleClick:
+		ci.Action = "double_click"
+	case genai.ComputerMove:
+		ci.Action = "mouse_move"
+	case genai.ComputerDrag:
+		if len(in.Path) < 2 {
+			return errors.New("drag requires at least 2 points")
+		}
+		first, last := in.Path[0], in.Path[len(in.Path)-1]
+		ci.Action = "left_click_drag"
+		ci.StartCoordinate = []int64{first.X, first.Y}
+		ci.Coordinate = []int64{last.X, last.Y}
+		return nil
+	case genai.ComputerType:
+		ci.Action = "type"
+		ci.Text = in.Text
+		return nil
+	case genai.ComputerKeypress:
+		ci.Action = "key"
+		ci.Text = strings.Join(in.Keys, "+")
+		return nil
+	case genai.ComputerScroll:
+		ci.Action = "scroll"
+		switch {
+		case in.ScrollY < 0:
+			ci.ScrollDirection = "up"
+			ci.ScrollAmount = -in.ScrollY
+		case in.ScrollY > 0:
+			ci.ScrollDirection = "down"
+			ci.ScrollAmount = in.ScrollY
+		case in.ScrollX < 0:
+			ci.ScrollDirection = "left"
+			ci.ScrollAmount = -in.ScrollX
+		default:
+			ci.ScrollDirection = "right"
+			ci.ScrollAmount = in.ScrollX
+		}
+	case genai.ComputerWait:
+		// The duration is not kept.
+		ci.Action = "wait"
+		ci.Duration = 1
+		return nil
+	default:
+		return fmt.Errorf("unsupported computer action %q", in.Type)
+	}
+	ci.Coordinate = []int64{in.X, in.Y}
+	return nil
+}
+
+// toolCallArguments returns the genai.ToolCall.Arguments for a tool use input, converting the computer tool
+// actions to genai.ComputerAction.
+func toolCallArguments(name string, input []byte) (string, error) {
+	if name != genai.ComputerToolName {
+		return string(input), nil
+	}
+	ci := ComputerInput{}
+	if err := json.Unmarshal(input, &ci); err != nil || ci.Action == "" {
+		// A client tool with the same name.
+		return string(input), nil
+	}
+	a := genai.ComputerAction{}
+	if err := ci.To(&a); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(&a)
+	return string(b), err
+}
+
+// decodeComputerAction returns the action if the tool call is a computer action.
+func decodeComputerAction(t *genai.ToolCall) (genai.ComputerAction, bool) {
+	a := genai.ComputerAction{}
+	if t.Name != genai.ComputerToolName {
+		return a, false
+	}
+	d := json.NewDecoder(strings.NewReader(t.Arguments))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&a); err != nil || a.Validate() != nil {
+		return a, false
+	}
+	return a, true
+}
+
 // SourceType is described at https://docs.anthropic.com/en/api/messages#body-messages-content-source
 type SourceType string
 
@@ -1031,23 +1456,15 @@ func (c *Citations) UnmarshalJSON(b []byte) error {
 		c.Enabled = false
 		return nil
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
 	var cc []Citation
-	if err := d.Decode(&cc); err == nil {
+	if err := json.Unmarshal(b, &cc); err == nil {
 		c.Citations = cc
 		c.Enabled = false
 		return nil
 	}
 
 	o := citationsObject{}
-	d = json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode(&o); err != nil {
+	if err := json.Unmarshal(b, &o); err != nil {
 		return err
 	}
 	c.Enabled = o.Enabled
@@ -1139,6 +1556,7 @@ type Citation struct {
 
 // To converts to the genai equivalent.
 func (c *Citation) To(dst *genai.Citation) error {
+	dst.CitedText = c.CitedText
 	switch c.Type {
 	case CitationText, CitationCharLocation, CitationPageLocation, CitationContentBlockLocation:
 		// TODO: Trigger CitationPageLocation, CitationContentBlockLocation in the smoke test.
@@ -1153,9 +1571,10 @@ func (c *Citation) To(dst *genai.Citation) error {
 			EndPageNumber:   c.EndPageNumber,
 			StartBlockIndex: c.StartBlockIndex,
 			EndBlockIndex:   c.EndBlockIndex,
+			DocumentIndex:   c.DocumentIndex + 1,
+			DocumentName:    c.DocumentTitle,
 		}}
 	case CitationWebSearchResultLocation:
-		// To confirm.
 		dst.Sources = []genai.CitationSource{{
 			Type:    genai.CitationWeb,
 			URL:     c.URL,
@@ -1213,6 +1632,36 @@ func (d ThinkingDisplay) Validate() error {
 	}
 }
 
+// CacheTTL is the lifetime of a prompt cache entry.
+//
+// https://docs.anthropic.com/en/docs/build-with-claude/prompt-caching#1-hour-cache-duration
+type CacheTTL string
+
+const (
+	// CacheTTL5m is the default cache lifetime of 5 minutes, refreshed each time the cache is hit.
+	CacheTTL5m CacheTTL = "5m"
+	// CacheTTL1h keeps the cache entry for one hour. Cache writes cost more.
+	CacheTTL1h CacheTTL = "1h"
+)
+
+// Validate implements genai.Validatable.
+func (c CacheTTL) Validate() error {
+	switch c {
+	case "", CacheTTL5m, CacheTTL1h:
+		return nil
+	default:
+		return fmt.Errorf("invalid CacheTTL %q", c)
+	}
+}
+
+// CacheControl is a prompt caching breakpoint.
+//
+// https://docs.anthropic.com/en/docs/build-with-claude/prompt-caching
+type CacheControl struct {
+	Type string   `json:"type,omitzero"` // "ephemeral"
+	TTL  CacheTTL `json:"ttl,omitzero"`
+}
+
 // Thinking is a provider-specific thinking block.
 type Thinking struct {
 	BudgetTokens int64           `json:"budget_tokens,omitzero"` // >1024 and less than max_tokens; unused for adaptive
@@ -1260,9 +1709,7 @@ type Tool struct {
 	Name string `json:"name,omitzero"`
 
 	// Type == "custom", "computer_20241022", "computer_20250124", "bash_20241022", "bash_20250124", "text_editor_20241022", "text_editor_20250124", "text_editor_20250429", "text_editor_20250728"
-	CacheControl struct {
-		Type string `json:"type,omitzero"` // "ephemeral"
-	} `json:"cache_control,omitzero"`
+	CacheControl CacheControl `json:"cache_control,omitzero"`
 
 	// Type == "text_editor_20250728"
 	MaxCharacters int64 `json:"max_characters,omitzero"`
@@ -1284,8 +1731,9 @@ type Tool struct {
 	} `json:"user_location,omitzero"`
 
 	// Type == "web_fetch_20250910"
-	MaxContentTokens int64 `json:"max_content_tokens,omitzero"` // Max tokens of fetched content to return. Default is 10000.
-	MaxUses          int64 `json:"max_uses,omitzero"`           // Max number of fetches per request. Default is 5.
+	MaxContentTokens int64     `json:"max_content_tokens,omitzero"` // Max tokens of fetched content to return. Default is 10000.
+	MaxUses          int64     `json:"max_uses,omitzero"`           // Max number of fetches per request. Default is 5.
+	Citations        Citations `json:"citations,omitzero"`
 }
 
 // ChatResponse is the provider-specific chat completion response.
@@ -1306,16 +1754,20 @@ type ChatResponse struct {
 
 // ToResult converts the response to a genai.Result.
 func (c *ChatResponse) ToResult() (genai.Result, error) {
+	fr, errFR := c.StopReason.ToFinishReason()
 	out := genai.Result{
 		Usage: genai.Usage{
 			InputTokens:       c.Usage.InputTokens,
 			InputCachedTokens: c.Usage.CacheReadInputTokens,
 			OutputTokens:      c.Usage.OutputTokens,
-			FinishReason:      c.StopReason.ToFinishReason(),
+			FinishReason:      fr,
+			StopSequence:      c.StopSequence,
 			ServiceTier:       c.Usage.ServiceTier,
+			WebSearchRequests: c.Usage.ServerToolUse.WebSearchRequests,
+			WebFetchRequests:  c.Usage.ServerToolUse.WebFetchRequests,
 		},
 	}
-	err := c.To(&out.Message)
+	err := errors.Join(errFR, c.To(&out.Message))
 	return out, err
 }
 
@@ -1333,25 +1785,24 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (s StopReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (s StopReason) ToFinishReason() (genai.FinishReason, error) {
 	switch s {
 	case StopEndTurn:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case StopToolUse:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case StopSequence:
-		return genai.FinishedStopSequence
+		return genai.FinishedStopSequence, nil
 	case StopMaxTokens:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case StopRefusal:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	case StopPauseTurn:
-		return genai.Pending
+		return genai.FinishedPauseTurn, nil
 	default:
-		if !internal.BeLenient {
-			panic(s)
-		}
-		return genai.FinishReason(s)
+		return genai.FinishReason(s), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", s)}
 	}
 }
 
@@ -1397,6 +1848,9 @@ type ChatStreamChunkResponse struct {
 	Delta StreamDelta `json:"delta"`
 
 	Usage Usage `json:"usage"`
+
+	// Type == ChunkMessageStop, only sent by Bedrock.
+	BedrockInvocationMetrics json.RawMessage `json:"amazon-bedrock-invocationMetrics,omitzero"`
 }
 
 // StreamMessage is the message payload in a message_start streaming chunk.
@@ -1424,6 +1878,9 @@ type StreamContentBlock struct {
 	// Never actually filled but present on content_block_start.
 	Signature []byte `json:"signature"`
 
+	// Type == ContentRedactedThinking
+	Data string `json:"data"`
+
 	// Type == ContentToolUse, ContentMCPToolUse
 	ID     string                     `json:"id"`
 	Name   string                     `json:"name"`
@@ -1592,14 +2049,46 @@ type BatchQueryResponse struct {
 // To converts to the genai equivalent.
 func (b *BatchQueryResponse) To(out *genai.Message) error {
 	// We need to split actual content and tool calls.
+	var errs []error
 	for i := range b.Result.Message.Content {
 		replies, err := b.Result.Message.Content[i].To()
 		if err != nil {
-			return fmt.Errorf("block %d: %w", i, err)
+			err = fmt.Errorf("block %d: %w", i, err)
+			if _, ok := errors.AsType[*base.ErrUnhandled](err); !ok {
+				return err
+			}
+			errs = append(errs, err)
 		}
 		out.Replies = append(out.Replies, replies...)
 	}
-	return nil
+	return errors.Join(errs...)
+}
+
+// ToResult converts the batch result to a genai.Result.
+func (b *BatchQueryResponse) ToResult() (genai.Result, error) {
+	res := genai.Result{}
+	switch b.Result.Type {
+	case "errored":
+		return res, fmt.Errorf("error %s: %s", b.Result.Error.Error.Type, b.Result.Error.Error.Message)
+	case "canceled", "expired":
+		return res, fmt.Errorf("request %s", b.Result.Type)
+	}
+	m := &b.Result.Message
+	err := b.To(&res.Message)
+	res.Usage.InputTokens = m.Usage.InputTokens
+	res.Usage.InputCachedTokens = m.Usage.CacheReadInputTokens
+	res.Usage.OutputTokens = m.Usage.OutputTokens
+	res.Usage.TotalTokens = res.Usage.InputTokens + res.Usage.InputCachedTokens + res.Usage.OutputTokens
+	var errFR error
+	res.Usage.FinishReason, errFR = m.StopReason.ToFinishReason()
+	res.Usage.StopSequence = m.StopSequence
+	res.Usage.ServiceTier = m.Usage.ServiceTier
+	res.Usage.WebSearchRequests = m.Usage.ServerToolUse.WebSearchRequests
+	res.Usage.WebFetchRequests = m.Usage.ServerToolUse.WebFetchRequests
+	if err == nil {
+		err = res.Validate()
+	}
+	return res, errors.Join(err, errFR)
 }
 
 // BatchListResponse is documented at https://docs.anthropic.com/en/api/listing-message-batches
@@ -1627,6 +2116,23 @@ type FileMetadata struct {
 	Downloadable bool      `json:"downloadable"`
 }
 
+// GetID implements genai.CacheEntry.
+func (f *FileMetadata) GetID() string {
+	return f.ID
+}
+
+// GetDisplayName implements genai.CacheEntry.
+func (f *FileMetadata) GetDisplayName() string {
+	return f.Filename
+}
+
+// GetExpiry implements genai.CacheEntry.
+//
+// Files do not expire, it always returns the zero time.
+func (f *FileMetadata) GetExpiry() time.Time {
+	return time.Time{}
+}
+
 // FileListResponse is the response for listing files.
 //
 // https://docs.anthropic.com/en/api/files
@@ -1720,6 +2226,29 @@ func (m *Model) Context() int64 {
 	return m.MaxInputTokens
 }
 
+// MaxOutputTokens implements genai.ModelOutputTokens.
+func (m *Model) MaxOutputTokens() int64 {
+	return m.MaxTokens
+}
+
+// GetModalities implements genai.ModelModalities.
+func (m *Model) GetModalities() (in, out genai.Modalities) {
+	in = genai.Modalities{genai.ModalityText}
+	if m.Capabilities.PDFInput.Supported {
+		in = append(in, genai.ModalityDocument)
+	}
+	if m.Capabilities.ImageInput.Supported {
+		in = append(in, genai.ModalityImage)
+	}
+	slices.Sort(in)
+	return in, genai.Modalities{genai.ModalityText}
+}
+
+// GetCreated implements genai.ModelCreated.
+func (m *Model) GetCreated() time.Time {
+	return m.CreatedAt
+}
+
 // ModelsResponse represents the response structure for Anthropic models listing.
 type ModelsResponse struct {
 	Data    []Model `json:"data"`
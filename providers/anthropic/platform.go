@@ -0,0 +1,208 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Access to Claude models hosted on Google Cloud Vertex AI and AWS Bedrock.
+
+package anthropic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal/eventstream"
+)
+
+// ProviderOptionAuth authenticates the requests sent to the cloud platform selected with
+// genai.ProviderOptionRemote.
+//
+// It is called on each outgoing request and must add the authentication headers, e.g. an OAuth2 access token
+// for Vertex AI or a SigV4 signature for Bedrock. When unspecified, the key provided with ProviderOptionAPIKey
+// is sent as a bearer token.
+type ProviderOptionAuth func(r *http.Request) error
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionAuth) Validate() error {
+	if p == nil {
+		return errors.New("ProviderOptionAuth cannot be nil")
+	}
+	return nil
+}
+
+// platform is a cloud platform hosting Claude models.
+type platform string
+
+const (
+	platformVertex  platform = "Vertex AI"
+	platformBedrock platform = "Bedrock"
+)
+
+// The anthropic_version value expected in the request body by each platform.
+const (
+	vertexVersion  = "vertex-2023-10-16"
+	bedrockVersion = "bedrock-2023-05-31"
+)
+
+// parseRemote returns the platform from a ProviderOptionRemote value.
+//
+// Vertex AI is selected with a location URL like
+// "https://us-east5-aiplatform.googleapis.com/v1/projects/<project>/locations/us-east5". Bedrock is selected
+// with a runtime URL like "https://bedrock-runtime.us-east-1.amazonaws.com".
+func parseRemote(remote string) (platform, string, error) {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid ProviderOptionRemote: %w", err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid ProviderOptionRemote %q: must be an absolute URL", remote)
+	}
+	remote = strings.TrimSuffix(remote, "/")
+	switch {
+	case strings.Contains(u.Path, "/projects/") && strings.Contains(u.Path, "/locations/"):
+		return platformVertex, strings.TrimSuffix(remote, "/publishers/anthropic/models"), nil
+	case strings.Contains(u.Host, "bedrock-runtime"):
+		return platformBedrock, remote, nil
+	default:
+		return "", "", fmt.Errorf("unsupported ProviderOptionRemote %q: expected a Vertex AI location or a Bedrock runtime URL", remote)
+	}
+}
+
+// platformTransport rewrites the Messages API requests to the shape expected by a cloud platform.
+//
+// The platforms take the model in the URL, the API version in the body and don't support the other endpoints.
+type platformTransport struct {
+	platform  platform
+	baseURL   string
+	auth      ProviderOptionAuth
+	transport http.RoundTripper
+}
+
+func (p *platformTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "POST" || req.URL.Path != "/v1/messages" {
+		return nil, &base.ErrNotSupported{Options: []string{fmt.Sprintf("%s %s on %s", req.Method, req.URL.Path, p.platform)}}
+	}
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	_ = req.Body.Close()
+	var model string
+	if err := json.Unmarshal(body["model"], &model); err != nil || model == "" {
+		return nil, errors.New("a model is required")
+	}
+	stream := false
+	if v, ok := body["stream"]; ok {
+		_ = json.Unmarshal(v, &stream)
+	}
+	delete(body, "model")
+	req = req.Clone(req.Context())
+	req.Header.Del("x-api-key")
+	req.Header.Del("anthropic-version")
+	var u string
+	switch p.platform {
+	case platformVertex:
+		body["anthropic_version"] = json.RawMessage(`"` + vertexVersion + `"`)
+		u = p.baseURL + "/publishers/anthropic/models/" + url.PathEscape(model) + ":rawPredict"
+		if stream {
+			u = p.baseURL + "/publishers/anthropic/models/" + url.PathEscape(model) + ":streamRawPredict"
+		}
+	case platformBedrock:
+		body["anthropic_version"] = json.RawMessage(`"` + bedrockVersion + `"`)
+		// Bedrock selects streaming with the URL and takes the betas in the body.
+		delete(body, "stream")
+		if v := req.Header.Get("anthropic-beta"); v != "" {
+			b, err := json.Marshal(strings.Split(v, ","))
+			if err != nil {
+				return nil, err
+			}
+			body["anthropic_beta"] = b
+			req.Header.Del("anthropic-beta")
+		}
+		u = p.baseURL + "/model/" + url.PathEscape(model) + "/invoke"
+		if stream {
+			u = p.baseURL + "/model/" + url.PathEscape(model) + "/invoke-with-response-stream"
+		}
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL, err = url.Parse(u); err != nil {
+		return nil, err
+	}
+	req.Host = ""
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.ContentLength = int64(len(b))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }
+	if err = p.auth(req); err != nil {
+		return nil, fmt.Errorf("failed to authenticate: %w", err)
+	}
+	return p.transport.RoundTrip(req)
+}
+
+func (p *platformTransport) Unwrap() http.RoundTripper {
+	return p.transport
+}
+
+// decodeBedrockStream decodes an InvokeModelWithResponseStream response body. Each event holds a Messages API
+// streaming chunk.
+func decodeBedrockStream(body io.Reader, er error, lenient bool) (iter.Seq[ChatStreamChunkResponse], func() error) {
+	var finalErr error
+	return func(yield func(ChatStreamChunkResponse) bool) {
+			for {
+				hdrs, payload, err := eventstream.ReadMessage(body)
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					finalErr = err
+					return
+				}
+				switch t := hdrs[":message-type"]; t {
+				case "event":
+					var chunk struct {
+						Bytes []byte `json:"bytes"`
+					}
+					if err := json.Unmarshal(payload, &chunk); err != nil {
+						finalErr = fmt.Errorf("failed to decode event %q: %w: %s", hdrs[":event-type"], err, payload)
+						return
+					}
+					var pkt ChatStreamChunkResponse
+					d := json.NewDecoder(bytes.NewReader(chunk.Bytes))
+					if !lenient {
+						d.DisallowUnknownFields()
+					}
+					if err := d.Decode(&pkt); err != nil {
+						finalErr = fmt.Errorf("failed to decode chunk: %w: %s", err, chunk.Bytes)
+						return
+					}
+					if !yield(pkt) {
+						return
+					}
+				case "exception":
+					var e struct {
+						Message string `json:"message"`
+					}
+					_ = json.Unmarshal(payload, &e)
+					finalErr = fmt.Errorf("%s: %s", hdrs[":exception-type"], e.Message)
+					return
+				case "error":
+					finalErr = fmt.Errorf("%s: %s", hdrs[":error-code"], hdrs[":error-message"])
+					return
+				default:
+					finalErr = fmt.Errorf("unexpected message type %q", t)
+					return
+				}
+			}
+		}, func() error {
+			return finalErr
+		}
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package azureopenai implements a client for Azure OpenAI deployments.
+//
+// Azure serves the OpenAI Chat Completions API with a different URL shape, an api-version query parameter
+// and its own authentication. The wire format is shared with package openaichat.
+//
+// It is described at https://learn.microsoft.com/azure/ai-foundry/openai/reference
+package azureopenai
+
+// See official client at https://github.com/openai/openai-go/tree/main/azure
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/maruel/roundtrippers"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal/bearer"
+	"github.com/maruel/genai/providers/openaibase"
+	"github.com/maruel/genai/providers/openaichat"
+	"github.com/maruel/genai/scoreboard"
+)
+
+//go:embed scoreboard.json
+var scoreboardJSON []byte
+
+// Scoreboard for Azure OpenAI.
+func Scoreboard() scoreboard.Score {
+	var s scoreboard.Score
+	d := json.NewDecoder(bytes.NewReader(scoreboardJSON))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&s); err != nil {
+		panic(fmt.Errorf("failed to unmarshal scoreboard.json: %w", err))
+	}
+	return s
+}
+
+// DefaultAPIVersion is the api-version used when neither ProviderOptionAPIVersion nor the OPENAI_API_VERSION
+// environment variable is set.
+//
+// See https://learn.microsoft.com/azure/ai-foundry/openai/api-version-lifecycle
+const DefaultAPIVersion = "2024-10-21"
+
+// Scope is the Microsoft Entra ID scope to request tokens for.
+const Scope = "https://cognitiveservices.azure.com/.default"
+
+// ProviderOptionAPIVersion is the api-version query parameter, e.g. "2024-10-21" or "2025-04-01-preview".
+type ProviderOptionAPIVersion string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionAPIVersion) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionAPIVersion cannot be empty")
+	}
+	return nil
+}
+
+// Token is an OAuth2 access token.
+type Token = bearer.Token
+
+// ProviderOptionTokenSource returns Microsoft Entra ID (formerly Azure AD) access tokens for Scope, sent as
+// Bearer tokens. It is called before each request; the client caches the returned token until it is about
+// to expire.
+//
+// It can wrap azidentity.Credential.GetToken from the Azure SDK.
+type ProviderOptionTokenSource func(ctx context.Context) (Token, error)
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionTokenSource) Validate() error {
+	if p == nil {
+		return errors.New("ProviderOptionTokenSource cannot be nil")
+	}
+	return nil
+}
+
+// Client implements genai.Provider.
+type Client struct {
+	base.NotImplemented
+	impl base.Provider[*openaichat.ErrorResponse, *openaichat.ChatRequest, *openaichat.ChatResponse, openaichat.ChatStreamChunkResponse]
+}
+
+// New creates a new client to talk to an Azure OpenAI resource.
+//
+// ProviderOptionRemote is the resource endpoint, e.g. "https://my-resource.openai.azure.com". It defaults to
+// the AZURE_OPENAI_ENDPOINT environment variable.
+//
+// ProviderOptionModel is the deployment name, which is chosen when deploying a model and may differ from the
+// model name. Automatic model selection via ModelCheap, ModelGood, ModelSOTA is not supported since
+// deployments are specific to each resource.
+//
+// Authentication is done in order with:
+//   - ProviderOptionAPIKey, sent in the api-key header.
+//   - ProviderOptionTokenSource, for Microsoft Entra ID.
+//   - The AZURE_OPENAI_API_KEY environment variable.
+//
+// If none is found, it will still return a client coupled with an base.ErrAPIKeyRequired error.
+//
+// To use multiple deployments, create multiple clients.
+//
+// The proxy defaults to the AZURE_OPENAI_PROXY environment variable, see genai.ProviderOptionProxyURL.
+func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
+	var apiKey, model, remote, apiVersion string
+	var ts ProviderOptionTokenSource
+	var modalities genai.Modalities
+	var preloadedModels []genai.Model
+	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
+	if err := base.CheckDuplicateOptions(opts); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return nil, err
+		}
+		switch v := opt.(type) {
+		case genai.ProviderOptionAPIKey:
+			apiKey = string(v)
+		case genai.ProviderOptionModel:
+			model = string(v)
+		case genai.ProviderOptionModalities:
+			modalities = genai.Modalities(v)
+		case genai.ProviderOptionPreloadedModels:
+			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionTransportWrapper:
+			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case genai.ProviderOptionRemote:
+			remote = string(v)
+		case ProviderOptionAPIVersion:
+			apiVersion = string(v)
+		case ProviderOptionTokenSource:
+			ts = v
+		default:
+			return nil, fmt.Errorf("unsupported option type %T", opt)
+		}
+	}
+	mod := genai.Modalities{genai.ModalityText}
+	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
+		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", modalities)
+	}
+	switch model {
+	case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
+		return nil, fmt.Errorf("automatic model selection is not supported; set ProviderOptionModel to a deployment name")
+	}
+	if remote == "" {
+		if remote = os.Getenv("AZURE_OPENAI_ENDPOINT"); remote == "" {
+			return nil, errors.New("option ProviderOptionRemote is required; set it or environment variable AZURE_OPENAI_ENDPOINT to the resource endpoint")
+		}
+	}
+	if apiVersion == "" {
+		if apiVersion = os.Getenv("OPENAI_API_VERSION"); apiVersion == "" {
+			apiVersion = DefaultAPIVersion
+		}
+	}
+	const apiKeyURL = "https://portal.azure.com/#view/Microsoft_Azure_ProjectOxford/CognitiveServicesHub/~/OpenAI"
+	var err error
+	if apiKey == "" && ts == nil {
+		if apiKey = os.Getenv("AZURE_OPENAI_API_KEY"); apiKey == "" {
+			err = &base.ErrAPIKeyRequired{EnvVar: "AZURE_OPENAI_API_KEY", URL: apiKeyURL}
+		}
+	}
+	t := base.NewTransport(httpOpts, proxyURL, "AZURE_OPENAI_PROXY")
+	if wrapper != nil {
+		t = wrapper(t)
+	}
+	if apiKey != "" {
+		t = &roundtrippers.Header{Header: http.Header{"api-key": {apiKey}}, Transport: t}
+	} else if ts != nil {
+		t = &bearer.Transport{Source: ts, Transport: t}
+	}
+	c := &Client{
+		impl: base.Provider[*openaichat.ErrorResponse, *openaichat.ChatRequest, *openaichat.ChatResponse, openaichat.ChatStreamChunkResponse]{
+			ProcessStream:   openaichat.ProcessStream,
+			ProcessHeaders:  openaibase.ProcessHeaders,
+			PreloadedModels: preloadedModels,
+			SchemaEnforced:  true,
+			ProviderBase: base.ProviderBase[*openaichat.ErrorResponse]{
+				Model:            model,
+				OutputModalities: mod,
+				APIKeyURL:        apiKeyURL,
+				Lenient:          lenient,
+				Log:              logger,
+				Client:           http.Client{Transport: &roundtrippers.RequestID{Transport: t}},
+			},
+		},
+	}
+	if model != "" {
+		c.impl.GenSyncURL = strings.TrimRight(remote, "/") + "/openai/deployments/" + url.PathEscape(model) + "/chat/completions?api-version=" + url.QueryEscape(apiVersion)
+	}
+	return c, err
+}
+
+// Name implements genai.Provider.
+//
+// It returns the name of the provider.
+func (c *Client) Name() string {
+	return "azureopenai"
+}
+
+// ModelID implements genai.Provider.
+//
+// It returns the deployment name.
+func (c *Client) ModelID() string {
+	return c.impl.Model
+}
+
+// OutputModalities implements genai.Provider.
+//
+// It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
+// video, etc).
+func (c *Client) OutputModalities() genai.Modalities {
+	return c.impl.OutputModalities
+}
+
+// Scoreboard implements genai.Provider.
+func (c *Client) Scoreboard() scoreboard.Score {
+	return Scoreboard()
+}
+
+// HTTPClient returns the HTTP client to fetch results (e.g. videos) generated by the provider.
+func (c *Client) HTTPClient() *http.Client {
+	return &c.impl.Client
+}
+
+// GenSync implements genai.Provider.
+func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	return c.impl.GenSync(ctx, msgs, opts...)
+}
+
+// GenSyncRaw provides access to the raw API.
+func (c *Client) GenSyncRaw(ctx context.Context, in *openaichat.ChatRequest, out *openaichat.ChatResponse) error {
+	return c.impl.GenSyncRaw(ctx, in, out)
+}
+
+// GenStream implements genai.Provider.
+func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	return c.impl.GenStream(ctx, msgs, opts...)
+}
+
+// GenStreamRaw provides access to the raw API.
+func (c *Client) GenStreamRaw(ctx context.Context, in *openaichat.ChatRequest) (iter.Seq[openaichat.ChatStreamChunkResponse], func() error) {
+	return c.impl.GenStreamRaw(ctx, in)
+}
+
+var _ genai.Provider = &Client{}
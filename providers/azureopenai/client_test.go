@@ -0,0 +1,126 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the Azure OpenAI client.
+
+package azureopenai_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/providers/azureopenai"
+	"github.com/maruel/genai/providers/openaichat"
+)
+
+func TestClient(t *testing.T) {
+	const endpoint = "https://my-resource.openai.azure.com/"
+	newClient := func(t *testing.T, auth genai.ProviderOption, h func(r *http.Request)) *azureopenai.Client {
+		wrapper := genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+			return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				h(r)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4o-mini-2024-07-18","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":8,"completion_tokens":2,"total_tokens":10}}`)),
+					Request:    r,
+				}, nil
+			})
+		})
+		opts := []genai.ProviderOption{genai.ProviderOptionRemote(endpoint), genai.ProviderOptionModel("my-gpt"), wrapper}
+		if auth != nil {
+			opts = append(opts, auth)
+		}
+		c, err := azureopenai.New(t.Context(), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	msgs := genai.Messages{genai.NewTextMessage("Hello")}
+
+	t.Run("api-key", func(t *testing.T) {
+		c := newClient(t, genai.ProviderOptionAPIKey("key"), func(r *http.Request) {
+			if got, want := r.URL.String(), "https://my-resource.openai.azure.com/openai/deployments/my-gpt/chat/completions?api-version="+azureopenai.DefaultAPIVersion; got != want {
+				t.Errorf("unexpected URL\nwant %s\ngot  %s", want, got)
+			}
+			if got := r.Header.Get("api-key"); got != "key" {
+				t.Errorf("unexpected api-key %q", got)
+			}
+			var in openaichat.ChatRequest
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				t.Error(err)
+			}
+			if in.Model != "my-gpt" || len(in.Messages) != 1 {
+				t.Errorf("unexpected request %+v", in)
+			}
+		})
+		if c.ModelID() != "my-gpt" {
+			t.Errorf("unexpected model %q", c.ModelID())
+		}
+		res, err := c.GenSync(t.Context(), msgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.String(); got != "Hi" {
+			t.Errorf("unexpected reply %q", got)
+		}
+		if res.Usage.InputTokens != 8 || res.Usage.OutputTokens != 2 || res.Usage.FinishReason != genai.FinishedStop {
+			t.Errorf("unexpected usage %+v", res.Usage)
+		}
+	})
+
+	t.Run("token source", func(t *testing.T) {
+		calls := 0
+		ts := azureopenai.ProviderOptionTokenSource(func(ctx context.Context) (azureopenai.Token, error) {
+			calls++
+			return azureopenai.Token{AccessToken: "tok", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		t.Setenv("OPENAI_API_VERSION", "2025-04-01-preview")
+		c := newClient(t, ts, func(r *http.Request) {
+			if got := r.URL.Query().Get("api-version"); got != "2025-04-01-preview" {
+				t.Errorf("unexpected api-version %q", got)
+			}
+			if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+				t.Errorf("unexpected Authorization %q", got)
+			}
+			if got := r.Header.Get("api-key"); got != "" {
+				t.Errorf("unexpected api-key %q", got)
+			}
+		})
+		for range 2 {
+			if _, err := c.GenSync(t.Context(), msgs); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected the token to be cached, got %d calls", calls)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("AZURE_OPENAI_API_KEY", "")
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "")
+	if _, err := azureopenai.New(t.Context(), genai.ProviderOptionAPIKey("key")); err == nil || !strings.Contains(err.Error(), "AZURE_OPENAI_ENDPOINT") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	remote := genai.ProviderOptionRemote("https://my-resource.openai.azure.com")
+	if _, err := azureopenai.New(t.Context(), remote, genai.ProviderOptionModel(string(genai.ModelCheap))); err == nil || !strings.Contains(err.Error(), "deployment name") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c, err := azureopenai.New(t.Context(), remote); c == nil || err == nil || !strings.Contains(err.Error(), "AZURE_OPENAI_API_KEY") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := azureopenai.New(t.Context(), remote, azureopenai.ProviderOptionAPIVersion("")); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -65,11 +65,19 @@ type Client struct {
 //
 // To use multiple models, create multiple clients.
 // Use one of the models from https://docs.baseten.co/development/model-apis/overview
+//
+// The proxy defaults to the BASETEN_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -86,8 +94,20 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -103,7 +123,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "BASETEN_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -113,10 +133,13 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			ProcessStream:   ProcessStream,
 			ProcessHeaders:  processHeaders,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			LieToolCalls:    true,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					// Baseten uses "Api-Key" prefix instead of "Bearer".
 					Transport: &roundtrippers.Header{
@@ -131,7 +154,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		switch model {
 		case "":
 		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
-			if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 				return nil, err
 			}
 			c.impl.OutputModalities = mod
@@ -152,6 +175,9 @@ func (c *Client) selectBestTextModel(ctx context.Context, preference string) (st
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	// Find the reference model from the scoreboard.
 	s := Scoreboard()
 	var ref string
@@ -239,6 +265,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 func (c *Client) OutputModalities() genai.Modalities {
 	return c.impl.OutputModalities
@@ -316,7 +347,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.TotalTokens = pkt.Usage.TotalTokens
 				}
 				if fr := pkt.Choices[0].FinishReason; fr != "" {
-					u.FinishReason = fr.ToFinishReason()
+					var err error
+					if u.FinishReason, err = fr.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 
 				for _, nt := range pkt.Choices[0].Delta.ToolCalls {
@@ -417,4 +451,7 @@ func enableThinkingByDefault(model string) bool {
 	return ok && v.(bool)
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+)
@@ -15,7 +15,6 @@ import (
 	"testing"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/baseten"
 	"github.com/maruel/genai/scoreboard"
@@ -36,6 +35,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return baseten.New(t.Context(), opts...)
 }
 
@@ -107,6 +107,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				provOpts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, provOpts...)
 			}
+			provOpts = append(provOpts, genai.ProviderOptionStrict(true))
 			c, err2 := baseten.New(t.Context(), provOpts...)
 			if err2 != nil {
 				t.Fatal(err2)
@@ -258,7 +259,3 @@ func TestGenOption(t *testing.T) {
 		}
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
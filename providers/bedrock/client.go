@@ -0,0 +1,405 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package bedrock implements a client for AWS Bedrock.
+//
+// It uses the Converse API which exposes the models from Anthropic, Meta, Mistral, Amazon and others through
+// a single message format.
+//
+// It is described at https://docs.aws.amazon.com/bedrock/latest/APIReference/welcome.html
+package bedrock
+
+// See official client at https://github.com/aws/aws-sdk-go-v2/tree/main/service/bedrockruntime
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/maruel/roundtrippers"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/scoreboard"
+)
+
+//go:embed scoreboard.json
+var scoreboardJSON []byte
+
+// Scoreboard for AWS Bedrock.
+func Scoreboard() scoreboard.Score {
+	var s scoreboard.Score
+	d := json.NewDecoder(bytes.NewReader(scoreboardJSON))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&s); err != nil {
+		panic(fmt.Errorf("failed to unmarshal scoreboard.json: %w", err))
+	}
+	return s
+}
+
+// ProviderOptionRegion is the AWS region to use, e.g. "us-east-1" or "eu-central-1".
+//
+// It defaults to the AWS_REGION environment variable, then AWS_DEFAULT_REGION, then "us-east-1".
+type ProviderOptionRegion string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionRegion) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionRegion cannot be empty")
+	}
+	for _, c := range p {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return fmt.Errorf("invalid ProviderOptionRegion %q", string(p))
+		}
+	}
+	return nil
+}
+
+// ProviderOptionCredentials are AWS credentials used to sign requests with Signature Version 4.
+//
+// It defaults to the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+type ProviderOptionCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is only needed for temporary credentials.
+	SessionToken string
+}
+
+// Validate implements genai.ProviderOption.
+func (p *ProviderOptionCredentials) Validate() error {
+	if p.AccessKeyID == "" {
+		return errors.New("field AccessKeyID is required")
+	}
+	if p.SecretAccessKey == "" {
+		return errors.New("field SecretAccessKey is required")
+	}
+	return nil
+}
+
+// Client implements genai.Provider.
+type Client struct {
+	base.NotImplemented
+	impl base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]
+	// runtimeURL is the bedrock-runtime endpoint, used for inference.
+	runtimeURL string
+	// controlURL is the bedrock endpoint, used for model listing.
+	controlURL string
+}
+
+// New creates a new client to talk to AWS Bedrock.
+//
+// Authentication is done in order with:
+//   - ProviderOptionAPIKey, a Bedrock API key sent as a Bearer token.
+//   - ProviderOptionCredentials, to sign requests with Signature Version 4.
+//   - The AWS_BEARER_TOKEN_BEDROCK environment variable.
+//   - The AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+//
+// If no credentials are found, it will still return a client coupled with an base.ErrAPIKeyRequired error.
+// Get an API key at https://console.aws.amazon.com/bedrock/home#/api-keys
+//
+// Use ProviderOptionRegion to select the region. ProviderOptionRemote overrides the bedrock-runtime endpoint,
+// e.g. for VPC endpoints.
+//
+// Use a model ID or an inference profile ID from
+// https://docs.aws.amazon.com/bedrock/latest/userguide/models-supported.html
+// Many models are only available through cross-region inference profiles like
+// "us.anthropic.claude-sonnet-4-20250514-v1:0".
+//
+// To use multiple models, create multiple clients.
+//
+// The proxy defaults to the BEDROCK_PROXY environment variable, see genai.ProviderOptionProxyURL.
+func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
+	var apiKey, model, remote, region string
+	var creds *ProviderOptionCredentials
+	var modalities genai.Modalities
+	var preloadedModels []genai.Model
+	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
+	if err := base.CheckDuplicateOptions(opts); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return nil, err
+		}
+		switch v := opt.(type) {
+		case genai.ProviderOptionAPIKey:
+			apiKey = string(v)
+		case genai.ProviderOptionModel:
+			model = string(v)
+		case genai.ProviderOptionModalities:
+			modalities = genai.Modalities(v)
+		case genai.ProviderOptionPreloadedModels:
+			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionTransportWrapper:
+			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case genai.ProviderOptionRemote:
+			remote = string(v)
+		case ProviderOptionRegion:
+			region = string(v)
+		case *ProviderOptionCredentials:
+			creds = v
+		default:
+			return nil, fmt.Errorf("unsupported option type %T", opt)
+		}
+	}
+	mod := genai.Modalities{genai.ModalityText}
+	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
+		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", modalities)
+	}
+	const apiKeyURL = "https://console.aws.amazon.com/bedrock/home#/api-keys"
+	var err error
+	if apiKey == "" && creds == nil {
+		if apiKey = os.Getenv("AWS_BEARER_TOKEN_BEDROCK"); apiKey == "" {
+			if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+				creds = &ProviderOptionCredentials{
+					AccessKeyID:     id,
+					SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+					SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+				}
+				err = creds.Validate()
+			} else {
+				err = &base.ErrAPIKeyRequired{EnvVar: "AWS_BEARER_TOKEN_BEDROCK", URL: apiKeyURL}
+			}
+		}
+	}
+	if region == "" {
+		if region = os.Getenv("AWS_REGION"); region == "" {
+			if region = os.Getenv("AWS_DEFAULT_REGION"); region == "" {
+				region = "us-east-1"
+			}
+		}
+	}
+	c := &Client{
+		runtimeURL: "https://bedrock-runtime." + region + ".amazonaws.com",
+		controlURL: "https://bedrock." + region + ".amazonaws.com",
+	}
+	if remote != "" {
+		c.runtimeURL = strings.TrimRight(remote, "/")
+	}
+	t := base.NewTransport(httpOpts, proxyURL, "BEDROCK_PROXY")
+	if wrapper != nil {
+		t = wrapper(t)
+	}
+	if apiKey != "" {
+		t = &roundtrippers.Header{Header: http.Header{"Authorization": {"Bearer " + apiKey}}, Transport: t}
+	} else if creds != nil {
+		// Sign last so the signature covers the final request. Both endpoints use the "bedrock" signing name.
+		t = &sigV4Transport{creds: *creds, region: region, service: "bedrock", transport: t}
+	}
+	c.impl = base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]{
+		ProcessStream:   ProcessStream,
+		DecodeStream:    DecodeStream,
+		PreloadedModels: preloadedModels,
+		ProviderBase: base.ProviderBase[*ErrorResponse]{
+			APIKeyURL: apiKeyURL,
+			Lenient:   lenient,
+			Log:       logger,
+			Client:    http.Client{Transport: &roundtrippers.RequestID{Transport: t}},
+		},
+	}
+	if err == nil {
+		switch model {
+		case "":
+		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
+			if c.impl.Model, err = selectBestModel(model); err != nil {
+				return nil, err
+			}
+			c.impl.OutputModalities = mod
+		default:
+			c.impl.Model = model
+			c.impl.OutputModalities = mod
+		}
+		if c.impl.Model != "" {
+			c.impl.GenSyncURL = c.modelURL("/converse")
+			c.impl.GenStreamURL = c.modelURL("/converse-stream")
+		}
+	}
+	return c, err
+}
+
+// selectBestModel returns the reference model for the preference (cheap, good, or SOTA) from the
+// scoreboard.
+//
+// Listing the models doesn't help since availability depends on the model access granted to the account.
+func selectBestModel(preference string) (string, error) {
+	for _, sc := range Scoreboard().Scenarios {
+		if len(sc.Models) == 0 {
+			continue
+		}
+		switch preference {
+		case string(genai.ModelCheap):
+			if sc.Cheap {
+				return sc.Models[0], nil
+			}
+		case string(genai.ModelGood):
+			if sc.Good {
+				return sc.Models[0], nil
+			}
+		default:
+			if sc.SOTA {
+				return sc.Models[0], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to find a %s model", preference)
+}
+
+// modelURL returns the URL for a method on the selected model.
+//
+// Model IDs contain a colon which AWS expects to be escaped.
+func (c *Client) modelURL(method string) string {
+	return c.runtimeURL + "/model/" + uriEncode(c.impl.Model) + method
+}
+
+// Name implements genai.Provider.
+//
+// It returns the name of the provider.
+func (c *Client) Name() string {
+	return "bedrock"
+}
+
+// ModelID implements genai.Provider.
+//
+// It returns the selected model ID.
+func (c *Client) ModelID() string {
+	return c.impl.Model
+}
+
+// OutputModalities implements genai.Provider.
+//
+// It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
+// video, etc).
+func (c *Client) OutputModalities() genai.Modalities {
+	return c.impl.OutputModalities
+}
+
+// Scoreboard implements genai.Provider.
+func (c *Client) Scoreboard() scoreboard.Score {
+	return Scoreboard()
+}
+
+// HTTPClient returns the HTTP client to fetch results (e.g. videos) generated by the provider.
+func (c *Client) HTTPClient() *http.Client {
+	return &c.impl.Client
+}
+
+// GenSync implements genai.Provider.
+func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	return c.impl.GenSync(ctx, msgs, opts...)
+}
+
+// GenSyncRaw provides access to the raw API.
+func (c *Client) GenSyncRaw(ctx context.Context, in *ChatRequest, out *ChatResponse) error {
+	return c.impl.GenSyncRaw(ctx, in, out)
+}
+
+// GenStream implements genai.Provider.
+func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	return c.impl.GenStream(ctx, msgs, opts...)
+}
+
+// GenStreamRaw provides access to the raw API.
+func (c *Client) GenStreamRaw(ctx context.Context, in *ChatRequest) (iter.Seq[ChatStreamChunkResponse], func() error) {
+	return c.impl.GenStreamRaw(ctx, in)
+}
+
+// ListModels implements genai.Provider.
+//
+// It lists the foundation models available in the region. Access to each model must be granted separately in
+// the AWS console.
+func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
+	if c.impl.PreloadedModels != nil {
+		return c.impl.PreloadedModels, nil
+	}
+	// https://docs.aws.amazon.com/bedrock/latest/APIReference/API_ListFoundationModels.html
+	var resp ModelsResponse
+	if err := c.impl.DoRequest(ctx, "GET", c.controlURL+"/foundation-models", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.ToModels(), nil
+}
+
+// ProcessStream converts the raw packets from the streaming API into Reply fragments.
+func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error)) {
+	var finalErr error
+	u := genai.Usage{}
+
+	return func(yield func(genai.Reply) bool) {
+			pendingToolCall := genai.ToolCall{}
+			for pkt := range chunks {
+				f := genai.Reply{}
+				switch pkt.Type {
+				case EventMessageStart:
+					if pkt.Role != "assistant" {
+						finalErr = &internal.BadError{Err: fmt.Errorf("unexpected role %q", pkt.Role)}
+						return
+					}
+				case EventContentBlockStart:
+					pendingToolCall = genai.ToolCall{ID: pkt.Start.ToolUse.ToolUseID, Name: pkt.Start.ToolUse.Name}
+				case EventContentBlockDelta:
+					d := &pkt.Delta
+					switch {
+					case d.ToolUse.Input != "":
+						pendingToolCall.Arguments += d.ToolUse.Input
+					case d.ReasoningContent.Text != "":
+						f.Reasoning = d.ReasoningContent.Text
+					case d.ReasoningContent.Signature != "":
+						f.Opaque = map[string]any{"signature": d.ReasoningContent.Signature}
+					case len(d.ReasoningContent.RedactedContent) != 0:
+						f.Opaque = map[string]any{"redacted_content": d.ReasoningContent.RedactedContent}
+					default:
+						f.Text = d.Text
+					}
+				case EventContentBlockStop:
+					if !pendingToolCall.IsZero() {
+						if pendingToolCall.Arguments == "" {
+							// Tools without arguments don't send a delta.
+							pendingToolCall.Arguments = "{}"
+						}
+						f.ToolCall = pendingToolCall
+						pendingToolCall = genai.ToolCall{}
+					}
+				case EventMessageStop:
+					var err error
+					if u.FinishReason, err = pkt.StopReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
+				case EventMetadata:
+					fr := u.FinishReason
+					u = pkt.Usage.To()
+					u.FinishReason = fr
+				default:
+					finalErr = errors.Join(finalErr, &base.ErrUnhandled{Err: fmt.Errorf("unknown event %q", pkt.Type)})
+				}
+				if !yield(f) {
+					return
+				}
+			}
+		}, func() (genai.Usage, [][]genai.Logprob, error) {
+			return u, nil, finalErr
+		}
+}
+
+var _ genai.Provider = &Client{}
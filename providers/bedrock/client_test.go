@@ -0,0 +1,213 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the AWS Bedrock client.
+
+package bedrock_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/providers/bedrock"
+)
+
+func TestScoreboard(t *testing.T) {
+	s := bedrock.Scoreboard()
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	const model = "us.anthropic.claude-sonnet-4-20250514-v1:0"
+	const base = "https://bedrock-runtime.eu-west-3.amazonaws.com/model/us.anthropic.claude-sonnet-4-20250514-v1%3A0"
+	newClient := func(t *testing.T, auth genai.ProviderOption, h func(r *http.Request) *http.Response) *bedrock.Client {
+		wrapper := genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+			return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				resp := h(r)
+				resp.Request = r
+				return resp, nil
+			})
+		})
+		c, err := bedrock.New(t.Context(), genai.ProviderOptionModel(model), bedrock.ProviderOptionRegion("eu-west-3"), auth, wrapper)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	msgs := genai.Messages{genai.NewTextMessage("Hello")}
+
+	t.Run("GenSync", func(t *testing.T) {
+		c := newClient(t, &bedrock.ProviderOptionCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, func(r *http.Request) *http.Response {
+			if got := r.URL.String(); got != base+"/converse" {
+				t.Errorf("unexpected URL %s", got)
+			}
+			if got := r.Header.Get("Authorization"); !strings.HasPrefix(got, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(got, "/eu-west-3/bedrock/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=") {
+				t.Errorf("unexpected Authorization %q", got)
+			}
+			var in bedrock.ChatRequest
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				t.Error(err)
+			}
+			if len(in.Messages) != 1 || in.Messages[0].Role != "user" || in.Messages[0].Content[0].Text != "Hello" {
+				t.Errorf("unexpected request %+v", in)
+			}
+			return jsonResponse(http.StatusOK, `{"output":{"message":{"role":"assistant","content":[{"text":"Hi"}]}},"stopReason":"end_turn","usage":{"inputTokens":8,"outputTokens":2,"totalTokens":10},"metrics":{"latencyMs":300}}`)
+		})
+		res, err := c.GenSync(t.Context(), msgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.String(); got != "Hi" {
+			t.Errorf("unexpected reply %q", got)
+		}
+		if res.Usage.InputTokens != 8 || res.Usage.OutputTokens != 2 || res.Usage.FinishReason != genai.FinishedStop {
+			t.Errorf("unexpected usage %+v", res.Usage)
+		}
+	})
+
+	t.Run("GenStream", func(t *testing.T) {
+		events := []struct {
+			typ     string
+			payload string
+		}{
+			{"messageStart", `{"role":"assistant","p":"abcd"}`},
+			{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"Let me "},"p":"ab"}`},
+			{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"check."}}`},
+			{"contentBlockStop", `{"contentBlockIndex":0}`},
+			{"contentBlockStart", `{"contentBlockIndex":1,"start":{"toolUse":{"toolUseId":"tooluse_1","name":"square_root"}}}`},
+			{"contentBlockDelta", `{"contentBlockIndex":1,"delta":{"toolUse":{"input":"{\"number\":"}}}`},
+			{"contentBlockDelta", `{"contentBlockIndex":1,"delta":{"toolUse":{"input":"132413}"}}}`},
+			{"contentBlockStop", `{"contentBlockIndex":1}`},
+			{"messageStop", `{"stopReason":"tool_use"}`},
+			{"metadata", `{"usage":{"inputTokens":20,"outputTokens":10,"totalTokens":30},"metrics":{"latencyMs":500}}`},
+		}
+		var body bytes.Buffer
+		for _, e := range events {
+			writeMessage(&body, map[string]string{":message-type": "event", ":event-type": e.typ, ":content-type": "application/json"}, e.payload)
+		}
+		c := newClient(t, genai.ProviderOptionAPIKey("key"), func(r *http.Request) *http.Response {
+			if got := r.URL.String(); got != base+"/converse-stream" {
+				t.Errorf("unexpected URL %s", got)
+			}
+			if got := r.Header.Get("Authorization"); got != "Bearer key" {
+				t.Errorf("unexpected Authorization %q", got)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/vnd.amazon.eventstream"}},
+				Body:       io.NopCloser(&body),
+			}
+		})
+		fragments, finish := c.GenStream(t.Context(), msgs)
+		for range fragments {
+		}
+		res, err := finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Replies) != 2 || res.Replies[0].Text != "Let me check." {
+			t.Fatalf("unexpected replies %+v", res.Replies)
+		}
+		want := genai.ToolCall{ID: "tooluse_1", Name: "square_root", Arguments: `{"number":132413}`}
+		if got := res.Replies[1].ToolCall; got.ID != want.ID || got.Name != want.Name || got.Arguments != want.Arguments {
+			t.Errorf("unexpected tool call %+v", got)
+		}
+		if res.Usage.InputTokens != 20 || res.Usage.OutputTokens != 10 || res.Usage.FinishReason != genai.FinishedToolCalls {
+			t.Errorf("unexpected usage %+v", res.Usage)
+		}
+	})
+
+	t.Run("GenStream/exception", func(t *testing.T) {
+		var body bytes.Buffer
+		writeMessage(&body, map[string]string{":message-type": "event", ":event-type": "messageStart"}, `{"role":"assistant"}`)
+		writeMessage(&body, map[string]string{":message-type": "exception", ":exception-type": "throttlingException"}, `{"message":"Too many requests"}`)
+		c := newClient(t, genai.ProviderOptionAPIKey("key"), func(r *http.Request) *http.Response {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(&body)}
+		})
+		fragments, finish := c.GenStream(t.Context(), msgs)
+		for range fragments {
+		}
+		if _, err := finish(); err == nil || err.Error() != "throttlingException: Too many requests" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c := newClient(t, genai.ProviderOptionAPIKey("key"), func(r *http.Request) *http.Response {
+			return jsonResponse(http.StatusBadRequest, `{"message":"The provided model identifier is invalid."}`)
+		})
+		if _, err := c.GenSync(t.Context(), msgs); err == nil || !strings.Contains(err.Error(), "The provided model identifier is invalid.") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ListModels", func(t *testing.T) {
+		c := newClient(t, genai.ProviderOptionAPIKey("key"), func(r *http.Request) *http.Response {
+			if got := r.URL.String(); got != "https://bedrock.eu-west-3.amazonaws.com/foundation-models" {
+				t.Errorf("unexpected URL %s", got)
+			}
+			return jsonResponse(http.StatusOK, `{"modelSummaries":[{"modelArn":"arn:aws:bedrock:eu-west-3::foundation-model/mistral.mistral-large-2402-v1:0","modelId":"mistral.mistral-large-2402-v1:0","modelName":"Mistral Large (24.02)","providerName":"Mistral AI","inputModalities":["TEXT"],"outputModalities":["TEXT"],"responseStreamingSupported":true,"customizationsSupported":[],"inferenceTypesSupported":["ON_DEMAND"],"modelLifecycle":{"status":"ACTIVE"}}]}`)
+		})
+		models, err := c.ListModels(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(models) != 1 || models[0].GetID() != "mistral.mistral-large-2402-v1:0" {
+			t.Fatalf("unexpected models %v", models)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("AWS_BEARER_TOKEN_BEDROCK", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	if _, err := bedrock.New(t.Context()); err == nil || !strings.Contains(err.Error(), "AWS_BEARER_TOKEN_BEDROCK") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := bedrock.New(t.Context(), bedrock.ProviderOptionRegion("US_EAST")); err == nil || err.Error() != `invalid ProviderOptionRegion "US_EAST"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := bedrock.New(t.Context(), &bedrock.ProviderOptionCredentials{AccessKeyID: "AKID"}); err == nil || err.Error() != "field SecretAccessKey is required" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// writeMessage encodes an event stream message with string headers.
+func writeMessage(w *bytes.Buffer, hdrs map[string]string, payload string) {
+	var h bytes.Buffer
+	for k, v := range hdrs {
+		h.WriteByte(byte(len(k)))
+		h.WriteString(k)
+		h.WriteByte(7)
+		_ = binary.Write(&h, binary.BigEndian, uint16(len(v)))
+		h.WriteString(v)
+	}
+	var msg bytes.Buffer
+	total := uint32(12 + h.Len() + len(payload) + 4)
+	_ = binary.Write(&msg, binary.BigEndian, total)
+	_ = binary.Write(&msg, binary.BigEndian, uint32(h.Len()))
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(h.Bytes())
+	msg.WriteString(payload)
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	w.Write(msg.Bytes())
+}
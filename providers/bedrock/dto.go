@@ -0,0 +1,680 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Wire types for the AWS Bedrock Converse API.
+//
+// API reference: https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_Converse.html
+
+package bedrock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal"
+)
+
+// ChatRequest is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_Converse.html
+//
+// The same request is used for ConverseStream.
+type ChatRequest struct {
+	Messages        []Message       `json:"messages"`
+	System          []SystemContent `json:"system,omitzero"`
+	InferenceConfig struct {
+		MaxTokens     int64    `json:"maxTokens,omitzero"`
+		Temperature   float64  `json:"temperature,omitzero"`
+		TopP          float64  `json:"topP,omitzero"`
+		StopSequences []string `json:"stopSequences,omitzero"`
+	} `json:"inferenceConfig,omitzero"`
+	ToolConfig                        ToolConfig      `json:"toolConfig,omitzero"`
+	GuardrailConfig                   json.RawMessage `json:"guardrailConfig,omitzero"`
+	AdditionalModelRequestFields      map[string]any  `json:"additionalModelRequestFields,omitzero"`
+	AdditionalModelResponseFieldPaths []string        `json:"additionalModelResponseFieldPaths,omitzero"`
+	PerformanceConfig                 struct {
+		Latency string `json:"latency,omitzero"` // "standard", "optimized"
+	} `json:"performanceConfig,omitzero"`
+	RequestMetadata map[string]string `json:"requestMetadata,omitzero"`
+}
+
+// Init implements base.InitializableRequest.
+func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenOption) error {
+	if err := msgs.Validate(); err != nil {
+		return err
+	}
+	var errs []error
+	var unsupported []string
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return err
+		}
+		switch v := opt.(type) {
+		case *genai.GenOptionText:
+			c.InferenceConfig.MaxTokens = v.MaxTokens
+			c.InferenceConfig.Temperature = v.Temperature
+			c.InferenceConfig.TopP = v.TopP
+			c.InferenceConfig.StopSequences = v.Stop
+			if v.SystemPrompt != "" {
+				c.System = []SystemContent{{Text: v.SystemPrompt}}
+			}
+			if v.TopK != 0 {
+				unsupported = append(unsupported, "GenOptionText.TopK")
+			}
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
+			if v.TopLogprobs != 0 {
+				unsupported = append(unsupported, "GenOptionText.TopLogprobs")
+			}
+			if v.DecodeAs != nil {
+				errs = append(errs, errors.New("unsupported option DecodeAs"))
+			} else if v.ReplyAsJSON {
+				errs = append(errs, errors.New("unsupported option ReplyAsJSON"))
+			}
+		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			if err := c.ToolConfig.init(v); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			unsupported = append(unsupported, internal.TypeName(opt))
+		}
+	}
+
+	c.Messages = make([]Message, 0, len(msgs))
+	for i := range msgs {
+		var m Message
+		if err := m.From(&msgs[i]); err != nil {
+			errs = append(errs, fmt.Errorf("message #%d: %w", i, err))
+			continue
+		}
+		// Bedrock requires tool results to be sent as a user message and rejects consecutive messages with the
+		// same role.
+		if n := len(c.Messages); n != 0 && c.Messages[n-1].Role == m.Role {
+			c.Messages[n-1].Content = append(c.Messages[n-1].Content, m.Content...)
+			continue
+		}
+		c.Messages = append(c.Messages, m)
+	}
+	// If we have unsupported features but no other errors, return a structured error.
+	if len(unsupported) > 0 && len(errs) == 0 {
+		return &base.ErrNotSupported{Options: unsupported}
+	}
+	return errors.Join(errs...)
+}
+
+// SetStream implements base.InitializableRequest.
+//
+// Streaming is selected by the endpoint, not in the request.
+func (c *ChatRequest) SetStream(stream bool) {
+}
+
+// SystemContent is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_SystemContentBlock.html
+type SystemContent struct {
+	Text       string      `json:"text,omitzero"`
+	CachePoint *CachePoint `json:"cachePoint,omitzero"`
+}
+
+// CachePoint is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_CachePointBlock.html
+type CachePoint struct {
+	Type string `json:"type"` // "default"
+}
+
+// ToolConfig is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ToolConfiguration.html
+type ToolConfig struct {
+	Tools      []Tool `json:"tools,omitzero"`
+	ToolChoice struct {
+		Auto *struct{} `json:"auto,omitzero"`
+		Any  *struct{} `json:"any,omitzero"`
+		Tool *struct {
+			Name string `json:"name"`
+		} `json:"tool,omitzero"`
+	} `json:"toolChoice,omitzero"`
+}
+
+func (t *ToolConfig) init(in *genai.GenOptionTools) error {
+	tools := in.AllowedTools()
+	if len(tools) == 0 {
+		return nil
+	}
+	switch in.Force {
+	case genai.ToolCallAny:
+		t.ToolChoice.Auto = &struct{}{}
+	case genai.ToolCallRequired:
+		t.ToolChoice.Any = &struct{}{}
+	case genai.ToolCallNone:
+		// The Converse API has no "none" tool choice; the tools must not be sent.
+		return nil
+	}
+	t.Tools = make([]Tool, len(tools))
+	var errs []error
+	for i := range tools {
+		t.Tools[i].ToolSpec.Name = tools[i].Name
+		t.Tools[i].ToolSpec.Description = tools[i].Description
+		s, err := tools[i].GetInputSchema()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tool %q: %w", tools[i].Name, err))
+			continue
+		}
+		t.Tools[i].ToolSpec.InputSchema.JSON = s
+	}
+	return errors.Join(errs...)
+}
+
+// Tool is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_Tool.html
+type Tool struct {
+	ToolSpec struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitzero"`
+		InputSchema struct {
+			JSON json.RawMessage `json:"json"`
+		} `json:"inputSchema"`
+	} `json:"toolSpec,omitzero"`
+	CachePoint *CachePoint `json:"cachePoint,omitzero"`
+}
+
+// Message is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_Message.html
+type Message struct {
+	Role    string    `json:"role"` // "user", "assistant"
+	Content []Content `json:"content"`
+}
+
+// From converts a genai.Message.
+func (m *Message) From(in *genai.Message) error {
+	switch r := in.Role(); r {
+	case "user", "assistant":
+		m.Role = r
+	case "computer":
+		// Tool results are sent by the user.
+		m.Role = "user"
+	default:
+		return fmt.Errorf("unsupported role %q", r)
+	}
+	if in.User != "" {
+		return errors.New("field User not supported")
+	}
+	for i := range in.Requests {
+		var c Content
+		if err := c.FromRequest(&in.Requests[i]); err != nil {
+			return fmt.Errorf("request #%d: %w", i, err)
+		}
+		m.Content = append(m.Content, c)
+	}
+	for i := range in.Replies {
+		var c Content
+		skip, err := c.FromReply(&in.Replies[i])
+		if err != nil {
+			return fmt.Errorf("reply #%d: %w", i, err)
+		}
+		if !skip {
+			m.Content = append(m.Content, c)
+		}
+	}
+	for i := range in.ToolCallResults {
+		r := &in.ToolCallResults[i]
+		c := Content{ToolResult: &ToolResult{ToolUseID: r.ID, Content: []ToolResultContent{{Text: r.Result}}}}
+		m.Content = append(m.Content, c)
+	}
+	return nil
+}
+
+// To converts to the genai equivalent.
+func (m *Message) To(out *genai.Message) error {
+	for i := range m.Content {
+		if err := m.Content[i].To(out); err != nil {
+			return fmt.Errorf("content #%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Content is a ContentBlock union documented at
+// https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ContentBlock.html
+//
+// Only one field is set.
+type Content struct {
+	Text             string            `json:"text,omitzero"`
+	Image            *Media            `json:"image,omitzero"`
+	Document         *Media            `json:"document,omitzero"`
+	Video            *Media            `json:"video,omitzero"`
+	ToolUse          *ToolUse          `json:"toolUse,omitzero"`
+	ToolResult       *ToolResult       `json:"toolResult,omitzero"`
+	ReasoningContent *ReasoningContent `json:"reasoningContent,omitzero"`
+	CachePoint       *CachePoint       `json:"cachePoint,omitzero"`
+	GuardContent     json.RawMessage   `json:"guardContent,omitzero"`
+}
+
+// FromRequest converts a genai.Request.
+func (c *Content) FromRequest(in *genai.Request) error {
+	if in.Text != "" {
+		c.Text = in.Text
+		return nil
+	}
+	if !in.Doc.IsZero() {
+		return c.fromDoc(&in.Doc)
+	}
+	return errors.New("unknown Request type")
+}
+
+// FromReply converts a genai.Reply.
+//
+// It returns true if the reply must be skipped.
+func (c *Content) FromReply(in *genai.Reply) (bool, error) {
+	if in.Reasoning != "" || in.Opaque != nil {
+		c.ReasoningContent = &ReasoningContent{}
+		if b, ok := in.Opaque["redacted_content"].([]byte); ok {
+			c.ReasoningContent.RedactedContent = b
+			return false, nil
+		}
+		c.ReasoningContent.ReasoningText.Text = in.Reasoning
+		if s, ok := in.Opaque["signature"].(string); ok {
+			c.ReasoningContent.ReasoningText.Signature = s
+		}
+		return false, nil
+	}
+	if in.Text != "" {
+		c.Text = in.Text
+		return false, nil
+	}
+	if !in.ToolCall.IsZero() {
+		if len(in.ToolCall.Opaque) != 0 {
+			return false, errors.New("field ToolCall.Opaque not supported")
+		}
+		c.ToolUse = &ToolUse{ToolUseID: in.ToolCall.ID, Name: in.ToolCall.Name}
+		if err := json.Unmarshal([]byte(in.ToolCall.Arguments), &c.ToolUse.Input); err != nil {
+			return false, fmt.Errorf("failed to decode tool call arguments: %w", err)
+		}
+		return false, nil
+	}
+	if !in.Doc.IsZero() {
+		return false, c.fromDoc(&in.Doc)
+	}
+	if !in.Citation.IsZero() {
+		// Citations are not sent back.
+		return true, nil
+	}
+	return false, errors.New("unknown Reply type")
+}
+
+func (c *Content) fromDoc(in *genai.Doc) error {
+	if in.URL != "" {
+		if !strings.HasPrefix(in.URL, "s3://") {
+			return errors.New("only s3:// URLs are supported")
+		}
+		m := &Media{Source: MediaSource{S3Location: &S3Location{URI: in.URL}}}
+		return c.setMedia(in, base.MimeByExt(path.Ext(in.URL)), m)
+	}
+	mimeType, data, err := in.Read(10 * 1024 * 1024)
+	if err != nil {
+		return err
+	}
+	return c.setMedia(in, mimeType, &Media{Source: MediaSource{Bytes: data}})
+}
+
+func (c *Content) setMedia(in *genai.Doc, mimeType string, m *Media) error {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		// png, jpeg, gif, webp
+		m.Format = strings.TrimPrefix(mimeType, "image/")
+		c.Image = m
+	case strings.HasPrefix(mimeType, "video/"):
+		// mkv, mov, mp4, webm, flv, mpeg, mpg, wmv, three_gp
+		m.Format = strings.TrimPrefix(mimeType, "video/")
+		switch m.Format {
+		case "quicktime":
+			m.Format = "mov"
+		case "x-matroska":
+			m.Format = "mkv"
+		case "3gpp":
+			m.Format = "three_gp"
+		}
+		c.Video = m
+	default:
+		f, ok := documentFormats[mimeType]
+		if !ok {
+			return fmt.Errorf("unsupported mime type %s", mimeType)
+		}
+		m.Format = f
+		// The name is required and restricted to alphanumeric characters, whitespace, hyphens, parentheses and
+		// square brackets.
+		m.Name = documentName(in.GetFilename())
+		c.Document = m
+	}
+	return nil
+}
+
+var documentFormats = map[string]string{
+	"application/pdf":    "pdf",
+	"text/csv":           "csv",
+	"application/msword": "doc",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": "docx",
+	"application/vnd.ms-excel": "xls",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": "xlsx",
+	"text/html":     "html",
+	"text/plain":    "txt",
+	"text/markdown": "md",
+}
+
+func documentName(s string) string {
+	if i := strings.LastIndexByte(s, '.'); i > 0 {
+		s = s[:i]
+	}
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '[' || r == ']':
+			return r
+		default:
+			return '-'
+		}
+	}, s)
+	if s == "" {
+		return "document"
+	}
+	return s
+}
+
+// To converts to the genai equivalent.
+func (c *Content) To(out *genai.Message) error {
+	switch {
+	case c.Text != "":
+		out.Replies = append(out.Replies, genai.Reply{Text: c.Text})
+	case c.ToolUse != nil:
+		args, err := json.Marshal(c.ToolUse.Input)
+		if err != nil {
+			return fmt.Errorf("failed to encode tool call arguments: %w", err)
+		}
+		out.Replies = append(out.Replies, genai.Reply{ToolCall: genai.ToolCall{ID: c.ToolUse.ToolUseID, Name: c.ToolUse.Name, Arguments: string(args)}})
+	case c.ReasoningContent != nil:
+		if len(c.ReasoningContent.RedactedContent) != 0 {
+			out.Replies = append(out.Replies, genai.Reply{Opaque: map[string]any{"redacted_content": c.ReasoningContent.RedactedContent}})
+			break
+		}
+		r := genai.Reply{Reasoning: c.ReasoningContent.ReasoningText.Text}
+		if s := c.ReasoningContent.ReasoningText.Signature; s != "" {
+			r.Opaque = map[string]any{"signature": s}
+		}
+		out.Replies = append(out.Replies, r)
+	case c.Image != nil, c.Document != nil, c.Video != nil, c.ToolResult != nil, c.CachePoint != nil, len(c.GuardContent) != 0:
+		return &internal.BadError{Err: fmt.Errorf("implement content %#v", c)}
+	default:
+		// Models sometimes reply with an empty text block.
+	}
+	return nil
+}
+
+// Media is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ImageBlock.html,
+// https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_DocumentBlock.html and
+// https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_VideoBlock.html
+type Media struct {
+	Format string      `json:"format"`
+	Name   string      `json:"name,omitzero"` // Only for documents.
+	Source MediaSource `json:"source"`
+}
+
+// MediaSource is a union; only one field is set.
+type MediaSource struct {
+	Bytes      []byte      `json:"bytes,omitzero"`
+	S3Location *S3Location `json:"s3Location,omitzero"`
+}
+
+// S3Location is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_S3Location.html
+type S3Location struct {
+	URI         string `json:"uri"`
+	BucketOwner string `json:"bucketOwner,omitzero"`
+}
+
+// ToolUse is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ToolUseBlock.html
+type ToolUse struct {
+	ToolUseID string `json:"toolUseId"`
+	Name      string `json:"name"`
+	Input     any    `json:"input"`
+}
+
+// ToolResult is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ToolResultBlock.html
+type ToolResult struct {
+	ToolUseID string              `json:"toolUseId"`
+	Content   []ToolResultContent `json:"content"`
+	Status    string              `json:"status,omitzero"` // "success", "error"
+}
+
+// ToolResultContent is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ToolResultContentBlock.html
+type ToolResultContent struct {
+	Text string          `json:"text,omitzero"`
+	JSON json.RawMessage `json:"json,omitzero"`
+}
+
+// ReasoningContent is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ReasoningContentBlock.html
+type ReasoningContent struct {
+	ReasoningText struct {
+		Text      string `json:"text"`
+		Signature string `json:"signature,omitzero"`
+	} `json:"reasoningText,omitzero"`
+	RedactedContent []byte `json:"redactedContent,omitzero"`
+}
+
+// ChatResponse is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_Converse.html#API_runtime_Converse_ResponseSyntax
+type ChatResponse struct {
+	Output struct {
+		Message Message `json:"message"`
+	} `json:"output"`
+	StopReason                    StopReason      `json:"stopReason"`
+	Usage                         Usage           `json:"usage"`
+	Metrics                       Metrics         `json:"metrics"`
+	AdditionalModelResponseFields json.RawMessage `json:"additionalModelResponseFields,omitzero"`
+	Trace                         json.RawMessage `json:"trace,omitzero"`
+	PerformanceConfig             struct {
+		Latency string `json:"latency,omitzero"`
+	} `json:"performanceConfig,omitzero"`
+}
+
+// ToResult implements base.ResultConverter.
+func (c *ChatResponse) ToResult() (genai.Result, error) {
+	out := genai.Result{Usage: c.Usage.To()}
+	var err error
+	out.Usage.FinishReason, err = c.StopReason.ToFinishReason()
+	err = errors.Join(err, c.Output.Message.To(&out.Message))
+	return out, err
+}
+
+// StopReason is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_Converse.html#API_runtime_Converse_ResponseSyntax
+type StopReason string
+
+// Stop reason values.
+const (
+	StopEndTurn             StopReason = "end_turn"
+	StopToolUse             StopReason = "tool_use"
+	StopMaxTokens           StopReason = "max_tokens"
+	StopSequence            StopReason = "stop_sequence"
+	StopGuardrailIntervened StopReason = "guardrail_intervened"
+	StopContentFiltered     StopReason = "content_filtered"
+)
+
+// ToFinishReason converts to a genai.FinishReason.
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (s StopReason) ToFinishReason() (genai.FinishReason, error) {
+	switch s {
+	case StopEndTurn:
+		return genai.FinishedStop, nil
+	case StopToolUse:
+		return genai.FinishedToolCalls, nil
+	case StopMaxTokens:
+		return genai.FinishedLength, nil
+	case StopSequence:
+		return genai.FinishedStopSequence, nil
+	case StopGuardrailIntervened, StopContentFiltered:
+		return genai.FinishedContentFilter, nil
+	default:
+		return genai.FinishReason(s), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", s)}
+	}
+}
+
+// Usage is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_TokenUsage.html
+type Usage struct {
+	InputTokens           int64 `json:"inputTokens"`
+	OutputTokens          int64 `json:"outputTokens"`
+	TotalTokens           int64 `json:"totalTokens"`
+	CacheReadInputTokens  int64 `json:"cacheReadInputTokens,omitzero"`
+	CacheWriteInputTokens int64 `json:"cacheWriteInputTokens,omitzero"`
+}
+
+// To converts to the genai equivalent.
+func (u *Usage) To() genai.Usage {
+	return genai.Usage{
+		InputTokens:       u.InputTokens + u.CacheReadInputTokens + u.CacheWriteInputTokens,
+		InputCachedTokens: u.CacheReadInputTokens,
+		OutputTokens:      u.OutputTokens,
+		TotalTokens:       u.TotalTokens,
+	}
+}
+
+// Metrics is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ConverseMetrics.html
+type Metrics struct {
+	LatencyMs int64 `json:"latencyMs"`
+}
+
+// ChatStreamChunkResponse is one event of the ConverseStream API. It is a union of the events documented at
+// https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ConverseStreamOutput.html
+type ChatStreamChunkResponse struct {
+	// Type is the ":event-type" header of the event stream message.
+	Type EventType `json:"-"`
+
+	// Type == EventMessageStart
+	Role string `json:"role,omitzero"`
+
+	// Type == EventContentBlockStart, EventContentBlockDelta or EventContentBlockStop
+	ContentBlockIndex int64 `json:"contentBlockIndex,omitzero"`
+	Start             struct {
+		ToolUse struct {
+			ToolUseID string `json:"toolUseId"`
+			Name      string `json:"name"`
+		} `json:"toolUse,omitzero"`
+	} `json:"start,omitzero"`
+	Delta struct {
+		Text    string `json:"text,omitzero"`
+		ToolUse struct {
+			Input string `json:"input"`
+		} `json:"toolUse,omitzero"`
+		ReasoningContent struct {
+			Text            string `json:"text,omitzero"`
+			Signature       string `json:"signature,omitzero"`
+			RedactedContent []byte `json:"redactedContent,omitzero"`
+		} `json:"reasoningContent,omitzero"`
+	} `json:"delta,omitzero"`
+
+	// Type == EventMessageStop
+	StopReason                    StopReason      `json:"stopReason,omitzero"`
+	AdditionalModelResponseFields json.RawMessage `json:"additionalModelResponseFields,omitzero"`
+
+	// Type == EventMetadata
+	Usage             Usage           `json:"usage,omitzero"`
+	Metrics           Metrics         `json:"metrics,omitzero"`
+	Trace             json.RawMessage `json:"trace,omitzero"`
+	PerformanceConfig struct {
+		Latency string `json:"latency,omitzero"`
+	} `json:"performanceConfig,omitzero"`
+
+	// P is random padding added by AWS to mask the payload length.
+	P string `json:"p,omitzero"`
+}
+
+// EventType is the type of a ConverseStream event.
+type EventType string
+
+// Event types.
+const (
+	EventMessageStart      EventType = "messageStart"
+	EventContentBlockStart EventType = "contentBlockStart"
+	EventContentBlockDelta EventType = "contentBlockDelta"
+	EventContentBlockStop  EventType = "contentBlockStop"
+	EventMessageStop       EventType = "messageStop"
+	EventMetadata          EventType = "metadata"
+)
+
+// ErrorResponse is the error returned by the API.
+//
+// See https://docs.aws.amazon.com/bedrock/latest/APIReference/CommonErrors.html
+type ErrorResponse struct {
+	Message string `json:"message"`
+	// Type is the exception type, e.g. "throttlingException". It is only set for exceptions received while
+	// streaming, from the ":exception-type" event stream header.
+	Type string `json:"-"`
+}
+
+func (er *ErrorResponse) Error() string {
+	if er.Type != "" {
+		return er.Type + ": " + er.Message
+	}
+	return er.Message
+}
+
+// IsAPIError implements base.ErrAPI.
+func (er *ErrorResponse) IsAPIError() bool {
+	return true
+}
+
+// Model is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_FoundationModelSummary.html
+type Model struct {
+	ModelArn                   string   `json:"modelArn"`
+	ModelID                    string   `json:"modelId"`
+	ModelName                  string   `json:"modelName"`
+	ProviderName               string   `json:"providerName"`
+	InputModalities            []string `json:"inputModalities"`  // "TEXT", "IMAGE", "EMBEDDING"
+	OutputModalities           []string `json:"outputModalities"` // "TEXT", "IMAGE", "EMBEDDING"
+	ResponseStreamingSupported bool     `json:"responseStreamingSupported"`
+	CustomizationsSupported    []string `json:"customizationsSupported"`
+	InferenceTypesSupported    []string `json:"inferenceTypesSupported"` // "ON_DEMAND", "PROVISIONED"
+	ModelLifecycle             struct {
+		Status string `json:"status"` // "ACTIVE", "LEGACY"
+	} `json:"modelLifecycle"`
+}
+
+// GetID implements genai.Model.
+func (m *Model) GetID() string {
+	return m.ModelID
+}
+
+func (m *Model) String() string {
+	s := fmt.Sprintf("%s: %s (%s) %s -> %s", m.ModelID, m.ModelName, m.ProviderName, strings.Join(m.InputModalities, "/"), strings.Join(m.OutputModalities, "/"))
+	if m.ModelLifecycle.Status == "LEGACY" {
+		s += " (legacy)"
+	}
+	return s
+}
+
+// Context implements genai.Model.
+//
+// The value is not reported by the API.
+func (m *Model) Context() int64 {
+	return 0
+}
+
+// ModelsResponse is documented at https://docs.aws.amazon.com/bedrock/latest/APIReference/API_ListFoundationModels.html
+type ModelsResponse struct {
+	ModelSummaries []Model `json:"modelSummaries"`
+}
+
+// ToModels converts the models to genai.Model interfaces.
+func (r *ModelsResponse) ToModels() []genai.Model {
+	models := make([]genai.Model, len(r.ModelSummaries))
+	for i := range r.ModelSummaries {
+		models[i] = &r.ModelSummaries[i]
+	}
+	return models
+}
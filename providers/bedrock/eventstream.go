@@ -0,0 +1,73 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Decoder for the binary AWS event stream encoding used by ConverseStream.
+
+package bedrock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/maruel/genai/internal/eventstream"
+)
+
+// DecodeStream decodes a ConverseStream response body encoded as "application/vnd.amazon.eventstream".
+//
+// er receives the payload of exception messages.
+//
+// See https://docs.aws.amazon.com/transcribe/latest/dg/streaming-setting-up.html#streaming-event-stream
+// for the framing.
+func DecodeStream(body io.Reader, er error, lenient bool) (iter.Seq[ChatStreamChunkResponse], func() error) {
+	var finalErr error
+	return func(yield func(ChatStreamChunkResponse) bool) {
+			for {
+				hdrs, payload, err := eventstream.ReadMessage(body)
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					finalErr = err
+					return
+				}
+				switch t := hdrs[":message-type"]; t {
+				case "event":
+					var pkt ChatStreamChunkResponse
+					d := json.NewDecoder(bytes.NewReader(payload))
+					if !lenient {
+						d.DisallowUnknownFields()
+					}
+					if err := d.Decode(&pkt); err != nil {
+						finalErr = fmt.Errorf("failed to decode event %q: %w: %s", hdrs[":event-type"], err, payload)
+						return
+					}
+					pkt.Type = EventType(hdrs[":event-type"])
+					if !yield(pkt) {
+						return
+					}
+				case "exception":
+					if err := json.Unmarshal(payload, er); err != nil {
+						finalErr = fmt.Errorf("failed to decode exception %q: %w: %s", hdrs[":exception-type"], err, payload)
+						return
+					}
+					if e, ok := er.(*ErrorResponse); ok {
+						e.Type = hdrs[":exception-type"]
+					}
+					finalErr = er
+					return
+				case "error":
+					finalErr = fmt.Errorf("%s: %s", hdrs[":error-code"], hdrs[":error-message"])
+					return
+				default:
+					finalErr = fmt.Errorf("unexpected message type %q", t)
+					return
+				}
+			}
+		}, func() error {
+			return finalErr
+		}
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// AWS Signature Version 4 request signing, implemented with the standard library only.
+
+package bedrock
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sigV4Transport signs requests with AWS Signature Version 4.
+//
+// See https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html
+type sigV4Transport struct {
+	creds     ProviderOptionCredentials
+	region    string
+	service   string
+	transport http.RoundTripper
+	now       func() time.Time
+}
+
+func (s *sigV4Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var payload []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var r io.ReadCloser = req.Body
+		if req.GetBody != nil {
+			var err error
+			if r, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		payload, err = io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	req = req.Clone(req.Context())
+	if req.GetBody == nil && payload != nil {
+		req.Body = io.NopCloser(bytes.NewReader(payload))
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	s.sign(req, payload, now().UTC())
+	return s.transport.RoundTrip(req)
+}
+
+func (s *sigV4Transport) Unwrap() http.RoundTripper {
+	return s.transport
+}
+
+// sign adds the X-Amz-Date, X-Amz-Security-Token and Authorization headers to req.
+func (s *sigV4Transport) sign(req *http.Request, payload []byte, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.creds.SessionToken)
+	}
+
+	// Sign the host, the content type and the x-amz-* headers. Other headers may be modified by proxies.
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	hdrs := map[string]string{"host": host}
+	for k, v := range req.Header {
+		if k = strings.ToLower(k); k == "content-type" || strings.HasPrefix(k, "x-amz-") {
+			vals := make([]string, len(v))
+			for i := range v {
+				vals[i] = strings.Join(strings.Fields(v[i]), " ")
+			}
+			hdrs[k] = strings.Join(vals, ",")
+		}
+	}
+	names := make([]string, 0, len(hdrs))
+	for k := range hdrs {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	var canonHdrs strings.Builder
+	for _, k := range names {
+		canonHdrs.WriteString(k + ":" + hdrs[k] + "\n")
+	}
+	signedHdrs := strings.Join(names, ";")
+
+	// Services other than S3 encode the path twice.
+	segs := strings.Split(req.URL.EscapedPath(), "/")
+	for i := range segs {
+		segs[i] = uriEncode(segs[i])
+	}
+	canonPath := strings.Join(segs, "/")
+	if canonPath == "" {
+		canonPath = "/"
+	}
+	q := req.URL.Query()
+	var params []string
+	for k, vs := range q {
+		for _, v := range vs {
+			params = append(params, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	slices.Sort(params)
+
+	canonReq := strings.Join([]string{
+		req.Method,
+		canonPath,
+		strings.Join(params, "&"),
+		canonHdrs.String(),
+		signedHdrs,
+		hexSHA256(payload),
+	}, "\n")
+	scope := date + "/" + s.region + "/" + s.service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonReq))
+	k := hmacSHA256([]byte("AWS4"+s.creds.SecretAccessKey), date)
+	k = hmacSHA256(k, s.region)
+	k = hmacSHA256(k, s.service)
+	k = hmacSHA256(k, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(k, toSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.creds.AccessKeyID+"/"+scope+", SignedHeaders="+signedHdrs+", Signature="+sig)
+}
+
+// uriEncode encodes everything but the unreserved characters as specified by SigV4.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			const hexUpper = "0123456789ABCDEF"
+			b.WriteByte('%')
+			b.WriteByte(hexUpper[c>>4])
+			b.WriteByte(hexUpper[c&15])
+		}
+	}
+	return b.String()
+}
+
+func hexSHA256(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
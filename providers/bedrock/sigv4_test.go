@@ -0,0 +1,82 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for Signature Version 4 signing, using the AWS test suite vectors.
+
+package bedrock
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigV4(t *testing.T) {
+	// From https://github.com/aws/aws-sdk-go-v2/tree/main/aws/signer/internal/v4 test suite.
+	s := sigV4Transport{
+		creds:   ProviderOptionCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"},
+		region:  "us-east-1",
+		service: "service",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		want   string
+	}{
+		{
+			name:   "get-vanilla",
+			method: "GET",
+			url:    "https://example.amazonaws.com/",
+			want:   "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "post-vanilla",
+			method: "POST",
+			url:    "https://example.amazonaws.com/",
+			want:   "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:   "get-vanilla-query-order-key-case",
+			method: "GET",
+			url:    "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			want:   "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.sign(req, nil, now)
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Fatalf("unexpected Authorization\nwant %s\ngot  %s", tt.want, got)
+			}
+		})
+	}
+	t.Run("session token", func(t *testing.T) {
+		s2 := s
+		s2.creds.SessionToken = "token"
+		req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s2.sign(req, nil, now)
+		if got := req.Header.Get("X-Amz-Security-Token"); got != "token" {
+			t.Fatalf("unexpected X-Amz-Security-Token %q", got)
+		}
+		if got := req.Header.Get("Authorization"); !strings.Contains(got, "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+			t.Fatalf("unexpected Authorization %q", got)
+		}
+	})
+}
+
+func TestURIEncode(t *testing.T) {
+	if got, want := uriEncode("anthropic.claude-v2:1 a/b~"), "anthropic.claude-v2%3A1%20a%2Fb~"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
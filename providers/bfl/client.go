@@ -27,7 +27,6 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/scoreboard"
 )
 
@@ -62,10 +61,16 @@ type Client struct {
 //
 // To use multiple models, create multiple clients.
 // Use one of the model from https://docs.bfl.ml/quick_start/generating_images
+//
+// The proxy defaults to the BFL_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model, remote string
 	var modalities genai.Modalities
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -84,6 +89,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			remote = string(v)
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -99,7 +112,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only image is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "BFL_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -110,7 +123,8 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		remote: remote,
 		impl: base.ProviderBase[*ErrorResponse]{
 			APIKeyURL: apiKeyURL,
-			Lenient:   internal.BeLenient,
+			Lenient:   lenient,
+			Log:       logger,
 			Client: http.Client{
 				Transport: &roundtrippers.Header{
 					Header:    http.Header{"x-key": {apiKey}},
@@ -207,31 +221,13 @@ func processHeaders(h http.Header) []genai.RateLimit {
 // GenSync implements genai.Provider.
 func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
 	// They recommend in their documentation to poll every 0.5s.
-	waitForPoll := 500 * time.Millisecond
-	filtered := make([]genai.GenOption, 0, len(opts))
-	for _, opt := range opts {
-		if v, ok := opt.(genai.GenOptionPollInterval); ok {
-			waitForPoll = time.Duration(v)
-		} else {
-			filtered = append(filtered, opt)
-		}
-	}
+	waitForPoll, progress, filtered := base.ExtractPollOptions(opts, 500*time.Millisecond)
 	id, err := c.GenAsync(ctx, msgs, filtered...)
 	if err != nil {
 		return genai.Result{}, err
 	}
 	// TODO: Expose a webhook with a custom OptionsImage.
-	// Loop until the result is available.
-	for {
-		select {
-		case <-ctx.Done():
-			return genai.Result{}, ctx.Err()
-		case <-time.After(waitForPoll):
-			if res, err := c.PokeResult(ctx, id); res.Usage.FinishReason != genai.Pending {
-				return res, err
-			}
-		}
-	}
+	return base.PollJob(ctx, id, waitForPoll, progress, c.pokeResult)
 }
 
 // GenStream implements genai.Provider.
@@ -267,24 +263,30 @@ func (c *Client) GenAsyncRaw(ctx context.Context, req *ImageRequest) (ImageReque
 //
 // It retrieves the result for a job ID.
 func (c *Client) PokeResult(ctx context.Context, id genai.Job) (genai.Result, error) {
+	res, _, err := c.pokeResult(ctx, id)
+	return res, err
+}
+
+// pokeResult is PokeResult that also returns the progress as metadata.
+func (c *Client) pokeResult(ctx context.Context, id genai.Job) (genai.Result, map[string]any, error) {
 	res := genai.Result{}
 	imgres, err := c.PokeResultRaw(ctx, id)
 	if err != nil {
-		return res, err
+		return res, nil, err
 	}
 	res.Usage.Limits = processHeaders(c.impl.LastResponseHeaders())
 	if imgres.Status == "Pending" {
 		res.Usage.FinishReason = genai.Pending
-		return res, nil
+		return res, map[string]any{"progress": imgres.Progress}, nil
 	}
 	if imgres.Status != "Ready" {
-		return res, fmt.Errorf("unexpected status: %#v", imgres)
+		return res, nil, fmt.Errorf("unexpected status: %#v", imgres)
 	}
 	res.Replies = []genai.Reply{{Doc: genai.Doc{Filename: "content.jpg", URL: imgres.Result.Sample}}}
 	if err := res.Validate(); err != nil {
-		return res, err
+		return res, nil, err
 	}
-	return res, nil
+	return res, nil, nil
 }
 
 // PokeResultRaw retrieves the result for a job ID if already available.
@@ -16,7 +16,6 @@ import (
 	"time"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/bfl"
 	"github.com/maruel/genai/scoreboard"
@@ -30,6 +29,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return bfl.New(t.Context(), opts...)
 }
 
@@ -88,6 +88,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := bfl.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -165,7 +166,3 @@ func (i *imageModelClient) GenSync(ctx context.Context, msgs genai.Messages, opt
 	opts = append(opts, genai.GenOptionPollInterval(time.Millisecond))
 	return i.Client.GenSync(ctx, msgs, opts...)
 }
-
-func init() {
-	internal.BeLenient = false
-}
@@ -131,6 +131,9 @@ func (i *ImageRequest) Init(msgs genai.Messages, model string, opts ...genai.Gen
 		}
 		switch v := opt.(type) {
 		case *genai.GenOptionImage:
+			if v.Count > 1 {
+				return &base.ErrNotSupported{Options: []string{"GenOptionImage.Count"}}
+			}
 			i.Height = int64(v.Height)
 			i.Width = int64(v.Width)
 		case genai.GenOptionSeed:
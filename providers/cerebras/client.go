@@ -153,12 +153,18 @@ type Client struct {
 //
 // To use multiple models, create multiple clients.
 // Use one of the model from https://cerebras.ai/inference
+//
+// The proxy defaults to the CEREBRAS_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var queueThreshold time.Duration
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -177,6 +183,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			preloadedModels = []genai.Model(v)
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		case ProviderOptionQueueThreshold:
 			queueThreshold = time.Duration(v)
 		default:
@@ -198,7 +212,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "CEREBRAS_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -211,7 +225,8 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			LieToolCalls:    true,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header: h,
@@ -345,7 +360,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.ReasoningTokens = pkt.Usage.CompletionTokensDetails.ReasoningTokens
 					u.OutputTokens = pkt.Usage.CompletionTokens
 					u.TotalTokens = pkt.Usage.TotalTokens
-					u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 
 				for _, nt := range pkt.Choices[0].Delta.ToolCalls {
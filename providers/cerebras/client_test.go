@@ -16,7 +16,6 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/adapters"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/cerebras"
 	"github.com/maruel/genai/scoreboard"
@@ -38,6 +37,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return cerebras.New(t.Context(), opts...)
 }
 
@@ -46,7 +46,7 @@ func TestNew(t *testing.T) {
 		t.Setenv("CEREBRAS_API_KEY", "environment-api-key")
 		var got string
 		c, err := cerebras.New(t.Context(), genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
-			return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
 				got = r.Header.Get("Authorization")
 				return &http.Response{
 					StatusCode: http.StatusOK,
@@ -144,6 +144,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				provOpts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fnWithLog)}, provOpts...)
 			}
+			provOpts = append(provOpts, genai.ProviderOptionStrict(true))
 			c, err2 := cerebras.New(ctx, provOpts...)
 			if err2 != nil {
 				t.Fatal(err2)
@@ -226,7 +227,3 @@ func TestClient(t *testing.T) {
 		internaltest.TestClientProviderErrors(t, f, data)
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"slices"
+	"sort"
 	"strings"
 
 	"github.com/maruel/genai"
@@ -320,6 +321,15 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.TopK != 0 {
 				unsupported = append(unsupported, "GenOptionText.TopK")
 			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			c.N = v.N
+			if v.ReasoningEffort == genai.ReasoningEffortOff && c.ReasoningEffort == "" {
+				c.ReasoningEffort = ReasoningEffortNone
+			} else if v.ReasoningEffort != "" && c.ReasoningEffort == "" {
+				c.ReasoningEffort = ReasoningEffort(v.ReasoningEffort)
+			}
 			c.Stop = v.Stop
 			if v.DecodeAs != nil {
 				c.ResponseFormat.Type = "json_schema"
@@ -334,7 +344,11 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				c.ResponseFormat.Type = "json_object"
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				switch v.Force {
 				case genai.ToolCallAny:
 					c.ToolChoice = ToolChoice{Mode: ToolChoiceAuto}
@@ -344,8 +358,8 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 					c.ToolChoice = ToolChoice{Mode: ToolChoiceNone}
 				}
 				c.ParallelToolCalls = true
-				c.Tools = make([]Tool, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
 					c.Tools[i].Type = "function"
 					c.Tools[i].Function.Name = t.Name
 					c.Tools[i].Function.Description = t.Description
@@ -643,20 +657,12 @@ func (c *Contents) UnmarshalJSON(b []byte) error {
 		*c = nil
 		return nil
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode((*[]Content)(c)); err == nil {
+	if err := json.Unmarshal(b, (*[]Content)(c)); err == nil {
 		return nil
 	}
 
 	v := Content{}
-	d = json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode(&v); err == nil {
+	if err := json.Unmarshal(b, &v); err == nil {
 		*c = Contents{v}
 		return nil
 	}
@@ -744,16 +750,27 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 			TotalTokens:       c.Usage.TotalTokens,
 		},
 	}
-	if len(c.Choices) != 1 {
-		return out, fmt.Errorf("expected 1 choice, got %#v", c.Choices)
+	if len(c.Choices) == 0 {
+		return out, errors.New("server returned no choice")
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-	err := c.Choices[0].Message.To(&out.Message)
+	// The server may not return the choices in order. The first one is the result, the rest are candidates.
+	sort.SliceStable(c.Choices, func(i, j int) bool { return c.Choices[i].Index < c.Choices[j].Index })
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
 	if out.Usage.FinishReason == genai.FinishedStop && slices.ContainsFunc(out.Replies, func(r genai.Reply) bool { return !r.ToolCall.IsZero() }) {
 		// Lie for the benefit of everyone.
 		out.Usage.FinishReason = genai.FinishedToolCalls
 	}
 	out.Logprobs = c.Choices[0].Logprobs.To()
+	if len(c.Choices) > 1 {
+		out.Candidates = make([]genai.Message, len(c.Choices)-1)
+		for i := 1; i < len(c.Choices); i++ {
+			if err2 := c.Choices[i].Message.To(&out.Candidates[i-1]); err2 != nil {
+				err = errors.Join(err, fmt.Errorf("choice #%d: %w", i, err2))
+			}
+		}
+	}
 	return out, err
 }
 
@@ -769,21 +786,20 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishContentFilter:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -16,15 +16,10 @@ import (
 	"time"
 
 	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/cerebras"
 )
 
-type roundTripperFunc func(*http.Request) (*http.Response, error)
-
-func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
-	return f(r)
-}
-
 func TestDTOFieldsHaveJSONTag(t *testing.T) {
 	seen := map[reflect.Type]struct{}{}
 	for _, typ := range []reflect.Type{
@@ -211,7 +206,7 @@ func TestQueueThreshold(t *testing.T) {
 				genai.ProviderOptionModel("gemma-4-31b"),
 				cerebras.ProviderOptionQueueThreshold(100*time.Millisecond),
 				genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
-					return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
 						got = r.Header.Get("queue_threshold")
 						return &http.Response{
 							StatusCode: http.StatusOK,
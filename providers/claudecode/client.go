@@ -209,6 +209,15 @@ func parseOpts(opts []genai.GenOption) (callOpts, error) {
 			if v.TopK != 0 {
 				unsupported = append(unsupported, "GenOptionText.TopK")
 			}
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			if len(v.Stop) != 0 {
 				unsupported = append(unsupported, "GenOptionText.Stop")
 			}
@@ -229,6 +238,9 @@ func parseOpts(opts []genai.GenOption) (callOpts, error) {
 			if v.Search || v.Fetch {
 				webTools = append(webTools, "WebFetch")
 			}
+			if v.SearchOptions != nil {
+				unsupported = append(unsupported, "GenOptionWeb.SearchOptions")
+			}
 		default:
 			return callOpts{}, fmt.Errorf("unsupported option %T", opt)
 		}
@@ -311,6 +323,7 @@ type Client struct {
 	bin            string
 	model          string
 	apiKeyAuth     bool // keep ANTHROPIC_API_KEY in subprocess environment
+	lenient        bool
 
 	binOnce sync.Once
 	binErr  error
@@ -328,8 +341,9 @@ type Client struct {
 //     Use genai.ModelCheap, genai.ModelGood, or genai.ModelSOTA for automatic selection.
 //   - ProviderOptionAPIKeyAuth — keep ANTHROPIC_API_KEY in the subprocess
 //     environment. By default the key is stripped so Claude Code uses OAuth.
+//   - genai.ProviderOptionStrict — reject unknown fields in the CLI's stream-json messages.
 func New(opts ...genai.ProviderOption) (*Client, error) {
-	c := &Client{}
+	c := &Client{lenient: true}
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -351,6 +365,8 @@ func New(opts ...genai.ProviderOption) (*Client, error) {
 			}
 		case ProviderOptionAPIKeyAuth:
 			c.apiKeyAuth = bool(v)
+		case genai.ProviderOptionStrict:
+			c.lenient = !bool(v)
 		case genai.ProviderOptionStarterWrapper:
 			c.starterWrapper = v
 		default:
@@ -464,13 +480,13 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 			}
 		case OutputAssistant:
 			var asst OutputAssistantMsg
-			if e := internal.UnmarshalJSON(line, &asst); e != nil {
+			if e := internal.UnmarshalJSON(line, &asst, c.lenient); e != nil {
 				return genai.Result{}, errors.Join(fmt.Errorf("parse assistant: %w", e), err)
 			}
 			asstBlocks = append(asstBlocks, asst.Message.Content...)
 		case OutputResult:
 			var res OutputResultMsg
-			if e := internal.UnmarshalJSON(line, &res); e != nil {
+			if e := internal.UnmarshalJSON(line, &res, c.lenient); e != nil {
 				return genai.Result{}, errors.Join(fmt.Errorf("parse result: %w", e), err)
 			}
 			if e := res.AsError(); e != nil {
@@ -534,7 +550,7 @@ func (c *Client) GenSyncRaw(ctx context.Context, msgs genai.Messages, opts ...ge
 		}
 		switch b.Type {
 		case OutputControlRequest:
-			if err := handleControlRequest(ctx, stdin, co.controlHandler, line); err != nil {
+			if err := handleControlRequest(ctx, stdin, co.controlHandler, line, c.lenient); err != nil {
 				return records, err
 			}
 		case OutputResult:
@@ -649,14 +665,14 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 				}
 			case OutputAssistant:
 				var asst OutputAssistantMsg
-				if err := internal.UnmarshalJSON(line, &asst); err != nil {
+				if err := internal.UnmarshalJSON(line, &asst, c.lenient); err != nil {
 					finalErr = fmt.Errorf("parse assistant: %w", err)
 					return
 				}
 				asstBlocks = append(asstBlocks, asst.Message.Content...)
 			case OutputResult:
 				var res OutputResultMsg
-				if err := internal.UnmarshalJSON(line, &res); err != nil {
+				if err := internal.UnmarshalJSON(line, &res, c.lenient); err != nil {
 					finalErr = fmt.Errorf("parse result: %w", err)
 					return
 				}
@@ -670,7 +686,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 				}
 				return
 			case OutputControlRequest:
-				if err := handleControlRequest(ctx, stdin, co.controlHandler, line); err != nil {
+				if err := handleControlRequest(ctx, stdin, co.controlHandler, line, c.lenient); err != nil {
 					finalErr = err
 					return
 				}
@@ -774,12 +790,12 @@ func (c *Client) buildArgs(co *callOpts, sessionID string, stream bool) []string
 	return args
 }
 
-func handleControlRequest(ctx context.Context, w io.Writer, h ControlHandler, line []byte) error {
+func handleControlRequest(ctx context.Context, w io.Writer, h ControlHandler, line []byte, lenient bool) error {
 	if h == nil {
 		return errors.New("claude requested host control but no control handler is configured")
 	}
 	var req OutputControlRequestMsg
-	if err := internal.UnmarshalJSON(line, &req); err != nil {
+	if err := internal.UnmarshalJSON(line, &req, lenient); err != nil {
 		return fmt.Errorf("parse control request: %w", err)
 	}
 	res, err := h(ctx, req)
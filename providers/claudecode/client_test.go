@@ -28,7 +28,7 @@ import (
 
 func newTestClient(t *testing.T, name string, opts ...genai.ProviderOption) *Client {
 	rec := internaltest.NewSubprocessRecorder(t, name, "claude")
-	opts = append(opts, genai.ProviderOptionStarterWrapper(rec.Wrap))
+	opts = append(opts, genai.ProviderOptionStrict(true), genai.ProviderOptionStarterWrapper(rec.Wrap))
 	c, err := New(opts...)
 	if err != nil {
 		t.Fatalf("New: %v", err)
@@ -37,7 +37,7 @@ func newTestClient(t *testing.T, name string, opts ...genai.ProviderOption) *Cli
 }
 
 func newOutputClient(t *testing.T, output string, captureArgs *[]string, opts ...genai.ProviderOption) *Client {
-	opts = append(opts, genai.ProviderOptionStarterWrapper(func(genai.Starter) genai.Starter {
+	opts = append(opts, genai.ProviderOptionStrict(true), genai.ProviderOptionStarterWrapper(func(genai.Starter) genai.Starter {
 		return func(_ context.Context, args []string) (io.WriteCloser, io.ReadCloser, func() error, error) {
 			if captureArgs != nil {
 				*captureArgs = slices.Clone(args)
@@ -115,7 +115,7 @@ func TestClient(t *testing.T) {
 				if rec, ok := wrapped.(*myrecorder.Recorder); ok {
 					name := strings.TrimSuffix(rec.Name(), ".yaml")
 					r := internaltest.NewSubprocessRecorder(t, name, "claude")
-					opts = append(opts, genai.ProviderOptionStarterWrapper(r.Wrap))
+					opts = append(opts, genai.ProviderOptionStrict(true), genai.ProviderOptionStarterWrapper(r.Wrap))
 				}
 			}
 			c, err := New(opts...)
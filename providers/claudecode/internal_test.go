@@ -135,11 +135,11 @@ func TestHandleControlRequest(t *testing.T) {
 			}, nil
 		}
 		var buf bytes.Buffer
-		if err := handleControlRequest(t.Context(), &buf, h, []byte(data)); err != nil {
+		if err := handleControlRequest(t.Context(), &buf, h, []byte(data), false); err != nil {
 			t.Fatal(err)
 		}
 		var got InputControlResponseMsg
-		if err := internal.UnmarshalJSON(buf.Bytes(), &got); err != nil {
+		if err := internal.UnmarshalJSON(buf.Bytes(), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Type != InputControlResponse {
@@ -162,7 +162,7 @@ func TestHandleControlRequest(t *testing.T) {
 	t.Run("missing_handler", func(t *testing.T) {
 		const data = `{"type":"control_request","request_id":"r1","request":{"subtype":"can_use_tool","tool_name":"Bash","input":{"command":"git status"},"tool_use_id":"toolu_1"}}`
 		var buf bytes.Buffer
-		err := handleControlRequest(t.Context(), &buf, nil, []byte(data))
+		err := handleControlRequest(t.Context(), &buf, nil, []byte(data), false)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -181,7 +181,7 @@ func TestHandleControlRequest(t *testing.T) {
 			}, nil
 		}
 		var buf bytes.Buffer
-		err := handleControlRequest(t.Context(), &buf, h, []byte(data))
+		err := handleControlRequest(t.Context(), &buf, h, []byte(data), false)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -243,7 +243,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("system_thinking_tokens", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"thinking_tokens","estimated_tokens":138,"estimated_tokens_delta":88,"uuid":"u1","session_id":"s1"}`
 		var got OutputSystemMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Subtype != SystemThinkingTokens {
@@ -259,7 +259,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("api_retry_fractional_delay", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"api_retry","attempt":1,"max_retries":10,"retry_delay_ms":599.3873493672435,"error_status":401,"error":"authentication_failed","session_id":"s1","uuid":"u1"}`
 		var got OutputSystemMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.RetryDelay != base.DurationMS(599.3873493672435) {
@@ -272,7 +272,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("task_updated", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"task_updated","task_id":"task-1","patch":{"status":"completed","end_time":1780832660165},"uuid":"u1","session_id":"s1"}`
 		var got OutputSystemMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Subtype != SystemTaskUpdated {
@@ -291,7 +291,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("task_updated_backgrounded", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"task_updated","task_id":"task-1","patch":{"is_backgrounded":true},"uuid":"u1","session_id":"s1"}`
 		var got OutputSystemMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if !got.Patch.IsBackgrounded {
@@ -301,7 +301,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("background_tasks_changed", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"background_tasks_changed","tasks":[{"task_id":"bldd7gfwj","task_type":"local_bash","description":"Run the smoke test with podman"}],"uuid":"u1","session_id":"s1"}`
 		var got OutputSystemMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Subtype != SystemBackgroundTasksChanged {
@@ -323,7 +323,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("background_tasks_changed_empty", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"background_tasks_changed","tasks":[],"uuid":"u1","session_id":"s1"}`
 		var got OutputSystemMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Tasks == nil {
@@ -336,7 +336,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("commands_changed", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"commands_changed","commands":[{"name":"widget","description":"Render widgets","argumentHint":"","aliases":["caic-widget:widget"]}],"uuid":"u1","session_id":"s1"}`
 		var got OutputCommandsChangedMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Subtype != SystemCommandsChanged {
@@ -359,7 +359,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("task_started_subagent_metadata", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"task_started","task_id":"task-1","tool_use_id":"toolu_1","description":"Find harness/model selection logic","subagent_type":"Explore","task_type":"local_agent","prompt":"Find harness/model selection logic","uuid":"u1","session_id":"s1"}`
 		var got OutputSystemMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.SubagentType != "Explore" {
@@ -369,7 +369,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("status_compact_result", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"status","status":null,"compact_result":"success","session_id":"s1","uuid":"u1"}`
 		var got OutputSystemMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.CompactResult != "success" {
@@ -379,7 +379,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("init_flags", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"init","cwd":"/tmp","session_id":"s1","tools":[],"model":"m","claude_code_version":"1.0","uuid":"u1","analytics_disabled":true,"product_feedback_disabled":true}`
 		var got OutputInitMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if !got.AnalyticsDisabled {
@@ -392,7 +392,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("init_2_1_214_fields", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"init","cwd":"/tmp","session_id":"s1","tools":[],"model":"m","claude_code_version":"2.1.214","uuid":"u1","plugins":[{"name":"p","path":"/p","source":"p@market","version":"1.2.3"}],"plugin_warnings":[{"plugin":"p","type":"shadowed","message":"ignored"}],"capabilities":["interrupt_receipt_v1"]}`
 		var got OutputInitMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Plugins[0].Version != "1.2.3" {
@@ -408,7 +408,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("turn_duration", func(t *testing.T) {
 		const data = `{"type":"system","subtype":"turn_duration","duration_ms":1234,"budget_tokens":100,"budget_limit":200,"budget_nudges":2,"message_count":9,"pending_background_agent_count":1,"pending_workflow_count":3,"uuid":"u1","session_id":"s1"}`
 		var got OutputTurnDurationMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Subtype != SystemTurnDuration {
@@ -421,7 +421,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("stream_context_management", func(t *testing.T) {
 		const data = `{"type":"stream_event","event":{"type":"message_delta","context_management":{"applied_edits":[{"type":"clear_tool_uses_20250919","cleared_input_tokens":123,"cleared_tool_uses":4},{"type":"clear_thinking_20251015","cleared_input_tokens":456,"cleared_thinking_turns":7}]}},"uuid":"u1","session_id":"s1","parent_tool_use_id":null}`
 		var got OutputStreamEventMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if len(got.Event.ContextManagement.AppliedEdits) != 2 {
@@ -448,7 +448,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("stream_message_start", func(t *testing.T) {
 		const data = `{"type":"stream_event","event":{"type":"message_start","message":{"model":"claude-opus-4-8","id":"msg_01","type":"message","role":"assistant","content":[],"stop_reason":null,"stop_sequence":null,"stop_details":null,"usage":{"input_tokens":268,"cache_creation_input_tokens":398,"cache_read_input_tokens":408224,"cache_creation":{"ephemeral_5m_input_tokens":0,"ephemeral_1h_input_tokens":398},"output_tokens":3,"service_tier":"standard","inference_geo":"not_available"},"diagnostics":null}},"uuid":"u1","session_id":"s1","parent_tool_use_id":null}`
 		var got OutputStreamEventMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Event.Message.ID != "msg_01" {
@@ -461,7 +461,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("assistant_message_metadata", func(t *testing.T) {
 		const data = `{"type":"assistant","message":{"model":"claude-opus-4-8","id":"msg_01","type":"message","role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"true"},"caller":{"type":"direct"}}],"stop_reason":"refusal","stop_sequence":null,"stop_details":{"type":"refusal","category":"cyber","explanation":"blocked"},"usage":{"input_tokens":1,"output_tokens":2},"container":{"id":"container_1","expires_at":"2026-06-07T12:00:00Z","skills":[{"skill_id":"sk_1","type":"anthropic","version":"latest"}]},"diagnostics":{"cache_miss_reason":{"type":"tools_changed","cache_missed_input_tokens":42}}},"uuid":"u1","session_id":"s1","parent_tool_use_id":null,"subagent_type":"Explore","task_description":"Find harness/model selection logic"}`
 		var got OutputAssistantMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.SubagentType != "Explore" {
@@ -503,7 +503,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("assistant_fallback_content", func(t *testing.T) {
 		const data = `{"type":"assistant","message":{"model":"claude-opus-4-8","id":"msg_01","type":"message","role":"assistant","content":[{"type":"fallback","from":{"model":"claude-fable-5"},"to":{"model":"claude-opus-4-8"}}],"stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":1,"output_tokens":1}},"uuid":"u1","session_id":"s1","parent_tool_use_id":null,"request_id":"req_1"}`
 		var got OutputAssistantMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		block := got.Message.Content[0]
@@ -514,7 +514,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("synthetic_refusal_assistant", func(t *testing.T) {
 		const data = `{"type":"assistant","message":{"id":"m1","container":null,"model":"<synthetic>","role":"assistant","stop_details":{"type":"refusal","category":"bio","explanation":null,"fallback_has_prefill_claim":null,"recommended_model":null},"stop_reason":"refusal","stop_sequence":"","type":"message","usage":{"input_tokens":0,"output_tokens":0,"cache_creation_input_tokens":0,"cache_read_input_tokens":0,"server_tool_use":{"web_search_requests":0},"service_tier":null,"cache_creation":{"ephemeral_1h_input_tokens":0,"ephemeral_5m_input_tokens":0},"inference_geo":null,"iterations":null,"speed":null},"content":[{"type":"text","text":"API Error: blocked"}],"context_management":null},"parent_tool_use_id":null,"session_id":"s1","uuid":"u1","error":"invalid_request","request_id":"req_1"}`
 		var got OutputAssistantMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Message.StopDetails.Category != "bio" || got.Error != "invalid_request" {
@@ -524,7 +524,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("model_refusal_system_messages", func(t *testing.T) {
 		const fallback = `{"type":"system","subtype":"model_refusal_fallback","trigger":"refusal","direction":"retry","original_model":"claude-fable-5","fallback_model":"claude-opus-4-8","request_id":"req_1","api_refusal_category":"bio","api_refusal_explanation":null,"refused_user_message_uuid":"user_1","content":"Switched to Opus 4.8.","session_id":"s1","uuid":"u1"}`
 		var got OutputSystemMsg
-		if err := internal.UnmarshalJSON([]byte(fallback), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(fallback), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Subtype != SystemModelRefusalFallback {
@@ -536,7 +536,7 @@ func TestOutputMessages(t *testing.T) {
 
 		const noFallback = `{"type":"system","subtype":"model_refusal_no_fallback","original_model":"claude-fable-5","request_id":"req_1","api_refusal_category":"bio","api_refusal_explanation":null,"refused_user_message_uuid":"user_1","content":"","session_id":"s1","uuid":"u1"}`
 		got = OutputSystemMsg{}
-		if err := internal.UnmarshalJSON([]byte(noFallback), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(noFallback), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Subtype != SystemModelRefusalNoFallback {
@@ -549,7 +549,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("user_subagent_metadata", func(t *testing.T) {
 		const data = `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"Find harness/model selection logic"}]},"parent_tool_use_id":"toolu_1","session_id":"s1","uuid":"u1","timestamp":"2026-06-13T20:16:11.423Z","subagent_type":"Explore","task_description":"Find harness/model selection logic"}`
 		var got OutputUserMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.SubagentType != "Explore" {
@@ -562,7 +562,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("user_inline_tool_result_error", func(t *testing.T) {
 		const data = `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","content":"Answer questions?","is_error":true,"tool_use_id":"toolu_ask"}]},"parent_tool_use_id":null,"session_id":"s1","uuid":"u1","timestamp":"2026-06-23T18:51:57.326Z","tool_use_result":"Error: Answer questions?"}`
 		var got OutputUserMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		msg, err := got.DecodeMessage()
@@ -590,7 +590,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("user_plain_answer_after_question", func(t *testing.T) {
 		const data = `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"Identity only (Recommended)"}]}}`
 		var got OutputUserMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		msg, err := got.DecodeMessage()
@@ -607,7 +607,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("user_top_level_tool_result", func(t *testing.T) {
 		const data = `{"type":"user","message":{"content":[{"type":"text","text":"file not found"}],"is_error":true},"parent_tool_use_id":"toolu_read"}`
 		var got OutputUserMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		msg, err := got.DecodeMessage()
@@ -628,7 +628,7 @@ func TestOutputMessages(t *testing.T) {
 			RequestID string               `json:"request_id"`
 			Request   ControlReqCanUseTool `json:"request"`
 		}
-		if err := internal.UnmarshalJSON([]byte(data), &raw); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &raw, false); err != nil {
 			t.Fatal(err)
 		}
 		if raw.Request.Subtype != ControlCanUseTool {
@@ -671,7 +671,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("ask_user_question_input", func(t *testing.T) {
 		const data = `{"questions":[{"question":"Which option?","header":"Pick","options":[{"label":"A","description":"First"},{"label":"B"}],"multiSelect":true}]}`
 		var got AskUserQuestionInput
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if len(got.Questions) != 1 {
@@ -697,7 +697,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("todo_write_input", func(t *testing.T) {
 		const data = `{"todos":[{"content":"Fix bug","status":"in_progress","activeForm":"Fixing bug"}]}`
 		var got TodoWriteInput
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if len(got.Todos) != 1 {
@@ -717,7 +717,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("stream_content_block_start", func(t *testing.T) {
 		const data = `{"type":"stream_event","event":{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"Bash","input":{},"caller":{"type":"code_execution_20260120","tool_id":"srv_1"}}},"uuid":"u1","session_id":"s1","parent_tool_use_id":null}`
 		var got OutputStreamEventMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Event.ContentBlock.ID != "toolu_1" {
@@ -733,7 +733,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("stream_message_delta_usage", func(t *testing.T) {
 		const data = `{"type":"stream_event","event":{"type":"message_delta","usage":{"input_tokens":2,"output_tokens":192,"cache_read_input_tokens":409477,"output_tokens_details":{"thinking_tokens":49},"iterations":[{"input_tokens":2,"output_tokens":192,"cache_read_input_tokens":409477,"cache_creation_input_tokens":0,"type":"message"},{"input_tokens":3,"output_tokens":4,"cache_read_input_tokens":5,"cache_creation_input_tokens":6,"model":"claude-opus-4-8","type":"advisor_message"}]}},"uuid":"u1","session_id":"s1","parent_tool_use_id":null}`
 		var got OutputStreamEventMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Event.Usage.OutputTokens != 192 {
@@ -755,7 +755,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("result_latency_fields", func(t *testing.T) {
 		const data = `{"type":"result","subtype":"success","is_error":false,"duration_ms":1,"duration_api_ms":2,"ttft_ms":3,"ttft_stream_ms":4,"time_to_request_ms":5,"time_to_request_from_spawn_ms":6,"warm_spare_claimed":true,"time_origin_ms":1784740000123,"num_turns":1,"result":"ok","structured_output":{"answer":42},"session_id":"s1","total_cost_usd":0,"usage":{},"uuid":"u1"}`
 		var got OutputResultMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Subtype != ResultSuccess {
@@ -777,7 +777,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("rate_limit_2_1_214_fields", func(t *testing.T) {
 		const data = `{"type":"rate_limit_event","rate_limit_info":{"status":"allowed_warning","rateLimitType":"seven_day_opus","overageStatus":"rejected","overageDisabledReason":"out_of_credits","overageInUse":true,"surpassedThreshold":0.8,"overagePeriodMonthly":{"utilization":0.7},"overagePeriodChannel":{"utilization":0.6},"errorCode":"credits_required","canUserPurchaseCredits":true,"hasChargeableSavedPaymentMethod":true},"uuid":"u1","session_id":"s1"}`
 		var got OutputRateLimitEventMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		i := got.RateLimitInfo
@@ -797,7 +797,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("result_origin", func(t *testing.T) {
 		const data = `{"type":"result","subtype":"success","is_error":false,"duration_ms":1,"duration_api_ms":2,"num_turns":1,"result":"ok","session_id":"s1","total_cost_usd":0,"usage":{},"uuid":"u1","origin":{"kind":"task-notification"}}`
 		var got OutputResultMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Origin.Kind != ResultOriginTaskNotification {
@@ -807,7 +807,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("tool_progress", func(t *testing.T) {
 		const data = `{"type":"tool_progress","tool_use_id":"toolu_1","tool_name":"Bash","parent_tool_use_id":null,"elapsed_time_seconds":1.5,"uuid":"u1","session_id":"s1"}`
 		var got OutputToolProgressMsg
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.ElapsedTime != base.DurationS(1.5) {
@@ -820,7 +820,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("tool_result_string_content", func(t *testing.T) {
 		const data = `{"content":"tool failed","is_error":true}`
 		var got OutputToolResult
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Content.Text != "tool failed" {
@@ -834,7 +834,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("tool_result_block_content", func(t *testing.T) {
 		const data = `{"content":[{"type":"text","text":"ok"}],"is_error":false}`
 		var got OutputToolResult
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if len(got.Content.Blocks) != 1 || got.Content.Blocks[0].Text != "ok" {
@@ -844,7 +844,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("control_wrappers", func(t *testing.T) {
 		const data = `{"subtype":"initialize","hooks":{"PreToolUse":[{"matcher":"Bash","hookCallbackIds":["hook_1"],"timeout":5}]},"jsonSchema":{"type":"object","properties":{"answer":{"type":"string"}}},"agentProgressSummaries":true}`
 		var got ControlReqInitialize
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Hooks[HookPreToolUse][0].HookCallbackIDs[0] != "hook_1" {
@@ -857,7 +857,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("hook_callback_input", func(t *testing.T) {
 		const data = `{"subtype":"hook_callback","callback_id":"hook_1","input":{"hook_event_name":"PreToolUse","session_id":"s1","transcript_path":"/tmp/t.jsonl","cwd":"/repo","tool_name":"Bash","tool_input":{"command":"true"},"tool_use_id":"toolu_1"},"tool_use_id":"toolu_1"}`
 		var got ControlReqHookCallback
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		inputJSON, err := json.Marshal(got.Input.ToolInput)
@@ -875,7 +875,7 @@ func TestOutputMessages(t *testing.T) {
 	t.Run("mcp_jsonrpc_message", func(t *testing.T) {
 		const data = `{"subtype":"mcp_message","server_name":"srv","message":{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{"cursor":"c1"}}}`
 		var got ControlReqMcpMessage
-		if err := internal.UnmarshalJSON([]byte(data), &got); err != nil {
+		if err := internal.UnmarshalJSON([]byte(data), &got, false); err != nil {
 			t.Fatal(err)
 		}
 		if got.Message.JSONRPC != "2.0" || got.Message.Method != "tools/list" {
@@ -1023,7 +1023,3 @@ func TestGenOption(t *testing.T) {
 		}
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
@@ -27,7 +27,6 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/scoreboard"
 )
 
@@ -74,11 +73,19 @@ type Client struct {
 //
 // To use multiple models, create multiple clients.
 // Use one of the model from https://developers.cloudflare.com/workers-ai/models/
+//
+// The proxy defaults to the CLOUDFLARE_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, accountID, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -97,8 +104,20 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -121,7 +140,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		// https://developers.cloudflare.com/workers-ai/models/?tasks=Text-to-Image
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is implemented (send PR to add support)", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "CLOUDFLARE_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -132,9 +151,12 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		impl: base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]{
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
@@ -149,7 +171,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		switch model {
 		case "":
 		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
-			if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 				return nil, err
 			}
 			// Important: the model must not be path escaped!
@@ -166,13 +188,15 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 
 // selectBestTextModel selects the most appropriate model based on the preference (cheap, good, or SOTA).
 //
-// We may want to make this function overridable in the future by the client since this is going to break one
-// day or another.
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
 func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
 	mdls, err := c.ListModels(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	cheap := preference == string(genai.ModelCheap)
 	good := preference == string(genai.ModelGood) || preference == ""
 	selectedModel := ""
@@ -229,6 +253,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -316,4 +345,7 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 		}
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+)
@@ -15,7 +15,6 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/adapters"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/cloudflare"
 	"github.com/maruel/genai/scoreboard"
@@ -42,6 +41,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return cloudflare.New(t.Context(), opts...)
 }
 
@@ -107,6 +107,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := cloudflare.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -182,7 +183,3 @@ func TestClient(t *testing.T) {
 		internaltest.TestClientProviderErrors(t, f, data)
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
@@ -67,6 +67,15 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			c.TopP = v.TopP
 			sp = v.SystemPrompt
 			c.TopK = v.TopK
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			if v.TopLogprobs > 0 {
 				unsupported = append(unsupported, "GenOptionText.TopLogprobs")
 			}
@@ -85,13 +94,17 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				c.ResponseFormat.Type = "json_object"
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				if v.Force != genai.ToolCallAny {
 					// Cloudflare doesn't provide a way to force tool use. Don't fail.
 					unsupported = append(unsupported, "GenOptionTools.Force")
 				}
-				c.Tools = make([]Tool, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
 					c.Tools[i].Type = "function"
 					c.Tools[i].Function.Name = t.Name
 					c.Tools[i].Function.Description = t.Description
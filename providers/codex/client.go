@@ -65,6 +65,15 @@ func Scoreboard() scoreboard.Score {
 // callOpts holds per-call options parsed from the GenOption slice.
 type callOpts struct {
 	systemPrompt string
+	effort       ReasoningEffort
+}
+
+// effortOr returns the per-call reasoning effort, or def when none was requested.
+func (co *callOpts) effortOr(def ReasoningEffort) ReasoningEffort {
+	if co.effort != "" {
+		return co.effort
+	}
+	return def
 }
 
 // parseOpts validates and collects the per-call options.
@@ -93,6 +102,17 @@ func parseOpts(opts []genai.GenOption) (callOpts, error) {
 			if v.TopK != 0 {
 				unsupported = append(unsupported, "GenOptionText.TopK")
 			}
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort == genai.ReasoningEffortOff {
+				co.effort = ReasoningEffortNone
+			} else {
+				co.effort = ReasoningEffort(v.ReasoningEffort)
+			}
 			if len(v.Stop) != 0 {
 				unsupported = append(unsupported, "GenOptionText.Stop")
 			}
@@ -183,6 +203,7 @@ type Client struct {
 	bin            string
 	model          string
 	effort         ReasoningEffort
+	lenient        bool
 	binOnce        sync.Once
 	binErr         error
 }
@@ -197,8 +218,9 @@ type Client struct {
 //     Use genai.ModelCheap, genai.ModelGood, or genai.ModelSOTA for automatic selection.
 //   - ReasoningEffort — reasoning depth ("none", "minimal", "low",
 //     "medium", "high", "xhigh"). Defaults to "medium".
+//   - genai.ProviderOptionStrict — reject unknown fields in the CLI's JSON-RPC messages.
 func New(opts ...genai.ProviderOption) (*Client, error) {
-	c := &Client{effort: ReasoningEffortMedium}
+	c := &Client{effort: ReasoningEffortMedium, lenient: true}
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -220,6 +242,8 @@ func New(opts ...genai.ProviderOption) (*Client, error) {
 			}
 		case ReasoningEffort:
 			c.effort = v
+		case genai.ProviderOptionStrict:
+			c.lenient = !bool(v)
 		case genai.ProviderOptionStarterWrapper:
 			c.starterWrapper = v
 		default:
@@ -305,7 +329,7 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 	}()
 
 	sc := newScanner(stdout)
-	models, err := initAndListModels(stdin, sc)
+	models, err := initAndListModels(stdin, sc, c.lenient)
 	if err != nil {
 		return nil, err
 	}
@@ -325,7 +349,7 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 	if err := c.ensureBin(); err != nil {
 		return genai.Result{}, err
 	}
-	_, optsErr := parseOpts(opts)
+	co, optsErr := parseOpts(opts)
 	if optsErr != nil {
 		if _, ok := errors.AsType[*base.ErrNotSupported](optsErr); !ok {
 			return genai.Result{}, optsErr
@@ -352,16 +376,16 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 	}()
 
 	sc := newScanner(stdout)
-	newThreadID, err := handshake(stdin, sc, c.model, threadID)
+	newThreadID, err := handshake(stdin, sc, c.model, threadID, c.lenient)
 	if err != nil {
 		return genai.Result{}, err
 	}
 
-	if err := sendTurnStart(stdin, newThreadID, c.effort, &userMsg); err != nil {
+	if err := sendTurnStart(stdin, newThreadID, co.effortOr(c.effort), &userMsg); err != nil {
 		return genai.Result{}, err
 	}
 
-	return readTurnSync(sc, newThreadID)
+	return readTurnSync(sc, newThreadID, c.lenient)
 }
 
 // GenStream implements genai.Provider.
@@ -369,7 +393,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 	if err := c.ensureBin(); err != nil {
 		return yieldNothing, errFinish(err)
 	}
-	_, optsErr := parseOpts(opts)
+	co, optsErr := parseOpts(opts)
 	if optsErr != nil {
 		if _, ok := errors.AsType[*base.ErrNotSupported](optsErr); !ok {
 			return yieldNothing, errFinish(optsErr)
@@ -397,12 +421,12 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		}()
 
 		sc := newScanner(stdout)
-		newThreadID, hsErr := handshake(stdin, sc, c.model, threadID)
+		newThreadID, hsErr := handshake(stdin, sc, c.model, threadID, c.lenient)
 		if hsErr != nil {
 			finalErr = hsErr
 			return
 		}
-		if err := sendTurnStart(stdin, newThreadID, c.effort, &userMsg); err != nil {
+		if err := sendTurnStart(stdin, newThreadID, co.effortOr(c.effort), &userMsg); err != nil {
 			finalErr = err
 			return
 		}
@@ -432,31 +456,31 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 			switch msg.Method {
 			case MethodItemDelta:
 				var p AgentMessageDeltaNotification
-				if internal.UnmarshalJSON(msg.Params, &p) == nil && p.Delta != "" {
+				if internal.UnmarshalJSON(msg.Params, &p, c.lenient) == nil && p.Delta != "" {
 					if !yield(genai.Reply{Text: p.Delta}) {
 						return
 					}
 				}
 			case MethodReasoningSummaryTextDelta:
 				var p ReasoningSummaryTextDeltaNotification
-				if internal.UnmarshalJSON(msg.Params, &p) == nil && p.Delta != "" {
+				if internal.UnmarshalJSON(msg.Params, &p, c.lenient) == nil && p.Delta != "" {
 					if !yield(genai.Reply{Reasoning: p.Delta}) {
 						return
 					}
 				}
 			case MethodItemCompleted:
-				r := parseCompletedItem(msg.Params)
+				r := parseCompletedItem(msg.Params, c.lenient)
 				if r != nil {
 					replies = append(replies, *r)
 				}
 			case MethodTokenUsageUpdated:
 				var p ThreadTokenUsageUpdatedNotification
-				if internal.UnmarshalJSON(msg.Params, &p) == nil {
+				if internal.UnmarshalJSON(msg.Params, &p, c.lenient) == nil {
 					accumulateUsage(&usage, &p.TokenUsage)
 				}
 			case MethodTurnCompleted:
 				var p TurnCompletedNotification
-				if internal.UnmarshalJSON(msg.Params, &p) != nil {
+				if internal.UnmarshalJSON(msg.Params, &p, c.lenient) != nil {
 					continue
 				}
 				if p.Turn.Status == TurnStatusFailed || p.Turn.Status == TurnStatusInterrupted {
@@ -471,7 +495,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 				return
 			case MethodErrorNotification:
 				var p ErrorNotification
-				if internal.UnmarshalJSON(msg.Params, &p) == nil && !p.WillRetry && p.Error != nil {
+				if internal.UnmarshalJSON(msg.Params, &p, c.lenient) == nil && !p.WillRetry && p.Error != nil {
 					finalErr = fmt.Errorf("codex error: %s", p.Error.Message)
 					return
 				}
@@ -495,7 +519,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 // initAndListModels performs the JSON-RPC initialize → initialized →
 // model/list sequence and returns the model list. nextID is set to the last
 // used request ID so the caller can continue numbering.
-func initAndListModels(stdin io.Writer, sc *bufio.Scanner) ([]ModelInfo, error) {
+func initAndListModels(stdin io.Writer, sc *bufio.Scanner, lenient bool) ([]ModelInfo, error) {
 	// 1. Send initialize request.
 	params, err := marshalJSONRaw(InitializeParams{
 		ClientInfo:   ClientInfo{Name: "genai-codex", Title: "genai-codex", Version: "1.0.0"},
@@ -534,7 +558,7 @@ func initAndListModels(stdin io.Writer, sc *bufio.Scanner) ([]ModelInfo, error)
 		return nil, fmt.Errorf("read model/list response: %w", err)
 	}
 	var mlResult ModelListResult
-	if err := internal.UnmarshalJSON(mlData, &mlResult); err != nil {
+	if err := internal.UnmarshalJSON(mlData, &mlResult, lenient); err != nil {
 		return nil, fmt.Errorf("parse model/list result: %w", err)
 	}
 	return mlResult.Data, nil
@@ -542,8 +566,8 @@ func initAndListModels(stdin io.Writer, sc *bufio.Scanner) ([]ModelInfo, error)
 
 // handshake performs the JSON-RPC initialize → initialized → model/list →
 // thread/start (or thread/resume) sequence. Returns the thread ID.
-func handshake(stdin io.Writer, sc *bufio.Scanner, mdl, resumeThreadID string) (string, error) {
-	if _, err := initAndListModels(stdin, sc); err != nil {
+func handshake(stdin io.Writer, sc *bufio.Scanner, mdl, resumeThreadID string, lenient bool) (string, error) {
+	if _, err := initAndListModels(stdin, sc, lenient); err != nil {
 		return "", err
 	}
 
@@ -581,7 +605,7 @@ func handshake(stdin io.Writer, sc *bufio.Scanner, mdl, resumeThreadID string) (
 	}
 
 	var result ThreadStartResult
-	if err := internal.UnmarshalJSON(respData, &result); err != nil {
+	if err := internal.UnmarshalJSON(respData, &result, lenient); err != nil {
 		return "", fmt.Errorf("parse thread/start result: %w", err)
 	}
 	if result.Thread.ID == "" {
@@ -676,7 +700,7 @@ func docToTurnInput(doc genai.Doc) (TurnInput, error) {
 }
 
 // readTurnSync reads notifications from the scanner until turn/completed.
-func readTurnSync(sc *bufio.Scanner, threadID string) (genai.Result, error) {
+func readTurnSync(sc *bufio.Scanner, threadID string, lenient bool) (genai.Result, error) {
 	var (
 		replies []genai.Reply
 		usage   genai.Usage
@@ -701,18 +725,18 @@ func readTurnSync(sc *bufio.Scanner, threadID string) (genai.Result, error) {
 
 		switch msg.Method {
 		case MethodItemCompleted:
-			r := parseCompletedItem(msg.Params)
+			r := parseCompletedItem(msg.Params, lenient)
 			if r != nil {
 				replies = append(replies, *r)
 			}
 		case MethodTokenUsageUpdated:
 			var p ThreadTokenUsageUpdatedNotification
-			if internal.UnmarshalJSON(msg.Params, &p) == nil {
+			if internal.UnmarshalJSON(msg.Params, &p, lenient) == nil {
 				accumulateUsage(&usage, &p.TokenUsage)
 			}
 		case MethodTurnCompleted:
 			var p TurnCompletedNotification
-			if internal.UnmarshalJSON(msg.Params, &p) != nil {
+			if internal.UnmarshalJSON(msg.Params, &p, lenient) != nil {
 				continue
 			}
 			if p.Turn.Status == TurnStatusFailed || p.Turn.Status == TurnStatusInterrupted {
@@ -725,7 +749,7 @@ func readTurnSync(sc *bufio.Scanner, threadID string) (genai.Result, error) {
 			return buildResult(replies, &usage, threadID), nil
 		case MethodErrorNotification:
 			var p ErrorNotification
-			if internal.UnmarshalJSON(msg.Params, &p) == nil && !p.WillRetry && p.Error != nil {
+			if internal.UnmarshalJSON(msg.Params, &p, lenient) == nil && !p.WillRetry && p.Error != nil {
 				return genai.Result{}, fmt.Errorf("codex error: %s", p.Error.Message)
 			}
 		default:
@@ -739,9 +763,9 @@ func readTurnSync(sc *bufio.Scanner, threadID string) (genai.Result, error) {
 
 // parseCompletedItem extracts a Reply from an item/completed notification if
 // the item is an agentMessage or reasoning. Returns nil for other item types.
-func parseCompletedItem(params json.RawMessage) *genai.Reply {
+func parseCompletedItem(params json.RawMessage, lenient bool) *genai.Reply {
 	var p ItemCompletedNotification
-	if internal.UnmarshalJSON(params, &p) != nil {
+	if internal.UnmarshalJSON(params, &p, lenient) != nil {
 		return nil
 	}
 	var h ItemHeader
@@ -751,13 +775,13 @@ func parseCompletedItem(params json.RawMessage) *genai.Reply {
 	switch h.Type {
 	case ItemTypeAgentMessage:
 		var item AgentMessageItem
-		if internal.UnmarshalJSON(p.Item, &item) != nil || item.Text == "" {
+		if internal.UnmarshalJSON(p.Item, &item, lenient) != nil || item.Text == "" {
 			return nil
 		}
 		return &genai.Reply{Text: item.Text}
 	case ItemTypeReasoning:
 		var item ReasoningItem
-		if internal.UnmarshalJSON(p.Item, &item) != nil || len(item.Summary) == 0 {
+		if internal.UnmarshalJSON(p.Item, &item, lenient) != nil || len(item.Summary) == 0 {
 			return nil
 		}
 		return &genai.Reply{Reasoning: strings.Join(item.Summary, "\n")}
@@ -24,7 +24,7 @@ import (
 
 func newTestClient(t *testing.T, name string, opts ...genai.ProviderOption) *Client {
 	rec := internaltest.NewSubprocessRecorder(t, name, "codex")
-	opts = append(opts, genai.ProviderOptionStarterWrapper(rec.Wrap))
+	opts = append(opts, genai.ProviderOptionStrict(true), genai.ProviderOptionStarterWrapper(rec.Wrap))
 	c, err := New(opts...)
 	if err != nil {
 		t.Fatalf("New: %v", err)
@@ -130,7 +130,7 @@ func TestClient(t *testing.T) {
 				if rec, ok := wrapped.(*myrecorder.Recorder); ok {
 					name := strings.TrimSuffix(rec.Name(), ".yaml")
 					r := internaltest.NewSubprocessRecorder(t, name, "codex")
-					opts = append(opts, genai.ProviderOptionStarterWrapper(r.Wrap))
+					opts = append(opts, genai.ProviderOptionStrict(true), genai.ProviderOptionStarterWrapper(r.Wrap))
 				}
 			}
 			c, err := New(opts...)
@@ -15,7 +15,6 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
-	"github.com/maruel/genai/internal"
 )
 
 func TestReasoningEffort(t *testing.T) {
@@ -279,7 +278,3 @@ func TestUserMessageItem(t *testing.T) {
 		}
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
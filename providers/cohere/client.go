@@ -44,12 +44,71 @@ func Scoreboard() scoreboard.Score {
 	return s
 }
 
+// GenOption defines Cohere specific options.
+type GenOption struct {
+	// Documents are passed as the documents parameter, for the model to ground its answer on and cite.
+	//
+	// Unlike genai.Doc in the messages, they can carry arbitrary fields, e.g. "title", "text" and "url".
+	//
+	// https://docs.cohere.com/v2/docs/retrieval-augmented-generation-rag
+	Documents []Document
+	// CitationMode controls how citations are generated. When empty, the model default is used.
+	CitationMode CitationMode
+}
+
+// Validate implements genai.Validatable.
+func (o *GenOption) Validate() error {
+	for i := range o.Documents {
+		if len(o.Documents[i].Data) == 0 {
+			return fmt.Errorf("document %d: field Data: required", i)
+		}
+	}
+	return o.CitationMode.Validate()
+}
+
+// CitationMode controls the trade off between the latency and the precision of the citations.
+//
+// https://docs.cohere.com/v2/docs/rag-citations
+type CitationMode string
+
+// Citation mode values.
+const (
+	CitationModeFast     CitationMode = "fast"
+	CitationModeAccurate CitationMode = "accurate"
+	CitationModeOff      CitationMode = "off"
+)
+
+// Validate implements genai.Validatable.
+func (c CitationMode) Validate() error {
+	switch c {
+	case "", CitationModeFast, CitationModeAccurate, CitationModeOff:
+		return nil
+	default:
+		return fmt.Errorf("invalid CitationMode %q", c)
+	}
+}
+
+// ProviderOptionRerankModel sets the model used by Client.Rerank. Defaults to DefaultRerankModel.
+type ProviderOptionRerankModel string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionRerankModel) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionRerankModel cannot be empty")
+	}
+	return nil
+}
+
+// DefaultRerankModel is the model used by Client.Rerank unless ProviderOptionRerankModel is specified.
+const DefaultRerankModel = "rerank-v3.5"
+
 //
 
 // Client implements genai.Provider.
 type Client struct {
 	base.NotImplemented
-	impl base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]
+	impl        base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]
+	rerankModel string
 }
 
 // New creates a new client to talk to the Cohere platform API.
@@ -61,6 +120,8 @@ type Client struct {
 // Use one of the model from https://cohere.com/pricing and https://docs.cohere.com/v2/docs/models
 // To use multiple models, create multiple clients.
 //
+// The proxy defaults to the COHERE_PROXY environment variable, see genai.ProviderOptionProxyURL.
+//
 // # Tool use
 //
 // Tool use requires the use a model that supports structured output.
@@ -69,7 +130,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	rerankModel := DefaultRerankModel
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -86,8 +154,22 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case ProviderOptionRerankModel:
+			rerankModel = string(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -103,7 +185,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "COHERE_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -112,9 +194,12 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			GenSyncURL:      "https://api.cohere.com/v2/chat",
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
@@ -123,12 +208,13 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 				},
 			},
 		},
+		rerankModel: rerankModel,
 	}
 	if err == nil {
 		switch model {
 		case "":
 		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
-			if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 				return nil, err
 			}
 			c.impl.OutputModalities = mod
@@ -142,13 +228,15 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 
 // selectBestTextModel selects the most appropriate model based on the preference (cheap, good, or SOTA).
 //
-// We may want to make this function overridable in the future by the client since this is going to break one
-// day or another.
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
 func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
 	mdls, err := c.ListModels(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	cheap := preference == string(genai.ModelCheap)
 	good := preference == string(genai.ModelGood) || preference == ""
 	selectedModel := ""
@@ -202,6 +290,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -253,6 +346,26 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 	return resp.ToModels(), nil
 }
 
+// Rerank implements genai.ProviderRerank.
+//
+// It uses the model specified with ProviderOptionRerankModel, not the chat model.
+func (c *Client) Rerank(ctx context.Context, query string, documents []string, topN int) ([]genai.RerankResult, error) {
+	in := RerankRequest{Model: c.rerankModel, Query: query, Documents: documents, TopN: int64(topN)}
+	var out RerankResponse
+	if err := c.RerankRaw(ctx, &in, &out); err != nil {
+		return nil, err
+	}
+	return out.To(len(documents))
+}
+
+// RerankRaw provides access to the raw rerank API.
+//
+// The chat model is not required since the request specifies its own model.
+func (c *Client) RerankRaw(ctx context.Context, in *RerankRequest, out *RerankResponse) error {
+	// https://docs.cohere.com/reference/rerank
+	return c.impl.DoRequest(ctx, "POST", "https://api.cohere.com/v2/rerank", in, out)
+}
+
 // ProcessStream converts the raw packets from the streaming API into Reply fragments.
 func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error)) {
 	var finalErr error
@@ -302,7 +415,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.InputTokens = pkt.Delta.Usage.Tokens.InputTokens
 					u.InputCachedTokens = pkt.Delta.Usage.CachedTokens
 					u.OutputTokens = pkt.Delta.Usage.Tokens.OutputTokens
-					u.FinishReason = pkt.Delta.FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Delta.FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				case ChunkContentStart:
 					if len(pkt.Delta.Message.Content) != 1 {
 						finalErr = &internal.BadError{Err: fmt.Errorf("expected content %#v", pkt)}
@@ -371,10 +487,7 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 						return
 					}
 				default:
-					if !internal.BeLenient {
-						finalErr = &internal.BadError{Err: fmt.Errorf("unknown packet %q", pkt.Type)}
-						return
-					}
+					finalErr = errors.Join(finalErr, &base.ErrUnhandled{Err: fmt.Errorf("unknown packet %q", pkt.Type)})
 				}
 				if !yield(f) {
 					return
@@ -385,4 +498,8 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 		}
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+	_ genai.ProviderRerank         = &Client{}
+)
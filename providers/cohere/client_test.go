@@ -7,6 +7,8 @@
 package cohere_test
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"os"
 	"slices"
@@ -14,7 +16,6 @@ import (
 	"testing"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/cohere"
 	"github.com/maruel/genai/scoreboard"
@@ -35,6 +36,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return cohere.New(t.Context(), opts...)
 }
 
@@ -105,6 +107,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				provOpts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, provOpts...)
 			}
+			provOpts = append(provOpts, genai.ProviderOptionStrict(true))
 			c, err := cohere.New(t.Context(), provOpts...)
 			if err != nil {
 				t.Fatal(err)
@@ -166,6 +169,72 @@ func TestClient(t *testing.T) {
 	})
 }
 
-func init() {
-	internal.BeLenient = false
+func TestGenOption(t *testing.T) {
+	doc, err := cohere.NewDocument("a", "Quackiland", "The capital is Quack.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := genai.Messages{genai.NewTextMessage("What is the capital?")}
+	var c cohere.ChatRequest
+	opt := cohere.GenOption{Documents: []cohere.Document{doc}, CitationMode: cohere.CitationModeAccurate}
+	if err := c.Init(msgs, "command-a-03-2025", &opt); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Documents) != 1 || c.Documents[0].ID != "a" || string(c.Documents[0].Data["title"]) != `"Quackiland"` {
+		t.Fatalf("unexpected documents %#v", c.Documents)
+	}
+	if c.CitationOptions.Mode != "accurate" {
+		t.Fatalf("unexpected citation mode %q", c.CitationOptions.Mode)
+	}
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			opt  cohere.GenOption
+			want string
+		}{
+			{cohere.GenOption{Documents: []cohere.Document{{ID: "a"}}}, "document 0: field Data: required"},
+			{cohere.GenOption{CitationMode: "slow"}, `invalid CitationMode "slow"`},
+		}
+		for _, tt := range tests {
+			if err := tt.opt.Validate(); err == nil || err.Error() != tt.want {
+				t.Errorf("want %q, got %v", tt.want, err)
+			}
+		}
+	})
+}
+
+func TestRerank(t *testing.T) {
+	var got cohere.RerankRequest
+	fake := internaltest.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != "https://api.cohere.com/v2/rerank" {
+			t.Errorf("unexpected URL %s", req.URL)
+		}
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		body := `{"id":"1","results":[{"index":2,"relevance_score":0.9},{"index":0,"relevance_score":0.1}],"meta":{"billed_units":{"search_units":1}}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	c, err := cohere.New(t.Context(),
+		genai.ProviderOptionAPIKey("key"),
+		cohere.ProviderOptionRerankModel("rerank-v3.5"),
+		genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Rerank(t.Context(), "capital", []string{"a", "b", "c"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []genai.RerankResult{{Index: 2, Score: 0.9}, {Index: 0, Score: 0.1}}
+	if !slices.Equal(want, res) {
+		t.Fatalf("want %v, got %v", want, res)
+	}
+	if got.Model != "rerank-v3.5" || got.Query != "capital" || got.TopN != 2 || len(got.Documents) != 3 {
+		t.Fatalf("unexpected request %#v", got)
+	}
 }
@@ -75,6 +75,15 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			c.P = v.TopP
 			sp = v.SystemPrompt
 			c.K = v.TopK
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			if v.TopLogprobs > 0 {
 				c.Logprobs = true
 			}
@@ -91,7 +100,11 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				c.ResponseFormat.Type = "json_object"
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				switch v.Force {
 				case genai.ToolCallAny:
 				case genai.ToolCallRequired:
@@ -100,8 +113,8 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				case genai.ToolCallNone:
 					c.ToolChoice = "none"
 				}
-				c.Tools = make([]Tool, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
 					c.Tools[i].Type = "function"
 					c.Tools[i].Function.Name = t.Name
 					c.Tools[i].Function.Description = t.Description
@@ -114,6 +127,9 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			}
 		case genai.GenOptionSeed:
 			c.Seed = int64(v)
+		case *GenOption:
+			c.Documents = append(c.Documents, v.Documents...)
+			c.CitationOptions.Mode = string(v.CitationMode)
 		default:
 			unsupported = append(unsupported, internal.TypeName(opt))
 		}
@@ -385,20 +401,12 @@ func (c *Citations) UnmarshalJSON(b []byte) error {
 		*c = nil
 		return nil
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode((*[]Citation)(c)); err == nil {
+	if err := json.Unmarshal(b, (*[]Citation)(c)); err == nil {
 		return nil
 	}
 
 	v := Citation{}
-	d = json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode(&v); err != nil {
+	if err := json.Unmarshal(b, &v); err != nil {
 		return err
 	}
 	*c = Citations{v}
@@ -452,6 +460,8 @@ func (c *Citation) To(out *genai.Citation) error {
 			cs.Type = genai.CitationDocument
 			cs.ID = source.Document.ID
 			cs.Title = source.Document.Title
+			// The document ID is the filename, see Content.FromRequest.
+			cs.DocumentName = source.Document.ID
 			// The snippet is essentially the whole text. This is not ideal.
 			// cs.Snippet = source.Document.Snippet
 			cs.Snippet = c.Text
@@ -521,6 +531,12 @@ type Document struct {
 	Data map[string]json.RawMessage `json:"data,omitzero"`
 }
 
+// NewDocument returns a Document with the fields "title" and "snippet", for use in GenOption.Documents.
+func NewDocument(id, title, snippet string) (Document, error) {
+	data, err := newDocumentData(title, snippet)
+	return Document{ID: id, Data: data}, err
+}
+
 func newDocumentData(title, snippet string) (map[string]json.RawMessage, error) {
 	titleJSON, err := json.Marshal(title)
 	if err != nil {
@@ -536,6 +552,47 @@ func newDocumentData(title, snippet string) (map[string]json.RawMessage, error)
 	}, nil
 }
 
+// RerankRequest is documented at https://docs.cohere.com/reference/rerank
+type RerankRequest struct {
+	Model           string   `json:"model"`
+	Query           string   `json:"query"`
+	Documents       []string `json:"documents"`
+	TopN            int64    `json:"top_n,omitzero"`
+	MaxTokensPerDoc int64    `json:"max_tokens_per_doc,omitzero"` // Default 4096
+}
+
+// RerankResponse is documented at https://docs.cohere.com/reference/rerank
+type RerankResponse struct {
+	ID      string `json:"id"`
+	Results []struct {
+		Index          int64   `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+	Meta struct {
+		APIVersion struct {
+			Version        string `json:"version"`
+			IsDeprecated   bool   `json:"is_deprecated"`
+			IsExperimental bool   `json:"is_experimental"`
+		} `json:"api_version"`
+		BilledUnits struct {
+			SearchUnits int64 `json:"search_units"`
+		} `json:"billed_units"`
+		Warnings []string `json:"warnings"`
+	} `json:"meta"`
+}
+
+// To converts to the genai equivalent. n is the number of documents sent.
+func (r *RerankResponse) To(n int) ([]genai.RerankResult, error) {
+	out := make([]genai.RerankResult, len(r.Results))
+	for i := range r.Results {
+		if r.Results[i].Index < 0 || r.Results[i].Index >= int64(n) {
+			return nil, &internal.BadError{Err: fmt.Errorf("result %d: invalid index %d for %d documents", i, r.Results[i].Index, n)}
+		}
+		out[i] = genai.RerankResult{Index: int(r.Results[i].Index), Score: r.Results[i].RelevanceScore}
+	}
+	return out, nil
+}
+
 // ChatResponse is the response from the chat API.
 type ChatResponse struct {
 	ID           string          `json:"id"`
@@ -547,13 +604,14 @@ type ChatResponse struct {
 
 // ToResult converts the ChatResponse to a genai.Result.
 func (c *ChatResponse) ToResult() (genai.Result, error) {
+	fr, errFR := c.FinishReason.ToFinishReason()
 	out := genai.Result{
 		Usage: genai.Usage{
 			// What about BilledUnits, especially for SearchUnits and Classifications?
 			InputTokens:       c.Usage.Tokens.InputTokens,
 			InputCachedTokens: c.Usage.CachedTokens,
 			OutputTokens:      c.Usage.Tokens.OutputTokens,
-			FinishReason:      c.FinishReason.ToFinishReason(),
+			FinishReason:      fr,
 		},
 	}
 	if len(c.Logprobs) != 0 {
@@ -563,7 +621,7 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 		}
 	}
 	// It is very frustrating that Cohere uses different message response types.
-	err := c.Message.To(&out.Message)
+	err := errors.Join(errFR, c.Message.To(&out.Message))
 	return out, err
 }
 
@@ -580,23 +638,22 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishComplete:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishToolCall:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishMaxTokens:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishStopSequence:
-		return genai.FinishedStopSequence
+		return genai.FinishedStopSequence, nil
 	case FinishError:
-		return "Error"
+		return "Error", nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(strings.ToLower(string(f)))
+		return genai.FinishReason(strings.ToLower(string(f))), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -653,8 +710,9 @@ type MessageResponse struct {
 
 // To converts a MessageResponse to a genai.Message.
 func (m *MessageResponse) To(out *genai.Message) error {
-	if m.ToolCallID != "" && !internal.BeLenient {
-		return errors.New("implement tool call id")
+	var err error
+	if m.ToolCallID != "" {
+		err = &base.ErrUnhandled{Err: errors.New("implement tool call id")}
 	}
 	if m.ToolPlan != "" {
 		out.Replies = []genai.Reply{{Reasoning: m.ToolPlan}}
@@ -678,7 +736,7 @@ func (m *MessageResponse) To(out *genai.Message) error {
 		out.Replies = append(out.Replies, genai.Reply{})
 		m.ToolCalls[i].To(&out.Replies[len(out.Replies)-1].ToolCall)
 	}
-	return nil
+	return err
 }
 
 // Contents is a slice of Content with custom unmarshalling.
@@ -690,20 +748,12 @@ func (c *Contents) UnmarshalJSON(b []byte) error {
 		*c = nil
 		return nil
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode((*[]Content)(c)); err == nil {
+	if err := json.Unmarshal(b, (*[]Content)(c)); err == nil {
 		return nil
 	}
 
 	v := Content{}
-	d = json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode(&v); err != nil {
+	if err := json.Unmarshal(b, &v); err != nil {
 		return err
 	}
 	if !v.IsZero() {
@@ -757,20 +807,12 @@ func (t *ToolCalls) UnmarshalJSON(b []byte) error {
 		*t = nil
 		return nil
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode((*[]ToolCall)(t)); err == nil {
+	if err := json.Unmarshal(b, (*[]ToolCall)(t)); err == nil {
 		return nil
 	}
 
 	tc := ToolCall{}
-	d = json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode(&tc); err != nil {
+	if err := json.Unmarshal(b, &tc); err != nil {
 		return err
 	}
 	if !tc.IsZero() {
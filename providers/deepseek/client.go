@@ -55,11 +55,19 @@ type Client struct {
 //
 // To use multiple models, create multiple clients.
 // Use one of the model from https://api-docs.deepseek.com/quick_start/pricing
+//
+// The proxy defaults to the DEEPSEEK_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -76,8 +84,20 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -93,7 +113,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "DEEPSEEK_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -102,9 +122,12 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			GenSyncURL:      "https://api.deepseek.com/chat/completions",
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
@@ -118,7 +141,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		switch model {
 		case "":
 		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
-			if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 				return nil, err
 			}
 			c.impl.OutputModalities = mod
@@ -132,13 +155,15 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 
 // selectBestTextModel selects the most appropriate model based on the preference (cheap, good, or SOTA).
 //
-// We may want to make this function overridable in the future by the client since this is going to break one
-// day or another.
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
 func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
 	mdls, err := c.ListModels(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	want := "deepseek-v4-pro"
 	if preference == string(genai.ModelCheap) {
 		want = "deepseek-v4-flash"
@@ -165,6 +190,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -238,7 +268,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.InputCachedTokens = pkt.Usage.PromptCacheHitTokens
 					u.ReasoningTokens = pkt.Usage.ChatTokensDetails.ReasoningTokens
 					u.OutputTokens = pkt.Usage.CompletionTokens
-					u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				if len(pkt.Choices[0].Delta.ToolCalls) > 1 {
 					finalErr = &internal.BadError{Err: fmt.Errorf("implement multiple tool calls: %#v", pkt)}
@@ -292,4 +325,7 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 		}
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+)
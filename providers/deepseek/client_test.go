@@ -12,7 +12,6 @@ import (
 	"testing"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/deepseek"
 	"github.com/maruel/genai/scoreboard"
@@ -33,6 +32,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return deepseek.New(t.Context(), opts...)
 }
 
@@ -97,6 +97,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := deepseek.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -160,7 +161,3 @@ func TestClient(t *testing.T) {
 		internaltest.TestClientProviderErrors(t, f, data)
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
@@ -120,6 +120,22 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.TopK != 0 {
 				unsupported = append(unsupported, "GenOptionText.TopK")
 			}
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" && c.Thinking.Type == "" && c.Thinking.ReasoningEffort == "" {
+				switch v.ReasoningEffort {
+				case genai.ReasoningEffortOff:
+					c.Thinking.Type = "disabled"
+				case genai.ReasoningEffortHigh:
+					c.Thinking.ReasoningEffort = string(ReasoningEffortHigh)
+				default:
+					unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+				}
+			}
 			c.Stop = v.Stop
 			if v.ReplyAsJSON {
 				c.ResponseFormat.Type = "json_object"
@@ -128,7 +144,11 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				errs = append(errs, errors.New("unsupported option DecodeAs"))
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				switch v.Force {
 				case genai.ToolCallAny:
 					c.ToolChoice = "auto"
@@ -137,8 +157,8 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				case genai.ToolCallNone:
 					c.ToolChoice = "none"
 				}
-				c.Tools = make([]Tool, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
 					c.Tools[i].Type = "function"
 					c.Tools[i].Function.Name = t.Name
 					c.Tools[i].Function.Description = t.Description
@@ -382,10 +402,11 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	if len(c.Choices) != 1 {
 		return out, fmt.Errorf("expected 1 choice, got %#v", c.Choices)
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
 	c.Choices[0].Message.To(&out.Message)
 	out.Logprobs = c.Choices[0].Logprobs.To()
-	return out, nil
+	return out, err
 }
 
 // FinishReason is a provider-specific finish reason.
@@ -401,26 +422,22 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishContentFilter:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	case FinishInsufficient:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
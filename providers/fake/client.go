@@ -0,0 +1,386 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package fake implements a provider with scripted responses for deterministic tests.
+//
+// Each request consumes the next Turn of the Script: text, reasoning, tool calls, usage, an error or latency.
+// Streaming splits the text in chunks of a configurable size. This is useful to test agent loops without
+// network access nor recordings. Use genaitest to record a real provider instead.
+//
+// It is not part of the providers.All registry.
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/scoreboard"
+)
+
+// Script is the list of scripted responses.
+type Script struct {
+	// Turns are the responses, one per GenSync or GenStream call, in order.
+	Turns []Turn `json:"turns"`
+	// Loop restarts at the first turn once all turns were used. Otherwise further calls fail.
+	Loop bool `json:"loop,omitzero"`
+	// ChunkSize is the number of characters per text and reasoning fragment when streaming. 0 sends each in a
+	// single fragment.
+	ChunkSize int `json:"chunk_size,omitzero"`
+	// ChunkDelay is the delay between fragments when streaming.
+	ChunkDelay Duration `json:"chunk_delay,omitzero"`
+	// ContextTokens is the model context reported by ListModels.
+	ContextTokens int64 `json:"context_tokens,omitzero"`
+
+	_ struct{}
+}
+
+// Validate implements genai.Validatable.
+func (s *Script) Validate() error {
+	if s.ChunkSize < 0 {
+		return errors.New("field ChunkSize: must be positive")
+	}
+	if s.ChunkDelay < 0 {
+		return errors.New("field ChunkDelay: must be positive")
+	}
+	for i := range s.Turns {
+		if err := s.Turns[i].Validate(); err != nil {
+			return fmt.Errorf("field Turns: item #%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// LoadScript loads a JSON encoded Script.
+func LoadScript(path string) (*Script, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Script{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Turn is a scripted response.
+type Turn struct {
+	// Reasoning is the reasoning reply, sent before the text.
+	Reasoning string `json:"reasoning,omitzero"`
+	// Text is the text reply.
+	Text string `json:"text,omitzero"`
+	// ToolCalls are sent after the text. An ID is generated when empty.
+	ToolCalls []genai.ToolCall `json:"tool_calls,omitzero"`
+	// Usage is returned as-is, except FinishReason which defaults to genai.FinishedToolCalls when there are
+	// tool calls and genai.FinishedStop otherwise.
+	Usage genai.Usage `json:"usage,omitzero"`
+	// Latency is the delay before the response.
+	Latency Duration `json:"latency,omitzero"`
+	// Error is returned as an error instead of the reply.
+	Error string `json:"error,omitzero"`
+	// Err is like Error for Go scripts, e.g. to return a *base.ErrAPI. It has precedence over Error.
+	Err error `json:"-"`
+
+	_ struct{}
+}
+
+// Validate implements genai.Validatable.
+func (t *Turn) Validate() error {
+	if t.Latency < 0 {
+		return errors.New("field Latency: must be positive")
+	}
+	for i := range t.ToolCalls {
+		if t.ToolCalls[i].Name == "" {
+			return fmt.Errorf("field ToolCalls: item #%d: field Name: required", i)
+		}
+		if t.ToolCalls[i].Arguments == "" {
+			continue
+		}
+		if err := t.ToolCalls[i].Validate(); err != nil {
+			return fmt.Errorf("field ToolCalls: item #%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Duration is a time.Duration encoded in JSON as a string like "1.5s".
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	*d = Duration(v)
+	return err
+}
+
+// Client is a provider replying with a Script.
+//
+// It is safe for concurrent use; the turns are consumed in the order the requests are received.
+type Client struct {
+	base.NotImplemented
+	script Script
+	model  string
+
+	mu       sync.Mutex
+	next     int
+	requests []genai.Messages
+}
+
+// New returns a Client replaying script.
+//
+// The only supported option is genai.ProviderOptionModel, which sets ModelID. It defaults to "fake".
+func New(script *Script, opts ...genai.ProviderOption) (*Client, error) {
+	if err := script.Validate(); err != nil {
+		return nil, err
+	}
+	c := &Client{script: *script, model: "fake"}
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return nil, err
+		}
+		switch v := opt.(type) {
+		case genai.ProviderOptionModel:
+			c.model = string(v)
+		default:
+			return nil, fmt.Errorf("unsupported option type %T", opt)
+		}
+	}
+	return c, nil
+}
+
+// Name implements genai.Provider.
+func (c *Client) Name() string {
+	return "fake"
+}
+
+// ModelID implements genai.Provider.
+func (c *Client) ModelID() string {
+	return c.model
+}
+
+// OutputModalities implements genai.Provider.
+func (c *Client) OutputModalities() genai.Modalities {
+	return genai.Modalities{genai.ModalityText}
+}
+
+// Scoreboard implements genai.Provider.
+func (c *Client) Scoreboard() scoreboard.Score {
+	return scoreboard.Score{}
+}
+
+// HTTPClient implements genai.Provider.
+func (c *Client) HTTPClient() *http.Client {
+	return http.DefaultClient
+}
+
+// ListModels implements genai.Provider.
+func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
+	return []genai.Model{&Model{ID: c.model, ContextTokens: c.script.ContextTokens}}, nil
+}
+
+// Requests returns the messages received so far, one per call.
+func (c *Client) Requests() []genai.Messages {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.requests)
+}
+
+// Remaining returns the number of turns not yet used. It is always the number of turns with Loop.
+func (c *Client) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.script.Turns) - c.next
+}
+
+// GenSync implements genai.Provider.
+func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	t, err := c.turn(ctx, msgs, opts)
+	if err != nil {
+		return genai.Result{}, err
+	}
+	res := genai.Result{Usage: t.usage()}
+	for _, f := range t.fragments(0) {
+		if err := res.Accumulate(&f); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// GenStream implements genai.Provider.
+func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	res := genai.Result{}
+	var finalErr error
+	fragments := func(yield func(genai.Reply) bool) {
+		t, err := c.turn(ctx, msgs, opts)
+		if err != nil {
+			finalErr = err
+			return
+		}
+		res.Usage = t.usage()
+		for i, f := range t.fragments(c.script.ChunkSize) {
+			if i != 0 {
+				if finalErr = sleep(ctx, time.Duration(c.script.ChunkDelay)); finalErr != nil {
+					return
+				}
+			}
+			if finalErr = res.Accumulate(&f); finalErr != nil {
+				return
+			}
+			if !yield(f) {
+				return
+			}
+		}
+	}
+	return fragments, func() (genai.Result, error) {
+		return res, finalErr
+	}
+}
+
+// Model is the model returned by ListModels.
+type Model struct {
+	ID            string
+	ContextTokens int64
+}
+
+// GetID implements genai.Model.
+func (m *Model) GetID() string {
+	return m.ID
+}
+
+func (m *Model) String() string {
+	return m.ID
+}
+
+// Context implements genai.Model.
+func (m *Model) Context() int64 {
+	return m.ContextTokens
+}
+
+//
+
+// turn records the request and returns the next turn after its latency.
+func (c *Client) turn(ctx context.Context, msgs genai.Messages, opts []genai.GenOption) (*Turn, error) {
+	if err := msgs.Validate(); err != nil {
+		return nil, err
+	}
+	for _, o := range opts {
+		if err := o.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	c.mu.Lock()
+	c.requests = append(c.requests, msgs)
+	if c.next == len(c.script.Turns) {
+		if !c.script.Loop || len(c.script.Turns) == 0 {
+			c.mu.Unlock()
+			return nil, fmt.Errorf("script exhausted after %d turns", len(c.script.Turns))
+		}
+		c.next = 0
+	}
+	i := c.next
+	c.next++
+	c.mu.Unlock()
+	t := &c.script.Turns[i]
+	if err := sleep(ctx, time.Duration(t.Latency)); err != nil {
+		return nil, err
+	}
+	if t.Err != nil {
+		return nil, t.Err
+	}
+	if t.Error != "" {
+		return nil, errors.New(t.Error)
+	}
+	return t, nil
+}
+
+func (t *Turn) usage() genai.Usage {
+	u := t.Usage
+	if u.FinishReason == "" {
+		u.FinishReason = genai.FinishedStop
+		if len(t.ToolCalls) != 0 {
+			u.FinishReason = genai.FinishedToolCalls
+		}
+	}
+	return u
+}
+
+// fragments returns the replies split in chunks of size characters, or unsplit when 0.
+func (t *Turn) fragments(size int) []genai.Reply {
+	var out []genai.Reply
+	for _, s := range chunks(t.Reasoning, size) {
+		out = append(out, genai.Reply{Reasoning: s})
+	}
+	for _, s := range chunks(t.Text, size) {
+		out = append(out, genai.Reply{Text: s})
+	}
+	for i, tc := range t.ToolCalls {
+		if tc.ID == "" {
+			tc.ID = "call_" + strconv.Itoa(i)
+		}
+		if tc.Arguments == "" {
+			tc.Arguments = "{}"
+		}
+		out = append(out, genai.Reply{ToolCall: tc})
+	}
+	return out
+}
+
+// chunks splits s in chunks of size runes.
+func chunks(s string, size int) []string {
+	if s == "" {
+		return nil
+	}
+	if size <= 0 {
+		return []string{s}
+	}
+	var out []string
+	for s != "" {
+		i, n := 0, 0
+		for ; i < len(s) && n < size; n++ {
+			_, w := utf8.DecodeRuneInString(s[i:])
+			i += w
+		}
+		out = append(out, s[:i])
+		s = s[i:]
+	}
+	return out
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+var _ genai.Provider = &Client{}
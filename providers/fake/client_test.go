@@ -0,0 +1,161 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/adapters"
+	"github.com/maruel/genai/providers/fake"
+)
+
+func TestClient_GenSync(t *testing.T) {
+	c, err := fake.New(&fake.Script{Turns: []fake.Turn{
+		{Reasoning: "hmm", Text: "hello", Usage: genai.Usage{InputTokens: 1, OutputTokens: 2}},
+		{Error: "boom"},
+	}}, genai.ProviderOptionModel("llm-sota"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.String() != "hello" || res.Reasoning() != "hmm" || res.Usage.OutputTokens != 2 || res.Usage.FinishReason != genai.FinishedStop {
+		t.Fatalf("unexpected %#v", res)
+	}
+	if _, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}); err == nil || err.Error() != "boom" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}); err == nil || err.Error() != "script exhausted after 2 turns" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if n := len(c.Requests()); n != 3 {
+		t.Fatalf("got %d requests, want 3", n)
+	}
+	if c.ModelID() != "llm-sota" {
+		t.Fatalf("unexpected model %q", c.ModelID())
+	}
+}
+
+func TestClient_GenStream(t *testing.T) {
+	c, err := fake.New(&fake.Script{ChunkSize: 2, Loop: true, Turns: []fake.Turn{{Text: "héllo"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		fragments, finish := c.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("hi")})
+		var got []string
+		for f := range fragments {
+			got = append(got, f.Text)
+		}
+		res, err := finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 3 || got[0] != "hé" || got[2] != "o" || res.String() != "héllo" {
+			t.Fatalf("unexpected %q, %q", got, res.String())
+		}
+	}
+	if c.Remaining() != 0 {
+		t.Fatalf("unexpected remaining %d", c.Remaining())
+	}
+}
+
+func TestClient_toolLoop(t *testing.T) {
+	c, err := fake.New(&fake.Script{Turns: []fake.Turn{
+		{ToolCalls: []genai.ToolCall{{Name: "add", Arguments: `{"a":1,"b":2}`}}},
+		{Text: "It is 3."},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type args struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	}
+	tools := &genai.GenOptionTools{Tools: []genai.ToolDef{{
+		Name:        "add",
+		Description: "Adds two numbers",
+		Callback:    func(ctx context.Context, a *args) (string, error) { return strconv.Itoa(a.A + a.B), nil },
+	}}}
+	msgs, _, err := adapters.GenSyncWithToolCallLoop(t.Context(), c, genai.Messages{genai.NewTextMessage("1+2?")}, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 3 || msgs[2].String() != "It is 3." {
+		t.Fatalf("unexpected %v", msgs)
+	}
+	reqs := c.Requests()
+	if len(reqs) != 2 || reqs[1][2].ToolCallResults[0].Result != "3" || reqs[1][2].ToolCallResults[0].ID != "call_0" {
+		t.Fatalf("unexpected requests %v", reqs)
+	}
+}
+
+func TestClient_latency(t *testing.T) {
+	c, err := fake.New(&fake.Script{Turns: []fake.Turn{{Text: "late", Latency: fake.Duration(time.Hour)}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+	defer cancel()
+	if _, err := c.GenSync(ctx, genai.Messages{genai.NewTextMessage("hi")}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestLoadScript(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "script.json")
+	data := `{"turns": [{"text": "hi", "latency": "1ms", "tool_calls": [{"name": "ls", "arguments": "{}"}]}], "context_tokens": 1000}`
+	if err := os.WriteFile(p, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := fake.LoadScript(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := fake.New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Usage.FinishReason != genai.FinishedToolCalls || res.Replies[1].ToolCall.ID != "call_0" {
+		t.Fatalf("unexpected %#v", res)
+	}
+	mdls, err := c.ListModels(t.Context())
+	if err != nil || len(mdls) != 1 || mdls[0].Context() != 1000 {
+		t.Fatalf("unexpected %v, %v", mdls, err)
+	}
+}
+
+func TestNew_errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script fake.Script
+		opts   []genai.ProviderOption
+		errMsg string
+	}{
+		{"chunk", fake.Script{ChunkSize: -1}, nil, "field ChunkSize: must be positive"},
+		{"tool", fake.Script{Turns: []fake.Turn{{ToolCalls: []genai.ToolCall{{}}}}}, nil, "field Turns: item #0: field ToolCalls: item #0: field Name: required"},
+		{"option", fake.Script{}, []genai.ProviderOption{genai.ProviderOptionAPIKey("a")}, "unsupported option type genai.ProviderOptionAPIKey"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := fake.New(&tc.script, tc.opts...); err == nil || err.Error() != tc.errMsg {
+				t.Fatalf("error mismatch\nwant %q\ngot  %v", tc.errMsg, err)
+			}
+		})
+	}
+}
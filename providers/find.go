@@ -0,0 +1,51 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package providers
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/maruel/genai/scoreboard"
+)
+
+// Match is a provider and model meeting the requirements passed to FindProviders.
+type Match struct {
+	// Provider is the key in the registry.
+	Provider string
+	// Model is the first model of the scenario. Pass it as genai.ProviderOptionModel to the Factory.
+	Model string
+	// Scenario is the matching scenario of the provider's scoreboard.
+	Scenario *scoreboard.Scenario
+	// Config is the registry entry.
+	Config Config
+}
+
+// FindProviders returns the providers and models in cfgs whose scoreboard meets the requirements, so
+// applications can select a model by its features instead of hardcoding its name.
+//
+// Pass All to search every provider, or the result of Available to only search the providers usable with the
+// current environment. The matches are sorted by provider name and then in scoreboard order, which lists the
+// SOTA model first. Aliases are skipped to not return the same provider twice.
+func FindProviders(cfgs map[string]Config, req *scoreboard.Requirements) ([]Match, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	var out []Match
+	for _, name := range slices.Sorted(maps.Keys(cfgs)) {
+		cfg := cfgs[name]
+		if cfg.Alias != "" || cfg.Scoreboard == nil {
+			continue
+		}
+		sb := cfg.Scoreboard()
+		for i := range sb.Scenarios {
+			sc := &sb.Scenarios[i]
+			if len(sc.Models) != 0 && req.Match(sc) {
+				out = append(out, Match{Provider: name, Model: sc.Models[0], Scenario: sc, Config: cfg})
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,336 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package fireworks implements a client for the Fireworks AI inference API.
+//
+// It is described at https://docs.fireworks.ai/api-reference/post-chatcompletions
+//
+// Fireworks offers an OpenAI-compatible chat completions endpoint with extensions: GBNF grammar constrained
+// decoding via genai.GenOptionText.Grammar and document inlining, which converts PDFs and images to text so
+// any model can process them.
+package fireworks
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/maruel/roundtrippers"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/scoreboard"
+)
+
+//go:embed scoreboard.json
+var scoreboardJSON []byte
+
+// Scoreboard for Fireworks.
+func Scoreboard() scoreboard.Score {
+	var s scoreboard.Score
+	d := json.NewDecoder(bytes.NewReader(scoreboardJSON))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&s); err != nil {
+		panic(fmt.Errorf("failed to unmarshal scoreboard.json: %w", err))
+	}
+	return s
+}
+
+// Client implements genai.Provider.
+type Client struct {
+	base.NotImplemented
+	impl base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]
+}
+
+// New creates a new client to talk to the Fireworks AI inference API.
+//
+// If apiKey is not provided via ProviderOptionAPIKey, it tries to load it from the FIREWORKS_API_KEY environment
+// variable. If none is found, it will still return a client coupled with a base.ErrAPIKeyRequired error.
+// Get an API key at https://fireworks.ai/account/api-keys
+//
+// To use multiple models, create multiple clients.
+// Use one of the models from https://fireworks.ai/models, e.g. "accounts/fireworks/models/gpt-oss-120b".
+//
+// The proxy defaults to the FIREWORKS_PROXY environment variable, see genai.ProviderOptionProxyURL.
+func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
+	var apiKey, model string
+	var modalities genai.Modalities
+	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
+	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
+	if err := base.CheckDuplicateOptions(opts); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return nil, err
+		}
+		switch v := opt.(type) {
+		case genai.ProviderOptionAPIKey:
+			apiKey = string(v)
+		case genai.ProviderOptionModel:
+			model = string(v)
+		case genai.ProviderOptionModalities:
+			modalities = genai.Modalities(v)
+		case genai.ProviderOptionPreloadedModels:
+			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
+		case genai.ProviderOptionTransportWrapper:
+			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		default:
+			return nil, fmt.Errorf("unsupported option type %T", opt)
+		}
+	}
+	const apiKeyURL = "https://fireworks.ai/account/api-keys"
+	var err error
+	if apiKey == "" {
+		if apiKey = os.Getenv("FIREWORKS_API_KEY"); apiKey == "" {
+			err = &base.ErrAPIKeyRequired{EnvVar: "FIREWORKS_API_KEY", URL: apiKeyURL}
+		}
+	}
+	mod := genai.Modalities{genai.ModalityText}
+	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
+		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
+	}
+	t := base.NewTransport(httpOpts, proxyURL, "FIREWORKS_PROXY")
+	if wrapper != nil {
+		t = wrapper(t)
+	}
+	c := &Client{
+		impl: base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]{
+			GenSyncURL:      "https://api.fireworks.ai/inference/v1/chat/completions",
+			ProcessStream:   ProcessStream,
+			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
+			ProviderBase: base.ProviderBase[*ErrorResponse]{
+				APIKeyURL: apiKeyURL,
+				Lenient:   lenient,
+				Log:       logger,
+				Client: http.Client{
+					Transport: &roundtrippers.Header{
+						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
+						Transport: &roundtrippers.RequestID{Transport: t},
+					},
+				},
+			},
+		},
+	}
+	if err == nil {
+		switch model {
+		case "":
+		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
+				return nil, err
+			}
+			c.impl.OutputModalities = mod
+		default:
+			c.impl.Model = model
+			c.impl.OutputModalities = mod
+		}
+	}
+	return c, err
+}
+
+// selectBestTextModel selects the most appropriate model based on the preference (cheap, good, or SOTA).
+//
+// Fireworks hosts hundreds of models, including fine tunes, so it uses the reference model from the scoreboard
+// and only confirms it is still served.
+func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
+	mdls, err := c.ListModels(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to automatically select the model: %w", err)
+	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
+	want := ""
+	for _, sc := range Scoreboard().Scenarios {
+		if len(sc.Models) == 0 {
+			continue
+		}
+		if (preference == string(genai.ModelCheap) && sc.Cheap) ||
+			(preference == string(genai.ModelGood) && sc.Good) ||
+			(preference == string(genai.ModelSOTA) && sc.SOTA) {
+			want = sc.Models[0]
+			break
+		}
+	}
+	if want == "" {
+		return "", errors.New("no reference model found in scoreboard")
+	}
+	for _, mdl := range mdls {
+		if mdl.GetID() == want {
+			return want, nil
+		}
+	}
+	return "", fmt.Errorf("failed to find preferred model %q", want)
+}
+
+// Name implements genai.Provider.
+func (c *Client) Name() string {
+	return "fireworks"
+}
+
+// ModelID implements genai.Provider.
+func (c *Client) ModelID() string {
+	return c.impl.Model
+}
+
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
+// OutputModalities implements genai.Provider.
+func (c *Client) OutputModalities() genai.Modalities {
+	return c.impl.OutputModalities
+}
+
+// Scoreboard implements genai.Provider.
+func (c *Client) Scoreboard() scoreboard.Score {
+	return Scoreboard()
+}
+
+// HTTPClient returns the HTTP client to fetch results generated by the provider.
+func (c *Client) HTTPClient() *http.Client {
+	return &c.impl.Client
+}
+
+// GenSync implements genai.Provider.
+func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	return c.impl.GenSync(ctx, msgs, opts...)
+}
+
+// GenSyncRaw provides access to the raw API.
+func (c *Client) GenSyncRaw(ctx context.Context, in *ChatRequest, out *ChatResponse) error {
+	return c.impl.GenSyncRaw(ctx, in, out)
+}
+
+// GenStream implements genai.Provider.
+func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	return c.impl.GenStream(ctx, msgs, opts...)
+}
+
+// GenStreamRaw provides access to the raw API.
+func (c *Client) GenStreamRaw(ctx context.Context, in *ChatRequest) (iter.Seq[ChatStreamChunkResponse], func() error) {
+	return c.impl.GenStreamRaw(ctx, in)
+}
+
+// ListModels implements genai.Provider.
+//
+// It only lists the serverless models, not the models deployed on dedicated deployments.
+func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
+	if c.impl.PreloadedModels != nil {
+		return c.impl.PreloadedModels, nil
+	}
+	// https://docs.fireworks.ai/tools-sdks/openai-compatibility
+	var resp ModelsResponse
+	if err := c.impl.DoRequest(ctx, "GET", "https://api.fireworks.ai/inference/v1/models", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.ToModels(), nil
+}
+
+// ProcessStream converts the raw packets from the streaming API into Reply fragments.
+func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error)) {
+	var finalErr error
+	u := genai.Usage{}
+	var l [][]genai.Logprob
+
+	return func(yield func(genai.Reply) bool) {
+			pendingToolCall := ToolCall{}
+			for pkt := range chunks {
+				if pkt.Usage.TotalTokens != 0 {
+					u.InputTokens = pkt.Usage.PromptTokens
+					u.InputCachedTokens = pkt.Usage.PromptTokensDetails.CachedTokens
+					u.OutputTokens = pkt.Usage.CompletionTokens
+					u.TotalTokens = pkt.Usage.TotalTokens
+				}
+				if len(pkt.Choices) != 1 {
+					continue
+				}
+				switch role := pkt.Choices[0].Delta.Role; role {
+				case "", "assistant":
+				default:
+					finalErr = &internal.BadError{Err: fmt.Errorf("unexpected role %q", role)}
+					return
+				}
+				if fr := pkt.Choices[0].FinishReason; fr != "" {
+					var err error
+					if u.FinishReason, err = fr.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
+				}
+				for _, nt := range pkt.Choices[0].Delta.ToolCalls {
+					switch {
+					case pendingToolCall.ID == "":
+						pendingToolCall = nt
+					case nt.ID == "" || nt.ID == pendingToolCall.ID:
+						// Continuation.
+						pendingToolCall.Function.Arguments += nt.Function.Arguments
+					default:
+						f := genai.Reply{}
+						pendingToolCall.To(&f.ToolCall)
+						if !yield(f) {
+							return
+						}
+						pendingToolCall = nt
+					}
+				}
+				if r := cmp.Or(pkt.Choices[0].Delta.ReasoningContent, pkt.Choices[0].Delta.Reasoning); r != "" {
+					if !yield(genai.Reply{Reasoning: r}) {
+						return
+					}
+				}
+				if t := pkt.Choices[0].Delta.Content; t != "" {
+					if !yield(genai.Reply{Text: t}) {
+						return
+					}
+				}
+				if len(pkt.Choices[0].Logprobs.Content) != 0 {
+					l = append(l, pkt.Choices[0].Logprobs.To()...)
+				}
+			}
+			if pendingToolCall.ID != "" {
+				f := genai.Reply{}
+				pendingToolCall.To(&f.ToolCall)
+				if !yield(f) {
+					return
+				}
+			}
+		}, func() (genai.Usage, [][]genai.Logprob, error) {
+			return u, l, finalErr
+		}
+}
+
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+)
@@ -0,0 +1,122 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the Fireworks AI provider client.
+
+package fireworks_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/providers/fireworks"
+)
+
+func TestClient(t *testing.T) {
+	var body []byte
+	fake := internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			t.Fatal(err)
+		}
+		const resp = `{"id":"1","object":"chat.completion","created":1760000000,"model":"accounts/fireworks/models/gpt-oss-20b",` +
+			`"choices":[{"index":0,"message":{"role":"assistant","content":"yes","reasoning_content":"Easy."},"finish_reason":"stop"}],` +
+			`"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(resp)),
+			Request:    r,
+		}, nil
+	})
+	c, err := fireworks.New(t.Context(),
+		genai.ProviderOptionAPIKey("key"),
+		genai.ProviderOptionModel("accounts/fireworks/models/gpt-oss-20b"),
+		genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }),
+		genai.ProviderOptionStrict(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := genai.GenOptionText{Grammar: `root ::= "yes" | "no"`}
+	res, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Is the sky blue?")}, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"response_format":{"type":"grammar","grammar":"root ::= \"yes\" | \"no\""}`) {
+		t.Errorf("unexpected request: %s", body)
+	}
+	if res.String() != "yes" || res.Replies[0].Reasoning != "Easy." {
+		t.Errorf("unexpected replies: %#v", res.Replies)
+	}
+	if res.Usage.InputTokens != 12 || res.Usage.OutputTokens != 3 || res.Usage.FinishReason != genai.FinishedStop {
+		t.Errorf("unexpected usage: %#v", res.Usage)
+	}
+}
+
+func TestGenOption(t *testing.T) {
+	msgs := genai.Messages{
+		genai.NewTextMessage("Summarize."),
+	}
+	msgs[0].Requests = append(msgs[0].Requests,
+		genai.Request{Doc: genai.Doc{URL: "https://example.com/a.jpg"}},
+		genai.Request{Doc: genai.Doc{Filename: "b.pdf", Src: strings.NewReader("%PDF-1.4")}},
+	)
+	t.Run("pdf", func(t *testing.T) {
+		var req fireworks.ChatRequest
+		if err := req.Init(msgs, "model"); err != nil {
+			t.Fatal(err)
+		}
+		c := req.Messages[0].Content
+		if len(c) != 3 {
+			t.Fatalf("got %d contents", len(c))
+		}
+		if got := c[1].ImageURL.URL; got != "https://example.com/a.jpg" {
+			t.Errorf("image URL = %q", got)
+		}
+		if got := c[2].ImageURL.URL; got != "data:application/pdf;base64,JVBERi0xLjQ=#transform=inline" {
+			t.Errorf("pdf URL = %q", got)
+		}
+	})
+	t.Run("inlining", func(t *testing.T) {
+		var req fireworks.ChatRequest
+		if err := req.Init(msgs, "model", &fireworks.GenOption{DocumentInlining: true}); err != nil {
+			t.Fatal(err)
+		}
+		c := req.Messages[0].Content
+		if got := c[1].ImageURL.URL; got != "https://example.com/a.jpg#transform=inline" {
+			t.Errorf("image URL = %q", got)
+		}
+		if got := c[2].ImageURL.URL; got != "data:application/pdf;base64,JVBERi0xLjQ=#transform=inline" {
+			t.Errorf("pdf URL = %q", got)
+		}
+	})
+	t.Run("sampler", func(t *testing.T) {
+		var req fireworks.ChatRequest
+		opts := &fireworks.GenOption{MinP: 0.05, RepetitionPenalty: 1.1}
+		if err := req.Init(genai.Messages{genai.NewTextMessage("hi")}, "model", opts, &genai.GenOptionText{ReasoningEffort: genai.ReasoningEffortOff}); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(&req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{`"min_p":0.05`, `"repetition_penalty":1.1`, `"reasoning_effort":"none"`} {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("missing %s in %s", want, b)
+			}
+		}
+		if err := (&fireworks.GenOption{MinP: 2}).Validate(); err == nil || err.Error() != "field MinP: must be [0, 1]" {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
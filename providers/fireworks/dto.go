@@ -0,0 +1,655 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Wire types for the Fireworks AI inference API (OpenAI-compatible chat completions).
+//
+// API reference: https://docs.fireworks.ai/api-reference/post-chatcompletions
+
+package fireworks
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal"
+)
+
+// GenOption controls Fireworks-specific generation options.
+type GenOption struct {
+	// DocumentInlining converts the images to text before passing them to the model, so models without vision
+	// support can process them. See https://docs.fireworks.ai/fireworks-models/document-inlining
+	//
+	// PDFs are always inlined since it is the only way Fireworks accepts them.
+	DocumentInlining bool
+	// MinP is the minimum probability of a token, relative to the most likely one, to be sampled. Between 0 and 1.
+	MinP float64
+	// RepetitionPenalty penalizes repeated tokens. 1 disables it.
+	RepetitionPenalty float64
+}
+
+// Validate implements genai.Validatable.
+func (g *GenOption) Validate() error {
+	if g.MinP < 0 || g.MinP > 1 {
+		return errors.New("field MinP: must be [0, 1]")
+	}
+	if g.RepetitionPenalty < 0 {
+		return errors.New("field RepetitionPenalty: must be non-negative")
+	}
+	return nil
+}
+
+// inlineSuffix is appended to an image_url to request document inlining.
+const inlineSuffix = "#transform=inline"
+
+// ChatRequest is documented at https://docs.fireworks.ai/api-reference/post-chatcompletions
+type ChatRequest struct {
+	Model             string         `json:"model"`
+	Messages          []Message      `json:"messages"`
+	MaxTokens         int64          `json:"max_tokens,omitzero"`
+	FrequencyPenalty  float64        `json:"frequency_penalty,omitzero"`
+	PresencePenalty   float64        `json:"presence_penalty,omitzero"`
+	RepetitionPenalty float64        `json:"repetition_penalty,omitzero"`
+	Logprobs          bool           `json:"logprobs,omitzero"`
+	TopLogprobs       int64          `json:"top_logprobs,omitzero"`
+	ResponseFormat    ResponseFormat `json:"response_format,omitzero"`
+	ReasoningEffort   string         `json:"reasoning_effort,omitzero"` // "none", "low", "medium", "high"
+	Seed              int64          `json:"seed,omitzero"`
+	Stop              []string       `json:"stop,omitzero"`
+	Stream            bool           `json:"stream,omitzero"`
+	StreamOptions     struct {
+		IncludeUsage bool `json:"include_usage,omitzero"`
+	} `json:"stream_options,omitzero"`
+	Temperature float64 `json:"temperature,omitzero"`
+	TopP        float64 `json:"top_p,omitzero"`
+	TopK        int64   `json:"top_k,omitzero"`
+	MinP        float64 `json:"min_p,omitzero"`
+	ToolChoice  string  `json:"tool_choice,omitzero"` // "none", "auto", "required"
+	Tools       []Tool  `json:"tools,omitzero"`
+}
+
+// ResponseFormat constrains the reply.
+type ResponseFormat struct {
+	Type       string `json:"type"` // "json_object", "json_schema", "grammar"
+	JSONSchema struct {
+		Name   string           `json:"name"`
+		Schema genai.JSONSchema `json:"schema"`
+		Strict bool             `json:"strict"`
+	} `json:"json_schema,omitzero"`
+	// Grammar is a GBNF grammar, only used with Type "grammar".
+	Grammar string `json:"grammar,omitzero"`
+}
+
+// Init initializes the provider specific completion request with the generic completion request.
+func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenOption) error {
+	c.Model = model
+	if err := msgs.Validate(); err != nil {
+		return err
+	}
+	var errs []error
+	var unsupported []string
+	sp := ""
+	inline := false
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return err
+		}
+		switch v := opt.(type) {
+		case *GenOption:
+			inline = v.DocumentInlining
+			c.MinP = v.MinP
+			c.RepetitionPenalty = v.RepetitionPenalty
+		case *genai.GenOptionText:
+			c.MaxTokens = v.MaxTokens
+			c.Temperature = v.Temperature
+			c.TopP = v.TopP
+			c.TopK = v.TopK
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			switch v.ReasoningEffort {
+			case "":
+			case genai.ReasoningEffortOff:
+				c.ReasoningEffort = "none"
+			default:
+				c.ReasoningEffort = string(v.ReasoningEffort)
+			}
+			sp = v.SystemPrompt
+			if v.TopLogprobs > 0 {
+				c.TopLogprobs = v.TopLogprobs
+				c.Logprobs = true
+			}
+			c.Stop = v.Stop
+			switch {
+			case v.DecodeAs != nil:
+				c.ResponseFormat.Type = "json_schema"
+				s, err := v.DecodeSchema()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					c.ResponseFormat.JSONSchema.Schema = s
+				}
+				c.ResponseFormat.JSONSchema.Strict = true
+			case v.ReplyAsJSON:
+				c.ResponseFormat.Type = "json_object"
+			case v.Grammar != "":
+				c.ResponseFormat.Type = "grammar"
+				c.ResponseFormat.Grammar = v.Grammar
+			}
+		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
+				switch v.Force {
+				case genai.ToolCallAny:
+					c.ToolChoice = "auto"
+				case genai.ToolCallRequired:
+					c.ToolChoice = "required"
+				case genai.ToolCallNone:
+					c.ToolChoice = "none"
+				}
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
+					c.Tools[i].Type = "function"
+					c.Tools[i].Function.Name = t.Name
+					c.Tools[i].Function.Description = t.Description
+					s, err := t.GetInputSchema()
+					if err != nil {
+						errs = append(errs, err)
+					}
+					c.Tools[i].Function.Parameters = s
+				}
+			}
+		case genai.GenOptionSeed:
+			c.Seed = int64(v)
+		default:
+			unsupported = append(unsupported, internal.TypeName(opt))
+		}
+	}
+
+	if sp != "" {
+		c.Messages = append(c.Messages, Message{Role: "system", Content: Contents{{Type: ContentText, Text: sp}}})
+	}
+	for i := range msgs {
+		if len(msgs[i].ToolCallResults) > 1 {
+			for j := range msgs[i].ToolCallResults {
+				msgCopy := msgs[i]
+				msgCopy.ToolCallResults = []genai.ToolCallResult{msgs[i].ToolCallResults[j]}
+				var newMsg Message
+				if err := newMsg.From(&msgCopy); err != nil {
+					errs = append(errs, fmt.Errorf("message #%d: tool call results #%d: %w", i, j, err))
+				} else {
+					c.Messages = append(c.Messages, newMsg)
+				}
+			}
+			continue
+		}
+		var newMsg Message
+		if err := newMsg.From(&msgs[i]); err != nil {
+			errs = append(errs, fmt.Errorf("message #%d: %w", i, err))
+		} else {
+			c.Messages = append(c.Messages, newMsg)
+		}
+	}
+	if inline {
+		for i := range c.Messages {
+			for j := range c.Messages[i].Content {
+				if u := &c.Messages[i].Content[j].ImageURL.URL; *u != "" && !strings.HasSuffix(*u, inlineSuffix) {
+					*u += inlineSuffix
+				}
+			}
+		}
+	}
+	if len(unsupported) > 0 && len(errs) == 0 {
+		return &base.ErrNotSupported{Options: unsupported}
+	}
+	return errors.Join(errs...)
+}
+
+// SetStream sets the streaming mode.
+func (c *ChatRequest) SetStream(stream bool) {
+	c.Stream = stream
+	c.StreamOptions.IncludeUsage = stream
+}
+
+// Message is a provider-specific message.
+type Message struct {
+	Role             string     `json:"role,omitzero"` // "system", "assistant", "user", "tool"
+	Content          Contents   `json:"content,omitzero"`
+	Reasoning        string     `json:"reasoning,omitzero"`
+	ReasoningContent string     `json:"reasoning_content,omitzero"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitzero"`
+	ToolCallID       string     `json:"tool_call_id,omitzero"`
+	Name             string     `json:"name,omitzero"`
+}
+
+// From must be called with at most one ToolCallResults.
+func (m *Message) From(in *genai.Message) error {
+	if len(in.ToolCallResults) > 1 {
+		return errors.New("internal error")
+	}
+	switch r := in.Role(); r {
+	case "user", "assistant":
+		m.Role = r
+	case "computer":
+		m.Role = "tool"
+	default:
+		return fmt.Errorf("unsupported role %q", r)
+	}
+	for i := range in.Requests {
+		m.Content = append(m.Content, Content{})
+		if err := m.Content[len(m.Content)-1].FromRequest(&in.Requests[i]); err != nil {
+			return fmt.Errorf("request #%d: %w", i, err)
+		}
+	}
+	for i := range in.Replies {
+		if !in.Replies[i].ToolCall.IsZero() {
+			m.ToolCalls = append(m.ToolCalls, ToolCall{})
+			if err := m.ToolCalls[len(m.ToolCalls)-1].From(&in.Replies[i].ToolCall); err != nil {
+				return fmt.Errorf("reply #%d: %w", i, err)
+			}
+			continue
+		}
+		if in.Replies[i].Reasoning != "" {
+			continue
+		}
+		m.Content = append(m.Content, Content{})
+		if err := m.Content[len(m.Content)-1].FromReply(&in.Replies[i]); err != nil {
+			return fmt.Errorf("reply #%d: %w", i, err)
+		}
+	}
+	if len(in.ToolCallResults) != 0 {
+		m.Content = Contents{{Type: ContentText, Text: in.ToolCallResults[0].Result}}
+		m.ToolCallID = in.ToolCallResults[0].ID
+		m.Name = in.ToolCallResults[0].Name
+	}
+	return nil
+}
+
+// To converts to the genai equivalent.
+func (m *Message) To(out *genai.Message) error {
+	out.Replies = make([]genai.Reply, 0, len(m.Content)+len(m.ToolCalls)+1)
+	if r := cmp.Or(m.ReasoningContent, m.Reasoning); r != "" {
+		out.Replies = append(out.Replies, genai.Reply{Reasoning: r})
+	}
+	for _, content := range m.Content {
+		switch content.Type {
+		case ContentText:
+			if content.Text != "" {
+				out.Replies = append(out.Replies, genai.Reply{Text: content.Text})
+			}
+		default:
+			return &internal.BadError{Err: fmt.Errorf("implement content type %q", content.Type)}
+		}
+	}
+	for i := range m.ToolCalls {
+		out.Replies = append(out.Replies, genai.Reply{})
+		m.ToolCalls[i].To(&out.Replies[len(out.Replies)-1].ToolCall)
+	}
+	if len(out.Replies) == 0 {
+		return errors.New("model sent no reply")
+	}
+	return nil
+}
+
+// Content is a provider-specific content block.
+type Content struct {
+	Type ContentType `json:"type,omitzero"`
+	Text string      `json:"text,omitzero"`
+
+	// Type == "image_url"
+	ImageURL struct {
+		Detail string `json:"detail,omitzero"` // "auto", "low", "high"
+		URL    string `json:"url,omitzero"`    // URL or base64 encoded image, with an optional "#transform=inline" suffix
+	} `json:"image_url,omitzero"`
+}
+
+// FromRequest converts from a genai request.
+func (c *Content) FromRequest(in *genai.Request) error {
+	if in.Text != "" {
+		c.Type = ContentText
+		c.Text = in.Text
+		return nil
+	}
+	if !in.Doc.IsZero() {
+		return c.fromDoc(&in.Doc)
+	}
+	return errors.New("unknown Request type")
+}
+
+// FromReply converts from a genai reply.
+func (c *Content) FromReply(in *genai.Reply) error {
+	if len(in.Opaque) != 0 {
+		return &internal.BadError{Err: errors.New("field Reply.Opaque not supported")}
+	}
+	switch {
+	case in.Text != "":
+		c.Type = ContentText
+		c.Text = in.Text
+	case !in.Doc.IsZero():
+		return c.fromDoc(&in.Doc)
+	default:
+		return &internal.BadError{Err: errors.New("internal error: unknown Reply type")}
+	}
+	return nil
+}
+
+func (c *Content) fromDoc(in *genai.Doc) error {
+	mimeType, data, err := in.Read(10 * 1024 * 1024)
+	if err != nil {
+		return fmt.Errorf("failed to read document: %w", err)
+	}
+	switch {
+	case (in.URL != "" && mimeType == "") || strings.HasPrefix(mimeType, "image/") || mimeType == "application/pdf":
+		c.Type = ContentImageURL
+		c.ImageURL.Detail = string(in.Detail)
+		if in.URL == "" {
+			c.ImageURL.URL = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
+		} else {
+			c.ImageURL.URL = in.URL
+		}
+		if mimeType == "application/pdf" {
+			c.ImageURL.URL += inlineSuffix
+		}
+	case strings.HasPrefix(mimeType, "text/"):
+		c.Type = ContentText
+		if in.URL != "" {
+			return fmt.Errorf("%s documents must be provided inline, not as a URL", mimeType)
+		}
+		c.Text = string(data)
+	default:
+		return fmt.Errorf("unsupported mime type %s", mimeType)
+	}
+	return nil
+}
+
+// ContentType is a provider-specific content type.
+type ContentType string
+
+// Content type values.
+const (
+	ContentText     ContentType = "text"
+	ContentImageURL ContentType = "image_url"
+)
+
+// Contents represents a slice of Content with custom unmarshalling to handle
+// both string and Content struct types.
+type Contents []Content
+
+// MarshalJSON implements json.Marshaler.
+func (c *Contents) MarshalJSON() ([]byte, error) {
+	if len(*c) == 1 && (*c)[0].Type == ContentText {
+		return json.Marshal((*c)[0].Text)
+	}
+	return json.Marshal([]Content(*c))
+}
+
+// UnmarshalJSON implements custom unmarshalling for Contents type.
+func (c *Contents) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*c = nil
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*c = Contents{{Type: ContentText, Text: s}}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]Content)(c))
+}
+
+// Tool is a provider-specific tool definition.
+type Tool struct {
+	Type     string `json:"type"` // "function"
+	Function struct {
+		Name        string           `json:"name"`
+		Description string           `json:"description"`
+		Parameters  genai.JSONSchema `json:"parameters"`
+	} `json:"function"`
+}
+
+// ToolCall is a provider-specific tool call.
+type ToolCall struct {
+	Type     string `json:"type,omitzero"` // "function"
+	ID       string `json:"id,omitzero"`
+	Index    int64  `json:"index,omitzero"`
+	Function struct {
+		Name      string `json:"name,omitzero"`
+		Arguments string `json:"arguments,omitzero"`
+	} `json:"function,omitzero"`
+}
+
+// From converts from the genai equivalent.
+func (t *ToolCall) From(in *genai.ToolCall) error {
+	if len(in.Opaque) != 0 {
+		return errors.New("field ToolCall.Opaque not supported")
+	}
+	t.Type = "function"
+	t.ID = in.ID
+	t.Function.Name = in.Name
+	t.Function.Arguments = in.Arguments
+	return nil
+}
+
+// To converts to the genai equivalent.
+func (t *ToolCall) To(out *genai.ToolCall) {
+	out.ID = t.ID
+	out.Name = t.Function.Name
+	out.Arguments = t.Function.Arguments
+}
+
+// ChatResponse is the provider-specific chat completion response.
+type ChatResponse struct {
+	ID      string     `json:"id"`
+	Model   string     `json:"model"`
+	Object  string     `json:"object"` // "chat.completion"
+	Created base.TimeS `json:"created"`
+	Choices []struct {
+		Index        int64        `json:"index"`
+		FinishReason FinishReason `json:"finish_reason"`
+		Message      Message      `json:"message"`
+		Logprobs     Logprobs     `json:"logprobs"`
+	} `json:"choices"`
+	Usage Usage `json:"usage"`
+}
+
+// ToResult converts the response to a genai.Result.
+func (c *ChatResponse) ToResult() (genai.Result, error) {
+	out := genai.Result{
+		Usage: genai.Usage{
+			InputTokens:       c.Usage.PromptTokens,
+			InputCachedTokens: c.Usage.PromptTokensDetails.CachedTokens,
+			OutputTokens:      c.Usage.CompletionTokens,
+			TotalTokens:       c.Usage.TotalTokens,
+		},
+	}
+	if len(c.Choices) != 1 {
+		return out, fmt.Errorf("expected 1 choice, got %d", len(c.Choices))
+	}
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
+	if out.Usage.FinishReason == genai.FinishedStop && slices.ContainsFunc(out.Replies, func(r genai.Reply) bool { return !r.ToolCall.IsZero() }) {
+		out.Usage.FinishReason = genai.FinishedToolCalls
+	}
+	out.Logprobs = c.Choices[0].Logprobs.To()
+	return out, err
+}
+
+// FinishReason is a provider-specific finish reason.
+type FinishReason string
+
+// Finish reason values.
+const (
+	FinishStop          FinishReason = "stop"
+	FinishToolCalls     FinishReason = "tool_calls"
+	FinishLength        FinishReason = "length"
+	FinishContentFilter FinishReason = "content_filter"
+)
+
+// ToFinishReason converts to a genai.FinishReason.
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
+	switch f {
+	case "":
+		return "", nil
+	case FinishStop:
+		return genai.FinishedStop, nil
+	case FinishToolCalls:
+		return genai.FinishedToolCalls, nil
+	case FinishLength:
+		return genai.FinishedLength, nil
+	case FinishContentFilter:
+		return genai.FinishedContentFilter, nil
+	default:
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
+	}
+}
+
+// ChatStreamChunkResponse is the provider-specific streaming chat chunk.
+type ChatStreamChunkResponse struct {
+	ID      string     `json:"id"`
+	Model   string     `json:"model"`
+	Object  string     `json:"object"` // "chat.completion.chunk"
+	Created base.TimeS `json:"created"`
+	Choices []struct {
+		Delta struct {
+			Role             string     `json:"role"`
+			Content          string     `json:"content"`
+			Reasoning        string     `json:"reasoning"`
+			ReasoningContent string     `json:"reasoning_content"`
+			ToolCalls        []ToolCall `json:"tool_calls"`
+		} `json:"delta"`
+		Index        int64        `json:"index"`
+		FinishReason FinishReason `json:"finish_reason"`
+		Logprobs     Logprobs     `json:"logprobs"`
+	} `json:"choices"`
+	Usage Usage `json:"usage"`
+}
+
+// Logprobs is the provider-specific log probabilities.
+type Logprobs struct {
+	Content []struct {
+		Token       string  `json:"token"`
+		Bytes       []byte  `json:"bytes"`
+		Logprob     float64 `json:"logprob"`
+		TopLogprobs []struct {
+			Token   string  `json:"token"`
+			Bytes   []byte  `json:"bytes"`
+			Logprob float64 `json:"logprob"`
+		} `json:"top_logprobs"`
+	} `json:"content"`
+}
+
+// To converts to the genai equivalent.
+func (l *Logprobs) To() [][]genai.Logprob {
+	if len(l.Content) == 0 {
+		return nil
+	}
+	out := make([][]genai.Logprob, 0, len(l.Content))
+	for _, c := range l.Content {
+		lp := make([]genai.Logprob, 1, len(c.TopLogprobs)+1)
+		lp[0] = genai.Logprob{Text: c.Token, Logprob: c.Logprob}
+		for _, tlp := range c.TopLogprobs {
+			lp = append(lp, genai.Logprob{Text: tlp.Token, Logprob: tlp.Logprob})
+		}
+		out = append(out, lp)
+	}
+	return out
+}
+
+// Usage is the provider-specific token usage.
+type Usage struct {
+	PromptTokens        int64 `json:"prompt_tokens"`
+	CompletionTokens    int64 `json:"completion_tokens"`
+	TotalTokens         int64 `json:"total_tokens"`
+	PromptTokensDetails struct {
+		CachedTokens int64 `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+}
+
+// Model is the provider-specific model metadata.
+type Model struct {
+	ID                 string `json:"id"` // e.g. "accounts/fireworks/models/gpt-oss-120b"
+	Object             string `json:"object"`
+	OwnedBy            string `json:"owned_by"`
+	Created            int64  `json:"created"`
+	Kind               string `json:"kind"` // "HF_BASE_MODEL", "HF_PEFT_ADDON", "FLUMINA_BASE_MODEL", ...
+	SupportsChat       bool   `json:"supports_chat"`
+	SupportsImageInput bool   `json:"supports_image_input"`
+	SupportsTools      bool   `json:"supports_tools"`
+	ContextLength      int64  `json:"context_length"`
+}
+
+// GetID implements genai.Model.
+func (m *Model) GetID() string {
+	return m.ID
+}
+
+func (m *Model) String() string {
+	var suffix []string
+	if m.SupportsImageInput {
+		suffix = append(suffix, "vision")
+	}
+	if m.SupportsTools {
+		suffix = append(suffix, "tools")
+	}
+	s := fmt.Sprintf("%s (context: %d)", m.ID, m.ContextLength)
+	if len(suffix) != 0 {
+		s += " " + strings.Join(suffix, ", ")
+	}
+	return s
+}
+
+// Context implements genai.Model.
+func (m *Model) Context() int64 {
+	return m.ContextLength
+}
+
+// ErrorResponse is the provider-specific error response.
+type ErrorResponse struct {
+	ErrorVal struct {
+		Message string      `json:"message"`
+		Type    string      `json:"type"`
+		Param   string      `json:"param"`
+		Code    json.Number `json:"code"`
+	} `json:"error"`
+}
+
+func (er *ErrorResponse) Error() string {
+	if er.ErrorVal.Type == "" {
+		return er.ErrorVal.Message
+	}
+	return fmt.Sprintf("%s: %s", er.ErrorVal.Type, er.ErrorVal.Message)
+}
+
+// IsAPIError implements base.ErrorResponseI.
+func (er *ErrorResponse) IsAPIError() bool {
+	return true
+}
+
+// ModelsResponse represents the response from the /v1/models endpoint.
+type ModelsResponse struct {
+	Object string  `json:"object"` // "list"
+	Data   []Model `json:"data"`
+}
+
+// ToModels converts models to genai.Model interfaces.
+func (r *ModelsResponse) ToModels() []genai.Model {
+	models := make([]genai.Model, len(r.Data))
+	for i := range r.Data {
+		models[i] = &r.Data[i]
+	}
+	return models
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// OAuth2 authentication, to use workload identity instead of API keys.
+
+package gemini
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maruel/genai/internal/bearer"
+)
+
+// Token is an OAuth2 access token.
+type Token = bearer.Token
+
+// ProviderOptionTokenSource returns OAuth2 access tokens used as Bearer tokens instead of an API key. It is
+// called before each request; the client caches the returned token until it is about to expire.
+//
+// Use vertexai.FindDefaultCredentials to get one from Application Default Credentials, which includes
+// workload identity on Google Cloud. A golang.org/x/oauth2 TokenSource can be adapted with:
+//
+//	gemini.ProviderOptionTokenSource(func(ctx context.Context) (gemini.Token, error) {
+//		t, err := ts.Token()
+//		if err != nil {
+//			return gemini.Token{}, err
+//		}
+//		return gemini.Token{AccessToken: t.AccessToken, Expiry: t.Expiry}, nil
+//	})
+//
+// See https://ai.google.dev/gemini-api/docs/oauth
+type ProviderOptionTokenSource func(ctx context.Context) (Token, error)
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionTokenSource) Validate() error {
+	if p == nil {
+		return errors.New("ProviderOptionTokenSource cannot be nil")
+	}
+	return nil
+}
@@ -20,10 +20,12 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"math"
 	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
@@ -35,6 +37,7 @@ import (
 	"github.com/maruel/genai/base"
 	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/bb"
+	"github.com/maruel/genai/internal/bearer"
 	"github.com/maruel/genai/scoreboard"
 )
 
@@ -101,10 +104,31 @@ type GenOption struct {
 	//
 	// https://ai.google.dev/gemini-api/docs/file-search
 	FileSearch *FileSearch
+
+	// GoogleMaps enables grounding with Google Maps, to answer questions about places. The places are returned
+	// as CitationWeb sources.
+	//
+	// https://ai.google.dev/gemini-api/docs/maps-grounding
+	GoogleMaps *GoogleMaps
+
+	// LatLng is the user's location, used by GoogleMaps and Google Search grounding.
+	LatLng *LatLng
+
+	// GroundingResolver, when set, replaces the grounding redirect URLs in the returned citations with their
+	// final destination.
+	GroundingResolver *GroundingResolver
 }
 
 // Validate implements genai.Validatable.
 func (o *GenOption) Validate() error {
+	if o.LatLng != nil {
+		if o.LatLng.Latitude < -90 || o.LatLng.Latitude > 90 {
+			return fmt.Errorf("field LatLng.Latitude must be between -90 and 90, got %g", o.LatLng.Latitude)
+		}
+		if o.LatLng.Longitude < -180 || o.LatLng.Longitude > 180 {
+			return fmt.Errorf("field LatLng.Longitude must be between -180 and 180, got %g", o.LatLng.Longitude)
+		}
+	}
 	return nil
 }
 
@@ -123,6 +147,9 @@ type Client struct {
 // If none is found, it will still return a client coupled with an base.ErrAPIKeyRequired error.
 // Get your API key at https://ai.google.dev/gemini-api/docs/getting-started
 //
+// Use ProviderOptionTokenSource instead of an API key to authenticate with OAuth2, e.g. with workload
+// identity on Google Cloud.
+//
 // To use multiple models, create multiple clients.
 // Use one of the model from https://ai.google.dev/gemini-api/docs/models/gemini
 //
@@ -135,11 +162,20 @@ type Client struct {
 //
 // As of May 2025, price on Pro model increases when more than 200k input tokens are used.
 // Cached input tokens are 25% of the price of new tokens.
+//
+// The proxy defaults to the GEMINI_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
+	var ts ProviderOptionTokenSource
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -156,15 +192,33 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case ProviderOptionTokenSource:
+			ts = v
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
 	}
 	const apiKeyURL = "https://aistudio.google.com/apikey"
 	var err error
-	if apiKey == "" {
+	if ts != nil {
+		if apiKey != "" {
+			return nil, errors.New("ProviderOptionAPIKey and ProviderOptionTokenSource are mutually exclusive")
+		}
+	} else if apiKey == "" {
 		if apiKey = os.Getenv("GEMINI_API_KEY"); apiKey == "" {
 			err = &base.ErrAPIKeyRequired{EnvVar: "GEMINI_API_KEY", URL: apiKeyURL}
 		}
@@ -192,27 +246,31 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	}
 	// Google supports HTTP POST gzip compression!
 	var t http.RoundTripper = &roundtrippers.PostCompressed{
-		Transport: base.DefaultTransport,
+		Transport: base.NewTransport(httpOpts, proxyURL, "GEMINI_PROXY"),
 		Encoding:  "gzip",
 	}
 	if wrapper != nil {
 		t = wrapper(t)
 	}
-	// Eventually, use OAuth https://ai.google.dev/gemini-api/docs/oauth#curl
+	t = &roundtrippers.RequestID{Transport: t}
+	if ts != nil {
+		// https://ai.google.dev/gemini-api/docs/oauth#curl
+		t = &bearer.Transport{Source: ts, Transport: t}
+	} else {
+		t = &roundtrippers.Header{Header: http.Header{"x-goog-api-key": {apiKey}}, Transport: t}
+	}
 	c := &Client{
 		impl: base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]{
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			LieToolCalls:    true,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
-				Client: http.Client{
-					Transport: &roundtrippers.Header{
-						Header:    http.Header{"x-goog-api-key": {apiKey}},
-						Transport: &roundtrippers.RequestID{Transport: t},
-					},
-				},
+				Lenient:   lenient,
+				Log:       logger,
+				Client:    http.Client{Transport: t},
 			},
 		},
 	}
@@ -232,7 +290,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			}
 			switch mod {
 			case genai.ModalityText:
-				if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+				if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 					return nil, err
 				}
 				c.impl.GenSyncURL = "https://generativelanguage.googleapis.com/v1beta/models/" + url.PathEscape(c.impl.Model) + ":generateContent"
@@ -312,13 +370,15 @@ func (c *Client) detectModelModalities(ctx context.Context, model string) (genai
 
 // selectBestTextModel selects the most appropriate model based on the preference (cheap, good, or SOTA).
 //
-// We may want to make this function overridable in the future by the client since this is going to break one
-// day or another.
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
 func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
 	mdls, err := c.ListModels(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 
 	// Build a map of available models for quick lookup.
 	availableModels := map[string]struct{}{}
@@ -573,6 +633,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -593,6 +658,18 @@ func (c *Client) HTTPClient() *http.Client {
 
 // GenSync implements genai.Provider.
 func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	start := time.Now()
+	sink, opts := base.ExtractOutputOption(opts)
+	res, err := c.genSync(ctx, msgs, opts...)
+	if err == nil {
+		err = sink.Redirect(&res)
+	}
+	c.impl.LogResult(ctx, "GenSync", start, &res, err)
+	return res, err
+}
+
+func (c *Client) genSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	ctx, opts = base.ExtractRawOptions(ctx, opts)
 	if !slices.Contains(c.impl.OutputModalities, genai.ModalityText) {
 		if len(msgs) != 1 {
 			return genai.Result{}, errors.New("must pass exactly one Message")
@@ -610,9 +687,13 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 		return res, err
 	}
 	res, err := out.ToResult()
-	if err != nil {
+	if err = c.impl.StrictErr(err); err != nil {
 		return res, err
 	}
+	if g := groundingResolver(opts); g != nil {
+		// Resolution is best effort.
+		_ = g.ResolveReplies(ctx, res.Replies)
+	}
 	if err := res.Validate(); err != nil {
 		// Catch provider implementation bugs.
 		return res, err
@@ -651,19 +732,26 @@ func (c *Client) GenSyncRaw(ctx context.Context, in *ChatRequest, out *ChatRespo
 
 // GenStream implements genai.Provider.
 func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	ctx, opts = base.ExtractRawOptions(ctx, opts)
+	if err := base.CheckStreamCandidates(opts); err != nil {
+		return func(yield func(genai.Reply) bool) {}, func() (genai.Result, error) { return genai.Result{}, err }
+	}
 	if !slices.Contains(c.impl.OutputModalities, genai.ModalityText) {
 		return base.SimulateStream(ctx, c, msgs, opts...)
 	}
+	sink, opts := base.ExtractOutputOption(opts)
 	// GenStream must be inlined because we need to call our GenStreamRaw.
 	res := genai.Result{}
 	var finalErr error
 
 	fnFragments := func(yield func(genai.Reply) bool) {
+		start := time.Now()
 		in := &ChatRequest{}
 		if err := in.Init(msgs, c.impl.Model, opts...); err != nil {
 			finalErr = &internal.BadError{Err: err}
 			return
 		}
+		g := groundingResolver(opts)
 		// Generate parsed chunks from the raw JSON SSE stream.
 		chunks, finish1 := c.GenStreamRaw(ctx, in)
 		// Converts raw chunks into fragments.
@@ -672,13 +760,19 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 			if f.IsZero() {
 				continue
 			}
+			if g != nil && !f.Citation.IsZero() {
+				// Resolution is best effort.
+				r := []genai.Reply{f}
+				_ = g.ResolveReplies(ctx, r)
+				f = r[0]
+			}
 			if err := f.Validate(); err != nil {
 				// Catch provider implementation bugs.
 				finalErr = &internal.BadError{Err: err}
 				break
 			}
-			if err := res.Accumulate(&f); err != nil {
-				finalErr = &internal.BadError{Err: err}
+			if err := sink.Accumulate(&res, &f); err != nil {
+				finalErr = err
 				break
 			}
 			if !yield(f) {
@@ -690,7 +784,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		}
 		var err error
 		res.Usage, res.Logprobs, err = finish2()
-		if finalErr == nil {
+		if err = c.impl.StrictErr(err); finalErr == nil {
 			finalErr = err
 		}
 		lastResp := c.impl.LastResponseHeaders()
@@ -706,6 +800,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 				}
 			}
 		}
+		c.impl.LogResult(ctx, "GenStream", start, &res, finalErr)
 	}
 	fnFinish := func() (genai.Result, error) {
 		if finalErr != nil {
@@ -863,30 +958,12 @@ func (c *Client) CacheDelete(ctx context.Context, name string) error {
 func (c *Client) genDoc(ctx context.Context, msg *genai.Message, opts ...genai.GenOption) (genai.Result, error) {
 	// TODO: Smartly decide the method to use instead of hardcoding on the modality.
 	if slices.Contains(c.impl.OutputModalities, genai.ModalityVideo) {
-		waitForPoll := time.Second
-		filtered := make([]genai.GenOption, 0, len(opts))
-		for _, opt := range opts {
-			if v, ok := opt.(genai.GenOptionPollInterval); ok {
-				waitForPoll = time.Duration(v)
-			} else {
-				filtered = append(filtered, opt)
-			}
-		}
+		waitForPoll, progress, filtered := base.ExtractPollOptions(opts, time.Second)
 		id, err := c.GenAsync(ctx, genai.Messages{*msg}, filtered...)
 		if err != nil {
 			return genai.Result{}, err
 		}
-		// Loop until the result is available.
-		for {
-			select {
-			case <-ctx.Done():
-				return genai.Result{}, ctx.Err()
-			case <-time.After(waitForPoll):
-				if res, err := c.PokeResult(ctx, id); res.Usage.FinishReason != genai.Pending {
-					return res, err
-				}
-			}
-		}
+		return base.PollJob(ctx, id, waitForPoll, progress, c.pokeResult)
 	}
 	res := genai.Result{}
 	req := ImageRequest{}
@@ -913,7 +990,7 @@ func (c *Client) genDoc(ctx context.Context, msg *genai.Message, opts ...genai.G
 		}
 		n := "content.jpg"
 		if nbImages > 1 {
-			n = fmt.Sprintf("content%d.jpg", i+1)
+			n = fmt.Sprintf("content%d.jpg", len(res.Replies)+1)
 		}
 		res.Replies = append(res.Replies, genai.Reply{Doc: genai.Doc{Filename: n, Src: &bb.BytesBuffer{D: resp.Predictions[i].BytesBase64Encoded}}})
 	}
@@ -931,7 +1008,7 @@ func (c *Client) genDoc(ctx context.Context, msg *genai.Message, opts ...genai.G
 // Model.SupportedGenerationMethods.
 //
 // The resulting file is available for 48 hours. It requires the API key in the HTTP header to be fetched, so
-// use the client's HTTP client.
+// use the client's HTTP client, e.g. with genai.Message.FetchDocs.
 func (c *Client) GenAsync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Job, error) {
 	// GenAsync only works with video generation models (predictLongRunning endpoint).
 	// Text models use generateContent which doesn't support async operations.
@@ -956,21 +1033,37 @@ func (c *Client) GenAsync(ctx context.Context, msgs genai.Messages, opts ...gena
 //
 // It retrieves the result for a job ID.
 func (c *Client) PokeResult(ctx context.Context, id genai.Job) (genai.Result, error) {
+	res, _, err := c.pokeResult(ctx, id)
+	return res, err
+}
+
+// pokeResult is PokeResult that also returns the operation metadata.
+func (c *Client) pokeResult(ctx context.Context, id genai.Job) (genai.Result, map[string]any, error) {
 	res := genai.Result{}
 	op, err := c.PokeResultRaw(ctx, id)
 	if err != nil {
-		return res, err
+		return res, nil, err
+	}
+	var md map[string]any
+	if len(op.Metadata) != 0 {
+		md = make(map[string]any, len(op.Metadata))
+		for k, v := range op.Metadata {
+			var a any
+			if err := json.Unmarshal(v, &a); err == nil {
+				md[k] = a
+			}
+		}
 	}
 	if !op.Done {
 		res.Usage.FinishReason = genai.Pending
-		return res, nil
+		return res, md, nil
 	}
 	res.Usage.FinishReason = genai.FinishedStop
 	for _, p := range op.Response.GenerateVideoResponse.GeneratedSamples {
 		// This requires the Google API key to fetch!
 		res.Replies = []genai.Reply{{Doc: genai.Doc{Filename: "content.mp4", URL: p.Video.URI}}}
 	}
-	return res, nil
+	return res, md, nil
 }
 
 // PredictRaw requests the providers' synchronous API to generate an image.
@@ -1060,6 +1153,10 @@ func (c *Client) CountTokens(ctx context.Context, msgs genai.Messages, opts ...g
 //  1. Initiate a resumable upload session with metadata.
 //  2. Upload the file bytes to the returned upload URL.
 //
+// The file is streamed, so it doesn't need to fit in memory. Reference the uploaded file in a genai.Doc
+// with its URI as URL and a Filename with the right extension, e.g.
+// genai.Doc{Filename: "video.mp4", URL: f.URI}.
+//
 // https://ai.google.dev/api/files#method:-media.upload
 func (c *Client) FileUpload(ctx context.Context, displayName, mimeType string, r io.Reader) (*FileMetadata, error) {
 	// Phase 1: Start the resumable upload.
@@ -1096,31 +1193,79 @@ func (c *Client) FileUpload(ctx context.Context, displayName, mimeType string, r
 	}
 
 	// Phase 2: Upload the file bytes.
-	data, err := io.ReadAll(r)
-	if err != nil {
+	var result struct {
+		File FileMetadata `json:"file"`
+	}
+	if err := c.uploadResumable(ctx, uploadURL, r, &result); err != nil {
 		return nil, err
 	}
-	req, err = http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(data))
+	return &result.File, nil
+}
+
+// uploadChunkSize is the size of each chunk uploaded by uploadResumable when the size of the input is
+// unknown. It must be a multiple of 256KiB.
+const uploadChunkSize = 8 * 1024 * 1024
+
+// uploadResumable uploads the content of r to a resumable upload session and decodes the final response
+// into out.
+//
+// When r is an io.Seeker, it is streamed in a single request. Otherwise it is sent in chunks of
+// uploadChunkSize, so it never needs to fit in memory.
+func (c *Client) uploadResumable(ctx context.Context, uploadURL string, r io.Reader, out any) error {
+	if _, ok := r.(io.Seeker); ok {
+		getBody, size, err := base.ReaderBody(r)
+		if err != nil {
+			return err
+		}
+		return c.uploadChunk(ctx, uploadURL, getBody, 0, size, true, out)
+	}
+	buf := make([]byte, uploadChunkSize)
+	for offset := int64(0); ; {
+		n, err := io.ReadFull(r, buf)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return err
+		}
+		getBody, _, err := base.ReaderBody(bytes.NewReader(buf[:n]))
+		if err != nil {
+			return err
+		}
+		if err = c.uploadChunk(ctx, uploadURL, getBody, offset, int64(n), last, out); err != nil || last {
+			return err
+		}
+		offset += int64(n)
+	}
+}
+
+// uploadChunk uploads the size bytes returned by getBody at offset in a resumable upload session. The
+// response of the finalizing chunk is decoded into out.
+func (c *Client) uploadChunk(ctx context.Context, uploadURL string, getBody func() (io.ReadCloser, error), offset, size int64, finalize bool, out any) error {
+	req, err := base.NewBodyRequest(ctx, http.MethodPut, uploadURL, getBody, size)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	req.Header.Set("Content-Length", strconv.FormatInt(size, 10))
+	req.Header.Set("X-Goog-Upload-Offset", strconv.FormatInt(offset, 10))
+	if finalize {
+		req.Header.Set("X-Goog-Upload-Command", "upload, finalize")
+	} else {
+		req.Header.Set("X-Goog-Upload-Command", "upload")
 	}
-	req.Header.Set("Content-Length", strconv.Itoa(len(data)))
-	req.Header.Set("X-Goog-Upload-Offset", "0")
-	req.Header.Set("X-Goog-Upload-Command", "upload, finalize")
-	resp, err = c.impl.Client.Do(req)
+	resp, err := c.impl.Client.Do(req)
 	if err != nil {
 		if resp != nil {
 			_ = resp.Body.Close()
 		}
-		return nil, err
+		return err
 	}
-	var result struct {
-		File FileMetadata `json:"file"`
+	if finalize {
+		return c.impl.DecodeResponse(resp, uploadURL, out)
 	}
-	if err := c.impl.DecodeResponse(resp, uploadURL, &result); err != nil {
-		return nil, err
+	if resp.StatusCode != http.StatusOK {
+		return c.impl.DecodeError(uploadURL, resp)
 	}
-	return &result.File, nil
+	_ = resp.Body.Close()
+	return nil
 }
 
 // FileGetMetadata retrieves metadata for a single file.
@@ -1273,29 +1418,77 @@ func (c *Client) FileSearchStoreUploadDocument(ctx context.Context, store, displ
 	}
 
 	// Phase 2: Upload the file bytes.
-	data, err := io.ReadAll(r)
-	if err != nil {
+	var result Operation
+	if err := c.uploadResumable(ctx, uploadURL, r, &result); err != nil {
 		return nil, err
 	}
-	req, err = http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(data))
+	return &result, nil
+}
+
+// VectorStoreAdd implements genai.ProviderVectorStore.
+//
+// It creates a file search store and returns its name, in the form "fileSearchStores/{id}".
+func (c *Client) VectorStoreAdd(ctx context.Context, displayName string) (string, error) {
+	s, err := c.FileSearchStoreCreate(ctx, displayName)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	req.Header.Set("Content-Length", strconv.Itoa(len(data)))
-	req.Header.Set("X-Goog-Upload-Offset", "0")
-	req.Header.Set("X-Goog-Upload-Command", "upload, finalize")
-	resp, err = c.impl.Client.Do(req)
+	return s.Name, nil
+}
+
+// VectorStoreAddDoc implements genai.ProviderVectorStore.
+//
+// It uploads the document with FileSearchStoreUploadDocument and polls the operation until the document is
+// indexed.
+func (c *Client) VectorStoreAddDoc(ctx context.Context, id string, doc *genai.Doc) error {
+	if doc.Src == nil {
+		return errors.New("only documents with Src are supported")
+	}
+	name := doc.GetFilename()
+	mimeType := internal.MimeByExt(filepath.Ext(name))
+	if mimeType == "" {
+		return errors.New("failed to determine mime-type, pass a filename with an extension")
+	}
+	op, err := c.FileSearchStoreUploadDocument(ctx, id, name, mimeType, doc.Src)
 	if err != nil {
-		if resp != nil {
-			_ = resp.Body.Close()
+		return err
+	}
+	for !op.Done {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
 		}
-		return nil, err
+		o, err := c.PokeResultRaw(ctx, genai.Job(op.Name))
+		if err != nil {
+			return err
+		}
+		op = &o
 	}
-	var result Operation
-	if err := c.impl.DecodeResponse(resp, uploadURL, &result); err != nil {
+	if op.Error.Code != 0 {
+		return fmt.Errorf("failed to index %q: %s", name, op.Error.Message)
+	}
+	return nil
+}
+
+// VectorStoreEntries implements genai.ProviderVectorStore.
+func (c *Client) VectorStoreEntries(ctx context.Context) ([]genai.VectorStoreEntry, error) {
+	l, err := c.FileSearchStoreList(ctx)
+	if err != nil {
 		return nil, err
 	}
-	return &result, nil
+	out := make([]genai.VectorStoreEntry, len(l))
+	for i := range l {
+		out[i] = &l[i]
+	}
+	return out, nil
+}
+
+// VectorStoreDelete implements genai.ProviderVectorStore.
+//
+// It deletes the file search store along with its documents.
+func (c *Client) VectorStoreDelete(ctx context.Context, id string) error {
+	return c.FileSearchStoreDelete(ctx, id, true)
 }
 
 // FileSearchStoreImportFile imports an already-uploaded file into a file search store.
@@ -1390,7 +1583,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.TotalTokens = pkt.UsageMetadata.TotalTokenCount
 				}
 				if pkt.Candidates[0].FinishReason != "" {
-					u.FinishReason = pkt.Candidates[0].FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Candidates[0].FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				switch role := pkt.Candidates[0].Content.Role; role {
 				case "model", "":
@@ -1410,6 +1606,7 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					// Handle citations as a separate packet.
 					for i := range replies {
 						f.Citation = replies[i].Citation
+						f.ServerToolCall = replies[i].ServerToolCall
 						if !yield(f) {
 							return
 						}
@@ -1478,6 +1675,9 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					}
 					if part.ExecutableCode.Language != "" || part.ExecutableCode.Code != "" {
 						// https://ai.google.dev/api/caching?hl=en#ExecutableCode
+						if !yield(genai.Reply{ServerToolCall: part.ExecutableCode.serverToolCall()}) {
+							return
+						}
 						f.Text = part.ExecutableCode.Code
 						f.Opaque = map[string]any{"type": "executable_code", "language": part.ExecutableCode.Language}
 						if !yield(f) {
@@ -1487,6 +1687,9 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					}
 					if part.CodeExecutionResult.Outcome != "" || part.CodeExecutionResult.Output != "" {
 						// https://ai.google.dev/api/caching?hl=en#CodeExecutionResult
+						if !yield(genai.Reply{ServerToolCall: part.CodeExecutionResult.serverToolCall()}) {
+							return
+						}
 						f.Text = part.CodeExecutionResult.Output
 						f.Opaque = map[string]any{"type": "code_execution_result", "outcome": part.CodeExecutionResult.Outcome}
 						if !yield(f) {
@@ -1517,4 +1720,30 @@ func (c *Client) Capabilities() genai.ProviderCapabilities {
 	}
 }
 
-var _ genai.Provider = &Client{}
+// ImageTokens implements genai.ProviderImageTokens.
+//
+// For Gemini 1.x and 2.x models, images up to 384x384 use 258 tokens. Larger images are cropped in square
+// tiles of 256 to 768 pixels, each using 258 tokens. Newer models use a fixed budget of 1120 tokens at the
+// default media resolution.
+//
+// See https://ai.google.dev/gemini-api/docs/tokens#multimodal-tokens
+func (c *Client) ImageTokens(width, height int) int64 {
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+	if !strings.HasPrefix(c.impl.Model, "gemini-1") && !strings.HasPrefix(c.impl.Model, "gemini-2") {
+		return 1120
+	}
+	if width <= 384 && height <= 384 {
+		return 258
+	}
+	unit := min(max(math.Floor(float64(min(width, height))/1.5), 256), 768)
+	return 258 * int64(math.Ceil(float64(width)/unit)*math.Ceil(float64(height)/unit))
+}
+
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+	_ genai.ProviderImageTokens    = &Client{}
+	_ genai.ProviderVectorStore    = &Client{}
+)
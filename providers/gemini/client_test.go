@@ -10,6 +10,8 @@ import (
 	"context"
 	_ "embed"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"slices"
@@ -22,7 +24,7 @@ import (
 	"gopkg.in/dnaeon/go-vcr.v4/pkg/recorder"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/base"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/gemini"
 	"github.com/maruel/genai/scoreboard"
@@ -46,6 +48,7 @@ func getClientInner(t *testing.T, model string, modalities genai.Modalities, pre
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return gemini.New(t.Context(), opts...)
 }
 
@@ -122,6 +125,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := gemini.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -595,7 +599,7 @@ func TestClient(t *testing.T) {
 						EndIndex: 10,
 						Sources: []genai.CitationSource{{
 							Type: genai.CitationDocument, ID: "fileSearchStores/123/documents/456",
-							Title: "Test Doc", URL: "gs://bucket/doc.pdf", Snippet: "relevant snippet",
+							Title: "Test Doc", URL: "gs://bucket/doc.pdf", Snippet: "relevant snippet", DocumentName: "Test Doc",
 						}},
 					}},
 				},
@@ -618,7 +622,7 @@ func TestClient(t *testing.T) {
 						EndIndex: 15,
 						Sources: []genai.CitationSource{{
 							Type: genai.CitationDocument, ID: "fileSearchStores/store-123",
-							Title: "Store Doc", Snippet: "snippet from store",
+							Title: "Store Doc", Snippet: "snippet from store", DocumentName: "Store Doc",
 						}},
 					}},
 				},
@@ -633,19 +637,62 @@ func TestClient(t *testing.T) {
 						}},
 					},
 					GroundingSupports: []gemini.GroundingSupport{
-						{GroundingChunkIndices: []int64{0, 1}, Segment: gemini.Segment{StartIndex: 5, EndIndex: 20}},
+						{
+							GroundingChunkIndices: []int64{0, 1},
+							ConfidenceScores:      []float64{0.5, 0.9},
+							Segment:               gemini.Segment{StartIndex: 5, EndIndex: 20, Text: "grounded text"},
+						},
 					},
 				},
 				want: []genai.Reply{
 					{Citation: genai.Citation{
+						CitedText:  "grounded text",
 						StartIndex: 5, EndIndex: 20,
 						Sources: []genai.CitationSource{
-							{Type: genai.CitationWeb, URL: "https://example.com", Title: "Web Result"},
-							{Type: genai.CitationDocument, ID: "fileSearchStores/abc/documents/def", Title: "Doc Result", Snippet: "doc snippet"},
+							{Type: genai.CitationWeb, URL: "https://example.com", Title: "Web Result", Confidence: 0.5},
+							{
+								Type: genai.CitationDocument, ID: "fileSearchStores/abc/documents/def", Title: "Doc Result",
+								Snippet: "doc snippet", DocumentName: "Doc Result", Confidence: 0.9,
+							},
 						},
 					}},
 				},
 			},
+			{
+				name: "maps",
+				in: gemini.GroundingMetadata{
+					GroundingChunks: []gemini.GroundingChunk{
+						{Maps: gemini.GroundingChunkMaps{
+							URI: "https://maps.google.com/?cid=1", Title: "Cafe", Text: "A cozy cafe", PlaceID: "places/abc",
+							PlaceAnswerSources: gemini.PlaceAnswerSources{ReviewSnippets: []gemini.ReviewSnippet{
+								{ReviewID: "r1", GoogleMapsURI: "https://maps.google.com/?review=1", Title: "Great coffee"},
+							}},
+						}},
+					},
+					GroundingSupports: []gemini.GroundingSupport{
+						{GroundingChunkIndices: []int64{0}, Segment: gemini.Segment{EndIndex: 12}},
+					},
+					GoogleMapsWidgetContextToken: "widgetcontent/abc",
+				},
+				want: []genai.Reply{
+					{Citation: genai.Citation{
+						EndIndex: 12,
+						Sources: []genai.CitationSource{
+							{Type: genai.CitationWeb, ID: "places/abc", Title: "Cafe", URL: "https://maps.google.com/?cid=1", Snippet: "A cozy cafe"},
+							{Type: genai.CitationWeb, ID: "r1", Title: "Great coffee", URL: "https://maps.google.com/?review=1"},
+						},
+					}},
+					{Opaque: map[string]any{"googleMapsWidgetContextToken": "widgetcontent/abc"}},
+				},
+			},
+			{
+				name: "webSearchQueries",
+				in:   gemini.GroundingMetadata{WebSearchQueries: []string{"genai"}},
+				want: []genai.Reply{
+					{ServerToolCall: genai.ServerToolCall{Name: genai.ServerToolWebSearch, Status: genai.ServerToolCallCompleted, Input: `{"queries":["genai"]}`}},
+					{Citation: genai.Citation{Sources: []genai.CitationSource{{Type: genai.CitationWebQuery, Snippet: "genai"}}}},
+				},
+			},
 		}
 		for _, tc := range data {
 			t.Run(tc.name, func(t *testing.T) {
@@ -725,11 +772,6 @@ func hasModalities(opts []genai.ProviderOption) bool {
 	})
 }
 
-func init() {
-	// Set slog default to debug level for verbose logging during test development.
-	internal.BeLenient = false
-}
-
 const prompt4K = `You are a sarcastic assistant. Here's a long prompt to make sure we are over 4k tokens that you should ignore.
 Okay, this is a significant amount of text. To make it coherent and somewhat engaging, I'll choose a broad theme and explore various facets of it. I'll aim for something related to the evolution of knowledge, technology, and human understanding.
 
@@ -787,3 +829,162 @@ The very language we use to describe the world is constantly adapting. New terms
 
 Ultimately, the human endeavor is a quest for understanding, not just of the external world, but of ourselves. It is a journey marked by triumphs and failures, by moments of profound insight and periods of confusion and doubt. It is a story that is still being written, by each of us, every day. The responsibility to write that story well, to learn from the past, to engage thoughtfully with the present, and to build a better future, rests on our collective shoulders. This requires courage – the courage to question, the courage to change, and the courage to hope. It requires humility – the humility to recognize the limits of our knowledge and the potential for error. And it requires a deep-seated curiosity – the insatiable desire to explore, to discover, and to understand that has driven human progress since the dawn of our species. The path ahead is uncertain, filled with both challenges and opportunities, but it is a path that we must walk together, guided by the light of reason, compassion, and an unwavering commitment to the pursuit of a more enlightened and humane world. The legacy we leave will be defined by how well we navigate this complex, ever-changing landscape, and by the wisdom we cultivate and pass on to future generations.
 `
+
+func TestImageTokens(t *testing.T) {
+	data := []struct {
+		model         string
+		width, height int
+		want          int64
+	}{
+		{"gemini-2.5-flash", 300, 300, 258},
+		{"gemini-2.5-flash", 1000, 1000, 1032},
+		{"gemini-2.5-flash", 3000, 1000, 2580},
+		{"gemini-3-flash", 1000, 1000, 1120},
+	}
+	for _, tc := range data {
+		t.Run(fmt.Sprintf("%s/%dx%d", tc.model, tc.width, tc.height), func(t *testing.T) {
+			c, err := gemini.New(t.Context(), genai.ProviderOptionAPIKey("<insert_api_key_here>"), genai.ProviderOptionModel(tc.model))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.ImageTokens(tc.width, tc.height); got != tc.want {
+				t.Fatalf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTokenSource(t *testing.T) {
+	const reply = `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":2,"candidatesTokenCount":1,"totalTokenCount":3}}`
+	calls := 0
+	ts := gemini.ProviderOptionTokenSource(func(ctx context.Context) (gemini.Token, error) {
+		calls++
+		return gemini.Token{AccessToken: "tok", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+	fake := internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("unexpected Authorization %q", got)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "" {
+			t.Errorf("unexpected x-goog-api-key %q", got)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(reply)),
+			Request:    r,
+		}, nil
+	})
+	t.Setenv("GEMINI_API_KEY", "")
+	c, err := gemini.New(t.Context(),
+		ts,
+		genai.ProviderOptionModel("gemini-2.5-flash"),
+		genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Hello")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the token to be cached, got %d calls", calls)
+	}
+	if _, err := gemini.New(t.Context(), ts, genai.ProviderOptionAPIKey("key")); err == nil {
+		t.Error("expected error with both ProviderOptionAPIKey and ProviderOptionTokenSource")
+	}
+}
+
+func TestGenStreamCandidates(t *testing.T) {
+	// Streamed fragments carry a single candidate so the request must not be sent.
+	c, err := getClientInner(t, "gemini-2.5-flash", nil, nil, func(http.RoundTripper) http.RoundTripper {
+		return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			t.Errorf("unexpected request %s", r.URL)
+			return nil, errors.New("unexpected request")
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, finish := c.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("Hi")}, &genai.GenOptionText{N: 2})
+	var e *base.ErrNotSupported
+	if _, err := finish(); !errors.As(err, &e) {
+		t.Fatalf("want ErrNotSupported, got %v", err)
+	}
+}
+
+func TestProviderVectorStore(t *testing.T) {
+	var got []string
+	c, err := getClientInner(t, "gemini-2.5-flash", nil, nil, func(http.RoundTripper) http.RoundTripper {
+		return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			got = append(got, r.Method+" "+r.URL.RequestURI())
+			h := http.Header{"Content-Type": []string{"application/json"}}
+			var b []byte
+			if r.Body != nil {
+				var err error
+				if b, err = io.ReadAll(r.Body); err != nil {
+					return nil, err
+				}
+			}
+			body := ""
+			switch r.Method + " " + r.URL.RequestURI() {
+			case "POST /v1beta/fileSearchStores":
+				if want := `{"displayName":"docs"}`; strings.TrimSpace(string(b)) != want {
+					t.Errorf("body = %s, want %s", b, want)
+				}
+				body = `{"name":"fileSearchStores/abc","displayName":"docs"}`
+			case "POST /upload/v1beta/fileSearchStores/abc:uploadToFileSearchStore":
+				if want := `{"displayName":"a.txt"}`; string(b) != want {
+					t.Errorf("body = %s, want %s", b, want)
+				}
+				if got := r.Header.Get("X-Goog-Upload-Header-Content-Type"); !strings.HasPrefix(got, "text/plain") {
+					t.Errorf("mime type = %q", got)
+				}
+				h.Set("X-Goog-Upload-Url", "https://generativelanguage.googleapis.com/upload/session/1")
+			case "PUT /upload/session/1":
+				if string(b) != "hello" || r.Header.Get("X-Goog-Upload-Command") != "upload, finalize" {
+					t.Errorf("body = %q, command = %q", b, r.Header.Get("X-Goog-Upload-Command"))
+				}
+				body = `{"name":"fileSearchStores/abc/upload/operations/op1"}`
+			case "GET /v1beta/fileSearchStores/abc/upload/operations/op1":
+				body = `{"name":"fileSearchStores/abc/upload/operations/op1","done":true}`
+			case "GET /v1beta/fileSearchStores?pageSize=20":
+				body = `{"fileSearchStores":[{"name":"fileSearchStores/abc","displayName":"docs","state":"STATE_ACTIVE"}]}`
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			}
+			return &http.Response{StatusCode: 200, Header: h, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs := c.(genai.ProviderVectorStore)
+	ctx := t.Context()
+	id, err := vs.VectorStoreAdd(ctx, "docs")
+	if err != nil || id != "fileSearchStores/abc" {
+		t.Fatalf("%q, %v", id, err)
+	}
+	if err = vs.VectorStoreAddDoc(ctx, id, &genai.Doc{Filename: "a.txt", Src: strings.NewReader("hello")}); err != nil {
+		t.Fatal(err)
+	}
+	if err = vs.VectorStoreAddDoc(ctx, id, &genai.Doc{Filename: "a", Src: strings.NewReader("hello")}); err == nil {
+		t.Error("expected error for a document without extension")
+	}
+	entries, err := vs.VectorStoreEntries(ctx)
+	if err != nil || len(entries) != 1 || entries[0].GetID() != "fileSearchStores/abc" || entries[0].GetDisplayName() != "docs" {
+		t.Fatalf("%#v, %v", entries, err)
+	}
+	want := []string{
+		"POST /v1beta/fileSearchStores",
+		"POST /upload/v1beta/fileSearchStores/abc:uploadToFileSearchStore",
+		"PUT /upload/session/1",
+		"GET /v1beta/fileSearchStores/abc/upload/operations/op1",
+		"GET /v1beta/fileSearchStores?pageSize=20",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("requests\ngot:  %q\nwant: %q", got, want)
+	}
+}
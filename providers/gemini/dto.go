@@ -192,7 +192,14 @@ func (t *jsonSchemaType) UnmarshalJSON(b []byte) error {
 //
 // Accepts standard JSON Schema with lowercase type names ("string", "integer", etc.).
 // Use with genai.GenOptionText.DecodeSchema() or genai.ToolDef.GetInputSchema().
+//
+// The schema is first lowered with base.SchemaDialectGemini: local $ref are inlined and constructs that
+// can't be represented, like oneOf, are reported as a *base.ErrSchemaNotSupported.
 func (s *Schema) FromJSONSchema(js genai.JSONSchema) error {
+	js, err := base.SchemaDialectGemini.Lower(js)
+	if err != nil {
+		return err
+	}
 	var n jsonSchemaNode
 	if err := json.Unmarshal(js, &n); err != nil {
 		return fmt.Errorf("invalid JSON schema: %w", err)
@@ -371,11 +378,19 @@ type Tool struct {
 	GoogleSearch *GoogleSearch `json:"googleSearch,omitzero"`
 	// FileSearch enables file search tool.
 	FileSearch *FileSearch `json:"fileSearch,omitzero"`
+	// GoogleMaps presence signifies that it should be enabled.
+	GoogleMaps *GoogleMaps `json:"googleMaps,omitzero"`
 }
 
 // GoogleSearch is "documented" at https://ai.google.dev/gemini-api/docs/google-search
 type GoogleSearch struct{}
 
+// GoogleMaps is documented at https://ai.google.dev/gemini-api/docs/maps-grounding
+type GoogleMaps struct {
+	// EnableWidget returns GroundingMetadata.GoogleMapsWidgetContextToken to render a Places widget.
+	EnableWidget bool `json:"enableWidget,omitzero"`
+}
+
 // FileSearch is documented at https://ai.google.dev/gemini-api/docs/file-search
 type FileSearch struct {
 	FileSearchStoreNames []string `json:"fileSearchStoreNames,omitzero"`
@@ -388,7 +403,10 @@ type FunctionDeclaration struct {
 	Name        string `json:"name,omitzero"`
 	Description string `json:"description,omitzero"`
 	Parameters  Schema `json:"parameters,omitzero"`
-	Response    Schema `json:"response,omitzero"`
+	// ParametersJSONSchema is a JSON Schema describing the parameters. It is mutually exclusive with
+	// Parameters and accepts constructs that Schema can't represent, like oneOf.
+	ParametersJSONSchema genai.JSONSchema `json:"parametersJsonSchema,omitzero"`
+	Response             Schema           `json:"response,omitzero"`
 }
 
 // ToolMode is documented at https://ai.google.dev/api/caching?hl=en#Mode_1
@@ -408,6 +426,35 @@ const (
 	ToolModeValidated ToolMode = "VALIDATED"
 )
 
+// initWebSearch applies the generic web search options. Only the user's coordinates are supported. They are
+// overridden by GenOption.LatLng.
+func (c *ChatRequest) initWebSearch(o *genai.WebSearchOptions) []string {
+	if o == nil {
+		return nil
+	}
+	var unsupported []string
+	if len(o.AllowedDomains) != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.AllowedDomains")
+	}
+	if len(o.BlockedDomains) != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.BlockedDomains")
+	}
+	if o.MaxSearches != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.MaxSearches")
+	}
+	if o.ContextSize != "" {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.ContextSize")
+	}
+	l := &o.UserLocation
+	if l.HasLatLng() && c.ToolConfig.RetrievalConfig.LatLng == (LatLng{}) {
+		c.ToolConfig.RetrievalConfig.LatLng = LatLng{Latitude: l.Latitude, Longitude: l.Longitude}
+	}
+	if l.City != "" || l.Region != "" || l.Country != "" || l.Timezone != "" {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.UserLocation.City")
+	}
+	return unsupported
+}
+
 // ToolConfig is documented at https://ai.google.dev/api/caching?hl=en#ToolConfig
 type ToolConfig struct {
 	// https://ai.google.dev/api/caching?hl=en#FunctionCallingConfig
@@ -415,6 +462,17 @@ type ToolConfig struct {
 		Mode                 ToolMode `json:"mode,omitzero"`
 		AllowedFunctionNames []string `json:"allowedFunctionNames,omitzero"`
 	} `json:"functionCallingConfig,omitzero"`
+	// https://ai.google.dev/api/caching?hl=en#RetrievalConfig
+	RetrievalConfig struct {
+		LatLng       LatLng `json:"latLng,omitzero"`
+		LanguageCode string `json:"languageCode,omitzero"`
+	} `json:"retrievalConfig,omitzero"`
+}
+
+// LatLng is documented at https://ai.google.dev/api/caching?hl=en#LatLng
+type LatLng struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }
 
 // Modality is documented at https://ai.google.dev/api/generate-content#Modality
@@ -433,10 +491,10 @@ type MediaResolution string
 
 // Media resolution values.
 const (
-	MediaResolutionUnspecified MediaResolution = ""       // "MEDIA_RESOLUTION_UNSPECIFIED"
-	MediaResolutionLow         MediaResolution = "LOW"    // 64 tokens
-	MediaResolutionMedium      MediaResolution = "MEDIUM" // 256 tokens
-	MediaResolutionHigh        MediaResolution = "HIGH"   // zoomed reframing with 256 tokens
+	MediaResolutionUnspecified MediaResolution = ""                        // "MEDIA_RESOLUTION_UNSPECIFIED"
+	MediaResolutionLow         MediaResolution = "MEDIA_RESOLUTION_LOW"    // 64 tokens
+	MediaResolutionMedium      MediaResolution = "MEDIA_RESOLUTION_MEDIUM" // 256 tokens
+	MediaResolutionHigh        MediaResolution = "MEDIA_RESOLUTION_HIGH"   // zoomed reframing with 256 tokens
 )
 
 // Request and response types.
@@ -497,11 +555,14 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 	}
 	var errs []error
 	var unsupported []string
+	var effort genai.ReasoningEffort
+	budgetSet := false
 
 	for _, opt := range opts {
 		switch v := opt.(type) {
 		case *GenOption:
 			// Accept both positive numbers and -1 (dynamic thinking)
+			budgetSet = v.ThinkingBudget != 0
 			if v.ThinkingBudget != 0 {
 				// https://ai.google.dev/gemini-api/docs/thinking
 				c.GenerationConfig.ThinkingConfig = &ThinkingConfig{
@@ -528,14 +589,31 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.FileSearch != nil {
 				c.Tools = append(c.Tools, Tool{FileSearch: v.FileSearch})
 			}
+			if v.GoogleMaps != nil {
+				c.Tools = append(c.Tools, Tool{GoogleMaps: v.GoogleMaps})
+			}
+			if v.LatLng != nil {
+				c.ToolConfig.RetrievalConfig.LatLng = *v.LatLng
+			}
 		case *genai.GenOptionText:
+			effort = v.ReasoningEffort
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
 			errs = append(errs, c.initOptionsText(v)...)
 		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
 			errs = append(errs, c.initOptionsTools(v)...)
+		case *genai.GenOptionFileSearch:
+			// https://ai.google.dev/gemini-api/docs/file-search
+			c.Tools = append(c.Tools, Tool{FileSearch: &FileSearch{FileSearchStoreNames: v.StoreIDs}})
 		case *genai.GenOptionWeb:
 			if v.Search {
 				// https://ai.google.dev/gemini-api/docs/google-search
 				c.Tools = append(c.Tools, Tool{GoogleSearch: &GoogleSearch{}})
+				unsupported = append(unsupported, c.initWebSearch(v.SearchOptions)...)
 			}
 			if v.Fetch {
 				// https://ai.google.dev/gemini-api/docs/url-context
@@ -554,12 +632,18 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 		}
 	}
 
+	// GenOption.ThinkingBudget takes precedence over the generic reasoning effort.
+	if effort != "" && !budgetSet {
+		c.GenerationConfig.ThinkingConfig = &ThinkingConfig{IncludeThoughts: effort != genai.ReasoningEffortOff, ThinkingBudget: thinkingBudgets[effort]}
+	}
+
 	c.Contents = make([]Content, len(msgs))
 	for i := range msgs {
 		if err := c.Contents[i].From(&msgs[i]); err != nil {
 			errs = append(errs, fmt.Errorf("message #%d: %w", i, err))
 		}
 	}
+	c.GenerationConfig.MediaResolution = mediaResolution(msgs)
 	// If we have unsupported features but no other errors, return a structured error.
 	if len(unsupported) > 0 && len(errs) == 0 {
 		return &base.ErrNotSupported{Options: unsupported}
@@ -572,6 +656,7 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) []error {
 	c.GenerationConfig.MaxOutputTokens = v.MaxTokens
 	c.GenerationConfig.Temperature = v.Temperature
 	c.GenerationConfig.TopP = v.TopP
+	c.GenerationConfig.CandidateCount = v.N
 	// For large ones, we could use cached storage.
 	if v.SystemPrompt != "" {
 		c.SystemInstruction.Parts = []Part{{Text: v.SystemPrompt}}
@@ -599,7 +684,8 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) []error {
 
 func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) []error {
 	var errs []error
-	if len(v.Tools) != 0 {
+	tools := v.AllowedTools()
+	if len(tools) != 0 {
 		switch v.Force {
 		case genai.ToolCallAny:
 			c.ToolConfig.FunctionCallingConfig.Mode = ToolModeValidated
@@ -608,22 +694,20 @@ func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) []error {
 		case genai.ToolCallNone:
 			c.ToolConfig.FunctionCallingConfig.Mode = ToolModeNone
 		}
-		c.Tools = make([]Tool, len(v.Tools))
-		for i, t := range v.Tools {
-			params := Schema{}
-			js, err := t.GetInputSchema()
-			if err != nil {
+		c.Tools = make([]Tool, len(tools))
+		for i, t := range tools {
+			c.Tools[i].FunctionDeclarations = []FunctionDeclaration{{Name: t.Name, Description: t.Description}}
+			fd := &c.Tools[i].FunctionDeclarations[0]
+			if len(t.InputSchemaOverride) != 0 {
+				// A user supplied schema is sent as-is, since it may use constructs that Schema can't represent.
+				fd.ParametersJSONSchema = t.InputSchemaOverride
+			} else if js, err := t.GetInputSchema(); err != nil {
 				errs = append(errs, fmt.Errorf("%s: tool parameters schema: %w", t.Name, err))
-			} else if err = params.FromJSONSchema(js); err != nil {
+			} else if err = fd.Parameters.FromJSONSchema(js); err != nil {
 				errs = append(errs, fmt.Errorf("%s: tool parameters: %w", t.Name, err))
 			}
 			// See FunctionResponse.To().
-			c.Tools[i].FunctionDeclarations = []FunctionDeclaration{{
-				Name:        t.Name,
-				Description: t.Description,
-				Parameters:  params,
-			}}
-			if err := c.Tools[i].FunctionDeclarations[0].Response.FromJSONSchema(functionResponseSchema); err != nil {
+			if err := fd.Response.FromJSONSchema(functionResponseSchema); err != nil {
 				errs = append(errs, fmt.Errorf("%s: tool response: %w", t.Name, err))
 			}
 		}
@@ -631,6 +715,24 @@ func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) []error {
 	return errs
 }
 
+// mediaResolution maps the genai.Doc.Detail of the input documents to a media resolution.
+//
+// Gemini only supports it per request, so the highest requested detail wins.
+func mediaResolution(msgs genai.Messages) MediaResolution {
+	out := MediaResolutionUnspecified
+	for i := range msgs {
+		for j := range msgs[i].Requests {
+			switch msgs[i].Requests[j].Doc.Detail {
+			case genai.ImageDetailHigh:
+				return MediaResolutionHigh
+			case genai.ImageDetailLow:
+				out = MediaResolutionLow
+			}
+		}
+	}
+	return out
+}
+
 // Content is the equivalent of Message for other providers.
 // https://ai.google.dev/api/caching?hl=en#Content
 type Content struct {
@@ -649,21 +751,31 @@ func (c *Content) From(in *genai.Message) error {
 	default:
 		return fmt.Errorf("unsupported role %q", r)
 	}
-	c.Parts = make([]Part, len(in.Requests)+len(in.Replies)+len(in.ToolCallResults))
+	c.Parts = make([]Part, len(in.Requests), len(in.Requests)+len(in.Replies)+len(in.ToolCallResults))
 	for i := range in.Requests {
 		if err := c.Parts[i].FromRequest(&in.Requests[i]); err != nil {
 			return fmt.Errorf("request #%d: %w", i, err)
 		}
 	}
-	offset := len(in.Requests)
 	for i := range in.Replies {
-		if err := c.Parts[i].FromReply(&in.Replies[i]); err != nil {
+		if !in.Replies[i].ServerToolCall.IsZero() {
+			// It is informative only.
+			continue
+		}
+		if _, ok := in.Replies[i].Opaque["googleMapsWidgetContextToken"]; ok {
+			// It is only used to render the Google Maps widget.
+			continue
+		}
+		p := Part{}
+		if err := p.FromReply(&in.Replies[i]); err != nil {
 			return fmt.Errorf("reply #%d: %w", i, err)
 		}
+		c.Parts = append(c.Parts, p)
 	}
-	offset += len(in.Replies)
 	for i := range in.ToolCallResults {
-		c.Parts[offset+i].FunctionResponse.From(&in.ToolCallResults[i])
+		p := Part{}
+		p.FunctionResponse.From(&in.ToolCallResults[i])
+		c.Parts = append(c.Parts, p)
 	}
 	return nil
 }
@@ -718,6 +830,9 @@ func (c *Content) To(out *genai.Message) error {
 			continue
 		}
 		if part.ExecutableCode.Language != "" || part.ExecutableCode.Code != "" {
+			if err := out.Accumulate(&genai.Reply{ServerToolCall: part.ExecutableCode.serverToolCall()}); err != nil {
+				return err
+			}
 			out.Replies = append(out.Replies, genai.Reply{
 				Text:   part.ExecutableCode.Code,
 				Opaque: map[string]any{"type": "executable_code", "language": part.ExecutableCode.Language},
@@ -725,6 +840,10 @@ func (c *Content) To(out *genai.Message) error {
 			continue
 		}
 		if part.CodeExecutionResult.Outcome != "" || part.CodeExecutionResult.Output != "" {
+			// This updates the ServerToolCall of the preceding ExecutableCode.
+			if err := out.Accumulate(&genai.Reply{ServerToolCall: part.CodeExecutionResult.serverToolCall()}); err != nil {
+				return err
+			}
 			out.Replies = append(out.Replies, genai.Reply{
 				Text:   part.CodeExecutionResult.Output,
 				Opaque: map[string]any{"type": "code_execution_result", "outcome": part.CodeExecutionResult.Outcome},
@@ -790,7 +909,11 @@ func (p *Part) FromRequest(in *genai.Request) error {
 				p.InlineData.Data = data
 			}
 		} else {
-			if mimeType = base.MimeByExt(path.Ext(in.Doc.URL)); mimeType == "" {
+			// Files uploaded with Client.FileUpload have no extension in their URI.
+			if mimeType = base.MimeByExt(path.Ext(in.Doc.Filename)); mimeType == "" {
+				mimeType = base.MimeByExt(path.Ext(in.Doc.URL))
+			}
+			if mimeType == "" {
 				return fmt.Errorf("could not determine mime type for URL %q", in.Doc.URL)
 			}
 			p.FileData.MimeType = mimeType
@@ -849,7 +972,11 @@ func (p *Part) FromReply(in *genai.Reply) error {
 				p.InlineData.Data = data
 			}
 		} else {
-			if mimeType = base.MimeByExt(path.Ext(in.Doc.URL)); mimeType == "" {
+			// Files uploaded with Client.FileUpload have no extension in their URI.
+			if mimeType = base.MimeByExt(path.Ext(in.Doc.Filename)); mimeType == "" {
+				mimeType = base.MimeByExt(path.Ext(in.Doc.URL))
+			}
+			if mimeType == "" {
 				return fmt.Errorf("could not determine mime type for URL %q", in.Doc.URL)
 			}
 			p.FileData.MimeType = mimeType
@@ -917,6 +1044,21 @@ type CodeExecutionResult struct {
 	Output  string `json:"output,omitzero"`
 }
 
+// serverToolCall returns the code execution as a pending server tool call.
+func (e *ExecutableCode) serverToolCall() genai.ServerToolCall {
+	b, _ := json.Marshal(e)
+	return genai.ServerToolCall{Name: genai.ServerToolCodeExecution, Status: genai.ServerToolCallInProgress, Input: string(b)}
+}
+
+// serverToolCall returns the completion of the code execution.
+func (c *CodeExecutionResult) serverToolCall() genai.ServerToolCall {
+	s := genai.ServerToolCall{Name: genai.ServerToolCodeExecution, Status: genai.ServerToolCallCompleted, Output: c.Output}
+	if c.Outcome != "OUTCOME_OK" {
+		s.Status = genai.ServerToolCallFailed
+	}
+	return s
+}
+
 // VideoMetadata is documented at https://ai.google.dev/api/caching#VideoMetadata
 type VideoMetadata struct {
 	StartOffset Duration `json:"startOffset,omitzero"`
@@ -924,6 +1066,14 @@ type VideoMetadata struct {
 	FPS         int64    `json:"fps,omitzero"` // Default: 1.0, range ]0, 24]
 }
 
+// thinkingBudgets maps the generic reasoning effort to a thinking budget.
+var thinkingBudgets = map[genai.ReasoningEffort]int64{
+	genai.ReasoningEffortOff:    0,
+	genai.ReasoningEffortLow:    1024,
+	genai.ReasoningEffortMedium: 8192,
+	genai.ReasoningEffortHigh:   24576,
+}
+
 // ThinkingConfig is documented at https://ai.google.dev/api/generate-content?hl=en#ThinkingConfig
 // See https://ai.google.dev/gemini-api/docs/thinking#rest
 type ThinkingConfig struct {
@@ -953,12 +1103,13 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 			TotalTokens:       c.UsageMetadata.TotalTokenCount,
 		},
 	}
-	if len(c.Candidates) != 1 {
-		return out, fmt.Errorf("unexpected number of candidates; expected 1, got %d", len(c.Candidates))
+	if len(c.Candidates) == 0 {
+		return out, errors.New("server returned no candidate")
 	}
 	// Gemini is the only one returning uppercase so convert down for compatibility.
-	out.Usage.FinishReason = c.Candidates[0].FinishReason.ToFinishReason()
-	err := c.Candidates[0].To(&out.Message)
+	var err error
+	out.Usage.FinishReason, err = c.Candidates[0].FinishReason.ToFinishReason()
+	err = errors.Join(err, c.Candidates[0].To(&out.Message))
 	if out.Usage.FinishReason == genai.FinishedStop && slices.ContainsFunc(out.Replies, func(r genai.Reply) bool { return !r.ToolCall.IsZero() }) {
 		// Lie for the benefit of everyone.
 		out.Usage.FinishReason = genai.FinishedToolCalls
@@ -968,6 +1119,14 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	// only works in English (!)
 
 	out.Logprobs = c.Candidates[0].LogprobsResult.To()
+	if len(c.Candidates) > 1 {
+		out.Candidates = make([]genai.Message, len(c.Candidates)-1)
+		for i := 1; i < len(c.Candidates); i++ {
+			if err2 := c.Candidates[i].To(&out.Candidates[i-1]); err2 != nil {
+				err = errors.Join(err, fmt.Errorf("candidate #%d: %w", i, err2))
+			}
+		}
+	}
 	return out, err
 }
 
@@ -1014,16 +1173,15 @@ func (r *ResponseCandidate) To(out *genai.Message) error {
 	if err := r.Content.To(out); err != nil {
 		return err
 	}
-	if !internal.BeLenient {
-		if len(r.SafetyRatings) > 0 {
-			return &internal.BadError{Err: fmt.Errorf("implement safety rating: %v", r.SafetyRatings)}
-		}
-		if len(r.CitationMetadata.CitationSources) > 0 {
-			return &internal.BadError{Err: fmt.Errorf("implement citation metadata: %v", r.CitationMetadata.CitationSources)}
-		}
-		if len(r.GroundingAttributions) > 0 {
-			return &internal.BadError{Err: fmt.Errorf("implement grounding attributions: %v", r.GroundingAttributions)}
-		}
+	var errs []error
+	if len(r.SafetyRatings) > 0 {
+		errs = append(errs, &base.ErrUnhandled{Err: fmt.Errorf("implement safety rating: %v", r.SafetyRatings)})
+	}
+	if len(r.CitationMetadata.CitationSources) > 0 {
+		errs = append(errs, &base.ErrUnhandled{Err: fmt.Errorf("implement citation metadata: %v", r.CitationMetadata.CitationSources)})
+	}
+	if len(r.GroundingAttributions) > 0 {
+		errs = append(errs, &base.ErrUnhandled{Err: fmt.Errorf("implement grounding attributions: %v", r.GroundingAttributions)})
 	}
 	if !r.GroundingMetadata.IsZero() {
 		replies, err := r.GroundingMetadata.To()
@@ -1035,7 +1193,7 @@ func (r *ResponseCandidate) To(out *genai.Message) error {
 	if len(r.UrlContextMetadata.UrlMetadata) > 0 {
 		out.Replies = append(out.Replies, r.UrlContextMetadata.To()...)
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // Grounding types.
@@ -1056,10 +1214,32 @@ type GroundingChunkRetrievedContext struct {
 	FileSearchStore string `json:"fileSearchStore,omitzero"`
 }
 
+// GroundingChunkMaps is documented at https://ai.google.dev/api/generate-content?hl=en#Maps
+type GroundingChunkMaps struct {
+	URI                string             `json:"uri,omitzero"`
+	Title              string             `json:"title,omitzero"`
+	Text               string             `json:"text,omitzero"`
+	PlaceID            string             `json:"placeId,omitzero"`
+	PlaceAnswerSources PlaceAnswerSources `json:"placeAnswerSources,omitzero"`
+}
+
+// PlaceAnswerSources is documented at https://ai.google.dev/api/generate-content?hl=en#PlaceAnswerSources
+type PlaceAnswerSources struct {
+	ReviewSnippets []ReviewSnippet `json:"reviewSnippets,omitzero"`
+}
+
+// ReviewSnippet is documented at https://ai.google.dev/api/generate-content?hl=en#ReviewSnippet
+type ReviewSnippet struct {
+	ReviewID      string `json:"reviewId,omitzero"`
+	GoogleMapsURI string `json:"googleMapsUri,omitzero"`
+	Title         string `json:"title,omitzero"`
+}
+
 // GroundingChunk is documented at https://ai.google.dev/api/generate-content?hl=en#GroundingChunk
 type GroundingChunk struct {
 	Web              GroundingChunkWeb              `json:"web,omitzero"`
 	RetrievedContext GroundingChunkRetrievedContext `json:"retrievedContext,omitzero"`
+	Maps             GroundingChunkMaps             `json:"maps,omitzero"`
 }
 
 // Segment is documented at https://ai.google.dev/api/generate-content?hl=en#Segment
@@ -1095,52 +1275,84 @@ type GroundingMetadata struct {
 	WebSearchQueries  []string           `json:"webSearchQueries,omitzero"`
 	SearchEntryPoint  SearchEntryPoint   `json:"searchEntryPoint,omitzero"`
 	RetrievalMetadata RetrievalMetadata  `json:"retrievalMetadata,omitzero"`
+	// GoogleMapsWidgetContextToken is set when GoogleMaps.EnableWidget is true.
+	GoogleMapsWidgetContextToken string `json:"googleMapsWidgetContextToken,omitzero"`
 }
 
 // IsZero reports whether the value is zero.
 func (g *GroundingMetadata) IsZero() bool {
-	return len(g.GroundingChunks) == 0 && len(g.GroundingSupports) == 0 && len(g.WebSearchQueries) == 0 && len(g.SearchEntryPoint.SDKBlob) == 0 && g.RetrievalMetadata.GoogleSearchDynamicRetrievalScore == 0
+	return len(g.GroundingChunks) == 0 && len(g.GroundingSupports) == 0 && len(g.WebSearchQueries) == 0 && len(g.SearchEntryPoint.SDKBlob) == 0 && g.RetrievalMetadata.GoogleSearchDynamicRetrievalScore == 0 && g.GoogleMapsWidgetContextToken == ""
 }
 
 // To converts to the genai equivalent.
 func (g *GroundingMetadata) To() ([]genai.Reply, error) {
 	var out []genai.Reply
+	if len(g.WebSearchQueries) != 0 {
+		b, _ := json.Marshal(map[string]any{"queries": g.WebSearchQueries})
+		out = append(out, genai.Reply{ServerToolCall: genai.ServerToolCall{Name: genai.ServerToolWebSearch, Status: genai.ServerToolCallCompleted, Input: string(b)}})
+	}
 	src := make([]genai.CitationSource, 0, len(g.WebSearchQueries))
 	for _, q := range g.WebSearchQueries {
 		src = append(src, genai.CitationSource{Type: genai.CitationWebQuery, Snippet: q})
 	}
 	for _, s := range g.GroundingSupports {
 		c := genai.Citation{
+			CitedText:  s.Segment.Text,
 			StartIndex: s.Segment.StartIndex,
 			EndIndex:   s.Segment.EndIndex,
 		}
 		c.Sources = append(c.Sources, src...)
 		// This will cause duplicate source.
-		for _, idx := range s.GroundingChunkIndices {
-			if idx < 0 || idx > int64(len(g.GroundingChunks)) {
+		for i, idx := range s.GroundingChunkIndices {
+			if idx < 0 || idx >= int64(len(g.GroundingChunks)) {
 				return out, &internal.BadError{Err: fmt.Errorf("invalid grounding chunk index: %v", idx)}
 			}
+			// ConfidenceScores is parallel to GroundingChunkIndices.
+			confidence := 0.
+			if i < len(s.ConfidenceScores) {
+				confidence = s.ConfidenceScores[i]
+			}
 			gc := g.GroundingChunks[idx]
 			rc := gc.RetrievedContext
-			if rc.DocumentName != "" || rc.URI != "" || rc.FileSearchStore != "" {
+			if m := gc.Maps; m.URI != "" || m.PlaceID != "" {
+				c.Sources = append(c.Sources, genai.CitationSource{
+					Type:       genai.CitationWeb,
+					ID:         m.PlaceID,
+					Title:      m.Title,
+					URL:        m.URI,
+					Snippet:    m.Text,
+					Confidence: confidence,
+				})
+				for _, r := range m.PlaceAnswerSources.ReviewSnippets {
+					c.Sources = append(c.Sources, genai.CitationSource{
+						Type:  genai.CitationWeb,
+						ID:    r.ReviewID,
+						Title: r.Title,
+						URL:   r.GoogleMapsURI,
+					})
+				}
+			} else if rc.DocumentName != "" || rc.URI != "" || rc.FileSearchStore != "" {
 				id := rc.DocumentName
 				if id == "" {
 					id = rc.FileSearchStore
 				}
 				c.Sources = append(c.Sources, genai.CitationSource{
-					Type:    genai.CitationDocument,
-					ID:      id,
-					Title:   rc.Title,
-					URL:     rc.URI,
-					Snippet: rc.Text,
+					Type:         genai.CitationDocument,
+					ID:           id,
+					Title:        rc.Title,
+					URL:          rc.URI,
+					Snippet:      rc.Text,
+					DocumentName: rc.Title,
+					Confidence:   confidence,
 				})
 			} else {
-				// TODO: The URL points to https://vertexaisearch.cloud.google.com/grounding-api-redirect/... which is
-				// not good. We should to a HEAD request to get the actual URL.
+				// The URL points to https://vertexaisearch.cloud.google.com/grounding-api-redirect/... Use
+				// GroundingResolver to get the actual URL.
 				c.Sources = append(c.Sources, genai.CitationSource{
-					Type:  genai.CitationWeb,
-					URL:   gc.Web.URI,
-					Title: gc.Web.Title,
+					Type:       genai.CitationWeb,
+					URL:        gc.Web.URI,
+					Title:      gc.Web.Title,
+					Confidence: confidence,
 				})
 			}
 		}
@@ -1150,6 +1362,9 @@ func (g *GroundingMetadata) To() ([]genai.Reply, error) {
 	if len(src) > 0 && len(g.GroundingSupports) == 0 {
 		out = append(out, genai.Reply{Citation: genai.Citation{Sources: src}})
 	}
+	if g.GoogleMapsWidgetContextToken != "" {
+		out = append(out, genai.Reply{Opaque: map[string]any{"googleMapsWidgetContextToken": g.GoogleMapsWidgetContextToken}})
+	}
 	// SearchEntryPoint will contain some HTML.
 	return out, nil
 }
@@ -1243,25 +1458,21 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishMaxTokens:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishSafety, FinishBlocklist, FinishProhibitedContent, FinishSPII, FinishImageSafety:
 		// TODO: Confirm. We lose on nuance here but does it matter?
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	case FinishRecitation, FinishLanguage, FinishOther, FinishMalformed:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(strings.ToLower(string(f)))
+		return genai.FinishReason(strings.ToLower(string(f))), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(strings.ToLower(string(f)))
+		return genai.FinishReason(strings.ToLower(string(f))), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -1329,8 +1540,18 @@ type ImageRequest struct {
 	Parameters ImageParameters `json:"parameters"`
 }
 
-// Init initializes the request from the given parameters.
+// Init initializes the request from the given parameters for the Gemini API.
 func (i *ImageRequest) Init(msg *genai.Message, model string, mod genai.Modalities, opts ...genai.GenOption) error {
+	return i.init(msg, mod, false, opts...)
+}
+
+// InitVertex initializes the request from the given parameters for the Vertex AI API, which supports more
+// video options than the Gemini API.
+func (i *ImageRequest) InitVertex(msg *genai.Message, model string, mod genai.Modalities, opts ...genai.GenOption) error {
+	return i.init(msg, mod, true, opts...)
+}
+
+func (i *ImageRequest) init(msg *genai.Message, mod genai.Modalities, vertex bool, opts ...genai.GenOption) error {
 	if err := msg.Validate(); err != nil {
 		return err
 	}
@@ -1363,8 +1584,7 @@ func (i *ImageRequest) Init(msg *genai.Message, model string, mod genai.Modaliti
 		i.Instances[0].Image.BytesBase64Encoded = img
 		i.Instances[0].Image.MimeType = mimeStr
 	}
-	// This is important otherwise it can return 2 images.
-	// TODO: Expose it in OptionsImage.
+	// This is important otherwise it can return 4 images.
 	i.Parameters.SampleCount = 1
 	// The acceptable value depends on the country the paying user account is associated with.
 	i.Parameters.PersonGeneration = "allow_adult"
@@ -1379,10 +1599,41 @@ func (i *ImageRequest) Init(msg *genai.Message, model string, mod genai.Modaliti
 		case *GenOption:
 		case *genai.GenOptionImage:
 			// TODO: Width and Height
+			if v.Count != 0 {
+				i.Parameters.SampleCount = int64(v.Count)
+			}
 		case *genai.GenOptionVideo:
 			if v.Duration != 0 {
 				i.Parameters.Duration = base.DurationS(v.Duration.Round(time.Second).Seconds())
 			}
+			i.Parameters.Resolution = v.Resolution
+			if vertex {
+				i.Parameters.FPS = int64(v.FPS)
+				i.Parameters.GenerateAudio = v.GenerateAudio
+			} else {
+				var unsupported []string
+				if v.FPS != 0 {
+					unsupported = append(unsupported, "GenOptionVideo.FPS")
+				}
+				if v.GenerateAudio != nil {
+					unsupported = append(unsupported, "GenOptionVideo.GenerateAudio")
+				}
+				if len(unsupported) != 0 {
+					return &base.ErrNotSupported{Options: unsupported}
+				}
+			}
+			i.Parameters.NegativePrompt = v.NegativePrompt
+			if !v.LastFrame.IsZero() {
+				if v.LastFrame.URL != "" {
+					return errors.New("field LastFrame: URL is not supported")
+				}
+				m, b, err := v.LastFrame.Read(10 * 1024 * 1024)
+				if err != nil {
+					return fmt.Errorf("field LastFrame: %w", err)
+				}
+				i.Instances[0].LastFrame.BytesBase64Encoded = b
+				i.Instances[0].LastFrame.MimeType = m
+			}
 		default:
 			return &base.ErrNotSupported{Options: []string{internal.TypeName(opt)}}
 		}
@@ -1398,6 +1649,11 @@ type ImageInstance struct {
 		BytesBase64Encoded []byte `json:"bytesBase64Encoded,omitzero"`
 		MimeType           string `json:"mimeType,omitzero"`
 	} `json:"image,omitzero"`
+	// LastFrame is only supported for video generation.
+	LastFrame struct {
+		BytesBase64Encoded []byte `json:"bytesBase64Encoded,omitzero"`
+		MimeType           string `json:"mimeType,omitzero"`
+	} `json:"lastFrame,omitzero"`
 }
 
 // ImageParameters is not really documented, better to read the SDK code and guess, since they don't use proper
@@ -1426,13 +1682,11 @@ type ImageParameters struct {
 	// Video only.
 	Duration       base.DurationS `json:"durationSeconds,omitzero"`
 	NegativePrompt string         `json:"negativePrompt,omitzero"`
+	Resolution     string         `json:"resolution,omitzero"` // "720p", "1080p"
 	// VertexAI only:
-	// FPS int64 `json:"fps,omitzero"`
-	// Seed int64 `json:"seed,omitzero"`
-	// Resolution string `json:"resolution,omitzero"`
+	FPS           int64 `json:"fps,omitzero"`
+	GenerateAudio *bool `json:"generateAudio,omitzero"`
 	// PubSubTopic string `json:"pubSubTopic,omitzero"`
-	// GenerateAudio string `json:"generateAudio,omitzero"`
-	// LastFrame string `json:"lastFrame,omitzero"`
 	// CompressionQuality string `json:"compressionQuality,omitzero"`
 }
 
@@ -1562,6 +1816,16 @@ type FileSearchStore struct {
 	EmbeddingModel       string               `json:"embeddingModel,omitzero"`
 }
 
+// GetID implements genai.VectorStoreEntry.
+func (f *FileSearchStore) GetID() string {
+	return f.Name
+}
+
+// GetDisplayName implements genai.VectorStoreEntry.
+func (f *FileSearchStore) GetDisplayName() string {
+	return f.DisplayName
+}
+
 // FileSearchStoreListResponse is the response from listing file search stores.
 type FileSearchStoreListResponse struct {
 	FileSearchStores []FileSearchStore `json:"fileSearchStores,omitzero"`
@@ -1741,6 +2005,11 @@ func (m *Model) Context() int64 {
 	return m.InputTokenLimit
 }
 
+// MaxOutputTokens implements genai.ModelOutputTokens.
+func (m *Model) MaxOutputTokens() int64 {
+	return m.OutputTokenLimit
+}
+
 // ModelsResponse represents the response structure for Gemini models listing.
 type ModelsResponse struct {
 	Models        []Model `json:"models"`
@@ -1827,3 +2096,92 @@ type ErrorResponseError struct {
 		RetryDelay string `json:"retryDelay"` // "28s"
 	} `json:"details"`
 }
+
+// Live API types.
+
+// LiveClientMessage is documented at https://ai.google.dev/api/live#BidiGenerateContentClientMessage
+//
+// Exactly one field must be set.
+type LiveClientMessage struct {
+	Setup         *LiveSetup         `json:"setup,omitzero"`
+	ClientContent *LiveClientContent `json:"clientContent,omitzero"`
+	RealtimeInput *LiveRealtimeInput `json:"realtimeInput,omitzero"`
+}
+
+// LiveSetup is documented at https://ai.google.dev/api/live#BidiGenerateContentSetup
+type LiveSetup struct {
+	Model            string `json:"model"` // "models/<model>"
+	GenerationConfig struct {
+		ResponseModalities []Modality `json:"responseModalities,omitzero"`
+		Temperature        float64    `json:"temperature,omitzero"`
+		TopP               float64    `json:"topP,omitzero"`
+		TopK               int64      `json:"topK,omitzero"`
+		MaxOutputTokens    int64      `json:"maxOutputTokens,omitzero"`
+		SpeechConfig       struct {
+			VoiceConfig struct {
+				PrebuiltVoiceConfig struct {
+					VoiceName string `json:"voiceName,omitzero"`
+				} `json:"prebuiltVoiceConfig,omitzero"`
+			} `json:"voiceConfig,omitzero"`
+		} `json:"speechConfig,omitzero"`
+	} `json:"generationConfig,omitzero"`
+	SystemInstruction Content `json:"systemInstruction,omitzero"`
+	// OutputAudioTranscription enables the transcription of the audio output when set to an empty struct.
+	OutputAudioTranscription *struct{} `json:"outputAudioTranscription,omitzero"`
+}
+
+// LiveClientContent is documented at https://ai.google.dev/api/live#BidiGenerateContentClientContent
+type LiveClientContent struct {
+	Turns        []Content `json:"turns,omitzero"`
+	TurnComplete bool      `json:"turnComplete,omitzero"`
+}
+
+// LiveRealtimeInput is documented at https://ai.google.dev/api/live#BidiGenerateContentRealtimeInput
+type LiveRealtimeInput struct {
+	Audio          Blob `json:"audio,omitzero"` // "audio/pcm;rate=16000"
+	AudioStreamEnd bool `json:"audioStreamEnd,omitzero"`
+}
+
+// LiveServerMessage is documented at https://ai.google.dev/api/live#BidiGenerateContentServerMessage
+type LiveServerMessage struct {
+	SetupComplete *struct{}         `json:"setupComplete,omitzero"`
+	ServerContent LiveServerContent `json:"serverContent,omitzero"`
+	UsageMetadata LiveUsageMetadata `json:"usageMetadata,omitzero"`
+	GoAway        struct {
+		TimeLeft string `json:"timeLeft"`
+	} `json:"goAway,omitzero"`
+	SessionResumptionUpdate json.RawMessage `json:"sessionResumptionUpdate,omitzero"`
+	ToolCall                json.RawMessage `json:"toolCall,omitzero"`
+	ToolCallCancellation    json.RawMessage `json:"toolCallCancellation,omitzero"`
+}
+
+// LiveServerContent is documented at https://ai.google.dev/api/live#BidiGenerateContentServerContent
+type LiveServerContent struct {
+	ModelTurn          Content `json:"modelTurn,omitzero"`
+	GenerationComplete bool    `json:"generationComplete,omitzero"`
+	TurnComplete       bool    `json:"turnComplete,omitzero"`
+	Interrupted        bool    `json:"interrupted,omitzero"`
+	InputTranscription struct {
+		Text string `json:"text"`
+	} `json:"inputTranscription,omitzero"`
+	OutputTranscription struct {
+		Text string `json:"text"`
+	} `json:"outputTranscription,omitzero"`
+	GroundingMetadata  json.RawMessage `json:"groundingMetadata,omitzero"`
+	TurnCompleteReason string          `json:"turnCompleteReason,omitzero"`
+	WaitingForInput    bool            `json:"waitingForInput,omitzero"`
+}
+
+// LiveUsageMetadata is documented at https://ai.google.dev/api/live#UsageMetadata
+type LiveUsageMetadata struct {
+	PromptTokenCount           int64                `json:"promptTokenCount,omitzero"`
+	CachedContentTokenCount    int64                `json:"cachedContentTokenCount,omitzero"`
+	ResponseTokenCount         int64                `json:"responseTokenCount,omitzero"`
+	ToolUsePromptTokenCount    int64                `json:"toolUsePromptTokenCount,omitzero"`
+	ThoughtsTokenCount         int64                `json:"thoughtsTokenCount,omitzero"`
+	TotalTokenCount            int64                `json:"totalTokenCount,omitzero"`
+	PromptTokensDetails        []ModalityTokenCount `json:"promptTokensDetails,omitzero"`
+	CacheTokensDetails         []ModalityTokenCount `json:"cacheTokensDetails,omitzero"`
+	ResponseTokensDetails      []ModalityTokenCount `json:"responseTokensDetails,omitzero"`
+	ToolUsePromptTokensDetails []ModalityTokenCount `json:"toolUsePromptTokensDetails,omitzero"`
+}
@@ -8,7 +8,9 @@ package gemini
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -277,3 +279,167 @@ func TestImageParametersDurationS(t *testing.T) {
 		t.Errorf("MarshalJSON() = %s, want %s", got, want)
 	}
 }
+
+func TestImageRequest_Count(t *testing.T) {
+	mod := genai.Modalities{genai.ModalityImage}
+	msg := genai.NewTextMessage("a cat")
+	for _, tt := range []struct {
+		name string
+		opts []genai.GenOption
+		want int64
+	}{
+		{"default", nil, 1},
+		{"count", []genai.GenOption{&genai.GenOptionImage{Count: 3}}, 3},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var req ImageRequest
+			if err := req.Init(&msg, "imagen-4.0-generate-001", mod, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if req.Parameters.SampleCount != tt.want {
+				t.Errorf("SampleCount = %d, want %d", req.Parameters.SampleCount, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageRequest_Video(t *testing.T) {
+	mod := genai.Modalities{genai.ModalityVideo}
+	msg := genai.NewTextMessage("a cat")
+	opts := &genai.GenOptionVideo{
+		Duration:       8 * time.Second,
+		Resolution:     "1080p",
+		FPS:            24,
+		GenerateAudio:  new(false),
+		NegativePrompt: "dogs",
+		LastFrame:      genai.Doc{Filename: "end.png", Src: strings.NewReader("\x89PNG\r\n\x1a\n")},
+	}
+	var req ImageRequest
+	if err := req.InitVertex(&msg, "veo-3.0-generate-001", mod, opts); err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(&req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"instances":[{"prompt":"a cat","lastFrame":{"bytesBase64Encoded":"iVBORw0KGgo=","mimeType":"image/png"}}],"parameters":{"sampleCount":1,"personGeneration":"allow_adult","durationSeconds":8,"negativePrompt":"dogs","resolution":"1080p","fps":24,"generateAudio":false}}`
+	if string(got) != want {
+		t.Errorf("MarshalJSON()\ngot  %s\nwant %s", got, want)
+	}
+	t.Run("Gemini", func(t *testing.T) {
+		var req ImageRequest
+		o := &genai.GenOptionVideo{FPS: 24, GenerateAudio: new(true)}
+		if err := req.Init(&msg, "veo-3.0-generate-001", mod, o); err == nil || err.Error() != "not supported: GenOptionVideo.FPS, GenOptionVideo.GenerateAudio" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("LastFrame_URL", func(t *testing.T) {
+		var req ImageRequest
+		o := &genai.GenOptionVideo{LastFrame: genai.Doc{URL: "https://example.com/end.png"}}
+		if err := req.Init(&msg, "veo-3.0-generate-001", mod, o); err == nil || err.Error() != "field LastFrame: URL is not supported" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestChatRequest_DecodeAsWrapped(t *testing.T) {
+	var req ChatRequest
+	opts := &genai.GenOptionText{DecodeAs: &[]string{}}
+	if err := req.Init(genai.Messages{genai.NewTextMessage("list")}, "gemini-2.5-flash", opts); err != nil {
+		t.Fatal(err)
+	}
+	want := Schema{
+		Type:       TypeObject,
+		Properties: map[string]Schema{"value": {Type: TypeArray, Items: &Schema{Type: TypeString}}},
+		Required:   []string{"value"},
+	}
+	if diff := cmp.Diff(want, req.GenerationConfig.ResponseSchema); diff != "" {
+		t.Fatalf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestChatRequest_GoogleMaps(t *testing.T) {
+	var req ChatRequest
+	opts := &GenOption{GoogleMaps: &GoogleMaps{EnableWidget: true}, LatLng: &LatLng{Latitude: 45.5, Longitude: -73.6}}
+	if err := req.Init(genai.Messages{genai.NewTextMessage("coffee nearby?")}, "gemini-2.5-flash", opts); err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(struct {
+		Tools      []Tool     `json:"tools"`
+		ToolConfig ToolConfig `json:"toolConfig"`
+	}{req.Tools, req.ToolConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"tools":[{"googleMaps":{"enableWidget":true}}],"toolConfig":{"retrievalConfig":{"latLng":{"latitude":45.5,"longitude":-73.6}}}}`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if err := (&GenOption{LatLng: &LatLng{Latitude: 91}}).Validate(); err == nil {
+		t.Error("expected error for invalid latitude")
+	}
+}
+
+func TestChatRequest_GoogleMapsWidgetToken(t *testing.T) {
+	// The widget token returned by GroundingMetadata.To is not sent back in the following turns.
+	var g GroundingMetadata
+	if err := json.Unmarshal([]byte(`{"googleMapsWidgetContextToken":"widgetcontent/abc"}`), &g); err != nil {
+		t.Fatal(err)
+	}
+	replies, err := g.To()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assistant := genai.Message{Replies: append([]genai.Reply{{Text: "Try Cafe."}}, replies...)}
+	var req ChatRequest
+	msgs := genai.Messages{genai.NewTextMessage("coffee nearby?"), assistant, genai.NewTextMessage("thanks")}
+	if err := req.Init(msgs, "gemini-2.5-flash"); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Contents) != 3 || len(req.Contents[1].Parts) != 1 || req.Contents[1].Parts[0].Text != "Try Cafe." {
+		t.Fatalf("unexpected contents %#v", req.Contents)
+	}
+}
+
+func TestChatRequest_MediaResolution(t *testing.T) {
+	img := func(d genai.ImageDetail) genai.Request {
+		return genai.Request{Doc: genai.Doc{URL: "https://example.com/image.jpg", Detail: d}}
+	}
+	tests := []struct {
+		name string
+		in   []genai.Request
+		want MediaResolution
+	}{
+		{"default", []genai.Request{img(genai.ImageDetailAuto)}, MediaResolutionUnspecified},
+		{"low", []genai.Request{img(genai.ImageDetailLow)}, MediaResolutionLow},
+		{"high wins", []genai.Request{img(genai.ImageDetailLow), img(genai.ImageDetailHigh)}, MediaResolutionHigh},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req ChatRequest
+			msgs := genai.Messages{{Requests: append([]genai.Request{{Text: "describe"}}, tt.in...)}}
+			if err := req.Init(msgs, "gemini-2.5-flash"); err != nil {
+				t.Fatal(err)
+			}
+			if got := req.GenerationConfig.MediaResolution; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChatRequest_FileSearch(t *testing.T) {
+	var req ChatRequest
+	opts := &genai.GenOptionFileSearch{StoreIDs: []string{"fileSearchStores/abc", "fileSearchStores/def"}}
+	if err := req.Init(genai.Messages{genai.NewTextMessage("what's in the docs?")}, "gemini-2.5-flash", opts); err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(req.Tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `[{"fileSearch":{"fileSearchStoreNames":["fileSearchStores/abc","fileSearchStores/def"]}}]`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Live API support for bidirectional audio sessions.
+//
+// See https://ai.google.dev/gemini-api/docs/live
+
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/maruel/roundtrippers"
+	"golang.org/x/net/websocket"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/internal/bearer"
+)
+
+const liveURL = "wss://generativelanguage.googleapis.com/ws/google.ai.generativelanguage.v1beta.GenerativeService.BidiGenerateContent"
+
+// GenOptionLive defines Gemini Live specific options for Client.Live.
+type GenOptionLive struct {
+	// Voice is the prebuilt voice used for audio output, e.g. "Puck", "Kore".
+	Voice string
+	// TextOnly requests text output instead of audio.
+	TextOnly bool
+}
+
+// Validate implements genai.Validatable.
+func (o *GenOptionLive) Validate() error {
+	if o.TextOnly && o.Voice != "" {
+		return errors.New("field Voice can't be used with TextOnly")
+	}
+	return nil
+}
+
+// Live implements genai.ProviderLive.
+//
+// It opens a session with the Gemini Live API. The model must support the Live API, e.g.
+// "gemini-live-2.5-flash-preview".
+//
+// Audio input is PCM16 at 16kHz mono. Audio output is PCM16 at 24kHz mono. The transcript of the audio output
+// is returned as text.
+//
+// Supported options are *genai.GenOptionText and *GenOptionLive.
+func (c *Client) Live(ctx context.Context, opts ...genai.GenOption) (genai.LiveSession, error) {
+	if c.impl.Model == "" {
+		return nil, errors.New("a model is required")
+	}
+	setup, err := initLiveSetup(c.impl.Model, opts)
+	if err != nil {
+		return nil, err
+	}
+	wsCfg, err := websocket.NewConfig(liveURL, "https://generativelanguage.googleapis.com")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create websocket config: %w", err)
+	}
+	// Extract auth headers from the HTTP client's transport chain.
+	wsCfg.Header = http.Header{}
+	switch h := c.impl.Client.Transport.(type) {
+	case *roundtrippers.Header:
+		for k, vs := range h.Header {
+			for _, v := range vs {
+				wsCfg.Header.Set(k, v)
+			}
+		}
+	case *bearer.Transport:
+		tok, err := h.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		wsCfg.Header.Set("Authorization", "Bearer "+tok)
+	}
+	raw, err := wsCfg.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to websocket %s: %w", liveURL, err)
+	}
+	s := &LiveSession{ws: &websocketConn{raw}}
+	if err := s.setup(setup); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func initLiveSetup(model string, opts []genai.GenOption) (*LiveSetup, error) {
+	setup := &LiveSetup{Model: "models/" + model}
+	setup.GenerationConfig.ResponseModalities = []Modality{ModalityAudio}
+	setup.OutputAudioTranscription = &struct{}{}
+	var unsupported []string
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return nil, err
+		}
+		switch v := opt.(type) {
+		case *genai.GenOptionText:
+			if v.SystemPrompt != "" {
+				setup.SystemInstruction.Parts = []Part{{Text: v.SystemPrompt}}
+			}
+			setup.GenerationConfig.Temperature = v.Temperature
+			setup.GenerationConfig.TopP = v.TopP
+			setup.GenerationConfig.TopK = v.TopK
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
+			setup.GenerationConfig.MaxOutputTokens = v.MaxTokens
+			if len(v.Stop) != 0 {
+				unsupported = append(unsupported, "GenOptionText.Stop")
+			}
+			if v.TopLogprobs != 0 {
+				unsupported = append(unsupported, "GenOptionText.TopLogprobs")
+			}
+		case *GenOptionLive:
+			setup.GenerationConfig.SpeechConfig.VoiceConfig.PrebuiltVoiceConfig.VoiceName = v.Voice
+			if v.TextOnly {
+				setup.GenerationConfig.ResponseModalities = []Modality{ModalityText}
+				setup.OutputAudioTranscription = nil
+			}
+		default:
+			unsupported = append(unsupported, internal.TypeName(opt))
+		}
+	}
+	if len(unsupported) != 0 {
+		return nil, &base.ErrNotSupported{Options: unsupported}
+	}
+	return setup, nil
+}
+
+// wsConn abstracts a WebSocket text-message connection.
+type wsConn interface {
+	Send(data string) error
+	Receive(msg *string) error
+	Close() error
+}
+
+// websocketConn adapts *websocket.Conn to wsConn.
+type websocketConn struct {
+	*websocket.Conn
+}
+
+func (c *websocketConn) Send(data string) error {
+	return websocket.Message.Send(c.Conn, data)
+}
+
+func (c *websocketConn) Receive(msg *string) error {
+	return websocket.Message.Receive(c.Conn, msg)
+}
+
+// LiveSession is a bidirectional session with the Gemini Live API.
+//
+// Create via Client.Live().
+type LiveSession struct {
+	mu sync.Mutex
+	// Protected by mu.
+	ws wsConn
+}
+
+// setup sends the setup message and waits for the server to acknowledge it.
+func (s *LiveSession) setup(setup *LiveSetup) error {
+	if err := s.send(&LiveClientMessage{Setup: setup}); err != nil {
+		return err
+	}
+	msg, err := s.recv()
+	if err != nil {
+		return err
+	}
+	if msg.SetupComplete == nil {
+		return errors.New("expected setupComplete message")
+	}
+	return nil
+}
+
+// Send implements genai.LiveSession.
+func (s *LiveSession) Send(ctx context.Context, in *genai.LiveInput) error {
+	if err := in.Validate(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	switch {
+	case len(in.Audio) != 0:
+		return s.send(&LiveClientMessage{RealtimeInput: &LiveRealtimeInput{Audio: Blob{MimeType: "audio/pcm;rate=16000", Data: in.Audio}}})
+	case in.Text != "":
+		return s.send(&LiveClientMessage{ClientContent: &LiveClientContent{
+			Turns:        []Content{{Role: "user", Parts: []Part{{Text: in.Text}}}},
+			TurnComplete: true,
+		}})
+	default:
+		return s.send(&LiveClientMessage{RealtimeInput: &LiveRealtimeInput{AudioStreamEnd: true}})
+	}
+}
+
+// Recv implements genai.LiveSession.
+//
+// The context cancellation is only checked between server messages. Call Close() to unblock a pending read.
+func (s *LiveSession) Recv(ctx context.Context) (iter.Seq[genai.LiveEvent], func() error) {
+	var finalErr error
+	fnEvents := func(yield func(genai.LiveEvent) bool) {
+		for {
+			if finalErr = ctx.Err(); finalErr != nil {
+				return
+			}
+			msg, err := s.recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) && !strings.Contains(err.Error(), "use of closed network connection") {
+					finalErr = err
+				}
+				return
+			}
+			for _, evt := range processLiveMessage(msg) {
+				if !yield(evt) {
+					return
+				}
+			}
+		}
+	}
+	return fnEvents, func() error { return finalErr }
+}
+
+// processLiveMessage converts a server message into genai.LiveEvent.
+func processLiveMessage(msg *LiveServerMessage) []genai.LiveEvent {
+	var out []genai.LiveEvent
+	sc := &msg.ServerContent
+	for i := range sc.ModelTurn.Parts {
+		p := &sc.ModelTurn.Parts[i]
+		switch {
+		case p.Thought:
+			// Thoughts are not forwarded in live sessions.
+		case p.Text != "":
+			out = append(out, genai.LiveEvent{Reply: genai.Reply{Text: p.Text}})
+		case len(p.InlineData.Data) != 0:
+			out = append(out, genai.LiveEvent{Reply: genai.Reply{Doc: genai.Doc{Filename: "audio.pcm", Src: bytes.NewReader(p.InlineData.Data)}}})
+		}
+	}
+	if t := sc.OutputTranscription.Text; t != "" {
+		out = append(out, genai.LiveEvent{Reply: genai.Reply{Text: t}})
+	}
+	if sc.Interrupted {
+		out = append(out, genai.LiveEvent{Interrupted: true})
+	}
+	if sc.TurnComplete {
+		u := &msg.UsageMetadata
+		out = append(out, genai.LiveEvent{
+			TurnComplete: true,
+			Usage: genai.Usage{
+				InputTokens:       u.PromptTokenCount,
+				InputCachedTokens: u.CachedContentTokenCount,
+				ReasoningTokens:   u.ThoughtsTokenCount,
+				OutputTokens:      u.ResponseTokenCount + u.ToolUsePromptTokenCount + u.ThoughtsTokenCount,
+				TotalTokens:       u.TotalTokenCount,
+				FinishReason:      genai.FinishedStop,
+			},
+		})
+	}
+	return out
+}
+
+// Close implements io.Closer. It closes the WebSocket connection.
+func (s *LiveSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ws == nil {
+		return nil
+	}
+	err := s.ws.Close()
+	s.ws = nil
+	return err
+}
+
+func (s *LiveSession) send(msg *LiveClientMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ws == nil {
+		return errors.New("websocket connection is closed")
+	}
+	return s.ws.Send(string(data))
+}
+
+func (s *LiveSession) recv() (*LiveServerMessage, error) {
+	s.mu.Lock()
+	ws := s.ws
+	s.mu.Unlock()
+	if ws == nil {
+		return nil, errors.New("websocket connection is closed")
+	}
+	var raw string
+	if err := ws.Receive(&raw); err != nil {
+		if errors.Is(err, io.EOF) || strings.Contains(err.Error(), "use of closed network connection") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("websocket receive: %w", err)
+	}
+	msg := &LiveServerMessage{}
+	d := json.NewDecoder(strings.NewReader(raw))
+	d.DisallowUnknownFields()
+	if err := d.Decode(msg); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w; raw: %s", err, raw)
+	}
+	return msg, nil
+}
+
+var (
+	_ genai.ProviderLive = &Client{}
+	_ genai.LiveSession  = &LiveSession{}
+)
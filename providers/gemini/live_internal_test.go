@@ -0,0 +1,138 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for live.go
+
+package gemini
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/maruel/genai"
+)
+
+func TestLiveSession(t *testing.T) {
+	ws := &fakeWS{recv: []string{
+		`{"setupComplete":{}}`,
+		`{"serverContent":{"modelTurn":{"parts":[{"inlineData":{"mimeType":"audio/pcm;rate=24000","data":"AQIDBA=="}}]}}}`,
+		`{"serverContent":{"outputTranscription":{"text":"Hi"}}}`,
+		`{"serverContent":{"interrupted":true}}`,
+		`{"serverContent":{"turnComplete":true},"usageMetadata":{"promptTokenCount":10,"cachedContentTokenCount":4,"responseTokenCount":20,"totalTokenCount":30}}`,
+	}}
+	s := &LiveSession{ws: ws}
+	if err := s.setup(&LiveSetup{Model: "models/m"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(t.Context(), &genai.LiveInput{Audio: []byte{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(t.Context(), &genai.LiveInput{EndOfTurn: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(t.Context(), &genai.LiveInput{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"setup":{"model":"models/m"}}`,
+		`{"realtimeInput":{"audio":{"mimeType":"audio/pcm;rate=16000","data":"AQI="}}}`,
+		`{"realtimeInput":{"audioStreamEnd":true}}`,
+		`{"clientContent":{"turns":[{"role":"user","parts":[{"text":"hello"}]}],"turnComplete":true}}`,
+	}
+	if diff := cmp.Diff(want, ws.sent); diff != "" {
+		t.Fatalf("unexpected sent messages (-want +got):\n%s", diff)
+	}
+	events, finish := s.Recv(t.Context())
+	got := slices.Collect(events)
+	if err := finish(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("unexpected events: %#v", got)
+	}
+	if got[0].Reply.Doc.Filename != "audio.pcm" {
+		t.Fatalf("unexpected audio: %#v", got[0])
+	}
+	if b, _ := io.ReadAll(got[0].Reply.Doc.Src); !slices.Equal(b, []byte{1, 2, 3, 4}) {
+		t.Fatalf("unexpected audio: %v", b)
+	}
+	if got[1].Reply.Text != "Hi" {
+		t.Fatalf("unexpected transcript: %#v", got[1])
+	}
+	if !got[2].Interrupted {
+		t.Fatalf("expected interruption: %#v", got[2])
+	}
+	if !got[3].TurnComplete {
+		t.Fatalf("unexpected turn completion: %#v", got[3])
+	}
+	wantUsage := genai.Usage{InputTokens: 10, InputCachedTokens: 4, OutputTokens: 20, TotalTokens: 30, FinishReason: genai.FinishedStop}
+	if diff := cmp.Diff(wantUsage, got[3].Usage); diff != "" {
+		t.Fatalf("unexpected usage (-want +got):\n%s", diff)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(t.Context(), &genai.LiveInput{Text: "hello"}); err == nil {
+		t.Fatal("expected error after Close")
+	}
+}
+
+func TestInitLiveSetup(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		setup, err := initLiveSetup("m", []genai.GenOption{
+			&genai.GenOptionText{SystemPrompt: "Be nice."},
+			&GenOptionLive{Voice: "Puck"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if setup.Model != "models/m" || setup.SystemInstruction.Parts[0].Text != "Be nice." ||
+			setup.GenerationConfig.SpeechConfig.VoiceConfig.PrebuiltVoiceConfig.VoiceName != "Puck" ||
+			setup.OutputAudioTranscription == nil {
+			t.Fatalf("unexpected setup: %+v", setup)
+		}
+	})
+	t.Run("text", func(t *testing.T) {
+		setup, err := initLiveSetup("m", []genai.GenOption{&GenOptionLive{TextOnly: true}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(setup.GenerationConfig.ResponseModalities, []Modality{ModalityText}) || setup.OutputAudioTranscription != nil {
+			t.Fatalf("unexpected setup: %+v", setup)
+		}
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := initLiveSetup("m", []genai.GenOption{&genai.GenOptionText{Stop: []string{"a"}}})
+		if err == nil || err.Error() != "not supported: GenOptionText.Stop" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+// fakeWS is an in-memory wsConn.
+type fakeWS struct {
+	sent []string
+	recv []string
+}
+
+func (f *fakeWS) Send(data string) error {
+	f.sent = append(f.sent, data)
+	return nil
+}
+
+func (f *fakeWS) Receive(msg *string) error {
+	if len(f.recv) == 0 {
+		return io.EOF
+	}
+	*msg = f.recv[0]
+	f.recv = f.recv[1:]
+	return nil
+}
+
+func (f *fakeWS) Close() error {
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Resolution of grounding redirect URLs to their final destination.
+
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/maruel/genai"
+)
+
+// groundingRedirectPrefix is the prefix of the URLs returned in web grounding chunks.
+const groundingRedirectPrefix = "https://vertexaisearch.cloud.google.com/grounding-api-redirect/"
+
+// GroundingResolver replaces the vertexaisearch.cloud.google.com redirect URLs returned by Google Search
+// grounding with their final destination.
+//
+// Set it in GenOption.GroundingResolver to resolve the citations returned by GenSync and GenStream. Resolution
+// is best effort: a URL that fails to resolve is left as-is.
+//
+// It is safe for concurrent use. Resolved URLs are cached, so reuse the same instance across requests.
+type GroundingResolver struct {
+	// Client is used to send the HEAD requests. Redirects are not followed. Defaults to http.DefaultClient.
+	Client *http.Client
+	// MaxConcurrency is the maximum number of concurrent HEAD requests. Defaults to 4.
+	MaxConcurrency int
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// Resolve returns the destination of a grounding redirect URL.
+//
+// Other URLs are returned as-is.
+func (g *GroundingResolver) Resolve(ctx context.Context, url string) (string, error) {
+	if !strings.HasPrefix(url, groundingRedirectPrefix) {
+		return url, nil
+	}
+	g.mu.Lock()
+	dst, ok := g.cache[url]
+	g.mu.Unlock()
+	if ok {
+		return dst, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return url, err
+	}
+	c := http.Client{}
+	if g.Client != nil {
+		c = *g.Client
+	}
+	c.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := c.Do(req)
+	if err != nil {
+		return url, err
+	}
+	_ = resp.Body.Close()
+	loc, err := resp.Location()
+	if err != nil {
+		return url, fmt.Errorf("failed to resolve %s: http %d: %w", url, resp.StatusCode, err)
+	}
+	dst = loc.String()
+	g.mu.Lock()
+	if g.cache == nil {
+		g.cache = map[string]string{}
+	}
+	g.cache[url] = dst
+	g.mu.Unlock()
+	return dst, nil
+}
+
+// ResolveReplies replaces the grounding redirect URLs in the citations of replies.
+//
+// URLs that failed to resolve are left unchanged and their errors are returned.
+func (g *GroundingResolver) ResolveReplies(ctx context.Context, replies []genai.Reply) error {
+	// The same chunk is often cited multiple times.
+	srcs := map[string][]*genai.CitationSource{}
+	for i := range replies {
+		for j := range replies[i].Citation.Sources {
+			if s := &replies[i].Citation.Sources[j]; strings.HasPrefix(s.URL, groundingRedirectPrefix) {
+				srcs[s.URL] = append(srcs[s.URL], s)
+			}
+		}
+	}
+	if len(srcs) == 0 {
+		return nil
+	}
+	n := g.MaxConcurrency
+	if n <= 0 {
+		n = 4
+	}
+	sem := make(chan struct{}, n)
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for url, s := range srcs {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			dst, err := g.Resolve(ctx, url)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				return
+			}
+			// Each goroutine updates distinct sources.
+			for _, src := range s {
+				src.URL = dst
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// groundingResolver returns the GroundingResolver set in opts, if any.
+func groundingResolver(opts []genai.GenOption) *GroundingResolver {
+	for _, opt := range opts {
+		if o, ok := opt.(*GenOption); ok && o.GroundingResolver != nil {
+			return o.GroundingResolver
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for grounding redirect URL resolution.
+
+package gemini_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/providers/gemini"
+)
+
+func TestGroundingResolver(t *testing.T) {
+	const redirect = "https://vertexaisearch.cloud.google.com/grounding-api-redirect/"
+	var calls atomic.Int32
+	g := gemini.GroundingResolver{
+		Client: &http.Client{Transport: internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			calls.Add(1)
+			if r.Method != http.MethodHead {
+				t.Errorf("unexpected method %s", r.Method)
+			}
+			h := http.Header{}
+			code := http.StatusNotFound
+			if id := strings.TrimPrefix(r.URL.String(), redirect); id != "bad" {
+				h.Set("Location", "https://example.com/"+id)
+				code = http.StatusFound
+			}
+			return &http.Response{StatusCode: code, Header: h, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+		})},
+		MaxConcurrency: 2,
+	}
+	t.Run("valid", func(t *testing.T) {
+		replies := []genai.Reply{
+			{Text: "hello"},
+			{Citation: genai.Citation{Sources: []genai.CitationSource{
+				{Type: genai.CitationWebQuery, Snippet: "query"},
+				{Type: genai.CitationWeb, URL: redirect + "a"},
+				{Type: genai.CitationWeb, URL: redirect + "b"},
+				{Type: genai.CitationWeb, URL: "https://example.org/"},
+			}}},
+			{Citation: genai.Citation{Sources: []genai.CitationSource{{Type: genai.CitationWeb, URL: redirect + "a"}}}},
+		}
+		if err := g.ResolveReplies(t.Context(), replies); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range replies {
+			for _, s := range r.Citation.Sources {
+				got = append(got, s.URL)
+			}
+		}
+		want := []string{"", "https://example.com/a", "https://example.com/b", "https://example.org/", "https://example.com/a"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		if n := calls.Load(); n != 2 {
+			t.Errorf("got %d requests, want 2", n)
+		}
+		// Cached.
+		if u, err := g.Resolve(t.Context(), redirect+"b"); err != nil || u != "https://example.com/b" {
+			t.Errorf("got %q, %v", u, err)
+		}
+		if n := calls.Load(); n != 2 {
+			t.Errorf("got %d requests, want 2", n)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		replies := []genai.Reply{{Citation: genai.Citation{Sources: []genai.CitationSource{{Type: genai.CitationWeb, URL: redirect + "bad"}}}}}
+		if err := g.ResolveReplies(t.Context(), replies); err == nil {
+			t.Fatal("expected error")
+		}
+		if u := replies[0].Citation.Sources[0].URL; u != redirect+"bad" {
+			t.Errorf("URL should be unchanged, got %q", u)
+		}
+	})
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Object detection and segmentation.
+
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/maruel/genai"
+)
+
+// Box2D is a bounding box as [ymin, xmin, ymax, xmax], with each coordinate normalized to [0, 1000]
+// relative to the image size.
+//
+// This is the format Gemini is trained to output. See
+// https://ai.google.dev/gemini-api/docs/image-understanding#object-detection
+type Box2D [4]int
+
+// Validate returns an error if the coordinates are out of range or inverted.
+func (b Box2D) Validate() error {
+	for _, v := range b {
+		if v < 0 || v > 1000 {
+			return fmt.Errorf("coordinate %d out of range [0, 1000]", v)
+		}
+	}
+	if b[0] > b[2] || b[1] > b[3] {
+		return fmt.Errorf("inverted box %v", [4]int(b))
+	}
+	return nil
+}
+
+// Rect returns the box in pixel coordinates of an image with the given bounds.
+func (b Box2D) Rect(bounds image.Rectangle) image.Rectangle {
+	w, h := bounds.Dx(), bounds.Dy()
+	return image.Rect(
+		bounds.Min.X+b[1]*w/1000,
+		bounds.Min.Y+b[0]*h/1000,
+		bounds.Min.X+b[3]*w/1000,
+		bounds.Min.Y+b[2]*h/1000,
+	)
+}
+
+// BoundingBox is an object detected in an image.
+type BoundingBox struct {
+	Label string
+	Box   Box2D
+}
+
+// SegmentationMask is an object segmented in an image.
+type SegmentationMask struct {
+	Label string
+	Box   Box2D
+	// Mask is a probability map covering Box, where each pixel's luminance is the probability in [0, 255]
+	// that the pixel belongs to the object. It is scaled to the box size when drawn.
+	Mask image.Image
+}
+
+// DetectObjects asks the model to detect the objects described by what, e.g. "all the prominent items" or
+// "the cats", in the image img and returns their bounding boxes.
+//
+// p should be a Gemini model, as other models are not trained to output normalized coordinates.
+func DetectObjects(ctx context.Context, p genai.Provider, img genai.Doc, what string, opts ...genai.GenOption) ([]BoundingBox, error) {
+	prompt := "Detect " + what + " in the image. Output a JSON list where each entry contains the 2D bounding box in the key \"box_2d\" as [ymin, xmin, ymax, xmax] normalized to 0-1000 and the text label in the key \"label\". Use descriptive labels."
+	s, err := genVision(ctx, p, img, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBoundingBoxes(s)
+}
+
+// SegmentObjects asks the model for the segmentation masks of the objects described by what, e.g. "the wooden and
+// glass items", in the image img.
+//
+// Segmentation is supported by Gemini 2.5 and later models.
+func SegmentObjects(ctx context.Context, p genai.Provider, img genai.Doc, what string, opts ...genai.GenOption) ([]SegmentationMask, error) {
+	prompt := "Give the segmentation masks for " + what + ". Output a JSON list of segmentation masks where each entry contains the 2D bounding box in the key \"box_2d\" as [ymin, xmin, ymax, xmax] normalized to 0-1000, the segmentation mask in the key \"mask\" and the text label in the key \"label\". Use descriptive labels."
+	s, err := genVision(ctx, p, img, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSegmentationMasks(s)
+}
+
+// ParseBoundingBoxes parses a reply listing bounding boxes in the key "box_2d" and labels in the key
+// "label".
+//
+// Markdown code fences around the JSON are ignored.
+func ParseBoundingBoxes(s string) ([]BoundingBox, error) {
+	items, err := parseVisionItems(s)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]BoundingBox, len(items))
+	for i := range items {
+		if out[i].Box, err = items[i].box(); err != nil {
+			return nil, fmt.Errorf("item #%d: %w", i, err)
+		}
+		out[i].Label = items[i].Label
+	}
+	return out, nil
+}
+
+// ParseSegmentationMasks parses a reply listing segmentation masks, with bounding boxes in the key "box_2d",
+// base64 encoded PNG masks in the key "mask" and labels in the key "label".
+//
+// Markdown code fences around the JSON are ignored.
+func ParseSegmentationMasks(s string) ([]SegmentationMask, error) {
+	items, err := parseVisionItems(s)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SegmentationMask, len(items))
+	for i := range items {
+		if out[i].Box, err = items[i].box(); err != nil {
+			return nil, fmt.Errorf("item #%d: %w", i, err)
+		}
+		out[i].Label = items[i].Label
+		m := items[i].Mask
+		if m == "" {
+			return nil, fmt.Errorf("item #%d: field mask: required", i)
+		}
+		if j := strings.Index(m, ";base64,"); strings.HasPrefix(m, "data:") && j != -1 {
+			m = m[j+len(";base64,"):]
+		}
+		b, err := base64.StdEncoding.DecodeString(m)
+		if err != nil {
+			return nil, fmt.Errorf("item #%d: field mask: %w", i, err)
+		}
+		if out[i].Mask, err = png.Decode(bytes.NewReader(b)); err != nil {
+			return nil, fmt.Errorf("item #%d: field mask: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+// DrawBoundingBoxes draws the outline of each box on dst, cycling through a palette of colors.
+func DrawBoundingBoxes(dst draw.Image, boxes []BoundingBox) {
+	b := dst.Bounds()
+	width := max(1, min(b.Dx(), b.Dy())/200)
+	for i := range boxes {
+		r := boxes[i].Box.Rect(b)
+		c := &image.Uniform{visionPalette[i%len(visionPalette)]}
+		for _, edge := range []image.Rectangle{
+			image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width),
+			image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y),
+			image.Rect(r.Min.X, r.Min.Y, r.Min.X+width, r.Max.Y),
+			image.Rect(r.Max.X-width, r.Min.Y, r.Max.X, r.Max.Y),
+		} {
+			draw.Draw(dst, edge.Intersect(b), c, image.Point{}, draw.Src)
+		}
+	}
+}
+
+// DrawSegmentationMasks overlays each mask on dst with a translucent color, cycling through a palette of
+// colors. Pixels with a probability below 50% are left untouched.
+func DrawSegmentationMasks(dst draw.Image, masks []SegmentationMask) {
+	b := dst.Bounds()
+	for i := range masks {
+		r := masks[i].Box.Rect(b).Intersect(b)
+		if r.Empty() || masks[i].Mask == nil {
+			continue
+		}
+		mb := masks[i].Mask.Bounds()
+		full := masks[i].Box.Rect(b)
+		// Scale the mask to the box with nearest neighbor sampling.
+		alpha := image.NewAlpha(r)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				mx := mb.Min.X + (x-full.Min.X)*mb.Dx()/full.Dx()
+				my := mb.Min.Y + (y-full.Min.Y)*mb.Dy()/full.Dy()
+				if color.GrayModel.Convert(masks[i].Mask.At(mx, my)).(color.Gray).Y >= 128 {
+					alpha.SetAlpha(x, y, color.Alpha{A: 128})
+				}
+			}
+		}
+		draw.DrawMask(dst, r, &image.Uniform{visionPalette[i%len(visionPalette)]}, image.Point{}, alpha, r.Min, draw.Over)
+	}
+}
+
+var visionPalette = []color.RGBA{
+	{R: 0xe6, G: 0x19, B: 0x4b, A: 0xff},
+	{R: 0x3c, G: 0xb4, B: 0x4b, A: 0xff},
+	{R: 0x43, G: 0x63, B: 0xd8, A: 0xff},
+	{R: 0xff, G: 0xe1, B: 0x19, A: 0xff},
+	{R: 0xf5, G: 0x82, B: 0x31, A: 0xff},
+	{R: 0x91, G: 0x1e, B: 0xb4, A: 0xff},
+	{R: 0x42, G: 0xd4, B: 0xf4, A: 0xff},
+	{R: 0xf0, G: 0x32, B: 0xe6, A: 0xff},
+}
+
+// genVision sends the image and the prompt and returns the text reply, asking for JSON.
+func genVision(ctx context.Context, p genai.Provider, img genai.Doc, prompt string, opts []genai.GenOption) (string, error) {
+	msgs := genai.Messages{{Requests: []genai.Request{{Doc: img}, {Text: prompt}}}}
+	i := slices.IndexFunc(opts, func(o genai.GenOption) bool {
+		_, ok := o.(*genai.GenOptionText)
+		return ok
+	})
+	if i == -1 {
+		opts = append(slices.Clip(opts), &genai.GenOptionText{ReplyAsJSON: true})
+	} else {
+		o := *opts[i].(*genai.GenOptionText)
+		o.ReplyAsJSON = true
+		opts = slices.Clone(opts)
+		opts[i] = &o
+	}
+	res, err := p.GenSync(ctx, msgs, opts...)
+	if err != nil {
+		return "", err
+	}
+	return res.String(), nil
+}
+
+// visionItem is one entry of a detection or segmentation reply.
+type visionItem struct {
+	Box2D []float64 `json:"box_2d"`
+	Label string    `json:"label"`
+	Mask  string    `json:"mask"`
+}
+
+func (v *visionItem) box() (Box2D, error) {
+	var b Box2D
+	if len(v.Box2D) != 4 {
+		return b, fmt.Errorf("field box_2d: expected 4 coordinates, got %d", len(v.Box2D))
+	}
+	for i, f := range v.Box2D {
+		b[i] = int(math.Round(f))
+	}
+	if err := b.Validate(); err != nil {
+		return b, fmt.Errorf("field box_2d: %w", err)
+	}
+	return b, nil
+}
+
+func parseVisionItems(s string) ([]visionItem, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "```") {
+		// Strip the fence and its language tag.
+		if i := strings.IndexByte(s, '\n'); i != -1 {
+			s = s[i+1:]
+		}
+		s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+	}
+	if s == "" {
+		return nil, errors.New("empty reply")
+	}
+	var items []visionItem
+	if err := json.Unmarshal([]byte(s), &items); err != nil {
+		return nil, fmt.Errorf("failed to decode reply as a JSON list: %w", err)
+	}
+	return items, nil
+}
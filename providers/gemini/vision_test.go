@@ -0,0 +1,235 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for object detection and segmentation.
+
+package gemini_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/providers/gemini"
+)
+
+func TestParseBoundingBoxes(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   []gemini.BoundingBox
+		errMsg string
+	}{
+		{
+			name: "plain",
+			in:   `[{"box_2d": [10, 20, 500, 600], "label": "cat"}]`,
+			want: []gemini.BoundingBox{{Label: "cat", Box: gemini.Box2D{10, 20, 500, 600}}},
+		},
+		{
+			name: "fence",
+			in:   "```json\n[{\"box_2d\": [0, 0, 1000, 1000.4], \"label\": \"all\"}, {\"box_2d\": [1, 2, 3, 4], \"label\": \"dot\"}]\n```\n",
+			want: []gemini.BoundingBox{{Label: "all", Box: gemini.Box2D{0, 0, 1000, 1000}}, {Label: "dot", Box: gemini.Box2D{1, 2, 3, 4}}},
+		},
+		{name: "empty_list", in: "[]", want: []gemini.BoundingBox{}},
+		{name: "empty", in: " ", errMsg: "empty reply"},
+		{name: "not_json", in: "I see a cat", errMsg: "failed to decode reply as a JSON list: invalid character 'I' looking for beginning of value"},
+		{name: "short", in: `[{"box_2d": [1, 2, 3]}]`, errMsg: "item #0: field box_2d: expected 4 coordinates, got 3"},
+		{name: "range", in: `[{"box_2d": [1, 2, 3, 1001]}]`, errMsg: "item #0: field box_2d: coordinate 1001 out of range [0, 1000]"},
+		{name: "inverted", in: `[{"box_2d": [500, 2, 3, 4]}]`, errMsg: "item #0: field box_2d: inverted box [500 2 3 4]"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := gemini.ParseBoundingBoxes(tc.in)
+			if tc.errMsg != "" {
+				if err == nil || err.Error() != tc.errMsg {
+					t.Fatalf("error mismatch\nwant %q\ngot  %v", tc.errMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("#%d: got %v, want %v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseSegmentationMasks(t *testing.T) {
+	mask := encodeMask(t, 4, 4)
+	t.Run("valid", func(t *testing.T) {
+		for _, prefix := range []string{"", "data:image/png;base64,"} {
+			in := `[{"box_2d": [0, 0, 500, 500], "mask": "` + prefix + mask + `", "label": "square"}]`
+			got, err := gemini.ParseSegmentationMasks(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || got[0].Label != "square" || got[0].Box != (gemini.Box2D{0, 0, 500, 500}) {
+				t.Fatalf("unexpected %+v", got)
+			}
+			if b := got[0].Mask.Bounds(); b.Dx() != 4 || b.Dy() != 4 {
+				t.Fatalf("unexpected mask bounds %v", b)
+			}
+		}
+	})
+	tests := []struct {
+		name   string
+		in     string
+		errMsg string
+	}{
+		{"missing", `[{"box_2d": [0, 0, 1, 1]}]`, "item #0: field mask: required"},
+		{"base64", `[{"box_2d": [0, 0, 1, 1], "mask": "!"}]`, "item #0: field mask: illegal base64 data at input byte 0"},
+		{"png", `[{"box_2d": [0, 0, 1, 1], "mask": "` + base64.StdEncoding.EncodeToString([]byte("GIF89a-not-a-png")) + `"}]`, "item #0: field mask: png: invalid format: not a PNG file"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := gemini.ParseSegmentationMasks(tc.in); err == nil || err.Error() != tc.errMsg {
+				t.Fatalf("error mismatch\nwant %q\ngot  %v", tc.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestBox2D_Rect(t *testing.T) {
+	got := gemini.Box2D{100, 200, 500, 1000}.Rect(image.Rect(10, 10, 210, 110))
+	if want := image.Rect(50, 20, 210, 60); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestDrawBoundingBoxes(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	gemini.DrawBoundingBoxes(img, []gemini.BoundingBox{{Label: "a", Box: gemini.Box2D{100, 100, 500, 500}}})
+	for _, p := range []image.Point{{10, 10}, {49, 49}, {30, 10}, {10, 30}} {
+		if img.RGBAAt(p.X, p.Y).A == 0 {
+			t.Fatalf("expected %v to be drawn", p)
+		}
+	}
+	for _, p := range []image.Point{{5, 5}, {30, 30}, {60, 60}} {
+		if img.RGBAAt(p.X, p.Y).A != 0 {
+			t.Fatalf("expected %v to be untouched", p)
+		}
+	}
+}
+
+func TestDrawSegmentationMasks(t *testing.T) {
+	m, err := png.Decode(bytes.NewReader(mustDecode(t, encodeMask(t, 4, 4))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	gemini.DrawSegmentationMasks(img, []gemini.SegmentationMask{{Label: "a", Box: gemini.Box2D{0, 0, 400, 400}, Mask: m}})
+	// The mask's left half is set, so only x in [0, 20) of the box is colored.
+	for _, tc := range []struct {
+		p     image.Point
+		drawn bool
+	}{
+		{image.Point{5, 5}, true},
+		{image.Point{19, 39}, true},
+		{image.Point{25, 5}, false},
+		{image.Point{50, 50}, false},
+	} {
+		if got := img.RGBAAt(tc.p.X, tc.p.Y).A != 0; got != tc.drawn {
+			t.Fatalf("%v: drawn=%t, want %t", tc.p, got, tc.drawn)
+		}
+	}
+}
+
+func TestDetectObjects(t *testing.T) {
+	const reply = "```json\n[{\"box_2d\": [10, 20, 30, 40], \"label\": \"cat\"}]\n```"
+	var req struct {
+		Contents []struct {
+			Parts []map[string]any `json:"parts"`
+		} `json:"contents"`
+		GenerationConfig struct {
+			ResponseMIMEType string  `json:"responseMimeType"`
+			Temperature      float64 `json:"temperature"`
+		} `json:"generationConfig"`
+	}
+	wrapper := func(http.RoundTripper) http.RoundTripper {
+		return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Error(err)
+			}
+			b, _ := json.Marshal(map[string]any{
+				"candidates": []any{map[string]any{
+					"content":      map[string]any{"role": "model", "parts": []any{map[string]any{"text": reply}}},
+					"finishReason": "STOP",
+				}},
+				"usageMetadata": map[string]any{"promptTokenCount": 1, "candidatesTokenCount": 1, "totalTokenCount": 2},
+				"modelVersion":  "gemini-2.5-flash",
+			})
+			h := http.Header{"Content-Type": {"application/json"}}
+			return &http.Response{StatusCode: 200, Header: h, Body: io.NopCloser(bytes.NewReader(b)), Request: r}, nil
+		})
+	}
+	c, err := gemini.New(t.Context(),
+		genai.ProviderOptionAPIKey("<insert_api_key_here>"),
+		genai.ProviderOptionModel("gemini-2.5-flash"),
+		genai.ProviderOptionModalities{genai.ModalityText},
+		genai.ProviderOptionTransportWrapper(wrapper),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := genai.Doc{Filename: "cat.png", Src: bytes.NewReader(mustDecode(t, encodeMask(t, 2, 2)))}
+	opt := &genai.GenOptionText{Temperature: 0.5}
+	got, err := gemini.DetectObjects(t.Context(), c, img, "the cats", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != (gemini.BoundingBox{Label: "cat", Box: gemini.Box2D{10, 20, 30, 40}}) {
+		t.Fatalf("unexpected %+v", got)
+	}
+	if opt.ReplyAsJSON {
+		t.Fatal("caller's options were modified")
+	}
+	if req.GenerationConfig.ResponseMIMEType != "application/json" || req.GenerationConfig.Temperature != 0.5 {
+		t.Fatalf("unexpected generation config %+v", req.GenerationConfig)
+	}
+	if len(req.Contents) != 1 || len(req.Contents[0].Parts) != 2 {
+		t.Fatalf("unexpected contents %+v", req.Contents)
+	}
+	if s, _ := req.Contents[0].Parts[1]["text"].(string); !strings.HasPrefix(s, "Detect the cats in the image.") {
+		t.Fatalf("unexpected prompt %q", s)
+	}
+}
+
+// encodeMask returns a base64 encoded PNG mask with its left half set.
+func encodeMask(t *testing.T, w, h int) string {
+	m := image.NewGray(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w / 2 {
+			m.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, m); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+func mustDecode(t *testing.T, s string) []byte {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -12,6 +12,7 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"net/http"
@@ -72,11 +73,17 @@ func getGHToken(ctx context.Context) (string, error) {
 //
 // To use multiple models, create multiple clients.
 // Use one of the models from https://github.com/marketplace/models
+//
+// The proxy defaults to the GITHUB_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -95,6 +102,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			preloadedModels = []genai.Model(v)
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -115,7 +130,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "GITHUB_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -127,7 +142,8 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			PreloadedModels: preloadedModels,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header: http.Header{
@@ -264,7 +280,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.InputCachedTokens = pkt.Usage.PromptTokensDetails.CachedTokens
 					u.ReasoningTokens = pkt.Usage.CompletionTokensDetails.ReasoningTokens
 					if len(pkt.Choices) > 0 {
-						u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+						var err error
+						if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+							finalErr = errors.Join(finalErr, err)
+						}
 					}
 				}
 				for _, nt := range pkt.Choices[0].Delta.ToolCalls {
@@ -13,7 +13,6 @@ import (
 	"testing"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/github"
 	"github.com/maruel/genai/scoreboard"
@@ -34,6 +33,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return github.New(t.Context(), opts...)
 }
 
@@ -103,6 +103,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			cl, err2 := github.New(t.Context(), opts...)
 			if err2 != nil {
 				t.Fatal(err2)
@@ -163,7 +164,3 @@ func TestClient(t *testing.T) {
 		internaltest.TestClientProviderErrors(t, f, data)
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
@@ -64,6 +64,15 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.TopK != 0 {
 				unsupported = append(unsupported, "GenOptionText.TopK")
 			}
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			if v.TopLogprobs != 0 {
 				unsupported = append(unsupported, "GenOptionText.TopLogprobs")
 			}
@@ -80,7 +89,11 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				c.ResponseFormat = responseFormat{Type: "json_object"}
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				switch v.Force {
 				case genai.ToolCallAny:
 					c.ToolChoice = "auto"
@@ -89,8 +102,8 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				case genai.ToolCallNone:
 					c.ToolChoice = "none"
 				}
-				c.Tools = make([]Tool, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
 					c.Tools[i].Type = "function"
 					c.Tools[i].Function.Name = t.Name
 					c.Tools[i].Function.Description = t.Description
@@ -474,8 +487,9 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	if len(c.Choices) != 1 {
 		return out, &internal.BadError{Err: fmt.Errorf("server returned an unexpected number of choices, expected 1, got %d", len(c.Choices))}
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-	err := c.Choices[0].Message.To(&out.Message)
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
 	return out, err
 }
 
@@ -483,21 +497,20 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 type FinishReason string
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishContentFilter:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -592,6 +605,16 @@ func (m *CatalogModel) Context() int64 {
 	return m.Limits.MaxInputTokens
 }
 
+// MaxOutputTokens implements genai.ModelOutputTokens.
+func (m *CatalogModel) MaxOutputTokens() int64 {
+	return m.Limits.MaxOutputTokens
+}
+
+// GetModalities implements genai.ModelModalities.
+func (m *CatalogModel) GetModalities() (in, out genai.Modalities) {
+	return base.ParseModalities(m.SupportedInputModalities), base.ParseModalities(m.SupportedOutputModalities)
+}
+
 // ErrorResponse is the provider-specific error response.
 type ErrorResponse struct {
 	ErrorVal struct {
@@ -12,13 +12,13 @@ import (
 	"iter"
 	"net/http"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"testing"
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/adapters"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/groq"
 	"github.com/maruel/genai/scoreboard"
@@ -32,6 +32,7 @@ func getClientInner(t *testing.T, opts []genai.ProviderOption, fn func(http.Roun
 	if fn != nil {
 		opts = append(opts, genai.ProviderOptionTransportWrapper(fn))
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return groq.New(t.Context(), opts...)
 }
 
@@ -101,6 +102,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append(opts, genai.ProviderOptionTransportWrapper(fn))
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			cl, err := groq.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -210,6 +212,59 @@ func (h *handleGroqReasoning) Unwrap() genai.Provider {
 	return h.Provider
 }
 
-func init() {
-	internal.BeLenient = false
+func TestModelSelector(t *testing.T) {
+	models := genai.ProviderOptionPreloadedModels{&groq.Model{ID: "openai/gpt-oss-20b"}, &groq.Model{ID: "custom"}}
+	selector := func(ctx context.Context, preference genai.ProviderOptionModel, models []genai.Model) (string, error) {
+		if preference != genai.ModelCheap {
+			t.Errorf("preference = %q", preference)
+		}
+		return models[len(models)-1].GetID(), nil
+	}
+	c, err := groq.New(t.Context(), genai.ProviderOptionAPIKey("key"), genai.ModelCheap, models, genai.ProviderOptionModelSelector(selector))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.ModelID(); got != "custom" {
+		t.Errorf("ModelID() = %q", got)
+	}
+	if got := c.ModelSelection(); got.Reason != "selected by ProviderOptionModelSelector" || got.Model != "custom" {
+		t.Errorf("ModelSelection() = %+v", got)
+	}
+	empty := func(ctx context.Context, preference genai.ProviderOptionModel, models []genai.Model) (string, error) {
+		return "", nil
+	}
+	if _, err = groq.New(t.Context(), genai.ProviderOptionAPIKey("key"), genai.ModelCheap, models, genai.ProviderOptionModelSelector(empty)); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestModelPin(t *testing.T) {
+	pin := genai.ProviderOptionModelPin(filepath.Join(t.TempDir(), "models.json"))
+	models := genai.ProviderOptionPreloadedModels{&groq.Model{ID: "openai/gpt-oss-20b"}}
+	c, err := groq.New(t.Context(), genai.ProviderOptionAPIKey("key"), genai.ModelCheap, models, pin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.ModelSelection(); got.Pinned || got.Model != "openai/gpt-oss-20b" || got.Preference != genai.ModelCheap {
+		t.Errorf("ModelSelection() = %+v", got)
+	}
+	b, err := os.ReadFile(string(pin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(strings.Fields(string(b)), ""); got != `{"groq":{"CHEAP":"openai/gpt-oss-20b"}}` {
+		t.Errorf("unexpected pin file: %s", b)
+	}
+
+	// The heuristics would now select another model but the pinned one is kept.
+	models = genai.ProviderOptionPreloadedModels{&groq.Model{ID: "openai/gpt-oss-120b"}, &groq.Model{ID: "openai/gpt-oss-safeguard-20b"}}
+	if c, err = groq.New(t.Context(), genai.ProviderOptionAPIKey("key"), genai.ModelCheap, models, pin); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.ModelSelection(); !got.Pinned || got.Model != "openai/gpt-oss-20b" {
+		t.Errorf("ModelSelection() = %+v", got)
+	}
+	if got := c.ModelID(); got != "openai/gpt-oss-20b" {
+		t.Errorf("ModelID() = %q", got)
+	}
 }
@@ -15,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
@@ -58,6 +59,7 @@ type ChatRequest struct {
 	Model             string          `json:"model"`
 	ParallelToolCalls bool            `json:"parallel_tool_calls,omitzero"`
 	PresencePenalty   float64         `json:"presence_penalty,omitzero"` // [-2.0, 2.0]
+	ReasoningEffort   string          `json:"reasoning_effort,omitzero"` // "none", "default", "low", "medium", "high"
 	ReasoningFormat   ReasoningFormat `json:"reasoning_format,omitzero"`
 	ResponseFormat    struct {
 		Type       string           `json:"type,omitzero"` // "json_object", "json_schema"
@@ -122,15 +124,30 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			}
 			sp = v.SystemPrompt
 		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
 			if err := c.initOptionsTools(v); err != nil {
 				errs = append(errs, err)
 			}
 		case *genai.GenOptionWeb:
 			if v.Search {
 				// https://console.groq.com/docs/browser-search
-				// TODO: Country and domains
 				c.SearchSettings.IncludeImages = true
 				c.Tools = append(c.Tools, Tool{Type: "browser_search"})
+				if o := v.SearchOptions; o != nil {
+					c.SearchSettings.IncludeDomains = o.AllowedDomains
+					c.SearchSettings.ExcludeDomains = o.BlockedDomains
+					if o.MaxSearches != 0 {
+						unsupported = append(unsupported, "GenOptionWeb.SearchOptions.MaxSearches")
+					}
+					if o.ContextSize != "" {
+						unsupported = append(unsupported, "GenOptionWeb.SearchOptions.ContextSize")
+					}
+					if !o.UserLocation.IsZero() {
+						unsupported = append(unsupported, "GenOptionWeb.SearchOptions.UserLocation")
+					}
+				}
 			}
 			// Fetch (visit_website) is only available on compound models, not chat completions.
 			// https://console.groq.com/docs/agentic-tooling
@@ -190,6 +207,19 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) ([]string, error)
 	if v.TopK != 0 {
 		unsupported = append(unsupported, "GenOptionText.TopK")
 	}
+	if v.N > 1 {
+		unsupported = append(unsupported, "GenOptionText.N")
+	}
+	if v.Grammar != "" {
+		unsupported = append(unsupported, "GenOptionText.Grammar")
+	}
+	switch v.ReasoningEffort {
+	case "":
+	case genai.ReasoningEffortOff:
+		c.ReasoningEffort = "none"
+	default:
+		c.ReasoningEffort = string(v.ReasoningEffort)
+	}
 	if v.TopLogprobs != 0 {
 		unsupported = append(unsupported, "GenOptionText.TopLogprobs")
 	}
@@ -208,7 +238,8 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) ([]string, error)
 }
 
 func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) error {
-	if len(v.Tools) != 0 {
+	tools := v.AllowedTools()
+	if len(tools) != 0 {
 		switch v.Force {
 		case genai.ToolCallAny:
 			c.ToolChoice = "auto"
@@ -218,8 +249,8 @@ func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) error {
 			c.ToolChoice = "none"
 		}
 		// Documentation states max is 128 tools.
-		c.Tools = make([]Tool, len(v.Tools))
-		for i, t := range v.Tools {
+		c.Tools = make([]Tool, len(tools))
+		for i, t := range tools {
 			c.Tools[i].Type = "function"
 			c.Tools[i].Function.Name = t.Name
 			c.Tools[i].Function.Description = t.Description
@@ -618,8 +649,9 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	if len(c.Choices) != 1 {
 		return out, &internal.BadError{Err: fmt.Errorf("server returned an unexpected number of choices, expected 1, got %d", len(c.Choices))}
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-	err := c.Choices[0].Message.To(&out.Message)
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
 	return out, err
 }
 
@@ -627,21 +659,20 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 type FinishReason string
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishContentFilter:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -746,6 +777,32 @@ func (m *Model) Context() int64 {
 	return max(m.ContextLength, m.ContextWindow)
 }
 
+// MaxOutputTokens implements genai.ModelOutputTokens.
+func (m *Model) MaxOutputTokens() int64 {
+	if m.MaxCompletionTokens != 0 {
+		return m.MaxCompletionTokens
+	}
+	return m.MaxOutputLength
+}
+
+// GetModalities implements genai.ModelModalities.
+func (m *Model) GetModalities() (in, out genai.Modalities) {
+	return base.ParseModalities(m.InputModalities), base.ParseModalities(m.OutputModalities)
+}
+
+// GetCreated implements genai.ModelCreated.
+func (m *Model) GetCreated() time.Time {
+	if m.Created.IsZero() {
+		return time.Time{}
+	}
+	return m.Created.AsTime()
+}
+
+// GetDeprecated implements genai.ModelDeprecated.
+func (m *Model) GetDeprecated() bool {
+	return !m.Active
+}
+
 // ModelsResponse represents the response structure for Groq models listing.
 type ModelsResponse struct {
 	Object string  `json:"object"` // list
@@ -52,13 +52,34 @@ func Scoreboard() scoreboard.Score {
 	return s
 }
 
+// ProviderOptionInferenceProvider selects the inference provider backing the requests, e.g. "together",
+// "fireworks-ai", "hf-inference", or the policies "fastest" and "cheapest". The router selects one
+// automatically when unspecified.
+//
+// It is equivalent to use the "model:provider" syntax with genai.ProviderOptionModel.
+//
+// See https://huggingface.co/docs/inference-providers/index for the list of providers. Model availability
+// varies per provider.
+type ProviderOptionInferenceProvider string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionInferenceProvider) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionInferenceProvider cannot be empty")
+	}
+	if strings.Contains(string(p), ":") {
+		return fmt.Errorf("invalid ProviderOptionInferenceProvider %q", string(p))
+	}
+	return nil
+}
+
 // Client implements genai.Provider.
 type Client struct {
 	base.NotImplemented
 	impl base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]
 }
 
-// TODO: Investigate https://huggingface.co/blog/inference-providers and https://huggingface.co/docs/inference-endpoints/
+// TODO: Investigate https://huggingface.co/docs/inference-endpoints/
 
 // New creates a new client to talk to the HuggingFace serverless inference API.
 //
@@ -72,11 +93,26 @@ type Client struct {
 //
 // ProviderOptionTransportWrapper can be used to add the HTTP header "X-HF-Bill-To" via roundtrippers.Header. See
 // https://huggingface.co/docs/inference-providers/pricing#organization-billing
+//
+// The proxy defaults to the HUGGINGFACE_PROXY environment variable, see genai.ProviderOptionProxyURL.
+//
+// # Inference providers
+//
+// Requests are sent to the HuggingFace router, which forwards them to an inference provider. Use
+// ProviderOptionInferenceProvider to select it. The provider that served the request is reported in
+// genai.Usage.InferenceProvider.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	var inferenceProvider string
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -93,8 +129,22 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case ProviderOptionInferenceProvider:
+			inferenceProvider = string(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -125,7 +175,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		// https://huggingface.co/docs/inference-providers/index
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is implemented (send PR to add support)", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "HUGGINGFACE_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -134,10 +184,13 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			GenSyncURL:      "https://router.huggingface.co/v1/chat/completions",
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProcessHeaders:  processHeaders,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
@@ -151,7 +204,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		switch model {
 		case "":
 		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
-			if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 				return nil, err
 			}
 			c.impl.OutputModalities = mod
@@ -159,20 +212,28 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			c.impl.Model = model
 			c.impl.OutputModalities = mod
 		}
+		if inferenceProvider != "" && c.impl.Model != "" {
+			if strings.Contains(c.impl.Model, ":") {
+				return nil, fmt.Errorf("model %q already specifies an inference provider", c.impl.Model)
+			}
+			c.impl.Model += ":" + inferenceProvider
+		}
 	}
 	return c, err
 }
 
 // selectBestTextModel selects the most recent model based on the preference (cheap, good, or SOTA).
 //
-// We may want to make this function overridable in the future by the client since this is going to break one
-// day or another.
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
 func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
 	// Warning: listing models from Huggingface takes a while.
 	mdls, err := c.ListModels(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	cheap := preference == string(genai.ModelCheap)
 	good := preference == string(genai.ModelGood) || preference == ""
 	selectedModel := ""
@@ -242,6 +303,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -262,7 +328,9 @@ func (c *Client) HTTPClient() *http.Client {
 
 // GenSync implements genai.Provider.
 func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
-	return c.impl.GenSync(ctx, msgs, opts...)
+	res, err := c.impl.GenSync(ctx, msgs, opts...)
+	c.setInferenceProvider(&res)
+	return res, err
 }
 
 // GenSyncRaw provides access to the raw API.
@@ -272,7 +340,19 @@ func (c *Client) GenSyncRaw(ctx context.Context, in *ChatRequest, out *ChatRespo
 
 // GenStream implements genai.Provider.
 func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
-	return c.impl.GenStream(ctx, msgs, opts...)
+	fragments, finish := c.impl.GenStream(ctx, msgs, opts...)
+	return fragments, func() (genai.Result, error) {
+		res, err := finish()
+		c.setInferenceProvider(&res)
+		return res, err
+	}
+}
+
+// setInferenceProvider sets the provider that served the last request, as reported by the router.
+func (c *Client) setInferenceProvider(res *genai.Result) {
+	if h := c.impl.LastResponseHeaders(); h != nil {
+		res.Usage.InferenceProvider = h.Get("X-Inference-Provider")
+	}
 }
 
 // GenStreamRaw provides access to the raw API.
@@ -315,7 +395,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					continue
 				}
 				if pkt.Choices[0].FinishReason != "" {
-					u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				if !pkt.Choices[0].Logprobs.IsZero() {
 					l = append(l, pkt.Choices[0].Logprobs.To()...)
@@ -409,4 +492,7 @@ func processHeaders(h http.Header) []genai.RateLimit {
 	return limits
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+)
@@ -7,6 +7,8 @@
 package huggingface_test
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"slices"
 	"strings"
@@ -14,7 +16,6 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/adapters"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/huggingface"
 	"github.com/maruel/genai/scoreboard"
@@ -36,6 +37,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return huggingface.New(t.Context(), opts...)
 }
 
@@ -99,6 +101,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := huggingface.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -191,6 +194,48 @@ func getAPIKeyTest(t testing.TB) string {
 	return apiKey
 }
 
-func init() {
-	internal.BeLenient = false
+func TestInferenceProvider(t *testing.T) {
+	var model string
+	fake := internaltest.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		var in struct {
+			Model string `json:"model"`
+		}
+		b, _ := io.ReadAll(req.Body)
+		if err := json.Unmarshal(b, &in); err != nil {
+			t.Error(err)
+		}
+		model = in.Model
+		body := `{"object":"chat.completion","id":"1","created":1765841401,"model":"meta-llama/llama-3.1-8b-instruct","choices":[{"finish_reason":"stop","index":0,"message":{"role":"assistant","content":"Hi"}}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}, "X-Inference-Provider": {"together"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	c, err := huggingface.New(t.Context(),
+		genai.ProviderOptionAPIKey("key"),
+		genai.ProviderOptionModel("meta-llama/Llama-3.1-8B-Instruct"),
+		huggingface.ProviderOptionInferenceProvider("together"),
+		genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model != "meta-llama/Llama-3.1-8B-Instruct:together" {
+		t.Fatalf("unexpected model %q", model)
+	}
+	if res.Usage.InferenceProvider != "together" {
+		t.Fatalf("unexpected inference provider %q", res.Usage.InferenceProvider)
+	}
+	_, err = huggingface.New(t.Context(),
+		genai.ProviderOptionAPIKey("key"),
+		genai.ProviderOptionModel("meta-llama/Llama-3.1-8B-Instruct:novita"),
+		huggingface.ProviderOptionInferenceProvider("together"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
 }
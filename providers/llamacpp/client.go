@@ -16,6 +16,7 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"iter"
@@ -25,9 +26,11 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/maruel/roundtrippers"
+	"golang.org/x/sync/semaphore"
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
@@ -50,16 +53,91 @@ func Scoreboard() scoreboard.Score {
 	return s
 }
 
+// ProviderOptionParallel limits the number of generation requests sent concurrently to llama-server.
+//
+// Set it to the server's --parallel value so each request gets a slot as soon as it is sent, and the others
+// wait client side, honoring their context, instead of being deferred by the server. 0 means no limit.
+type ProviderOptionParallel int64
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionParallel) Validate() error {
+	if p < 0 {
+		return errors.New("ProviderOptionParallel must be non-negative")
+	}
+	return nil
+}
+
 // GenOption is the llama.cpp-specific options.
+//
+// The sampler options are documented at
+// https://github.com/ggml-org/llama.cpp/blob/master/tools/server/README.md. Zero values use the server's
+// defaults.
 type GenOption struct {
 	// ReasoningFormat sets the reasoning format for the model.
 	ReasoningFormat ReasoningFormat
 	// Thinking enables thinking mode via chat_template_kwargs.
 	Thinking bool
+
+	// MinP is the minimum probability of a token relative to the most likely one, between 0 and 1.
+	MinP float64
+	// TypicalP enables locally typical sampling, between 0 and 1. 1 disables it.
+	TypicalP float64
+	// Mirostat selects the Mirostat sampling version: 1 or 2. It replaces the Top K, Top P and Min P samplers.
+	Mirostat int32
+	// MirostatTau is the Mirostat target entropy.
+	MirostatTau float64
+	// MirostatEta is the Mirostat learning rate.
+	MirostatEta float64
+	// RepeatPenalty penalizes the repetition of tokens. 1 disables it.
+	RepeatPenalty float64
+	// RepeatLastN is the number of last tokens considered by RepeatPenalty. -1 means the context size.
+	RepeatLastN int64
+	// DryMultiplier enables the DRY (Don't Repeat Yourself) repetition penalty when non-zero.
+	DryMultiplier float64
+	// DryBase is the DRY penalty base.
+	DryBase float64
+	// DryAllowedLength is the length of a repeated sequence that isn't penalized by DRY.
+	DryAllowedLength int64
+	// DryPenaltyLastN is the number of last tokens scanned by DRY. -1 means the context size.
+	DryPenaltyLastN int64
+	// DrySequenceBreakers are the sequences that reset DRY's repetition matching.
+	DrySequenceBreakers []string
 }
 
 // Validate implements genai.Validatable.
 func (o *GenOption) Validate() error {
+	if o.MinP < 0 || o.MinP > 1 {
+		return errors.New("field MinP: must be [0, 1]")
+	}
+	if o.TypicalP < 0 || o.TypicalP > 1 {
+		return errors.New("field TypicalP: must be [0, 1]")
+	}
+	if o.Mirostat < 0 || o.Mirostat > 2 {
+		return fmt.Errorf("field Mirostat: invalid value %d", o.Mirostat)
+	}
+	if o.Mirostat == 0 && o.MirostatTau != 0 {
+		return errors.New("field MirostatTau: requires Mirostat")
+	}
+	if o.Mirostat == 0 && o.MirostatEta != 0 {
+		return errors.New("field MirostatEta: requires Mirostat")
+	}
+	for _, f := range []struct {
+		name string
+		v    float64
+	}{{"MirostatTau", o.MirostatTau}, {"MirostatEta", o.MirostatEta}, {"RepeatPenalty", o.RepeatPenalty}, {"DryMultiplier", o.DryMultiplier}, {"DryBase", o.DryBase}} {
+		if f.v < 0 {
+			return fmt.Errorf("field %s: must be non-negative", f.name)
+		}
+	}
+	if o.RepeatLastN < -1 {
+		return errors.New("field RepeatLastN: must be -1 or non-negative")
+	}
+	if o.DryAllowedLength < 0 {
+		return errors.New("field DryAllowedLength: must be non-negative")
+	}
+	if o.DryPenaltyLastN < -1 {
+		return errors.New("field DryPenaltyLastN: must be -1 or non-negative")
+	}
 	return nil
 }
 
@@ -142,6 +220,8 @@ type Metrics struct {
 //
 
 // Client implements genai.Provider.
+//
+// It is safe for concurrent use. Use ProviderOptionParallel to match the number of slots of the server.
 type Client struct {
 	base.NotImplemented
 	impl           base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]
@@ -151,6 +231,44 @@ type Client struct {
 	encoding       *PromptEncoding
 }
 
+// slotLimiter limits the number of concurrent POST requests, which are generation requests. The slot is
+// released when the response body is closed, so streams hold their slot until they are done.
+type slotLimiter struct {
+	sem       *semaphore.Weighted
+	transport http.RoundTripper
+}
+
+func (s *slotLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost {
+		return s.transport.RoundTrip(req)
+	}
+	if err := s.sem.Acquire(req.Context(), 1); err != nil {
+		return nil, err
+	}
+	resp, err := s.transport.RoundTrip(req)
+	if err != nil {
+		s.sem.Release(1)
+		return resp, err
+	}
+	resp.Body = &slotBody{ReadCloser: resp.Body, release: sync.OnceFunc(func() { s.sem.Release(1) })}
+	return resp, nil
+}
+
+func (s *slotLimiter) Unwrap() http.RoundTripper {
+	return s.transport
+}
+
+type slotBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (s *slotBody) Close() error {
+	err := s.ReadCloser.Close()
+	s.release()
+	return err
+}
+
 // New creates a new client to talk to a llama-server instance.
 //
 // ProviderOptionRemote defaults to "http://localhost:8080".
@@ -159,11 +277,18 @@ type Client struct {
 //
 // Automatic model selection via ModelCheap, ModelGood, ModelSOTA is not supported. It will ask llama-server
 // to determine which model is already loaded.
+//
+// The proxy defaults to the LLAMACPP_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var baseURL, model string
+	var parallel int64
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -182,6 +307,16 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			preloadedModels = []genai.Model(v)
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case ProviderOptionParallel:
+			parallel = int64(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -193,10 +328,13 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "LLAMACPP_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
+	if parallel != 0 {
+		t = &slotLimiter{sem: semaphore.NewWeighted(parallel), transport: t}
+	}
 	c := &Client{
 		impl: base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]{
 			GenSyncURL:      baseURL + "/chat/completions",
@@ -204,7 +342,8 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			PreloadedModels: preloadedModels,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				ModelOptional: true,
-				Lenient:       internal.BeLenient,
+				Lenient:       lenient,
+				Log:           logger,
 				Client: http.Client{
 					Transport: &roundtrippers.RequestID{Transport: t},
 				},
@@ -334,7 +473,8 @@ func (c *Client) Completion(ctx context.Context, msgs genai.Messages, opts ...ge
 	if err := c.CompletionRaw(ctx, &rpcin, &rpcout); err != nil {
 		return genai.Result{}, fmt.Errorf("failed to get llama server response: %w", err)
 	}
-	return rpcout.ToResult()
+	res, err := rpcout.ToResult()
+	return res, c.impl.StrictErr(err)
 }
 
 // CompletionRaw provides raw access to the completion API.
@@ -380,7 +520,7 @@ func (c *Client) CompletionStream(ctx context.Context, msgs genai.Messages, opts
 		}
 		var err error
 		res.Usage, res.Logprobs, err = finish2()
-		if finalErr == nil {
+		if err = c.impl.StrictErr(err); finalErr == nil {
 			finalErr = err
 		}
 	}
@@ -454,6 +594,48 @@ func (c *Client) GetHealthRaw(ctx context.Context) (HealthResponse, error) {
 	return msg, nil
 }
 
+// GetSlots returns the state of the server's processing slots.
+//
+// It requires llama-server to not be started with --no-slots.
+func (c *Client) GetSlots(ctx context.Context) ([]Slot, error) {
+	var out []Slot
+	if err := c.impl.DoRequest(ctx, "GET", c.baseURL+"/slots", nil, &out); err != nil {
+		return out, fmt.Errorf("failed to get slots response: %w", err)
+	}
+	return out, nil
+}
+
+// IdleSlots returns the number of slots that are not processing a request.
+//
+// It can be used to implement backpressure before sending a request.
+func (c *Client) IdleSlots(ctx context.Context) (int, error) {
+	slots, err := c.GetSlots(ctx)
+	n := 0
+	for i := range slots {
+		if !slots[i].IsProcessing {
+			n++
+		}
+	}
+	return n, err
+}
+
+// Rerank implements genai.ProviderRerank.
+//
+// It requires llama-server to be started with a reranking model and --reranking.
+func (c *Client) Rerank(ctx context.Context, query string, documents []string, topN int) ([]genai.RerankResult, error) {
+	in := RerankRequest{Model: c.impl.Model, Query: query, Documents: documents, TopN: int64(topN)}
+	var out RerankResponse
+	if err := c.RerankRaw(ctx, &in, &out); err != nil {
+		return nil, err
+	}
+	return out.To(len(documents))
+}
+
+// RerankRaw provides access to the raw rerank API.
+func (c *Client) RerankRaw(ctx context.Context, in *RerankRequest, out *RerankResponse) error {
+	return c.impl.DoRequest(ctx, "POST", c.baseURL+"/v1/rerank", in, out)
+}
+
 // Ping verifies the server is healthy and available.
 func (c *Client) Ping(ctx context.Context) error {
 	status, err := c.GetHealth(ctx)
@@ -526,9 +708,6 @@ func (c *Client) GetMetrics(ctx context.Context, m *Metrics) error {
 		case "llamacpp:n_past_max":
 		case "llamacpp:n_tokens_max":
 		default:
-			if !internal.BeLenient {
-				panic(fmt.Sprintf("unknown metric %q", l))
-			}
 			return fmt.Errorf("unknown metric %q", l)
 		}
 	}
@@ -603,7 +782,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (fragments iter.Seq
 				}
 				l = append(l, pkt.Choices[0].Logprobs.To()...)
 				if pkt.Choices[0].FinishReason != "" {
-					u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				switch role := pkt.Choices[0].Delta.Role; role {
 				case "assistant", "":
@@ -668,7 +850,11 @@ func ProcessCompletionStream(chunks iter.Seq[CompletionStreamChunkResponse]) (fr
 					u.OutputTokens = pkt.Timings.PredictedN
 				}
 				if pkt.StopType != "" {
-					u.FinishReason = pkt.StopType.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.StopType.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
+					u.StopSequence = pkt.StoppingWord
 				}
 				if !yield(genai.Reply{Text: pkt.Content}) {
 					break
@@ -682,4 +868,7 @@ func ProcessCompletionStream(chunks iter.Seq[CompletionStreamChunkResponse]) (fr
 func yieldNothing[T any](yield func(T) bool) {
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider       = &Client{}
+	_ genai.ProviderRerank = &Client{}
+)
@@ -10,6 +10,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"io"
 	"net"
@@ -17,14 +18,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/maruel/roundtrippers"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/llamacpp"
 	"github.com/maruel/genai/providers/llamacpp/llamacppsrv"
@@ -53,7 +56,7 @@ func TestClient(t *testing.T) {
 				Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
 				Transport: h,
 			})
-		}), genai.ProviderOptionRemote(s.lazyStart(t)))
+		}), genai.ProviderOptionRemote(s.lazyStart(t)), genai.ProviderOptionStrict(true))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -83,6 +86,7 @@ func TestClient(t *testing.T) {
 					})
 				})}, opts...)
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c2, err2 := llamacpp.New(ctx, opts...)
 			if err2 != nil {
 				t.Fatal(err2)
@@ -116,7 +120,7 @@ func TestClient(t *testing.T) {
 					Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
 					Transport: h,
 				})
-			}), genai.ProviderOptionRemote(s.lazyStart(t)), genai.ModelCheap)
+			}), genai.ProviderOptionRemote(s.lazyStart(t)), genai.ModelCheap, genai.ProviderOptionStrict(true))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -131,7 +135,7 @@ func TestClient(t *testing.T) {
 				Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
 				Transport: h,
 			})
-		}), genai.ProviderOptionRemote(s.lazyStart(t)))
+		}), genai.ProviderOptionRemote(s.lazyStart(t)), genai.ProviderOptionStrict(true))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -152,7 +156,7 @@ func TestClient(t *testing.T) {
 				Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
 				Transport: h,
 			})
-		}), genai.ProviderOptionRemote(s.lazyStart(t)))
+		}), genai.ProviderOptionRemote(s.lazyStart(t)), genai.ProviderOptionStrict(true))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -329,6 +333,122 @@ func TestGenOption(t *testing.T) {
 			t.Errorf("Messages[1].Role = %q, want user", req.Messages[1].Role)
 		}
 	})
+	t.Run("sampler", func(t *testing.T) {
+		var req llamacpp.ChatRequest
+		opts := &llamacpp.GenOption{MinP: 0.05, Mirostat: 2, MirostatTau: 5, RepeatPenalty: 1.1, RepeatLastN: -1, DryMultiplier: 0.8}
+		if err := req.Init(msgs, "model", opts); err != nil {
+			t.Fatal(err)
+		}
+		if req.MinP != 0.05 || req.Mirostat != 2 || req.MirostatTau != 5 || req.RepeatPenalty != 1.1 || req.RepeatLastN != -1 || req.DryMultiplier != 0.8 {
+			t.Errorf("unexpected sampler settings: %+v", req)
+		}
+		if err := (&llamacpp.GenOption{MirostatEta: 0.1}).Validate(); err == nil || err.Error() != "field MirostatEta: requires Mirostat" {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("grammar", func(t *testing.T) {
+		var req llamacpp.ChatRequest
+		const grammar = `root ::= "yes" | "no"`
+		if err := req.Init(msgs, "model", &genai.GenOptionText{Grammar: grammar}); err != nil {
+			t.Fatal(err)
+		}
+		if req.Grammar != grammar {
+			t.Errorf("Grammar = %q, want %q", req.Grammar, grammar)
+		}
+	})
+}
+
+func TestProviderOptionParallel(t *testing.T) {
+	if err := llamacpp.ProviderOptionParallel(-1).Validate(); err == nil {
+		t.Fatal("expected error")
+	}
+	var current, peak atomic.Int32
+	fake := internaltest.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if n := current.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		return &http.Response{StatusCode: 200, Body: &countedBody{Reader: strings.NewReader("{}"), n: &current}}, nil
+	})
+	c, err := llamacpp.New(t.Context(),
+		genai.ProviderOptionRemote("http://localhost:1"),
+		llamacpp.ProviderOptionParallel(2),
+		genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bodies []io.Closer
+	for range 2 {
+		resp, err := c.HTTPClient().Post("http://localhost:1/completions", "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodies = append(bodies, resp.Body)
+	}
+	// The third request must wait for a slot.
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://localhost:1/completions", strings.NewReader("{}"))
+	if _, err := c.HTTPClient().Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	for _, b := range bodies {
+		_ = b.Close()
+	}
+	resp, err := c.HTTPClient().Post("http://localhost:1/completions", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if p := peak.Load(); p != 2 {
+		t.Fatalf("peak concurrency %d, want 2", p)
+	}
+}
+
+type countedBody struct {
+	*strings.Reader
+	n *atomic.Int32
+}
+
+func (c *countedBody) Close() error {
+	c.n.Add(-1)
+	return nil
+}
+
+func TestRerank(t *testing.T) {
+	var got map[string]any
+	fake := internaltest.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != "http://localhost:1/v1/rerank" {
+			t.Errorf("unexpected URL %s", req.URL)
+		}
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		body := `{"model":"bge-reranker","object":"list","usage":{"prompt_tokens":10,"total_tokens":10},"results":[{"index":1,"relevance_score":0.8},{"index":0,"relevance_score":-2.5}]}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	// llama-server serves the model it was started with, so none is specified.
+	c, err := llamacpp.New(t.Context(),
+		genai.ProviderOptionRemote("http://localhost:1"),
+		genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Rerank(t.Context(), "capital", []string{"a", "b"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []genai.RerankResult{{Index: 1, Score: 0.8}, {Index: 0, Score: -2.5}}; !slices.Equal(want, res) {
+		t.Fatalf("want %v, got %v", want, res)
+	}
+	if _, ok := got["model"]; ok || got["query"] != "capital" {
+		t.Fatalf("unexpected request %#v", got)
+	}
 }
 
 func TestMessage(t *testing.T) {
@@ -369,7 +489,3 @@ func TestMessage(t *testing.T) {
 		}
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
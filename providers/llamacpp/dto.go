@@ -120,7 +120,14 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			c.Temperature = v.Temperature
 			c.TopP = v.TopP
 			c.TopK = v.TopK
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			c.Stop = v.Stop
+			c.Grammar = v.Grammar
 			if v.ReplyAsJSON {
 				c.ResponseFormat.Type = "json_object"
 			}
@@ -134,8 +141,12 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				}
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
-				c.Tools = make([]Tool, len(v.Tools))
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
+				c.Tools = make([]Tool, len(tools))
 				c.ParallelToolCalls = true
 				switch v.Force {
 				case genai.ToolCallAny:
@@ -147,9 +158,9 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				}
 				for i := range c.Tools {
 					c.Tools[i].Type = "function"
-					c.Tools[i].Function.Name = v.Tools[i].Name
-					c.Tools[i].Function.Description = v.Tools[i].Description
-					s, err := v.Tools[i].GetInputSchema()
+					c.Tools[i].Function.Name = tools[i].Name
+					c.Tools[i].Function.Description = tools[i].Description
+					s, err := tools[i].GetInputSchema()
 					if err != nil {
 						errs = append(errs, err)
 					}
@@ -170,6 +181,18 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			} else {
 				c.ChatTemplateKWArgs["enable_thinking"] = json.RawMessage("false")
 			}
+			c.MinP = v.MinP
+			c.TypicalP = v.TypicalP
+			c.Mirostat = v.Mirostat
+			c.MirostatTau = v.MirostatTau
+			c.MirostatEta = v.MirostatEta
+			c.RepeatPenalty = v.RepeatPenalty
+			c.RepeatLastN = v.RepeatLastN
+			c.DryMultiplier = v.DryMultiplier
+			c.DryBase = v.DryBase
+			c.DryAllowedLength = v.DryAllowedLength
+			c.DryPenaltyLastN = v.DryPenaltyLastN
+			c.DrySequenceBreakers = v.DrySequenceBreakers
 		default:
 			unsupported = append(unsupported, internal.TypeName(opt))
 		}
@@ -243,14 +266,15 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 			TotalTokens:       c.Usage.TotalTokens,
 		},
 	}
+	var err error
 	if len(c.Choices) == 1 {
-		out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-		if err := c.Choices[0].Message.To(&out.Message); err != nil {
-			return out, err
+		out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+		if err2 := c.Choices[0].Message.To(&out.Message); err2 != nil {
+			return out, err2
 		}
 		out.Logprobs = c.Choices[0].Logprobs.To()
 	}
-	return out, nil
+	return out, err
 }
 
 // Logprobs contains per-token log-probability information.
@@ -321,19 +345,18 @@ const (
 )
 
 // ToFinishReason converts to the genai finish reason type.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishedStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishedLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishedToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -378,6 +401,55 @@ type HealthResponse struct {
 	SlotsProcessing int64  `json:"slots_processing"`
 }
 
+// RerankRequest is documented at
+// https://github.com/ggml-org/llama.cpp/blob/master/tools/server/README.md#post-reranking-rerank-documents-according-to-a-given-query
+type RerankRequest struct {
+	Model     string   `json:"model,omitzero"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int64    `json:"top_n,omitzero"`
+}
+
+// RerankResponse is documented at
+// https://github.com/ggml-org/llama.cpp/blob/master/tools/server/README.md#post-reranking-rerank-documents-according-to-a-given-query
+type RerankResponse struct {
+	Model  string `json:"model"`
+	Object string `json:"object"` // "list"
+	Usage  struct {
+		PromptTokens int64 `json:"prompt_tokens"`
+		TotalTokens  int64 `json:"total_tokens"`
+	} `json:"usage"`
+	Results []struct {
+		Index          int64   `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// To converts to the genai equivalent. n is the number of documents sent.
+func (r *RerankResponse) To(n int) ([]genai.RerankResult, error) {
+	out := make([]genai.RerankResult, len(r.Results))
+	for i := range r.Results {
+		if r.Results[i].Index < 0 || r.Results[i].Index >= int64(n) {
+			return nil, &internal.BadError{Err: fmt.Errorf("result %d: invalid index %d for %d documents", i, r.Results[i].Index, n)}
+		}
+		out[i] = genai.RerankResult{Index: int(r.Results[i].Index), Score: r.Results[i].RelevanceScore}
+	}
+	return out, nil
+}
+
+// Slot is documented at
+// https://github.com/ggml-org/llama.cpp/blob/master/tools/server/README.md#get-slots-returns-the-current-slots-processing-state
+type Slot struct {
+	ID           int64           `json:"id"`
+	IDTask       int64           `json:"id_task"`
+	NCtx         int64           `json:"n_ctx"`
+	Speculative  bool            `json:"speculative"`
+	IsProcessing bool            `json:"is_processing"`
+	Params       json.RawMessage `json:"params"`
+	Prompt       string          `json:"prompt"`
+	NextToken    json.RawMessage `json:"next_token"`
+}
+
 // CompletionRequest is documented at
 // https://github.com/ggml-org/llama.cpp/blob/master/tools/server/README.md#post-completion-given-a-prompt-it-returns-the-predicted-completion
 type CompletionRequest struct {
@@ -453,13 +525,40 @@ func (c *CompletionRequest) Init(msgs genai.Messages, model string, opts ...gena
 			c.Temperature = v.Temperature
 			c.TopP = v.TopP
 			c.TopK = v.TopK
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			c.Stop = v.Stop
+			c.Grammar = v.Grammar
 			if v.ReplyAsJSON {
 				errs = append(errs, errors.New("implement option ReplyAsJSON"))
 			}
 			if v.DecodeAs != nil {
 				errs = append(errs, errors.New("implement option DecodeAs"))
 			}
+		case *GenOption:
+			// The completion API doesn't use a chat template.
+			if v.ReasoningFormat != "" {
+				unsupported = append(unsupported, "GenOption.ReasoningFormat")
+			}
+			if v.Thinking {
+				unsupported = append(unsupported, "GenOption.Thinking")
+			}
+			c.MinP = v.MinP
+			c.TypicalP = v.TypicalP
+			c.Mirostat = v.Mirostat
+			c.MirostatTau = v.MirostatTau
+			c.MirostatEta = v.MirostatEta
+			c.RepeatPenalty = v.RepeatPenalty
+			c.RepeatLastN = v.RepeatLastN
+			c.DryMultiplier = v.DryMultiplier
+			c.DryBase = v.DryBase
+			c.DryAllowedLength = v.DryAllowedLength
+			c.DryPenaltyLastN = v.DryPenaltyLastN
+			c.DrySequenceBreakers = v.DrySequenceBreakers
 		case genai.GenOptionSeed:
 			c.Seed = int64(v)
 		default:
@@ -547,16 +646,18 @@ type CompletionResponse struct {
 
 // ToResult converts the completion response to a genai.Result.
 func (c *CompletionResponse) ToResult() (genai.Result, error) {
+	fr, err := c.StopType.ToFinishReason()
 	out := genai.Result{
 		Message: genai.Message{Replies: []genai.Reply{{Text: c.Content}}},
 		Usage: genai.Usage{
 			InputTokens:       c.TokensPredicted,
 			InputCachedTokens: c.TokensCached,
 			OutputTokens:      c.TokensEvaluated,
-			FinishReason:      c.StopType.ToFinishReason(),
+			FinishReason:      fr,
+			StopSequence:      c.StoppingWord,
 		},
 	}
-	return out, nil
+	return out, err
 }
 
 // StopType describes the reason a completion stopped.
@@ -570,19 +671,18 @@ const (
 )
 
 // ToFinishReason converts to the genai finish reason type.
-func (s StopType) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (s StopType) ToFinishReason() (genai.FinishReason, error) {
 	switch s {
 	case StopEOS:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case StopLimit:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case StopWord:
-		return genai.FinishedStopSequence
+		return genai.FinishedStopSequence, nil
 	default:
-		if !internal.BeLenient {
-			panic(s)
-		}
-		return genai.FinishReason(s)
+		return genai.FinishReason(s), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", s)}
 	}
 }
 
@@ -765,11 +865,7 @@ func (c *Contents) UnmarshalJSON(b []byte) error {
 		*c = nil
 		return nil
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode((*[]Content)(c)); err == nil {
+	if err := json.Unmarshal(b, (*[]Content)(c)); err == nil {
 		return nil
 	}
 
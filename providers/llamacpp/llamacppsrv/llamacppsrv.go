@@ -4,6 +4,9 @@
 
 // Package llamacppsrv downloads and starts llama-server from
 // llama.cpp, directly from GitHub releases.
+//
+// Use NewSupervisor to keep the server running across crashes and to swap
+// the loaded model.
 package llamacppsrv
 
 import (
@@ -177,6 +180,8 @@ func (s *Server) URL() string {
 
 // Done is a channel to listen to the server's termination. No need to call
 // Close() if it is set.
+//
+// The server is not restarted when it crashes. Use a Supervisor for that.
 func (s *Server) Done() <-chan error {
 	return s.done
 }
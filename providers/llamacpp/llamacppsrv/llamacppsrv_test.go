@@ -19,8 +19,11 @@ import (
 )
 
 func TestMain(m *testing.M) {
-	if os.Getenv("LLAMACPP_TEST_HELPER") == "1" {
+	switch os.Getenv("LLAMACPP_TEST_HELPER") {
+	case "1":
 		os.Exit(runDownloadReleaseHelper())
+	case "server":
+		os.Exit(runFakeServer())
 	}
 	os.Exit(m.Run())
 }
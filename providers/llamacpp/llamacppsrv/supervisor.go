@@ -0,0 +1,418 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Supervision of a llama-server instance.
+
+package llamacppsrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/providers/llamacpp"
+)
+
+// EventType is the kind of lifecycle Event emitted by a Supervisor.
+type EventType int
+
+const (
+	// EventStarted is emitted when the server is healthy, including after a restart or a model swap.
+	EventStarted EventType = iota + 1
+	// EventUnhealthy is emitted when a health check fails.
+	EventUnhealthy
+	// EventExited is emitted when the server exited unexpectedly, failed to start or was stopped because it
+	// was unhealthy.
+	EventExited
+	// EventRestarting is emitted after the backoff delay, right before the server is restarted.
+	EventRestarting
+	// EventModelSwapped is emitted when SwapModel succeeded.
+	EventModelSwapped
+	// EventStopped is emitted last, when the supervisor stops.
+	EventStopped
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventStarted:
+		return "started"
+	case EventUnhealthy:
+		return "unhealthy"
+	case EventExited:
+		return "exited"
+	case EventRestarting:
+		return "restarting"
+	case EventModelSwapped:
+		return "model swapped"
+	case EventStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(e))
+	}
+}
+
+// Event is a lifecycle event of a supervised llama-server.
+type Event struct {
+	Type EventType
+	// ModelPath is the model served, or being started.
+	ModelPath string
+	// Attempt is the number of consecutive restart attempts for EventRestarting.
+	Attempt int
+	// Err is the cause of EventUnhealthy, EventExited and EventStopped, if any.
+	Err error
+}
+
+// SupervisorOptions configures the supervision of a llama-server.
+//
+// The zero value uses the defaults documented on each field.
+type SupervisorOptions struct {
+	// HealthInterval is the interval between health checks. Defaults to 5s.
+	HealthInterval time.Duration
+	// UnhealthyThreshold is the number of consecutive failed health checks after which the server is
+	// considered hung and is restarted. Defaults to 3.
+	UnhealthyThreshold int
+	// MinBackoff is the delay before the first restart attempt. It doubles on each consecutive attempt.
+	// Defaults to 500ms.
+	MinBackoff time.Duration
+	// MaxBackoff bounds the delay between restart attempts. The attempt counter is reset once the server ran
+	// for longer than MaxBackoff. Defaults to 30s.
+	MaxBackoff time.Duration
+	// MaxRestarts is the number of consecutive restart attempts after which the supervisor gives up. Defaults
+	// to 5. Set to -1 to retry forever.
+	MaxRestarts int
+}
+
+// Validate returns an error if the options are invalid.
+func (o *SupervisorOptions) Validate() error {
+	if o.HealthInterval < 0 {
+		return errors.New("field HealthInterval: must be positive")
+	}
+	if o.UnhealthyThreshold < 0 {
+		return errors.New("field UnhealthyThreshold: must be positive")
+	}
+	if o.MinBackoff < 0 {
+		return errors.New("field MinBackoff: must be positive")
+	}
+	if o.MaxBackoff < 0 {
+		return errors.New("field MaxBackoff: must be positive")
+	}
+	if o.MinBackoff != 0 && o.MaxBackoff != 0 && o.MinBackoff > o.MaxBackoff {
+		return errors.New("field MinBackoff: must not be greater than MaxBackoff")
+	}
+	if o.MaxRestarts < -1 {
+		return errors.New("field MaxRestarts: must be -1 or positive")
+	}
+	return nil
+}
+
+// Supervisor runs a llama-server, health checks it, restarts it when it crashes or hangs and can swap the
+// loaded model.
+//
+// The server keeps the same URL across restarts.
+type Supervisor struct {
+	exe       string
+	logOutput io.Writer
+	hostPort  string
+	threads   int
+	extraArgs []string
+	opts      SupervisorOptions
+	url       string
+	health    *llamacpp.Client
+
+	cancel  context.CancelFunc
+	events  chan Event
+	swap    chan swapRequest
+	done    chan error
+	stopped chan struct{}
+	err     error
+
+	mu        sync.Mutex
+	srv       *Server
+	modelPath string
+}
+
+type swapRequest struct {
+	modelPath string
+	reply     chan error
+}
+
+// NewSupervisor starts a llama-server like New and supervises it until ctx is canceled or Close is called.
+//
+// opts can be nil to use the defaults.
+func NewSupervisor(ctx context.Context, exe, modelPath string, logOutput io.Writer, hostPort string, threads int, extraArgs []string, opts *SupervisorOptions) (*Supervisor, error) {
+	s := &Supervisor{
+		exe:       exe,
+		logOutput: logOutput,
+		threads:   threads,
+		extraArgs: extraArgs,
+		events:    make(chan Event, 32),
+		swap:      make(chan swapRequest),
+		done:      make(chan error, 1),
+		stopped:   make(chan struct{}),
+		modelPath: modelPath,
+	}
+	if opts != nil {
+		if err := opts.Validate(); err != nil {
+			return nil, err
+		}
+		s.opts = *opts
+	}
+	if s.opts.HealthInterval == 0 {
+		s.opts.HealthInterval = 5 * time.Second
+	}
+	if s.opts.UnhealthyThreshold == 0 {
+		s.opts.UnhealthyThreshold = 3
+	}
+	if s.opts.MinBackoff == 0 {
+		s.opts.MinBackoff = 500 * time.Millisecond
+	}
+	if s.opts.MaxBackoff == 0 {
+		s.opts.MaxBackoff = 30 * time.Second
+	}
+	if s.opts.MaxRestarts == 0 {
+		s.opts.MaxRestarts = 5
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	srv, err := New(ctx, exe, modelPath, logOutput, hostPort, threads, extraArgs)
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	// Pin the port so the URL stays valid across restarts.
+	u, err := url.Parse(srv.URL())
+	if err != nil {
+		_ = srv.Close()
+		s.cancel()
+		return nil, err
+	}
+	s.url = srv.URL()
+	s.hostPort = u.Host
+	if s.health, err = llamacpp.New(ctx, genai.ProviderOptionRemote(s.url)); err != nil {
+		_ = srv.Close()
+		s.cancel()
+		return nil, err
+	}
+	s.srv = srv
+	s.emit(Event{Type: EventStarted, ModelPath: modelPath})
+	go s.run(ctx)
+	return s, nil
+}
+
+// URL returns the URL to the server. It doesn't change across restarts.
+func (s *Supervisor) URL() string {
+	return s.url
+}
+
+// ModelPath returns the path of the model currently served.
+func (s *Supervisor) ModelPath() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.modelPath
+}
+
+// Events returns the channel of lifecycle events. It is closed after EventStopped.
+//
+// The channel is buffered. Events are dropped when the buffer is full, so the supervisor is never blocked by
+// a slow reader.
+func (s *Supervisor) Events() <-chan Event {
+	return s.events
+}
+
+// Done is a channel to listen to the supervisor's termination. It returns nil when stopped with Close or
+// the context, or the last error when it gave up restarting the server.
+func (s *Supervisor) Done() <-chan error {
+	return s.done
+}
+
+// Close stops the server and the supervision, and waits for them to exit.
+func (s *Supervisor) Close() error {
+	s.cancel()
+	<-s.stopped
+	return s.err
+}
+
+// SwapModel restarts the server with another model and waits for it to be healthy.
+//
+// modelPath must be an absolute path to a local model file. On failure, the server is restarted with the
+// previous model.
+func (s *Supervisor) SwapModel(ctx context.Context, modelPath string) error {
+	if !filepath.IsAbs(modelPath) {
+		return errors.New("modelPath must be an absolute path")
+	}
+	req := swapRequest{modelPath: modelPath, reply: make(chan error, 1)}
+	select {
+	case s.swap <- req:
+	case <-s.stopped:
+		return errors.New("supervisor is stopped")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-req.reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Supervisor) run(ctx context.Context) {
+	var finalErr error
+	defer func() {
+		s.mu.Lock()
+		srv := s.srv
+		s.srv = nil
+		s.mu.Unlock()
+		if srv != nil {
+			_ = srv.Close()
+		}
+		s.emit(Event{Type: EventStopped, ModelPath: s.ModelPath(), Err: finalErr})
+		close(s.events)
+		s.err = finalErr
+		close(s.stopped)
+		s.done <- finalErr
+	}()
+	attempt := 0
+	started := time.Now()
+	failures := 0
+	t := time.NewTicker(s.opts.HealthInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-s.srv.Done():
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				err = errors.New("server exited")
+			}
+			s.setServer(nil)
+			s.emit(Event{Type: EventExited, ModelPath: s.ModelPath(), Err: err})
+			if finalErr = s.restart(ctx, &attempt, &started); finalErr != nil || ctx.Err() != nil {
+				return
+			}
+			failures = 0
+		case <-t.C:
+			hctx, cancel := context.WithTimeout(ctx, s.opts.HealthInterval)
+			status, err := s.health.GetHealth(hctx)
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil && status != "ok" {
+				err = fmt.Errorf("server unavailable. status: %q", status)
+			}
+			if err == nil {
+				failures = 0
+				continue
+			}
+			failures++
+			s.emit(Event{Type: EventUnhealthy, ModelPath: s.ModelPath(), Err: err})
+			if failures < s.opts.UnhealthyThreshold {
+				continue
+			}
+			s.stopServer()
+			s.emit(Event{Type: EventExited, ModelPath: s.ModelPath(), Err: fmt.Errorf("unhealthy: %w", err)})
+			if finalErr = s.restart(ctx, &attempt, &started); finalErr != nil || ctx.Err() != nil {
+				return
+			}
+			failures = 0
+		case req := <-s.swap:
+			prev := s.ModelPath()
+			s.stopServer()
+			s.setModelPath(req.modelPath)
+			srv, err := New(ctx, s.exe, req.modelPath, s.logOutput, s.hostPort, s.threads, s.extraArgs)
+			if err == nil {
+				s.setServer(srv)
+				attempt = 0
+				started = time.Now()
+				failures = 0
+				s.emit(Event{Type: EventModelSwapped, ModelPath: req.modelPath})
+				s.emit(Event{Type: EventStarted, ModelPath: req.modelPath})
+				req.reply <- nil
+				continue
+			}
+			req.reply <- fmt.Errorf("failed to start with model %s: %w", req.modelPath, err)
+			s.emit(Event{Type: EventExited, ModelPath: req.modelPath, Err: err})
+			s.setModelPath(prev)
+			if finalErr = s.restart(ctx, &attempt, &started); finalErr != nil || ctx.Err() != nil {
+				return
+			}
+			failures = 0
+		}
+	}
+}
+
+// restart starts the server again with a bounded exponential backoff.
+//
+// It returns an error when it gave up.
+func (s *Supervisor) restart(ctx context.Context, attempt *int, started *time.Time) error {
+	if time.Since(*started) > s.opts.MaxBackoff {
+		*attempt = 0
+	}
+	for {
+		*attempt++
+		if s.opts.MaxRestarts >= 0 && *attempt > s.opts.MaxRestarts {
+			return fmt.Errorf("gave up after %d restart attempts", s.opts.MaxRestarts)
+		}
+		delay := s.opts.MaxBackoff
+		if shift := *attempt - 1; shift < 32 && s.opts.MinBackoff<<shift < delay {
+			delay = s.opts.MinBackoff << shift
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		modelPath := s.ModelPath()
+		s.emit(Event{Type: EventRestarting, ModelPath: modelPath, Attempt: *attempt})
+		srv, err := New(ctx, s.exe, modelPath, s.logOutput, s.hostPort, s.threads, s.extraArgs)
+		if err == nil {
+			s.setServer(srv)
+			*started = time.Now()
+			s.emit(Event{Type: EventStarted, ModelPath: modelPath})
+			return nil
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.emit(Event{Type: EventExited, ModelPath: modelPath, Err: err})
+	}
+}
+
+func (s *Supervisor) stopServer() {
+	s.mu.Lock()
+	srv := s.srv
+	s.srv = nil
+	s.mu.Unlock()
+	if srv != nil {
+		_ = srv.Close()
+	}
+}
+
+func (s *Supervisor) setServer(srv *Server) {
+	s.mu.Lock()
+	s.srv = srv
+	s.mu.Unlock()
+}
+
+func (s *Supervisor) setModelPath(modelPath string) {
+	s.mu.Lock()
+	s.modelPath = modelPath
+	s.mu.Unlock()
+}
+
+func (s *Supervisor) emit(e Event) {
+	select {
+	case s.events <- e:
+	default:
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the llama-server supervisor.
+
+package llamacppsrv
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSupervisor(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts subprocesses")
+	}
+	dir := t.TempDir()
+	exe := installHelperExecutable(t, dir)
+	t.Setenv("LLAMACPP_TEST_HELPER", "server")
+	opts := &SupervisorOptions{HealthInterval: 20 * time.Millisecond, MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, MaxRestarts: 2}
+	s, err := NewSupervisor(t.Context(), exe, filepath.Join(dir, "a.gguf"), nil, "localhost:0", 1, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	wantEvents(t, s, EventStarted)
+	u := s.URL()
+
+	t.Run("crash", func(t *testing.T) {
+		if err := s.srv.cmd.Process.Kill(); err != nil {
+			t.Fatal(err)
+		}
+		wantEvents(t, s, EventExited, EventRestarting, EventStarted)
+		if s.URL() != u {
+			t.Fatalf("URL changed from %s to %s", u, s.URL())
+		}
+	})
+	t.Run("SwapModel", func(t *testing.T) {
+		b := filepath.Join(dir, "b.gguf")
+		if err := s.SwapModel(t.Context(), b); err != nil {
+			t.Fatal(err)
+		}
+		wantEvents(t, s, EventModelSwapped, EventStarted)
+		if got := s.ModelPath(); got != b {
+			t.Fatalf("unexpected model %q", got)
+		}
+	})
+	t.Run("SwapModel_broken", func(t *testing.T) {
+		if err := s.SwapModel(t.Context(), filepath.Join(dir, "broken.gguf")); err == nil {
+			t.Fatal("expected error")
+		}
+		wantEvents(t, s, EventExited, EventRestarting, EventStarted)
+		if got := s.ModelPath(); got != filepath.Join(dir, "b.gguf") {
+			t.Fatalf("unexpected model %q", got)
+		}
+	})
+	t.Run("unhealthy", func(t *testing.T) {
+		if _, err := http.Post(u+"/hang", "", nil); err != nil {
+			t.Fatal(err)
+		}
+		wantEvents(t, s, EventUnhealthy, EventUnhealthy, EventUnhealthy, EventExited, EventRestarting, EventStarted)
+	})
+	t.Run("Close", func(t *testing.T) {
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+		wantEvents(t, s, EventStopped)
+		if _, ok := <-s.Events(); ok {
+			t.Fatal("expected Events to be closed")
+		}
+		if err := s.SwapModel(t.Context(), filepath.Join(dir, "a.gguf")); err == nil || err.Error() != "supervisor is stopped" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSupervisorOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     SupervisorOptions
+		errMsg string
+	}{
+		{"HealthInterval", SupervisorOptions{HealthInterval: -1}, "field HealthInterval: must be positive"},
+		{"Backoff", SupervisorOptions{MinBackoff: time.Minute, MaxBackoff: time.Second}, "field MinBackoff: must not be greater than MaxBackoff"},
+		{"MaxRestarts", SupervisorOptions{MaxRestarts: -2}, "field MaxRestarts: must be -1 or positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.in.Validate(); err == nil || err.Error() != tt.errMsg {
+				t.Fatalf("error mismatch\nwant %q\ngot  %q", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+// wantEvents waits for the events in order.
+func wantEvents(t *testing.T, s *Supervisor, want ...EventType) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case e := <-s.Events():
+			if e.Type != w {
+				t.Fatalf("want event %s, got %s (%v)", w, e.Type, e.Err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for event %s", w)
+		}
+	}
+}
+
+// runFakeServer emulates llama-server's health endpoint.
+//
+// A model named "broken" fails to start. POST /hang makes the health checks fail.
+func runFakeServer() int {
+	host, port, model := "localhost", "", ""
+	for i := 1; i < len(os.Args)-1; i++ {
+		switch os.Args[i] {
+		case "--host":
+			host = os.Args[i+1]
+		case "--port":
+			port = os.Args[i+1]
+		case "--model":
+			model = os.Args[i+1]
+		}
+	}
+	if strings.HasPrefix(filepath.Base(model), "broken") {
+		return 1
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return 1
+	}
+	hang := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-hang:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"code":503,"message":"Loading model","type":"unavailable_error"}}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		}
+	})
+	mux.HandleFunc("POST /hang", func(w http.ResponseWriter, r *http.Request) {
+		close(hang)
+	})
+	_ = http.Serve(l, mux)
+	return 0
+}
@@ -16,7 +16,6 @@ import (
 	"testing"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/mistral"
 	"github.com/maruel/genai/scoreboard"
@@ -45,6 +44,7 @@ func getClientInner(t *testing.T, opts []genai.ProviderOption, fn func(http.Roun
 	if fn != nil {
 		opts = append(opts, genai.ProviderOptionTransportWrapper(fn))
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return mistral.New(t.Context(), opts...)
 }
 
@@ -104,6 +104,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append(opts, genai.ProviderOptionTransportWrapper(fn))
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := mistral.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -172,7 +173,3 @@ func TestClient(t *testing.T) {
 		internaltest.TestClientProviderErrors(t, f, data)
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
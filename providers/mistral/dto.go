@@ -9,12 +9,13 @@
 package mistral
 
 import (
-	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
@@ -93,6 +94,13 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.TopK != 0 {
 				unsupported = append(unsupported, "GenOptionText.TopK")
 			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			c.N = v.N
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			if v.TopLogprobs > 0 {
 				unsupported = append(unsupported, "GenOptionText.TopLogprobs")
 			}
@@ -112,7 +120,11 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				c.ResponseFormat.Type = "json_object"
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				switch v.Force {
 				case genai.ToolCallAny:
 					c.ToolChoice = "auto"
@@ -121,8 +133,8 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				case genai.ToolCallNone:
 					c.ToolChoice = "none"
 				}
-				c.Tools = make([]Tool, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
 					c.Tools[i].Type = "function"
 					c.Tools[i].Function.Name = t.Name
 					c.Tools[i].Function.Description = t.Description
@@ -137,6 +149,11 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			}
 		// GenOptionWeb is not supported. Web search is only available via the Agents API, not chat completions.
 		// https://docs.mistral.ai/agents/tools/built-in/websearch
+		case *genai.GenOptionAudio:
+			// Voxtral accepts audio input and only replies with text, so TextOnly and OmitTranscript are implied.
+			if v.InputLanguage != "" {
+				unsupported = append(unsupported, "GenOptionAudio.InputLanguage")
+			}
 		case genai.GenOptionSeed:
 			c.RandomSeed = int64(v)
 		default:
@@ -424,11 +441,22 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 			TotalTokens:  c.Usage.TotalTokens,
 		},
 	}
-	if len(c.Choices) != 1 {
-		return out, fmt.Errorf("server returned an unexpected number of choices, expected 1, got %d", len(c.Choices))
+	if len(c.Choices) == 0 {
+		return out, errors.New("server returned no choice")
+	}
+	// The server may not return the choices in order. The first one is the result, the rest are candidates.
+	sort.SliceStable(c.Choices, func(i, j int) bool { return c.Choices[i].Index < c.Choices[j].Index })
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
+	if len(c.Choices) > 1 {
+		out.Candidates = make([]genai.Message, len(c.Choices)-1)
+		for i := 1; i < len(c.Choices); i++ {
+			if err2 := c.Choices[i].Message.To(&out.Candidates[i-1]); err2 != nil {
+				err = errors.Join(err, fmt.Errorf("choice #%d: %w", i, err2))
+			}
+		}
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-	err := c.Choices[0].Message.To(&out.Message)
 	return out, err
 }
 
@@ -444,21 +472,20 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishContentFilter:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -611,6 +638,34 @@ func (m *Model) Context() int64 {
 	return m.MaxContextLength
 }
 
+// GetModalities implements genai.ModelModalities.
+func (m *Model) GetModalities() (in, out genai.Modalities) {
+	if !m.Capabilities.CompletionChat {
+		return nil, nil
+	}
+	if m.Capabilities.Audio {
+		in = append(in, genai.ModalityAudio)
+	}
+	if m.Capabilities.Vision {
+		in = append(in, genai.ModalityImage)
+	}
+	in = append(in, genai.ModalityText)
+	return in, genai.Modalities{genai.ModalityText}
+}
+
+// GetCreated implements genai.ModelCreated.
+func (m *Model) GetCreated() time.Time {
+	if m.Created.IsZero() {
+		return time.Time{}
+	}
+	return m.Created.AsTime()
+}
+
+// GetDeprecated implements genai.ModelDeprecated.
+func (m *Model) GetDeprecated() bool {
+	return m.Deprecation != ""
+}
+
 // ModelsResponse represents the response structure for Mistral models listing.
 type ModelsResponse struct {
 	Object string  `json:"object"` // list
@@ -722,11 +777,7 @@ func (ed *ErrorDetails) String() string {
 
 // UnmarshalJSON implements json.Unmarshaler.
 func (ed *ErrorDetails) UnmarshalJSON(b []byte) error {
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode((*[]ErrorDetail)(ed)); err == nil {
+	if err := json.Unmarshal(b, (*[]ErrorDetail)(ed)); err == nil {
 		return nil
 	}
 	s := ""
@@ -752,11 +803,7 @@ func (er *ErrorMessage) UnmarshalJSON(b []byte) error {
 	var x struct {
 		Detail ErrorDetails `json:"detail"`
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode(&x); err != nil {
+	if err := json.Unmarshal(b, &x); err != nil {
 		return err
 	}
 	er.Detail = x.Detail
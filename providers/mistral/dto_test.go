@@ -28,3 +28,20 @@ func TestUsageDurationS(t *testing.T) {
 		t.Errorf("PromptAudio.AsDuration() = %v", got.PromptAudio.AsDuration())
 	}
 }
+
+func TestChatResponse_candidates(t *testing.T) {
+	var c mistral.ChatResponse
+	if err := json.Unmarshal([]byte(`{"choices":[{"index":1,"finish_reason":"stop","message":{"role":"assistant","content":"Hi"}},{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"Hello"}}]}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.ToResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.String(); got != "Hello" {
+		t.Errorf("got %q, want %q", got, "Hello")
+	}
+	if len(res.Candidates) != 1 || res.Candidates[0].String() != "Hi" {
+		t.Errorf("got candidates %#v", res.Candidates)
+	}
+}
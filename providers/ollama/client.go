@@ -71,11 +71,17 @@ type Client struct {
 // Automatic model selection via ModelCheap, ModelGood, ModelSOTA is using hardcoded models. Before using an
 // hardcoded model ID, it will ask ollama to determine if a model is already loaded and it will use that
 // instead.
+//
+// The proxy defaults to the OLLAMA_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var baseURL, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -94,6 +100,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			preloadedModels = []genai.Model(v)
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -105,13 +119,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "OLLAMA_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
 	c := &Client{
 		impl: base.ProviderBase[*ErrorResponse]{
-			Lenient: internal.BeLenient,
+			Lenient: lenient,
+			Log:     logger,
 			Client: http.Client{
 				Transport: &roundtrippers.RequestID{Transport: t},
 			},
@@ -203,7 +218,7 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 		return res, err
 	}
 	res, err := out.ToResult()
-	if err != nil {
+	if err = c.impl.StrictErr(err); err != nil {
 		return res, err
 	}
 	if err = res.Validate(); err != nil {
@@ -222,7 +237,7 @@ func (c *Client) GenSyncRaw(ctx context.Context, in *ChatRequest, out *ChatRespo
 	if err != nil {
 		// TODO: Cheezy.
 		if strings.Contains(err.Error(), "not found") {
-			if err := c.PullModel(ctx, c.impl.Model); err != nil {
+			if err := c.PullModel(ctx, c.impl.Model, nil); err != nil {
 				return err
 			}
 			// Retry.
@@ -267,7 +282,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		}
 		var err error
 		res.Usage, res.Logprobs, err = finish2()
-		if finalErr == nil {
+		if err = c.impl.StrictErr(err); finalErr == nil {
 			finalErr = err
 		}
 	}
@@ -312,7 +327,7 @@ func (c *Client) GenStreamRaw(ctx context.Context, in *ChatRequest) (iter.Seq[Ch
 			return err2
 		}
 		// Model was not present. Try to pull then rerun again.
-		if err2 = c.PullModel(ctx, c.impl.Model); err2 != nil {
+		if err2 = c.PullModel(ctx, c.impl.Model, nil); err2 != nil {
 			return &internal.BadError{Err: err2}
 		}
 		// Try a second time now that the model was pulled successfully.
@@ -355,19 +370,70 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 
 // PullModel is the equivalent of "ollama pull".
 //
+// progress, if not nil, is called for each progress update sent by the server while the model is being
+// downloaded.
+//
 // Files are cached under $HOME/.ollama/models/manifests/registry.ollama.ai/library/ or $OLLAMA_MODELS.
-func (c *Client) PullModel(ctx context.Context, model string) error {
-	in := pullModelRequest{Model: model}
-	// TODO: Stream updates instead of hanging for several minutes.
-	out := pullModelResponse{}
-	if err := c.impl.DoRequest(ctx, "POST", c.baseURL+"/api/pull", &in, &out); err != nil {
+func (c *Client) PullModel(ctx context.Context, model string, progress func(PullProgress)) error {
+	// https://docs.ollama.com/api/pull
+	url := c.baseURL + "/api/pull"
+	// Only stream when progress is requested; otherwise the server replies once the pull completed.
+	in := pullModelRequest{Model: model, Stream: progress != nil}
+	resp, err := c.impl.JSONRequest(ctx, "POST", url, &in)
+	if err != nil {
 		return fmt.Errorf("pull failed: %w", err)
-	} else if out.Status != "success" {
-		return fmt.Errorf("pull failed: %s", out.Status)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pull failed: %w", c.impl.DecodeError(url, resp))
+	}
+	status := ""
+	for d := json.NewDecoder(resp.Body); ; {
+		p := PullProgress{}
+		if err := d.Decode(&p); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("pull failed: %w", err)
+		}
+		if p.ErrorVal != "" {
+			return fmt.Errorf("pull failed: %s", p.ErrorVal)
+		}
+		status = p.Status
+		if progress != nil {
+			progress(p)
+		}
+	}
+	if status != "success" {
+		return fmt.Errorf("pull failed: %s", status)
 	}
 	return nil
 }
 
+// Embed returns the embedding vector of each input, in order.
+//
+// The model must be an embedding model, e.g. "embeddinggemma" or "nomic-embed-text". Use EmbedRaw to control
+// the dimensions or keep_alive.
+func (c *Client) Embed(ctx context.Context, input []string) ([][]float64, error) {
+	in := EmbedRequest{Model: c.impl.Model, Input: input, Truncate: true}
+	var out EmbedResponse
+	if err := c.EmbedRaw(ctx, &in, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Embeddings) != len(input) {
+		return nil, &internal.BadError{Err: fmt.Errorf("expected %d embeddings, got %d", len(input), len(out.Embeddings))}
+	}
+	return out.Embeddings, nil
+}
+
+// EmbedRaw provides access to the raw embedding API.
+func (c *Client) EmbedRaw(ctx context.Context, in *EmbedRequest, out *EmbedResponse) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	// https://docs.ollama.com/api/embed
+	return c.impl.DoRequest(ctx, "POST", c.baseURL+"/api/embed", in, out)
+}
+
 // Version returns the Ollama server version.
 func (c *Client) Version(ctx context.Context) (string, error) {
 	v := Version{}
@@ -439,7 +505,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 				if pkt.EvalCount != 0 {
 					u.InputTokens = pkt.PromptEvalCount
 					u.OutputTokens = pkt.EvalCount
-					u.FinishReason = pkt.DoneReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.DoneReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				l = append(l, ToGenaiLogprobs(pkt.Logprobs)...)
 				switch role := pkt.Message.Role; role {
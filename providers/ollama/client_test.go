@@ -8,7 +8,9 @@ package ollama_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"iter"
 	"log/slog"
 	"net/http"
@@ -20,7 +22,6 @@ import (
 	"github.com/maruel/roundtrippers"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/ollama"
 	"github.com/maruel/genai/scoreboard"
@@ -71,6 +72,7 @@ func TestClient(t *testing.T) {
 			if model.Model != "" {
 				opts = append(opts, genai.ProviderOptionModel(model.Model))
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := ollama.New(ctx, opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -110,6 +112,7 @@ func TestClient(t *testing.T) {
 					return testRecorder.Record(t, h)
 				}),
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := ollama.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -146,6 +149,7 @@ func TestClient(t *testing.T) {
 				serverURL = s.lazyStart(t)
 			}
 			opts = append(opts, genai.ProviderOptionRemote(serverURL), genai.ProviderOptionTransportWrapper(wrapper))
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			return ollama.New(t.Context(), opts...)
 		}
 		internaltest.TestClientProviderErrors(t, f, data)
@@ -201,6 +205,151 @@ func (o *ollamaThinkOff) Unwrap() genai.Provider {
 	return o.Provider
 }
 
-func init() {
-	internal.BeLenient = false
+func TestPullModel(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		want   []ollama.PullProgress
+		errMsg string
+	}{
+		{
+			name: "success",
+			body: `{"status":"pulling manifest"}
+{"status":"pulling 3f8eb4da87fa","digest":"sha256:3f8eb4da87fa","total":2000,"completed":1000}
+{"status":"pulling 3f8eb4da87fa","digest":"sha256:3f8eb4da87fa","total":2000,"completed":2000}
+{"status":"success"}
+`,
+			want: []ollama.PullProgress{
+				{Status: "pulling manifest"},
+				{Status: "pulling 3f8eb4da87fa", Digest: "sha256:3f8eb4da87fa", Total: 2000, Completed: 1000},
+				{Status: "pulling 3f8eb4da87fa", Digest: "sha256:3f8eb4da87fa", Total: 2000, Completed: 2000},
+				{Status: "success"},
+			},
+		},
+		{
+			name:   "error",
+			body:   "{\"status\":\"pulling manifest\"}\n{\"error\":\"pull model manifest: file does not exist\"}\n",
+			want:   []ollama.PullProgress{{Status: "pulling manifest"}},
+			errMsg: "pull failed: pull model manifest: file does not exist",
+		},
+		{
+			name:   "truncated",
+			body:   "{\"status\":\"pulling manifest\"}\n",
+			want:   []ollama.PullProgress{{Status: "pulling manifest"}},
+			errMsg: "pull failed: pulling manifest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeClient(t, "", func(r *http.Request) *http.Response {
+				if r.URL.Path != "/api/pull" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				var in map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in["model"] != "gemma3:4b" || in["stream"] != true {
+					t.Errorf("unexpected request %v: %v", in, err)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(tt.body))}
+			})
+			var got []ollama.PullProgress
+			err := c.PullModel(t.Context(), "gemma3:4b", func(p ollama.PullProgress) { got = append(got, p) })
+			if tt.errMsg == "" && err != nil {
+				t.Fatal(err)
+			} else if tt.errMsg != "" && (err == nil || err.Error() != tt.errMsg) {
+				t.Fatalf("error mismatch\nwant %q\ngot  %q", tt.errMsg, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("#%d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+	t.Run("no_progress", func(t *testing.T) {
+		c := newFakeClient(t, "", func(r *http.Request) *http.Response {
+			var in map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in["stream"] != false {
+				t.Errorf("unexpected request %v: %v", in, err)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"status":"success"}`))}
+		})
+		if err := c.PullModel(t.Context(), "gemma3:4b", nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestEmbed(t *testing.T) {
+	c := newFakeClient(t, "embeddinggemma", func(r *http.Request) *http.Response {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var in ollama.EmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Error(err)
+		}
+		if in.Model != "embeddinggemma" || len(in.Input) != 2 || !in.Truncate {
+			t.Errorf("unexpected request %+v", in)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"model":"embeddinggemma","embeddings":[[0.1,0.2],[0.3,0.4]],"total_duration":14143917,"load_duration":1019500,"prompt_eval_count":8}`)),
+		}
+	})
+	got, err := c.Embed(t.Context(), []string{"Why is the sky blue?", "Why is the grass green?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0][1] != 0.2 || got[1][0] != 0.3 {
+		t.Fatalf("unexpected embeddings %v", got)
+	}
+}
+
+func TestGenOptionText(t *testing.T) {
+	c := newFakeClient(t, "gemma3:4b", func(r *http.Request) *http.Response {
+		var in ollama.ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Error(err)
+		}
+		if in.KeepAlive != "-1s" {
+			t.Errorf("unexpected keep_alive %q", in.KeepAlive)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"model":"gemma3:4b","created_at":"2025-01-01T00:00:00Z","message":{"role":"assistant","content":"Hi"},"done":true,"done_reason":"stop","prompt_eval_count":1,"eval_count":1}`)),
+		}
+	})
+	if _, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Hello")}, &ollama.GenOptionText{KeepAlive: "-1s"}); err != nil {
+		t.Fatal(err)
+	}
+	o := ollama.GenOptionText{KeepAlive: "5"}
+	if err := o.Validate(); err == nil || err.Error() != `field KeepAlive: time: missing unit in duration "5"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// newFakeClient returns a client where every HTTP request is served by h.
+func newFakeClient(t *testing.T, model string, h func(r *http.Request) *http.Response) *ollama.Client {
+	wrapper := genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+		return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			resp := h(r)
+			resp.Request = r
+			return resp, nil
+		})
+	})
+	opts := []genai.ProviderOption{wrapper}
+	if model != "" {
+		opts = append(opts, genai.ProviderOptionModel(model))
+	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
+	c, err := ollama.New(t.Context(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
 }
@@ -131,10 +131,19 @@ func (r ReasoningEffort) Validate() error {
 type GenOptionText struct {
 	// ReasoningEffort controls the thinking effort level ("off", "low", "medium", "high").
 	ReasoningEffort ReasoningEffort
+	// KeepAlive is how long the model stays loaded in memory after the request, formatted as a Go duration,
+	// e.g. "10m". "0s" unloads the model immediately and a negative duration keeps it loaded indefinitely.
+	// Defaults to the server's OLLAMA_KEEP_ALIVE, which is "5m" by default.
+	KeepAlive string
 }
 
 // Validate implements genai.Validatable.
 func (o *GenOptionText) Validate() error {
+	if o.KeepAlive != "" {
+		if _, err := time.ParseDuration(o.KeepAlive); err != nil {
+			return fmt.Errorf("field KeepAlive: %w", err)
+		}
+	}
 	return o.ReasoningEffort.Validate()
 }
 
@@ -187,6 +196,15 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			sp = v.SystemPrompt
 			c.Options.TopK = v.TopK
 			c.Options.Stop = v.Stop
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" && c.Think == "" {
+				c.Think = ReasoningEffort(v.ReasoningEffort)
+			}
 			if v.TopLogprobs > 0 {
 				c.TopLogprobs = v.TopLogprobs
 				c.Logprobs = true
@@ -202,15 +220,19 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				c.Format.Type = "json"
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				switch v.Force {
 				case genai.ToolCallAny:
 				case genai.ToolCallRequired, genai.ToolCallNone:
 					// Don't fail.
 					unsupported = append(unsupported, "GenOptionTools.Force")
 				}
-				c.Tools = make([]Tool, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
 					c.Tools[i].Type = "function"
 					c.Tools[i].Function.Name = t.Name
 					c.Tools[i].Function.Description = t.Description
@@ -225,6 +247,7 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.ReasoningEffort != "" {
 				c.Think = v.ReasoningEffort
 			}
+			c.KeepAlive = v.KeepAlive
 		case genai.GenOptionSeed:
 			c.Options.Seed = int64(v)
 		default:
@@ -520,16 +543,17 @@ type ChatResponse struct {
 
 // ToResult converts the ChatResponse to a genai.Result.
 func (c *ChatResponse) ToResult() (genai.Result, error) {
+	fr, errFR := c.DoneReason.ToFinishReason()
 	out := genai.Result{
 		// TODO: llama-server supports caching and we should report it.
 		Usage: genai.Usage{
 			InputTokens:  c.PromptEvalCount,
 			OutputTokens: c.EvalCount,
-			FinishReason: c.DoneReason.ToFinishReason(),
+			FinishReason: fr,
 		},
 		Logprobs: ToGenaiLogprobs(c.Logprobs),
 	}
-	err := c.Message.To(&out.Message)
+	err := errors.Join(errFR, c.Message.To(&out.Message))
 	if out.Usage.FinishReason == genai.FinishedStop && slices.ContainsFunc(out.Replies, func(r genai.Reply) bool { return !r.ToolCall.IsZero() }) {
 		// Lie for the benefit of everyone.
 		out.Usage.FinishReason = genai.FinishedToolCalls
@@ -555,19 +579,18 @@ const (
 )
 
 // ToFinishReason converts the DoneReason to a genai.FinishReason.
-func (d DoneReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (d DoneReason) ToFinishReason() (genai.FinishReason, error) {
 	switch d {
 	case DoneStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case DoneLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case DoneLoad, DoneUnload:
-		return genai.FinishReason(d)
+		return genai.FinishReason(d), nil
 	default:
-		if !internal.BeLenient {
-			panic(d)
-		}
-		return genai.FinishReason(d)
+		return genai.FinishReason(d), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", d)}
 	}
 }
 
@@ -598,7 +621,7 @@ func (m *Model) GetID() string {
 }
 
 func (m *Model) String() string {
-	return fmt.Sprintf("%s (%s)", m.Name, m.Details.QuantizationLevel)
+	return fmt.Sprintf("%s (%s %s, %.1fGiB)", m.Name, m.Details.ParameterSize, m.Details.QuantizationLevel, float64(m.Size)/(1<<30))
 }
 
 // Context implements genai.Model.
@@ -626,11 +649,39 @@ type pullModelRequest struct {
 	Stream   bool   `json:"stream"`
 }
 
-type pullModelResponse struct {
-	Status    string `json:"status"`
-	Digest    string `json:"digest"`
-	Total     int64  `json:"total"`
-	Completed int64  `json:"completed"`
+// PullProgress is a progress update while pulling a model.
+//
+// It is documented at https://docs.ollama.com/api/pull
+type PullProgress struct {
+	// Status is a human readable status, e.g. "pulling manifest", "pulling <digest>" or "success".
+	Status string `json:"status"`
+	// Digest is the layer being downloaded, if any.
+	Digest string `json:"digest,omitzero"`
+	// Total is the size of the layer in bytes.
+	Total int64 `json:"total,omitzero"`
+	// Completed is the number of bytes of the layer downloaded so far.
+	Completed int64 `json:"completed,omitzero"`
+	// ErrorVal is set when the pull failed midway.
+	ErrorVal string `json:"error,omitzero"`
+}
+
+// EmbedRequest is documented at https://docs.ollama.com/api/embed
+type EmbedRequest struct {
+	Model      string             `json:"model"`
+	Input      []string           `json:"input"`
+	Truncate   bool               `json:"truncate"` // Default true; truncates the input to fit the context length.
+	Dimensions int64              `json:"dimensions,omitzero"`
+	KeepAlive  string             `json:"keep_alive,omitzero"` // Default "5m"
+	Options    ChatRequestOptions `json:"options,omitzero"`
+}
+
+// EmbedResponse is documented at https://docs.ollama.com/api/embed
+type EmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float64 `json:"embeddings"`
+	TotalDuration   int64       `json:"total_duration"` // Nanoseconds
+	LoadDuration    int64       `json:"load_duration"`  // Nanoseconds
+	PromptEvalCount int64       `json:"prompt_eval_count"`
 }
 
 // Version is the response from the version API.
@@ -12,9 +12,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"os"
 	"regexp"
 	"slices"
 	"strconv"
@@ -61,6 +63,54 @@ func textModelRank(id string, cheap, good bool) int {
 	return 0
 }
 
+// ProviderOptionOrganization selects the organization billed for the requests, for accounts belonging to
+// multiple organizations. It is sent as the OpenAI-Organization header.
+//
+// It defaults to the OPENAI_ORG_ID environment variable.
+type ProviderOptionOrganization string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionOrganization) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionOrganization cannot be empty")
+	}
+	return nil
+}
+
+// ProviderOptionProject selects the project billed for the requests. It is sent as the OpenAI-Project
+// header.
+//
+// It defaults to the OPENAI_PROJECT_ID environment variable.
+type ProviderOptionProject string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionProject) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionProject cannot be empty")
+	}
+	return nil
+}
+
+// Headers returns the headers to authenticate to the OpenAI API.
+//
+// org and project default to the OPENAI_ORG_ID and OPENAI_PROJECT_ID environment variables.
+func Headers(apiKey string, org ProviderOptionOrganization, project ProviderOptionProject) http.Header {
+	h := http.Header{"Authorization": {"Bearer " + apiKey}}
+	if org == "" {
+		org = ProviderOptionOrganization(os.Getenv("OPENAI_ORG_ID"))
+	}
+	if org != "" {
+		h.Set("OpenAI-Organization", string(org))
+	}
+	if project == "" {
+		project = ProviderOptionProject(os.Getenv("OPENAI_PROJECT_ID"))
+	}
+	if project != "" {
+		h.Set("OpenAI-Project", string(project))
+	}
+	return h
+}
+
 // Client holds the shared state and methods common to both the OpenAI Chat Completion and Responses API
 // providers.
 //
@@ -72,6 +122,8 @@ type Client struct {
 	BaseURL string
 	// PreloadedModels is an optional pre-supplied model list to avoid HTTP round-trips.
 	PreloadedModels []genai.Model
+	// ModelSelector replaces the heuristics of SelectBestTextModel when set.
+	ModelSelector genai.ProviderOptionModelSelector
 }
 
 // ListModels returns the list of available models.
@@ -88,25 +140,32 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 }
 
 // GenDoc generates an image document from a single message.
+//
+// When the message contains images, they are edited instead. A Doc named "mask" with any extension, e.g.
+// "mask.png", is used as the mask: its fully transparent areas indicate where the image should be edited.
 func (c *Client) GenDoc(ctx context.Context, msg *genai.Message, opts ...genai.GenOption) (genai.Result, error) {
-	// https://platform.openai.com/docs/api-reference/images/create
 	res := genai.Result{}
 	if err := c.Impl.Validate(); err != nil {
 		return res, err
 	}
-	req := ImageRequest{}
-	if err := req.Init(msg, c.Impl.Model, opts...); err != nil {
-		return res, err
-	}
-	u := c.BaseURL + "/images/generations"
-
-	// It is very different because it requires a multi-part upload.
-	// https://platform.openai.com/docs/api-reference/images/createEdit
-	// url = "https://api.openai.com/v1/images/edits"
-
 	resp := ImageResponse{}
-	if err := c.Impl.DoRequest(ctx, "POST", u, &req, &resp); err != nil {
-		return res, err
+	if slices.ContainsFunc(msg.Requests, func(r genai.Request) bool { return !r.Doc.IsZero() }) {
+		req := ImageEditRequest{}
+		if err := req.Init(msg, c.Impl.Model, opts...); err != nil {
+			return res, err
+		}
+		if err := c.ImageEditRaw(ctx, &req, &resp); err != nil {
+			return res, err
+		}
+	} else {
+		// https://platform.openai.com/docs/api-reference/images/create
+		req := ImageRequest{}
+		if err := req.Init(msg, c.Impl.Model, opts...); err != nil {
+			return res, err
+		}
+		if err := c.Impl.DoRequest(ctx, "POST", c.BaseURL+"/images/generations", &req, &resp); err != nil {
+			return res, err
+		}
 	}
 	res.Replies = make([]genai.Reply, len(resp.Data))
 	for i := range resp.Data {
@@ -128,32 +187,81 @@ func (c *Client) GenDoc(ctx context.Context, msg *genai.Message, opts ...genai.G
 	return res, nil
 }
 
-// FileAdd uploads a file. The TTL is one month.
-func (c *Client) FileAdd(ctx context.Context, filename string, r io.ReadSeeker) (string, error) {
-	// https://platform.openai.com/docs/api-reference/files/create
+// ImageEditRaw edits images with a multipart upload.
+func (c *Client) ImageEditRaw(ctx context.Context, in *ImageEditRequest, out *ImageResponse) error {
+	// https://platform.openai.com/docs/api-reference/images/createEdit
 	buf := bytes.Buffer{}
 	w := multipart.NewWriter(&buf)
 	// We don't need this to be random, and setting it to be deterministic makes HTTP playback possible.
 	_ = w.SetBoundary("80309819a837f26826233a299e185d0ccf3f559362092bd3278b8a045ee1")
-	if err := w.WriteField("purpose", "batch"); err != nil {
-		return "", err
+	if err := in.Write(w); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
 	}
-	part, err := w.CreateFormFile("file", filename)
+	u := c.BaseURL + "/images/edits"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, &buf)
 	if err != nil {
-		return "", err
+		return err
 	}
-	if _, err = io.Copy(part, r); err != nil {
-		return "", err
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	resp, err := c.Impl.Client.Do(req)
+	if err != nil {
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		return err
 	}
-	if err := w.Close(); err != nil {
+	return c.Impl.DecodeResponse(resp, u, out)
+}
+
+// Moderate returns the sorted categories the text is flagged for by the moderation model.
+//
+// It implements safety.Moderator.
+func (c *Client) Moderate(ctx context.Context, text string) ([]string, error) {
+	resp := ModerationResponse{}
+	if err := c.ModerateRaw(ctx, &ModerationRequest{Input: text}, &resp); err != nil {
+		return nil, err
+	}
+	var out []string
+	for i := range resp.Results {
+		for k, v := range resp.Results[i].Categories {
+			if v && !slices.Contains(out, k) {
+				out = append(out, k)
+			}
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// ModerateRaw classifies text with the moderation endpoint.
+func (c *Client) ModerateRaw(ctx context.Context, in *ModerationRequest, out *ModerationResponse) error {
+	// https://platform.openai.com/docs/api-reference/moderations/create
+	return c.Impl.DoRequest(ctx, "POST", c.BaseURL+"/moderations", in, out)
+}
+
+// FileAdd uploads a file. The TTL is one month.
+//
+// The file is streamed, so it doesn't need to fit in memory.
+func (c *Client) FileAdd(ctx context.Context, filename string, r io.ReadSeeker) (string, error) {
+	return c.fileAdd(ctx, filename, "batch", r)
+}
+
+func (c *Client) fileAdd(ctx context.Context, filename, purpose string, r io.Reader) (string, error) {
+	// https://platform.openai.com/docs/api-reference/files/create
+	// We don't need this to be random, and setting it to be deterministic makes HTTP playback possible.
+	getBody, contentType, size, err := base.MultipartFile("80309819a837f26826233a299e185d0ccf3f559362092bd3278b8a045ee1", map[string]string{"purpose": purpose}, "file", filename, r)
+	if err != nil {
 		return "", err
 	}
 	u := c.BaseURL + "/files"
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, &buf)
+	req, err := base.NewBodyRequest(ctx, http.MethodPost, u, getBody, size)
 	if err != nil {
 		return "", err
 	}
-	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Content-Type", contentType)
 	resp, err := c.Impl.Client.Do(req)
 	if err != nil {
 		if resp != nil {
@@ -200,6 +308,113 @@ func (c *Client) FilesListRaw(ctx context.Context) ([]File, error) {
 	return resp.Data, err
 }
 
+// VectorStoreCreate creates a vector store, to be used with the file_search tool of the Responses API.
+func (c *Client) VectorStoreCreate(ctx context.Context, name string) (*VectorStore, error) {
+	// https://platform.openai.com/docs/api-reference/vector-stores/create
+	req := VectorStoreRequest{Name: name}
+	resp := &VectorStore{}
+	if err := c.Impl.DoRequest(ctx, "POST", c.BaseURL+"/vector_stores", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VectorStoreGet retrieves a vector store.
+func (c *Client) VectorStoreGet(ctx context.Context, id string) (*VectorStore, error) {
+	// https://platform.openai.com/docs/api-reference/vector-stores/retrieve
+	resp := &VectorStore{}
+	if err := c.Impl.DoRequest(ctx, "GET", c.BaseURL+"/vector_stores/"+url.PathEscape(id), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VectorStoreList lists all the vector stores.
+func (c *Client) VectorStoreList(ctx context.Context) ([]VectorStore, error) {
+	// https://platform.openai.com/docs/api-reference/vector-stores/list
+	var out []VectorStore
+	for after := ""; ; {
+		u := c.BaseURL + "/vector_stores?limit=100"
+		if after != "" {
+			u += "&after=" + url.QueryEscape(after)
+		}
+		resp := VectorStoreListResponse{}
+		if err := c.Impl.DoRequest(ctx, "GET", u, nil, &resp); err != nil {
+			return out, err
+		}
+		out = append(out, resp.Data...)
+		if !resp.HasMore || resp.LastID == "" {
+			return out, nil
+		}
+		after = resp.LastID
+	}
+}
+
+// VectorStoreDelete deletes a vector store. The files it contains are not deleted.
+func (c *Client) VectorStoreDelete(ctx context.Context, id string) error {
+	// https://platform.openai.com/docs/api-reference/vector-stores/delete
+	out := FileDeleteResponse{}
+	return c.Impl.DoRequest(ctx, "DELETE", c.BaseURL+"/vector_stores/"+url.PathEscape(id), nil, &out)
+}
+
+// VectorStoreFileAdd uploads a file and adds it to a vector store.
+//
+// The file is processed asynchronously: poll VectorStoreFileGet until its Status is not "in_progress" before
+// searching it.
+func (c *Client) VectorStoreFileAdd(ctx context.Context, storeID, filename string, r io.Reader) (*VectorStoreFile, error) {
+	id, err := c.fileAdd(ctx, filename, "assistants", r)
+	if err != nil {
+		return nil, err
+	}
+	// https://platform.openai.com/docs/api-reference/vector-stores-files/createFile
+	req := VectorStoreFileRequest{FileID: id}
+	resp := &VectorStoreFile{}
+	if err := c.Impl.DoRequest(ctx, "POST", c.BaseURL+"/vector_stores/"+url.PathEscape(storeID)+"/files", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VectorStoreFileGet retrieves a file in a vector store, including its processing status.
+func (c *Client) VectorStoreFileGet(ctx context.Context, storeID, fileID string) (*VectorStoreFile, error) {
+	// https://platform.openai.com/docs/api-reference/vector-stores-files/getFile
+	u := c.BaseURL + "/vector_stores/" + url.PathEscape(storeID) + "/files/" + url.PathEscape(fileID)
+	resp := &VectorStoreFile{}
+	if err := c.Impl.DoRequest(ctx, "GET", u, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VectorStoreFileList lists the files in a vector store.
+func (c *Client) VectorStoreFileList(ctx context.Context, storeID string) ([]VectorStoreFile, error) {
+	// https://platform.openai.com/docs/api-reference/vector-stores-files/listFiles
+	var out []VectorStoreFile
+	for after := ""; ; {
+		u := c.BaseURL + "/vector_stores/" + url.PathEscape(storeID) + "/files?limit=100"
+		if after != "" {
+			u += "&after=" + url.QueryEscape(after)
+		}
+		resp := VectorStoreFileListResponse{}
+		if err := c.Impl.DoRequest(ctx, "GET", u, nil, &resp); err != nil {
+			return out, err
+		}
+		out = append(out, resp.Data...)
+		if !resp.HasMore || resp.LastID == "" {
+			return out, nil
+		}
+		after = resp.LastID
+	}
+}
+
+// VectorStoreFileDelete removes a file from a vector store. The file itself is not deleted, use FileDel.
+func (c *Client) VectorStoreFileDelete(ctx context.Context, storeID, fileID string) error {
+	// https://platform.openai.com/docs/api-reference/vector-stores-files/deleteFile
+	u := c.BaseURL + "/vector_stores/" + url.PathEscape(storeID) + "/files/" + url.PathEscape(fileID)
+	out := FileDeleteResponse{}
+	return c.Impl.DoRequest(ctx, "DELETE", u, nil, &out)
+}
+
 // DetectModelModalities tries its best to figure out the modality of a model.
 //
 // We may want to make this function overridable in the future by the client since this is going to break one
@@ -225,6 +440,9 @@ func (c *Client) SelectBestTextModel(ctx context.Context, preference string) (st
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.ModelSelector != nil {
+		return base.SelectModel(ctx, c.ModelSelector, preference, mdls)
+	}
 	cheap := preference == string(genai.ModelCheap)
 	good := preference == string(genai.ModelGood) || preference == ""
 	selectedModel := ""
@@ -360,6 +578,57 @@ func (c *Client) IsVideo() bool {
 	return slices.Contains(c.Impl.OutputModalities, genai.ModalityVideo)
 }
 
+// ImageTokens estimates the input tokens of an image of width x height pixels sent to model at high detail.
+//
+// Most models scale the image to fit in 2048x2048, then its shortest side to 768px and count 512px tiles.
+// Mini and nano models count 32px patches instead.
+//
+// See https://platform.openai.com/docs/guides/images-vision#calculating-costs
+func ImageTokens(model string, width, height int) int64 {
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+	w, h := float64(width), float64(height)
+	for _, p := range []struct {
+		prefix     string
+		multiplier float64
+	}{
+		{"gpt-4.1-mini", 1.62},
+		{"gpt-4.1-nano", 2.46},
+		{"gpt-5-mini", 1.62},
+		{"gpt-5-nano", 2.46},
+		{"o4-mini", 1.72},
+	} {
+		if strings.HasPrefix(model, p.prefix) {
+			// Scale down so the image fits in 1536 patches.
+			const maxPatches = 1536
+			if n := math.Ceil(w/32) * math.Ceil(h/32); n > maxPatches {
+				r := math.Sqrt(32 * 32 * maxPatches / (w * h))
+				r *= min(math.Floor(w*r/32)/(w*r/32), math.Floor(h*r/32)/(h*r/32))
+				w, h = w*r, h*r
+			}
+			return int64(math.Ceil(math.Ceil(w/32) * math.Ceil(h/32) * p.multiplier))
+		}
+	}
+	if r := 2048 / max(w, h); r < 1 {
+		w, h = w*r, h*r
+	}
+	if r := 768 / min(w, h); r < 1 {
+		w, h = w*r, h*r
+	}
+	tiles := int64(math.Ceil(w/512) * math.Ceil(h/512))
+	switch {
+	case strings.HasPrefix(model, "gpt-4o-mini"):
+		return 2833 + 5667*tiles
+	case strings.HasPrefix(model, "o1"), strings.HasPrefix(model, "o3"):
+		return 75 + 150*tiles
+	case strings.HasPrefix(model, "gpt-5"):
+		return 70 + 140*tiles
+	default:
+		return 85 + 170*tiles
+	}
+}
+
 // ProcessHeaders extracts rate limit information from OpenAI HTTP response headers.
 func ProcessHeaders(h http.Header) []genai.RateLimit {
 	var limits []genai.RateLimit
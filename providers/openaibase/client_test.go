@@ -5,10 +5,21 @@
 package openaibase
 
 import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal/internaltest"
 )
 
 func TestClient(t *testing.T) {
@@ -76,3 +87,208 @@ func TestClient(t *testing.T) {
 		}
 	})
 }
+
+func TestImageTokens(t *testing.T) {
+	data := []struct {
+		model         string
+		width, height int
+		want          int64
+	}{
+		{"gpt-4o", 1024, 1024, 765},
+		{"gpt-4o", 2048, 4096, 1105},
+		{"gpt-4o", 100, 100, 255},
+		{"gpt-4o-mini", 1024, 1024, 25501},
+		{"gpt-5.6-luna", 1024, 1024, 630},
+		{"o3", 512, 512, 225},
+		{"gpt-4.1-mini", 1024, 1024, 1659},
+		{"gpt-4.1-mini", 1800, 2400, 2353},
+		{"gpt-4o", 0, 100, 0},
+	}
+	for _, tc := range data {
+		t.Run(fmt.Sprintf("%s/%dx%d", tc.model, tc.width, tc.height), func(t *testing.T) {
+			if got := ImageTokens(tc.model, tc.width, tc.height); got != tc.want {
+				t.Fatalf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenDocEdit(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nimage")
+	var got map[string][]string
+	c := &Client{
+		Impl: &base.ProviderBase[*ErrorResponse]{
+			Model: "gpt-image-1",
+			Client: http.Client{Transport: internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if r.URL.Path != "/v1/images/edits" {
+					t.Errorf("unexpected path %q", r.URL.Path)
+				}
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Fatal(err)
+				}
+				got = r.MultipartForm.Value
+				for k, files := range r.MultipartForm.File {
+					for _, f := range files {
+						got[k] = append(got[k], f.Filename+" "+f.Header.Get("Content-Type"))
+					}
+				}
+				b := `{"created":1,"data":[{"b64_json":"` + base64.StdEncoding.EncodeToString(png) + `"}]}`
+				h := http.Header{"Content-Type": {"application/json"}}
+				return &http.Response{StatusCode: 200, Header: h, Body: io.NopCloser(strings.NewReader(b)), Request: r}, nil
+			})},
+		},
+		BaseURL: "https://api.openai.com/v1",
+	}
+	msg := genai.Message{Requests: []genai.Request{
+		{Text: "Add a hat."},
+		{Doc: genai.Doc{Filename: "cat.png", Src: bytes.NewReader(png)}},
+		{Doc: genai.Doc{Filename: "mask.png", Src: bytes.NewReader(png)}},
+	}}
+	res, err := c.GenDoc(t.Context(), &msg, &genai.GenOptionImage{Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"image":  {"cat.png image/png"},
+		"mask":   {"mask.png image/png"},
+		"model":  {"gpt-image-1"},
+		"n":      {"2"},
+		"prompt": {"Add a hat."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("form mismatch (-want +got):\n%s", diff)
+	}
+	if len(res.Replies) != 1 || res.Replies[0].Doc.Filename != "content.jpg" {
+		t.Fatalf("unexpected replies %+v", res.Replies)
+	}
+}
+
+func TestImageEditRequest(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nimage")
+	t.Run("multiple", func(t *testing.T) {
+		msg := genai.Message{Requests: []genai.Request{
+			{Text: "Combine."},
+			{Doc: genai.Doc{Filename: "a.png", Src: bytes.NewReader(png)}},
+			{Doc: genai.Doc{Filename: "b.png", Src: bytes.NewReader(png)}},
+		}}
+		var req ImageEditRequest
+		if err := req.Init(&msg, "gpt-image-1"); err != nil {
+			t.Fatal(err)
+		}
+		buf := bytes.Buffer{}
+		w := multipart.NewWriter(&buf)
+		if err := req.Write(w); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		f, err := multipart.NewReader(&buf, w.Boundary()).ReadForm(1 << 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(f.File["image[]"]); n != 2 {
+			t.Fatalf("expected 2 images, got %d", n)
+		}
+	})
+	tests := []struct {
+		name   string
+		model  string
+		reqs   []genai.Request
+		errMsg string
+	}{
+		{
+			name:   "no_image",
+			model:  "gpt-image-1",
+			reqs:   []genai.Request{{Text: "Add a hat."}, {Doc: genai.Doc{Filename: "mask.png", Src: bytes.NewReader(png)}}},
+			errMsg: "an image to edit is required",
+		},
+		{
+			name:   "url",
+			model:  "gpt-image-1",
+			reqs:   []genai.Request{{Text: "Add a hat."}, {Doc: genai.Doc{URL: "https://example.com/cat.png"}}},
+			errMsg: "image URLs are not supported, pass the image data",
+		},
+		{
+			name:   "not_image",
+			model:  "gpt-image-1",
+			reqs:   []genai.Request{{Text: "Add a hat."}, {Doc: genai.Doc{Filename: "cat.txt", Src: strings.NewReader("cat")}}},
+			errMsg: "unsupported mime type \"text/plain; charset=utf-8\", only images can be edited",
+		},
+		{
+			name:  "two_masks",
+			model: "gpt-image-1",
+			reqs: []genai.Request{
+				{Text: "Add a hat."},
+				{Doc: genai.Doc{Filename: "mask.png", Src: bytes.NewReader(png)}},
+				{Doc: genai.Doc{Filename: "mask.webp", Src: bytes.NewReader(png)}},
+			},
+			errMsg: "only one mask can be passed as input",
+		},
+		{
+			name:  "dall-e-2",
+			model: "dall-e-2",
+			reqs: []genai.Request{
+				{Text: "Combine."},
+				{Doc: genai.Doc{Filename: "a.png", Src: bytes.NewReader(png)}},
+				{Doc: genai.Doc{Filename: "b.png", Src: bytes.NewReader(png)}},
+			},
+			errMsg: "dall-e-2 only supports one image",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var req ImageEditRequest
+			msg := genai.Message{Requests: tc.reqs}
+			if err := req.Init(&msg, tc.model); err == nil || err.Error() != tc.errMsg {
+				t.Fatalf("error mismatch\nwant %q\ngot  %v", tc.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestModerate(t *testing.T) {
+	c := &Client{
+		Impl: &base.ProviderBase[*ErrorResponse]{
+			Model: "gpt-5.6",
+			Client: http.Client{Transport: internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if r.URL.Path != "/v1/moderations" {
+					t.Errorf("unexpected path %q", r.URL.Path)
+				}
+				b := `{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":true,"categories":{"violence":true,"hate":true,"sexual":false},"category_scores":{"violence":0.9,"hate":0.8,"sexual":0.1},"category_applied_input_types":{"violence":["text"],"hate":["text"],"sexual":["text"]}}]}`
+				h := http.Header{"Content-Type": {"application/json"}}
+				return &http.Response{StatusCode: 200, Header: h, Body: io.NopCloser(strings.NewReader(b)), Request: r}, nil
+			})},
+		},
+		BaseURL: "https://api.openai.com/v1",
+	}
+	got, err := c.Moderate(t.Context(), "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"hate", "violence"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	t.Setenv("OPENAI_ORG_ID", "org-env")
+	t.Setenv("OPENAI_PROJECT_ID", "")
+	h := Headers("key", "", "")
+	if got := h.Get("Authorization"); got != "Bearer key" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := h.Get("OpenAI-Organization"); got != "org-env" {
+		t.Errorf("OpenAI-Organization = %q", got)
+	}
+	if _, ok := h["Openai-Project"]; ok {
+		t.Errorf("unexpected OpenAI-Project %q", h.Get("OpenAI-Project"))
+	}
+	h = Headers("key", "org-1", "proj_1")
+	if got := h.Get("OpenAI-Organization"); got != "org-1" {
+		t.Errorf("OpenAI-Organization = %q", got)
+	}
+	if got := h.Get("OpenAI-Project"); got != "proj_1" {
+		t.Errorf("OpenAI-Project = %q", got)
+	}
+}
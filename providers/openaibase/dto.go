@@ -15,7 +15,10 @@ package openaibase
 import (
 	"errors"
 	"fmt"
+	"mime/multipart"
+	"net/textproto"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -81,6 +84,14 @@ func (r ReasoningEffort) Validate() error {
 	}
 }
 
+// ReasoningEffortFrom converts the provider agnostic genai.ReasoningEffort.
+func ReasoningEffortFrom(r genai.ReasoningEffort) ReasoningEffort {
+	if r == genai.ReasoningEffortOff {
+		return ReasoningEffortNone
+	}
+	return ReasoningEffort(r)
+}
+
 // Background is only supported on gpt-image-1.
 type Background string
 
@@ -161,6 +172,102 @@ func (i *ImageRequest) Init(msg *genai.Message, model string, opts ...genai.GenO
 			if v.Height != 0 && v.Width != 0 {
 				i.Size = fmt.Sprintf("%dx%d", v.Width, v.Height)
 			}
+			i.N = int64(v.Count)
+		default:
+			return &base.ErrNotSupported{Options: []string{internal.TypeName(opt)}}
+		}
+	}
+	return nil
+}
+
+// ImageEditRequest is documented at https://platform.openai.com/docs/api-reference/images/createEdit
+//
+// It is sent as a multipart form.
+type ImageEditRequest struct {
+	Prompt         string
+	Model          string
+	Images         []ImageFile // gpt-image-1 accepts up to 16 images. dall-e-2 accepts one square png.
+	Mask           ImageFile   // Optional png whose fully transparent areas indicate where to edit.
+	Background     Background
+	N              int64
+	Quality        string
+	ResponseFormat string
+	Size           string
+	User           string
+}
+
+// ImageFile is an image uploaded in a multipart form.
+type ImageFile struct {
+	Filename string
+	MimeType string
+	Data     []byte
+}
+
+// Init initializes the request from the given parameters.
+//
+// Each Doc in the message is an image to edit, except the one named "mask" with any extension, e.g.
+// "mask.png", which is used as the mask.
+func (i *ImageEditRequest) Init(msg *genai.Message, model string, opts ...genai.GenOption) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	for j := range msg.Requests {
+		d := &msg.Requests[j].Doc
+		if d.IsZero() {
+			continue
+		}
+		if d.URL != "" {
+			return errors.New("image URLs are not supported, pass the image data")
+		}
+		mimeType, data, err := d.Read(50 * 1024 * 1024)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(mimeType, "image/") {
+			return fmt.Errorf("unsupported mime type %q, only images can be edited", mimeType)
+		}
+		name := d.GetFilename()
+		f := ImageFile{Filename: name, MimeType: mimeType, Data: data}
+		if strings.TrimSuffix(name, filepath.Ext(name)) == "mask" {
+			if len(i.Mask.Data) != 0 {
+				return errors.New("only one mask can be passed as input")
+			}
+			i.Mask = f
+		} else {
+			i.Images = append(i.Images, f)
+		}
+	}
+	if len(i.Images) == 0 {
+		return errors.New("an image to edit is required")
+	}
+	i.Prompt = msg.String()
+	i.Model = model
+	switch model {
+	case "dall-e-2":
+		if len(i.Images) > 1 {
+			return errors.New("dall-e-2 only supports one image")
+		}
+		// We assume dall-e-2 is only used for smoke testing, so use the smallest image.
+		i.Size = "256x256"
+		if len(i.Prompt) > 1000 {
+			i.Prompt = i.Prompt[:1000]
+		}
+		i.ResponseFormat = "b64_json"
+	default:
+		// gpt-image-1 only returns b64_json.
+	}
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return err
+		}
+		switch v := opt.(type) {
+		case *GenOptionImage:
+			i.Background = v.Background
+		case *genai.GenOptionImage:
+			if v.Height != 0 && v.Width != 0 {
+				i.Size = fmt.Sprintf("%dx%d", v.Width, v.Height)
+			}
+			i.N = int64(v.Count)
 		default:
 			return &base.ErrNotSupported{Options: []string{internal.TypeName(opt)}}
 		}
@@ -168,6 +275,79 @@ func (i *ImageRequest) Init(msg *genai.Message, model string, opts ...genai.GenO
 	return nil
 }
 
+// Write writes the request as multipart form fields.
+func (i *ImageEditRequest) Write(w *multipart.Writer) error {
+	field := "image"
+	if len(i.Images) > 1 {
+		field = "image[]"
+	}
+	for j := range i.Images {
+		if err := writeFormFile(w, field, &i.Images[j]); err != nil {
+			return err
+		}
+	}
+	if len(i.Mask.Data) != 0 {
+		if err := writeFormFile(w, "mask", &i.Mask); err != nil {
+			return err
+		}
+	}
+	for _, f := range [...][2]string{
+		{"prompt", i.Prompt},
+		{"model", i.Model},
+		{"background", string(i.Background)},
+		{"quality", i.Quality},
+		{"response_format", i.ResponseFormat},
+		{"size", i.Size},
+		{"user", i.User},
+	} {
+		if f[1] != "" {
+			if err := w.WriteField(f[0], f[1]); err != nil {
+				return err
+			}
+		}
+	}
+	if i.N != 0 {
+		if err := w.WriteField("n", strconv.FormatInt(i.N, 10)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFormFile writes a file part with its mime type, as the API rejects application/octet-stream.
+func writeFormFile(w *multipart.Writer, field string, f *ImageFile) error {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, strings.ReplaceAll(f.Filename, `"`, "")))
+	h.Set("Content-Type", f.MimeType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(f.Data)
+	return err
+}
+
+// ModerationRequest is documented at https://platform.openai.com/docs/api-reference/moderations/create
+type ModerationRequest struct {
+	Input string `json:"input"`
+	Model string `json:"model,omitzero"` // Defaults to "omni-moderation-latest".
+}
+
+// ModerationResponse is the provider-specific moderation response.
+type ModerationResponse struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []ModerationResult `json:"results"`
+}
+
+// ModerationResult is the moderation result for one input.
+type ModerationResult struct {
+	Flagged                   bool                `json:"flagged"`
+	Categories                map[string]bool     `json:"categories"`
+	CategoryScores            map[string]float64  `json:"category_scores"`
+	CategoryAppliedInputTypes map[string][]string `json:"category_applied_input_types"`
+}
+
 // ImageResponse is the provider-specific image generation response.
 type ImageResponse struct {
 	Created base.TimeS        `json:"created"`
@@ -250,6 +430,14 @@ func (m *Model) Context() int64 {
 	return 0
 }
 
+// GetCreated implements genai.ModelCreated.
+func (m *Model) GetCreated() time.Time {
+	if m.Created.IsZero() {
+		return time.Time{}
+	}
+	return m.Created.AsTime()
+}
+
 // ModelsResponse represents the response structure for OpenAI models listing.
 type ModelsResponse struct {
 	Object string  `json:"object"` // list
@@ -306,6 +494,99 @@ type FileListResponse struct {
 	Object string `json:"object"` // "list"
 }
 
+// VectorStoreRequest is documented at https://platform.openai.com/docs/api-reference/vector-stores/create
+type VectorStoreRequest struct {
+	Name         string            `json:"name,omitzero"`
+	Description  string            `json:"description,omitzero"`
+	FileIDs      []string          `json:"file_ids,omitzero"`
+	ExpiresAfter ExpiresAfter      `json:"expires_after,omitzero"`
+	Metadata     map[string]string `json:"metadata,omitzero"`
+}
+
+// ExpiresAfter is the expiration policy of a vector store.
+type ExpiresAfter struct {
+	Anchor string `json:"anchor,omitzero"` // "last_active_at"
+	Days   int64  `json:"days,omitzero"`
+}
+
+// VectorStore is documented at https://platform.openai.com/docs/api-reference/vector-stores/object
+type VectorStore struct {
+	ID          string     `json:"id"`
+	Object      string     `json:"object"` // "vector_store"
+	CreatedAt   base.TimeS `json:"created_at"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	UsageBytes  int64      `json:"usage_bytes"`
+	FileCounts  struct {
+		InProgress int64 `json:"in_progress"`
+		Completed  int64 `json:"completed"`
+		Failed     int64 `json:"failed"`
+		Cancelled  int64 `json:"cancelled"`
+		Total      int64 `json:"total"`
+	} `json:"file_counts"`
+	Status       string            `json:"status"` // "expired", "in_progress", "completed"
+	ExpiresAfter ExpiresAfter      `json:"expires_after"`
+	ExpiresAt    base.TimeS        `json:"expires_at"`
+	LastActiveAt base.TimeS        `json:"last_active_at"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+// GetID implements genai.VectorStoreEntry.
+func (v *VectorStore) GetID() string {
+	return v.ID
+}
+
+// GetDisplayName implements genai.VectorStoreEntry.
+func (v *VectorStore) GetDisplayName() string {
+	return v.Name
+}
+
+// VectorStoreListResponse is documented at https://platform.openai.com/docs/api-reference/vector-stores/list
+type VectorStoreListResponse struct {
+	Object  string        `json:"object"` // "list"
+	Data    []VectorStore `json:"data"`
+	FirstID string        `json:"first_id"`
+	LastID  string        `json:"last_id"`
+	HasMore bool          `json:"has_more"`
+}
+
+// VectorStoreFileRequest is documented at https://platform.openai.com/docs/api-reference/vector-stores-files/createFile
+type VectorStoreFileRequest struct {
+	FileID     string         `json:"file_id"`
+	Attributes map[string]any `json:"attributes,omitzero"`
+}
+
+// VectorStoreFile is documented at https://platform.openai.com/docs/api-reference/vector-stores-files/file-object
+type VectorStoreFile struct {
+	ID            string     `json:"id"`
+	Object        string     `json:"object"` // "vector_store.file"
+	UsageBytes    int64      `json:"usage_bytes"`
+	CreatedAt     base.TimeS `json:"created_at"`
+	VectorStoreID string     `json:"vector_store_id"`
+	Status        string     `json:"status"` // "in_progress", "completed", "cancelled", "failed"
+	LastError     struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"last_error"`
+	ChunkingStrategy struct {
+		Type   string `json:"type"` // "static", "other"
+		Static struct {
+			MaxChunkSizeTokens int64 `json:"max_chunk_size_tokens"`
+			ChunkOverlapTokens int64 `json:"chunk_overlap_tokens"`
+		} `json:"static"`
+	} `json:"chunking_strategy"`
+	Attributes map[string]any `json:"attributes"`
+}
+
+// VectorStoreFileListResponse is documented at https://platform.openai.com/docs/api-reference/vector-stores-files/listFiles
+type VectorStoreFileListResponse struct {
+	Object  string            `json:"object"` // "list"
+	Data    []VectorStoreFile `json:"data"`
+	FirstID string            `json:"first_id"`
+	LastID  string            `json:"last_id"`
+	HasMore bool              `json:"has_more"`
+}
+
 // BatchRequest is documented at https://platform.openai.com/docs/api-reference/batch/create
 type BatchRequest struct {
 	CompletionWindow string            `json:"completion_window"` // Must be "24h"
@@ -103,6 +103,12 @@ type Client struct {
 	shared openaibase.Client
 }
 
+// ProviderOptionOrganization selects the organization billed for the requests.
+type ProviderOptionOrganization = openaibase.ProviderOptionOrganization
+
+// ProviderOptionProject selects the project billed for the requests.
+type ProviderOptionProject = openaibase.ProviderOptionProject
+
 // New creates a new client to talk to the OpenAI platform API.
 //
 // If ProviderOptionAPIKey is not provided, it tries to load it from the OPENAI_API_KEY environment variable.
@@ -112,6 +118,11 @@ type Client struct {
 // To use multiple models, create multiple clients.
 // Use one of the model from https://platform.openai.com/docs/models
 //
+// Use ProviderOptionOrganization and ProviderOptionProject to select which organization and project are
+// billed.
+//
+// The proxy defaults to the OPENAI_PROXY environment variable, see genai.ProviderOptionProxyURL.
+//
 // # Documents
 //
 // OpenAI supports many types of documents, listed at
@@ -120,7 +131,15 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	var org ProviderOptionOrganization
+	var project ProviderOptionProject
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -137,8 +156,24 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case ProviderOptionOrganization:
+			org = v
+		case ProviderOptionProject:
+			project = v
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -164,7 +199,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	default:
 		return nil, fmt.Errorf("unexpected option Modalities %s, only audio, image or text are supported", modalities)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "OPENAI_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -174,14 +209,17 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			GenSyncURL:      baseURL + "/chat/completions",
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProcessHeaders:  openaibase.ProcessHeaders,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				// OpenAI error message prints the api key URL already.
 				APIKeyURL: "",
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
-						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
+						Header:    openaibase.Headers(apiKey, org, project),
 						Transport: &roundtrippers.RequestID{Transport: t},
 					},
 				},
@@ -192,6 +230,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		Impl:            &c.impl.ProviderBase,
 		BaseURL:         baseURL,
 		PreloadedModels: preloadedModels,
+		ModelSelector:   selector,
 	}
 	if err == nil {
 		switch model {
@@ -209,7 +248,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			}
 			switch mod {
 			case genai.ModalityText:
-				if c.impl.Model, err = c.shared.SelectBestTextModel(ctx, model); err != nil {
+				if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.shared.SelectBestTextModel); err != nil {
 					return nil, err
 				}
 				c.impl.OutputModalities = genai.Modalities{mod}
@@ -307,6 +346,7 @@ func (c *Client) Name() string {
 // GenSyncRaw provides access to the raw API.
 func (c *Client) GenSyncRaw(ctx context.Context, in *ChatRequest, out *ChatResponse) error {
 	out.audioFormat = in.Audio.Format
+	out.omitTranscript = in.omitTranscript
 	return c.impl.GenSyncRaw(ctx, in, out)
 }
 
@@ -373,6 +413,7 @@ func (c *Client) PokeResult(ctx context.Context, id genai.Job) (genai.Result, er
 			}
 			var err4 error
 			res, err4 = out.Response.Body.ToResult()
+			err4 = c.impl.StrictErr(err4)
 			if err4 == nil && out.Error.Message != "" {
 				err4 = fmt.Errorf("error %s: %s", out.Error.Code, out.Error.Message)
 			}
@@ -465,7 +506,7 @@ func (c *Client) FilesListRaw(ctx context.Context) ([]File, error) {
 }
 
 // ProcessStream converts the raw packets from the streaming API into Reply fragments.
-func makeProcessStream(audioFormat string) func(iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error)) {
+func makeProcessStream(audioFormat string, omitTranscript bool) func(iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error)) {
 	return func(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error)) {
 		var finalErr error
 		u := genai.Usage{}
@@ -489,9 +530,15 @@ func makeProcessStream(audioFormat string) func(iter.Seq[ChatStreamChunkResponse
 					if len(pkt.Choices) != 1 {
 						continue
 					}
+					if omitTranscript {
+						pkt.Choices[0].Delta.Audio.Transcript = ""
+					}
 					l = append(l, pkt.Choices[0].Logprobs.To()...)
 					if fr := pkt.Choices[0].FinishReason; fr != "" {
-						u.FinishReason = fr.ToFinishReason()
+						var err error
+						if u.FinishReason, err = fr.ToFinishReason(); err != nil {
+							finalErr = errors.Join(finalErr, err)
+						}
 					}
 					switch role := pkt.Choices[0].Delta.Role; role {
 					case "", "assistant":
@@ -631,7 +678,7 @@ func makeProcessStream(audioFormat string) func(iter.Seq[ChatStreamChunkResponse
 
 // ProcessStream is the default stream processor (no audio format).
 func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error)) {
-	return makeProcessStream("")(chunks)
+	return makeProcessStream("", false)(chunks)
 }
 
 // Capabilities implements genai.Provider.
@@ -642,6 +689,11 @@ func (c *Client) Capabilities() genai.ProviderCapabilities {
 	}
 }
 
+// ImageTokens implements genai.ProviderImageTokens.
+func (c *Client) ImageTokens(width, height int) int64 {
+	return openaibase.ImageTokens(c.impl.Model, width, height)
+}
+
 // ModelID implements genai.Provider.
 //
 // It returns the selected model ID.
@@ -649,6 +701,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -672,8 +729,32 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 	return c.shared.ListModels(ctx)
 }
 
+// Moderate returns the sorted categories the text is flagged for by the moderation model.
+//
+// It implements safety.Moderator.
+func (c *Client) Moderate(ctx context.Context, text string) ([]string, error) {
+	return c.shared.Moderate(ctx, text)
+}
+
+// ModerateRaw classifies text with the moderation endpoint.
+func (c *Client) ModerateRaw(ctx context.Context, in *ModerationRequest, out *ModerationResponse) error {
+	return c.shared.ModerateRaw(ctx, in, out)
+}
+
 // GenSync implements genai.Provider.
 func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	start := time.Now()
+	sink, opts := base.ExtractOutputOption(opts)
+	res, err := c.genSync(ctx, msgs, opts...)
+	if err == nil {
+		err = sink.Redirect(&res)
+	}
+	c.impl.LogResult(ctx, "GenSync", start, &res, err)
+	return res, err
+}
+
+func (c *Client) genSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	ctx, opts = base.ExtractRawOptions(ctx, opts)
 	if c.shared.IsImage() || c.shared.IsVideo() {
 		if len(msgs) != 1 {
 			return genai.Result{}, errors.New("must pass exactly one Message")
@@ -690,7 +771,7 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 		return genai.Result{}, err
 	}
 	res, err := out.ToResult()
-	if err != nil {
+	if err = c.impl.StrictErr(err); err != nil {
 		return res, err
 	}
 	if err := res.Validate(); err != nil {
@@ -704,9 +785,14 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 
 // GenStream implements genai.Provider.
 func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	ctx, opts = base.ExtractRawOptions(ctx, opts)
+	if err := base.CheckStreamCandidates(opts); err != nil {
+		return func(yield func(genai.Reply) bool) {}, func() (genai.Result, error) { return genai.Result{}, err }
+	}
 	if c.shared.IsImage() || c.shared.IsVideo() {
 		return base.SimulateStream(ctx, c, msgs, opts...)
 	}
+	sink, opts := base.ExtractOutputOption(opts)
 	// Build the request ourselves so makeProcessStream can use the audio format.
 	in := &ChatRequest{}
 	if err := in.Init(msgs, c.impl.Model, opts...); err != nil {
@@ -720,10 +806,11 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 	res := genai.Result{}
 	var finalErr error
 	fnFragments := func(yield func(genai.Reply) bool) {
+		start := time.Now()
 		chunks, finish := c.GenStreamRaw(ctx, in)
 		// Capture headers immediately after the HTTP call, before iterating.
 		lastResp := c.impl.LastResponseHeaders()
-		fragments, finish2 := makeProcessStream(in.Audio.Format)(chunks)
+		fragments, finish2 := makeProcessStream(in.Audio.Format, in.omitTranscript)(chunks)
 		sent := false
 		for f := range fragments {
 			if f.IsZero() {
@@ -733,8 +820,8 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 				finalErr = &internal.BadError{Err: err}
 				break
 			}
-			if err := res.Accumulate(&f); err != nil {
-				finalErr = &internal.BadError{Err: err}
+			if err := sink.Accumulate(&res, &f); err != nil {
+				finalErr = err
 				break
 			}
 			sent = true
@@ -747,7 +834,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		}
 		var err error
 		res.Usage, res.Logprobs, err = finish2()
-		if finalErr == nil {
+		if err = c.impl.StrictErr(err); finalErr == nil {
 			finalErr = err
 		}
 		if !sent && finalErr == nil {
@@ -756,10 +843,15 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		if lastResp != nil {
 			res.Usage.Limits = openaibase.ProcessHeaders(lastResp)
 		}
+		c.impl.LogResult(ctx, "GenStream", start, &res, finalErr)
 	}
 	return fnFragments, func() (genai.Result, error) {
 		return res, finalErr
 	}
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+	_ genai.ProviderImageTokens    = &Client{}
+)
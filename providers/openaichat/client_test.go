@@ -9,16 +9,19 @@ package openaichat_test
 import (
 	"context"
 	_ "embed"
+	"errors"
+	"io"
 	"iter"
 	"net/http"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/base"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/openaichat"
 	"github.com/maruel/genai/scoreboard"
@@ -40,6 +43,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return openaichat.New(t.Context(), opts...)
 }
 
@@ -111,6 +115,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				provOpts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, provOpts...)
 			}
+			provOpts = append(provOpts, genai.ProviderOptionStrict(true))
 			c, err := openaichat.New(t.Context(), provOpts...)
 			if err != nil {
 				t.Fatal(err)
@@ -288,6 +293,61 @@ func (i *injectReasoning) GenStream(ctx context.Context, msgs genai.Messages, op
 	return res, err
 }
 
-func init() {
-	internal.BeLenient = false
+func TestGenStreamCandidates(t *testing.T) {
+	// Streamed fragments carry a single candidate so the request must not be sent.
+	c, err := getClientInner(t, func(http.RoundTripper) http.RoundTripper {
+		return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			t.Errorf("unexpected request %s", r.URL)
+			return nil, errors.New("unexpected request")
+		})
+	}, genai.ProviderOptionModel("gpt-4o-mini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, finish := c.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("Hi")}, &genai.GenOptionText{N: 2})
+	var e *base.ErrNotSupported
+	if _, err := finish(); !errors.As(err, &e) {
+		t.Fatalf("want ErrNotSupported, got %v", err)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	// An unknown finish reason is only an error for a strict client.
+	const resp = `{"id":"1","object":"chat.completion","created":1,"model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"brand_new"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`
+	for _, strict := range []bool{false, true} {
+		t.Run(strconv.FormatBool(strict), func(t *testing.T) {
+			c, err := openaichat.New(t.Context(),
+				genai.ProviderOptionAPIKey("<insert_api_key_here>"),
+				genai.ProviderOptionModel("gpt-4o-mini"),
+				genai.ProviderOptionStrict(strict),
+				genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+					return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Header:     http.Header{"Content-Type": {"application/json"}},
+							Body:       io.NopCloser(strings.NewReader(resp)),
+							Request:    r,
+						}, nil
+					})
+				}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Hi")})
+			if got := res.String(); got != "Hello" {
+				t.Errorf("want %q, got %q", "Hello", got)
+			}
+			if res.Usage.FinishReason != "brand_new" {
+				t.Errorf("unexpected finish reason %q", res.Usage.FinishReason)
+			}
+			var unhandled *base.ErrUnhandled
+			if strict {
+				if !errors.As(err, &unhandled) {
+					t.Fatalf("want ErrUnhandled, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
 }
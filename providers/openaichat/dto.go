@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"mime"
+	"sort"
 	"strings"
 
 	"github.com/maruel/genai"
@@ -82,6 +83,12 @@ type (
 	ImageChoiceData = openaibase.ImageChoiceData
 	// GenOptionImage is an alias to the shared OpenAI image generation options.
 	GenOptionImage = openaibase.GenOptionImage
+	// ModerationRequest is an alias to the shared OpenAI moderation request type.
+	ModerationRequest = openaibase.ModerationRequest
+	// ModerationResponse is an alias to the shared OpenAI moderation response type.
+	ModerationResponse = openaibase.ModerationResponse
+	// ModerationResult is an alias to the shared OpenAI moderation result type.
+	ModerationResult = openaibase.ModerationResult
 	// Model is an alias to the shared OpenAI model type.
 	Model = openaibase.Model
 	// ModelsResponse is an alias to the shared OpenAI models response type.
@@ -169,6 +176,8 @@ type ChatRequest struct {
 	ParallelToolCalls bool              `json:"parallel_tool_calls,omitzero"`
 	User              string            `json:"user,omitzero"`
 	WebSearchOptions  *WebSearchOptions `json:"web_search_options,omitzero"`
+
+	omitTranscript bool // Set by Init from genai.GenOptionAudio; not sent to the API.
 }
 
 // Init initializes the provider specific completion request with the generic completion request.
@@ -199,6 +208,9 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			}
 			sp = v.SystemPrompt
 		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
 			if err := c.initOptionsTools(v, model); err != nil {
 				errs = append(errs, err)
 			}
@@ -207,6 +219,7 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				c.WebSearchOptions = &WebSearchOptions{
 					SearchContextSize: "high",
 				}
+				unsupported = append(unsupported, c.WebSearchOptions.init(v.SearchOptions)...)
 			}
 			if v.Fetch {
 				errs = append(errs, errors.New("unsupported GenOptionWeb.Fetch"))
@@ -222,9 +235,17 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				c.Audio.Format = "mp3"
 			}
 		case *genai.GenOptionAudio:
-			c.Modalities = []string{"text", "audio"}
-			c.Audio.Voice = "alloy"
-			c.Audio.Format = "mp3"
+			if v.InputLanguage != "" {
+				unsupported = append(unsupported, "GenOptionAudio.InputLanguage")
+			}
+			c.omitTranscript = v.OmitTranscript
+			if v.TextOnly {
+				c.Modalities = []string{"text"}
+			} else {
+				c.Modalities = []string{"text", "audio"}
+				c.Audio.Voice = "alloy"
+				c.Audio.Format = "mp3"
+			}
 		case genai.GenOptionSeed:
 			if strings.HasPrefix(model, "gpt-4o-") && strings.Contains(model, "-search") {
 				unsupported = append(unsupported, "GenOptionSeed")
@@ -328,6 +349,13 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText, model string) ([]s
 		// Track this as an unsupported feature that can be ignored
 		unsupported = append(unsupported, "GenOptionText.TopK")
 	}
+	if v.Grammar != "" {
+		unsupported = append(unsupported, "GenOptionText.Grammar")
+	}
+	if v.ReasoningEffort != "" && c.ReasoningEffort == "" {
+		c.ReasoningEffort = openaibase.ReasoningEffortFrom(v.ReasoningEffort)
+	}
+	c.N = v.N
 	c.Stop = v.Stop
 	if v.DecodeAs != nil {
 		c.ResponseFormat.Type = "json_schema"
@@ -335,6 +363,9 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText, model string) ([]s
 		c.ResponseFormat.JSONSchema.Name = "response"
 		c.ResponseFormat.JSONSchema.Strict = true
 		s, err := v.DecodeSchema()
+		if err == nil {
+			s, err = base.SchemaDialectOpenAIStrict.Lower(s)
+		}
 		if err != nil {
 			return unsupported, err
 		}
@@ -346,7 +377,8 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText, model string) ([]s
 }
 
 func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools, model string) error {
-	if len(v.Tools) != 0 {
+	tools := v.AllowedTools()
+	if len(tools) != 0 {
 		// TODO: Determine exactly which models do not support this.
 		if model != "o4-mini" {
 			c.ParallelToolCalls = true
@@ -359,8 +391,8 @@ func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools, model string) er
 		case genai.ToolCallNone:
 			c.ToolChoice = "none"
 		}
-		c.Tools = make([]Tool, len(v.Tools))
-		for i, t := range v.Tools {
+		c.Tools = make([]Tool, len(tools))
+		for i, t := range tools {
 			c.Tools[i].Type = "function"
 			c.Tools[i].Function.Name = t.Name
 			c.Tools[i].Function.Description = t.Description
@@ -380,13 +412,45 @@ type WebSearchOptions struct {
 	UserLocation      struct {
 		Type        string `json:"type,omitzero"` // "approximate"
 		Approximate struct {
-			Country string `json:"country,omitzero"` // "GB"
-			City    string `json:"city,omitzero"`    // "London"
-			Region  string `json:"region,omitzero"`  // "London"
+			Country  string `json:"country,omitzero"`  // "GB"
+			City     string `json:"city,omitzero"`     // "London"
+			Region   string `json:"region,omitzero"`   // "London"
+			Timezone string `json:"timezone,omitzero"` // "Europe/London"
 		} `json:"approximate,omitzero"`
 	} `json:"user_location,omitzero"`
 }
 
+// init applies the generic web search options. It returns the unsupported options.
+func (w *WebSearchOptions) init(o *genai.WebSearchOptions) []string {
+	if o == nil {
+		return nil
+	}
+	var unsupported []string
+	if len(o.AllowedDomains) != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.AllowedDomains")
+	}
+	if len(o.BlockedDomains) != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.BlockedDomains")
+	}
+	if o.MaxSearches != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.MaxSearches")
+	}
+	if o.ContextSize != "" {
+		w.SearchContextSize = o.ContextSize
+	}
+	if l := &o.UserLocation; !l.IsZero() {
+		w.UserLocation.Type = "approximate"
+		w.UserLocation.Approximate.Country = l.Country
+		w.UserLocation.Approximate.City = l.City
+		w.UserLocation.Approximate.Region = l.Region
+		w.UserLocation.Approximate.Timezone = l.Timezone
+		if l.HasLatLng() {
+			unsupported = append(unsupported, "GenOptionWeb.SearchOptions.UserLocation.Latitude")
+		}
+	}
+	return unsupported
+}
+
 // ============================================================
 // Chat message types.
 // ============================================================
@@ -527,21 +591,26 @@ type Contents []Content
 // UnmarshalJSON implements json.Unmarshaler.
 //
 // OpenAI replies with content as a string.
+//
+// When the payload is neither, the error from decoding the list is returned if it looks like a list, so the
+// caller sees the unknown field or type mismatch instead of a generic string mismatch.
 func (c *Contents) UnmarshalJSON(b []byte) error {
 	if bytes.Equal(b, []byte("null")) {
 		*c = nil
 		return nil
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode((*[]Content)(c)); err == nil {
+	var l []Content
+	errList := json.Unmarshal(b, &l)
+	if errList == nil {
+		*c = l
 		return nil
 	}
 
 	s := ""
 	if err := json.Unmarshal(b, &s); err != nil {
+		if t := bytes.TrimSpace(b); len(t) != 0 && t[0] == '[' {
+			return errList
+		}
 		return err
 	}
 	*c = Contents{{Type: ContentText, Text: s}}
@@ -606,6 +675,7 @@ func (c *Content) FromRequest(in *genai.Request) error {
 		switch {
 		case strings.HasPrefix(mimeType, "image/"):
 			c.Type = ContentImageURL
+			c.ImageURL.Detail = string(in.Doc.Detail)
 			if in.Doc.URL == "" {
 				c.ImageURL.URL = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
 			} else {
@@ -680,6 +750,7 @@ func (c *Content) FromReply(in *genai.Reply) error {
 		switch {
 		case strings.HasPrefix(mimeType, "image/"):
 			c.Type = ContentImageURL
+			c.ImageURL.Detail = string(in.Doc.Detail)
 			if in.Doc.URL == "" {
 				c.ImageURL.URL = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
 			} else {
@@ -835,7 +906,8 @@ type ChatResponse struct {
 	ServiceTier       string     `json:"service_tier"`
 	SystemFingerprint string     `json:"system_fingerprint"`
 
-	audioFormat string // Set by GenSyncRaw after Init; used by ToResult for Doc filenames.
+	audioFormat    string // Set by GenSyncRaw after Init; used by ToResult for Doc filenames.
+	omitTranscript bool   // Set by GenSyncRaw after Init; used by ToResult to drop the audio transcript.
 }
 
 // ToResult converts the response to a genai.Result.
@@ -850,11 +922,17 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 			ServiceTier:       c.ServiceTier,
 		},
 	}
-	if len(c.Choices) != 1 {
-		return out, fmt.Errorf("server returned an unexpected number of choices, expected 1, got %d", len(c.Choices))
+	if len(c.Choices) == 0 {
+		return out, errors.New("server returned no choice")
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-	err := c.Choices[0].Message.To(&out.Message)
+	// The server may not return the choices in order. The first one is the result, the rest are candidates.
+	sort.SliceStable(c.Choices, func(i, j int) bool { return c.Choices[i].Index < c.Choices[j].Index })
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+	if c.omitTranscript {
+		c.Choices[0].Message.Audio.Transcript = ""
+	}
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
 	// Fix audio Doc filenames to match the requested format.
 	if c.audioFormat != "" {
 		for i := range out.Replies {
@@ -864,6 +942,14 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 		}
 	}
 	out.Logprobs = c.Choices[0].Logprobs.To()
+	if len(c.Choices) > 1 {
+		out.Candidates = make([]genai.Message, len(c.Choices)-1)
+		for i := 1; i < len(c.Choices); i++ {
+			if err2 := c.Choices[i].Message.To(&out.Candidates[i-1]); err2 != nil {
+				err = errors.Join(err, fmt.Errorf("choice #%d: %w", i, err2))
+			}
+		}
+	}
 	return out, err
 }
 
@@ -879,21 +965,20 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishContentFilter:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -5,11 +5,17 @@
 package openaichat
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/internal/sse"
 )
 
 func testToolOption() *genai.GenOptionTools {
@@ -48,4 +54,217 @@ func TestChatRequest(t *testing.T) {
 			t.Fatalf("got unsupported options %#v, want GenOptionText.ReasoningEffort", uerr.Options)
 		}
 	})
+	t.Run("Init/tools/denied tools are not sent", func(t *testing.T) {
+		opts := testToolOption()
+		opts.Tools = append(opts.Tools, genai.ToolDef{Name: "delete", Description: "Deletes", InputSchemaOverride: genai.JSONSchema(`{"type":"object"}`)})
+		opts.Deny = []string{"delete"}
+		var r ChatRequest
+		if err := r.Init(genai.Messages{genai.NewTextMessage("calculate")}, "gpt-4.1", opts); err != nil {
+			t.Fatal(err)
+		}
+		if len(r.Tools) != 1 || r.Tools[0].Function.Name != "square_root" {
+			t.Fatalf("unexpected tools %#v", r.Tools)
+		}
+	})
+	t.Run("Init/generic reasoning effort", func(t *testing.T) {
+		var r ChatRequest
+		err := r.Init(genai.Messages{genai.NewTextMessage("hi")}, "gpt-5.6-luna", &genai.GenOptionText{ReasoningEffort: genai.ReasoningEffortOff})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.ReasoningEffort != ReasoningEffortNone {
+			t.Fatalf("got %q, want %q", r.ReasoningEffort, ReasoningEffortNone)
+		}
+	})
+
+	t.Run("Init/provider reasoning effort takes precedence", func(t *testing.T) {
+		var r ChatRequest
+		err := r.Init(genai.Messages{genai.NewTextMessage("hi")}, "gpt-5.6-luna", &GenOptionText{ReasoningEffort: ReasoningEffortHigh}, &genai.GenOptionText{ReasoningEffort: genai.ReasoningEffortLow})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.ReasoningEffort != ReasoningEffortHigh {
+			t.Fatalf("got %q, want %q", r.ReasoningEffort, ReasoningEffortHigh)
+		}
+	})
+
+	t.Run("Init/DecodeAs/optional fields are nullable", func(t *testing.T) {
+		type reply struct {
+			Name string `json:"name"`
+			Note string `json:"note,omitempty"`
+		}
+		var r ChatRequest
+		err := r.Init(genai.Messages{genai.NewTextMessage("hi")}, "gpt-5.6-luna", &genai.GenOptionText{DecodeAs: &reply{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","additionalProperties":false,"properties":{"name":{"type":"string"},"note":{"type":["string","null"]}},"required":["name","note"],"type":"object"}`
+		if got := string(r.ResponseFormat.JSONSchema.Schema); got != want {
+			t.Fatalf("got %s\nwant %s", got, want)
+		}
+	})
+
+	t.Run("Init/image detail", func(t *testing.T) {
+		var r ChatRequest
+		msgs := genai.Messages{{Requests: []genai.Request{{Doc: genai.Doc{URL: "https://example.com/image.jpg", Detail: genai.ImageDetailLow}}}}}
+		if err := r.Init(msgs, "gpt-5.6-luna"); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Messages[0].Content[0].ImageURL.Detail; got != "low" {
+			t.Fatalf("got %q, want %q", got, "low")
+		}
+	})
+
+	t.Run("Init/audio", func(t *testing.T) {
+		audioIn := genai.Messages{{Requests: []genai.Request{{Doc: genai.Doc{Filename: "a.mp3", Src: strings.NewReader("ID3")}}}}}
+		t.Run("TextOnly", func(t *testing.T) {
+			var r ChatRequest
+			if err := r.Init(audioIn, "gpt-4o-audio-preview", &genai.GenOptionAudio{TextOnly: true}); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(r.Modalities, []string{"text"}) || r.Audio.Format != "" {
+				t.Fatalf("got modalities %q, audio %+v", r.Modalities, r.Audio)
+			}
+		})
+		t.Run("default", func(t *testing.T) {
+			var r ChatRequest
+			if err := r.Init(audioIn, "gpt-4o-audio-preview", &genai.GenOptionAudio{}); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(r.Modalities, []string{"text", "audio"}) || r.Audio.Format != "mp3" {
+				t.Fatalf("got modalities %q, audio %+v", r.Modalities, r.Audio)
+			}
+		})
+		t.Run("InputLanguage", func(t *testing.T) {
+			var r ChatRequest
+			err := r.Init(audioIn, "gpt-4o-audio-preview", &genai.GenOptionAudio{InputLanguage: "fr"})
+			uerr, ok := errors.AsType[*base.ErrNotSupported](err)
+			if !ok || len(uerr.Options) != 1 || uerr.Options[0] != "GenOptionAudio.InputLanguage" {
+				t.Fatalf("got %v, want ErrNotSupported for GenOptionAudio.InputLanguage", err)
+			}
+		})
+	})
+
+	t.Run("Init/DecodeAs/unsupported schema", func(t *testing.T) {
+		var r ChatRequest
+		js := genai.JSONSchema(`{"type":"object","properties":{"a":{"oneOf":[{"type":"string"},{"type":"integer"}]}},"required":["a"],"additionalProperties":false}`)
+		err := r.Init(genai.Messages{genai.NewTextMessage("hi")}, "gpt-5.6-luna", &genai.GenOptionText{DecodeAs: js})
+		if _, ok := errors.AsType[*base.ErrSchemaNotSupported](err); !ok {
+			t.Fatalf("got %v, want ErrSchemaNotSupported", err)
+		}
+	})
+}
+
+func TestChatResponse_omitTranscript(t *testing.T) {
+	for _, omit := range []bool{false, true} {
+		var c ChatResponse
+		if err := json.Unmarshal([]byte(`{"choices":[{"finish_reason":"stop","message":{"role":"assistant","audio":{"data":"SUQz","transcript":"Hello"}}}]}`), &c); err != nil {
+			t.Fatal(err)
+		}
+		c.omitTranscript = omit
+		res, err := c.ToResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "Hello"
+		if omit {
+			want = ""
+		}
+		if got := res.String(); got != want {
+			t.Errorf("omitTranscript=%t: got %q, want %q", omit, got, want)
+		}
+	}
+}
+
+func TestChatResponse_candidates(t *testing.T) {
+	t.Run("ordered", func(t *testing.T) {
+		var c ChatResponse
+		if err := json.Unmarshal([]byte(`{"choices":[{"finish_reason":"stop","message":{"role":"assistant","content":"Hello"}},{"index":1,"finish_reason":"stop","message":{"role":"assistant","content":"Hi"}}]}`), &c); err != nil {
+			t.Fatal(err)
+		}
+		res, err := c.ToResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.String(); got != "Hello" {
+			t.Errorf("got %q, want %q", got, "Hello")
+		}
+		if len(res.Candidates) != 1 || res.Candidates[0].String() != "Hi" {
+			t.Errorf("got candidates %#v", res.Candidates)
+		}
+	})
+	t.Run("out of order", func(t *testing.T) {
+		var c ChatResponse
+		if err := json.Unmarshal([]byte(`{"choices":[{"index":2,"finish_reason":"stop","message":{"role":"assistant","content":"Hey"}},{"index":0,"finish_reason":"length","message":{"role":"assistant","content":"Hello"}},{"index":1,"finish_reason":"stop","message":{"role":"assistant","content":"Hi"}}]}`), &c); err != nil {
+			t.Fatal(err)
+		}
+		res, err := c.ToResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.String(); got != "Hello" {
+			t.Errorf("got %q, want %q", got, "Hello")
+		}
+		if res.Usage.FinishReason != genai.FinishedLength {
+			t.Errorf("got finish reason %q, want %q", res.Usage.FinishReason, genai.FinishedLength)
+		}
+		if len(res.Candidates) != 2 || res.Candidates[0].String() != "Hi" || res.Candidates[1].String() != "Hey" {
+			t.Errorf("got candidates %#v", res.Candidates)
+		}
+	})
+}
+
+func FuzzContents_UnmarshalJSON(f *testing.F) {
+	for _, s := range []string{
+		`null`,
+		`"hello"`,
+		`""`,
+		`[]`,
+		`[{"type":"text","text":"hi"}]`,
+		`[{"type":"image_url","image_url":{"url":"https://example.com/a.png","detail":"low"}}]`,
+		`[{"type":"text","unknown":1}]`,
+		`[null]`,
+		`{"type":"text"}`,
+		`42`,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var c Contents
+		if err := json.Unmarshal(b, &c); err != nil {
+			return
+		}
+		// A successful decode must round trip.
+		out, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("failed to encode %q: %v", b, err)
+		}
+		var c2 Contents
+		if err := json.Unmarshal(out, &c2); err != nil {
+			t.Fatalf("failed to decode %q re-encoded from %q: %v", out, b, err)
+		}
+	})
+}
+
+func FuzzChatStreamChunkResponse(f *testing.F) {
+	for _, s := range []string{
+		"data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n",
+		"data: {\"choices\":[{\"delta\":{\"content\":null,\"tool_calls\":null}}],\"usage\":null}\n\n",
+		"data: {\"error\":{\"message\":\"boom\"}}\n\n",
+		": keep-alive\n\ndata: {}\n\n",
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		it, finish := sse.Process[ChatStreamChunkResponse](bytes.NewReader(b), &ErrorResponse{}, false)
+		for range it {
+		}
+		if err := finish(); err != nil {
+			if _, ok := errors.AsType[*internal.BadError](err); !ok {
+				if _, ok := errors.AsType[*ErrorResponse](err); !ok {
+					t.Fatalf("unexpected error type %T: %v", err, err)
+				}
+			}
+		}
+	})
 }
@@ -62,6 +62,10 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -80,6 +84,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			preloadedModels = []genai.Model(v)
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		case genai.ProviderOptionRemote:
 			remote = string(v)
 		default:
@@ -100,7 +112,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	case "", string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
 		model = ""
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -114,7 +126,8 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 				ModelOptional:    true,
 				OutputModalities: mod,
 				// It is always lenient by definition.
-				Lenient: true,
+				Lenient: lenient,
+				Log:     logger,
 				Client: http.Client{
 					Transport: &roundtrippers.RequestID{Transport: t},
 				},
@@ -189,7 +202,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 				}
 				if len(pkt.Choices) == 1 {
 					if pkt.Choices[0].FinishReason != "" {
-						u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+						var err error
+						if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+							finalErr = errors.Join(finalErr, err)
+						}
 					}
 					switch role := pkt.Choices[0].Delta.Role; role {
 					case "", "assistant":
@@ -211,7 +227,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					continue
 				}
 				if pkt.FinishReason != "" {
-					u.FinishReason = pkt.FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				m := pkt.Delta.Message
 				c := pkt.Delta.Message.Content
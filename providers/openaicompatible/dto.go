@@ -53,6 +53,15 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.TopK != 0 {
 				unsupported = append(unsupported, "GenOptionText.TopK")
 			}
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			if v.TopLogprobs > 0 {
 				unsupported = append(unsupported, "GenOptionText.TopLogprobs")
 			}
@@ -259,8 +268,9 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 		return out, fmt.Errorf("expected 1 choice, got %#v", c)
 	}
 	if len(c.Choices) == 1 {
-		out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-		err := c.Choices[0].Message.To(&out.Message)
+		var err error
+		out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+		err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
 		return out, err
 	}
 	m := c.Message2
@@ -273,8 +283,9 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	if err := m.To(&out.Message); err != nil {
 		return out, err
 	}
-	out.Usage.FinishReason = c.FinishReason.ToFinishReason()
-	return out, nil
+	var err error
+	out.Usage.FinishReason, err = c.FinishReason.ToFinishReason()
+	return out, err
 }
 
 // FinishReason is a provider-specific finish reason.
@@ -287,17 +298,16 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -16,10 +16,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"iter"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/maruel/roundtrippers"
 	"golang.org/x/net/websocket"
@@ -57,16 +59,117 @@ type GenOptionText struct {
 	Truncation Truncation
 	// PreviousResponseID enables server-side conversation state, avoiding re-transmitting full history.
 	PreviousResponseID string
+	// NoStore disables server-side response storage, e.g. for zero data retention.
+	//
+	// The reasoning state is then sent back encrypted with the full history instead of being referenced via
+	// the previous response.
+	NoStore bool
+	// Background runs the request in background mode. GenSync then polls the response until it completes,
+	// which is recommended for long-running models. Use genai.GenOptionPollInterval to change the polling
+	// interval, which defaults to 5 seconds.
+	//
+	// It requires server-side storage. It is enabled automatically for deep research models like
+	// o3-deep-research, which also get the web search tool when no other data source is specified.
+	Background bool
 }
 
 // Validate implements genai.Validatable.
 func (o *GenOptionText) Validate() error {
+	if o.Background && o.NoStore {
+		return errors.New("field Background: incompatible with NoStore")
+	}
 	if err := o.ReasoningEffort.Validate(); err != nil {
 		return err
 	}
 	return o.ServiceTier.Validate()
 }
 
+// GenOptionTools defines OpenAI Responses specific tool options.
+type GenOptionTools struct {
+	// HostedTools are tools run on OpenAI's servers. They can be used along genai.GenOptionTools.
+	HostedTools []HostedTool
+}
+
+// Validate implements genai.Validatable.
+func (o *GenOptionTools) Validate() error {
+	if len(o.HostedTools) == 0 {
+		return errors.New("field HostedTools is required")
+	}
+	for i := range o.HostedTools {
+		if err := o.HostedTools[i].Validate(); err != nil {
+			return fmt.Errorf("hosted tool %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// HostedToolType is a tool run by OpenAI.
+type HostedToolType string
+
+// Hosted tool type values.
+const (
+	// HostedToolCodeInterpreter runs python code in a sandboxed container. The code and its logs are returned as
+	// Text replies, generated images as Doc replies.
+	HostedToolCodeInterpreter HostedToolType = "code_interpreter"
+	// HostedToolFileSearch searches the files in vector stores. The results are returned as Citation replies.
+	HostedToolFileSearch HostedToolType = "file_search"
+	// HostedToolImageGeneration generates images. They are returned as Doc replies.
+	HostedToolImageGeneration HostedToolType = "image_generation"
+)
+
+// HostedTool is a tool run on OpenAI's servers.
+//
+// https://platform.openai.com/docs/guides/tools
+type HostedTool struct {
+	Type HostedToolType
+
+	// VectorStoreIDs are the vector stores to search. Required with HostedToolFileSearch.
+	VectorStoreIDs []string
+
+	// ContainerID is an existing container to run the code in, for HostedToolCodeInterpreter. When empty, a
+	// container is created automatically with FileIDs.
+	ContainerID string
+	// FileIDs are the uploaded files to make available in the automatically created container.
+	FileIDs []string
+
+	// PartialImages is the number of partial images to stream with HostedToolImageGeneration, between 0 and 3.
+	// Partial images are yielded by GenStream as Opaque replies with "type": "partial_image". They are ignored
+	// by GenSync.
+	PartialImages int64
+}
+
+// Validate implements genai.Validatable.
+func (h *HostedTool) Validate() error {
+	switch h.Type {
+	case HostedToolCodeInterpreter:
+		if h.ContainerID != "" && len(h.FileIDs) != 0 {
+			return errors.New("field FileIDs can't be used along ContainerID")
+		}
+	case HostedToolFileSearch:
+		if len(h.VectorStoreIDs) == 0 {
+			return errors.New("field VectorStoreIDs is required")
+		}
+	case HostedToolImageGeneration:
+		if h.PartialImages < 0 || h.PartialImages > 3 {
+			return fmt.Errorf("field PartialImages must be between 0 and 3, got %d", h.PartialImages)
+		}
+	case "":
+		return errors.New("field Type is required")
+	default:
+		return fmt.Errorf("unknown hosted tool type %q", h.Type)
+	}
+	if h.Type != HostedToolFileSearch && len(h.VectorStoreIDs) != 0 {
+		return fmt.Errorf("field VectorStoreIDs is only supported with %s", HostedToolFileSearch)
+	}
+	if h.Type != HostedToolCodeInterpreter && (h.ContainerID != "" || len(h.FileIDs) != 0) {
+		return fmt.Errorf("fields ContainerID and FileIDs are only supported with %s", HostedToolCodeInterpreter)
+	}
+	if h.Type != HostedToolImageGeneration && h.PartialImages != 0 {
+		return fmt.Errorf("field PartialImages is only supported with %s", HostedToolImageGeneration)
+	}
+	return nil
+}
+
 // Client is a client for the OpenAI Responses API.
 type Client struct {
 	base.NotImplemented
@@ -76,6 +179,12 @@ type Client struct {
 	baseURL string
 }
 
+// ProviderOptionOrganization selects the organization billed for the requests.
+type ProviderOptionOrganization = openaibase.ProviderOptionOrganization
+
+// ProviderOptionProject selects the project billed for the requests.
+type ProviderOptionProject = openaibase.ProviderOptionProject
+
 // New creates a new client to talk to the OpenAI Responses API.
 //
 // If ProviderOptionAPIKey is not provided, it tries to load it from the OPENAI_API_KEY environment variable.
@@ -85,6 +194,11 @@ type Client struct {
 // To use multiple models, create multiple clients.
 // Use one of the model from https://platform.openai.com/docs/models
 //
+// Use ProviderOptionOrganization and ProviderOptionProject to select which organization and project are
+// billed.
+//
+// The proxy defaults to the OPENAI_PROXY environment variable, see genai.ProviderOptionProxyURL.
+//
 // # Documents
 //
 // OpenAI supports many types of documents, listed at
@@ -93,7 +207,15 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model, remote string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	var org ProviderOptionOrganization
+	var project ProviderOptionProject
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -110,8 +232,24 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case ProviderOptionOrganization:
+			org = v
+		case ProviderOptionProject:
+			project = v
 		case genai.ProviderOptionRemote:
 			remote = string(v)
 		default:
@@ -144,7 +282,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if remote != "" {
 		baseURL = strings.TrimRight(remote, "/")
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "OPENAI_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -155,13 +293,16 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			GenStreamURL:    baseURL + "/responses",
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProcessHeaders:  openaibase.ProcessHeaders,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: "", // OpenAI error message prints the api key URL already.
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
-						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
+						Header:    openaibase.Headers(apiKey, org, project),
 						Transport: &roundtrippers.RequestID{Transport: t},
 					},
 				},
@@ -172,6 +313,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 		Impl:            &c.impl.ProviderBase,
 		BaseURL:         baseURL,
 		PreloadedModels: preloadedModels,
+		ModelSelector:   selector,
 	}
 	if err == nil {
 		switch model {
@@ -189,7 +331,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			}
 			switch mod {
 			case genai.ModalityText:
-				if c.impl.Model, err = c.shared.SelectBestTextModel(ctx, model); err != nil {
+				if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.shared.SelectBestTextModel); err != nil {
 					return nil, err
 				}
 				c.impl.OutputModalities = genai.Modalities{mod}
@@ -259,6 +401,11 @@ func (c *Client) Capabilities() genai.ProviderCapabilities {
 	return genai.ProviderCapabilities{GenAsync: true}
 }
 
+// ImageTokens implements genai.ProviderImageTokens.
+func (c *Client) ImageTokens(width, height int) int64 {
+	return openaibase.ImageTokens(c.impl.Model, width, height)
+}
+
 // GenAsync implements genai.Provider.
 //
 // It uses the OpenAI Responses API background mode to submit a request that is processed asynchronously.
@@ -273,9 +420,17 @@ func (c *Client) GenAsync(ctx context.Context, msgs genai.Messages, opts ...gena
 	if err := req.Init(msgs, c.impl.Model, opts...); err != nil {
 		return "", err
 	}
+	return c.genAsync(ctx, &req)
+}
+
+// genAsync submits the request in background mode.
+func (c *Client) genAsync(ctx context.Context, req *Response) (genai.Job, error) {
+	if !req.Store {
+		return "", errors.New("background mode requires server-side storage; GenOptionText.NoStore is incompatible")
+	}
 	req.Background = true
 	resp := Response{}
-	if err := c.impl.DoRequest(ctx, "POST", c.impl.GenSyncURL, &req, &resp); err != nil {
+	if err := c.impl.DoRequest(ctx, "POST", c.impl.GenSyncURL, req, &resp); err != nil {
 		return "", err
 	}
 	if resp.ID == "" {
@@ -290,26 +445,32 @@ func (c *Client) GenAsync(ctx context.Context, msgs genai.Messages, opts ...gena
 //
 // https://platform.openai.com/docs/api-reference/responses/get
 func (c *Client) PokeResult(ctx context.Context, job genai.Job) (genai.Result, error) {
-	resp := Response{}
-	url := c.impl.GenSyncURL + "/" + string(job)
-	if err := c.impl.DoRequest(ctx, "GET", url, nil, &resp); err != nil {
-		return genai.Result{}, err
+	res, _, err := c.pokeResult(ctx, job)
+	return res, err
+}
+
+// pokeResult is PokeResult that also returns the response status as metadata.
+func (c *Client) pokeResult(ctx context.Context, job genai.Job) (genai.Result, map[string]any, error) {
+	resp, err := c.PokeResultRaw(ctx, job)
+	if err != nil {
+		return genai.Result{}, nil, err
 	}
 	switch resp.Status {
 	case "queued", "in_progress":
-		return genai.Result{Usage: genai.Usage{FinishReason: genai.Pending}}, nil
+		return genai.Result{Usage: genai.Usage{FinishReason: genai.Pending}}, map[string]any{"status": resp.Status}, nil
 	case "incomplete":
 		res, err := resp.ToResult()
 		if err != nil {
-			return res, err
+			return res, nil, err
 		}
-		return res, errors.New(resp.IncompleteDetails.Reason)
+		return res, nil, errors.New(resp.IncompleteDetails.Reason)
 	case "failed":
-		return genai.Result{}, &resp.Error
+		return genai.Result{}, nil, &resp.Error
 	case "completed":
-		return resp.ToResult()
+		res, err := resp.ToResult()
+		return res, nil, err
 	default:
-		return genai.Result{}, fmt.Errorf("unexpected response status %q", resp.Status)
+		return genai.Result{}, nil, fmt.Errorf("unexpected response status %q", resp.Status)
 	}
 }
 
@@ -333,7 +494,7 @@ func ProcessStream(chunks iter.Seq[ResponseStreamChunkResponse]) (iter.Seq[genai
 			refused := false
 			pendingToolCall := genai.ToolCall{}
 			for pkt := range chunks {
-				f := genai.Reply{}
+				f := genai.Reply{Index: pkt.OutputIndex}
 				for _, lp := range pkt.Logprobs {
 					l = append(l, lp.To())
 				}
@@ -362,7 +523,7 @@ func ProcessStream(chunks iter.Seq[ResponseStreamChunkResponse]) (iter.Seq[genai
 						switch msg.Status {
 						case "":
 						case "completed":
-							if msg.Type == MessageFunctionCall {
+							if msg.Type == MessageFunctionCall || msg.Type == MessageComputerCall {
 								u.FinishReason = genai.FinishedToolCalls
 							}
 						case "in_progress":
@@ -413,12 +574,14 @@ func ProcessStream(chunks iter.Seq[ResponseStreamChunkResponse]) (iter.Seq[genai
 							bits = append(bits, pkt.Item.Summary[i].Text)
 						}
 						f.Reasoning = strings.Join(bits, "")
-					case MessageWebSearchCall:
-						// TODO: Send a fragment to tell the user. It's a server-side tool call, we don't have infrastructure
-						// to surface that to the user yet.
-					case MessageFileSearchCall:
-						// Server-side file search; data arrives in ResponseOutputItemDone.
-					case MessageComputerCall, MessageImageGenerationCall, MessageCodeInterpreterCall, MessageLocalShellCall, MessageMcpListTools, MessageMcpApprovalRequest, MessageMcpCall, MessageComputerCallOutput, MessageFunctionCallOutput, MessageLocalShellCallOutput, MessageMcpApprovalResponse, MessageItemReference:
+					case MessageWebSearchCall, MessageFileSearchCall, MessageCodeInterpreterCall:
+						// Server-side hosted tool; data arrives in ResponseOutputItemDone.
+						f.ServerToolCall = pkt.Item.serverToolCall()
+					case MessageImageGenerationCall:
+						// Server-side hosted tool; data arrives in ResponseOutputItemDone.
+					case MessageComputerCall:
+						// The action arrives in ResponseOutputItemDone.
+					case MessageLocalShellCall, MessageMcpListTools, MessageMcpApprovalRequest, MessageMcpCall, MessageComputerCallOutput, MessageFunctionCallOutput, MessageLocalShellCallOutput, MessageMcpApprovalResponse, MessageItemReference:
 						finalErr = &internal.BadError{Err: fmt.Errorf("implement item: %q", pkt.Item.Type)}
 						return
 					default:
@@ -430,35 +593,54 @@ func ProcessStream(chunks iter.Seq[ResponseStreamChunkResponse]) (iter.Seq[genai
 					// Perfect place to handle web search, since the "pending" has no data.
 					switch pkt.Item.Type {
 					case MessageWebSearchCall:
-						// TODO: Check for pkt.Item.Status == "completed"
-						switch pkt.Item.Action.Type {
-						case "search":
-							f.Citation.Sources = make([]genai.CitationSource, len(pkt.Item.Action.Sources)+1)
-							f.Citation.Sources[0].Type = genai.CitationWebQuery
-							f.Citation.Sources[0].Snippet = pkt.Item.Action.Query
-							for i, src := range pkt.Item.Action.Sources {
-								f.Citation.Sources[i+1].Type = genai.CitationWeb
-								f.Citation.Sources[i+1].URL = src.URL
-							}
-						default:
-							finalErr = &internal.BadError{Err: fmt.Errorf("implement action type %q", pkt.Item.Action.Type)}
+						c, err := pkt.Item.webSearchCitation()
+						if err != nil {
+							finalErr = err
 							return
 						}
+						if !yield(genai.Reply{Index: pkt.OutputIndex, ServerToolCall: pkt.Item.serverToolCall()}) {
+							return
+						}
+						f.Citation = c
 					case MessageFileSearchCall:
+						if !yield(genai.Reply{Index: pkt.OutputIndex, ServerToolCall: pkt.Item.serverToolCall()}) {
+							return
+						}
 						// File search completed; yield results as citations.
 						for _, r := range pkt.Item.Results {
-							if !yield(genai.Reply{Citation: genai.Citation{
+							if !yield(genai.Reply{Index: pkt.OutputIndex, Citation: genai.Citation{
 								CitedText: r.Text,
 								Sources: []genai.CitationSource{{
-									Type:  genai.CitationDocument,
-									ID:    r.FileID,
-									Title: r.Filename,
+									Type:         genai.CitationDocument,
+									ID:           r.FileID,
+									Title:        r.Filename,
+									DocumentName: r.Filename,
+									Confidence:   r.Score,
 								}},
 							}}) {
 								return
 							}
 						}
-					case MessageMessage, MessageComputerCall, MessageFunctionCall, MessageReasoning, MessageImageGenerationCall, MessageCodeInterpreterCall, MessageLocalShellCall, MessageMcpListTools, MessageMcpApprovalRequest, MessageMcpCall, MessageComputerCallOutput, MessageFunctionCallOutput, MessageLocalShellCallOutput, MessageMcpApprovalResponse, MessageItemReference:
+					case MessageCodeInterpreterCall, MessageImageGenerationCall, MessageComputerCall:
+						var m genai.Message
+						if err := pkt.Item.To(&m); err != nil {
+							finalErr = &internal.BadError{Err: err}
+							return
+						}
+						for i := range m.Replies {
+							m.Replies[i].Index = pkt.OutputIndex
+							if !yield(m.Replies[i]) {
+								return
+							}
+						}
+					case MessageReasoning:
+						// Keep the reference to the reasoning item so it can be sent back along the following tool calls.
+						if opaque := pkt.Item.reasoningOpaque(); opaque != nil {
+							if !yield(genai.Reply{Opaque: opaque}) {
+								return
+							}
+						}
+					case MessageMessage, MessageFunctionCall, MessageLocalShellCall, MessageMcpListTools, MessageMcpApprovalRequest, MessageMcpCall, MessageComputerCallOutput, MessageFunctionCallOutput, MessageLocalShellCallOutput, MessageMcpApprovalResponse, MessageItemReference:
 					default:
 						// The default stance is to ignore this event since it's generally duplicate information.
 					}
@@ -545,10 +727,19 @@ func ProcessStream(chunks iter.Seq[ResponseStreamChunkResponse]) (iter.Seq[genai
 				case ResponseReasoningTextDone:
 					// https://platform.openai.com/docs/api-reference/responses_streaming/response/reasoning_text/done
 
-				case ResponseImageGenerationCallCompleted, ResponseImageGenerationCallGenerating, ResponseImageGenerationCallInProgress, ResponseImageGenerationCallPartialImage:
+				case ResponseImageGenerationCallCompleted, ResponseImageGenerationCallGenerating, ResponseImageGenerationCallInProgress:
 					// https://platform.openai.com/docs/api-reference/responses_streaming/response/image_generation_call/completed
-					finalErr = &internal.BadError{Err: fmt.Errorf("implement packet: %q", pkt.Type)}
-					return
+					// Data is sent in ResponseOutputItemDone.
+				case ResponseImageGenerationCallPartialImage:
+					// https://platform.openai.com/docs/api-reference/responses_streaming/response/image_generation_call/partial_image
+					// Not sent as a Doc since Message.Accumulate would concatenate it with the final image.
+					f.Opaque = map[string]any{
+						"type":          "partial_image",
+						"id":            pkt.ItemID,
+						"index":         pkt.PartialImageIndex,
+						"output_format": pkt.OutputFormat,
+						"b64":           pkt.PartialImageB64,
+					}
 
 				case ResponseMCPCallArgumentsDelta, ResponseMCPCallArgumentsDone, ResponseMCPCallCompleted, ResponseMCPCallFailed, ResponseMCPCallInProgress, ResponseMCPListToolsCompleted, ResponseMCPListToolsFailed, ResponseMCPListToolsInProgress:
 					// https://platform.openai.com/docs/api-reference/responses_streaming/response/mcp_call_arguments/delta
@@ -557,8 +748,7 @@ func ProcessStream(chunks iter.Seq[ResponseStreamChunkResponse]) (iter.Seq[genai
 
 				case ResponseCodeInterpreterCallInterpreting, ResponseCodeInterpreterCallCompleted, ResponseCodeInterpreterCallDelta, ResponseCodeInterpreterCallDone:
 					// https://platform.openai.com/docs/api-reference/responses_streaming/response/code_interpreter_call/in_progress
-					finalErr = &internal.BadError{Err: fmt.Errorf("implement packet: %q", pkt.Type)}
-					return
+					// Data is sent in ResponseOutputItemDone.
 
 				case ResponseOutputTextAnnotationAdded:
 					// https://platform.openai.com/docs/api-reference/responses_streaming/response/output_text/annotation/added
@@ -573,7 +763,7 @@ func ProcessStream(chunks iter.Seq[ResponseStreamChunkResponse]) (iter.Seq[genai
 						f.Citation.StartIndex = pkt.Annotation.StartIndex
 						f.Citation.EndIndex = pkt.Annotation.EndIndex
 						f.Citation.Sources = []genai.CitationSource{
-							{Type: genai.CitationDocument, ID: pkt.Annotation.FileID},
+							{Type: genai.CitationDocument, ID: pkt.Annotation.FileID, DocumentName: pkt.Annotation.Filename},
 						}
 					case "file_path":
 						f.Citation.Sources = []genai.CitationSource{
@@ -622,6 +812,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -645,11 +840,35 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 	return c.shared.ListModels(ctx)
 }
 
+// Moderate returns the sorted categories the text is flagged for by the moderation model.
+//
+// It implements safety.Moderator.
+func (c *Client) Moderate(ctx context.Context, text string) ([]string, error) {
+	return c.shared.Moderate(ctx, text)
+}
+
+// ModerateRaw classifies text with the moderation endpoint.
+func (c *Client) ModerateRaw(ctx context.Context, in *ModerationRequest, out *ModerationResponse) error {
+	return c.shared.ModerateRaw(ctx, in, out)
+}
+
 // GenSync implements genai.Provider.
 //
 // It handles delta detection: if msgs contains metadata from a prior call (via Reply.Opaque),
 // only new messages are sent. The response ID is captured and emitted as metadata for the next call.
 func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	start := time.Now()
+	sink, opts := base.ExtractOutputOption(opts)
+	res, err := c.genSync(ctx, msgs, opts...)
+	if err == nil {
+		err = sink.Redirect(&res)
+	}
+	c.impl.LogResult(ctx, "GenSync", start, &res, err)
+	return res, err
+}
+
+func (c *Client) genSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	ctx, opts = base.ExtractRawOptions(ctx, opts)
 	if c.shared.IsAudio() {
 		return genai.Result{}, errors.New("OpenAI Responses API does not support audio output as of December 2025; see https://platform.openai.com/docs/guides/audio")
 	}
@@ -659,12 +878,28 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 		}
 		return c.shared.GenDoc(ctx, &msgs[0], opts...)
 	}
+	waitForPoll, progress, opts := base.ExtractPollOptions(opts, 5*time.Second)
 	cleaned, prevRespID := c.prepareDelta(msgs, opts)
 	in := &Response{}
 	if err := in.Init(cleaned, c.impl.Model, opts...); err != nil {
 		return genai.Result{}, err
 	}
 	in.PreviousResponseID = prevRespID
+	if in.Background {
+		job, err := c.genAsync(ctx, in)
+		if err != nil {
+			return genai.Result{}, err
+		}
+		res, err := base.PollJob(ctx, job, waitForPoll, progress, c.pokeResult)
+		if err != nil {
+			return res, err
+		}
+		if err := res.Validate(); err != nil {
+			return res, &internal.BadError{Err: err}
+		}
+		res.Replies = append(res.Replies, emitMeta(string(job), len(msgs)))
+		return res, nil
+	}
 	out := &Response{}
 	if err := c.GenSyncRaw(ctx, in, out); err != nil {
 		return genai.Result{}, err
@@ -680,7 +915,7 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 	if c.impl.ProcessHeaders != nil && lastResp != nil {
 		res.Usage.Limits = c.impl.ProcessHeaders(lastResp)
 	}
-	if out.ID != "" {
+	if out.ID != "" && in.Store {
 		res.Replies = append(res.Replies, emitMeta(out.ID, len(msgs)))
 	}
 	return res, nil
@@ -691,6 +926,7 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 // It handles delta detection: if msgs contains metadata from a prior call (via Reply.Opaque),
 // only new messages are sent. The response ID is captured and emitted as metadata for the next call.
 func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	ctx, opts = base.ExtractRawOptions(ctx, opts)
 	if c.shared.IsAudio() {
 		return func(yield func(genai.Reply) bool) {}, func() (genai.Result, error) {
 			return genai.Result{}, errors.New("OpenAI Responses API does not support audio output as of December 2025; see https://platform.openai.com/docs/guides/audio")
@@ -699,6 +935,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 	if c.shared.IsImage() || c.shared.IsVideo() {
 		return base.SimulateStream(ctx, c, msgs, opts...)
 	}
+	sink, opts := base.ExtractOutputOption(opts)
 	cleaned, prevRespID := c.prepareDelta(msgs, opts)
 	in := &Response{}
 	if err := in.Init(cleaned, c.impl.Model, opts...); err != nil {
@@ -707,6 +944,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		}
 	}
 	in.PreviousResponseID = prevRespID
+	start := time.Now()
 	chunks, finish := c.GenStreamRaw(ctx, in)
 	// Capture headers immediately after the HTTP call, before iterating.
 	lastResp := c.impl.LastResponseHeaders()
@@ -727,8 +965,8 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 				finalErr = &internal.BadError{Err: err}
 				break
 			}
-			if err := res.Accumulate(&f); err != nil {
-				finalErr = &internal.BadError{Err: err}
+			if err := sink.Accumulate(&res, &f); err != nil {
+				finalErr = err
 				break
 			}
 			sent = true
@@ -747,6 +985,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		if !sent && finalErr == nil {
 			finalErr = errors.New("model sent no reply")
 		}
+		c.impl.LogResult(ctx, "GenStream", start, &res, finalErr)
 	}
 	fnFinish := func() (genai.Result, error) {
 		if finalErr != nil {
@@ -758,7 +997,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		if c.impl.ProcessHeaders != nil && lastResp != nil {
 			res.Usage.Limits = c.impl.ProcessHeaders(lastResp)
 		}
-		if respID != "" {
+		if respID != "" && in.Store {
 			res.Replies = append(res.Replies, emitMeta(respID, msgCount))
 		}
 		return res, nil
@@ -766,6 +1005,102 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 	return fnFragments, fnFinish
 }
 
+// VectorStoreCreate creates a vector store. Pass its ID in HostedTool.VectorStoreIDs to search it with
+// HostedToolFileSearch, or in genai.GenOptionFileSearch.StoreIDs.
+func (c *Client) VectorStoreCreate(ctx context.Context, name string) (*VectorStore, error) {
+	return c.shared.VectorStoreCreate(ctx, name)
+}
+
+// VectorStoreGet retrieves a vector store.
+func (c *Client) VectorStoreGet(ctx context.Context, id string) (*VectorStore, error) {
+	return c.shared.VectorStoreGet(ctx, id)
+}
+
+// VectorStoreList lists all the vector stores.
+func (c *Client) VectorStoreList(ctx context.Context) ([]VectorStore, error) {
+	return c.shared.VectorStoreList(ctx)
+}
+
+// VectorStoreDelete deletes a vector store. The files it contains are not deleted.
+func (c *Client) VectorStoreDelete(ctx context.Context, id string) error {
+	return c.shared.VectorStoreDelete(ctx, id)
+}
+
+// VectorStoreAdd implements genai.ProviderVectorStore.
+func (c *Client) VectorStoreAdd(ctx context.Context, displayName string) (string, error) {
+	v, err := c.shared.VectorStoreCreate(ctx, displayName)
+	if err != nil {
+		return "", err
+	}
+	return v.ID, nil
+}
+
+// VectorStoreAddDoc implements genai.ProviderVectorStore.
+//
+// It uploads the document with VectorStoreFileAdd and polls VectorStoreFileGet until it is processed.
+func (c *Client) VectorStoreAddDoc(ctx context.Context, id string, doc *genai.Doc) error {
+	if doc.Src == nil {
+		return errors.New("only documents with Src are supported")
+	}
+	f, err := c.shared.VectorStoreFileAdd(ctx, id, doc.GetFilename(), doc.Src)
+	if err != nil {
+		return err
+	}
+	for f.Status == "in_progress" {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+		if f, err = c.shared.VectorStoreFileGet(ctx, id, f.ID); err != nil {
+			return err
+		}
+	}
+	if f.Status != "completed" {
+		return fmt.Errorf("file %q is %s: %s", f.ID, f.Status, f.LastError.Message)
+	}
+	return nil
+}
+
+// VectorStoreEntries implements genai.ProviderVectorStore.
+func (c *Client) VectorStoreEntries(ctx context.Context) ([]genai.VectorStoreEntry, error) {
+	l, err := c.shared.VectorStoreList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]genai.VectorStoreEntry, len(l))
+	for i := range l {
+		out[i] = &l[i]
+	}
+	return out, nil
+}
+
+// VectorStoreFileAdd uploads a file and adds it to a vector store.
+//
+// The file is processed asynchronously: poll VectorStoreFileGet until its Status is not "in_progress" before
+// searching it.
+func (c *Client) VectorStoreFileAdd(ctx context.Context, storeID, filename string, r io.Reader) (*VectorStoreFile, error) {
+	return c.shared.VectorStoreFileAdd(ctx, storeID, filename, r)
+}
+
+// VectorStoreFileGet retrieves a file in a vector store, including its processing status.
+func (c *Client) VectorStoreFileGet(ctx context.Context, storeID, fileID string) (*VectorStoreFile, error) {
+	return c.shared.VectorStoreFileGet(ctx, storeID, fileID)
+}
+
+// VectorStoreFileList lists the files in a vector store.
+func (c *Client) VectorStoreFileList(ctx context.Context, storeID string) ([]VectorStoreFile, error) {
+	return c.shared.VectorStoreFileList(ctx, storeID)
+}
+
+// VectorStoreFileDelete removes a file from a vector store and deletes the file.
+func (c *Client) VectorStoreFileDelete(ctx context.Context, storeID, fileID string) error {
+	if err := c.shared.VectorStoreFileDelete(ctx, storeID, fileID); err != nil {
+		return err
+	}
+	return c.shared.FileDel(ctx, fileID)
+}
+
 // WebSocket opens a persistent WebSocket connection to the OpenAI Responses API.
 //
 // The returned connection inherits the client's model, API key, and base URL.
@@ -779,13 +1114,29 @@ func (c *Client) WebSocket(ctx context.Context) (*WebSocketConn, error) {
 	wsURL = strings.Replace(wsURL, "http://", "ws://", 1)
 	wsURL += "/responses"
 
+	wsCfg, err := c.wsConfig(wsURL)
+	if err != nil {
+		return nil, err
+	}
+	wsCfg.Header.Set("OpenAI-Beta", "responses=v1")
+	raw, err := wsCfg.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to websocket %s: %w", wsURL, err)
+	}
+	return &WebSocketConn{
+		client: c,
+		ws:     &websocketConn{raw},
+	}, nil
+}
+
+// wsConfig returns a WebSocket configuration with the authentication headers extracted from the HTTP client's
+// transport chain.
+func (c *Client) wsConfig(wsURL string) (*websocket.Config, error) {
 	wsCfg, err := websocket.NewConfig(wsURL, wsURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create websocket config: %w", err)
 	}
-	// Extract auth headers from the HTTP client's transport chain.
 	wsCfg.Header = http.Header{}
-	wsCfg.Header.Set("OpenAI-Beta", "responses=v1")
 	if h, ok := c.impl.Client.Transport.(*roundtrippers.Header); ok {
 		for k, vs := range h.Header {
 			for _, v := range vs {
@@ -793,14 +1144,7 @@ func (c *Client) WebSocket(ctx context.Context) (*WebSocketConn, error) {
 			}
 		}
 	}
-	raw, err := wsCfg.DialContext(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to websocket %s: %w", wsURL, err)
-	}
-	return &WebSocketConn{
-		client: c,
-		ws:     &websocketConn{raw},
-	}, nil
+	return wsCfg, nil
 }
 
 // Opaque keys for session metadata stored in Reply.Opaque between calls.
@@ -921,4 +1265,9 @@ func streamWithRespID(src iter.Seq[ResponseStreamChunkResponse], respID *string)
 	}
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+	_ genai.ProviderImageTokens    = &Client{}
+	_ genai.ProviderVectorStore    = &Client{}
+)
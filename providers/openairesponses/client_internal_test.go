@@ -7,6 +7,8 @@
 package openairesponses
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/maruel/genai"
@@ -94,3 +96,123 @@ func TestStreamWithRespID(t *testing.T) {
 		}
 	})
 }
+
+func TestReasoningRoundTrip(t *testing.T) {
+	out := Response{Output: []Message{
+		{Type: MessageReasoning, ID: "rs_1", EncryptedContent: "enc", Summary: []ReasoningSummary{{Type: "summary_text", Text: "think"}}},
+		{Type: MessageFunctionCall, CallID: "call_1", Name: "tool", Arguments: "{}"},
+	}}
+	var got genai.Message
+	for i := range out.Output {
+		if err := out.Output[i].To(&got); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got.Replies[0].Opaque[opaqueReasoningID] != "rs_1" {
+		t.Fatalf("unexpected %#v", got.Replies)
+	}
+	msgs := genai.Messages{
+		genai.NewTextMessage("Hello"),
+		got,
+		{ToolCallResults: []genai.ToolCallResult{{ID: "call_1", Name: "tool", Result: "42"}}},
+	}
+	var in Response
+	if err := in.Init(msgs, "gpt-5.6-luna", &GenOptionText{NoStore: true}); err != nil {
+		t.Fatal(err)
+	}
+	if in.Store {
+		t.Error("Store should be false")
+	}
+	if len(in.Input) != 4 {
+		t.Fatalf("got %d input items, want 4", len(in.Input))
+	}
+	r := in.Input[1]
+	if r.Type != MessageReasoning || r.ID != "rs_1" || r.EncryptedContent != "enc" || len(r.Summary) != 1 || r.Summary[0].Text != "think" {
+		t.Errorf("unexpected reasoning item %#v", r)
+	}
+	if in.Input[2].Type != MessageFunctionCall {
+		t.Errorf("got %q, want function call after reasoning", in.Input[2].Type)
+	}
+}
+
+func TestGenOptionText_Validate(t *testing.T) {
+	if err := (&GenOptionText{Background: true}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	o := GenOptionText{Background: true, NoStore: true}
+	if err := o.Validate(); err == nil || err.Error() != "field Background: incompatible with NoStore" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var in Response
+	if err := in.Init(genai.Messages{genai.NewTextMessage("Hello")}, "o3-deep-research", &GenOptionText{Background: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !in.Background || !in.Store {
+		t.Errorf("unexpected Background %t Store %t", in.Background, in.Store)
+	}
+}
+
+func TestDeepResearch(t *testing.T) {
+	var in Response
+	if err := in.Init(genai.Messages{genai.NewTextMessage("Research")}, "o4-mini-deep-research"); err != nil {
+		t.Fatal(err)
+	}
+	if !in.Background || len(in.Tools) != 1 || in.Tools[0].Type != "web_search" {
+		t.Errorf("unexpected Background %t Tools %#v", in.Background, in.Tools)
+	}
+	var m Message
+	m.Type = MessageWebSearchCall
+	m.Action.Type = "find_in_page"
+	m.Action.URL = "https://example.com"
+	m.Action.Pattern = "genai"
+	var out genai.Message
+	if err := m.To(&out); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.Replies[0].ServerToolCall; s.Name != genai.ServerToolWebSearch || s.Input != `{"type":"find_in_page","url":"https://example.com","pattern":"genai"}` {
+		t.Errorf("unexpected %#v", s)
+	}
+	if src := out.Replies[1].Citation.Sources[0]; src.URL != "https://example.com" || src.Snippet != "genai" {
+		t.Errorf("unexpected %#v", src)
+	}
+}
+
+func TestComputerCall(t *testing.T) {
+	var item Message
+	if err := json.Unmarshal([]byte(`{"type":"computer_call","id":"cu_1","call_id":"call_1","action":{"type":"click","x":10,"y":20,"button":"wheel"},"pending_safety_checks":[{"id":"sc_1","code":"malicious_instructions"}],"status":"completed"}`), &item); err != nil {
+		t.Fatal(err)
+	}
+	var got genai.Message
+	if err := item.To(&got); err != nil {
+		t.Fatal(err)
+	}
+	tc := got.Replies[0].ToolCall
+	if tc.Name != genai.ComputerToolName || tc.Arguments != `{"type":"click","x":10,"y":20,"button":"middle"}` {
+		t.Fatalf("unexpected %#v", tc)
+	}
+	msgs := genai.Messages{
+		genai.NewTextMessage("Click"),
+		got,
+		{ToolCallResults: []genai.ToolCallResult{{ID: "call_1", Name: genai.ComputerToolName, Doc: genai.Doc{Filename: "screen.png", Src: strings.NewReader("PNG")}}}},
+	}
+	var in Response
+	if err := in.Init(msgs, "computer-use-preview", &genai.GenOptionTools{Computer: &genai.Computer{Width: 1024, Height: 768}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(in.Input) != 3 {
+		t.Fatalf("got %d input items, want 3", len(in.Input))
+	}
+	if c := in.Input[1]; c.Type != MessageComputerCall || c.ID != "cu_1" || c.Action.Button != "wheel" || len(c.PendingSafetyChecks) != 1 {
+		t.Errorf("unexpected computer call %#v", c)
+	}
+	o := in.Input[2]
+	if o.Type != MessageComputerCallOutput || o.Output.Type != "computer_screenshot" || !strings.HasPrefix(o.Output.ImageURL, "data:image/png;base64,") {
+		t.Errorf("unexpected computer call output %#v", o)
+	}
+	if len(o.AcknowledgedSafetyChecks) != 1 || o.AcknowledgedSafetyChecks[0].ID != "sc_1" {
+		t.Errorf("unexpected acknowledged safety checks %#v", o.AcknowledgedSafetyChecks)
+	}
+	if in.Tools[0].Type != "computer_use_preview" || in.Tools[0].Environment != "browser" || in.Truncation != "auto" {
+		t.Errorf("unexpected tool %#v", in.Tools[0])
+	}
+}
@@ -8,6 +8,7 @@ package openairesponses_test
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"os"
 	"slices"
@@ -15,8 +16,8 @@ import (
 	"testing"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/providers/openaibase"
 	"github.com/maruel/genai/providers/openairesponses"
 	"github.com/maruel/genai/scoreboard"
 	"github.com/maruel/genai/smoke/smoketest"
@@ -36,6 +37,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return openairesponses.New(t.Context(), opts...)
 }
 
@@ -97,6 +99,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := openairesponses.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -328,6 +331,289 @@ func TestPreviousResponseID(t *testing.T) {
 	})
 }
 
-func init() {
-	internal.BeLenient = false
+func TestHostedTools(t *testing.T) {
+	msgs := genai.Messages{genai.NewTextMessage("hello")}
+	t.Run("request", func(t *testing.T) {
+		opts := openairesponses.GenOptionTools{HostedTools: []openairesponses.HostedTool{
+			{Type: openairesponses.HostedToolCodeInterpreter, FileIDs: []string{"file_1"}},
+			{Type: openairesponses.HostedToolCodeInterpreter, ContainerID: "cntr_1"},
+			{Type: openairesponses.HostedToolFileSearch, VectorStoreIDs: []string{"vs_1"}},
+			{Type: openairesponses.HostedToolImageGeneration, PartialImages: 2},
+		}}
+		var req openairesponses.Response
+		if err := req.Init(msgs, "gpt-5.6-luna", &opts); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(req.Tools)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `[{"type":"code_interpreter","container":{"type":"auto","file_ids":["file_1"]}},` +
+			`{"type":"code_interpreter","container":"cntr_1"},` +
+			`{"type":"file_search","vector_store_ids":["vs_1"]},` +
+			`{"type":"image_generation","partial_images":2}]`
+		if got := string(b); got != want {
+			t.Errorf("tools\ngot:  %s\nwant: %s", got, want)
+		}
+		wantInclude := []string{"code_interpreter_call.outputs", "code_interpreter_call.outputs", "file_search_call.results"}
+		if !slices.Equal(req.Include, wantInclude) {
+			t.Errorf("include = %q, want %q", req.Include, wantInclude)
+		}
+		var c openairesponses.ToolContainer
+		if err := json.Unmarshal([]byte(`"cntr_1"`), &c); err != nil || c.ID != "cntr_1" {
+			t.Errorf("unmarshal container = %#v, %v", c, err)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			tool openairesponses.HostedTool
+			want string
+		}{
+			{"type", openairesponses.HostedTool{}, "hosted tool 0: field Type is required"},
+			{"unknown", openairesponses.HostedTool{Type: "computer"}, `hosted tool 0: unknown hosted tool type "computer"`},
+			{"vector_stores", openairesponses.HostedTool{Type: openairesponses.HostedToolFileSearch}, "hosted tool 0: field VectorStoreIDs is required"},
+			{"container", openairesponses.HostedTool{Type: openairesponses.HostedToolCodeInterpreter, ContainerID: "c", FileIDs: []string{"f"}}, "hosted tool 0: field FileIDs can't be used along ContainerID"},
+			{"partial_images", openairesponses.HostedTool{Type: openairesponses.HostedToolImageGeneration, PartialImages: 4}, "hosted tool 0: field PartialImages must be between 0 and 3, got 4"},
+			{"mismatch", openairesponses.HostedTool{Type: openairesponses.HostedToolImageGeneration, VectorStoreIDs: []string{"vs_1"}}, "hosted tool 0: field VectorStoreIDs is only supported with file_search"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				o := openairesponses.GenOptionTools{HostedTools: []openairesponses.HostedTool{tc.tool}}
+				if err := o.Validate(); err == nil || err.Error() != tc.want {
+					t.Errorf("got %v, want %q", err, tc.want)
+				}
+			})
+		}
+	})
+	t.Run("result", func(t *testing.T) {
+		// A 1x1 transparent PNG, truncated since it is not decoded.
+		const png = "iVBORw0KGgo="
+		resp := openairesponses.Response{Output: []openairesponses.Message{
+			{
+				Type:        openairesponses.MessageCodeInterpreterCall,
+				ID:          "ci_1",
+				Code:        "print(1+1)",
+				ContainerID: "cntr_1",
+				Outputs: []openairesponses.CodeInterpreterOutput{
+					{Type: "logs", Logs: "2\n"},
+					{Type: "image", URL: "https://example.com/plot.png"},
+				},
+			},
+			{Type: openairesponses.MessageImageGenerationCall, ID: "ig_1", Result: png, OutputFormat: "webp", RevisedPrompt: "a cat"},
+		}}
+		res, err := resp.ToResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Replies) != 5 {
+			t.Fatalf("got %d replies: %#v", len(res.Replies), res.Replies)
+		}
+		want := genai.ServerToolCall{ID: "ci_1", Name: genai.ServerToolCodeExecution, Status: genai.ServerToolCallCompleted, Input: `{"code":"print(1+1)"}`, Output: "2\n"}
+		if r := res.Replies[0]; r.ServerToolCall != want {
+			t.Errorf("server tool call: %#v", r)
+		}
+		if r := res.Replies[1]; r.Text != "print(1+1)" || r.Opaque["container_id"] != "cntr_1" {
+			t.Errorf("code: %#v", r)
+		}
+		if r := res.Replies[2]; r.Text != "2\n" {
+			t.Errorf("logs: %#v", r)
+		}
+		if r := res.Replies[3]; r.Doc.URL != "https://example.com/plot.png" {
+			t.Errorf("image: %#v", r)
+		}
+		if r := res.Replies[4]; r.Doc.Filename != "image.webp" || r.Opaque["revised_prompt"] != "a cat" {
+			t.Errorf("generated image: %#v", r)
+		}
+		for i := range res.Replies {
+			if err := res.Replies[i].Validate(); err != nil {
+				t.Errorf("reply %d: %v", i, err)
+			}
+		}
+		// Hosted tool calls are not sent back.
+		var req openairesponses.Response
+		if err := req.Init(genai.Messages{msgs[0], res.Message, genai.NewTextMessage("thanks")}, "gpt-5.6-luna"); err != nil {
+			t.Fatal(err)
+		}
+		if len(req.Input) != 2 {
+			t.Errorf("got %d inputs: %#v", len(req.Input), req.Input)
+		}
+	})
+}
+
+func TestVectorStore(t *testing.T) {
+	var got []string
+	fn := func(http.RoundTripper) http.RoundTripper {
+		return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			got = append(got, r.Method+" "+r.URL.RequestURI())
+			body := ""
+			switch r.Method + " " + r.URL.RequestURI() {
+			case "POST /v1/vector_stores":
+				body = `{"id":"vs_1","object":"vector_store","name":"docs","status":"completed"}`
+			case "POST /v1/files":
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Error(err)
+				} else if p := r.FormValue("purpose"); p != "assistants" {
+					t.Errorf("purpose = %q", p)
+				}
+				body = `{"id":"file_1","object":"file","filename":"a.txt","purpose":"assistants"}`
+			case "POST /v1/vector_stores/vs_1/files":
+				body = `{"id":"file_1","object":"vector_store.file","vector_store_id":"vs_1","status":"in_progress"}`
+			case "GET /v1/vector_stores?limit=100":
+				body = `{"object":"list","data":[{"id":"vs_1"}],"last_id":"vs_1","has_more":true}`
+			case "GET /v1/vector_stores?limit=100&after=vs_1":
+				body = `{"object":"list","data":[{"id":"vs_2"}],"last_id":"vs_2","has_more":false}`
+			case "DELETE /v1/vector_stores/vs_1/files/file_1":
+				body = `{"id":"file_1","object":"vector_store.file.deleted","deleted":true}`
+			case "DELETE /v1/files/file_1":
+				body = `{"id":"file_1","object":"file","deleted":true}`
+			case "DELETE /v1/vector_stores/vs_1":
+				body = `{"id":"vs_1","object":"vector_store.deleted","deleted":true}`
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			}
+			h := http.Header{"Content-Type": []string{"application/json"}}
+			return &http.Response{StatusCode: 200, Header: h, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+		})
+	}
+	p, err := getClientInner(t, fn, genai.ProviderOptionModel("gpt-5.6-luna"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := p.(*openairesponses.Client)
+	ctx := t.Context()
+	vs, err := c.VectorStoreCreate(ctx, "docs")
+	if err != nil || vs.ID != "vs_1" {
+		t.Fatalf("%#v, %v", vs, err)
+	}
+	f, err := c.VectorStoreFileAdd(ctx, vs.ID, "a.txt", strings.NewReader("hello"))
+	if err != nil || f.ID != "file_1" || f.Status != "in_progress" {
+		t.Fatalf("%#v, %v", f, err)
+	}
+	l, err := c.VectorStoreList(ctx)
+	if err != nil || len(l) != 2 || l[1].ID != "vs_2" {
+		t.Fatalf("%#v, %v", l, err)
+	}
+	if err := c.VectorStoreFileDelete(ctx, vs.ID, f.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.VectorStoreDelete(ctx, vs.ID); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"POST /v1/vector_stores",
+		"POST /v1/files",
+		"POST /v1/vector_stores/vs_1/files",
+		"GET /v1/vector_stores?limit=100",
+		"GET /v1/vector_stores?limit=100&after=vs_1",
+		"DELETE /v1/vector_stores/vs_1/files/file_1",
+		"DELETE /v1/files/file_1",
+		"DELETE /v1/vector_stores/vs_1",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("requests\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestProviderVectorStore(t *testing.T) {
+	var got []string
+	fn := func(http.RoundTripper) http.RoundTripper {
+		return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			got = append(got, r.Method+" "+r.URL.RequestURI())
+			body := ""
+			switch r.Method + " " + r.URL.RequestURI() {
+			case "POST /v1/vector_stores":
+				var in openaibase.VectorStoreRequest
+				if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Name != "docs" {
+					t.Errorf("request = %#v, %v", in, err)
+				}
+				body = `{"id":"vs_1","object":"vector_store","name":"docs","status":"completed"}`
+			case "POST /v1/files":
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Error(err)
+				} else if f := r.MultipartForm.File["file"]; len(f) != 1 || f[0].Filename != "a.txt" {
+					t.Errorf("file = %#v", f)
+				}
+				body = `{"id":"file_1","object":"file","filename":"a.txt","purpose":"assistants"}`
+			case "POST /v1/vector_stores/vs_1/files":
+				var in openaibase.VectorStoreFileRequest
+				if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.FileID != "file_1" {
+					t.Errorf("request = %#v, %v", in, err)
+				}
+				body = `{"id":"file_1","object":"vector_store.file","vector_store_id":"vs_1","status":"in_progress"}`
+			case "GET /v1/vector_stores/vs_1/files/file_1":
+				body = `{"id":"file_1","object":"vector_store.file","vector_store_id":"vs_1","status":"completed"}`
+			case "GET /v1/vector_stores?limit=100":
+				body = `{"object":"list","data":[{"id":"vs_1","name":"docs"}],"last_id":"vs_1","has_more":false}`
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			}
+			h := http.Header{"Content-Type": []string{"application/json"}}
+			return &http.Response{StatusCode: 200, Header: h, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+		})
+	}
+	p, err := getClientInner(t, fn, genai.ProviderOptionModel("gpt-5.6-luna"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := p.(genai.ProviderVectorStore)
+	ctx := t.Context()
+	id, err := c.VectorStoreAdd(ctx, "docs")
+	if err != nil || id != "vs_1" {
+		t.Fatalf("%q, %v", id, err)
+	}
+	if err = c.VectorStoreAddDoc(ctx, id, &genai.Doc{Filename: "a.txt", Src: strings.NewReader("hello")}); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.VectorStoreAddDoc(ctx, id, &genai.Doc{URL: "https://example.com/a.txt"}); err == nil {
+		t.Error("expected error for a document without Src")
+	}
+	entries, err := c.VectorStoreEntries(ctx)
+	if err != nil || len(entries) != 1 || entries[0].GetID() != "vs_1" || entries[0].GetDisplayName() != "docs" {
+		t.Fatalf("%#v, %v", entries, err)
+	}
+	want := []string{
+		"POST /v1/vector_stores",
+		"POST /v1/files",
+		"POST /v1/vector_stores/vs_1/files",
+		"GET /v1/vector_stores/vs_1/files/file_1",
+		"GET /v1/vector_stores?limit=100",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("requests\ngot:  %q\nwant: %q", got, want)
+	}
+	t.Run("failed", func(t *testing.T) {
+		p, err := getClientInner(t, func(http.RoundTripper) http.RoundTripper {
+			return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				body := `{"id":"file_1","object":"file","filename":"a.txt","purpose":"assistants"}`
+				if r.URL.Path == "/v1/vector_stores/vs_1/files" {
+					body = `{"id":"file_1","object":"vector_store.file","status":"failed","last_error":{"code":"unsupported_file","message":"bad file"}}`
+				}
+				h := http.Header{"Content-Type": []string{"application/json"}}
+				return &http.Response{StatusCode: 200, Header: h, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+			})
+		}, genai.ProviderOptionModel("gpt-5.6-luna"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = p.(genai.ProviderVectorStore).VectorStoreAddDoc(t.Context(), "vs_1", &genai.Doc{Filename: "a.txt", Src: strings.NewReader("hello")})
+		if err == nil || !strings.Contains(err.Error(), "bad file") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+	t.Run("GenOptionFileSearch", func(t *testing.T) {
+		var req openairesponses.Response
+		if err := req.Init(genai.Messages{genai.NewTextMessage("hello")}, "gpt-5.6-luna", &genai.GenOptionFileSearch{StoreIDs: []string{"vs_1", "vs_2"}}); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(req.Tools)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `[{"type":"file_search","vector_store_ids":["vs_1","vs_2"]}]`; string(b) != want {
+			t.Errorf("tools\ngot:  %s\nwant: %s", b, want)
+		}
+		if want := []string{"file_search_call.results"}; !slices.Equal(req.Include, want) {
+			t.Errorf("include = %q, want %q", req.Include, want)
+		}
+	})
 }
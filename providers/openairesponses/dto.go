@@ -25,6 +25,7 @@ import (
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
 	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/internal/bb"
 	"github.com/maruel/genai/providers/openaibase"
 )
 
@@ -83,6 +84,12 @@ type (
 	ImageChoiceData = openaibase.ImageChoiceData
 	// GenOptionImage is an alias to the shared OpenAI image generation options.
 	GenOptionImage = openaibase.GenOptionImage
+	// ModerationRequest is an alias to the shared OpenAI moderation request type.
+	ModerationRequest = openaibase.ModerationRequest
+	// ModerationResponse is an alias to the shared OpenAI moderation response type.
+	ModerationResponse = openaibase.ModerationResponse
+	// ModerationResult is an alias to the shared OpenAI moderation result type.
+	ModerationResult = openaibase.ModerationResult
 	// Model is an alias to the shared OpenAI model type.
 	Model = openaibase.Model
 	// ModelsResponse is an alias to the shared OpenAI models response type.
@@ -93,6 +100,10 @@ type (
 	FileDeleteResponse = openaibase.FileDeleteResponse
 	// FileListResponse is an alias to the shared OpenAI file list response type.
 	FileListResponse = openaibase.FileListResponse
+	// VectorStore is an alias to the shared OpenAI vector store type.
+	VectorStore = openaibase.VectorStore
+	// VectorStoreFile is an alias to the shared OpenAI vector store file type.
+	VectorStoreFile = openaibase.VectorStoreFile
 	// BatchRequest is an alias to the shared OpenAI batch request type.
 	BatchRequest = openaibase.BatchRequest
 	// Batch is an alias to the shared OpenAI batch type.
@@ -192,23 +203,36 @@ func (r *Response) Init(msgs genai.Messages, model string, opts ...genai.GenOpti
 		}
 		switch v := opt.(type) {
 		case *GenOptionText:
-			r.Reasoning.Effort = v.ReasoningEffort
+			if v.ReasoningEffort != "" {
+				r.Reasoning.Effort = v.ReasoningEffort
+			}
 			r.ServiceTier = v.ServiceTier
-			r.Truncation = string(v.Truncation)
+			if v.Truncation != "" {
+				r.Truncation = string(v.Truncation)
+			}
 			r.PreviousResponseID = v.PreviousResponseID
+			if v.NoStore {
+				r.Store = false
+				r.Include = append(r.Include, "reasoning.encrypted_content")
+			}
+			r.Background = v.Background
 		case *genai.GenOptionText:
 			u, e := r.initOptionsText(v)
 			unsupported = append(unsupported, u...)
 			errs = append(errs, e...)
+		case *GenOptionTools:
+			r.initOptionsHostedTools(v)
 		case *genai.GenOptionTools:
 			errs = append(errs, r.initOptionsTools(v)...)
+		case *genai.GenOptionFileSearch:
+			r.Tools = append(r.Tools, Tool{Type: string(HostedToolFileSearch), FileSearchVectorStoreIDs: v.StoreIDs})
+			r.Include = append(r.Include, "file_search_call.results")
 		case *genai.GenOptionWeb:
 			if v.Search {
-				r.Tools = append(r.Tools, Tool{
-					Type: "web_search",
-					// SearchContextSize: "medium",
-				})
-				r.Include = []string{"web_search_call.action.sources"}
+				t := Tool{Type: "web_search"}
+				unsupported = append(unsupported, t.initWebSearch(v.SearchOptions)...)
+				r.Tools = append(r.Tools, t)
+				r.Include = append(r.Include, "web_search_call.action.sources")
 			}
 			if v.Fetch {
 				errs = append(errs, errors.New("unsupported GenOptionWeb.Fetch"))
@@ -217,6 +241,17 @@ func (r *Response) Init(msgs genai.Messages, model string, opts ...genai.GenOpti
 			return &base.ErrNotSupported{Options: []string{internal.TypeName(opt)}}
 		}
 	}
+	if isDeepResearch(model) {
+		// Deep research models require at least one data source.
+		if !slices.ContainsFunc(r.Tools, func(t Tool) bool { return t.Type == "web_search" || t.Type == "file_search" || t.Type == "mcp" }) {
+			r.Tools = append(r.Tools, Tool{Type: "web_search"})
+			r.Include = append(r.Include, "web_search_call.action.sources")
+		}
+		// Runs take tens of minutes, longer than what most HTTP connections survive.
+		if r.Store {
+			r.Background = true
+		}
+	}
 	if len(msgs) == 0 && r.PreviousResponseID == "" {
 		return errors.New("no messages provided")
 	}
@@ -244,7 +279,26 @@ func (r *Response) Init(msgs genai.Messages, model string, opts ...genai.GenOpti
 		case len(msgs[i].Replies) > 1:
 			// Goddam OpenAI. Handle messages with multiple tool calls by creating multiple messages.
 			var txt []genai.Reply
+			// Reasoning items must be sent back right before the tool calls that followed them. Reasoning without
+			// a reference to the item, e.g. from another provider, is dropped.
+			var reasoning *Message
+			flush := func() {
+				if reasoning != nil && reasoning.ID != "" {
+					r.Input = append(r.Input, *reasoning)
+				}
+				reasoning = nil
+			}
 			for j := range msgs[i].Replies {
+				if rep := &msgs[i].Replies[j]; rep.Reasoning != "" || rep.Opaque[opaqueReasoningID] != nil {
+					if reasoning == nil || !reasoning.addReasoning(rep) {
+						flush()
+						// The API requires the summary field even if empty.
+						reasoning = &Message{Type: MessageReasoning, Summary: []ReasoningSummary{}}
+						reasoning.addReasoning(rep)
+					}
+					continue
+				}
+				flush()
 				if !msgs[i].Replies[j].ToolCall.IsZero() {
 					msgCopy := msgs[i]
 					msgCopy.Replies = []genai.Reply{msgs[i].Replies[j]}
@@ -258,6 +312,7 @@ func (r *Response) Init(msgs genai.Messages, model string, opts ...genai.GenOpti
 					txt = append(txt, msgs[i].Replies[j])
 				}
 			}
+			flush()
 			if len(txt) != 0 {
 				// Create a copy of the message with only the non-tool call messages.
 				msgCopy := msgs[i]
@@ -279,6 +334,16 @@ func (r *Response) Init(msgs genai.Messages, model string, opts ...genai.GenOpti
 			}
 		}
 	}
+	// Sending back the screenshot means the computer action was executed, which acknowledges its safety checks.
+	pending := map[string][]SafetyCheck{}
+	for i := range r.Input {
+		switch r.Input[i].Type {
+		case MessageComputerCall:
+			pending[r.Input[i].CallID] = r.Input[i].PendingSafetyChecks
+		case MessageComputerCallOutput:
+			r.Input[i].AcknowledgedSafetyChecks = pending[r.Input[i].CallID]
+		}
+	}
 	// If we have unsupported features but no other errors, return a structured error.
 	if len(unsupported) > 0 && len(errs) == 0 {
 		return &base.ErrNotSupported{Options: unsupported}
@@ -350,6 +415,15 @@ func (r *Response) initOptionsText(v *genai.GenOptionText) ([]string, []error) {
 	if v.TopK != 0 {
 		unsupported = append(unsupported, "GenOptionText.TopK")
 	}
+	if v.N > 1 {
+		unsupported = append(unsupported, "GenOptionText.N")
+	}
+	if v.Grammar != "" {
+		unsupported = append(unsupported, "GenOptionText.Grammar")
+	}
+	if v.ReasoningEffort != "" && r.Reasoning.Effort == "" {
+		r.Reasoning.Effort = openaibase.ReasoningEffortFrom(v.ReasoningEffort)
+	}
 	if v.TopLogprobs > 0 {
 		r.TopLogprobs = v.TopLogprobs
 	}
@@ -362,6 +436,9 @@ func (r *Response) initOptionsText(v *genai.GenOptionText) ([]string, []error) {
 		r.Text.Format.Name = "response"
 		r.Text.Format.Strict = true
 		s, err := v.DecodeSchema()
+		if err == nil {
+			s, err = base.SchemaDialectOpenAIStrict.Lower(s)
+		}
 		if err != nil {
 			errs = append(errs, err)
 		} else {
@@ -375,7 +452,20 @@ func (r *Response) initOptionsText(v *genai.GenOptionText) ([]string, []error) {
 
 func (r *Response) initOptionsTools(v *genai.GenOptionTools) []error {
 	var errs []error
-	if len(v.Tools) != 0 {
+	if v.Computer != nil {
+		// https://platform.openai.com/docs/guides/tools-computer-use
+		env := v.Computer.Environment
+		if env == "" {
+			env = "browser"
+		}
+		r.Tools = append(r.Tools, Tool{Type: "computer_use_preview", DisplayWidth: v.Computer.Width, DisplayHeight: v.Computer.Height, Environment: env})
+		// The API requires it.
+		if r.Truncation == "" {
+			r.Truncation = string(TruncationAuto)
+		}
+	}
+	tools := v.AllowedTools()
+	if len(tools) != 0 {
 		r.ParallelToolCalls = true
 		switch v.Force {
 		case genai.ToolCallAny:
@@ -385,24 +475,44 @@ func (r *Response) initOptionsTools(v *genai.GenOptionTools) []error {
 		case genai.ToolCallNone:
 			r.ToolChoice = "none"
 		}
-		r.Tools = make([]Tool, len(v.Tools))
-		for i, t := range v.Tools {
+		for _, t := range tools {
 			if t.Name == "" {
 				errs = append(errs, errors.New("tool name is required"))
 			}
-			r.Tools[i].Type = "function"
-			r.Tools[i].Name = t.Name
-			r.Tools[i].Description = t.Description
 			s, err := t.GetInputSchema()
 			if err != nil {
 				errs = append(errs, err)
 			}
-			r.Tools[i].Parameters = s
+			r.Tools = append(r.Tools, Tool{Type: "function", Name: t.Name, Description: t.Description, Parameters: s})
 		}
 	}
 	return errs
 }
 
+func (r *Response) initOptionsHostedTools(v *GenOptionTools) {
+	for _, h := range v.HostedTools {
+		t := Tool{Type: string(h.Type)}
+		switch h.Type {
+		case HostedToolCodeInterpreter:
+			if h.ContainerID != "" {
+				t.Container.ID = h.ContainerID
+			} else {
+				t.Container.Type = "auto"
+				t.Container.FileIDs = h.FileIDs
+			}
+			// Return the logs and the generated files.
+			r.Include = append(r.Include, "code_interpreter_call.outputs")
+		case HostedToolFileSearch:
+			t.FileSearchVectorStoreIDs = h.VectorStoreIDs
+			// Return the retrieved chunks along the queries.
+			r.Include = append(r.Include, "file_search_call.results")
+		case HostedToolImageGeneration:
+			t.PartialImages = h.PartialImages
+		}
+		r.Tools = append(r.Tools, t)
+	}
+}
+
 // ReasoningConfig represents reasoning configuration for o-series models.
 type ReasoningConfig struct {
 	Context string          `json:"context,omitzero"` // "current_turn"
@@ -448,6 +558,17 @@ type Tool struct {
 	// Type == "file_search"
 	FileSearchVectorStoreIDs []string `json:"vector_store_ids,omitzero"`
 
+	// Type == "computer_use_preview"
+	DisplayWidth  int64  `json:"display_width,omitzero"`
+	DisplayHeight int64  `json:"display_height,omitzero"`
+	Environment   string `json:"environment,omitzero"` // "browser", "mac", "windows", "ubuntu", "linux"
+
+	// Type == "code_interpreter"
+	Container ToolContainer `json:"container,omitzero"`
+
+	// Type == "image_generation"
+	PartialImages int64 `json:"partial_images,omitzero"` // [0, 3]
+
 	// Type == "web_search"
 	Filters struct {
 		AllowedDomains []string `json:"allowed_domains,omitzero"`
@@ -463,6 +584,67 @@ type Tool struct {
 	} `json:"user_location,omitzero"`
 }
 
+// initWebSearch configures the "web_search" tool. It returns the unsupported options.
+func (t *Tool) initWebSearch(o *genai.WebSearchOptions) []string {
+	if o == nil {
+		return nil
+	}
+	var unsupported []string
+	t.Filters.AllowedDomains = o.AllowedDomains
+	if len(o.BlockedDomains) != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.BlockedDomains")
+	}
+	if o.MaxSearches != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.MaxSearches")
+	}
+	t.SearchContextSize = o.ContextSize
+	if l := &o.UserLocation; !l.IsZero() {
+		t.UserLocation.Type = "approximate"
+		t.UserLocation.City = l.City
+		t.UserLocation.Region = l.Region
+		t.UserLocation.Country = l.Country
+		t.UserLocation.Timezone = l.Timezone
+		if l.HasLatLng() {
+			unsupported = append(unsupported, "GenOptionWeb.SearchOptions.UserLocation.Latitude")
+		}
+	}
+	return unsupported
+}
+
+// ToolContainer is the container used by the "code_interpreter" tool.
+//
+// It is serialized as a string when ID is set, as an object otherwise.
+type ToolContainer struct {
+	ID string `json:"-"`
+
+	Type    string   `json:"type,omitzero"` // "auto"
+	FileIDs []string `json:"file_ids,omitzero"`
+}
+
+// IsZero returns true if the container is unset.
+func (t ToolContainer) IsZero() bool {
+	return t.ID == "" && t.Type == "" && len(t.FileIDs) == 0
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t ToolContainer) MarshalJSON() ([]byte, error) {
+	if t.ID != "" {
+		return json.Marshal(t.ID)
+	}
+	type alias ToolContainer
+	return json.Marshal(alias(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *ToolContainer) UnmarshalJSON(b []byte) error {
+	if len(b) != 0 && b[0] == '"' {
+		*t = ToolContainer{}
+		return json.Unmarshal(b, &t.ID)
+	}
+	type alias ToolContainer
+	return json.Unmarshal(b, (*alias)(t))
+}
+
 // MessageType controls what kind of content is allowed.
 //
 // This means a single message cannot contain multiple kind of calls at the time time. I really don't know
@@ -528,26 +710,276 @@ type Message struct {
 	Arguments string `json:"arguments,omitzero"` // JSON
 	Name      string `json:"name,omitzero"`
 
-	// Type == MessageFunctionCall, MessageFunctionCallOutput
+	// Type == MessageFunctionCall, MessageFunctionCallOutput, MessageComputerCall, MessageComputerCallOutput
 	CallID string `json:"call_id,omitzero"`
 
-	// Type == MessageFunctionCallOutput
-	Output string `json:"output,omitzero"` // JSON
+	// Type == MessageFunctionCallOutput, MessageComputerCallOutput
+	Output CallOutput `json:"output,omitzero"`
+
+	// Type == MessageComputerCall
+	PendingSafetyChecks []SafetyCheck `json:"pending_safety_checks,omitzero"`
+	// Type == MessageComputerCallOutput
+	AcknowledgedSafetyChecks []SafetyCheck `json:"acknowledged_safety_checks,omitzero"`
 
 	// Type == MessageReasoning
 	EncryptedContent string             `json:"encrypted_content,omitzero"`
 	Summary          []ReasoningSummary `json:"summary,omitzero"`
 
-	// Type == MessageWebSearchCall
+	// Type == MessageWebSearchCall, MessageComputerCall
 	Action struct {
-		Type    string   `json:"type,omitzero"` // "search"
+		// MessageWebSearchCall: "search", "open_page", "find_in_page"
+		// MessageComputerCall: "click", "double_click", "drag", "keypress", "move", "screenshot", "scroll", "type",
+		// "wait"
+		Type    string   `json:"type,omitzero"`
 		Queries []string `json:"queries,omitzero"`
 		Query   string   `json:"query,omitzero"`
+		URL     string   `json:"url,omitzero"`     // "open_page", "find_in_page"
+		Pattern string   `json:"pattern,omitzero"` // "find_in_page"
 		Sources []struct {
 			Type string `json:"type,omitzero"` // "url"
 			URL  string `json:"url,omitzero"`
 		} `json:"sources,omitzero"`
+
+		X       int64                 `json:"x,omitzero"`
+		Y       int64                 `json:"y,omitzero"`
+		Button  string                `json:"button,omitzero"` // "left", "right", "wheel", "back", "forward"
+		Path    []genai.ComputerPoint `json:"path,omitzero"`
+		ScrollX int64                 `json:"scroll_x,omitzero"`
+		ScrollY int64                 `json:"scroll_y,omitzero"`
+		Text    string                `json:"text,omitzero"`
+		Keys    []string              `json:"keys,omitzero"`
 	} `json:"action,omitzero"`
+
+	// Type == MessageCodeInterpreterCall
+	Code        string                  `json:"code,omitzero"`
+	ContainerID string                  `json:"container_id,omitzero"`
+	Outputs     []CodeInterpreterOutput `json:"outputs,omitzero"`
+
+	// Type == MessageImageGenerationCall
+	Result        string `json:"result,omitzero"` // base64
+	RevisedPrompt string `json:"revised_prompt,omitzero"`
+	Background    string `json:"background,omitzero"`    // "opaque", "transparent"
+	OutputFormat  string `json:"output_format,omitzero"` // "png", "jpeg", "webp"
+	Quality       string `json:"quality,omitzero"`       // "low", "medium", "high"
+	Size          string `json:"size,omitzero"`          // "1024x1024"
+}
+
+// Reply.Opaque keys used to round trip reasoning items.
+const (
+	opaqueReasoningID      = "reasoning_id"
+	opaqueEncryptedContent = "encrypted_content"
+)
+
+// reasoningOpaque returns the Reply.Opaque referencing the reasoning item, if it has an ID.
+func (m *Message) reasoningOpaque() map[string]any {
+	if m.ID == "" {
+		return nil
+	}
+	opaque := map[string]any{opaqueReasoningID: m.ID}
+	if m.EncryptedContent != "" {
+		opaque[opaqueEncryptedContent] = m.EncryptedContent
+	}
+	return opaque
+}
+
+// addReasoning adds the reasoning reply to the reasoning input item. It returns false if the reply belongs to
+// a different reasoning item.
+func (m *Message) addReasoning(in *genai.Reply) bool {
+	id, _ := in.Opaque[opaqueReasoningID].(string)
+	if id != "" {
+		if m.ID != "" {
+			return false
+		}
+		m.ID = id
+		m.EncryptedContent, _ = in.Opaque[opaqueEncryptedContent].(string)
+	}
+	if in.Reasoning != "" {
+		m.Summary = append(m.Summary, ReasoningSummary{Type: "summary_text", Text: in.Reasoning})
+	}
+	return true
+}
+
+// webSearchCitation converts a web search call into a citation.
+//
+// Deep research models also open and search in pages.
+func (m *Message) webSearchCitation() (genai.Citation, error) {
+	switch m.Action.Type {
+	case "search":
+		c := genai.Citation{Sources: make([]genai.CitationSource, len(m.Action.Sources)+1)}
+		c.Sources[0].Type = genai.CitationWebQuery
+		c.Sources[0].Snippet = m.Action.Query
+		for i, src := range m.Action.Sources {
+			c.Sources[i+1].Type = genai.CitationWeb
+			c.Sources[i+1].URL = src.URL
+		}
+		return c, nil
+	case "open_page", "find_in_page":
+		return genai.Citation{Sources: []genai.CitationSource{{Type: genai.CitationWeb, URL: m.Action.URL, Snippet: m.Action.Pattern}}}, nil
+	default:
+		return genai.Citation{}, &internal.BadError{Err: fmt.Errorf("implement action type %q", m.Action.Type)}
+	}
+}
+
+// serverToolCall returns the progress of a hosted tool call item: web search, file search or code interpreter.
+func (m *Message) serverToolCall() genai.ServerToolCall {
+	s := genai.ServerToolCall{ID: m.ID, Status: genai.ServerToolCallCompleted}
+	switch m.Status {
+	case "in_progress", "searching", "interpreting":
+		s.Status = genai.ServerToolCallInProgress
+	case "failed", "incomplete":
+		s.Status = genai.ServerToolCallFailed
+	}
+	var input any
+	switch m.Type {
+	case MessageWebSearchCall:
+		s.Name = genai.ServerToolWebSearch
+		if m.Action.Type != "" {
+			input = struct {
+				Type    string   `json:"type"`
+				Query   string   `json:"query,omitzero"`
+				Queries []string `json:"queries,omitzero"`
+				URL     string   `json:"url,omitzero"`
+				Pattern string   `json:"pattern,omitzero"`
+			}{m.Action.Type, m.Action.Query, m.Action.Queries, m.Action.URL, m.Action.Pattern}
+		}
+	case MessageFileSearchCall:
+		s.Name = genai.ServerToolFileSearch
+		if len(m.Queries) != 0 {
+			input = map[string]any{"queries": m.Queries}
+		}
+	case MessageCodeInterpreterCall:
+		s.Name = genai.ServerToolCodeExecution
+		if m.Code != "" {
+			input = map[string]any{"code": m.Code}
+		}
+		var logs []string
+		for _, o := range m.Outputs {
+			if o.Type == "logs" && o.Logs != "" {
+				logs = append(logs, o.Logs)
+			}
+		}
+		s.Output = strings.Join(logs, "\n")
+	}
+	if input != nil {
+		b, _ := json.Marshal(input)
+		s.Input = string(b)
+	}
+	return s
+}
+
+// CallOutput is the output of a tool call.
+//
+// It is serialized as a string for MessageFunctionCallOutput and as an object for MessageComputerCallOutput.
+type CallOutput struct {
+	// Type == MessageFunctionCallOutput
+	Text string
+	// Type == MessageComputerCallOutput
+	Type     string `json:"type,omitzero"` // "computer_screenshot"
+	ImageURL string `json:"image_url,omitzero"`
+	FileID   string `json:"file_id,omitzero"`
+}
+
+// IsZero implements the json omitzero interface.
+func (c *CallOutput) IsZero() bool {
+	return c.Text == "" && c.Type == "" && c.ImageURL == "" && c.FileID == ""
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c *CallOutput) MarshalJSON() ([]byte, error) {
+	if c.Type == "" {
+		return json.Marshal(c.Text)
+	}
+	type Alias CallOutput
+	return json.Marshal((*Alias)(c))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *CallOutput) UnmarshalJSON(b []byte) error {
+	if len(b) != 0 && b[0] == '"' {
+		return json.Unmarshal(b, &c.Text)
+	}
+	type Alias CallOutput
+	return json.Unmarshal(b, (*Alias)(c))
+}
+
+// SafetyCheck is a safety check raised on a computer call.
+//
+// https://platform.openai.com/docs/guides/tools-computer-use#acknowledge-safety-checks
+type SafetyCheck struct {
+	ID      string `json:"id,omitzero"`
+	Code    string `json:"code,omitzero"`
+	Message string `json:"message,omitzero"`
+}
+
+// ToolCall.Opaque keys used to round trip computer calls.
+const (
+	opaqueComputerCallID      = "computer_call_id"
+	opaquePendingSafetyChecks = "pending_safety_checks"
+)
+
+// computerAction converts the computer call action to the genai equivalent.
+func (m *Message) computerAction() (genai.ComputerAction, error) {
+	a := genai.ComputerAction{
+		Type:    genai.ComputerActionType(m.Action.Type),
+		X:       m.Action.X,
+		Y:       m.Action.Y,
+		Button:  m.Action.Button,
+		Path:    m.Action.Path,
+		ScrollX: m.Action.ScrollX,
+		ScrollY: m.Action.ScrollY,
+		Text:    m.Action.Text,
+		Keys:    m.Action.Keys,
+	}
+	if a.Button == "wheel" {
+		a.Button = "middle"
+	}
+	if err := a.Validate(); err != nil {
+		return a, &internal.BadError{Err: fmt.Errorf("implement computer action %q: %w", m.Action.Type, err)}
+	}
+	return a, nil
+}
+
+// fromComputerAction converts the genai computer action into the computer call action.
+func (m *Message) fromComputerAction(t *genai.ToolCall) error {
+	a := genai.ComputerAction{}
+	if err := json.Unmarshal([]byte(t.Arguments), &a); err != nil {
+		return fmt.Errorf("failed to decode computer action: %w", err)
+	}
+	m.Type = MessageComputerCall
+	m.ID, _ = t.Opaque[opaqueComputerCallID].(string)
+	m.CallID = t.ID
+	m.Status = "completed"
+	m.Action.Type = string(a.Type)
+	m.Action.X = a.X
+	m.Action.Y = a.Y
+	m.Action.Button = a.Button
+	if m.Action.Button == "middle" {
+		m.Action.Button = "wheel"
+	}
+	m.Action.Path = a.Path
+	m.Action.ScrollX = a.ScrollX
+	m.Action.ScrollY = a.ScrollY
+	m.Action.Text = a.Text
+	m.Action.Keys = a.Keys
+	// The field is required even if empty. Opaque may have been through a JSON round trip so re-decode it.
+	m.PendingSafetyChecks = []SafetyCheck{}
+	if v, ok := t.Opaque[opaquePendingSafetyChecks]; ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &m.PendingSafetyChecks); err != nil {
+			return fmt.Errorf("failed to decode pending safety checks: %w", err)
+		}
+	}
+	return nil
+}
+
+// CodeInterpreterOutput is an output of the code interpreter tool.
+type CodeInterpreterOutput struct {
+	Type string `json:"type,omitzero"` // "logs", "image"
+	Logs string `json:"logs,omitzero"`
+	URL  string `json:"url,omitzero"`
 }
 
 // From must be called with at most one ToolCallResults.
@@ -558,9 +990,26 @@ func (m *Message) From(in *genai.Message) (bool, error) {
 	if len(in.ToolCallResults) != 0 {
 		// Handle multiple tool call results by creating multiple messages
 		// The caller (Init method) should handle this by creating separate messages
+		t := &in.ToolCallResults[0]
+		m.CallID = t.ID
+		if t.Name == genai.ComputerToolName && !t.Doc.IsZero() {
+			// https://platform.openai.com/docs/guides/tools-computer-use#4-capture-the-updated-screenshot
+			c := Content{}
+			if err := c.FromRequest(&genai.Request{Doc: t.Doc}); err != nil {
+				return false, err
+			}
+			if c.Type != ContentInputImage {
+				return false, errors.New("computer call output must be an image")
+			}
+			m.Type = MessageComputerCallOutput
+			m.Output = CallOutput{Type: "computer_screenshot", ImageURL: c.ImageURL}
+			return false, nil
+		}
+		if !t.Doc.IsZero() {
+			return false, errors.New("field ToolCallResult.Doc is only supported for computer actions")
+		}
 		m.Type = MessageFunctionCallOutput
-		m.CallID = in.ToolCallResults[0].ID
-		m.Output = in.ToolCallResults[0].Result
+		m.Output = CallOutput{Text: t.Result}
 		return false, nil
 	}
 	if len(in.Requests) != 0 {
@@ -578,6 +1027,9 @@ func (m *Message) From(in *genai.Message) (bool, error) {
 		// Handle multiple tool calls by creating multiple messages
 		// The caller (Init method) should handle this by creating separate messages
 		if !in.Replies[0].ToolCall.IsZero() {
+			if _, ok := in.Replies[0].ToolCall.Opaque[opaqueComputerCallID]; ok {
+				return false, m.fromComputerAction(&in.Replies[0].ToolCall)
+			}
 			if len(in.Replies[0].ToolCall.Opaque) != 0 {
 				return false, &internal.BadError{Err: errors.New("field ToolCall.Opaque not supported")}
 			}
@@ -590,10 +1042,14 @@ func (m *Message) From(in *genai.Message) (bool, error) {
 		m.Type = MessageMessage
 		m.Role = "assistant"
 		for j := range in.Replies {
-			// TODO: should we send it back, at least the ID?
+			// Reasoning items are sent back as separate input items by Response.Init.
 			if in.Replies[j].Reasoning != "" {
 				continue
 			}
+			// Hosted tool calls are kept server side.
+			if len(in.Replies[j].Opaque) != 0 || !in.Replies[j].ServerToolCall.IsZero() {
+				continue
+			}
 			m.Content = append(m.Content, Content{})
 			if err := m.Content[len(m.Content)-1].FromReply(&in.Replies[j]); err != nil {
 				return false, fmt.Errorf("reply #%d: %w", j, err)
@@ -625,21 +1081,24 @@ func (m *Message) To(out *genai.Message) error {
 			}
 			out.Replies = append(out.Replies, genai.Reply{Reasoning: m.Summary[i].Text})
 		}
+		// Keep the reference to the reasoning item so it can be sent back along the following tool calls.
+		if opaque := m.reasoningOpaque(); opaque != nil {
+			if len(m.Summary) != 0 {
+				out.Replies[len(out.Replies)-len(m.Summary)].Opaque = opaque
+			} else {
+				out.Replies = append(out.Replies, genai.Reply{Opaque: opaque})
+			}
+		}
 	case MessageFunctionCall:
 		out.Replies = append(out.Replies, genai.Reply{ToolCall: genai.ToolCall{ID: m.CallID, Name: m.Name, Arguments: m.Arguments}})
 	case MessageWebSearchCall:
-		if m.Action.Type != "search" {
-			return &internal.BadError{Err: fmt.Errorf("implement action type %q", m.Action.Type)}
-		}
-		c := genai.Citation{Sources: make([]genai.CitationSource, len(m.Action.Sources)+1)}
-		c.Sources[0].Type = genai.CitationWebQuery
-		c.Sources[0].Snippet = m.Action.Query
-		for i, src := range m.Action.Sources {
-			c.Sources[i+1].Type = genai.CitationWeb
-			c.Sources[i+1].URL = src.URL
+		c, err := m.webSearchCitation()
+		if err != nil {
+			return err
 		}
-		out.Replies = append(out.Replies, genai.Reply{Citation: c})
+		out.Replies = append(out.Replies, genai.Reply{ServerToolCall: m.serverToolCall()}, genai.Reply{Citation: c})
 	case MessageFileSearchCall:
+		out.Replies = append(out.Replies, genai.Reply{ServerToolCall: m.serverToolCall()})
 		for _, q := range m.Queries {
 			out.Replies = append(out.Replies, genai.Reply{Citation: genai.Citation{
 				Sources: []genai.CitationSource{{Type: genai.CitationWebQuery, Snippet: q}},
@@ -649,13 +1108,68 @@ func (m *Message) To(out *genai.Message) error {
 			out.Replies = append(out.Replies, genai.Reply{Citation: genai.Citation{
 				CitedText: r.Text,
 				Sources: []genai.CitationSource{{
-					Type:  genai.CitationDocument,
-					ID:    r.FileID,
-					Title: r.Filename,
+					Type:         genai.CitationDocument,
+					ID:           r.FileID,
+					Title:        r.Filename,
+					DocumentName: r.Filename,
+					Confidence:   r.Score,
 				}},
 			}})
 		}
-	case MessageComputerCall, MessageImageGenerationCall, MessageCodeInterpreterCall, MessageLocalShellCall, MessageMcpListTools, MessageMcpApprovalRequest, MessageMcpCall, MessageComputerCallOutput, MessageFunctionCallOutput, MessageLocalShellCallOutput, MessageMcpApprovalResponse, MessageItemReference:
+	case MessageCodeInterpreterCall:
+		out.Replies = append(out.Replies, genai.Reply{ServerToolCall: m.serverToolCall()})
+		if m.Code != "" {
+			out.Replies = append(out.Replies, genai.Reply{
+				Text:   m.Code,
+				Opaque: map[string]any{"type": string(m.Type), "id": m.ID, "container_id": m.ContainerID},
+			})
+		}
+		for _, o := range m.Outputs {
+			switch o.Type {
+			case "logs":
+				if o.Logs != "" {
+					out.Replies = append(out.Replies, genai.Reply{Text: o.Logs, Opaque: map[string]any{"type": "code_interpreter_logs"}})
+				}
+			case "image":
+				out.Replies = append(out.Replies, genai.Reply{
+					Doc:    genai.Doc{Filename: "image.png", URL: o.URL},
+					Opaque: map[string]any{"type": "code_interpreter_image"},
+				})
+			default:
+				return &internal.BadError{Err: fmt.Errorf("implement code interpreter output type %q", o.Type)}
+			}
+		}
+	case MessageImageGenerationCall:
+		if m.Result == "" {
+			return nil
+		}
+		data, err := base64.StdEncoding.DecodeString(m.Result)
+		if err != nil {
+			return fmt.Errorf("failed to decode generated image: %w", err)
+		}
+		opaque := map[string]any{"type": string(m.Type), "id": m.ID}
+		if m.RevisedPrompt != "" {
+			opaque["revised_prompt"] = m.RevisedPrompt
+		}
+		out.Replies = append(out.Replies, genai.Reply{
+			Doc:    genai.Doc{Filename: "image." + imageExt(m.OutputFormat), Src: &bb.BytesBuffer{D: data}},
+			Opaque: opaque,
+		})
+	case MessageComputerCall:
+		a, err := m.computerAction()
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(&a)
+		if err != nil {
+			return err
+		}
+		opaque := map[string]any{opaqueComputerCallID: m.ID}
+		if len(m.PendingSafetyChecks) != 0 {
+			opaque[opaquePendingSafetyChecks] = m.PendingSafetyChecks
+		}
+		out.Replies = append(out.Replies, genai.Reply{ToolCall: genai.ToolCall{ID: m.CallID, Name: genai.ComputerToolName, Arguments: string(b), Opaque: opaque}})
+	case MessageLocalShellCall, MessageMcpListTools, MessageMcpApprovalRequest, MessageMcpCall, MessageComputerCallOutput, MessageFunctionCallOutput, MessageLocalShellCallOutput, MessageMcpApprovalResponse, MessageItemReference:
 		return &internal.BadError{Err: fmt.Errorf("unsupported output type %q", m.Type)}
 	default:
 		return &internal.BadError{Err: fmt.Errorf("unsupported output type %q", m.Type)}
@@ -663,6 +1177,14 @@ func (m *Message) To(out *genai.Message) error {
 	return nil
 }
 
+// imageExt returns the file extension for an output_format value.
+func imageExt(format string) string {
+	if format == "" {
+		return "png"
+	}
+	return format
+}
+
 // ContentType defines the data being transported. It only includes actual data (text, files), no tool call nor result.
 type ContentType string
 
@@ -720,7 +1242,7 @@ func (c *Content) To() ([]genai.Reply, error) {
 			ci = genai.Citation{
 				StartIndex: a.StartIndex,
 				EndIndex:   a.EndIndex,
-				Sources:    []genai.CitationSource{{Type: genai.CitationDocument, ID: a.FileID}},
+				Sources:    []genai.CitationSource{{Type: genai.CitationDocument, ID: a.FileID, DocumentName: a.Filename}},
 			}
 		case "file_path":
 			ci = genai.Citation{
@@ -765,7 +1287,10 @@ func (c *Content) FromRequest(in *genai.Request) error {
 		switch {
 		case strings.HasPrefix(mimeType, "image/"):
 			c.Type = ContentInputImage
-			c.Detail = "auto" // TODO: Make it configurable.
+			c.Detail = "auto"
+			if in.Doc.Detail != "" {
+				c.Detail = string(in.Doc.Detail)
+			}
 			if in.Doc.URL == "" {
 				c.ImageURL = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
 			} else {
@@ -826,7 +1351,10 @@ func (c *Content) FromReply(in *genai.Reply) error {
 		switch {
 		case strings.HasPrefix(mimeType, "image/"):
 			c.Type = ContentInputImage
-			c.Detail = "auto" // TODO: Make it configurable.
+			c.Detail = "auto"
+			if in.Doc.Detail != "" {
+				c.Detail = string(in.Doc.Detail)
+			}
 			if in.Doc.URL == "" {
 				c.ImageURL = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
 			} else {
@@ -890,6 +1418,9 @@ type Annotation struct {
 	// Type == "file_citation", "file_path"
 	Index int64 `json:"index,omitzero"`
 
+	// Type == "file_citation", "container_file_citation"
+	Filename string `json:"filename,omitzero"`
+
 	// Type == "url_citation"
 	URL   string `json:"url,omitzero"`
 	Title string `json:"title,omitzero"`
@@ -1042,7 +1573,7 @@ type ResponseStreamChunkResponse struct {
 	Part Content `json:"part,omitzero"`
 
 	// Type == ResponseOutputTextDelta, ResponseRefusalDelta, ResponseFunctionCallArgumentsDelta,
-	// ResponseReasoningSummaryTextDelta
+	// ResponseReasoningSummaryTextDelta, ResponseCodeInterpreterCallDelta
 	Delta string `json:"delta,omitzero"`
 
 	// Type == ResponseOutputTextDone, ResponseReasoningSummaryTextDone
@@ -1062,6 +1593,17 @@ type ResponseStreamChunkResponse struct {
 	Annotation      Annotation `json:"annotation,omitzero"`
 	AnnotationIndex int64      `json:"annotation_index,omitzero"`
 
+	// Type == ResponseImageGenerationCallPartialImage
+	PartialImageB64   string `json:"partial_image_b64,omitzero"`
+	PartialImageIndex int64  `json:"partial_image_index,omitzero"`
+	Background        string `json:"background,omitzero"`
+	OutputFormat      string `json:"output_format,omitzero"`
+	Quality           string `json:"quality,omitzero"`
+	Size              string `json:"size,omitzero"`
+
+	// Type == ResponseCodeInterpreterCallDone
+	Code string `json:"code,omitzero"`
+
 	// Type == ResponseError
 	ErrorResponse
 
@@ -1148,3 +1690,99 @@ type BatchRequestOutput struct {
 		Body       Response `json:"body"`
 	} `json:"response"`
 }
+
+//
+// Realtime API types.
+//
+
+// RealtimeClientEvent is a client event sent over the Realtime API WebSocket.
+//
+// https://platform.openai.com/docs/api-reference/realtime-client-events
+type RealtimeClientEvent struct {
+	Type    string                 `json:"type"` // "session.update", "input_audio_buffer.append", "input_audio_buffer.commit", "conversation.item.create", "response.create"
+	Session *RealtimeSessionConfig `json:"session,omitzero"`
+	Audio   string                 `json:"audio,omitzero"` // base64 encoded PCM16.
+	Item    *RealtimeItem          `json:"item,omitzero"`
+}
+
+// RealtimeSessionConfig is documented at https://platform.openai.com/docs/api-reference/realtime-client-events/session/update
+type RealtimeSessionConfig struct {
+	Type             string               `json:"type"` // "realtime"
+	Instructions     string               `json:"instructions,omitzero"`
+	OutputModalities []string             `json:"output_modalities,omitzero"` // "audio" or "text"
+	Audio            RealtimeSessionAudio `json:"audio,omitzero"`
+}
+
+// RealtimeSessionAudio is the audio configuration of a realtime session.
+type RealtimeSessionAudio struct {
+	Input struct {
+		Format RealtimeAudioFormat `json:"format,omitzero"`
+		// TurnDetection is set to null to disable server-side voice activity detection.
+		TurnDetection json.RawMessage `json:"turn_detection,omitzero"`
+	} `json:"input,omitzero"`
+	Output struct {
+		Format RealtimeAudioFormat `json:"format,omitzero"`
+		Voice  string              `json:"voice,omitzero"`
+	} `json:"output,omitzero"`
+}
+
+// RealtimeAudioFormat is the format of audio frames.
+type RealtimeAudioFormat struct {
+	Type string `json:"type,omitzero"` // "audio/pcm", "audio/pcmu", "audio/pcma"
+	Rate int64  `json:"rate,omitzero"` // Only 24000 is supported for "audio/pcm".
+}
+
+// RealtimeItem is a conversation item.
+type RealtimeItem struct {
+	Type    string                `json:"type"` // "message"
+	Role    string                `json:"role"` // "user"
+	Content []RealtimeItemContent `json:"content"`
+}
+
+// RealtimeItemContent is the content of a conversation item.
+type RealtimeItemContent struct {
+	Type string `json:"type"` // "input_text"
+	Text string `json:"text,omitzero"`
+}
+
+// RealtimeServerEvent is a server event received over the Realtime API WebSocket.
+//
+// Only the fields used by this package are decoded. The protocol has dozens of event types with large
+// payloads, the rest is ignored.
+//
+// https://platform.openai.com/docs/api-reference/realtime-server-events
+type RealtimeServerEvent struct {
+	Type     string           `json:"type"`
+	EventID  string           `json:"event_id"`
+	Delta    string           `json:"delta"`
+	Response RealtimeResponse `json:"response"`
+	Error    APIError         `json:"error"`
+}
+
+// RealtimeResponse is the response object in "response.done" events.
+type RealtimeResponse struct {
+	ID     string        `json:"id"`
+	Status string        `json:"status"` // "completed", "cancelled", "failed", "incomplete"
+	Usage  RealtimeUsage `json:"usage"`
+}
+
+// RealtimeUsage is the token usage of a realtime response.
+type RealtimeUsage struct {
+	TotalTokens       int64 `json:"total_tokens"`
+	InputTokens       int64 `json:"input_tokens"`
+	OutputTokens      int64 `json:"output_tokens"`
+	InputTokenDetails struct {
+		CachedTokens int64 `json:"cached_tokens"`
+		TextTokens   int64 `json:"text_tokens"`
+		AudioTokens  int64 `json:"audio_tokens"`
+	} `json:"input_token_details"`
+	OutputTokenDetails struct {
+		TextTokens  int64 `json:"text_tokens"`
+		AudioTokens int64 `json:"audio_tokens"`
+	} `json:"output_token_details"`
+}
+
+// isDeepResearch returns true for the deep research models, e.g. o3-deep-research.
+func isDeepResearch(model string) bool {
+	return strings.Contains(model, "deep-research")
+}
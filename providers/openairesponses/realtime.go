@@ -0,0 +1,292 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Realtime API support for bidirectional audio sessions.
+//
+// See https://platform.openai.com/docs/guides/realtime-websocket
+
+package openairesponses
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal"
+)
+
+// GenOptionLive defines OpenAI Realtime specific options for Client.Live.
+type GenOptionLive struct {
+	// Voice is the voice used for audio output, e.g. "alloy", "marin", "cedar".
+	Voice string
+	// TextOnly requests text output instead of audio.
+	TextOnly bool
+	// DisableVAD disables server-side voice activity detection. When disabled, the client must send
+	// LiveInput.EndOfTurn to request a response.
+	DisableVAD bool
+}
+
+// Validate implements genai.Validatable.
+func (o *GenOptionLive) Validate() error {
+	if o.TextOnly && o.Voice != "" {
+		return errors.New("field Voice can't be used with TextOnly")
+	}
+	return nil
+}
+
+// Live implements genai.ProviderLive.
+//
+// It opens a session with the OpenAI Realtime API. The model must be a realtime model, e.g. "gpt-realtime".
+//
+// Audio input and output is PCM16 at 24kHz mono.
+//
+// Supported options are *genai.GenOptionText's SystemPrompt and *GenOptionLive.
+func (c *Client) Live(ctx context.Context, opts ...genai.GenOption) (genai.LiveSession, error) {
+	if c.impl.Model == "" {
+		return nil, errors.New("a model is required")
+	}
+	cfg, err := initRealtimeSession(opts)
+	if err != nil {
+		return nil, err
+	}
+	wsURL := strings.Replace(c.baseURL, "https://", "wss://", 1)
+	wsURL = strings.Replace(wsURL, "http://", "ws://", 1)
+	wsURL += "/realtime?model=" + url.QueryEscape(c.impl.Model)
+	wsCfg, err := c.wsConfig(wsURL)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := wsCfg.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to websocket %s: %w", wsURL, err)
+	}
+	s := &LiveSession{ws: &websocketConn{raw}, lenient: c.impl.Lenient}
+	if err := s.send(&RealtimeClientEvent{Type: "session.update", Session: cfg}); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func initRealtimeSession(opts []genai.GenOption) (*RealtimeSessionConfig, error) {
+	cfg := &RealtimeSessionConfig{Type: "realtime"}
+	cfg.Audio.Input.Format = RealtimeAudioFormat{Type: "audio/pcm", Rate: 24000}
+	cfg.Audio.Output.Format = RealtimeAudioFormat{Type: "audio/pcm", Rate: 24000}
+	var unsupported []string
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return nil, err
+		}
+		switch v := opt.(type) {
+		case *genai.GenOptionText:
+			cfg.Instructions = v.SystemPrompt
+			if v.Temperature != 0 {
+				unsupported = append(unsupported, "GenOptionText.Temperature")
+			}
+			if v.TopP != 0 {
+				unsupported = append(unsupported, "GenOptionText.TopP")
+			}
+			if v.TopK != 0 {
+				unsupported = append(unsupported, "GenOptionText.TopK")
+			}
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
+			if len(v.Stop) != 0 {
+				unsupported = append(unsupported, "GenOptionText.Stop")
+			}
+			if v.MaxTokens != 0 {
+				unsupported = append(unsupported, "GenOptionText.MaxTokens")
+			}
+		case *GenOptionLive:
+			cfg.Audio.Output.Voice = v.Voice
+			if v.TextOnly {
+				cfg.OutputModalities = []string{"text"}
+			}
+			if v.DisableVAD {
+				cfg.Audio.Input.TurnDetection = json.RawMessage("null")
+			}
+		default:
+			unsupported = append(unsupported, internal.TypeName(opt))
+		}
+	}
+	if len(unsupported) != 0 {
+		return nil, &base.ErrNotSupported{Options: unsupported}
+	}
+	return cfg, nil
+}
+
+// LiveSession is a bidirectional session with the OpenAI Realtime API.
+//
+// Create via Client.Live().
+type LiveSession struct {
+	lenient bool
+
+	mu sync.Mutex
+	// Protected by mu.
+	ws wsConn
+}
+
+// Send implements genai.LiveSession.
+func (s *LiveSession) Send(ctx context.Context, in *genai.LiveInput) error {
+	if err := in.Validate(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	switch {
+	case len(in.Audio) != 0:
+		return s.send(&RealtimeClientEvent{Type: "input_audio_buffer.append", Audio: base64.StdEncoding.EncodeToString(in.Audio)})
+	case in.Text != "":
+		item := &RealtimeItem{Type: "message", Role: "user", Content: []RealtimeItemContent{{Type: "input_text", Text: in.Text}}}
+		if err := s.send(&RealtimeClientEvent{Type: "conversation.item.create", Item: item}); err != nil {
+			return err
+		}
+		return s.send(&RealtimeClientEvent{Type: "response.create"})
+	default:
+		if err := s.send(&RealtimeClientEvent{Type: "input_audio_buffer.commit"}); err != nil {
+			return err
+		}
+		return s.send(&RealtimeClientEvent{Type: "response.create"})
+	}
+}
+
+// Recv implements genai.LiveSession.
+//
+// The context cancellation is only checked between server events. Call Close() to unblock a pending read.
+func (s *LiveSession) Recv(ctx context.Context) (iter.Seq[genai.LiveEvent], func() error) {
+	var finalErr error
+	fnEvents := func(yield func(genai.LiveEvent) bool) {
+		for {
+			if finalErr = ctx.Err(); finalErr != nil {
+				return
+			}
+			s.mu.Lock()
+			ws := s.ws
+			s.mu.Unlock()
+			if ws == nil {
+				return
+			}
+			var msg string
+			if err := ws.Receive(&msg); err != nil {
+				if !errors.Is(err, io.EOF) && !strings.Contains(err.Error(), "use of closed network connection") {
+					finalErr = fmt.Errorf("websocket receive: %w", err)
+				}
+				return
+			}
+			var evt RealtimeServerEvent
+			if err := json.Unmarshal([]byte(msg), &evt); err != nil {
+				finalErr = fmt.Errorf("failed to decode event: %w; raw: %s", err, msg)
+				return
+			}
+			out, ok, err := s.processEvent(&evt)
+			if err != nil {
+				finalErr = err
+				return
+			}
+			if ok && !yield(out) {
+				return
+			}
+		}
+	}
+	return fnEvents, func() error { return finalErr }
+}
+
+// processEvent converts a server event into a genai.LiveEvent. It returns false when the event is not
+// relevant.
+func (s *LiveSession) processEvent(evt *RealtimeServerEvent) (genai.LiveEvent, bool, error) {
+	out := genai.LiveEvent{}
+	switch evt.Type {
+	case "error":
+		return out, false, &evt.Error
+	case "response.output_audio.delta", "response.audio.delta":
+		b, err := base64.StdEncoding.DecodeString(evt.Delta)
+		if err != nil {
+			return out, false, fmt.Errorf("failed to decode audio: %w", err)
+		}
+		out.Reply.Doc = genai.Doc{Filename: "audio.pcm", Src: bytes.NewReader(b)}
+	case "response.output_audio_transcript.delta", "response.audio_transcript.delta",
+		"response.output_text.delta", "response.text.delta":
+		if evt.Delta == "" {
+			return out, false, nil
+		}
+		out.Reply.Text = evt.Delta
+	case "input_audio_buffer.speech_started":
+		out.Interrupted = true
+	case "response.done":
+		out.TurnComplete = true
+		u := &evt.Response.Usage
+		out.Usage = genai.Usage{
+			InputTokens:       u.InputTokens,
+			InputCachedTokens: u.InputTokenDetails.CachedTokens,
+			OutputTokens:      u.OutputTokens,
+			TotalTokens:       u.TotalTokens,
+		}
+		switch evt.Response.Status {
+		case "completed":
+			out.Usage.FinishReason = genai.FinishedStop
+		case "incomplete":
+			out.Usage.FinishReason = genai.FinishedLength
+		case "cancelled":
+			out.Interrupted = true
+		case "failed":
+			return out, false, fmt.Errorf("response %s failed", evt.Response.ID)
+		default:
+			if !s.lenient {
+				return out, false, fmt.Errorf("unexpected response status %q", evt.Response.Status)
+			}
+		}
+	default:
+		// There are many other events like session.created, rate_limits.updated, etc.
+		return out, false, nil
+	}
+	return out, true, nil
+}
+
+// Close implements io.Closer. It closes the WebSocket connection.
+func (s *LiveSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ws == nil {
+		return nil
+	}
+	err := s.ws.Close()
+	s.ws = nil
+	return err
+}
+
+func (s *LiveSession) send(evt *RealtimeClientEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ws == nil {
+		return errors.New("websocket connection is closed")
+	}
+	return s.ws.Send(string(data))
+}
+
+var (
+	_ genai.ProviderLive = &Client{}
+	_ genai.LiveSession  = &LiveSession{}
+)
@@ -0,0 +1,141 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for realtime.go
+
+package openairesponses
+
+import (
+	"encoding/base64"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/maruel/genai"
+)
+
+func TestLiveSession(t *testing.T) {
+	audio := base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4})
+	ws := &fakeWS{recv: []string{
+		`{"type":"session.created","event_id":"1","session":{"id":"sess_1"}}`,
+		`{"type":"input_audio_buffer.speech_started","event_id":"2","audio_start_ms":10}`,
+		`{"type":"response.output_audio.delta","event_id":"3","delta":"` + audio + `"}`,
+		`{"type":"response.output_audio_transcript.delta","event_id":"4","delta":"Hi"}`,
+		`{"type":"response.done","event_id":"5","response":{"id":"resp_1","status":"completed","usage":{"total_tokens":30,"input_tokens":10,"output_tokens":20,"input_token_details":{"cached_tokens":4}}}}`,
+	}}
+	s := &LiveSession{ws: ws}
+	if err := s.Send(t.Context(), &genai.LiveInput{Audio: []byte{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(t.Context(), &genai.LiveInput{EndOfTurn: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(t.Context(), &genai.LiveInput{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"type":"input_audio_buffer.append","audio":"AQI="}`,
+		`{"type":"input_audio_buffer.commit"}`,
+		`{"type":"response.create"}`,
+		`{"type":"conversation.item.create","item":{"type":"message","role":"user","content":[{"type":"input_text","text":"hello"}]}}`,
+		`{"type":"response.create"}`,
+	}
+	if !slices.Equal(want, ws.sent) {
+		t.Fatalf("unexpected sent events:\n%q", ws.sent)
+	}
+	events, finish := s.Recv(t.Context())
+	got := slices.Collect(events)
+	if err := finish(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("unexpected events: %#v", got)
+	}
+	if !got[0].Interrupted {
+		t.Fatalf("expected interruption: %#v", got[0])
+	}
+	if got[1].Reply.Doc.Filename != "audio.pcm" {
+		t.Fatalf("unexpected audio: %#v", got[1])
+	}
+	if b, _ := io.ReadAll(got[1].Reply.Doc.Src); !slices.Equal(b, []byte{1, 2, 3, 4}) {
+		t.Fatalf("unexpected audio: %v", b)
+	}
+	if got[2].Reply.Text != "Hi" {
+		t.Fatalf("unexpected transcript: %#v", got[2])
+	}
+	wantUsage := genai.Usage{InputTokens: 10, InputCachedTokens: 4, OutputTokens: 20, TotalTokens: 30, FinishReason: genai.FinishedStop}
+	if !got[3].TurnComplete {
+		t.Fatalf("unexpected turn completion: %#v", got[3])
+	}
+	if diff := cmp.Diff(wantUsage, got[3].Usage); diff != "" {
+		t.Fatalf("unexpected usage (-want +got):\n%s", diff)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(t.Context(), &genai.LiveInput{Text: "hello"}); err == nil {
+		t.Fatal("expected error after Close")
+	}
+}
+
+func TestLiveSession_error(t *testing.T) {
+	ws := &fakeWS{recv: []string{
+		`{"type":"error","event_id":"1","error":{"type":"invalid_request_error","code":"invalid_value","message":"bad audio"}}`,
+	}}
+	s := &LiveSession{ws: ws}
+	events, finish := s.Recv(t.Context())
+	for range events {
+		t.Fatal("unexpected event")
+	}
+	if err := finish(); err == nil || err.Error() != "invalid_value: bad audio" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestInitRealtimeSession(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg, err := initRealtimeSession([]genai.GenOption{
+			&genai.GenOptionText{SystemPrompt: "Be nice."},
+			&GenOptionLive{Voice: "marin", DisableVAD: true},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Instructions != "Be nice." || cfg.Audio.Output.Voice != "marin" || string(cfg.Audio.Input.TurnDetection) != "null" {
+			t.Fatalf("unexpected config: %+v", cfg)
+		}
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := initRealtimeSession([]genai.GenOption{&genai.GenOptionText{Temperature: 0.5}})
+		if err == nil || err.Error() != "not supported: GenOptionText.Temperature" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+// fakeWS is an in-memory wsConn.
+type fakeWS struct {
+	sent []string
+	recv []string
+}
+
+func (f *fakeWS) Send(data string) error {
+	f.sent = append(f.sent, data)
+	return nil
+}
+
+func (f *fakeWS) Receive(msg *string) error {
+	if len(f.recv) == 0 {
+		return io.EOF
+	}
+	*msg = f.recv[0]
+	f.recv = f.recv[1:]
+	return nil
+}
+
+func (f *fakeWS) Close() error {
+	return nil
+}
@@ -166,6 +166,7 @@ type Client struct {
 	starterWrapper genai.ProviderOptionStarterWrapper
 	bin            string
 	model          string
+	lenient        bool
 	binOnce        sync.Once
 	binErr         error
 }
@@ -178,8 +179,9 @@ type Client struct {
 // Supported ProviderOptions:
 //   - genai.ProviderOptionModel — model ID (e.g. "opencode/big-pickle").
 //     Use genai.ModelCheap, genai.ModelGood, or genai.ModelSOTA for automatic selection.
+//   - genai.ProviderOptionStrict — reject unknown fields in the CLI's ACP messages.
 func New(opts ...genai.ProviderOption) (*Client, error) {
-	c := &Client{}
+	c := &Client{lenient: true}
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -199,6 +201,8 @@ func New(opts ...genai.ProviderOption) (*Client, error) {
 			default:
 				c.model = string(v)
 			}
+		case genai.ProviderOptionStrict:
+			c.lenient = !bool(v)
 		case genai.ProviderOptionStarterWrapper:
 			c.starterWrapper = v
 		default:
@@ -284,7 +288,7 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 	}()
 
 	sc := newScanner(stdout)
-	hs, err := handshake(stdin, sc, "", "", "", "")
+	hs, err := handshake(stdin, sc, "", "", "", "", c.lenient)
 	if err != nil {
 		return nil, err
 	}
@@ -331,7 +335,7 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 	}()
 
 	sc := newScanner(stdout)
-	hs, err := handshake(stdin, sc, c.model, co.effort, co.mode, resumeSessionID)
+	hs, err := handshake(stdin, sc, c.model, co.effort, co.mode, resumeSessionID, c.lenient)
 	if err != nil {
 		return genai.Result{}, err
 	}
@@ -341,7 +345,7 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 		return genai.Result{}, err
 	}
 
-	return readTurn(sc, stdin, hs.sessionID, promptID, func(string, string) bool { return true })
+	return readTurn(sc, stdin, hs.sessionID, promptID, c.lenient, func(string, string) bool { return true })
 }
 
 // GenStream implements genai.Provider.
@@ -377,7 +381,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		}()
 
 		sc := newScanner(stdout)
-		hs, hsErr := handshake(stdin, sc, c.model, co.effort, co.mode, resumeSessionID)
+		hs, hsErr := handshake(stdin, sc, c.model, co.effort, co.mode, resumeSessionID, c.lenient)
 		if hsErr != nil {
 			finalErr = hsErr
 			return
@@ -388,7 +392,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 			return
 		}
 
-		result, finalErr = readTurn(sc, stdin, hs.sessionID, promptID, func(text, reasoning string) bool {
+		result, finalErr = readTurn(sc, stdin, hs.sessionID, promptID, c.lenient, func(text, reasoning string) bool {
 			if text != "" && !yield(genai.Reply{Text: text}) {
 				return false
 			}
@@ -413,6 +417,7 @@ type handshakeResult struct {
 	nextID          int64
 	availableModels []ModelInfo
 	configOptions   []SessionConfigOption
+	lenient         bool
 }
 
 func (h *handshakeResult) setConfigOptions(opts []SessionConfigOption) {
@@ -483,7 +488,7 @@ func (h *handshakeResult) setSessionConfigOption(stdin io.Writer, sc *bufio.Scan
 		return fmt.Errorf("read session/set_config_option response: %w", err)
 	}
 	var result SetSessionConfigOptionResult
-	if err := internal.UnmarshalJSON(data, &result); err != nil {
+	if err := internal.UnmarshalJSON(data, &result, h.lenient); err != nil {
 		return fmt.Errorf("parse session/set_config_option response: %w", err)
 	}
 	if len(result.ConfigOptions) == 0 {
@@ -518,8 +523,8 @@ func (h *handshakeResult) setSessionModel(stdin io.Writer, sc *bufio.Scanner, mo
 }
 
 // handshake performs the ACP initialize → session/new sequence.
-func handshake(stdin io.Writer, sc *bufio.Scanner, mdl string, effort Effort, mode Mode, resumeSessionID string) (*handshakeResult, error) {
-	hs := &handshakeResult{}
+func handshake(stdin io.Writer, sc *bufio.Scanner, mdl string, effort Effort, mode Mode, resumeSessionID string, lenient bool) (*handshakeResult, error) {
+	hs := &handshakeResult{lenient: lenient}
 
 	// 1. Send initialize request.
 	hs.nextID++
@@ -544,7 +549,7 @@ func handshake(stdin io.Writer, sc *bufio.Scanner, mdl string, effort Effort, mo
 		return nil, fmt.Errorf("read initialize response: %w", err)
 	}
 	var initResult InitializeResult
-	if internal.UnmarshalJSON(initData, &initResult) == nil {
+	if internal.UnmarshalJSON(initData, &initResult, lenient) == nil {
 		hs.supportsImage = initResult.AgentCapabilities.PromptCapabilities.Image
 	}
 
@@ -582,7 +587,7 @@ func handshake(stdin io.Writer, sc *bufio.Scanner, mdl string, effort Effort, mo
 		return nil, fmt.Errorf("read session response: %w", err)
 	}
 	var snResult SessionNewResult
-	if err := internal.UnmarshalJSON(sessionData, &snResult); err != nil {
+	if err := internal.UnmarshalJSON(sessionData, &snResult, lenient); err != nil {
 		return nil, fmt.Errorf("parse session result: %w", err)
 	}
 	if snResult.SessionID != "" {
@@ -728,7 +733,7 @@ func readResponse(sc *bufio.Scanner) (json.RawMessage, error) {
 // readTurn reads session/update notifications until the session/prompt response
 // arrives. For each text or reasoning delta, onDelta is called; returning false
 // stops the read loop early (used by GenStream when the caller breaks).
-func readTurn(sc *bufio.Scanner, stdin io.Writer, sessionID string, promptID int64, onDelta func(text, reasoning string) bool) (genai.Result, error) {
+func readTurn(sc *bufio.Scanner, stdin io.Writer, sessionID string, promptID int64, lenient bool, onDelta func(text, reasoning string) bool) (genai.Result, error) {
 	var textBuf, thinkBuf strings.Builder
 	for sc.Scan() {
 		line := sc.Bytes()
@@ -741,14 +746,14 @@ func readTurn(sc *bufio.Scanner, stdin io.Writer, sessionID string, promptID int
 		if len(probe.ID) > 0 && probe.Method == "" {
 			var id int64
 			if json.Unmarshal(probe.ID, &id) == nil && id == promptID {
-				return buildPromptResult(line, textBuf.String(), thinkBuf.String(), sessionID)
+				return buildPromptResult(line, textBuf.String(), thinkBuf.String(), sessionID, lenient)
 			}
 			continue
 		}
 
 		// Request from agent (permission) → auto-approve.
 		if len(probe.ID) > 0 && probe.Method != "" {
-			if err := handleAgentRequest(stdin, line); err != nil {
+			if err := handleAgentRequest(stdin, line, lenient); err != nil {
 				return genai.Result{}, fmt.Errorf("handle agent request: %w", err)
 			}
 			continue
@@ -762,7 +767,7 @@ func readTurn(sc *bufio.Scanner, stdin io.Writer, sessionID string, promptID int
 		if json.Unmarshal(line, &msg) != nil {
 			continue
 		}
-		text, reasoning, err := parseSessionUpdateDelta(msg.Params)
+		text, reasoning, err := parseSessionUpdateDelta(msg.Params, lenient)
 		if err != nil {
 			return genai.Result{}, err
 		}
@@ -780,9 +785,9 @@ func readTurn(sc *bufio.Scanner, stdin io.Writer, sessionID string, promptID int
 
 // parseSessionUpdateDelta extracts text and reasoning deltas from a
 // session/update notification's params.
-func parseSessionUpdateDelta(params json.RawMessage) (text, reasoning string, err error) {
+func parseSessionUpdateDelta(params json.RawMessage, lenient bool) (text, reasoning string, err error) {
 	var sup SessionUpdateParams
-	if err := internal.UnmarshalJSON(params, &sup); err != nil {
+	if err := internal.UnmarshalJSON(params, &sup, lenient); err != nil {
 		return "", "", fmt.Errorf("unmarshal session/update params: %w", err)
 	}
 	var probe UpdateProbe
@@ -792,13 +797,13 @@ func parseSessionUpdateDelta(params json.RawMessage) (text, reasoning string, er
 	switch probe.SessionUpdate {
 	case UpdateAgentMessageChunk:
 		var u AgentMessageChunkUpdate
-		if err := internal.UnmarshalJSON(sup.Update, &u); err != nil {
+		if err := internal.UnmarshalJSON(sup.Update, &u, lenient); err != nil {
 			return "", "", fmt.Errorf("unmarshal agent_message_chunk: %w", err)
 		}
 		return u.Content.Text, "", nil
 	case UpdateAgentThoughtChunk:
 		var u AgentThoughtChunkUpdate
-		if err := internal.UnmarshalJSON(sup.Update, &u); err != nil {
+		if err := internal.UnmarshalJSON(sup.Update, &u, lenient); err != nil {
 			return "", "", fmt.Errorf("unmarshal agent_thought_chunk: %w", err)
 		}
 		return "", u.Content.Text, nil
@@ -809,9 +814,9 @@ func parseSessionUpdateDelta(params json.RawMessage) (text, reasoning string, er
 
 // handleAgentRequest responds to JSON-RPC requests from the agent (e.g.
 // permission requests) by auto-approving with the first "allow" option.
-func handleAgentRequest(stdin io.Writer, line []byte) error {
+func handleAgentRequest(stdin io.Writer, line []byte, lenient bool) error {
 	var msg JSONRPCMessage
-	if err := internal.UnmarshalJSON(line, &msg); err != nil {
+	if err := internal.UnmarshalJSON(line, &msg, lenient); err != nil {
 		return fmt.Errorf("unmarshal agent request: %w", err)
 	}
 	var id int64
@@ -826,7 +831,7 @@ func handleAgentRequest(stdin io.Writer, line []byte) error {
 		return msgutil.WriteNDJSON(stdin, JSONRPCResponse{JSONRPC: "2.0", ID: id, Result: result})
 	}
 	var params PermissionRequestParams
-	if err := internal.UnmarshalJSON(msg.Params, &params); err != nil {
+	if err := internal.UnmarshalJSON(msg.Params, &params, lenient); err != nil {
 		return fmt.Errorf("unmarshal permission request: %w", err)
 	}
 	// Find the first allow option.
@@ -853,17 +858,17 @@ func handleAgentRequest(stdin io.Writer, line []byte) error {
 
 // buildPromptResult constructs a genai.Result from the session/prompt response.
 // Returns an error if the JSON-RPC response is an error.
-func buildPromptResult(line []byte, text, thinking, sessionID string) (genai.Result, error) {
+func buildPromptResult(line []byte, text, thinking, sessionID string, lenient bool) (genai.Result, error) {
 	r := genai.Result{}
 
 	var msg JSONRPCMessage
-	if internal.UnmarshalJSON(line, &msg) == nil {
+	if internal.UnmarshalJSON(line, &msg, lenient) == nil {
 		if msg.Error != nil {
 			return r, fmt.Errorf("JSON-RPC error %d: %s", msg.Error.Code, msg.Error.Message)
 		}
 		if msg.Result != nil {
 			var pr PromptResult
-			if internal.UnmarshalJSON(msg.Result, &pr) == nil {
+			if internal.UnmarshalJSON(msg.Result, &pr, lenient) == nil {
 				r.Usage.FinishReason = stopReasonToFinishReason(pr.StopReason)
 				r.Usage.InputTokens = int64(pr.Usage.InputTokens)
 				r.Usage.OutputTokens = int64(pr.Usage.OutputTokens)
@@ -19,7 +19,6 @@ import (
 	"testing"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/internal/msgutil"
 	"github.com/maruel/genai/internal/myrecorder"
@@ -29,7 +28,7 @@ import (
 
 func newTestClient(t *testing.T, name string, opts ...genai.ProviderOption) *Client {
 	rec := internaltest.NewSubprocessRecorder(t, name, "opencode")
-	opts = append(opts, genai.ProviderOptionStarterWrapper(rec.Wrap))
+	opts = append(opts, genai.ProviderOptionStrict(true), genai.ProviderOptionStarterWrapper(rec.Wrap))
 	c, err := New(opts...)
 	if err != nil {
 		t.Fatalf("New: %v", err)
@@ -38,7 +37,7 @@ func newTestClient(t *testing.T, name string, opts ...genai.ProviderOption) *Cli
 }
 
 func newOutputClient(t *testing.T, output string, opts ...genai.ProviderOption) *Client {
-	opts = append(opts, genai.ProviderOptionStarterWrapper(func(genai.Starter) genai.Starter {
+	opts = append(opts, genai.ProviderOptionStrict(true), genai.ProviderOptionStarterWrapper(func(genai.Starter) genai.Starter {
 		return func(_ context.Context, _ []string) (io.WriteCloser, io.ReadCloser, func() error, error) {
 			pr, pw := io.Pipe()
 			go func() { _, _ = io.Copy(io.Discard, pr) }()
@@ -97,7 +96,7 @@ func TestClient(t *testing.T) {
 				if rec, ok := wrapped.(*myrecorder.Recorder); ok {
 					name := strings.TrimSuffix(rec.Name(), ".yaml")
 					r := internaltest.NewSubprocessRecorder(t, name, "opencode")
-					opts = append(opts, genai.ProviderOptionStarterWrapper(r.Wrap))
+					opts = append(opts, genai.ProviderOptionStrict(true), genai.ProviderOptionStarterWrapper(r.Wrap))
 				}
 			}
 			c, err := New(opts...)
@@ -158,7 +157,7 @@ func TestClient(t *testing.T) {
 					sb.WriteString(`{"jsonrpc":"2.0","id":3,"result":{"configOptions":[{"id":"model","name":"Model","category":"model","type":"select","currentValue":"openai/gpt-5.4","options":[{"value":"openai/gpt-5.4","name":"GPT-5.4"}]},{"id":"effort","name":"Effort","category":"thought_level","type":"select","currentValue":"low","options":[{"value":"low","name":"Low"},{"value":"high","name":"High"},{"value":"xhigh","name":"Extra high"}]},{"id":"mode","name":"Mode","category":"mode","type":"select","currentValue":"build","options":[{"value":"build","name":"Build"},{"value":"plan","name":"Plan"}]}]}}`)
 				}
 				responses := sb.String()
-				if _, err := handshake(&written, newScanner(strings.NewReader(responses)), tc.model, tc.effort, tc.mode, ""); err != nil {
+				if _, err := handshake(&written, newScanner(strings.NewReader(responses)), tc.model, tc.effort, tc.mode, "", false); err != nil {
 					t.Fatalf("handshake: %v", err)
 				}
 
@@ -186,7 +185,7 @@ func TestClient(t *testing.T) {
 	t.Run("unsupported_effort", func(t *testing.T) {
 		responses := `{"jsonrpc":"2.0","id":1,"result":{}}` + "\n" +
 			`{"jsonrpc":"2.0","id":2,"result":{"sessionId":"session-1","configOptions":[{"id":"model","name":"Model","category":"model","type":"select","currentValue":"openai/gpt-5.4","options":[{"value":"openai/gpt-5.4","name":"GPT-5.4"}]}]}}`
-		_, err := handshake(&bytes.Buffer{}, newScanner(strings.NewReader(responses)), "", "custom", "", "")
+		_, err := handshake(&bytes.Buffer{}, newScanner(strings.NewReader(responses)), "", "custom", "", "", false)
 		if err == nil {
 			t.Fatal("expected unsupported effort error")
 		}
@@ -484,7 +483,3 @@ func TestScoreboard(t *testing.T) {
 		t.Fatal("scoreboard has no scenarios")
 	}
 }
-
-func init() {
-	internal.BeLenient = false
-}
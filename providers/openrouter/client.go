@@ -16,9 +16,11 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"net/http"
+	"net/url"
 	"os"
 	"slices"
 
@@ -57,6 +59,11 @@ type GenOption struct {
 
 // Validate implements genai.Validatable.
 func (o *GenOption) Validate() error {
+	if o.Provider != nil {
+		if err := o.Provider.Validate(); err != nil {
+			return fmt.Errorf("field Provider: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -68,6 +75,36 @@ const (
 	RouteFallback Route = "fallback"
 )
 
+// ProviderOptionAppURL is the URL of the application sent as the HTTP-Referer header, to attribute the
+// requests to it in the OpenRouter rankings and in the activity logs.
+//
+// See https://openrouter.ai/docs/app-attribution
+type ProviderOptionAppURL string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionAppURL) Validate() error {
+	u, err := url.Parse(string(p))
+	if err != nil {
+		return fmt.Errorf("invalid ProviderOptionAppURL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid ProviderOptionAppURL %q: must be an absolute http(s) URL", string(p))
+	}
+	return nil
+}
+
+// ProviderOptionAppTitle is the name of the application sent as the X-Title header, displayed along
+// ProviderOptionAppURL in the OpenRouter rankings.
+type ProviderOptionAppTitle string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionAppTitle) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionAppTitle cannot be empty")
+	}
+	return nil
+}
+
 // Client implements genai.Provider for OpenRouter.
 type Client struct {
 	base.NotImplemented
@@ -82,11 +119,22 @@ type Client struct {
 //
 // OpenRouter model IDs use the format "provider/model", e.g. "anthropic/claude-3.5-sonnet".
 // See https://openrouter.ai/models for available models.
+//
+// Use ProviderOptionAppURL and ProviderOptionAppTitle to attribute the requests to your application. Use
+// GenOption.Provider to control the routing to the upstream providers, including price caps. The upstream
+// provider and the cost of each request are reported in genai.Usage.
+//
+// The proxy defaults to the OPENROUTER_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	h := http.Header{}
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -105,6 +153,18 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			preloadedModels = []genai.Model(v)
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case ProviderOptionAppURL:
+			h.Set("HTTP-Referer", string(v))
+		case ProviderOptionAppTitle:
+			h.Set("X-Title", string(v))
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -116,11 +176,12 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			err = &base.ErrAPIKeyRequired{EnvVar: "OPENROUTER_API_KEY", URL: apiKeyURL}
 		}
 	}
+	h.Set("Authorization", "Bearer "+apiKey)
 	mod := genai.Modalities{genai.ModalityText}
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "OPENROUTER_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -131,10 +192,11 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			PreloadedModels: preloadedModels,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
-						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
+						Header:    h,
 						Transport: &roundtrippers.RequestID{Transport: t},
 					},
 				},
@@ -244,11 +306,20 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.OutputTokens = pkt.Usage.CompletionTokens
 					u.TotalTokens = pkt.Usage.TotalTokens
 				}
+				if pkt.Usage.Cost != 0 {
+					u.Cost = pkt.Usage.Cost
+				}
+				if pkt.Provider != "" {
+					u.InferenceProvider = pkt.Provider
+				}
 				if pkt.ServiceTier != "" {
 					u.ServiceTier = pkt.ServiceTier
 				}
 				if pkt.Choices[0].FinishReason != "" {
-					u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				if pkt.Choices[0].Logprobs != nil {
 					logprobs = append(logprobs, pkt.Choices[0].Logprobs.To()...)
@@ -8,6 +8,7 @@ package openrouter_test
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"os"
 	"slices"
@@ -15,7 +16,6 @@ import (
 	"testing"
 
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/openrouter"
 	"github.com/maruel/genai/scoreboard"
@@ -29,6 +29,7 @@ func getClientInner(t *testing.T, opts []genai.ProviderOption, fn func(http.Roun
 	if fn != nil {
 		opts = append(opts, genai.ProviderOptionTransportWrapper(fn))
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return openrouter.New(t.Context(), opts...)
 }
 
@@ -54,6 +55,70 @@ func TestChatRequest(t *testing.T) {
 	})
 }
 
+func TestGenOption(t *testing.T) {
+	t.Run("max price", func(t *testing.T) {
+		var req openrouter.ChatRequest
+		opt := &openrouter.GenOption{Provider: &openrouter.ProviderPreferences{Sort: "price", MaxPrice: openrouter.MaxPrice{Prompt: 1, Completion: 2}}}
+		if err := req.Init(genai.Messages{genai.NewTextMessage("Hi")}, "qwen/qwen3.5-35b-a3b", opt); err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(req.Provider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"sort":"price","max_price":{"prompt":1,"completion":2}}`; string(got) != want {
+			t.Fatalf("Provider mismatch:\n got: %s\nwant: %s", got, want)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			p    openrouter.ProviderPreferences
+			want string
+		}{
+			{openrouter.ProviderPreferences{Sort: "random"}, `field Provider: field Sort: invalid value "random"`},
+			{openrouter.ProviderPreferences{DataCollection: "maybe"}, `field Provider: field DataCollection: invalid value "maybe"`},
+			{openrouter.ProviderPreferences{MaxPrice: openrouter.MaxPrice{Prompt: -1}}, "field Provider: field MaxPrice: prices must be non-negative"},
+		}
+		for _, l := range data {
+			o := openrouter.GenOption{Provider: &l.p}
+			if err := o.Validate(); err == nil || err.Error() != l.want {
+				t.Errorf("want %q, got %v", l.want, err)
+			}
+		}
+	})
+}
+
+func TestProviderOptionApp(t *testing.T) {
+	if err := openrouter.ProviderOptionAppURL("example.com").Validate(); err == nil {
+		t.Fatal("expected error")
+	}
+	var got http.Header
+	fake := internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		got = r.Header
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"data":[]}`)),
+			Request:    r,
+		}, nil
+	})
+	c, err := openrouter.New(t.Context(),
+		genai.ProviderOptionAPIKey("key"),
+		openrouter.ProviderOptionAppURL("https://example.com"),
+		openrouter.ProviderOptionAppTitle("Example"),
+		genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.ListModels(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("HTTP-Referer") != "https://example.com" || got.Get("X-Title") != "Example" || got.Get("Authorization") != "Bearer key" {
+		t.Fatalf("unexpected headers: %v", got)
+	}
+}
+
 func TestChatResponse(t *testing.T) {
 	t.Run("service tier and string content", func(t *testing.T) {
 		body := `{"id":"gen-test","object":"chat.completion","created":1781467681,"model":"qwen/qwen3.5-35b-a3b-20260224","provider":"Ambient","service_tier":"auto","choices":[{"index":0,"finish_reason":"stop","native_finish_reason":"stop","message":{"role":"assistant","content":"Hello"}}],"usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3}}`
@@ -73,6 +138,25 @@ func TestChatResponse(t *testing.T) {
 		if got.Usage.ServiceTier != "auto" {
 			t.Fatalf("ServiceTier = %q, want auto", got.Usage.ServiceTier)
 		}
+		if got.Usage.InferenceProvider != "Ambient" {
+			t.Fatalf("InferenceProvider = %q, want Ambient", got.Usage.InferenceProvider)
+		}
+	})
+	t.Run("cost", func(t *testing.T) {
+		body := `{"id":"gen-test","object":"chat.completion","created":1781467681,"model":"qwen/qwen3.5-35b-a3b-20260224","provider":"DeepInfra","choices":[{"index":0,"finish_reason":"stop","native_finish_reason":"stop","message":{"role":"assistant","content":"Hello"}}],"usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3,"cost":0.00042,"is_byok":false}}`
+		var resp openrouter.ChatResponse
+		dec := json.NewDecoder(strings.NewReader(body))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		got, err := resp.ToResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Usage.Cost != 0.00042 {
+			t.Fatalf("Cost = %g, want 0.00042", got.Usage.Cost)
+		}
 	})
 }
 
@@ -157,6 +241,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append(opts, genai.ProviderOptionTransportWrapper(fn))
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := openrouter.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -215,7 +300,3 @@ func TestClient(t *testing.T) {
 		internaltest.TestClientProviderErrors(t, f, data)
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
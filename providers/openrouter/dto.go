@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
@@ -45,11 +46,48 @@ type ProviderPreferences struct {
 	ZDR bool `json:"zdr,omitzero"`
 	// EnforceDistillableText requires text output compatible with distillation.
 	EnforceDistillableText bool `json:"enforce_distillable_text,omitzero"`
+	// MaxPrice skips the providers charging more than these prices.
+	MaxPrice MaxPrice `json:"max_price,omitzero"`
+}
+
+// Validate implements genai.Validatable.
+func (p *ProviderPreferences) Validate() error {
+	switch p.Sort {
+	case "", "price", "throughput", "latency":
+	default:
+		return fmt.Errorf("field Sort: invalid value %q", p.Sort)
+	}
+	switch p.DataCollection {
+	case "", "allow", "deny":
+	default:
+		return fmt.Errorf("field DataCollection: invalid value %q", p.DataCollection)
+	}
+	if err := p.MaxPrice.Validate(); err != nil {
+		return fmt.Errorf("field MaxPrice: %w", err)
+	}
+	return nil
+}
+
+// MaxPrice caps the price of the providers a request can be routed to, in USD per million tokens for Prompt
+// and Completion, and in USD per unit for Request and Image. Zero means no cap.
+type MaxPrice struct {
+	Prompt     float64 `json:"prompt,omitzero"`
+	Completion float64 `json:"completion,omitzero"`
+	Request    float64 `json:"request,omitzero"`
+	Image      float64 `json:"image,omitzero"`
+}
+
+// Validate implements genai.Validatable.
+func (m *MaxPrice) Validate() error {
+	if m.Prompt < 0 || m.Completion < 0 || m.Request < 0 || m.Image < 0 {
+		return errors.New("prices must be non-negative")
+	}
+	return nil
 }
 
 // Reasoning configures reasoning/thinking behavior for supported models.
 type Reasoning struct {
-	// Effort controls how much effort the model spends on reasoning ("low", "medium", "high").
+	// Effort controls how much effort the model spends on reasoning ("none", "low", "medium", "high").
 	Effort string `json:"effort,omitzero"`
 	// Summary controls whether to include a summary of reasoning ("auto", "concise", "detailed").
 	Summary string `json:"summary,omitzero"`
@@ -113,6 +151,9 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			}
 			sp = v.SystemPrompt
 		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
 			if err := c.initOptionsTools(v); err != nil {
 				errs = append(errs, err)
 			}
@@ -169,10 +210,23 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) ([]string, error)
 	if v.TopK != 0 {
 		unsupported = append(unsupported, "GenOptionText.TopK")
 	}
+	if v.N > 1 {
+		unsupported = append(unsupported, "GenOptionText.N")
+	}
+	if v.Grammar != "" {
+		unsupported = append(unsupported, "GenOptionText.Grammar")
+	}
 	if v.TopLogprobs != 0 {
 		c.Logprobs = true
 		c.TopLogprobs = v.TopLogprobs
 	}
+	switch v.ReasoningEffort {
+	case "":
+	case genai.ReasoningEffortOff:
+		c.Reasoning = &Reasoning{Effort: "none"}
+	default:
+		c.Reasoning = &Reasoning{Effort: string(v.ReasoningEffort)}
+	}
 	c.Stop = v.Stop
 	if v.DecodeAs != nil {
 		c.ResponseFormat.Type = "json_schema"
@@ -190,7 +244,8 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) ([]string, error)
 }
 
 func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) error {
-	if len(v.Tools) != 0 {
+	tools := v.AllowedTools()
+	if len(tools) != 0 {
 		switch v.Force {
 		case genai.ToolCallAny:
 			c.ToolChoice = "auto"
@@ -199,8 +254,8 @@ func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) error {
 		case genai.ToolCallNone:
 			c.ToolChoice = "none"
 		}
-		c.Tools = make([]Tool, len(v.Tools))
-		for i, t := range v.Tools {
+		c.Tools = make([]Tool, len(tools))
+		for i, t := range tools {
 			c.Tools[i].Type = "function"
 			c.Tools[i].Function.Name = t.Name
 			c.Tools[i].Function.Description = t.Description
@@ -629,6 +684,8 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 			OutputTokens:      c.Usage.CompletionTokens,
 			TotalTokens:       c.Usage.TotalTokens,
 			ServiceTier:       c.ServiceTier,
+			InferenceProvider: c.Provider,
+			Cost:              c.Usage.Cost,
 		},
 	}
 	if c.Error != nil {
@@ -637,11 +694,12 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	if len(c.Choices) != 1 {
 		return out, &internal.BadError{Err: fmt.Errorf("server returned an unexpected number of choices, expected 1, got %d", len(c.Choices))}
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
 	if c.Choices[0].Logprobs != nil {
 		out.Logprobs = c.Choices[0].Logprobs.To()
 	}
-	err := c.Choices[0].Message.To(&out.Message)
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
 	return out, err
 }
 
@@ -658,25 +716,24 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case "":
-		return ""
+		return "", nil
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishContentFilter:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	case FinishError:
-		return genai.FinishReason("error")
+		return genai.FinishReason("error"), nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -799,6 +856,37 @@ func (m *Model) Context() int64 {
 	return m.ContextLength
 }
 
+// MaxOutputTokens implements genai.ModelOutputTokens.
+func (m *Model) MaxOutputTokens() int64 {
+	return m.TopProvider.MaxCompletionTokens
+}
+
+// GetModalities implements genai.ModelModalities.
+func (m *Model) GetModalities() (in, out genai.Modalities) {
+	return base.ParseModalities(m.Architecture.InputModalities), base.ParseModalities(m.Architecture.OutputModalities)
+}
+
+// GetCreated implements genai.ModelCreated.
+func (m *Model) GetCreated() time.Time {
+	if m.Created.IsZero() {
+		return time.Time{}
+	}
+	return m.Created.AsTime()
+}
+
+// GetDeprecated implements genai.ModelDeprecated.
+func (m *Model) GetDeprecated() bool {
+	return m.ExpirationDate != ""
+}
+
+// GetPricing implements genai.ModelPricing.
+func (m *Model) GetPricing() genai.ModelPrice {
+	// OpenRouter returns the price per token.
+	in, _ := strconv.ParseFloat(m.Pricing.Prompt, 64)
+	out, _ := strconv.ParseFloat(m.Pricing.Completion, 64)
+	return genai.ModelPrice{Input: in * 1e6, Output: out * 1e6}
+}
+
 // ModelBenchmarks contains OpenRouter benchmark metadata for a model.
 type ModelBenchmarks struct {
 	ArtificialAnalysis ModelArtificialAnalysisBenchmark `json:"artificial_analysis,omitzero"`
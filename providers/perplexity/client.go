@@ -12,11 +12,13 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"net/http"
 	"os"
 	"slices"
+	"strings"
 
 	"github.com/maruel/roundtrippers"
 
@@ -43,11 +45,35 @@ func Scoreboard() scoreboard.Score {
 // GenOption defines Perplexity specific options.
 type GenOption struct {
 	// DisableRelatedQuestions disabled related questions, to save on tokens and latency.
+	//
+	// Related questions are returned as a Reply with Opaque key "related_questions" holding a []string.
 	DisableRelatedQuestions bool
+	// DisableImages disables returning images found during the search. Images are returned as
+	// genai.CitationWebImage citations.
+	DisableImages bool
+	// SearchDomainFilter limits the search to these domains, e.g. "wikipedia.org". Prefix a domain with "-"
+	// to exclude it instead. At most 10 domains can be specified.
+	SearchDomainFilter []string
+	// SearchRecencyFilter limits the search to pages published recently. One of "hour", "day", "week" or
+	// "month".
+	SearchRecencyFilter string
 }
 
 // Validate implements genai.Validatable.
 func (o *GenOption) Validate() error {
+	if len(o.SearchDomainFilter) > 10 {
+		return fmt.Errorf("field SearchDomainFilter: at most 10 domains, got %d", len(o.SearchDomainFilter))
+	}
+	for i, d := range o.SearchDomainFilter {
+		if strings.TrimPrefix(d, "-") == "" {
+			return fmt.Errorf("field SearchDomainFilter: domain %d is empty", i)
+		}
+	}
+	switch o.SearchRecencyFilter {
+	case "", "hour", "day", "week", "month":
+	default:
+		return fmt.Errorf("field SearchRecencyFilter: invalid value %q", o.SearchRecencyFilter)
+	}
 	return nil
 }
 
@@ -65,11 +91,17 @@ type Client struct {
 //
 // To use multiple models, create multiple clients.
 // Models are listed at https://docs.perplexity.ai/guides/model-cards
+//
+// The proxy defaults to the PERPLEXITY_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -88,6 +120,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			preloadedModels = []genai.Model(v)
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -103,7 +143,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
 		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "PERPLEXITY_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -114,7 +154,8 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			PreloadedModels: preloadedModels,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
@@ -215,6 +256,8 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 	// Perplexity has a bug where it will send the search result multiple times. We need to filter them. Use the
 	// URL as key.
 	seen := map[string]struct{}{}
+	searched := false
+	related := false
 
 	return func(yield func(genai.Reply) bool) {
 			for pkt := range chunks {
@@ -226,7 +269,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					u.OutputTokens = pkt.Usage.CompletionTokens
 				}
 				if pkt.Choices[0].FinishReason != "" {
-					u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				switch role := pkt.Choices[0].Delta.Role; role {
 				case "", "assistant":
@@ -250,6 +296,12 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 						})
 					}
 					if len(f.Citation.Sources) > 0 {
+						if !searched {
+							searched = true
+							if !yield(genai.Reply{ServerToolCall: webSearchCall}) {
+								return
+							}
+						}
 						if !yield(f) {
 							return
 						}
@@ -263,7 +315,7 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 						}
 						seen[img.ImageURL] = struct{}{}
 						f.Citation.Sources = append(f.Citation.Sources, genai.CitationSource{
-							Type:  genai.CitationDocument,
+							Type:  genai.CitationWebImage,
 							Title: img.OriginURL,
 							URL:   img.ImageURL,
 							Metadata: map[string]any{
@@ -278,7 +330,13 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 						}
 					}
 				}
-				// TODO: Return pkt.RelatedQuestions as part of the result when genai supports related questions.
+				if len(pkt.RelatedQuestions) > 0 && !related {
+					// They are repeated in the following packets.
+					related = true
+					if !yield(genai.Reply{Opaque: map[string]any{opaqueRelatedQuestions: pkt.RelatedQuestions}}) {
+						return
+					}
+				}
 				if !yield(genai.Reply{Text: pkt.Choices[0].Delta.Content}) {
 					return
 				}
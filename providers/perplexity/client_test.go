@@ -8,6 +8,7 @@ package perplexity_test
 
 import (
 	"context"
+	"encoding/json"
 	"iter"
 	"net/http"
 	"os"
@@ -19,7 +20,6 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/adapters"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/perplexity"
 	"github.com/maruel/genai/scoreboard"
@@ -36,6 +36,7 @@ func getClientInner(t *testing.T, apiKey string, opts []genai.ProviderOption, fn
 	if fn != nil {
 		opts = append(opts, genai.ProviderOptionTransportWrapper(fn))
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return perplexity.New(t.Context(), opts...)
 }
 
@@ -80,7 +81,7 @@ func TestClient(t *testing.T) {
 			}
 		}
 		getClientRT := func(t testing.TB, model scoreboard.Model, fn func(http.RoundTripper) http.RoundTripper) genai.Provider {
-			var o []genai.ProviderOption
+			o := []genai.ProviderOption{genai.ProviderOptionStrict(true)}
 			if model.Model != "" {
 				o = append(o, genai.ProviderOptionModel(model.Model))
 			}
@@ -182,6 +183,43 @@ func TestClient(t *testing.T) {
 	})
 }
 
+func TestGenOption(t *testing.T) {
+	var in perplexity.ChatRequest
+	opt := perplexity.GenOption{DisableImages: true, SearchDomainFilter: []string{"wikipedia.org", "-reddit.com"}, SearchRecencyFilter: "week"}
+	if err := in.Init(genai.Messages{genai.NewTextMessage("Hi")}, "sonar", &opt); err != nil {
+		t.Fatal(err)
+	}
+	if in.ReturnImages || !in.ReturnRelatedQuestions || len(in.SearchDomainFilter) != 2 || in.SearchRecencyFilter != "week" {
+		t.Errorf("unexpected %#v", in)
+	}
+	var resp perplexity.ChatResponse
+	if err := json.Unmarshal([]byte(`{"choices":[{"finish_reason":"stop","message":{"role":"assistant","content":"Hello"}}],"related_questions":["Why?"]}`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	res, err := resp.ToResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Replies) != 2 || !slices.Equal(res.Replies[1].Opaque["related_questions"].([]string), []string{"Why?"}) {
+		t.Fatalf("unexpected %#v", res.Replies)
+	}
+	// Related questions are not sent back.
+	if err := in.Init(genai.Messages{genai.NewTextMessage("Hi"), res.Message, genai.NewTextMessage("Thanks")}, "sonar"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		in   perplexity.GenOption
+		want string
+	}{
+		{perplexity.GenOption{SearchRecencyFilter: "year"}, `field SearchRecencyFilter: invalid value "year"`},
+		{perplexity.GenOption{SearchDomainFilter: []string{"-"}}, "field SearchDomainFilter: domain 0 is empty"},
+	} {
+		if err := tt.in.Validate(); err == nil || err.Error() != tt.want {
+			t.Errorf("want %q, got %v", tt.want, err)
+		}
+	}
+}
+
 // injectOptions generally inject the option unless "Quackiland" is in the last message.
 type injectOptions struct {
 	genai.Provider
@@ -211,7 +249,3 @@ func (i *injectOptions) GenStream(ctx context.Context, msgs genai.Messages, opts
 	}
 	return i.Provider.GenStream(ctx, msgs, opts...)
 }
-
-func init() {
-	internal.BeLenient = false
-}
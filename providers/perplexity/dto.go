@@ -14,6 +14,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/maruel/genai"
@@ -90,6 +91,9 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			unsupported, errs = c.initOptionsText(v)
 			sp = v.SystemPrompt
 		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
 			if len(v.Tools) != 0 {
 				errs = append(errs, errors.New("unsupported options GenOptionTools.Tools"))
 			}
@@ -98,11 +102,24 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			}
 		case *genai.GenOptionWeb:
 			c.DisableSearch = !v.Search
+			if v.SearchOptions != nil {
+				u, err := c.initWebSearch(v.SearchOptions)
+				unsupported = append(unsupported, u...)
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
 			if v.Fetch {
 				errs = append(errs, errors.New("unsupported GenOptionWeb.Fetch"))
 			}
 		case *GenOption:
 			c.ReturnRelatedQuestions = !v.DisableRelatedQuestions
+			c.ReturnImages = !v.DisableImages
+			// They take precedence over GenOptionWeb.SearchOptions.
+			if len(v.SearchDomainFilter) != 0 {
+				c.SearchDomainFilter = v.SearchDomainFilter
+			}
+			c.SearchRecencyFilter = v.SearchRecencyFilter
 		default:
 			unsupported = append(unsupported, internal.TypeName(opt))
 		}
@@ -128,6 +145,34 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 	return errors.Join(errs...)
 }
 
+// initWebSearch applies the generic web search options. It returns the unsupported options.
+func (c *ChatRequest) initWebSearch(o *genai.WebSearchOptions) ([]string, error) {
+	var unsupported []string
+	if len(c.SearchDomainFilter) == 0 {
+		c.SearchDomainFilter = slices.Clone(o.AllowedDomains)
+		for _, d := range o.BlockedDomains {
+			c.SearchDomainFilter = append(c.SearchDomainFilter, "-"+d)
+		}
+		if len(c.SearchDomainFilter) > 10 {
+			return nil, fmt.Errorf("at most 10 domains are supported, got %d", len(c.SearchDomainFilter))
+		}
+	}
+	if o.MaxSearches != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.MaxSearches")
+	}
+	c.WebSearchOptions.SearchContextSize = o.ContextSize
+	l := &o.UserLocation
+	c.WebSearchOptions.UserLocation.CountryCode = l.Country
+	if l.HasLatLng() {
+		c.WebSearchOptions.UserLocation.Latitude = l.Latitude
+		c.WebSearchOptions.UserLocation.Longitude = l.Longitude
+	}
+	if l.City != "" || l.Region != "" || l.Timezone != "" {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.UserLocation.City")
+	}
+	return unsupported, nil
+}
+
 // SetStream sets the streaming mode.
 func (c *ChatRequest) SetStream(stream bool) {
 	c.Stream = stream
@@ -140,6 +185,19 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) ([]string, []error
 	c.Temperature = v.Temperature
 	c.TopP = v.TopP
 	c.TopK = v.TopK
+	if v.N > 1 {
+		unsupported = append(unsupported, "GenOptionText.N")
+	}
+	if v.Grammar != "" {
+		unsupported = append(unsupported, "GenOptionText.Grammar")
+	}
+	switch v.ReasoningEffort {
+	case "":
+	case genai.ReasoningEffortOff:
+		unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+	default:
+		c.ReasoningEffort = string(v.ReasoningEffort)
+	}
 	if v.TopLogprobs > 0 {
 		unsupported = append(unsupported, "GenOptionText.TopLogprobs")
 	}
@@ -211,6 +269,10 @@ func (m *Message) From(in *genai.Message) error {
 		}
 	}
 	for i := range in.Replies {
+		if _, ok := in.Replies[i].Opaque[opaqueRelatedQuestions]; ok {
+			// Related questions are only informative.
+			continue
+		}
 		if len(in.Replies[i].Opaque) != 0 {
 			return &internal.BadError{Err: fmt.Errorf("reply #%d: field Reply.Opaque not supported", i)}
 		}
@@ -241,7 +303,7 @@ func (m *Message) From(in *genai.Message) error {
 			default:
 				return &internal.BadError{Err: fmt.Errorf("reply #%d: perplexity only supports text documents, got %s", i, mimeType)}
 			}
-		case in.Replies[i].Reasoning != "":
+		case in.Replies[i].Reasoning != "", !in.Replies[i].ServerToolCall.IsZero():
 			// Ignore
 		default:
 			return &internal.BadError{Err: errors.New("unknown Reply type")}
@@ -250,6 +312,13 @@ func (m *Message) From(in *genai.Message) error {
 	return nil
 }
 
+// opaqueRelatedQuestions is the Reply.Opaque key holding the related questions suggested by Perplexity.
+const opaqueRelatedQuestions = "related_questions"
+
+// webSearchCall is reported when the search results are returned. Perplexity doesn't expose the search
+// itself.
+var webSearchCall = genai.ServerToolCall{Name: genai.ServerToolWebSearch, Status: genai.ServerToolCallCompleted}
+
 // To converts the message to a genai.Message.
 func (m *Message) To(search []SearchResult, images []Images, related []string, out *genai.Message) error {
 	for i := range m.Content {
@@ -260,6 +329,7 @@ func (m *Message) To(search []SearchResult, images []Images, related []string, o
 		}
 	}
 	if len(search) > 0 {
+		out.Replies = append(out.Replies, genai.Reply{ServerToolCall: webSearchCall})
 		ct := genai.Citation{Sources: make([]genai.CitationSource, len(search))}
 		for i := range search {
 			ct.Sources[i].Type = genai.CitationWeb
@@ -272,7 +342,7 @@ func (m *Message) To(search []SearchResult, images []Images, related []string, o
 	if len(images) > 0 {
 		ct := genai.Citation{Sources: make([]genai.CitationSource, len(images))}
 		for i := range images {
-			ct.Sources[i].Type = genai.CitationDocument
+			ct.Sources[i].Type = genai.CitationWebImage
 			ct.Sources[i].Title = images[i].OriginURL
 			ct.Sources[i].URL = images[i].ImageURL
 			ct.Sources[i].Metadata = map[string]any{
@@ -282,9 +352,9 @@ func (m *Message) To(search []SearchResult, images []Images, related []string, o
 		}
 		out.Replies = append(out.Replies, genai.Reply{Citation: ct})
 	}
-	// if len(related) > 0 {
-	// TODO: Figure out how to return this.
-	// }
+	if len(related) > 0 {
+		out.Replies = append(out.Replies, genai.Reply{Opaque: map[string]any{opaqueRelatedQuestions: related}})
+	}
 	return nil
 }
 
@@ -360,8 +430,9 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	if len(c.Choices) != 1 {
 		return out, errors.New("expected 1 choice")
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-	err := c.Choices[0].Message.To(c.SearchResults, c.Images, c.RelatedQuestions, &out.Message)
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+	err = errors.Join(err, c.Choices[0].Message.To(c.SearchResults, c.Images, c.RelatedQuestions, &out.Message))
 	return out, err
 }
 
@@ -394,17 +465,16 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
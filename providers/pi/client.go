@@ -104,6 +104,7 @@ type Client struct {
 	starterWrapper genai.ProviderOptionStarterWrapper
 	bin            string
 	model          string
+	lenient        bool
 
 	binOnce sync.Once
 	binErr  error
@@ -117,8 +118,9 @@ type Client struct {
 // Supported ProviderOptions:
 //   - genai.ProviderOptionModel — model ID (e.g. "claude-sonnet-4-20250514").
 //     Use genai.ModelCheap, genai.ModelGood, or genai.ModelSOTA for automatic selection.
+//   - genai.ProviderOptionStrict — reject unknown fields in the CLI's JSONL messages.
 func New(opts ...genai.ProviderOption) (*Client, error) {
-	c := &Client{}
+	c := &Client{lenient: true}
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -132,6 +134,8 @@ func New(opts ...genai.ProviderOption) (*Client, error) {
 			// For now, store the raw string; we'll resolve shortcuts when we
 			// have the model list.
 			c.model = string(v)
+		case genai.ProviderOptionStrict:
+			c.lenient = !bool(v)
 		case genai.ProviderOptionStarterWrapper:
 			c.starterWrapper = v
 		default:
@@ -218,12 +222,12 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 	if err := msgutil.WriteNDJSON(stdin, GetModelsCmd{Type: CmdGetModels}); err != nil {
 		return nil, fmt.Errorf("write get_available_models: %w", err)
 	}
-	resp, err := readResponseForCommand(sc, CmdGetModels)
+	resp, err := readResponseForCommand(sc, CmdGetModels, c.lenient)
 	if err != nil {
 		return nil, err
 	}
 	var md ModelsData
-	if err := internal.UnmarshalJSON(resp.Data, &md); err != nil {
+	if err := internal.UnmarshalJSON(resp.Data, &md, c.lenient); err != nil {
 		return nil, fmt.Errorf("parse models data: %w", err)
 	}
 	out := make([]genai.Model, 0, len(md.Models))
@@ -271,7 +275,7 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 	if err := sendPrompt(stdin, &userMsg); err != nil {
 		return genai.Result{}, err
 	}
-	return readUntilDone(sc, stdin, func(string, string) bool { return true })
+	return readUntilDone(sc, stdin, c.lenient, func(string, string) bool { return true })
 }
 
 // GenStream implements genai.Provider.
@@ -317,7 +321,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 			finalErr = err
 			return
 		}
-		result, finalErr = readUntilDone(sc, stdin, func(text, reasoning string) bool {
+		result, finalErr = readUntilDone(sc, stdin, c.lenient, func(text, reasoning string) bool {
 			if text != "" && !yield(genai.Reply{Text: text}) {
 				return false
 			}
@@ -357,7 +361,7 @@ func (c *Client) setupModel(stdin io.Writer, sc *bufio.Scanner) error {
 	}); err != nil {
 		return fmt.Errorf("write set_model: %w", err)
 	}
-	_, err := readResponseForCommand(sc, CmdSetModel)
+	_, err := readResponseForCommand(sc, CmdSetModel, c.lenient)
 	return err
 }
 
@@ -410,7 +414,7 @@ func msgToPromptParts(msg *genai.Message) (string, []ImageContent, error) {
 }
 
 // readResponseForCommand reads lines until a response for the given command is found.
-func readResponseForCommand(sc *bufio.Scanner, cmd CommandType) (*Response, error) {
+func readResponseForCommand(sc *bufio.Scanner, cmd CommandType, lenient bool) (*Response, error) {
 	for sc.Scan() {
 		var probe LineProbe
 		if json.Unmarshal(sc.Bytes(), &probe) != nil {
@@ -420,7 +424,7 @@ func readResponseForCommand(sc *bufio.Scanner, cmd CommandType) (*Response, erro
 			continue
 		}
 		var resp Response
-		if err := internal.UnmarshalJSON(sc.Bytes(), &resp); err != nil {
+		if err := internal.UnmarshalJSON(sc.Bytes(), &resp, lenient); err != nil {
 			continue
 		}
 		if resp.Command != cmd {
@@ -439,7 +443,7 @@ func readResponseForCommand(sc *bufio.Scanner, cmd CommandType) (*Response, erro
 
 // readUntilDone reads events until agent_end, building a genai.Result.
 // For each text or reasoning delta, onDelta is called; returning false stops early.
-func readUntilDone(sc *bufio.Scanner, stdin io.Writer, onDelta func(text, reasoning string) bool) (genai.Result, error) {
+func readUntilDone(sc *bufio.Scanner, stdin io.Writer, lenient bool, onDelta func(text, reasoning string) bool) (genai.Result, error) {
 	var textBuf, thinkBuf strings.Builder
 	for sc.Scan() {
 		line := sc.Bytes()
@@ -471,7 +475,7 @@ func readUntilDone(sc *bufio.Scanner, stdin io.Writer, onDelta func(text, reason
 		case EventResponse:
 			// Responses to commands (e.g. prompt ack); skip.
 			var resp Response
-			if internal.UnmarshalJSON(line, &resp) == nil && !resp.Success {
+			if internal.UnmarshalJSON(line, &resp, lenient) == nil && !resp.Success {
 				return genai.Result{}, fmt.Errorf("pi error (command=%s): %s", resp.Command, resp.Error)
 			}
 		default:
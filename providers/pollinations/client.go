@@ -62,11 +62,19 @@ type Client struct {
 //
 // To use multiple models, create multiple clients.
 // Models are listed at https://docs.perplexity.ai/guides/model-cards
+//
+// The proxy defaults to the POLLINATIONS_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -83,8 +91,20 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -120,7 +140,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	default:
 		return nil, fmt.Errorf("unexpected option Modalities %s, only image or text are supported", modalities)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "POLLINATIONS_PROXY")
 	if r, ok := t.(*roundtrippers.Retry); ok {
 		// Make a copy so we can edit it.
 		c := *r
@@ -142,9 +162,12 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			GenSyncURL:      "https://text.pollinations.ai/openai",
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			LieToolCalls:    true,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
-				Lenient: internal.BeLenient,
+				Lenient: lenient,
+				Log:     logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    h,
@@ -159,7 +182,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	case "":
 	case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
 		if preferText {
-			if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 				return nil, err
 			}
 			c.impl.OutputModalities = genai.Modalities{genai.ModalityText}
@@ -191,14 +214,16 @@ func (c *Client) detectModelModalities(ctx context.Context, model string) (genai
 
 // selectBestTextModel selects the most appropriate model based on the preference (cheap, good, or SOTA).
 //
-// We may want to make this function overridable in the future by the client since this is going to break one
-// day or another.
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
 func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
 	// We only list text models here, not images generation ones.
 	mdls, err := c.ListTextModels(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	cheap := preference == string(genai.ModelCheap)
 	good := preference == string(genai.ModelGood) || preference == ""
 	selectedModel := ""
@@ -256,6 +281,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -343,6 +373,9 @@ func (c *Client) genImage(ctx context.Context, msg *genai.Message, opts ...genai
 		}
 		switch v := opt.(type) {
 		case *genai.GenOptionImage:
+			if v.Count > 1 {
+				return genai.Result{}, &base.ErrNotSupported{Options: []string{"GenOptionImage.Count"}}
+			}
 			if v.Width != 0 {
 				qp.Add("width", strconv.Itoa(v.Width))
 			}
@@ -497,7 +530,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					continue
 				}
 				if fr := pkt.Choices[0].FinishReason; fr != "" {
-					u.FinishReason = fr.ToFinishReason()
+					var err error
+					if u.FinishReason, err = fr.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				switch role := pkt.Choices[0].Delta.Role; role {
 				case "assistant", "":
@@ -565,4 +601,7 @@ func (e *exponentialBackoff) ShouldRetry(ctx context.Context, start time.Time, t
 func yieldNoFragment(yield func(genai.Reply) bool) {
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+)
@@ -17,7 +17,6 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/adapters"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/pollinations"
 	"github.com/maruel/genai/scoreboard"
@@ -56,6 +55,7 @@ func getClientInner(t *testing.T, opts *providerOptions, fn func(http.RoundTripp
 	if fn != nil {
 		provOpts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, provOpts...)
 	}
+	provOpts = append(provOpts, genai.ProviderOptionStrict(true))
 	return pollinations.New(t.Context(), provOpts...)
 }
 
@@ -147,6 +147,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 			}
+			opts = append(opts, genai.ProviderOptionStrict(true))
 			c, err := pollinations.New(t.Context(), opts...)
 			if err != nil {
 				t.Fatal(err)
@@ -241,7 +242,3 @@ func hasModalities(opts []genai.ProviderOption) bool {
 		return ok
 	})
 }
-
-func init() {
-	internal.BeLenient = false
-}
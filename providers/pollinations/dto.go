@@ -77,6 +77,9 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			errs = append(errs, e...)
 			sp = v.SystemPrompt
 		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
 			if err := c.initOptionsTools(v); err != nil {
 				errs = append(errs, err)
 			}
@@ -135,6 +138,19 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) ([]string, []error
 	if v.TopK != 0 {
 		unsupported = append(unsupported, "GenOptionText.TopK")
 	}
+	if v.N > 1 {
+		unsupported = append(unsupported, "GenOptionText.N")
+	}
+	if v.Grammar != "" {
+		unsupported = append(unsupported, "GenOptionText.Grammar")
+	}
+	switch v.ReasoningEffort {
+	case "":
+	case genai.ReasoningEffortOff:
+		unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+	default:
+		c.ReasoningEffort = ReasoningEffort(v.ReasoningEffort)
+	}
 	if v.TopLogprobs > 0 {
 		// Try to request it but it's known to not work, so add it anyway to the unsupported flag.
 		c.TopLogprobs = v.TopLogprobs
@@ -152,7 +168,8 @@ func (c *ChatRequest) initOptionsText(v *genai.GenOptionText) ([]string, []error
 }
 
 func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) error {
-	if len(v.Tools) != 0 {
+	tools := v.AllowedTools()
+	if len(tools) != 0 {
 		switch v.Force {
 		case genai.ToolCallAny:
 			c.ToolChoice = "auto"
@@ -161,8 +178,8 @@ func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) error {
 		case genai.ToolCallNone:
 			c.ToolChoice = "none"
 		}
-		c.Tools = make([]Tool, len(v.Tools))
-		for i, t := range v.Tools {
+		c.Tools = make([]Tool, len(tools))
+		for i, t := range tools {
 			c.Tools[i].Type = "function"
 			c.Tools[i].Function.Name = t.Name
 			c.Tools[i].Function.Description = t.Description
@@ -474,8 +491,9 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	if len(c.Choices) != 1 {
 		return out, fmt.Errorf("server returned an unexpected number of choices, expected 1, got %d", len(c.Choices))
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
-	err := c.Choices[0].Message.To(&out.Message)
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
 	return out, err
 }
 
@@ -490,19 +508,18 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
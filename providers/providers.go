@@ -13,7 +13,9 @@ import (
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/providers/alibaba"
 	"github.com/maruel/genai/providers/anthropic"
+	"github.com/maruel/genai/providers/azureopenai"
 	"github.com/maruel/genai/providers/baseten"
+	"github.com/maruel/genai/providers/bedrock"
 	"github.com/maruel/genai/providers/bfl"
 	"github.com/maruel/genai/providers/cerebras"
 	"github.com/maruel/genai/providers/claudecode"
@@ -21,6 +23,7 @@ import (
 	"github.com/maruel/genai/providers/codex"
 	"github.com/maruel/genai/providers/cohere"
 	"github.com/maruel/genai/providers/deepseek"
+	"github.com/maruel/genai/providers/fireworks"
 	"github.com/maruel/genai/providers/gemini"
 	"github.com/maruel/genai/providers/github"
 	"github.com/maruel/genai/providers/groq"
@@ -37,7 +40,10 @@ import (
 	"github.com/maruel/genai/providers/pi"
 	"github.com/maruel/genai/providers/pollinations"
 	"github.com/maruel/genai/providers/togetherai"
+	"github.com/maruel/genai/providers/vertexai"
+	"github.com/maruel/genai/providers/xai"
 	"github.com/maruel/genai/providers/xiaomi"
+	"github.com/maruel/genai/scoreboard"
 )
 
 // Config is a registry entry.
@@ -47,8 +53,10 @@ type Config struct {
 	// IsCLI is true for providers that launch a subprocess instead of making
 	// HTTP requests (e.g. claudecode, codex, opencode). CLI providers accept
 	// ProviderOptionStarterWrapper but not ProviderOptionTransportWrapper.
-	IsCLI   bool
-	Factory func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error)
+	IsCLI bool
+	// Scoreboard returns the provider's scoreboard without having to create a client.
+	Scoreboard func() scoreboard.Score
+	Factory    func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error)
 }
 
 // All is a easy way to propose the user to load any of the supported provider.
@@ -58,6 +66,9 @@ type Config struct {
 var All = map[string]Config{
 	"alibaba": {
 		APIKeyEnvVar: "DASHSCOPE_API_KEY",
+		Scoreboard: func() scoreboard.Score {
+			return alibaba.ScoreboardForBackend(alibaba.BackendIntl)
+		},
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := alibaba.New(ctx, opts...)
 			if p == nil {
@@ -68,6 +79,7 @@ var All = map[string]Config{
 	},
 	"anthropic": {
 		APIKeyEnvVar: "ANTHROPIC_API_KEY",
+		Scoreboard:   anthropic.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := anthropic.New(ctx, opts...)
 			if p == nil {
@@ -76,8 +88,20 @@ var All = map[string]Config{
 			return p, err
 		},
 	},
+	"azureopenai": {
+		APIKeyEnvVar: "AZURE_OPENAI_API_KEY",
+		Scoreboard:   azureopenai.Scoreboard,
+		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
+			p, err := azureopenai.New(ctx, opts...)
+			if p == nil {
+				return nil, err
+			}
+			return p, err
+		},
+	},
 	"baseten": {
 		APIKeyEnvVar: "BASETEN_API_KEY",
+		Scoreboard:   baseten.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := baseten.New(ctx, opts...)
 			if p == nil {
@@ -86,8 +110,20 @@ var All = map[string]Config{
 			return p, err
 		},
 	},
+	"bedrock": {
+		APIKeyEnvVar: "AWS_BEARER_TOKEN_BEDROCK",
+		Scoreboard:   bedrock.Scoreboard,
+		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
+			p, err := bedrock.New(ctx, opts...)
+			if p == nil {
+				return nil, err
+			}
+			return p, err
+		},
+	},
 	"bfl": {
 		APIKeyEnvVar: "BFL_API_KEY",
+		Scoreboard:   bfl.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := bfl.New(ctx, opts...)
 			if p == nil {
@@ -98,6 +134,7 @@ var All = map[string]Config{
 	},
 	"cerebras": {
 		APIKeyEnvVar: "CEREBRAS_API_KEY",
+		Scoreboard:   cerebras.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := cerebras.New(ctx, opts...)
 			if p == nil {
@@ -107,7 +144,8 @@ var All = map[string]Config{
 		},
 	},
 	"claudecode": {
-		IsCLI: true,
+		IsCLI:      true,
+		Scoreboard: claudecode.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := claudecode.New(opts...)
 			if p == nil {
@@ -117,7 +155,8 @@ var All = map[string]Config{
 		},
 	},
 	"codex": {
-		IsCLI: true,
+		IsCLI:      true,
+		Scoreboard: codex.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := codex.New(opts...)
 			if p == nil {
@@ -128,6 +167,7 @@ var All = map[string]Config{
 	},
 	"cloudflare": {
 		APIKeyEnvVar: "CLOUDFLARE_API_KEY",
+		Scoreboard:   cloudflare.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := cloudflare.New(ctx, opts...)
 			if p == nil {
@@ -138,6 +178,7 @@ var All = map[string]Config{
 	},
 	"cohere": {
 		APIKeyEnvVar: "COHERE_API_KEY",
+		Scoreboard:   cohere.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := cohere.New(ctx, opts...)
 			if p == nil {
@@ -148,6 +189,7 @@ var All = map[string]Config{
 	},
 	"deepseek": {
 		APIKeyEnvVar: "DEEPSEEK_API_KEY",
+		Scoreboard:   deepseek.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := deepseek.New(ctx, opts...)
 			if p == nil {
@@ -156,8 +198,20 @@ var All = map[string]Config{
 			return p, err
 		},
 	},
+	"fireworks": {
+		APIKeyEnvVar: "FIREWORKS_API_KEY",
+		Scoreboard:   fireworks.Scoreboard,
+		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
+			p, err := fireworks.New(ctx, opts...)
+			if p == nil {
+				return nil, err
+			}
+			return p, err
+		},
+	},
 	"gemini": {
 		APIKeyEnvVar: "GEMINI_API_KEY",
+		Scoreboard:   gemini.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := gemini.New(ctx, opts...)
 			if p == nil {
@@ -168,6 +222,7 @@ var All = map[string]Config{
 	},
 	"github": {
 		APIKeyEnvVar: "GITHUB_TOKEN",
+		Scoreboard:   github.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := github.New(ctx, opts...)
 			if p == nil {
@@ -178,6 +233,7 @@ var All = map[string]Config{
 	},
 	"groq": {
 		APIKeyEnvVar: "GROQ_API_KEY",
+		Scoreboard:   groq.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := groq.New(ctx, opts...)
 			if p == nil {
@@ -188,6 +244,7 @@ var All = map[string]Config{
 	},
 	"huggingface": {
 		APIKeyEnvVar: "HUGGINGFACE_API_KEY",
+		Scoreboard:   huggingface.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := huggingface.New(ctx, opts...)
 			if p == nil {
@@ -198,6 +255,7 @@ var All = map[string]Config{
 	},
 	"llamacpp": {
 		APIKeyEnvVar: "",
+		Scoreboard:   llamacpp.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := llamacpp.New(ctx, opts...)
 			if p == nil {
@@ -208,6 +266,7 @@ var All = map[string]Config{
 	},
 	"mistral": {
 		APIKeyEnvVar: "MISTRAL_API_KEY",
+		Scoreboard:   mistral.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := mistral.New(ctx, opts...)
 			if p == nil {
@@ -218,6 +277,7 @@ var All = map[string]Config{
 	},
 	"ollama": {
 		APIKeyEnvVar: "",
+		Scoreboard:   ollama.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := ollama.New(ctx, opts...)
 			if p == nil {
@@ -229,6 +289,7 @@ var All = map[string]Config{
 	"openai": {
 		APIKeyEnvVar: "OPENAI_API_KEY",
 		Alias:        "openairesponses",
+		Scoreboard:   openairesponses.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := openairesponses.New(ctx, opts...)
 			if p == nil {
@@ -239,6 +300,7 @@ var All = map[string]Config{
 	},
 	"openaichat": {
 		APIKeyEnvVar: "OPENAI_API_KEY",
+		Scoreboard:   openaichat.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := openaichat.New(ctx, opts...)
 			if p == nil {
@@ -249,6 +311,7 @@ var All = map[string]Config{
 	},
 	"openairesponses": {
 		APIKeyEnvVar: "OPENAI_API_KEY",
+		Scoreboard:   openairesponses.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := openairesponses.New(ctx, opts...)
 			if p == nil {
@@ -258,6 +321,7 @@ var All = map[string]Config{
 		},
 	},
 	"openaicompatible": {
+		Scoreboard: openaicompatible.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := openaicompatible.New(ctx, opts...)
 			if p == nil {
@@ -267,7 +331,8 @@ var All = map[string]Config{
 		},
 	},
 	"opencode": {
-		IsCLI: true,
+		IsCLI:      true,
+		Scoreboard: opencode.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := opencode.New(opts...)
 			if p == nil {
@@ -277,7 +342,8 @@ var All = map[string]Config{
 		},
 	},
 	"pi": {
-		IsCLI: true,
+		IsCLI:      true,
+		Scoreboard: pi.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := pi.New(opts...)
 			if p == nil {
@@ -288,6 +354,7 @@ var All = map[string]Config{
 	},
 	"openrouter": {
 		APIKeyEnvVar: "OPENROUTER_API_KEY",
+		Scoreboard:   openrouter.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := openrouter.New(ctx, opts...)
 			if p == nil {
@@ -298,6 +365,7 @@ var All = map[string]Config{
 	},
 	"perplexity": {
 		APIKeyEnvVar: "PERPLEXITY_API_KEY",
+		Scoreboard:   perplexity.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := perplexity.New(ctx, opts...)
 			if p == nil {
@@ -308,6 +376,7 @@ var All = map[string]Config{
 	},
 	"pollinations": {
 		APIKeyEnvVar: "POLLINATIONS_API_KEY",
+		Scoreboard:   pollinations.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := pollinations.New(ctx, opts...)
 			if p == nil {
@@ -318,6 +387,7 @@ var All = map[string]Config{
 	},
 	"togetherai": {
 		APIKeyEnvVar: "TOGETHER_API_KEY",
+		Scoreboard:   togetherai.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := togetherai.New(ctx, opts...)
 			if p == nil {
@@ -326,8 +396,31 @@ var All = map[string]Config{
 			return p, err
 		},
 	},
+	"vertexai": {
+		APIKeyEnvVar: "",
+		Scoreboard:   vertexai.Scoreboard,
+		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
+			p, err := vertexai.New(ctx, opts...)
+			if p == nil {
+				return nil, err
+			}
+			return p, err
+		},
+	},
+	"xai": {
+		APIKeyEnvVar: "XAI_API_KEY",
+		Scoreboard:   xai.Scoreboard,
+		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
+			p, err := xai.New(ctx, opts...)
+			if p == nil {
+				return nil, err
+			}
+			return p, err
+		},
+	},
 	"xiaomi": {
 		APIKeyEnvVar: "MIMO_API_KEY",
+		Scoreboard:   xiaomi.Scoreboard,
 		Factory: func(ctx context.Context, opts ...genai.ProviderOption) (genai.Provider, error) {
 			p, err := xiaomi.New(ctx, opts...)
 			if p == nil {
@@ -0,0 +1,164 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Conformance tests across all the registered providers.
+
+package providers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/providers"
+	"github.com/maruel/genai/providers/pollinations"
+	"github.com/maruel/genai/scoreboard"
+)
+
+// TestToolInputSchemaOverride ensures that a user supplied JSON schema is sent unmodified by every provider
+// supporting tools, including constructs that can't be derived from a Go type.
+func TestToolInputSchemaOverride(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"properties": {
+			"shape": {
+				"oneOf": [
+					{"$ref": "#/$defs/circle"},
+					{"type": "object", "properties": {"side": {"type": "number"}}, "required": ["side"]}
+				]
+			},
+			"unit": {"type": "string", "enum": ["cm", "in"], "description": "Set at runtime."}
+		},
+		"required": ["shape"],
+		"$defs": {"circle": {"type": "object", "properties": {"radius": {"type": "number"}}, "required": ["radius"]}}
+	}`
+	var want any
+	if err := json.Unmarshal([]byte(schema), &want); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "https://example.openai.azure.com")
+	t.Setenv("CLOUDFLARE_ACCOUNT_ID", "account")
+	extra := map[string][]genai.ProviderOption{
+		"gemini":           {genai.ProviderOptionModalities{genai.ModalityText}},
+		"openaicompatible": {genai.ProviderOptionRemote("http://localhost:8080/v1")},
+		"pollinations":     {genai.ProviderOptionPreloadedModels{&pollinations.TextModel{Name: "model"}}},
+		"togetherai":       {genai.ProviderOptionModalities{genai.ModalityText}},
+		"vertexai":         {genai.ProviderOptionAPIKey("key")},
+	}
+	for _, name := range slices.Sorted(maps.Keys(providers.All)) {
+		cfg := providers.All[name]
+		if cfg.IsCLI {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			var body []byte
+			wrapper := genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+				return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					if r.Body != nil {
+						body, _ = io.ReadAll(r.Body)
+					}
+					return &http.Response{
+						StatusCode: http.StatusBadRequest,
+						Header:     http.Header{"Content-Type": {"application/json"}},
+						Body:       io.NopCloser(strings.NewReader("{}")),
+						Request:    r,
+					}, nil
+				})
+			})
+			opts := []genai.ProviderOption{genai.ProviderOptionModel("model"), wrapper}
+			if cfg.APIKeyEnvVar != "" {
+				opts = append(opts, genai.ProviderOptionAPIKey("key"))
+			}
+			opts = append(opts, extra[name]...)
+			c, err := cfg.Factory(t.Context(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tool := genai.ToolDef{Name: "area", Description: "Calculates the area of a shape.", InputSchemaOverride: genai.JSONSchema(schema)}
+			_, err = c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Area of a 2cm circle?")}, &genai.GenOptionTools{Tools: []genai.ToolDef{tool}})
+			if body == nil {
+				if !supportsTools(c.Scoreboard()) {
+					t.Skip(err)
+				}
+				t.Fatalf("request not sent: %v", err)
+			}
+			var got any
+			d := json.NewDecoder(bytes.NewReader(body))
+			d.UseNumber()
+			if err := d.Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if !containsValue(got, want) {
+				t.Fatalf("schema not found in request:\n%s", body)
+			}
+		})
+	}
+}
+
+func TestFindProviders(t *testing.T) {
+	for name, cfg := range providers.All {
+		if cfg.Scoreboard == nil {
+			t.Errorf("%s: missing Scoreboard", name)
+		}
+	}
+	req := &scoreboard.Requirements{Tools: true, JSONSchema: true, In: []scoreboard.Modality{scoreboard.ModalityImage}}
+	got, err := providers.FindProviders(providers.All, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 {
+		t.Fatal("expected at least one provider")
+	}
+	for _, m := range got {
+		if !req.Match(m.Scenario) || m.Model != m.Scenario.Models[0] || m.Config.Alias != "" {
+			t.Errorf("unexpected match %s/%s", m.Provider, m.Model)
+		}
+	}
+	if !slices.IsSortedFunc(got, func(a, b providers.Match) int { return strings.Compare(a.Provider, b.Provider) }) {
+		t.Error("expected matches sorted by provider")
+	}
+	if _, err := providers.FindProviders(providers.All, &scoreboard.Requirements{Out: []scoreboard.Modality{"smell"}}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// supportsTools returns true if any scenario of the scoreboard supports tools.
+func supportsTools(s scoreboard.Score) bool {
+	for _, sc := range s.Scenarios {
+		if sc.GenSync != nil && sc.GenSync.Tools != scoreboard.False {
+			return true
+		}
+	}
+	return false
+}
+
+// containsValue returns true if want is v or one of its descendants.
+func containsValue(v, want any) bool {
+	if reflect.DeepEqual(v, want) {
+		return true
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for _, c := range v {
+			if containsValue(c, want) {
+				return true
+			}
+		}
+	case []any:
+		for _, c := range v {
+			if containsValue(c, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -49,10 +49,27 @@ func Scoreboard() scoreboard.Score {
 	return s
 }
 
+// ProviderOptionRerankModel sets the model used by Client.Rerank. Defaults to DefaultRerankModel.
+//
+// See https://docs.together.ai/docs/serverless-models#rerank-models
+type ProviderOptionRerankModel string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionRerankModel) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionRerankModel cannot be empty")
+	}
+	return nil
+}
+
+// DefaultRerankModel is the model used by Client.Rerank unless ProviderOptionRerankModel is specified.
+const DefaultRerankModel = "mixedbread-ai/Mxbai-Rerank-Large-V2"
+
 // Client implements genai.Provider.
 type Client struct {
 	base.NotImplemented
-	impl base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]
+	impl        base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]
+	rerankModel string
 }
 
 // New creates a new client to talk to the Together.AI platform API.
@@ -64,6 +81,8 @@ type Client struct {
 // To use multiple models, create multiple clients.
 // Use one of the model from https://docs.together.ai/docs/serverless-models
 //
+// The proxy defaults to the TOGETHER_PROXY environment variable, see genai.ProviderOptionProxyURL.
+//
 // # Vision
 //
 // We must select a model that supports video.
@@ -72,7 +91,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	rerankModel := DefaultRerankModel
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -89,8 +115,22 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case ProviderOptionRerankModel:
+			rerankModel = string(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -118,7 +158,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	default:
 		return nil, fmt.Errorf("unexpected option Modalities %s, only image or text are implemented (send PR to add support)", modalities)
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "TOGETHER_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -127,10 +167,13 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			GenSyncURL:      "https://api.together.xyz/v1/chat/completions",
 			ProcessStream:   ProcessStream,
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProcessHeaders:  processHeaders,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
@@ -139,13 +182,14 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 				},
 			},
 		},
+		rerankModel: rerankModel,
 	}
 	if err == nil {
 		switch model {
 		case "":
 		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
 			if len(modalities) == 0 || modalities[0] == genai.ModalityText {
-				if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+				if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 					return nil, err
 				}
 				c.impl.OutputModalities = genai.Modalities{genai.ModalityText}
@@ -195,13 +239,15 @@ func (c *Client) detectModelModalities(ctx context.Context, model string) (genai
 
 // selectBestTextModel selects the most appropriate model based on the preference (cheap, good, or SOTA).
 //
-// We may want to make this function overridable in the future by the client since this is going to break one
-// day or another.
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
 func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
 	mdls, err := c.ListModels(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	cheap := preference == string(genai.ModelCheap)
 	good := preference == string(genai.ModelGood) || preference == ""
 	selectedModel := ""
@@ -373,6 +419,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -472,6 +523,26 @@ func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
 	return resp.ToModels(), nil
 }
 
+// Rerank implements genai.ProviderRerank.
+//
+// It uses the model specified with ProviderOptionRerankModel, not the chat model.
+func (c *Client) Rerank(ctx context.Context, query string, documents []string, topN int) ([]genai.RerankResult, error) {
+	in := RerankRequest{Model: c.rerankModel, Query: query, Documents: documents, TopN: int64(topN)}
+	var out RerankResponse
+	if err := c.RerankRaw(ctx, &in, &out); err != nil {
+		return nil, err
+	}
+	return out.To(len(documents))
+}
+
+// RerankRaw provides access to the raw rerank API.
+//
+// The chat model is not required since the request specifies its own model.
+func (c *Client) RerankRaw(ctx context.Context, in *RerankRequest, out *RerankResponse) error {
+	// https://docs.together.ai/reference/rerank-1
+	return c.impl.DoRequest(ctx, "POST", "https://api.together.xyz/v1/rerank", in, out)
+}
+
 // ProcessStream converts the raw packets from the streaming API into Reply fragments.
 func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error)) {
 	var finalErr error
@@ -523,7 +594,10 @@ func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Rep
 					return
 				}
 				if pkt.Choices[0].FinishReason != "" {
-					u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+					var err error
+					if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
 				}
 				switch role := pkt.Choices[0].Delta.Role; role {
 				case "assistant", "":
@@ -626,4 +700,8 @@ func processHeaders(h http.Header) []genai.RateLimit {
 	return limits
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+	_ genai.ProviderRerank         = &Client{}
+)
@@ -9,6 +9,8 @@ package togetherai_test
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
+	"io"
 	"net/http"
 	"os"
 	"slices"
@@ -17,7 +19,6 @@ import (
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/adapters"
-	"github.com/maruel/genai/internal"
 	"github.com/maruel/genai/internal/internaltest"
 	"github.com/maruel/genai/providers/togetherai"
 	"github.com/maruel/genai/scoreboard"
@@ -38,6 +39,7 @@ func getClientInner(t *testing.T, fn func(http.RoundTripper) http.RoundTripper,
 	if fn != nil {
 		opts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, opts...)
 	}
+	opts = append(opts, genai.ProviderOptionStrict(true))
 	return togetherai.New(t.Context(), opts...)
 }
 
@@ -106,6 +108,7 @@ func TestClient(t *testing.T) {
 			if fn != nil {
 				provOpts = append([]genai.ProviderOption{genai.ProviderOptionTransportWrapper(fn)}, provOpts...)
 			}
+			provOpts = append(provOpts, genai.ProviderOptionStrict(true))
 			c, err := togetherai.New(t.Context(), provOpts...)
 			if err != nil {
 				t.Fatal(err)
@@ -235,6 +238,40 @@ func hasModalities(opts []genai.ProviderOption) bool {
 	})
 }
 
-func init() {
-	internal.BeLenient = false
+func TestRerank(t *testing.T) {
+	var got togetherai.RerankRequest
+	fake := internaltest.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != "https://api.together.xyz/v1/rerank" {
+			t.Errorf("unexpected URL %s", req.URL)
+		}
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		body := `{"id":"1","object":"rerank","model":"mixedbread-ai/Mxbai-Rerank-Large-V2","results":[{"index":1,"relevance_score":0.8,"document":{"text":""}}],"usage":{"prompt_tokens":10,"completion_tokens":0,"total_tokens":10}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	c, err := togetherai.New(t.Context(),
+		genai.ProviderOptionAPIKey("key"),
+		genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper { return fake }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Rerank(t.Context(), "capital", []string{"a", "b"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []genai.RerankResult{{Index: 1, Score: 0.8}}; !slices.Equal(want, res) {
+		t.Fatalf("want %v, got %v", want, res)
+	}
+	if got.Model != togetherai.DefaultRerankModel || got.Query != "capital" || got.TopN != 1 {
+		t.Fatalf("unexpected request %#v", got)
+	}
+	if _, err := c.Rerank(t.Context(), "capital", []string{"a"}, 0); err == nil {
+		t.Fatal("expected error on out of range index")
+	}
 }
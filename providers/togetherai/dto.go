@@ -15,8 +15,10 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/maruel/genai"
 	"github.com/maruel/genai/base"
@@ -80,6 +82,13 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			// 	unsupported = append(unsupported, "GenOptionText.TopLogprobs")
 			// }
 			c.TopK = v.TopK
+			c.N = int32(v.N)
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
 			c.Stop = v.Stop
 			if v.DecodeAs != nil {
 				// Warning: using a model small may fail.
@@ -94,7 +103,11 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				c.ResponseFormat.Type = "json_object"
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				switch v.Force {
 				case genai.ToolCallAny:
 					c.ToolChoice = "auto"
@@ -106,8 +119,8 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				case genai.ToolCallNone:
 					c.ToolChoice = "none"
 				}
-				c.Tools = make([]Tool, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]Tool, len(tools))
+				for i, t := range tools {
 					c.Tools[i].Type = "function"
 					c.Tools[i].Function.Name = t.Name
 					c.Tools[i].Function.Description = t.Description
@@ -272,11 +285,7 @@ func (c *Contents) UnmarshalJSON(b []byte) error {
 		*c = nil
 		return nil
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode((*[]Content)(c)); err == nil {
+	if err := json.Unmarshal(b, (*[]Content)(c)); err == nil {
 		return nil
 	}
 
@@ -511,12 +520,23 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 			TotalTokens:       c.Usage.TotalTokens,
 		},
 	}
-	if len(c.Choices) != 1 {
-		return out, fmt.Errorf("server returned an unexpected number of choices, expected 1, got %d", len(c.Choices))
+	if len(c.Choices) == 0 {
+		return out, errors.New("server returned no choice")
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
+	// The server may not return the choices in order. The first one is the result, the rest are candidates.
+	sort.SliceStable(c.Choices, func(i, j int) bool { return c.Choices[i].Index < c.Choices[j].Index })
+	var err error
+	out.Usage.FinishReason, err = c.Choices[0].FinishReason.ToFinishReason()
 	out.Logprobs = c.Choices[0].Logprobs.To()
-	err := c.Choices[0].Message.To(&out.Message)
+	err = errors.Join(err, c.Choices[0].Message.To(&out.Message))
+	if len(c.Choices) > 1 {
+		out.Candidates = make([]genai.Message, len(c.Choices)-1)
+		for i := 1; i < len(c.Choices); i++ {
+			if err2 := c.Choices[i].Message.To(&out.Candidates[i-1]); err2 != nil {
+				err = errors.Join(err, fmt.Errorf("choice #%d: %w", i, err2))
+			}
+		}
+	}
 	if err == nil && len(c.Warnings) != 0 {
 		ent := &base.ErrNotSupported{}
 		for _, w := range c.Warnings {
@@ -544,21 +564,20 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop, "":
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishEOS:
-		return genai.FinishedStopSequence
+		return genai.FinishedStopSequence, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishToolCalls, FinishFunctionCall:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -613,13 +632,7 @@ func (l *LogprobsChunk) UnmarshalJSON(b []byte) error {
 
 	type Alias LogprobsChunk
 	a := struct{ *Alias }{Alias: (*Alias)(l)}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if internal.BeLenient {
-		// Allow unknown fields when lenient mode is on
-	} else {
-		d.DisallowUnknownFields()
-	}
-	return d.Decode(&a)
+	return json.Unmarshal(b, &a)
 }
 
 // To converts to the genai equivalent.
@@ -777,6 +790,24 @@ func (m *Model) Context() int64 {
 	return m.ContextLength
 }
 
+// MaxOutputTokens implements genai.ModelOutputTokens.
+func (m *Model) MaxOutputTokens() int64 {
+	return m.Config.MaxOutputLength
+}
+
+// GetCreated implements genai.ModelCreated.
+func (m *Model) GetCreated() time.Time {
+	if m.Created.IsZero() {
+		return time.Time{}
+	}
+	return m.Created.AsTime()
+}
+
+// GetPricing implements genai.ModelPricing.
+func (m *Model) GetPricing() genai.ModelPrice {
+	return genai.ModelPrice{Input: m.Pricing.Input, Output: m.Pricing.Output}
+}
+
 // PricingImagePixel is the per-megapixel pricing for image generation.
 //
 // The API returns either 0 (no pricing) or an object with pricing details.
@@ -879,6 +910,7 @@ func (i *ImageRequest) Init(msg *genai.Message, model string, opts ...genai.GenO
 		case *genai.GenOptionImage:
 			i.Height = int64(v.Height)
 			i.Width = int64(v.Width)
+			i.N = int64(v.Count)
 		case genai.GenOptionSeed:
 			i.Seed = int64(v)
 		default:
@@ -888,6 +920,42 @@ func (i *ImageRequest) Init(msg *genai.Message, model string, opts ...genai.GenO
 	return nil
 }
 
+// RerankRequest is documented at https://docs.together.ai/reference/rerank-1
+type RerankRequest struct {
+	Model           string   `json:"model"`
+	Query           string   `json:"query"`
+	Documents       []string `json:"documents"`
+	TopN            int64    `json:"top_n,omitzero"`
+	ReturnDocuments bool     `json:"return_documents,omitzero"`
+}
+
+// RerankResponse is documented at https://docs.together.ai/reference/rerank-1
+type RerankResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"` // "rerank"
+	Model   string `json:"model"`
+	Results []struct {
+		Index          int64   `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+		Document       struct {
+			Text string `json:"text"`
+		} `json:"document"`
+	} `json:"results"`
+	Usage Usage `json:"usage"`
+}
+
+// To converts to the genai equivalent. n is the number of documents sent.
+func (r *RerankResponse) To(n int) ([]genai.RerankResult, error) {
+	out := make([]genai.RerankResult, len(r.Results))
+	for i := range r.Results {
+		if r.Results[i].Index < 0 || r.Results[i].Index >= int64(n) {
+			return nil, &internal.BadError{Err: fmt.Errorf("result %d: invalid index %d for %d documents", i, r.Results[i].Index, n)}
+		}
+		out[i] = genai.RerankResult{Index: int(r.Results[i].Index), Score: r.Results[i].RelevanceScore}
+	}
+	return out, nil
+}
+
 // ImageResponse doesn't have a formal documentation.
 //
 // https://github.com/togethercomputer/together-python/blob/main/src/together/types/images.py is the
@@ -0,0 +1,296 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// OAuth2 access tokens from Google Application Default Credentials, implemented with the standard library
+// only.
+
+package vertexai
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/providers/gemini"
+)
+
+// Scope is the OAuth2 scope requested for Vertex AI.
+const Scope = "https://www.googleapis.com/auth/cloud-platform"
+
+const (
+	defaultTokenURL = "https://oauth2.googleapis.com/token"
+	metadataURL     = "http://metadata.google.internal/computeMetadata/v1/"
+)
+
+// Token is an OAuth2 access token.
+type Token = gemini.Token
+
+// ProviderOptionTokenSource returns OAuth2 access tokens used as Bearer tokens. It is called before each
+// request; the client caches the returned token until it is about to expire.
+//
+// Use FindDefaultCredentials to get one from Application Default Credentials. It is the same type as
+// gemini.ProviderOptionTokenSource so the credentials can be used with both providers.
+type ProviderOptionTokenSource = gemini.ProviderOptionTokenSource
+
+// Credentials are Google Cloud credentials.
+type Credentials struct {
+	// ProjectID is the project associated with the credentials, if known.
+	ProjectID string
+	// TokenSource returns access tokens for the credentials.
+	TokenSource ProviderOptionTokenSource
+}
+
+// FindDefaultCredentials looks up Application Default Credentials.
+//
+// It looks in order at the file referenced by the GOOGLE_APPLICATION_CREDENTIALS environment variable, the
+// file created by "gcloud auth application-default login", then the metadata server when running on Google
+// Cloud.
+//
+// client is used to fetch the tokens. It defaults to a client using base.DefaultTransport.
+//
+// See https://cloud.google.com/docs/authentication/application-default-credentials
+func FindDefaultCredentials(ctx context.Context, client *http.Client) (*Credentials, error) {
+	if client == nil {
+		client = &http.Client{Transport: base.DefaultTransport}
+	}
+	if p := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); p != "" {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+		}
+		return CredentialsFromJSON(b, client)
+	}
+	if p := wellKnownCredentialsFile(); p != "" {
+		if b, err := os.ReadFile(p); err == nil {
+			return CredentialsFromJSON(b, client)
+		}
+	}
+	// Probe the metadata server. Outside of Google Cloud the host name doesn't resolve so it fails fast.
+	ctx2, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	project, err := getMetadata(ctx2, client, "project/project-id")
+	if err != nil {
+		return nil, errors.New("no Application Default Credentials found; set GOOGLE_APPLICATION_CREDENTIALS or run \"gcloud auth application-default login\"; see https://cloud.google.com/docs/authentication/provide-credentials-adc")
+	}
+	return &Credentials{
+		ProjectID: project,
+		TokenSource: func(ctx context.Context) (Token, error) {
+			b, err := getMetadata(ctx, client, "instance/service-accounts/default/token?scopes="+url.QueryEscape(Scope))
+			if err != nil {
+				return Token{}, err
+			}
+			return decodeToken(strings.NewReader(b))
+		},
+	}, nil
+}
+
+// CredentialsFromJSON loads credentials from a JSON credentials file.
+//
+// Service account keys and authorized user credentials (as created by "gcloud auth application-default
+// login") are supported.
+//
+// client is used to fetch the tokens. It defaults to a client using base.DefaultTransport.
+func CredentialsFromJSON(b []byte, client *http.Client) (*Credentials, error) {
+	if client == nil {
+		client = &http.Client{Transport: base.DefaultTransport}
+	}
+	var f credentialsFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	if f.TokenURI == "" {
+		f.TokenURI = defaultTokenURL
+	}
+	switch f.Type {
+	case "service_account":
+		key, err := parsePrivateKey(f.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		return &Credentials{
+			ProjectID: f.ProjectID,
+			TokenSource: func(ctx context.Context) (Token, error) {
+				assertion, err := signJWT(key, f.PrivateKeyID, f.ClientEmail, f.TokenURI, time.Now())
+				if err != nil {
+					return Token{}, err
+				}
+				return postToken(ctx, client, f.TokenURI, url.Values{
+					"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+					"assertion":  {assertion},
+				})
+			},
+		}, nil
+	case "authorized_user":
+		if f.RefreshToken == "" {
+			return nil, errors.New("authorized_user credentials are missing refresh_token")
+		}
+		return &Credentials{
+			ProjectID: f.QuotaProjectID,
+			TokenSource: func(ctx context.Context) (Token, error) {
+				return postToken(ctx, client, f.TokenURI, url.Values{
+					"grant_type":    {"refresh_token"},
+					"client_id":     {f.ClientID},
+					"client_secret": {f.ClientSecret},
+					"refresh_token": {f.RefreshToken},
+				})
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported credentials type %q", f.Type)
+	}
+}
+
+// credentialsFile is the JSON credentials file format.
+type credentialsFile struct {
+	Type string `json:"type"`
+
+	// Type == "service_account"
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+
+	// Type == "authorized_user"
+	ClientID       string `json:"client_id"`
+	ClientSecret   string `json:"client_secret"`
+	RefreshToken   string `json:"refresh_token"`
+	QuotaProjectID string `json:"quota_project_id"`
+}
+
+// wellKnownCredentialsFile returns the path to the file written by gcloud.
+func wellKnownCredentialsFile() string {
+	const f = "application_default_credentials.json"
+	if runtime.GOOS == "windows" {
+		if d := os.Getenv("APPDATA"); d != "" {
+			return filepath.Join(d, "gcloud", f)
+		}
+		return ""
+	}
+	if d, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(d, ".config", "gcloud", f)
+	}
+	return ""
+}
+
+func parsePrivateKey(s string) (*rsa.PrivateKey, error) {
+	b, _ := pem.Decode([]byte(s))
+	if b == nil {
+		return nil, errors.New("failed to decode private_key: invalid PEM")
+	}
+	k, err := x509.ParsePKCS8PrivateKey(b.Bytes)
+	if err != nil {
+		if k2, err2 := x509.ParsePKCS1PrivateKey(b.Bytes); err2 == nil {
+			return k2, nil
+		}
+		return nil, fmt.Errorf("failed to parse private_key: %w", err)
+	}
+	r, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private_key type %T", k)
+	}
+	return r, nil
+}
+
+// signJWT returns a signed JWT assertion to exchange for an access token.
+//
+// https://developers.google.com/identity/protocols/oauth2/service-account#authorizingrequests
+func signJWT(key *rsa.PrivateKey, keyID, email, aud string, now time.Time) (string, error) {
+	hdr, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   email,
+		"scope": Scope,
+		"aud":   aud,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	s := enc.EncodeToString(hdr) + "." + enc.EncodeToString(claims)
+	h := sha256.Sum256([]byte(s))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return s + "." + enc.EncodeToString(sig), nil
+}
+
+func postToken(ctx context.Context, client *http.Client, tokenURL string, v url.Values) (Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to get access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return Token{}, fmt.Errorf("failed to get access token: http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return decodeToken(resp.Body)
+}
+
+func getMetadata(ctx context.Context, client *http.Client, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server: http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return string(b), nil
+}
+
+func decodeToken(r io.Reader) (Token, error) {
+	var t struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return Token{}, fmt.Errorf("failed to decode access token: %w", err)
+	}
+	if t.AccessToken == "" {
+		return Token{}, errors.New("no access token returned")
+	}
+	out := Token{AccessToken: t.AccessToken}
+	if t.ExpiresIn > 0 {
+		out.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	return out, nil
+}
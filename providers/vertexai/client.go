@@ -0,0 +1,579 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package vertexai implements a client for Google's Vertex AI.
+//
+// It serves the same Gemini, Imagen and Veo models as package gemini through Google Cloud, with OAuth2
+// authentication, regional endpoints and the Vertex only features like seeded Imagen generation. The wire
+// format is shared with package gemini.
+//
+// It is described at https://cloud.google.com/vertex-ai/generative-ai/docs/reference/rest
+package vertexai
+
+// See official client at https://github.com/googleapis/go-genai
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/maruel/roundtrippers"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal/bb"
+	"github.com/maruel/genai/internal/bearer"
+	"github.com/maruel/genai/providers/gemini"
+	"github.com/maruel/genai/scoreboard"
+)
+
+//go:embed scoreboard.json
+var scoreboardJSON []byte
+
+// Scoreboard for Vertex AI.
+func Scoreboard() scoreboard.Score {
+	var s scoreboard.Score
+	d := json.NewDecoder(bytes.NewReader(scoreboardJSON))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&s); err != nil {
+		panic(fmt.Errorf("failed to unmarshal scoreboard.json: %w", err))
+	}
+	return s
+}
+
+// ProviderOptionProject is the Google Cloud project ID to bill.
+//
+// It defaults to the GOOGLE_CLOUD_PROJECT environment variable, then to the project associated with the
+// credentials.
+type ProviderOptionProject string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionProject) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionProject cannot be empty")
+	}
+	return nil
+}
+
+// ProviderOptionLocation is the Google Cloud region to use, e.g. "us-central1" or "europe-west4".
+//
+// It defaults to the GOOGLE_CLOUD_LOCATION environment variable, then to "global". Preview models are often
+// only available in the global location.
+//
+// See https://cloud.google.com/vertex-ai/generative-ai/docs/learn/locations
+type ProviderOptionLocation string
+
+// Validate implements genai.ProviderOption.
+func (p ProviderOptionLocation) Validate() error {
+	if p == "" {
+		return errors.New("ProviderOptionLocation cannot be empty")
+	}
+	for _, c := range p {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return fmt.Errorf("invalid ProviderOptionLocation %q", string(p))
+		}
+	}
+	return nil
+}
+
+// Client implements genai.Provider.
+type Client struct {
+	base.NotImplemented
+	impl base.Provider[*gemini.ErrorResponse, *gemini.ChatRequest, *gemini.ChatResponse, gemini.ChatStreamChunkResponse]
+	// baseURL is the URL up to and including "/publishers/google/models".
+	baseURL string
+}
+
+// New creates a new client to talk to Google's Vertex AI platform API.
+//
+// Authentication is done in order with:
+//   - ProviderOptionAPIKey, for Vertex AI express mode. No project is used in this mode.
+//   - ProviderOptionTokenSource.
+//   - Application Default Credentials, see FindDefaultCredentials.
+//
+// If no credentials are found, it will still return a client coupled with an error.
+//
+// Use ProviderOptionProject and ProviderOptionLocation to select the project and region.
+// ProviderOptionRemote overrides the host, e.g. for Private Service Connect endpoints.
+//
+// To use multiple models, create multiple clients.
+// Use one of the model from https://cloud.google.com/vertex-ai/generative-ai/docs/models
+//
+// The proxy defaults to the VERTEXAI_PROXY environment variable, see genai.ProviderOptionProxyURL.
+func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
+	var apiKey, model, remote, project, location string
+	var modalities genai.Modalities
+	var preloadedModels []genai.Model
+	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
+	var ts ProviderOptionTokenSource
+	if err := base.CheckDuplicateOptions(opts); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return nil, err
+		}
+		switch v := opt.(type) {
+		case genai.ProviderOptionAPIKey:
+			apiKey = string(v)
+		case genai.ProviderOptionModel:
+			model = string(v)
+		case genai.ProviderOptionModalities:
+			modalities = genai.Modalities(v)
+		case genai.ProviderOptionPreloadedModels:
+			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionTransportWrapper:
+			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		case genai.ProviderOptionRemote:
+			remote = string(v)
+		case ProviderOptionProject:
+			project = string(v)
+		case ProviderOptionLocation:
+			location = string(v)
+		case ProviderOptionTokenSource:
+			ts = v
+		default:
+			return nil, fmt.Errorf("unsupported option type %T", opt)
+		}
+	}
+	switch len(modalities) {
+	case 0:
+	case 1:
+		switch modalities[0] {
+		case genai.ModalityImage, genai.ModalityText, genai.ModalityVideo:
+		case genai.ModalityAudio, genai.ModalityDocument:
+			return nil, fmt.Errorf("unexpected option Modalities %s, only image, text, or video are supported", modalities)
+		default:
+			return nil, fmt.Errorf("unexpected option Modalities %s, only image, text, or video are supported", modalities)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected option Modalities %s, only image, text, or video are supported", modalities)
+	}
+	if project == "" {
+		project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if location == "" {
+		if location = os.Getenv("GOOGLE_CLOUD_LOCATION"); location == "" {
+			location = "global"
+		}
+	}
+	var err error
+	if apiKey == "" && ts == nil {
+		var creds *Credentials
+		if creds, err = FindDefaultCredentials(ctx, nil); err == nil {
+			ts = creds.TokenSource
+			if project == "" {
+				project = creds.ProjectID
+			}
+		}
+	}
+	if err == nil && apiKey == "" && project == "" {
+		err = errors.New("a project is required; set environment variable GOOGLE_CLOUD_PROJECT or use ProviderOptionProject")
+	}
+	if remote == "" {
+		if location == "global" {
+			remote = "https://aiplatform.googleapis.com"
+		} else {
+			remote = "https://" + location + "-aiplatform.googleapis.com"
+		}
+	}
+	// Google supports HTTP POST gzip compression!
+	var t http.RoundTripper = &roundtrippers.PostCompressed{
+		Transport: base.NewTransport(httpOpts, proxyURL, "VERTEXAI_PROXY"),
+		Encoding:  "gzip",
+	}
+	if wrapper != nil {
+		t = wrapper(t)
+	}
+	t = &roundtrippers.RequestID{Transport: t}
+	c := &Client{}
+	if apiKey != "" {
+		// Express mode.
+		c.baseURL = remote + "/v1/publishers/google/models"
+		t = &roundtrippers.Header{Header: http.Header{"x-goog-api-key": {apiKey}}, Transport: t}
+	} else {
+		c.baseURL = remote + "/v1/projects/" + url.PathEscape(project) + "/locations/" + url.PathEscape(location) + "/publishers/google/models"
+		if ts != nil {
+			t = &bearer.Transport{Source: ts, Transport: t}
+		}
+	}
+	c.impl = base.Provider[*gemini.ErrorResponse, *gemini.ChatRequest, *gemini.ChatResponse, gemini.ChatStreamChunkResponse]{
+		ProcessStream:   gemini.ProcessStream,
+		PreloadedModels: preloadedModels,
+		LieToolCalls:    true,
+		SchemaEnforced:  true,
+		ProviderBase: base.ProviderBase[*gemini.ErrorResponse]{
+			APIKeyURL: "https://console.cloud.google.com/vertex-ai",
+			Lenient:   lenient,
+			Log:       logger,
+			Client:    http.Client{Transport: t},
+		},
+	}
+	if err == nil {
+		switch model {
+		case "":
+		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
+			mod := genai.ModalityText
+			if len(modalities) == 1 {
+				mod = modalities[0]
+			}
+			if c.impl.Model, err = selectBestModel(model, mod); err != nil {
+				return nil, err
+			}
+			c.impl.OutputModalities = genai.Modalities{mod}
+		default:
+			c.impl.Model = model
+			if len(modalities) == 0 {
+				c.impl.OutputModalities = detectModelModalities(model)
+			} else {
+				c.impl.OutputModalities = modalities
+			}
+		}
+		if c.impl.Model != "" {
+			c.impl.GenSyncURL = c.modelURL(":generateContent")
+			c.impl.GenStreamURL = c.modelURL(":streamGenerateContent?alt=sse")
+		}
+	}
+	return c, err
+}
+
+// selectBestModel returns the reference model for the preference (cheap, good, or SOTA) from the
+// scoreboard.
+func selectBestModel(preference string, mod genai.Modality) (string, error) {
+	for _, sc := range Scoreboard().Scenarios {
+		if _, ok := sc.Out[scoreboard.Modality(mod)]; !ok || len(sc.Models) == 0 {
+			continue
+		}
+		switch preference {
+		case string(genai.ModelCheap):
+			if sc.Cheap {
+				return sc.Models[0], nil
+			}
+		case string(genai.ModelGood):
+			if sc.Good {
+				return sc.Models[0], nil
+			}
+		default:
+			if sc.SOTA {
+				return sc.Models[0], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to find a %s model for modality %s", preference, mod)
+}
+
+// detectModelModalities guesses the output modality of a model from its name.
+//
+// Vertex AI doesn't report the supported methods of publisher models.
+func detectModelModalities(model string) genai.Modalities {
+	for _, sc := range Scoreboard().Scenarios {
+		if slices.Contains(sc.Models, model) && len(sc.Out) == 1 {
+			for m := range sc.Out {
+				return genai.Modalities{genai.Modality(m)}
+			}
+		}
+	}
+	switch {
+	case strings.HasPrefix(model, "imagen"):
+		return genai.Modalities{genai.ModalityImage}
+	case strings.HasPrefix(model, "veo"):
+		return genai.Modalities{genai.ModalityVideo}
+	default:
+		return genai.Modalities{genai.ModalityText}
+	}
+}
+
+// modelURL returns the URL for a method on the selected model.
+func (c *Client) modelURL(method string) string {
+	return c.baseURL + "/" + url.PathEscape(c.impl.Model) + method
+}
+
+// Name implements genai.Provider.
+//
+// It returns the name of the provider.
+func (c *Client) Name() string {
+	return "vertexai"
+}
+
+// ModelID implements genai.Provider.
+//
+// It returns the selected model ID.
+func (c *Client) ModelID() string {
+	return c.impl.Model
+}
+
+// OutputModalities implements genai.Provider.
+//
+// It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
+// video, etc).
+func (c *Client) OutputModalities() genai.Modalities {
+	return c.impl.OutputModalities
+}
+
+// Scoreboard implements genai.Provider.
+func (c *Client) Scoreboard() scoreboard.Score {
+	return Scoreboard()
+}
+
+// HTTPClient returns the HTTP client to fetch results (e.g. videos) generated by the provider.
+func (c *Client) HTTPClient() *http.Client {
+	return &c.impl.Client
+}
+
+// GenSync implements genai.Provider.
+func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	if !slices.Contains(c.impl.OutputModalities, genai.ModalityText) {
+		ctx, opts = base.ExtractRawOptions(ctx, opts)
+		if len(msgs) != 1 {
+			return genai.Result{}, errors.New("must pass exactly one Message")
+		}
+		return c.genDoc(ctx, &msgs[0], opts...)
+	}
+	return c.impl.GenSync(ctx, msgs, opts...)
+}
+
+// GenSyncRaw provides access to the raw API.
+//
+// It calls the Vertex AI method generateContent.
+func (c *Client) GenSyncRaw(ctx context.Context, in *gemini.ChatRequest, out *gemini.ChatResponse) error {
+	return c.impl.GenSyncRaw(ctx, in, out)
+}
+
+// GenStream implements genai.Provider.
+func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	if !slices.Contains(c.impl.OutputModalities, genai.ModalityText) {
+		return base.SimulateStream(ctx, c, msgs, opts...)
+	}
+	return c.impl.GenStream(ctx, msgs, opts...)
+}
+
+// GenStreamRaw provides access to the raw API.
+//
+// It calls the Vertex AI method streamGenerateContent?alt=sse.
+func (c *Client) GenStreamRaw(ctx context.Context, in *gemini.ChatRequest) (iter.Seq[gemini.ChatStreamChunkResponse], func() error) {
+	return c.impl.GenStreamRaw(ctx, in)
+}
+
+func (c *Client) genDoc(ctx context.Context, msg *genai.Message, opts ...genai.GenOption) (genai.Result, error) {
+	if slices.Contains(c.impl.OutputModalities, genai.ModalityVideo) {
+		waitForPoll, progress, filtered := base.ExtractPollOptions(opts, time.Second)
+		id, err := c.GenAsync(ctx, genai.Messages{*msg}, filtered...)
+		if err != nil {
+			return genai.Result{}, err
+		}
+		return base.PollJob(ctx, id, waitForPoll, progress, func(ctx context.Context, id genai.Job) (genai.Result, map[string]any, error) {
+			res, err := c.PokeResult(ctx, id)
+			return res, nil, err
+		})
+	}
+	res := genai.Result{}
+	req := gemini.ImageRequest{}
+	if err := req.InitVertex(msg, c.impl.Model, c.impl.OutputModalities, opts...); err != nil {
+		return res, err
+	}
+	resp, err := c.PredictRaw(ctx, &req)
+	if err != nil {
+		return res, err
+	}
+	var imgs []int
+	for i := range resp.Predictions {
+		if len(resp.Predictions[i].BytesBase64Encoded) > 0 {
+			imgs = append(imgs, i)
+		}
+	}
+	for n, i := range imgs {
+		ext := ""
+		switch resp.Predictions[i].MimeType {
+		case "image/jpeg":
+			ext = ".jpg"
+		case "image/png":
+			ext = ".png"
+		default:
+			return res, fmt.Errorf("unsupported mime type %q", resp.Predictions[i].MimeType)
+		}
+		name := "content" + ext
+		if len(imgs) > 1 {
+			name = fmt.Sprintf("content%d%s", n+1, ext)
+		}
+		res.Replies = append(res.Replies, genai.Reply{Doc: genai.Doc{Filename: name, Src: &bb.BytesBuffer{D: resp.Predictions[i].BytesBase64Encoded}}})
+	}
+	if err := res.Validate(); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GenAsync implements genai.ProviderGenAsync.
+//
+// It requests the providers' asynchronous API and returns the job ID. It is only supported for video
+// generation models.
+func (c *Client) GenAsync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Job, error) {
+	if !slices.Contains(c.impl.OutputModalities, genai.ModalityVideo) {
+		return "", &base.ErrNotSupported{}
+	}
+	if err := c.impl.Validate(); err != nil {
+		return "", err
+	}
+	if len(msgs) != 1 {
+		return "", errors.New("only one message can be passed as input")
+	}
+	req := gemini.ImageRequest{}
+	if err := req.InitVertex(&msgs[0], c.impl.Model, c.impl.OutputModalities, opts...); err != nil {
+		return "", err
+	}
+	resp, err := c.PredictLongRunningRaw(ctx, &req)
+	return genai.Job(resp.Name), err
+}
+
+// PokeResult implements genai.ProviderGenAsync.
+//
+// It retrieves the result for a job ID.
+func (c *Client) PokeResult(ctx context.Context, id genai.Job) (genai.Result, error) {
+	res := genai.Result{}
+	op, err := c.PokeResultRaw(ctx, id)
+	if err != nil {
+		return res, err
+	}
+	if !op.Done {
+		res.Usage.FinishReason = genai.Pending
+		return res, nil
+	}
+	if op.Error.Code != 0 {
+		return res, fmt.Errorf("job %q failed: %s", id, op.Error.Message)
+	}
+	res.Usage.FinishReason = genai.FinishedStop
+	for i, v := range op.Response.Videos {
+		name := "content.mp4"
+		if len(op.Response.Videos) > 1 {
+			name = fmt.Sprintf("content%d.mp4", i+1)
+		}
+		if len(v.BytesBase64Encoded) != 0 {
+			res.Replies = append(res.Replies, genai.Reply{Doc: genai.Doc{Filename: name, Src: &bb.BytesBuffer{D: v.BytesBase64Encoded}}})
+		} else {
+			// gs:// URLs must be fetched with the Cloud Storage API.
+			res.Replies = append(res.Replies, genai.Reply{Doc: genai.Doc{Filename: name, URL: v.GCSURI}})
+		}
+	}
+	if len(res.Replies) == 0 && op.Response.RAIMediaFilteredCount != 0 {
+		res.Usage.FinishReason = genai.FinishedContentFilter
+	}
+	return res, nil
+}
+
+// PredictRaw requests the providers' synchronous API to generate an image.
+//
+// https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/imagen-api
+func (c *Client) PredictRaw(ctx context.Context, req *gemini.ImageRequest) (gemini.ImageResponse, error) {
+	res := gemini.ImageResponse{}
+	if err := c.impl.Validate(); err != nil {
+		return res, err
+	}
+	err := c.impl.DoRequest(ctx, "POST", c.modelURL(":predict"), req, &res)
+	return res, err
+}
+
+// PredictLongRunningRaw requests the providers' asynchronous API to generate a video.
+//
+// https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/veo-video-generation
+func (c *Client) PredictLongRunningRaw(ctx context.Context, req *gemini.ImageRequest) (VideoOperation, error) {
+	res := VideoOperation{}
+	if err := c.impl.Validate(); err != nil {
+		return res, err
+	}
+	err := c.impl.DoRequest(ctx, "POST", c.modelURL(":predictLongRunning"), req, &res)
+	return res, err
+}
+
+// PokeResultRaw retrieves the result for a job ID if already available.
+func (c *Client) PokeResultRaw(ctx context.Context, id genai.Job) (VideoOperation, error) {
+	res := VideoOperation{}
+	if err := c.impl.Validate(); err != nil {
+		return res, err
+	}
+	in := FetchPredictOperationRequest{OperationName: string(id)}
+	if err := c.impl.DoRequest(ctx, "POST", c.modelURL(":fetchPredictOperation"), &in, &res); err != nil {
+		return res, fmt.Errorf("failed to get job %q: %w", id, err)
+	}
+	return res, nil
+}
+
+// CountTokens counts the number of tokens in the given messages.
+//
+// https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/count-tokens
+func (c *Client) CountTokens(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (*gemini.CountTokensResponse, error) {
+	if err := c.impl.Validate(); err != nil {
+		return nil, err
+	}
+	var req gemini.ChatRequest
+	if err := req.Init(msgs, c.impl.Model, opts...); err != nil {
+		return nil, err
+	}
+	var resp gemini.CountTokensResponse
+	if err := c.impl.DoRequest(ctx, "POST", c.modelURL(":countTokens"), &req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListModels implements genai.Provider.
+//
+// It lists the Google publisher models.
+func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
+	if c.impl.PreloadedModels != nil {
+		return c.impl.PreloadedModels, nil
+	}
+	// The list is only available in v1beta1 and is not scoped to a project.
+	// https://cloud.google.com/vertex-ai/docs/reference/rest/v1beta1/publishers.models/list
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	listURL := u.Scheme + "://" + u.Host + "/v1beta1/publishers/google/models?pageSize=1000"
+	var out []genai.Model
+	for token := ""; ; {
+		var resp PublisherModelsResponse
+		q := listURL
+		if token != "" {
+			q += "&pageToken=" + url.QueryEscape(token)
+		}
+		if err := c.impl.DoRequest(ctx, "GET", q, nil, &resp); err != nil {
+			return nil, err
+		}
+		out = append(out, resp.ToModels()...)
+		if token = resp.NextPageToken; token == "" {
+			return out, nil
+		}
+	}
+}
+
+// Capabilities implements genai.Provider.
+func (c *Client) Capabilities() genai.ProviderCapabilities {
+	return genai.ProviderCapabilities{
+		GenAsync: slices.Contains(c.impl.OutputModalities, genai.ModalityVideo),
+	}
+}
+
+var _ genai.Provider = &Client{}
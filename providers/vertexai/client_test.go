@@ -0,0 +1,236 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the Vertex AI client.
+
+package vertexai_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/providers/vertexai"
+)
+
+func jsonResponse(r *http.Request, body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}
+}
+
+func TestScoreboard(t *testing.T) {
+	s := vertexai.Scoreboard()
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	const reply = `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":2,"candidatesTokenCount":1,"totalTokenCount":3}}`
+	tests := []struct {
+		name    string
+		opts    []genai.ProviderOption
+		wantURL string
+		check   func(t *testing.T, r *http.Request)
+	}{
+		{
+			name: "regional",
+			opts: []genai.ProviderOption{
+				vertexai.ProviderOptionProject("proj"),
+				vertexai.ProviderOptionLocation("us-central1"),
+				vertexai.ProviderOptionTokenSource(func(ctx context.Context) (vertexai.Token, error) {
+					return vertexai.Token{AccessToken: "tok", Expiry: time.Now().Add(time.Hour)}, nil
+				}),
+			},
+			wantURL: "https://us-central1-aiplatform.googleapis.com/v1/projects/proj/locations/us-central1/publishers/google/models/gemini-2.5-flash:generateContent",
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+					t.Errorf("unexpected Authorization %q", got)
+				}
+			},
+		},
+		{
+			name: "global",
+			opts: []genai.ProviderOption{
+				vertexai.ProviderOptionProject("proj"),
+				vertexai.ProviderOptionLocation("global"),
+				vertexai.ProviderOptionTokenSource(func(ctx context.Context) (vertexai.Token, error) {
+					return vertexai.Token{AccessToken: "tok"}, nil
+				}),
+			},
+			wantURL: "https://aiplatform.googleapis.com/v1/projects/proj/locations/global/publishers/google/models/gemini-2.5-flash:generateContent",
+		},
+		{
+			name:    "express",
+			opts:    []genai.ProviderOption{genai.ProviderOptionAPIKey("key")},
+			wantURL: "https://aiplatform.googleapis.com/v1/publishers/google/models/gemini-2.5-flash:generateContent",
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("x-goog-api-key"); got != "key" {
+					t.Errorf("unexpected x-goog-api-key %q", got)
+				}
+				if got := r.Header.Get("Authorization"); got != "" {
+					t.Errorf("unexpected Authorization %q", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapper := genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+				return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					if got := r.URL.String(); got != tt.wantURL {
+						t.Errorf("unexpected URL\nwant %s\ngot  %s", tt.wantURL, got)
+					}
+					if tt.check != nil {
+						tt.check(t, r)
+					}
+					return jsonResponse(r, reply), nil
+				})
+			})
+			opts := append([]genai.ProviderOption{genai.ProviderOptionModel("gemini-2.5-flash"), wrapper}, tt.opts...)
+			c, err := vertexai.New(t.Context(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Hello")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := res.String(); got != "Hi" {
+				t.Fatalf("unexpected reply %q", got)
+			}
+		})
+	}
+	t.Run("ModelGood", func(t *testing.T) {
+		c, err := vertexai.New(t.Context(), genai.ModelGood, genai.ProviderOptionModalities{genai.ModalityImage}, genai.ProviderOptionAPIKey("key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.ModelID(); got != "imagen-4.0-generate-001" {
+			t.Fatalf("unexpected model %q", got)
+		}
+	})
+	t.Run("token caching", func(t *testing.T) {
+		var calls atomic.Int32
+		ts := vertexai.ProviderOptionTokenSource(func(ctx context.Context) (vertexai.Token, error) {
+			calls.Add(1)
+			return vertexai.Token{AccessToken: "tok", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		wrapper := genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+			return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return jsonResponse(r, reply), nil
+			})
+		})
+		c, err := vertexai.New(t.Context(), genai.ProviderOptionModel("gemini-2.5-flash"), vertexai.ProviderOptionProject("proj"), ts, wrapper)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range 2 {
+			if _, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Hello")}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if n := calls.Load(); n != 1 {
+			t.Fatalf("got %d token requests, want 1", n)
+		}
+	})
+}
+
+func TestCredentialsFromJSON(t *testing.T) {
+	t.Run("service_account", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(map[string]string{
+			"type":           "service_account",
+			"project_id":     "proj",
+			"private_key_id": "kid",
+			"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+			"client_email":   "sa@proj.iam.gserviceaccount.com",
+			"token_uri":      "https://oauth2.example.com/token",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &http.Client{Transport: internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if got := r.URL.String(); got != "https://oauth2.example.com/token" {
+				t.Errorf("unexpected URL %s", got)
+			}
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+			if got := r.PostForm.Get("grant_type"); got != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+				t.Errorf("unexpected grant_type %q", got)
+			}
+			if got := strings.Count(r.PostForm.Get("assertion"), "."); got != 2 {
+				t.Errorf("unexpected assertion %q", r.PostForm.Get("assertion"))
+			}
+			return jsonResponse(r, `{"access_token":"tok","expires_in":3600,"token_type":"Bearer"}`), nil
+		})}
+		creds, err := vertexai.CredentialsFromJSON(b, client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creds.ProjectID != "proj" {
+			t.Errorf("unexpected project %q", creds.ProjectID)
+		}
+		tok, err := creds.TokenSource(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.AccessToken != "tok" || tok.Expiry.IsZero() {
+			t.Errorf("unexpected token %+v", tok)
+		}
+	})
+	t.Run("authorized_user", func(t *testing.T) {
+		b := []byte(`{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"refresh","quota_project_id":"proj"}`)
+		client := &http.Client{Transport: internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if got := r.URL.String(); got != "https://oauth2.googleapis.com/token" {
+				t.Errorf("unexpected URL %s", got)
+			}
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+			if got := r.PostForm.Get("refresh_token"); got != "refresh" {
+				t.Errorf("unexpected refresh_token %q", got)
+			}
+			return jsonResponse(r, `{"access_token":"tok","expires_in":3600}`), nil
+		})}
+		creds, err := vertexai.CredentialsFromJSON(b, client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creds.ProjectID != "proj" {
+			t.Errorf("unexpected project %q", creds.ProjectID)
+		}
+		if tok, err := creds.TokenSource(t.Context()); err != nil || tok.AccessToken != "tok" {
+			t.Fatalf("unexpected token %+v, %v", tok, err)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		if _, err := vertexai.CredentialsFromJSON([]byte(`{"type":"external_account"}`), nil); err == nil || err.Error() != `unsupported credentials type "external_account"` {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
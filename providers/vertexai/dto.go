@@ -0,0 +1,92 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Wire types for the Vertex AI REST API that differ from the Gemini API.
+//
+// API reference: https://cloud.google.com/vertex-ai/generative-ai/docs/reference/rest
+
+package vertexai
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/providers/gemini"
+)
+
+// PublisherModel is documented at
+// https://cloud.google.com/vertex-ai/docs/reference/rest/v1beta1/publishers.models#PublisherModel
+type PublisherModel struct {
+	Name                   string `json:"name"` // "publishers/google/models/gemini-2.5-flash"
+	VersionID              string `json:"versionId,omitzero"`
+	OpenSourceCategory     string `json:"openSourceCategory,omitzero"`
+	LaunchStage            string `json:"launchStage,omitzero"` // "GA", "PUBLIC_PREVIEW", "EXPERIMENTAL"
+	VersionState           string `json:"versionState,omitzero"`
+	PublisherModelTemplate string `json:"publisherModelTemplate,omitzero"`
+}
+
+// GetID implements genai.Model.
+func (m *PublisherModel) GetID() string {
+	return path.Base(m.Name)
+}
+
+func (m *PublisherModel) String() string {
+	if m.LaunchStage == "" {
+		return m.GetID()
+	}
+	return fmt.Sprintf("%s (%s)", m.GetID(), m.LaunchStage)
+}
+
+// Context implements genai.Model.
+//
+// The value is not reported by the API.
+func (m *PublisherModel) Context() int64 {
+	return 0
+}
+
+// PublisherModelsResponse is documented at
+// https://cloud.google.com/vertex-ai/docs/reference/rest/v1beta1/publishers.models/list
+type PublisherModelsResponse struct {
+	PublisherModels []PublisherModel `json:"publisherModels"`
+	NextPageToken   string           `json:"nextPageToken"`
+}
+
+// ToModels converts the models to genai.Model interfaces.
+func (r *PublisherModelsResponse) ToModels() []genai.Model {
+	models := make([]genai.Model, len(r.PublisherModels))
+	for i := range r.PublisherModels {
+		models[i] = &r.PublisherModels[i]
+	}
+	return models
+}
+
+// FetchPredictOperationRequest is documented at
+// https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/veo-video-generation#poll
+type FetchPredictOperationRequest struct {
+	OperationName string `json:"operationName"`
+}
+
+// VideoOperation is the long running operation returned by Veo on Vertex AI.
+//
+// It differs from gemini.Operation.
+type VideoOperation struct {
+	Name     string        `json:"name"`
+	Done     bool          `json:"done,omitzero"`
+	Error    gemini.Status `json:"error,omitzero"`
+	Response struct {
+		Type                    string   `json:"@type,omitzero"`
+		RAIMediaFilteredCount   int64    `json:"raiMediaFilteredCount,omitzero"`
+		RAIMediaFilteredReasons []string `json:"raiMediaFilteredReasons,omitzero"`
+		Videos                  []Video  `json:"videos,omitzero"`
+	} `json:"response,omitzero"`
+}
+
+// Video is a generated video. Either GCSURI or BytesBase64Encoded is set, depending on whether storageUri
+// was specified in the request.
+type Video struct {
+	GCSURI             string `json:"gcsUri,omitzero"`
+	BytesBase64Encoded []byte `json:"bytesBase64Encoded,omitzero"`
+	MimeType           string `json:"mimeType,omitzero"`
+}
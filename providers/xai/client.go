@@ -0,0 +1,331 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package xai implements a client for the xAI API, serving the Grok models.
+//
+// It is described at https://docs.x.ai/docs/api-reference
+package xai
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/maruel/roundtrippers"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/scoreboard"
+)
+
+//go:embed scoreboard.json
+var scoreboardJSON []byte
+
+// Scoreboard for xAI.
+func Scoreboard() scoreboard.Score {
+	var s scoreboard.Score
+	d := json.NewDecoder(bytes.NewReader(scoreboardJSON))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&s); err != nil {
+		panic(fmt.Errorf("failed to unmarshal scoreboard.json: %w", err))
+	}
+	return s
+}
+
+// Client implements genai.Provider.
+type Client struct {
+	base.NotImplemented
+	impl base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]
+}
+
+// New creates a new client to talk to the xAI platform API.
+//
+// If ProviderOptionAPIKey is not provided, it tries to load it from the XAI_API_KEY environment variable.
+// If none is found, it will still return a client coupled with an base.ErrAPIKeyRequired error.
+// Get your API key at https://console.x.ai/
+//
+// To use multiple models, create multiple clients.
+// Use one of the model from https://docs.x.ai/docs/models
+//
+// Live Search is enabled with genai.GenOptionWeb or GenOption.Search. Its sources are returned as citations.
+//
+// The proxy defaults to the XAI_PROXY environment variable, see genai.ProviderOptionProxyURL.
+func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
+	var apiKey, model string
+	var modalities genai.Modalities
+	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
+	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
+	if err := base.CheckDuplicateOptions(opts); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return nil, err
+		}
+		switch v := opt.(type) {
+		case genai.ProviderOptionAPIKey:
+			apiKey = string(v)
+		case genai.ProviderOptionModel:
+			model = string(v)
+		case genai.ProviderOptionModalities:
+			modalities = genai.Modalities(v)
+		case genai.ProviderOptionPreloadedModels:
+			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
+		case genai.ProviderOptionTransportWrapper:
+			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
+		default:
+			return nil, fmt.Errorf("unsupported option type %T", opt)
+		}
+	}
+	const apiKeyURL = "https://console.x.ai/"
+	var err error
+	if apiKey == "" {
+		if apiKey = os.Getenv("XAI_API_KEY"); apiKey == "" {
+			err = &base.ErrAPIKeyRequired{EnvVar: "XAI_API_KEY", URL: apiKeyURL}
+		}
+	}
+	mod := genai.Modalities{genai.ModalityText}
+	if len(modalities) != 0 && !slices.Equal(modalities, mod) {
+		return nil, fmt.Errorf("unexpected option Modalities %s, only text is supported", mod)
+	}
+	t := base.NewTransport(httpOpts, proxyURL, "XAI_PROXY")
+	if wrapper != nil {
+		t = wrapper(t)
+	}
+	c := &Client{
+		impl: base.Provider[*ErrorResponse, *ChatRequest, *ChatResponse, ChatStreamChunkResponse]{
+			GenSyncURL:      "https://api.x.ai/v1/chat/completions",
+			ProcessStream:   ProcessStream,
+			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
+			ProviderBase: base.ProviderBase[*ErrorResponse]{
+				APIKeyURL: apiKeyURL,
+				Lenient:   lenient,
+				Log:       logger,
+				Client: http.Client{
+					Transport: &roundtrippers.Header{
+						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
+						Transport: &roundtrippers.RequestID{Transport: t},
+					},
+				},
+			},
+		},
+	}
+	if err == nil {
+		switch model {
+		case "":
+		case string(genai.ModelCheap), string(genai.ModelGood), string(genai.ModelSOTA):
+			if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
+				return nil, err
+			}
+			c.impl.OutputModalities = mod
+		default:
+			c.impl.Model = model
+			c.impl.OutputModalities = mod
+		}
+	}
+	return c, err
+}
+
+// selectBestTextModel selects the most appropriate model based on the preference (cheap, good, or SOTA).
+//
+// genai.ProviderOptionModelSelector overrides it since this is going to break one day or another.
+func (c *Client) selectBestTextModel(ctx context.Context, preference string) (string, error) {
+	mdls, err := c.ListModels(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to automatically select the model: %w", err)
+	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
+	var want string
+	switch preference {
+	case string(genai.ModelCheap):
+		want = "grok-3-mini"
+	case string(genai.ModelGood):
+		want = "grok-4-fast-non-reasoning"
+	default:
+		want = "grok-4-0709"
+	}
+	for _, mdl := range mdls {
+		if m := mdl.(*Model); m.ID == want || slices.Contains(m.Aliases, want) {
+			return want, nil
+		}
+	}
+	return "", errors.New("failed to find a model automatically")
+}
+
+// Name implements genai.Provider.
+//
+// It returns the name of the provider.
+func (c *Client) Name() string {
+	return "xai"
+}
+
+// ModelID implements genai.Provider.
+//
+// It returns the selected model ID.
+func (c *Client) ModelID() string {
+	return c.impl.Model
+}
+
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
+// OutputModalities implements genai.Provider.
+//
+// It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
+// video, etc).
+func (c *Client) OutputModalities() genai.Modalities {
+	return c.impl.OutputModalities
+}
+
+// Scoreboard implements genai.Provider.
+func (c *Client) Scoreboard() scoreboard.Score {
+	return Scoreboard()
+}
+
+// HTTPClient returns the HTTP client to fetch results (e.g. videos) generated by the provider.
+func (c *Client) HTTPClient() *http.Client {
+	return &c.impl.Client
+}
+
+// GenSync implements genai.Provider.
+func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	return c.impl.GenSync(ctx, msgs, opts...)
+}
+
+// GenSyncRaw provides access to the raw API.
+func (c *Client) GenSyncRaw(ctx context.Context, in *ChatRequest, out *ChatResponse) error {
+	return c.impl.GenSyncRaw(ctx, in, out)
+}
+
+// GenStream implements genai.Provider.
+func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	return c.impl.GenStream(ctx, msgs, opts...)
+}
+
+// GenStreamRaw provides access to the raw API.
+func (c *Client) GenStreamRaw(ctx context.Context, in *ChatRequest) (iter.Seq[ChatStreamChunkResponse], func() error) {
+	return c.impl.GenStreamRaw(ctx, in)
+}
+
+// ListModels implements genai.Provider.
+func (c *Client) ListModels(ctx context.Context) ([]genai.Model, error) {
+	if c.impl.PreloadedModels != nil {
+		return c.impl.PreloadedModels, nil
+	}
+	// https://docs.x.ai/docs/api-reference#list-language-models
+	var resp ModelsResponse
+	if err := c.impl.DoRequest(ctx, "GET", "https://api.x.ai/v1/language-models", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.ToModels(), nil
+}
+
+// ProcessStream converts the raw packets from the streaming API into Reply fragments.
+//
+// xAI sends each tool call whole in a single chunk, and the Live Search citations in the last chunk.
+func ProcessStream(chunks iter.Seq[ChatStreamChunkResponse]) (iter.Seq[genai.Reply], func() (genai.Usage, [][]genai.Logprob, error)) {
+	var finalErr error
+	u := genai.Usage{}
+	var l [][]genai.Logprob
+
+	return func(yield func(genai.Reply) bool) {
+			for pkt := range chunks {
+				if pkt.Usage.TotalTokens != 0 {
+					f := u.FinishReason
+					u = pkt.Usage.To()
+					u.FinishReason = f
+				}
+				if len(pkt.Citations) != 0 {
+					if !yield(genai.Reply{Citation: citationsTo(pkt.Citations)}) {
+						return
+					}
+				}
+				if len(pkt.Choices) != 1 {
+					continue
+				}
+				if r := pkt.Choices[0].FinishReason; r != "" {
+					var err error
+					if u.FinishReason, err = r.ToFinishReason(); err != nil {
+						finalErr = errors.Join(finalErr, err)
+					}
+				}
+				if len(pkt.Choices[0].Logprobs.Content) != 0 {
+					l = append(l, pkt.Choices[0].Logprobs.To()...)
+				}
+				switch role := pkt.Choices[0].Delta.Role; role {
+				case "assistant", "":
+				default:
+					finalErr = &internal.BadError{Err: fmt.Errorf("unexpected role %q", role)}
+					return
+				}
+				if s := pkt.Choices[0].Delta.ReasoningContent; s != "" {
+					if !yield(genai.Reply{Reasoning: s}) {
+						return
+					}
+				}
+				for _, c := range pkt.Choices[0].Delta.Content {
+					switch c.Type {
+					case ContentText:
+						if !yield(genai.Reply{Text: c.Text}) {
+							return
+						}
+					default:
+						finalErr = &internal.BadError{Err: fmt.Errorf("implement content type %q", c.Type)}
+						return
+					}
+				}
+				if s := pkt.Choices[0].Delta.Refusal; s != "" {
+					if !yield(genai.Reply{Text: s}) {
+						return
+					}
+				}
+				for _, t := range pkt.Choices[0].Delta.ToolCalls {
+					f := genai.Reply{}
+					t.To(&f.ToolCall)
+					if !yield(f) {
+						return
+					}
+				}
+			}
+		}, func() (genai.Usage, [][]genai.Logprob, error) {
+			return u, l, finalErr
+		}
+}
+
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+)
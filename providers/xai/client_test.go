@@ -0,0 +1,215 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the xAI provider client.
+
+package xai_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/internal/internaltest"
+	"github.com/maruel/genai/providers/xai"
+)
+
+func TestScoreboard(t *testing.T) {
+	s := xai.Scoreboard()
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	newClient := func(t *testing.T, model string, h func(r *http.Request) *http.Response) *xai.Client {
+		wrapper := genai.ProviderOptionTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+			return internaltest.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				resp := h(r)
+				resp.Request = r
+				return resp, nil
+			})
+		})
+		opts := []genai.ProviderOption{genai.ProviderOptionAPIKey("key"), wrapper}
+		if model != "" {
+			opts = append(opts, genai.ProviderOptionModel(model))
+		}
+		c, err := xai.New(t.Context(), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	t.Run("GenSync", func(t *testing.T) {
+		c := newClient(t, "grok-4-0709", func(r *http.Request) *http.Response {
+			if got := r.URL.String(); got != "https://api.x.ai/v1/chat/completions" {
+				t.Errorf("unexpected URL %s", got)
+			}
+			if got := r.Header.Get("Authorization"); got != "Bearer key" {
+				t.Errorf("unexpected Authorization %q", got)
+			}
+			var in xai.ChatRequest
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				t.Error(err)
+			}
+			if len(in.Messages) != 1 || len(in.Messages[0].Content) != 2 {
+				t.Fatalf("unexpected request %+v", in)
+			}
+			if c := in.Messages[0].Content[1]; c.Type != xai.ContentImageURL || !strings.HasPrefix(c.ImageURL.URL, "data:image/png;base64,") {
+				t.Errorf("unexpected image content %+v", c)
+			}
+			if s := in.SearchParameters; s.Mode != xai.SearchOn || !s.ReturnCitations {
+				t.Errorf("unexpected search parameters %+v", s)
+			}
+			return jsonResponse(http.StatusOK, `{"id":"1","object":"chat.completion","created":1752000000,"model":"grok-4-0709","choices":[{"index":0,"message":{"role":"assistant","content":"A cat."},"finish_reason":"stop"}],"usage":{"prompt_tokens":300,"completion_tokens":3,"total_tokens":400,"prompt_tokens_details":{"text_tokens":44,"image_tokens":256,"cached_tokens":5},"completion_tokens_details":{"reasoning_tokens":97},"num_sources_used":2},"system_fingerprint":"fp","citations":["https://example.com/a","https://example.com/b"]}`)
+		})
+		msgs := genai.Messages{{Requests: []genai.Request{
+			{Text: "What is it?"},
+			{Doc: genai.Doc{Filename: "cat.png", Src: strings.NewReader("\x89PNG")}},
+		}}}
+		res, err := c.GenSync(t.Context(), msgs, &genai.GenOptionWeb{Search: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Replies) != 2 || res.Replies[0].Text != "A cat." {
+			t.Fatalf("unexpected replies %+v", res.Replies)
+		}
+		if src := res.Replies[1].Citation.Sources; len(src) != 2 || src[0].Type != genai.CitationWeb || src[1].URL != "https://example.com/b" {
+			t.Errorf("unexpected citation %+v", res.Replies[1].Citation)
+		}
+		if u := res.Usage; u.InputTokens != 300 || u.InputCachedTokens != 5 || u.ReasoningTokens != 97 || u.OutputTokens != 3 || u.FinishReason != genai.FinishedStop {
+			t.Errorf("unexpected usage %+v", u)
+		}
+	})
+
+	t.Run("GenStream", func(t *testing.T) {
+		chunks := []string{
+			`{"id":"1","object":"chat.completion.chunk","created":1752000000,"model":"grok-3-mini","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"Think."}}],"system_fingerprint":"fp"}`,
+			`{"id":"1","object":"chat.completion.chunk","created":1752000000,"model":"grok-3-mini","choices":[{"index":0,"delta":{"content":"Let me "}}],"system_fingerprint":"fp"}`,
+			`{"id":"1","object":"chat.completion.chunk","created":1752000000,"model":"grok-3-mini","choices":[{"index":0,"delta":{"content":"check."}}],"system_fingerprint":"fp"}`,
+			`{"id":"1","object":"chat.completion.chunk","created":1752000000,"model":"grok-3-mini","choices":[{"index":0,"delta":{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"square_root","arguments":"{\"number\":132413}"}}]},"finish_reason":"tool_calls"}],"system_fingerprint":"fp"}`,
+			`{"id":"1","object":"chat.completion.chunk","created":1752000000,"model":"grok-3-mini","choices":[],"usage":{"prompt_tokens":20,"completion_tokens":10,"total_tokens":50,"completion_tokens_details":{"reasoning_tokens":20}},"system_fingerprint":"fp","citations":["https://example.com/a"]}`,
+		}
+		var body strings.Builder
+		for _, c := range chunks {
+			body.WriteString("data: " + c + "\n\n")
+		}
+		body.WriteString("data: [DONE]\n\n")
+		c := newClient(t, "grok-3-mini", func(r *http.Request) *http.Response {
+			var in xai.ChatRequest
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				t.Error(err)
+			}
+			if !in.Stream || !in.StreamOptions.IncludeUsage || in.ReasoningEffort != xai.ReasoningEffortLow {
+				t.Errorf("unexpected request %+v", in)
+			}
+			if s := in.SearchParameters; s.Mode != xai.SearchAuto || len(s.Sources) != 1 || s.Sources[0].Type != "news" {
+				t.Errorf("unexpected search parameters %+v", s)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"text/event-stream"}},
+				Body:       io.NopCloser(strings.NewReader(body.String())),
+			}
+		})
+		opts := &xai.GenOption{
+			ReasoningEffort: xai.ReasoningEffortLow,
+			Search:          xai.SearchParameters{Mode: xai.SearchAuto, Sources: []xai.SearchSource{{Type: "news", Country: "CA"}}},
+		}
+		fragments, finish := c.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("Hello")}, opts, &genai.GenOptionWeb{Search: true})
+		for range fragments {
+		}
+		res, err := finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Replies) != 4 || res.Replies[0].Reasoning != "Think." || res.Replies[1].Text != "Let me check." {
+			t.Fatalf("unexpected replies %+v", res.Replies)
+		}
+		want := genai.ToolCall{ID: "call_1", Name: "square_root", Arguments: `{"number":132413}`}
+		if got := res.Replies[2].ToolCall; got.ID != want.ID || got.Name != want.Name || got.Arguments != want.Arguments {
+			t.Errorf("unexpected tool call %+v", got)
+		}
+		if src := res.Replies[3].Citation.Sources; len(src) != 1 || src[0].URL != "https://example.com/a" {
+			t.Errorf("unexpected citation %+v", res.Replies[3].Citation)
+		}
+		if u := res.Usage; u.InputTokens != 20 || u.OutputTokens != 10 || u.ReasoningTokens != 20 || u.FinishReason != genai.FinishedToolCalls {
+			t.Errorf("unexpected usage %+v", u)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c := newClient(t, "grok-4-0709", func(r *http.Request) *http.Response {
+			return jsonResponse(http.StatusBadRequest, `{"code":"Client specified an invalid argument","error":"Incorrect API key provided: ke***ey."}`)
+		})
+		_, err := c.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("Hello")})
+		if err == nil || !strings.Contains(err.Error(), "Client specified an invalid argument: Incorrect API key provided") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ListModels", func(t *testing.T) {
+		c := newClient(t, "", func(r *http.Request) *http.Response {
+			if got := r.URL.String(); got != "https://api.x.ai/v1/language-models" {
+				t.Errorf("unexpected URL %s", got)
+			}
+			return jsonResponse(http.StatusOK, `{"models":[{"id":"grok-3-mini","fingerprint":"fp","created":1743724800,"object":"model","owned_by":"xai","version":"1.0","input_modalities":["text"],"output_modalities":["text"],"prompt_text_token_price":3000,"cached_prompt_text_token_price":750,"prompt_image_token_price":0,"completion_text_token_price":5000,"search_price":250000000,"aliases":["grok-3-mini-latest"],"max_prompt_length":131072}]}`)
+		})
+		models, err := c.ListModels(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(models) != 1 || models[0].GetID() != "grok-3-mini" || models[0].Context() != 131072 {
+			t.Fatalf("unexpected models %v", models)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("XAI_API_KEY", "")
+	if _, err := xai.New(t.Context()); err == nil || !strings.Contains(err.Error(), "XAI_API_KEY") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	preloaded := genai.ProviderOptionPreloadedModels{&xai.Model{ID: "grok-3-mini"}}
+	c, err := xai.New(t.Context(), genai.ProviderOptionAPIKey("key"), genai.ProviderOptionModel(string(genai.ModelCheap)), preloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.ModelID(); got != "grok-3-mini" {
+		t.Fatalf("unexpected model %q", got)
+	}
+}
+
+func TestGenOption(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     xai.GenOption
+		errMsg string
+	}{
+		{"ReasoningEffort", xai.GenOption{ReasoningEffort: "max"}, `invalid ReasoningEffort "max"`},
+		{"Mode", xai.GenOption{Search: xai.SearchParameters{Mode: "always"}}, `field Search: invalid Mode "always"`},
+		{"FromDate", xai.GenOption{Search: xai.SearchParameters{FromDate: "2025/01/01"}}, `field Search: field FromDate: must be formatted as YYYY-MM-DD: "2025/01/01"`},
+		{"Source", xai.GenOption{Search: xai.SearchParameters{Sources: []xai.SearchSource{{Type: "tv"}}}}, `field Search: source #0: invalid Type "tv"`},
+		{"Websites", xai.GenOption{Search: xai.SearchParameters{Sources: []xai.SearchSource{{Type: "web", AllowedWebsites: []string{"a"}, ExcludedWebsites: []string{"b"}}}}}, "field Search: source #0: field AllowedWebsites: cannot be used with ExcludedWebsites"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.in.Validate(); err == nil || err.Error() != tt.errMsg {
+				t.Fatalf("error mismatch\nwant %q\ngot  %q", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
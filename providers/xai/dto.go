@@ -0,0 +1,823 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Wire types for the xAI chat completions REST API.
+//
+// Reference: https://docs.x.ai/docs/api-reference
+
+package xai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/internal"
+)
+
+// GenOption is the xAI-specific options.
+type GenOption struct {
+	// ReasoningEffort is only supported by grok-3-mini. Other reasoning models always reason and reject this
+	// parameter.
+	ReasoningEffort ReasoningEffort
+	// Search enables Live Search with fine grained control. It takes precedence over genai.GenOptionWeb.
+	//
+	// See https://docs.x.ai/docs/guides/live-search
+	Search SearchParameters
+}
+
+// Validate implements genai.Validatable.
+func (o *GenOption) Validate() error {
+	switch o.ReasoningEffort {
+	case "", ReasoningEffortLow, ReasoningEffortHigh:
+	default:
+		return fmt.Errorf("invalid ReasoningEffort %q", o.ReasoningEffort)
+	}
+	if err := o.Search.Validate(); err != nil {
+		return fmt.Errorf("field Search: %w", err)
+	}
+	return nil
+}
+
+// ReasoningEffort controls how much time the model spends thinking.
+type ReasoningEffort string
+
+// Reasoning effort values.
+const (
+	ReasoningEffortLow  ReasoningEffort = "low"
+	ReasoningEffortHigh ReasoningEffort = "high"
+)
+
+// SearchMode controls when Live Search is used.
+type SearchMode string
+
+// Search mode values.
+const (
+	// SearchOff disables search. This is the default.
+	SearchOff SearchMode = "off"
+	// SearchAuto lets the model decide whether to search.
+	SearchAuto SearchMode = "auto"
+	// SearchOn forces search.
+	SearchOn SearchMode = "on"
+)
+
+// SearchParameters is documented at https://docs.x.ai/docs/guides/live-search
+type SearchParameters struct {
+	Mode SearchMode `json:"mode,omitzero"`
+	// ReturnCitations returns the URLs used as sources. It defaults to true on the server side when search is
+	// enabled.
+	ReturnCitations  bool           `json:"return_citations,omitzero"`
+	MaxSearchResults int64          `json:"max_search_results,omitzero"` // Default 20
+	FromDate         string         `json:"from_date,omitzero"`          // YYYY-MM-DD
+	ToDate           string         `json:"to_date,omitzero"`            // YYYY-MM-DD
+	Sources          []SearchSource `json:"sources,omitzero"`            // Defaults to web and x.
+}
+
+// IsZero reports whether the value is zero.
+func (s *SearchParameters) IsZero() bool {
+	return s.Mode == "" && !s.ReturnCitations && s.MaxSearchResults == 0 && s.FromDate == "" && s.ToDate == "" && len(s.Sources) == 0
+}
+
+// Validate implements genai.Validatable.
+func (s *SearchParameters) Validate() error {
+	switch s.Mode {
+	case "", SearchOff, SearchAuto, SearchOn:
+	default:
+		return fmt.Errorf("invalid Mode %q", s.Mode)
+	}
+	if s.MaxSearchResults < 0 {
+		return errors.New("field MaxSearchResults: must be non-negative")
+	}
+	for _, d := range []struct{ name, v string }{{"FromDate", s.FromDate}, {"ToDate", s.ToDate}} {
+		if d.v == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, d.v); err != nil {
+			return fmt.Errorf("field %s: must be formatted as YYYY-MM-DD: %q", d.name, d.v)
+		}
+	}
+	for i := range s.Sources {
+		if err := s.Sources[i].Validate(); err != nil {
+			return fmt.Errorf("source #%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// initWebSearch maps the generic web search options to a "web" source.
+func (s *SearchParameters) initWebSearch(o *genai.WebSearchOptions) []string {
+	if o == nil {
+		return nil
+	}
+	var unsupported []string
+	if o.MaxSearches != 0 {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.MaxSearches")
+	}
+	if o.ContextSize != "" {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.ContextSize")
+	}
+	l := &o.UserLocation
+	if l.City != "" || l.Region != "" || l.Timezone != "" || l.HasLatLng() {
+		unsupported = append(unsupported, "GenOptionWeb.SearchOptions.UserLocation.City")
+	}
+	if len(o.AllowedDomains) != 0 || len(o.BlockedDomains) != 0 || l.Country != "" {
+		s.Sources = []SearchSource{{
+			Type:             "web",
+			Country:          l.Country,
+			AllowedWebsites:  o.AllowedDomains,
+			ExcludedWebsites: o.BlockedDomains,
+		}}
+	}
+	return unsupported
+}
+
+// SearchSource is a data source for Live Search.
+type SearchSource struct {
+	Type string `json:"type"` // "web", "x", "news", "rss"
+
+	// Type == "web" or "news"
+	Country          string   `json:"country,omitzero"` // ISO alpha-2
+	ExcludedWebsites []string `json:"excluded_websites,omitzero"`
+	AllowedWebsites  []string `json:"allowed_websites,omitzero"` // Only for "web"
+
+	// Type == "x"
+	IncludedXHandles  []string `json:"included_x_handles,omitzero"`
+	ExcludedXHandles  []string `json:"excluded_x_handles,omitzero"`
+	PostFavoriteCount int64    `json:"post_favorite_count,omitzero"`
+	PostViewCount     int64    `json:"post_view_count,omitzero"`
+
+	// Type == "rss"
+	Links []string `json:"links,omitzero"`
+}
+
+// Validate implements genai.Validatable.
+func (s *SearchSource) Validate() error {
+	switch s.Type {
+	case "web", "x", "news", "rss":
+	default:
+		return fmt.Errorf("invalid Type %q", s.Type)
+	}
+	if len(s.ExcludedWebsites) != 0 && len(s.AllowedWebsites) != 0 {
+		return errors.New("field AllowedWebsites: cannot be used with ExcludedWebsites")
+	}
+	if len(s.IncludedXHandles) != 0 && len(s.ExcludedXHandles) != 0 {
+		return errors.New("field IncludedXHandles: cannot be used with ExcludedXHandles")
+	}
+	return nil
+}
+
+// ChatRequest is documented at https://docs.x.ai/docs/api-reference#chat-completions
+type ChatRequest struct {
+	Messages          []Message       `json:"messages"`
+	Model             string          `json:"model"`
+	FrequencyPenalty  float64         `json:"frequency_penalty,omitzero"` // [-2.0, 2.0]
+	Logprobs          bool            `json:"logprobs,omitzero"`
+	MaxChatTokens     int64           `json:"max_completion_tokens,omitzero"`
+	ParallelToolCalls bool            `json:"parallel_tool_calls,omitzero"`
+	PresencePenalty   float64         `json:"presence_penalty,omitzero"` // [-2.0, 2.0]
+	ReasoningEffort   ReasoningEffort `json:"reasoning_effort,omitzero"`
+	ResponseFormat    struct {
+		Type       string `json:"type,omitzero"` // "text", "json_object", "json_schema"
+		JSONSchema struct {
+			Name   string           `json:"name,omitzero"`
+			Schema genai.JSONSchema `json:"schema,omitzero"`
+			Strict bool             `json:"strict,omitzero"`
+		} `json:"json_schema,omitzero"`
+	} `json:"response_format,omitzero"`
+	SearchParameters SearchParameters `json:"search_parameters,omitzero"`
+	Seed             int64            `json:"seed,omitzero"`
+	Stop             []string         `json:"stop,omitzero"`
+	Stream           bool             `json:"stream"`
+	StreamOptions    struct {
+		IncludeUsage bool `json:"include_usage,omitzero"`
+	} `json:"stream_options,omitzero"`
+	Temperature float64 `json:"temperature,omitzero"` // [0, 2]
+	ToolChoice  string  `json:"tool_choice,omitzero"` // "none", "auto", "required"
+	Tools       []Tool  `json:"tools,omitzero"`
+	TopLogprobs int64   `json:"top_logprobs,omitzero"` // [0, 8]
+	TopP        float64 `json:"top_p,omitzero"`        // [0, 1]
+	User        string  `json:"user,omitzero"`
+
+	// Explicitly Unsupported:
+	// Deferred  bool               `json:"deferred,omitzero"`
+	// LogitBias map[string]float64 `json:"logit_bias,omitzero"`
+	// N         int64              `json:"n,omitzero"`
+}
+
+// Init initializes the provider specific completion request with the generic completion request.
+func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenOption) error {
+	c.Model = model
+	if err := msgs.Validate(); err != nil {
+		return err
+	}
+	var errs []error
+	var unsupported []string
+	sp := ""
+	for _, opt := range opts {
+		if err := opt.Validate(); err != nil {
+			return err
+		}
+		switch v := opt.(type) {
+		case *GenOption:
+			if v.ReasoningEffort != "" {
+				c.ReasoningEffort = v.ReasoningEffort
+			}
+			if !v.Search.IsZero() {
+				c.SearchParameters = v.Search
+			}
+		case *genai.GenOptionText:
+			unsupported = append(unsupported, c.initOptionsText(v, &errs)...)
+			sp = v.SystemPrompt
+		case *genai.GenOptionTools:
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			if err := c.initOptionsTools(v); err != nil {
+				errs = append(errs, err)
+			}
+		case *genai.GenOptionWeb:
+			if v.Search && c.SearchParameters.IsZero() {
+				c.SearchParameters = SearchParameters{Mode: SearchOn, ReturnCitations: true}
+				unsupported = append(unsupported, c.SearchParameters.initWebSearch(v.SearchOptions)...)
+			}
+			if v.Fetch {
+				unsupported = append(unsupported, "GenOptionWeb.Fetch")
+			}
+		case genai.GenOptionSeed:
+			c.Seed = int64(v)
+		default:
+			unsupported = append(unsupported, internal.TypeName(opt))
+		}
+	}
+
+	if sp != "" {
+		c.Messages = append(c.Messages, Message{Role: "system", Content: Contents{{Type: ContentText, Text: sp}}})
+	}
+	for i := range msgs {
+		if len(msgs[i].ToolCallResults) > 1 {
+			// Handle messages with multiple tool call results by creating multiple messages
+			for j := range msgs[i].ToolCallResults {
+				// Create a copy of the message with only one tool call result
+				msgCopy := msgs[i]
+				msgCopy.ToolCallResults = []genai.ToolCallResult{msgs[i].ToolCallResults[j]}
+				var newMsg Message
+				if err := newMsg.From(&msgCopy); err != nil {
+					errs = append(errs, fmt.Errorf("message #%d: tool call results #%d: %w", i, j, err))
+				} else {
+					c.Messages = append(c.Messages, newMsg)
+				}
+			}
+		} else {
+			var newMsg Message
+			if err := newMsg.From(&msgs[i]); err != nil {
+				errs = append(errs, fmt.Errorf("message #%d: %w", i, err))
+			} else {
+				c.Messages = append(c.Messages, newMsg)
+			}
+		}
+	}
+	// If we have unsupported features but no other errors, return a structured error.
+	if len(unsupported) > 0 && len(errs) == 0 {
+		return &base.ErrNotSupported{Options: unsupported}
+	}
+	return errors.Join(errs...)
+}
+
+// SetStream sets the streaming mode.
+func (c *ChatRequest) SetStream(stream bool) {
+	c.Stream = stream
+	c.StreamOptions.IncludeUsage = stream
+}
+
+func (c *ChatRequest) initOptionsText(v *genai.GenOptionText, errs *[]error) []string {
+	var unsupported []string
+	c.MaxChatTokens = v.MaxTokens
+	c.Temperature = v.Temperature
+	c.TopP = v.TopP
+	if v.TopK != 0 {
+		unsupported = append(unsupported, "GenOptionText.TopK")
+	}
+	if v.N > 1 {
+		unsupported = append(unsupported, "GenOptionText.N")
+	}
+	if v.Grammar != "" {
+		unsupported = append(unsupported, "GenOptionText.Grammar")
+	}
+	switch v.ReasoningEffort {
+	case "":
+	case genai.ReasoningEffortLow, genai.ReasoningEffortHigh:
+		if c.ReasoningEffort == "" {
+			c.ReasoningEffort = ReasoningEffort(v.ReasoningEffort)
+		}
+	default:
+		unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+	}
+	if v.TopLogprobs > 0 {
+		c.TopLogprobs = v.TopLogprobs
+		c.Logprobs = true
+	}
+	c.Stop = v.Stop
+	if v.DecodeAs != nil {
+		s, err := v.DecodeSchema()
+		if err != nil {
+			*errs = append(*errs, err)
+		}
+		c.ResponseFormat.Type = "json_schema"
+		c.ResponseFormat.JSONSchema.Name = "response"
+		c.ResponseFormat.JSONSchema.Schema = s
+		c.ResponseFormat.JSONSchema.Strict = true
+	} else if v.ReplyAsJSON {
+		c.ResponseFormat.Type = "json_object"
+	}
+	return unsupported
+}
+
+func (c *ChatRequest) initOptionsTools(v *genai.GenOptionTools) error {
+	tools := v.AllowedTools()
+	if len(tools) != 0 {
+		switch v.Force {
+		case genai.ToolCallAny:
+			c.ToolChoice = "auto"
+		case genai.ToolCallRequired:
+			c.ToolChoice = "required"
+		case genai.ToolCallNone:
+			c.ToolChoice = "none"
+		}
+		// Documentation states max is 128 tools.
+		c.Tools = make([]Tool, len(tools))
+		for i, t := range tools {
+			c.Tools[i].Type = "function"
+			c.Tools[i].Function.Name = t.Name
+			c.Tools[i].Function.Description = t.Description
+			s, err := t.GetInputSchema()
+			if err != nil {
+				return err
+			}
+			c.Tools[i].Function.Parameters = s
+		}
+	}
+	return nil
+}
+
+// Message is documented at https://docs.x.ai/docs/api-reference#chat-completions
+type Message struct {
+	Role             string     `json:"role,omitzero"` // "system", "assistant", "user", "tool"
+	Name             string     `json:"name,omitzero"`
+	Content          Contents   `json:"content,omitzero"`
+	ReasoningContent string     `json:"reasoning_content,omitzero"` // In replies; only for grok-3-mini
+	Refusal          string     `json:"refusal,omitzero"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitzero"`
+	ToolCallID       string     `json:"tool_call_id,omitzero"`
+}
+
+// From must be called with at most one ToolCallResults.
+func (m *Message) From(in *genai.Message) error {
+	if len(in.ToolCallResults) > 1 {
+		return errors.New("internal error")
+	}
+	switch r := in.Role(); r {
+	case "user", "assistant":
+		m.Role = r
+	case "computer":
+		m.Role = "tool"
+	default:
+		return fmt.Errorf("unsupported role %q", r)
+	}
+	m.Name = in.User
+	if len(in.Requests) != 0 {
+		m.Content = make(Contents, len(in.Requests))
+		for i := range in.Requests {
+			if err := m.Content[i].FromRequest(&in.Requests[i]); err != nil {
+				return fmt.Errorf("request #%d: %w", i, err)
+			}
+		}
+	}
+	if len(in.Replies) != 0 {
+		m.Content = make(Contents, 0, len(in.Replies))
+		for i := range in.Replies {
+			if len(in.Replies[i].Opaque) != 0 {
+				return &internal.BadError{Err: fmt.Errorf("reply #%d: field Reply.Opaque not supported", i)}
+			}
+			switch {
+			case in.Replies[i].Reasoning != "":
+				// xAI ignores reasoning_content in the input.
+			case !in.Replies[i].Citation.IsZero():
+				// Citations are not sent back.
+			case !in.Replies[i].ToolCall.IsZero():
+				m.ToolCalls = append(m.ToolCalls, ToolCall{})
+				if err := m.ToolCalls[len(m.ToolCalls)-1].From(&in.Replies[i].ToolCall); err != nil {
+					return fmt.Errorf("reply #%d: %w", i, err)
+				}
+			case in.Replies[i].Text != "":
+				m.Content = append(m.Content, Content{Type: ContentText, Text: in.Replies[i].Text})
+			default:
+				return &internal.BadError{Err: fmt.Errorf("reply #%d: unsupported Reply type", i)}
+			}
+		}
+	}
+	if len(in.ToolCallResults) != 0 {
+		// Process only the first tool call result in this method.
+		// The Init method handles multiple tool call results by creating multiple messages.
+		m.Content = Contents{{Type: ContentText, Text: in.ToolCallResults[0].Result}}
+		m.ToolCallID = in.ToolCallResults[0].ID
+	}
+	return nil
+}
+
+// To converts to the genai equivalent.
+func (m *Message) To(out *genai.Message) error {
+	if m.ReasoningContent != "" {
+		out.Replies = append(out.Replies, genai.Reply{Reasoning: m.ReasoningContent})
+	}
+	for _, c := range m.Content {
+		switch c.Type {
+		case ContentText:
+			if c.Text == "" {
+				return &internal.BadError{Err: errors.New("empty content text")}
+			}
+			out.Replies = append(out.Replies, genai.Reply{Text: c.Text})
+		default:
+			return &internal.BadError{Err: fmt.Errorf("implement content type %q", c.Type)}
+		}
+	}
+	if m.Refusal != "" {
+		out.Replies = append(out.Replies, genai.Reply{Text: m.Refusal})
+	}
+	for i := range m.ToolCalls {
+		out.Replies = append(out.Replies, genai.Reply{})
+		m.ToolCalls[i].To(&out.Replies[len(out.Replies)-1].ToolCall)
+	}
+	return nil
+}
+
+// Contents exists to marshal single content text block as a string.
+type Contents []Content
+
+// IsZero reports whether the value is zero.
+func (c *Contents) IsZero() bool {
+	return len(*c) == 0
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c *Contents) MarshalJSON() ([]byte, error) {
+	if len(*c) == 0 {
+		return []byte("null"), nil
+	}
+	if len(*c) == 1 && (*c)[0].Type == ContentText {
+		return json.Marshal((*c)[0].Text)
+	}
+	return json.Marshal([]Content(*c))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Contents) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		// e.g. tool calls.
+		*c = nil
+		return nil
+	}
+	if err := json.Unmarshal(b, (*[]Content)(c)); err == nil {
+		return nil
+	}
+	s := ""
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s != "" {
+		*c = Contents{{Type: ContentText, Text: s}}
+	} else {
+		// Decode empty string as nil.
+		*c = nil
+	}
+	return nil
+}
+
+// Content is a provider-specific content block.
+type Content struct {
+	Type ContentType `json:"type,omitzero"`
+
+	// Type == "text"
+	Text string `json:"text,omitzero"`
+
+	// Type == "image_url"
+	ImageURL struct {
+		Detail string `json:"detail,omitzero"` // "auto", "low", "high"
+		URL    string `json:"url,omitzero"`    // URL or base64 encoded image
+	} `json:"image_url,omitzero"`
+}
+
+// FromRequest converts from a genai request.
+func (c *Content) FromRequest(in *genai.Request) error {
+	if in.Text != "" {
+		c.Type = ContentText
+		c.Text = in.Text
+		return nil
+	}
+	if !in.Doc.IsZero() {
+		// https://docs.x.ai/docs/guides/image-understanding
+		mimeType, data, err := in.Doc.Read(10 * 1024 * 1024)
+		if err != nil {
+			return err
+		}
+		switch {
+		case (in.Doc.URL != "" && mimeType == "") || mimeType == "image/jpeg" || mimeType == "image/png":
+			c.Type = ContentImageURL
+			c.ImageURL.Detail = "high"
+			if in.Doc.URL == "" {
+				c.ImageURL.URL = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
+			} else {
+				c.ImageURL.URL = in.Doc.URL
+			}
+		case strings.HasPrefix(mimeType, "text/"):
+			c.Type = ContentText
+			if in.Doc.URL != "" {
+				return fmt.Errorf("%s documents must be provided inline, not as a URL", mimeType)
+			}
+			c.Text = string(data)
+		default:
+			return fmt.Errorf("unsupported mime type %s", mimeType)
+		}
+		return nil
+	}
+	return errors.New("unknown Request type")
+}
+
+// ContentType is a provider-specific content type.
+type ContentType string
+
+// Content type values.
+const (
+	ContentText     ContentType = "text"
+	ContentImageURL ContentType = "image_url"
+)
+
+// Tool is a provider-specific tool definition.
+type Tool struct {
+	Type     string `json:"type,omitzero"` // "function"
+	Function struct {
+		Name        string           `json:"name,omitzero"`
+		Description string           `json:"description,omitzero"`
+		Parameters  genai.JSONSchema `json:"parameters,omitzero"`
+	} `json:"function,omitzero"`
+}
+
+// ToolCall is a provider-specific tool call.
+type ToolCall struct {
+	Index    int64  `json:"index,omitzero"`
+	Type     string `json:"type,omitzero"` // "function"
+	ID       string `json:"id,omitzero"`
+	Function struct {
+		Name      string `json:"name,omitzero"`
+		Arguments string `json:"arguments,omitzero"`
+	} `json:"function,omitzero"`
+}
+
+// From converts from the genai equivalent.
+func (t *ToolCall) From(in *genai.ToolCall) error {
+	if len(in.Opaque) != 0 {
+		return errors.New("field ToolCall.Opaque not supported")
+	}
+	t.Type = "function"
+	t.ID = in.ID
+	t.Function.Name = in.Name
+	t.Function.Arguments = in.Arguments
+	return nil
+}
+
+// To converts to the genai equivalent.
+func (t *ToolCall) To(out *genai.ToolCall) {
+	out.ID = t.ID
+	out.Name = t.Function.Name
+	out.Arguments = t.Function.Arguments
+}
+
+// ChatResponse is the provider-specific chat completion response.
+type ChatResponse struct {
+	ID      string     `json:"id"`
+	Object  string     `json:"object"` // "chat.completion"
+	Created base.TimeS `json:"created"`
+	Model   string     `json:"model"`
+	Choices []struct {
+		Index        int64        `json:"index"`
+		Message      Message      `json:"message"`
+		FinishReason FinishReason `json:"finish_reason"`
+		Logprobs     Logprobs     `json:"logprobs"`
+	} `json:"choices"`
+	Usage             Usage    `json:"usage"`
+	SystemFingerprint string   `json:"system_fingerprint"`
+	Citations         []string `json:"citations"`
+}
+
+// ToResult converts the response to a genai.Result.
+func (c *ChatResponse) ToResult() (genai.Result, error) {
+	out := genai.Result{Usage: c.Usage.To()}
+	if len(c.Choices) != 1 {
+		return out, &internal.BadError{Err: fmt.Errorf("server returned an unexpected number of choices, expected 1, got %d", len(c.Choices))}
+	}
+	fr, errFR := c.Choices[0].FinishReason.ToFinishReason()
+	out.Usage.FinishReason = fr
+	if err := c.Choices[0].Message.To(&out.Message); err != nil {
+		return out, err
+	}
+	if len(c.Citations) != 0 {
+		out.Replies = append(out.Replies, genai.Reply{Citation: citationsTo(c.Citations)})
+	}
+	out.Logprobs = c.Choices[0].Logprobs.To()
+	return out, errFR
+}
+
+// citationsTo converts the Live Search source URLs.
+func citationsTo(urls []string) genai.Citation {
+	ct := genai.Citation{Sources: make([]genai.CitationSource, len(urls))}
+	for i, u := range urls {
+		ct.Sources[i].Type = genai.CitationWeb
+		ct.Sources[i].URL = u
+	}
+	return ct
+}
+
+// FinishReason is a provider-specific finish reason.
+type FinishReason string
+
+// Finish reason values.
+const (
+	FinishStop          FinishReason = "stop"
+	FinishLength        FinishReason = "length"
+	FinishToolCalls     FinishReason = "tool_calls"
+	FinishContentFilter FinishReason = "content_filter"
+	FinishEndTurn       FinishReason = "end_turn"
+)
+
+// ToFinishReason converts to a genai.FinishReason.
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
+	switch f {
+	case FinishStop, FinishEndTurn:
+		return genai.FinishedStop, nil
+	case FinishLength:
+		return genai.FinishedLength, nil
+	case FinishToolCalls:
+		return genai.FinishedToolCalls, nil
+	case FinishContentFilter:
+		return genai.FinishedContentFilter, nil
+	default:
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
+	}
+}
+
+// Usage is the provider-specific token usage.
+type Usage struct {
+	PromptTokens        int64 `json:"prompt_tokens"`
+	CompletionTokens    int64 `json:"completion_tokens"`
+	TotalTokens         int64 `json:"total_tokens"`
+	PromptTokensDetails struct {
+		TextTokens   int64 `json:"text_tokens"`
+		AudioTokens  int64 `json:"audio_tokens"`
+		ImageTokens  int64 `json:"image_tokens"`
+		CachedTokens int64 `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails struct {
+		ReasoningTokens          int64 `json:"reasoning_tokens"`
+		AudioTokens              int64 `json:"audio_tokens"`
+		AcceptedPredictionTokens int64 `json:"accepted_prediction_tokens"`
+		RejectedPredictionTokens int64 `json:"rejected_prediction_tokens"`
+	} `json:"completion_tokens_details"`
+	NumSourcesUsed int64 `json:"num_sources_used"`
+}
+
+// To converts to the genai equivalent.
+func (u *Usage) To() genai.Usage {
+	return genai.Usage{
+		InputTokens:       u.PromptTokens,
+		InputCachedTokens: u.PromptTokensDetails.CachedTokens,
+		ReasoningTokens:   u.CompletionTokensDetails.ReasoningTokens,
+		OutputTokens:      u.CompletionTokens,
+		TotalTokens:       u.TotalTokens,
+	}
+}
+
+// Logprobs is the provider-specific log probabilities.
+type Logprobs struct {
+	Content []struct {
+		Token       string  `json:"token"`
+		Bytes       []byte  `json:"bytes"`
+		Logprob     float64 `json:"logprob"`
+		TopLogprobs []struct {
+			Token   string  `json:"token"`
+			Bytes   []byte  `json:"bytes"`
+			Logprob float64 `json:"logprob"`
+		} `json:"top_logprobs"`
+	} `json:"content,omitzero"`
+}
+
+// To converts to the genai equivalent.
+func (l *Logprobs) To() [][]genai.Logprob {
+	if len(l.Content) == 0 {
+		return nil
+	}
+	out := make([][]genai.Logprob, 0, len(l.Content))
+	for _, c := range l.Content {
+		lp := make([]genai.Logprob, 1, len(c.TopLogprobs)+1)
+		// Intentionally discard Bytes.
+		lp[0] = genai.Logprob{Text: c.Token, Logprob: c.Logprob}
+		for _, tlp := range c.TopLogprobs {
+			lp = append(lp, genai.Logprob{Text: tlp.Token, Logprob: tlp.Logprob})
+		}
+		out = append(out, lp)
+	}
+	return out
+}
+
+// ChatStreamChunkResponse is the provider-specific streaming chat chunk.
+type ChatStreamChunkResponse struct {
+	ID                string     `json:"id"`
+	Object            string     `json:"object"` // "chat.completion.chunk"
+	Created           base.TimeS `json:"created"`
+	Model             string     `json:"model"`
+	SystemFingerprint string     `json:"system_fingerprint"`
+	Choices           []struct {
+		Index        int64        `json:"index"`
+		Delta        Message      `json:"delta"`
+		Logprobs     Logprobs     `json:"logprobs"`
+		FinishReason FinishReason `json:"finish_reason"`
+	} `json:"choices"`
+	Usage     Usage    `json:"usage,omitzero"`
+	Citations []string `json:"citations"`
+}
+
+// Model is documented at https://docs.x.ai/docs/api-reference#list-language-models
+type Model struct {
+	ID                         string     `json:"id"`
+	Fingerprint                string     `json:"fingerprint"`
+	Created                    base.TimeS `json:"created"`
+	Object                     string     `json:"object"` // "model"
+	OwnedBy                    string     `json:"owned_by"`
+	Version                    string     `json:"version"`
+	InputModalities            []string   `json:"input_modalities"`  // "text", "image"
+	OutputModalities           []string   `json:"output_modalities"` // "text"
+	PromptTextTokenPrice       int64      `json:"prompt_text_token_price"`
+	CachedPromptTextTokenPrice int64      `json:"cached_prompt_text_token_price"`
+	PromptImageTokenPrice      int64      `json:"prompt_image_token_price"`
+	CompletionTextTokenPrice   int64      `json:"completion_text_token_price"`
+	SearchPrice                int64      `json:"search_price"`
+	Aliases                    []string   `json:"aliases"`
+	MaxPromptLength            int64      `json:"max_prompt_length"`
+}
+
+// GetID implements genai.Model.
+func (m *Model) GetID() string {
+	return m.ID
+}
+
+func (m *Model) String() string {
+	suffix := ""
+	if len(m.Aliases) != 0 {
+		suffix = " aka " + strings.Join(m.Aliases, ", ")
+	}
+	return fmt.Sprintf("%s (%s) %s->%s%s", m.ID, m.Created.AsTime().Format("2006-01-02"), strings.Join(m.InputModalities, "+"), strings.Join(m.OutputModalities, "+"), suffix)
+}
+
+// Context implements genai.Model.
+func (m *Model) Context() int64 {
+	return m.MaxPromptLength
+}
+
+// ModelsResponse represents the response structure for xAI language models listing.
+type ModelsResponse struct {
+	Models []Model `json:"models"`
+}
+
+// ToModels converts xAI models to genai.Model interfaces.
+func (r *ModelsResponse) ToModels() []genai.Model {
+	models := make([]genai.Model, len(r.Models))
+	for i := range r.Models {
+		models[i] = &r.Models[i]
+	}
+	return models
+}
+
+// ErrorResponse is the provider-specific error response.
+type ErrorResponse struct {
+	Code     string `json:"code"`  // e.g. "Client specified an invalid argument"
+	ErrorVal string `json:"error"` // e.g. "Incorrect API key provided: xa***. You can obtain an API key from https://console.x.ai."
+}
+
+func (er *ErrorResponse) Error() string {
+	if er.Code == "" {
+		return er.ErrorVal
+	}
+	return fmt.Sprintf("%s: %s", er.Code, er.ErrorVal)
+}
+
+// IsAPIError implements base.ErrorResponseI.
+func (er *ErrorResponse) IsAPIError() bool {
+	return true
+}
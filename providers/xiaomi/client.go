@@ -61,11 +61,19 @@ type Client struct {
 //
 // To use multiple models, create multiple clients.
 // Use one of the models from https://platform.xiaomimimo.com/docs/en-US/api/chat/openai-api
+//
+// The proxy defaults to the MIMO_PROXY environment variable, see genai.ProviderOptionProxyURL.
 func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 	var apiKey, model string
 	var modalities genai.Modalities
 	var preloadedModels []genai.Model
+	var selector genai.ProviderOptionModelSelector
+	var pin genai.ProviderOptionModelPin
 	var wrapper func(http.RoundTripper) http.RoundTripper
+	var logger genai.ProviderOptionLogger
+	var httpOpts *genai.ProviderOptionHTTP
+	var proxyURL genai.ProviderOptionProxyURL
+	lenient := true
 	if err := base.CheckDuplicateOptions(opts); err != nil {
 		return nil, err
 	}
@@ -82,8 +90,20 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			modalities = genai.Modalities(v)
 		case genai.ProviderOptionPreloadedModels:
 			preloadedModels = []genai.Model(v)
+		case genai.ProviderOptionModelSelector:
+			selector = v
+		case genai.ProviderOptionModelPin:
+			pin = v
 		case genai.ProviderOptionTransportWrapper:
 			wrapper = v
+		case genai.ProviderOptionLogger:
+			logger = v
+		case genai.ProviderOptionHTTP:
+			httpOpts = &v
+		case genai.ProviderOptionProxyURL:
+			proxyURL = v
+		case genai.ProviderOptionStrict:
+			lenient = !bool(v)
 		default:
 			return nil, fmt.Errorf("unsupported option type %T", opt)
 		}
@@ -105,7 +125,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			return nil, fmt.Errorf("unexpected option Modalities %s, only text or audio is supported", modalities)
 		}
 	}
-	t := base.DefaultTransport
+	t := base.NewTransport(httpOpts, proxyURL, "MIMO_PROXY")
 	if wrapper != nil {
 		t = wrapper(t)
 	}
@@ -114,9 +134,12 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 			GenSyncURL:      "https://api.xiaomimimo.com/v1/chat/completions",
 			ProcessStream:   makeProcessStream(""),
 			PreloadedModels: preloadedModels,
+			ModelPin:        string(pin),
+			ModelSelector:   selector,
 			ProviderBase: base.ProviderBase[*ErrorResponse]{
 				APIKeyURL: apiKeyURL,
-				Lenient:   internal.BeLenient,
+				Lenient:   lenient,
+				Log:       logger,
 				Client: http.Client{
 					Transport: &roundtrippers.Header{
 						Header:    http.Header{"Authorization": {"Bearer " + apiKey}},
@@ -135,7 +158,7 @@ func New(ctx context.Context, opts ...genai.ProviderOption) (*Client, error) {
 					return nil, err
 				}
 			} else {
-				if c.impl.Model, err = c.selectBestTextModel(ctx, model); err != nil {
+				if c.impl.Model, err = c.impl.AutoSelectModel(ctx, c.Name(), model, c.selectBestTextModel); err != nil {
 					return nil, err
 				}
 			}
@@ -161,6 +184,9 @@ func (c *Client) selectBestTextModel(ctx context.Context, preference string) (st
 	if err != nil {
 		return "", fmt.Errorf("failed to automatically select the model: %w", err)
 	}
+	if c.impl.ModelSelector != nil {
+		return base.SelectModel(ctx, c.impl.ModelSelector, preference, mdls)
+	}
 	// ModelGood and ModelSOTA both select mimo-v2.5-pro.
 	want := "mimo-v2.5-pro"
 	if preference == string(genai.ModelCheap) {
@@ -206,6 +232,11 @@ func (c *Client) ModelID() string {
 	return c.impl.Model
 }
 
+// ModelSelection implements genai.ProviderModelSelection.
+func (c *Client) ModelSelection() genai.ModelSelection {
+	return c.impl.Selection
+}
+
 // OutputModalities implements genai.Provider.
 //
 // It returns the output modalities, i.e. what kind of output the model will generate (text, audio, image,
@@ -235,7 +266,8 @@ func (c *Client) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai
 	if err := c.GenSyncRaw(ctx, in, out); err != nil {
 		return genai.Result{}, err
 	}
-	return out.ToResult()
+	res, err := out.ToResult()
+	return res, c.impl.StrictErr(err)
 }
 
 // GenSyncRaw provides access to the raw API.
@@ -278,7 +310,7 @@ func (c *Client) GenStream(ctx context.Context, msgs genai.Messages, opts ...gen
 		err := finishRaw()
 		var usageErr error
 		res.Usage, res.Logprobs, usageErr = finishUsage()
-		err = errors.Join(err, usageErr)
+		err = errors.Join(err, c.impl.StrictErr(usageErr))
 		if err != nil {
 			return res, err
 		}
@@ -329,7 +361,10 @@ func makeProcessStream(audioFormat string) func(iter.Seq[ChatStreamChunkResponse
 					}
 					// Extract finish reason from the chunk that has it.
 					if pkt.Choices[0].FinishReason != "" {
-						u.FinishReason = pkt.Choices[0].FinishReason.ToFinishReason()
+						var err error
+						if u.FinishReason, err = pkt.Choices[0].FinishReason.ToFinishReason(); err != nil {
+							finalErr = errors.Join(finalErr, err)
+						}
 					}
 					if len(pkt.Choices[0].Delta.ToolCalls) > 1 {
 						finalErr = &internal.BadError{Err: fmt.Errorf("implement multiple tool calls: %#v", pkt)}
@@ -364,10 +399,7 @@ func makeProcessStream(audioFormat string) func(iter.Seq[ChatStreamChunkResponse
 					// Handle web search citations.
 					for _, a := range pkt.Choices[0].Delta.Annotations {
 						if a.Type != "url_citation" {
-							if !internal.BeLenient {
-								finalErr = &internal.BadError{Err: fmt.Errorf("unsupported annotation type %q", a.Type)}
-								return
-							}
+							finalErr = errors.Join(finalErr, &base.ErrUnhandled{Err: fmt.Errorf("unsupported annotation type %q", a.Type)})
 							continue
 						}
 						c := genai.Citation{
@@ -427,4 +459,7 @@ func makeProcessStream(audioFormat string) func(iter.Seq[ChatStreamChunkResponse
 	}
 }
 
-var _ genai.Provider = &Client{}
+var (
+	_ genai.Provider               = &Client{}
+	_ genai.ProviderModelSelection = &Client{}
+)
@@ -124,6 +124,15 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.TopK != 0 {
 				unsupported = append(unsupported, "GenOptionText.TopK")
 			}
+			if v.N > 1 {
+				unsupported = append(unsupported, "GenOptionText.N")
+			}
+			if v.Grammar != "" {
+				unsupported = append(unsupported, "GenOptionText.Grammar")
+			}
+			if v.ReasoningEffort != "" {
+				unsupported = append(unsupported, "GenOptionText.ReasoningEffort")
+			}
 			if v.TopLogprobs > 0 {
 				unsupported = append(unsupported, "GenOptionText.TopLogprobs")
 			}
@@ -135,7 +144,11 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				errs = append(errs, errors.New("unsupported option DecodeAs"))
 			}
 		case *genai.GenOptionTools:
-			if len(v.Tools) != 0 {
+			if v.Computer != nil {
+				unsupported = append(unsupported, "GenOptionTools.Computer")
+			}
+			tools := v.AllowedTools()
+			if len(tools) != 0 {
 				switch v.Force {
 				case genai.ToolCallAny:
 					c.ToolChoice = "auto"
@@ -144,8 +157,8 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 				case genai.ToolCallNone:
 					c.ToolChoice = "auto"
 				}
-				c.Tools = make([]ToolOrSearch, len(v.Tools))
-				for i, t := range v.Tools {
+				c.Tools = make([]ToolOrSearch, len(tools))
+				for i, t := range tools {
 					fn := Tool{Type: "function"}
 					fn.Function.Name = t.Name
 					fn.Function.Description = t.Description
@@ -166,6 +179,9 @@ func (c *ChatRequest) Init(msgs genai.Messages, model string, opts ...genai.GenO
 			if v.Fetch {
 				errs = append(errs, errors.New("unsupported GenOptionWeb.Fetch"))
 			}
+			if v.SearchOptions != nil {
+				errs = append(errs, errors.New("unsupported GenOptionWeb.SearchOptions"))
+			}
 		case *GenOptionAudio:
 			if v.Format != "" {
 				c.Audio.Format = v.Format
@@ -254,11 +270,7 @@ func (c *Contents) UnmarshalJSON(b []byte) error {
 		*c = nil
 		return nil
 	}
-	d := json.NewDecoder(bytes.NewReader(b))
-	if !internal.BeLenient {
-		d.DisallowUnknownFields()
-	}
-	if err := d.Decode((*[]Content)(c)); err == nil {
+	if err := json.Unmarshal(b, (*[]Content)(c)); err == nil {
 		return nil
 	}
 
@@ -476,11 +488,10 @@ func (m *Message) To(out *genai.Message) error {
 		out.Replies = append(out.Replies, genai.Reply{})
 		m.ToolCalls[i].To(&out.Replies[len(out.Replies)-1].ToolCall)
 	}
+	var errs []error
 	for _, a := range m.Annotations {
 		if a.Type != "url_citation" {
-			if !internal.BeLenient {
-				return fmt.Errorf("unsupported annotation type %q", a.Type)
-			}
+			errs = append(errs, &base.ErrUnhandled{Err: fmt.Errorf("unsupported annotation type %q", a.Type)})
 			continue
 		}
 		c := genai.Citation{
@@ -492,7 +503,7 @@ func (m *Message) To(out *genai.Message) error {
 		}
 		out.Replies = append(out.Replies, genai.Reply{Citation: c})
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // ToolCall is a provider-specific tool call.
@@ -587,7 +598,8 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 	if len(c.Choices) != 1 {
 		return out, fmt.Errorf("expected 1 choice, got %#v", c.Choices)
 	}
-	out.Usage.FinishReason = c.Choices[0].FinishReason.ToFinishReason()
+	fr, errFR := c.Choices[0].FinishReason.ToFinishReason()
+	out.Usage.FinishReason = fr
 	if err := c.Choices[0].Message.To(&out.Message); err != nil {
 		return out, err
 	}
@@ -599,7 +611,7 @@ func (c *ChatResponse) ToResult() (genai.Result, error) {
 			}
 		}
 	}
-	return out, nil
+	return out, errFR
 }
 
 // FinishReason is a provider-specific finish reason.
@@ -615,23 +627,22 @@ const (
 )
 
 // ToFinishReason converts to a genai.FinishReason.
-func (f FinishReason) ToFinishReason() genai.FinishReason {
+//
+// An unknown value is returned as-is with a *base.ErrUnhandled.
+func (f FinishReason) ToFinishReason() (genai.FinishReason, error) {
 	switch f {
 	case FinishStop:
-		return genai.FinishedStop
+		return genai.FinishedStop, nil
 	case FinishToolCalls:
-		return genai.FinishedToolCalls
+		return genai.FinishedToolCalls, nil
 	case FinishLength:
-		return genai.FinishedLength
+		return genai.FinishedLength, nil
 	case FinishContentFilter:
-		return genai.FinishedContentFilter
+		return genai.FinishedContentFilter, nil
 	case FinishRepetition:
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), nil
 	default:
-		if !internal.BeLenient {
-			panic(f)
-		}
-		return genai.FinishReason(f)
+		return genai.FinishReason(f), &base.ErrUnhandled{Err: fmt.Errorf("unknown finish reason %q", f)}
 	}
 }
 
@@ -0,0 +1,297 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package rag implements the retrieval side of retrieval augmented generation.
+//
+// An Index splits documents into chunks with a Chunker, embeds them with an Embedder and keeps the vectors
+// in memory. Search returns the chunks closest to a query by cosine similarity and Retrieve formats them as
+// genai.Request documents, so providers supporting citations (Anthropic, Cohere) can cite them.
+package rag
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/maruel/genai"
+)
+
+// Embedder converts texts to embedding vectors.
+type Embedder interface {
+	// Embed returns one vector per text, in the same order. All the vectors must have the same dimension.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface.
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// Embed implements Embedder.
+func (f EmbedderFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
+
+// Chunker splits a text into chunks.
+type Chunker func(text string) []string
+
+// ByTokens returns a Chunker that splits text in chunks of size tokens, each chunk repeating the last
+// overlap tokens of the previous one.
+//
+// Tokens are approximated as whitespace separated words, which is about 0.75 of an actual tokenizer token in
+// English.
+func ByTokens(size, overlap int) Chunker {
+	if size <= 0 || overlap < 0 || overlap >= size {
+		panic(fmt.Sprintf("invalid size %d and overlap %d", size, overlap))
+	}
+	return func(text string) []string {
+		words := strings.Fields(text)
+		var out []string
+		for start := 0; start < len(words); start += size - overlap {
+			end := min(start+size, len(words))
+			out = append(out, strings.Join(words[start:end], " "))
+			if end == len(words) {
+				break
+			}
+		}
+		return out
+	}
+}
+
+// BySentences returns a Chunker that groups whole sentences in chunks of at most maxLen bytes. A sentence
+// longer than maxLen is its own chunk.
+func BySentences(maxLen int) Chunker {
+	if maxLen <= 0 {
+		panic(fmt.Sprintf("invalid maxLen %d", maxLen))
+	}
+	return func(text string) []string {
+		var out []string
+		var cur strings.Builder
+		for _, s := range sentences(text) {
+			if cur.Len() != 0 && cur.Len()+1+len(s) > maxLen {
+				out = append(out, cur.String())
+				cur.Reset()
+			}
+			if cur.Len() != 0 {
+				cur.WriteByte(' ')
+			}
+			cur.WriteString(s)
+		}
+		if cur.Len() != 0 {
+			out = append(out, cur.String())
+		}
+		return out
+	}
+}
+
+// sentences splits text after '.', '!' or '?' followed by a space, and collapses whitespace.
+func sentences(text string) []string {
+	var out []string
+	words := strings.Fields(text)
+	start := 0
+	for i, w := range words {
+		if r := w[len(w)-1]; r == '.' || r == '!' || r == '?' || i == len(words)-1 {
+			out = append(out, strings.Join(words[start:i+1], " "))
+			start = i + 1
+		}
+	}
+	return out
+}
+
+// Chunk is a part of a document.
+type Chunk struct {
+	// Source is the name of the document, e.g. its filename or URL.
+	Source string
+	// Index is the position of the chunk in the document.
+	Index int
+	// Text is the content of the chunk.
+	Text string
+}
+
+// Match is a chunk returned by Index.Search.
+type Match struct {
+	Chunk
+	// Score is the cosine similarity with the query, between -1 and 1. After Rerank, it is the score returned
+	// by the reranker.
+	Score float64
+}
+
+// Index is an in-memory vector index. It is safe for concurrent use.
+type Index struct {
+	// Embedder embeds the chunks and the queries. It is required.
+	Embedder Embedder
+	// Chunker splits the documents. Defaults to ByTokens(256, 32).
+	Chunker Chunker
+
+	mu      sync.RWMutex
+	chunks  []Chunk
+	vectors [][]float32
+}
+
+// Add splits the document text in chunks, embeds and indexes them.
+func (x *Index) Add(ctx context.Context, source, text string) error {
+	if x.Embedder == nil {
+		return errors.New("field Embedder: required")
+	}
+	chunker := x.Chunker
+	if chunker == nil {
+		chunker = ByTokens(256, 32)
+	}
+	texts := chunker(text)
+	if len(texts) == 0 {
+		return nil
+	}
+	vecs, err := x.embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if len(x.vectors) != 0 && len(vecs[0]) != len(x.vectors[0]) {
+		return fmt.Errorf("embedding dimension %d doesn't match the index dimension %d", len(vecs[0]), len(x.vectors[0]))
+	}
+	for i := range texts {
+		x.chunks = append(x.chunks, Chunk{Source: source, Index: i, Text: texts[i]})
+	}
+	x.vectors = append(x.vectors, vecs...)
+	return nil
+}
+
+// Len returns the number of chunks indexed.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.chunks)
+}
+
+// Search returns the k chunks most similar to the query, the most similar first.
+func (x *Index) Search(ctx context.Context, query string, k int) ([]Match, error) {
+	if x.Embedder == nil {
+		return nil, errors.New("field Embedder: required")
+	}
+	if k <= 0 {
+		return nil, errors.New("k must be positive")
+	}
+	vecs, err := x.embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	q := vecs[0]
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if len(x.vectors) != 0 && len(q) != len(x.vectors[0]) {
+		return nil, fmt.Errorf("embedding dimension %d doesn't match the index dimension %d", len(q), len(x.vectors[0]))
+	}
+	out := make([]Match, len(x.chunks))
+	for i := range x.chunks {
+		out[i] = Match{Chunk: x.chunks[i], Score: dot(q, x.vectors[i])}
+	}
+	slices.SortStableFunc(out, func(a, b Match) int { return cmp.Compare(b.Score, a.Score) })
+	return out[:min(k, len(out))], nil
+}
+
+// Retrieve returns the k chunks most similar to the query as text documents, followed by the query itself.
+//
+// Use the result as the Requests of a genai.Message. Each document is named after the source and the chunk
+// index so citations can be traced back to the original document.
+func (x *Index) Retrieve(ctx context.Context, query string, k int) ([]genai.Request, error) {
+	matches, err := x.Search(ctx, query, k)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]genai.Request, 0, len(matches)+1)
+	for i := range matches {
+		out = append(out, genai.Request{Doc: genai.Doc{Filename: matches[i].filename(), Src: strings.NewReader(matches[i].Text)}})
+	}
+	return append(out, genai.Request{Text: query}), nil
+}
+
+// Rerank sorts the matches by relevance to the query with a reranker and returns the k most relevant.
+//
+// It is commonly used to refine a larger set of candidates returned by Search, e.g. with cohere.Client:
+//
+//	matches, err := x.Search(ctx, query, 50)
+//	...
+//	matches, err = rag.Rerank(ctx, c, query, matches, 5)
+func Rerank(ctx context.Context, r genai.ProviderRerank, query string, matches []Match, k int) ([]Match, error) {
+	if k <= 0 {
+		return nil, errors.New("k must be positive")
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	texts := make([]string, len(matches))
+	for i := range matches {
+		texts[i] = matches[i].Text
+	}
+	res, err := r.Rerank(ctx, query, texts, min(k, len(matches)))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Match, 0, len(res))
+	for _, rr := range res {
+		if rr.Index < 0 || rr.Index >= len(matches) {
+			return nil, fmt.Errorf("reranker returned an invalid index %d for %d documents", rr.Index, len(matches))
+		}
+		out = append(out, Match{Chunk: matches[rr.Index].Chunk, Score: rr.Score})
+	}
+	slices.SortStableFunc(out, func(a, b Match) int { return cmp.Compare(b.Score, a.Score) })
+	return out[:min(k, len(out))], nil
+}
+
+// filename returns a valid genai.Doc.Filename for the chunk.
+func (c *Chunk) filename() string {
+	name := filepath.Base(c.Source)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	return fmt.Sprintf("%s-%d.txt", name, c.Index)
+}
+
+// embed embeds the texts and normalizes the vectors so the cosine similarity is a dot product.
+func (x *Index) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vecs, err := x.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vecs), len(texts))
+	}
+	for i, v := range vecs {
+		if len(v) == 0 || len(v) != len(vecs[0]) {
+			return nil, fmt.Errorf("embedder returned an invalid vector #%d of dimension %d", i, len(v))
+		}
+		var n float64
+		for _, f := range v {
+			n += float64(f) * float64(f)
+		}
+		if n == 0 {
+			continue
+		}
+		n = math.Sqrt(n)
+		nv := make([]float32, len(v))
+		for j := range v {
+			nv[j] = float32(float64(v[j]) / n)
+		}
+		vecs[i] = nv
+	}
+	return vecs, nil
+}
+
+func dot(a, b []float32) float64 {
+	var s float64
+	for i := range a {
+		s += float64(a[i]) * float64(b[i])
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the retrieval helpers.
+
+package rag_test
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/rag"
+)
+
+func TestByTokens(t *testing.T) {
+	got := rag.ByTokens(3, 1)("a b c d e f g")
+	want := []string{"a b c", "c d e", "e f g"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got := rag.ByTokens(3, 1)("  "); len(got) != 0 {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestBySentences(t *testing.T) {
+	got := rag.BySentences(20)("One two. Three four five!\nSix? A very long sentence that doesn't fit")
+	want := []string{"One two.", "Three four five!", "Six?", "A very long sentence that doesn't fit"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	x := rag.Index{Embedder: rag.EmbedderFunc(letters), Chunker: rag.BySentences(1)}
+	if err := x.Add(t.Context(), "docs/fruits.md", "Apples are red. Bananas are yellow. Kiwis are green."); err != nil {
+		t.Fatal(err)
+	}
+	if x.Len() != 3 {
+		t.Fatalf("got %d chunks", x.Len())
+	}
+	matches, err := x.Search(t.Context(), "bananas", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Text != "Bananas are yellow." || matches[0].Index != 1 {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+	reqs, err := x.Retrieve(t.Context(), "bananas", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := genai.Message{Requests: reqs}
+	if err := msg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 3 || reqs[0].Doc.Filename != "fruits-1.txt" || reqs[2].Text != "bananas" {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+	b, err := io.ReadAll(reqs[0].Doc.Src)
+	if err != nil || string(b) != "Bananas are yellow." {
+		t.Fatalf("got %q, %v", b, err)
+	}
+}
+
+func TestIndex_errors(t *testing.T) {
+	var x rag.Index
+	if err := x.Add(t.Context(), "a", "b"); err == nil || err.Error() != "field Embedder: required" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x.Embedder = rag.EmbedderFunc(func(ctx context.Context, texts []string) ([][]float32, error) {
+		return nil, nil
+	})
+	if err := x.Add(t.Context(), "a", "b"); err == nil || err.Error() != "embedder returned 0 vectors for 1 texts" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := x.Search(t.Context(), "a", 0); err == nil || err.Error() != "k must be positive" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRerank(t *testing.T) {
+	matches := []rag.Match{
+		{Chunk: rag.Chunk{Source: "a", Text: "Apples are red."}, Score: 0.9},
+		{Chunk: rag.Chunk{Source: "b", Text: "Bananas are yellow."}, Score: 0.8},
+		{Chunk: rag.Chunk{Source: "c", Text: "Kiwis are green."}, Score: 0.7},
+	}
+	got, err := rag.Rerank(t.Context(), &lengthReranker{}, "fruits", matches, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Source != "b" || got[0].Score != 19 || got[1].Source != "c" {
+		t.Fatalf("unexpected matches: %+v", got)
+	}
+	if _, err := rag.Rerank(t.Context(), &lengthReranker{}, "fruits", matches, 0); err == nil || err.Error() != "k must be positive" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// lengthReranker is a toy reranker favoring the longest documents.
+type lengthReranker struct{}
+
+func (l *lengthReranker) Rerank(ctx context.Context, query string, documents []string, topN int) ([]genai.RerankResult, error) {
+	out := make([]genai.RerankResult, len(documents))
+	for i, d := range documents {
+		out[i] = genai.RerankResult{Index: i, Score: float64(len(d))}
+	}
+	slices.SortFunc(out, func(a, b genai.RerankResult) int { return int(b.Score - a.Score) })
+	return out[:topN], nil
+}
+
+// letters is a toy embedder counting the letters.
+func letters(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, s := range texts {
+		out[i] = make([]float32, 26)
+		for _, r := range strings.ToLower(s) {
+			if r >= 'a' && r <= 'z' {
+				out[i][r-'a']++
+			}
+		}
+	}
+	return out, nil
+}
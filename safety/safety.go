@@ -0,0 +1,345 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package safety implements a content safety policy combining provider moderation and local rules.
+//
+// A Policy checks text against user-defined regexp and keyword rules and an optional Moderator, like the
+// OpenAI moderation endpoint. Each match triggers an Action: flag it, redact it or block the request. Every
+// match is reported to Policy.Audit to build an audit trail.
+//
+// Wrap a provider client with Provider to enforce a Policy on its input and output. Use a different Policy
+// per client to configure them independently.
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/maruel/genai"
+)
+
+// Action is the action taken when content matches a rule.
+type Action int
+
+// Action values, from the least to the most severe.
+const (
+	// ActionFlag only reports the match to the audit trail.
+	ActionFlag Action = iota
+	// ActionRedact replaces the matched text.
+	ActionRedact
+	// ActionBlock fails the request with an *ErrBlocked.
+	ActionBlock
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionFlag:
+		return "flag"
+	case ActionRedact:
+		return "redact"
+	case ActionBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+}
+
+// Validate implements genai.Validatable.
+func (a Action) Validate() error {
+	if a < ActionFlag || a > ActionBlock {
+		return fmt.Errorf("invalid action %s", a)
+	}
+	return nil
+}
+
+// Direction is whether the content is sent to or received from the model.
+type Direction string
+
+// Direction values.
+const (
+	Input  Direction = "input"
+	Output Direction = "output"
+)
+
+// Rule is a local rule.
+type Rule struct {
+	// Name identifies the rule in the audit trail.
+	Name string
+	// Pattern matches the text. Either Pattern or Keywords must be set.
+	Pattern *regexp.Regexp
+	// Keywords are matched case-insensitively.
+	Keywords []string
+	// Action is the action to take on a match.
+	Action Action
+	// Replacement is the text to replace matches with when Action is ActionRedact. Defaults to "[REDACTED]".
+	Replacement string
+
+	_ struct{}
+}
+
+// Validate implements genai.Validatable.
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return errors.New("field Name: required")
+	}
+	if r.Pattern == nil && len(r.Keywords) == 0 {
+		return errors.New("field Pattern or Keywords: required")
+	}
+	for i, k := range r.Keywords {
+		if k == "" {
+			return fmt.Errorf("field Keywords: item #%d: must not be empty", i)
+		}
+	}
+	if err := r.Action.Validate(); err != nil {
+		return fmt.Errorf("field Action: %w", err)
+	}
+	return nil
+}
+
+// Moderator classifies text with a moderation model.
+//
+// The openaichat and openairesponses clients implement it.
+type Moderator interface {
+	// Moderate returns the categories the text is flagged for, or none if it is acceptable.
+	Moderate(ctx context.Context, text string) ([]string, error)
+}
+
+// ModeratorFunc adapts a function to a Moderator.
+type ModeratorFunc func(ctx context.Context, text string) ([]string, error)
+
+// Moderate implements Moderator.
+func (f ModeratorFunc) Moderate(ctx context.Context, text string) ([]string, error) {
+	return f(ctx, text)
+}
+
+// Event is an entry in the audit trail.
+type Event struct {
+	Time      time.Time
+	Direction Direction
+	// Rule is the name of the rule that matched. It is "moderation" for the Moderator.
+	Rule   string
+	Action Action
+	// Match is the matched text. It is the whole text for the Moderator.
+	Match string
+	// Categories are the categories flagged by the Moderator.
+	Categories []string
+}
+
+func (e *Event) String() string {
+	s := fmt.Sprintf("%s %s: %s", e.Direction, e.Rule, e.Action)
+	if len(e.Categories) != 0 {
+		s += " (" + strings.Join(e.Categories, ", ") + ")"
+	}
+	return s
+}
+
+// ErrBlocked is returned when content is blocked by the Policy.
+type ErrBlocked struct {
+	Event Event
+}
+
+func (e *ErrBlocked) Error() string {
+	return "content blocked by safety policy: " + e.Event.String()
+}
+
+// Policy is a content safety policy.
+type Policy struct {
+	// Rules are the local rules, applied in order.
+	Rules []Rule
+	// Moderator is optional. It is called after the local rules, on the redacted text.
+	Moderator Moderator
+	// ModeratorAction is the action to take when the Moderator flags the text. ActionRedact replaces the whole
+	// text.
+	ModeratorAction Action
+	// SkipInput and SkipOutput disable the checks in one direction.
+	SkipInput  bool
+	SkipOutput bool
+	// Audit is called for each match. It must be safe for concurrent use.
+	Audit func(Event)
+
+	_ struct{}
+}
+
+// Validate implements genai.Validatable.
+func (p *Policy) Validate() error {
+	for i := range p.Rules {
+		if err := p.Rules[i].Validate(); err != nil {
+			return fmt.Errorf("field Rules: item #%d: %w", i, err)
+		}
+	}
+	if err := p.ModeratorAction.Validate(); err != nil {
+		return fmt.Errorf("field ModeratorAction: %w", err)
+	}
+	return nil
+}
+
+// Check applies the policy to text and returns the text with redactions applied.
+//
+// It returns an *ErrBlocked when a rule with ActionBlock matches.
+func (p *Policy) Check(ctx context.Context, d Direction, text string) (string, error) {
+	if text == "" {
+		return text, nil
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		for _, loc := range r.find(text) {
+			e := Event{Time: time.Now(), Direction: d, Rule: r.Name, Action: r.Action, Match: text[loc[0]:loc[1]]}
+			p.audit(e)
+			if r.Action == ActionBlock {
+				return text, &ErrBlocked{Event: e}
+			}
+		}
+		if r.Action == ActionRedact {
+			text = r.redact(text)
+		}
+	}
+	if p.Moderator != nil {
+		cats, err := p.Moderator.Moderate(ctx, text)
+		if err != nil {
+			return text, fmt.Errorf("moderation failed: %w", err)
+		}
+		if len(cats) != 0 {
+			e := Event{Time: time.Now(), Direction: d, Rule: "moderation", Action: p.ModeratorAction, Match: text, Categories: cats}
+			p.audit(e)
+			switch p.ModeratorAction {
+			case ActionBlock:
+				return text, &ErrBlocked{Event: e}
+			case ActionRedact:
+				text = "[REDACTED]"
+			}
+		}
+	}
+	return text, nil
+}
+
+// Provider wraps a genai.Provider and enforces Policy.
+//
+// Only the text of the last message is checked on input, as the previous messages were checked on earlier
+// calls. When streaming, the output is checked once the stream completes: fragments were already yielded, so
+// redactions only apply to the returned Result and blocking returns an error from the finish function.
+type Provider struct {
+	genai.Provider
+	Policy *Policy
+}
+
+// GenSync implements genai.Provider.
+func (p *Provider) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	msgs, err := p.checkInput(ctx, msgs)
+	if err != nil {
+		return genai.Result{}, err
+	}
+	res, err := p.Provider.GenSync(ctx, msgs, opts...)
+	if err != nil {
+		return res, err
+	}
+	return res, p.checkOutput(ctx, &res)
+}
+
+// GenStream implements genai.Provider.
+func (p *Provider) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	msgs, err := p.checkInput(ctx, msgs)
+	if err != nil {
+		return func(yield func(genai.Reply) bool) {}, func() (genai.Result, error) { return genai.Result{}, err }
+	}
+	fragments, finish := p.Provider.GenStream(ctx, msgs, opts...)
+	return fragments, func() (genai.Result, error) {
+		res, err := finish()
+		if err != nil {
+			return res, err
+		}
+		return res, p.checkOutput(ctx, &res)
+	}
+}
+
+// Unwrap implements genai.ProviderUnwrap.
+func (p *Provider) Unwrap() genai.Provider {
+	return p.Provider
+}
+
+// checkInput returns msgs with the last message redacted, without modifying the caller's messages.
+func (p *Provider) checkInput(ctx context.Context, msgs genai.Messages) (genai.Messages, error) {
+	if p.Policy.SkipInput || len(msgs) == 0 {
+		return msgs, nil
+	}
+	last := &msgs[len(msgs)-1]
+	var reqs []genai.Request
+	for i := range last.Requests {
+		s, err := p.Policy.Check(ctx, Input, last.Requests[i].Text)
+		if err != nil {
+			return nil, err
+		}
+		if s != last.Requests[i].Text {
+			if reqs == nil {
+				reqs = append([]genai.Request(nil), last.Requests...)
+			}
+			reqs[i].Text = s
+		}
+	}
+	if reqs == nil {
+		return msgs, nil
+	}
+	out := append(genai.Messages(nil), msgs...)
+	out[len(out)-1].Requests = reqs
+	return out, nil
+}
+
+func (p *Provider) checkOutput(ctx context.Context, res *genai.Result) error {
+	if p.Policy.SkipOutput {
+		return nil
+	}
+	for i := range res.Replies {
+		s, err := p.Policy.Check(ctx, Output, res.Replies[i].Text)
+		if err != nil {
+			return err
+		}
+		res.Replies[i].Text = s
+	}
+	return nil
+}
+
+func (p *Policy) audit(e Event) {
+	if p.Audit != nil {
+		p.Audit(e)
+	}
+}
+
+// find returns the locations of the matches.
+func (r *Rule) find(text string) [][]int {
+	var out [][]int
+	if r.Pattern != nil {
+		out = r.Pattern.FindAllStringIndex(text, -1)
+	}
+	if len(r.Keywords) != 0 {
+		out = append(out, r.keywords().FindAllStringIndex(text, -1)...)
+	}
+	return out
+}
+
+func (r *Rule) redact(text string) string {
+	repl := r.Replacement
+	if repl == "" {
+		repl = "[REDACTED]"
+	}
+	if r.Pattern != nil {
+		text = r.Pattern.ReplaceAllLiteralString(text, repl)
+	}
+	if len(r.Keywords) != 0 {
+		text = r.keywords().ReplaceAllLiteralString(text, repl)
+	}
+	return text
+}
+
+// keywords returns a case-insensitive regexp matching any of the keywords.
+func (r *Rule) keywords() *regexp.Regexp {
+	q := make([]string, len(r.Keywords))
+	for i, k := range r.Keywords {
+		q[i] = regexp.QuoteMeta(k)
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(q, "|"))
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the content safety policy.
+
+package safety_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/safety"
+	"github.com/maruel/genai/scoreboard"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	email := safety.Rule{Name: "email", Pattern: regexp.MustCompile(`[\w.]+@[\w.]+`), Action: safety.ActionRedact, Replacement: "<email>"}
+	secret := safety.Rule{Name: "secret", Keywords: []string{"Project X"}, Action: safety.ActionBlock}
+	watch := safety.Rule{Name: "watch", Keywords: []string{"competitor"}}
+	mod := safety.ModeratorFunc(func(ctx context.Context, text string) ([]string, error) {
+		if strings.Contains(text, "hate") {
+			return []string{"hate"}, nil
+		}
+		return nil, nil
+	})
+	tests := []struct {
+		name   string
+		action safety.Action
+		in     string
+		want   string
+		events []string
+		errMsg string
+	}{
+		{name: "clean", in: "hello", want: "hello"},
+		{name: "redact", in: "mail a@b.com or c@d.org", want: "mail <email> or <email>", events: []string{"input email: redact", "input email: redact"}},
+		{name: "flag", in: "the Competitor is", want: "the Competitor is", events: []string{"input watch: flag"}},
+		{
+			name:   "block",
+			in:     "about project x",
+			events: []string{"input secret: block"},
+			errMsg: "content blocked by safety policy: input secret: block",
+		},
+		{
+			name:   "moderation_block",
+			action: safety.ActionBlock,
+			in:     "hate",
+			events: []string{"input moderation: block (hate)"},
+			errMsg: "content blocked by safety policy: input moderation: block (hate)",
+		},
+		{name: "moderation_redact", action: safety.ActionRedact, in: "hate", want: "[REDACTED]", events: []string{"input moderation: redact (hate)"}},
+		{name: "moderation_flag", in: "hate", want: "hate", events: []string{"input moderation: flag (hate)"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var events []string
+			p := &safety.Policy{
+				Rules:           []safety.Rule{email, secret, watch},
+				Moderator:       mod,
+				ModeratorAction: tc.action,
+				Audit:           func(e safety.Event) { events = append(events, e.String()) },
+			}
+			if err := p.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Check(t.Context(), safety.Input, tc.in)
+			if tc.errMsg != "" {
+				if err == nil || err.Error() != tc.errMsg {
+					t.Fatalf("error mismatch\nwant %q\ngot  %v", tc.errMsg, err)
+				}
+				if _, ok := errors.AsType[*safety.ErrBlocked](err); !ok {
+					t.Fatalf("expected *ErrBlocked, got %T", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tc.want {
+					t.Fatalf("got %q, want %q", got, tc.want)
+				}
+			}
+			if !slices.Equal(events, tc.events) {
+				t.Fatalf("events mismatch\nwant %q\ngot  %q", tc.events, events)
+			}
+		})
+	}
+	t.Run("moderation_error", func(t *testing.T) {
+		p := &safety.Policy{Moderator: safety.ModeratorFunc(func(ctx context.Context, text string) ([]string, error) {
+			return nil, errors.New("boom")
+		})}
+		if _, err := p.Check(t.Context(), safety.Output, "hi"); err == nil || err.Error() != "moderation failed: boom" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     safety.Policy
+		errMsg string
+	}{
+		{"Name", safety.Policy{Rules: []safety.Rule{{Keywords: []string{"a"}}}}, "field Rules: item #0: field Name: required"},
+		{"Pattern", safety.Policy{Rules: []safety.Rule{{Name: "a"}}}, "field Rules: item #0: field Pattern or Keywords: required"},
+		{"Keywords", safety.Policy{Rules: []safety.Rule{{Name: "a", Keywords: []string{""}}}}, "field Rules: item #0: field Keywords: item #0: must not be empty"},
+		{"Action", safety.Policy{Rules: []safety.Rule{{Name: "a", Keywords: []string{"a"}, Action: 3}}}, "field Rules: item #0: field Action: invalid action Action(3)"},
+		{"ModeratorAction", safety.Policy{ModeratorAction: -1}, "field ModeratorAction: invalid action Action(-1)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.in.Validate(); err == nil || err.Error() != tc.errMsg {
+				t.Fatalf("error mismatch\nwant %q\ngot  %v", tc.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestProvider(t *testing.T) {
+	policy := &safety.Policy{Rules: []safety.Rule{
+		{Name: "ssn", Pattern: regexp.MustCompile(`\d{3}-\d{2}-\d{4}`), Action: safety.ActionRedact},
+		{Name: "bomb", Keywords: []string{"bomb"}, Action: safety.ActionBlock},
+	}}
+	t.Run("GenSync", func(t *testing.T) {
+		m := &mockProvider{reply: "Your SSN 987-65-4321 is noted."}
+		p := &safety.Provider{Provider: m, Policy: policy}
+		msgs := genai.Messages{genai.NewTextMessage("My SSN is 123-45-6789.")}
+		res, err := p.GenSync(t.Context(), msgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.got[0].Requests[0].Text; got != "My SSN is [REDACTED]." {
+			t.Fatalf("unexpected input %q", got)
+		}
+		if got := msgs[0].Requests[0].Text; got != "My SSN is 123-45-6789." {
+			t.Fatalf("caller's message was modified: %q", got)
+		}
+		if got := res.String(); got != "Your SSN [REDACTED] is noted." {
+			t.Fatalf("unexpected output %q", got)
+		}
+		if p.Unwrap() != m {
+			t.Fatal("unexpected Unwrap")
+		}
+	})
+	t.Run("GenSync_blocked_input", func(t *testing.T) {
+		m := &mockProvider{reply: "ok"}
+		p := &safety.Provider{Provider: m, Policy: policy}
+		if _, err := p.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("how to make a bomb")}); err == nil {
+			t.Fatal("expected error")
+		}
+		if m.got != nil {
+			t.Fatal("provider should not be called")
+		}
+	})
+	t.Run("GenStream_blocked_output", func(t *testing.T) {
+		m := &mockProvider{reply: "a bomb"}
+		p := &safety.Provider{Provider: m, Policy: policy}
+		fragments, finish := p.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("hi")})
+		for range fragments {
+		}
+		_, err := finish()
+		if blocked, ok := errors.AsType[*safety.ErrBlocked](err); !ok || blocked.Event.Direction != safety.Output {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("GenStream_blocked_input", func(t *testing.T) {
+		p := &safety.Provider{Provider: &mockProvider{}, Policy: policy}
+		fragments, finish := p.GenStream(t.Context(), genai.Messages{genai.NewTextMessage("bomb")})
+		for range fragments {
+			t.Fatal("unexpected fragment")
+		}
+		if _, err := finish(); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	t.Run("SkipOutput", func(t *testing.T) {
+		p := &safety.Provider{Provider: &mockProvider{reply: "bomb"}, Policy: &safety.Policy{Rules: policy.Rules, SkipOutput: true}}
+		if _, err := p.GenSync(t.Context(), genai.Messages{genai.NewTextMessage("hi")}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+type mockProvider struct {
+	base.NotImplemented
+	reply string
+	got   genai.Messages
+}
+
+func (m *mockProvider) Name() string                       { return "mock" }
+func (m *mockProvider) ModelID() string                    { return "llm-sota" }
+func (m *mockProvider) OutputModalities() genai.Modalities { return nil }
+func (m *mockProvider) HTTPClient() *http.Client           { return nil }
+func (m *mockProvider) Scoreboard() scoreboard.Score       { return scoreboard.Score{} }
+
+func (m *mockProvider) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	m.got = msgs
+	return genai.Result{Message: genai.Message{Replies: []genai.Reply{{Text: m.reply}}}}, nil
+}
+
+func (m *mockProvider) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	res, err := m.GenSync(ctx, msgs, opts...)
+	return func(yield func(genai.Reply) bool) {
+			for _, r := range res.Replies {
+				if !yield(r) {
+					return
+				}
+			}
+		}, func() (genai.Result, error) {
+			return res, err
+		}
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Retrieval and persistence of generated documents.
+
+package genai
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maruel/genai/internal"
+	"github.com/maruel/genai/internal/bb"
+)
+
+// SaveDocs writes every Doc reply to a file in dir and returns the paths written, in reply order.
+//
+// Docs referenced by URL are fetched with c, which should be the provider's HTTPClient() since some providers
+// require authentication to fetch the generated content. If c is nil, http.DefaultClient is used. Fetches
+// failing with a transient error (HTTP 429, 5xx or a network error) are retried a few times.
+//
+// name is a template for the file names. It supports the placeholders "{index}" for the 1-based index of the
+// document among the Doc replies, "{name}" for the Doc filename without its extension and "{ext}" for the
+// extension including the leading dot. It defaults to "{name}{ext}". The extension is derived from the actual
+// content when it doesn't match the Doc filename, e.g. a PNG image named "content.jpg" is saved as
+// "content.png".
+//
+// Existing files are overwritten. It is an error if two Doc replies resolve to the same file name; use
+// "{index}" in this case.
+func (m *Message) SaveDocs(ctx context.Context, c *http.Client, dir, name string) ([]string, error) {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	if name == "" {
+		name = "{name}{ext}"
+	}
+	var out []string
+	seen := map[string]int{}
+	for i := range m.Replies {
+		d := &m.Replies[i].Doc
+		if d.IsZero() {
+			continue
+		}
+		p, err := saveDoc(ctx, c, d, dir, name, len(out)+1, seen)
+		if err != nil {
+			return out, fmt.Errorf("reply #%d: %w", i, err)
+		}
+		seen[p] = i
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// FetchDocs downloads every Doc reply referenced by URL and replaces the URL with the content inline in Src.
+//
+// This is useful for generated videos and images, whose URLs expire and may require authentication: c should
+// be the provider's HTTPClient(), which sets the authentication headers. If c is nil, http.DefaultClient is
+// used. Documents larger than maxSize bytes are rejected; 0 means no limit.
+//
+// The Doc filename is kept, or derived from the URL when not set.
+func (m *Message) FetchDocs(ctx context.Context, c *http.Client, maxSize int64) error {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	for i := range m.Replies {
+		d := &m.Replies[i].Doc
+		if d.URL == "" {
+			continue
+		}
+		resp, err := fetchDoc(ctx, c, d.URL)
+		if err != nil {
+			return fmt.Errorf("reply #%d: %w", i, err)
+		}
+		var r io.Reader = resp.Body
+		if maxSize > 0 {
+			r = io.LimitReader(r, maxSize+1)
+		}
+		b, err := io.ReadAll(r)
+		_ = resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("reply #%d: failed to read %s: %w", i, d.URL, err)
+		}
+		if maxSize > 0 && int64(len(b)) > maxSize {
+			return fmt.Errorf("reply #%d: %s is larger than %d bytes", i, d.URL, maxSize)
+		}
+		if d.Filename == "" {
+			d.Filename = path.Base(strings.SplitN(d.URL, "?", 2)[0])
+		}
+		d.URL = ""
+		d.Src = &bb.BytesBuffer{D: b}
+	}
+	return nil
+}
+
+func saveDoc(ctx context.Context, c *http.Client, d *Doc, dir, tmpl string, index int, seen map[string]int) (string, error) {
+	var src io.Reader
+	contentType := ""
+	switch {
+	case d.URL != "":
+		resp, err := fetchDoc(ctx, c, d.URL)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		src = resp.Body
+		contentType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	case d.Src != nil:
+		if _, err := d.Src.Seek(0, io.SeekStart); err != nil {
+			return "", fmt.Errorf("failed to seek data at beginning: %w", err)
+		}
+		src = d.Src
+	default:
+		return "", errors.New("field Src or URL is required")
+	}
+
+	// Sniff the content to fix up the extension when it doesn't match.
+	br := bufio.NewReaderSize(src, 512)
+	head, _ := br.Peek(512)
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	}
+	base := d.GetFilename()
+	if base == "" && d.URL != "" {
+		base = path.Base(strings.SplitN(d.URL, "?", 2)[0])
+	}
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" || stem == "." || stem == "/" {
+		stem = "content"
+	}
+	// Sniffing can't tell text formats apart, so keep the extension for text.
+	cur, _, _ := mime.ParseMediaType(internal.MimeByExt(ext))
+	if contentType != "" && contentType != "application/octet-stream" && cur != contentType &&
+		!(strings.HasPrefix(cur, "text/") && strings.HasPrefix(contentType, "text/")) {
+		if e := internal.ExtByMime(contentType); e != "" {
+			ext = e
+		}
+	}
+
+	n := strings.NewReplacer("{index}", strconv.Itoa(index), "{name}", stem, "{ext}", ext).Replace(tmpl)
+	if n == "" || n == "." || n == ".." || filepath.Base(n) != n {
+		return "", fmt.Errorf("invalid file name %q generated from template %q", n, tmpl)
+	}
+	p := filepath.Join(dir, n)
+	if j, ok := seen[p]; ok {
+		return "", fmt.Errorf("file name %q conflicts with reply #%d; add {index} to the template", n, j)
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(f, br); err != nil {
+		_ = f.Close()
+		_ = os.Remove(p)
+		return "", fmt.Errorf("failed to write %s: %w", p, err)
+	}
+	if err = f.Close(); err != nil {
+		_ = os.Remove(p)
+		return "", err
+	}
+	return p, nil
+}
+
+// fetchDoc fetches the URL, retrying on transient errors.
+func fetchDoc(ctx context.Context, c *http.Client, u string) (*http.Response, error) {
+	const attempts = 3
+	delay := 250 * time.Millisecond
+	for i := 0; ; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.Do(req)
+		if err == nil {
+			if resp.StatusCode == http.StatusOK {
+				return resp, nil
+			}
+			_ = resp.Body.Close()
+			err = fmt.Errorf("failed to fetch %s: http %d", u, resp.StatusCode)
+			if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+				return nil, err
+			}
+		}
+		if ctx.Err() != nil || i == attempts-1 {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for persisting generated documents.
+
+package genai
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai/internal/bb"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestMessage_SaveDocs(t *testing.T) {
+	const png = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	t.Run("inline", func(t *testing.T) {
+		dir := t.TempDir()
+		m := Message{Replies: []Reply{
+			{Text: "Here you go"},
+			{Doc: Doc{Filename: "content.jpg", Src: &bb.BytesBuffer{D: []byte(png)}}},
+			{Doc: Doc{Filename: "notes.md", Src: &bb.BytesBuffer{D: []byte("# Title\n")}}},
+		}}
+		got, err := m.SaveDocs(t.Context(), nil, dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{filepath.Join(dir, "content.png"), filepath.Join(dir, "notes.md")}
+		if !slices.Equal(got, want) {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+		if b, err := os.ReadFile(want[0]); err != nil || string(b) != png {
+			t.Fatalf("unexpected content %q: %v", b, err)
+		}
+	})
+	t.Run("URL", func(t *testing.T) {
+		dir := t.TempDir()
+		calls := 0
+		c := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"image/webp"}},
+				Body:       io.NopCloser(strings.NewReader("RIFF")),
+				Request:    r,
+			}, nil
+		})}
+		m := Message{Replies: []Reply{
+			{Doc: Doc{URL: "https://example.com/a/husky?sig=1"}},
+			{Doc: Doc{Filename: "husky.png", Src: &bb.BytesBuffer{D: []byte(png)}}},
+		}}
+		got, err := m.SaveDocs(t.Context(), c, dir, "out-{index}-{name}{ext}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{filepath.Join(dir, "out-1-husky.webp"), filepath.Join(dir, "out-2-husky.png")}
+		if !slices.Equal(got, want) {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+		if calls != 2 {
+			t.Fatalf("expected one retry, got %d calls", calls)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		c := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+		})}
+		tests := []struct {
+			name   string
+			m      Message
+			tmpl   string
+			errMsg string
+		}{
+			{
+				name:   "conflict",
+				m:      Message{Replies: []Reply{{Doc: Doc{Filename: "a.png", Src: &bb.BytesBuffer{D: []byte(png)}}}, {Doc: Doc{Filename: "a.png", Src: &bb.BytesBuffer{D: []byte(png)}}}}},
+				errMsg: `reply #1: file name "a.png" conflicts with reply #0; add {index} to the template`,
+			},
+			{
+				name:   "path",
+				m:      Message{Replies: []Reply{{Doc: Doc{Filename: "a.png", Src: &bb.BytesBuffer{D: []byte(png)}}}}},
+				tmpl:   "../{name}{ext}",
+				errMsg: `reply #0: invalid file name "../a.png" generated from template "../{name}{ext}"`,
+			},
+			{
+				name:   "not found",
+				m:      Message{Replies: []Reply{{Doc: Doc{URL: "https://example.com/a.png"}}}},
+				errMsg: "reply #0: failed to fetch https://example.com/a.png: http 404",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, err := tt.m.SaveDocs(t.Context(), c, t.TempDir(), tt.tmpl); err == nil || err.Error() != tt.errMsg {
+					t.Fatalf("error mismatch\nwant %q\ngot  %q", tt.errMsg, err)
+				}
+			})
+		}
+	})
+}
+
+func TestMessage_FetchDocs(t *testing.T) {
+	c := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("X-Goog-Api-Key") != "key" {
+			return &http.Response{StatusCode: http.StatusForbidden, Body: http.NoBody, Request: r}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("video")), Request: r}, nil
+	})}
+	auth := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r.Header.Set("X-Goog-Api-Key", "key")
+		return c.Transport.RoundTrip(r)
+	})}
+	t.Run("valid", func(t *testing.T) {
+		m := Message{Replies: []Reply{
+			{Text: "Here you go"},
+			{Doc: Doc{Filename: "content.mp4", URL: "https://example.com/files/abc:download?alt=media"}},
+			{Doc: Doc{URL: "https://example.com/files/b.mp4?alt=media"}},
+		}}
+		if err := m.FetchDocs(t.Context(), auth, 10); err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range []string{"content.mp4", "b.mp4"} {
+			d := &m.Replies[i+1].Doc
+			if d.URL != "" || d.Filename != want {
+				t.Fatalf("#%d: unexpected doc %+v", i, d)
+			}
+			if b, err := io.ReadAll(d.Src); err != nil || string(b) != "video" {
+				t.Fatalf("#%d: unexpected content %q, %v", i, b, err)
+			}
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			c       *http.Client
+			maxSize int64
+			errMsg  string
+		}{
+			{"unauthenticated", c, 0, "reply #0: failed to fetch https://example.com/a.mp4: http 403"},
+			{"maxSize", auth, 4, "reply #0: https://example.com/a.mp4 is larger than 4 bytes"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				m := Message{Replies: []Reply{{Doc: Doc{URL: "https://example.com/a.mp4"}}}}
+				if err := m.FetchDocs(t.Context(), tc.c, tc.maxSize); err == nil || err.Error() != tc.errMsg {
+					t.Fatalf("error mismatch\nwant %q\ngot  %v", tc.errMsg, err)
+				}
+			})
+		}
+	})
+}
@@ -676,6 +676,87 @@ func CompareScenarios(a, b Scenario) int { //nolint:gocritic // hugeParam: requi
 	return 0
 }
 
+// Requirements are the features an application needs from a model.
+//
+// Use Match to select a scenario in a Score, or providers.FindProviders to search all the providers. Only
+// features that were actually tested are matched; flaky features don't match.
+type Requirements struct {
+	// In are the input modalities, e.g. ModalityImage for vision.
+	In []Modality
+	// Out are the output modalities. Defaults to any.
+	Out []Modality
+	// Stream requires the features to be supported with GenStream instead of GenSync.
+	Stream bool
+	// Reason requires a reasoning model.
+	Reason bool
+
+	Tools            bool
+	ToolCallRequired bool
+	WebSearch        bool
+	WebFetch         bool
+	JSON             bool
+	JSONSchema       bool
+	Citations        bool
+	Seed             bool
+	TopLogprobs      bool
+	MaxTokens        bool
+	StopSequence     bool
+	ReportRateLimits bool
+
+	_ struct{}
+}
+
+// Validate returns an error if the Requirements are not correctly configured.
+func (r *Requirements) Validate() error {
+	for _, m := range r.In {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("field In: %w", err)
+		}
+	}
+	for _, m := range r.Out {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("field Out: %w", err)
+		}
+	}
+	return nil
+}
+
+// Match returns true if the scenario meets all the requirements.
+func (r *Requirements) Match(sc *Scenario) bool {
+	if sc.Untested() || (r.Reason && !sc.Reason) {
+		return false
+	}
+	for _, m := range r.In {
+		if _, ok := sc.In[m]; !ok {
+			return false
+		}
+	}
+	for _, m := range r.Out {
+		if _, ok := sc.Out[m]; !ok {
+			return false
+		}
+	}
+	f := sc.GenSync
+	if r.Stream {
+		f = sc.GenStream
+	}
+	if f == nil {
+		return false
+	}
+	return (!r.Tools || f.Tools == True) &&
+		(!r.ToolCallRequired || f.ToolCallRequired) &&
+		(!r.WebSearch || f.WebSearch) &&
+		(!r.WebFetch || f.WebFetch) &&
+		(!r.JSON || f.JSON) &&
+		(!r.JSONSchema || f.JSONSchema) &&
+		(!r.Citations || f.Citations) &&
+		(!r.Seed || f.Seed) &&
+		(!r.TopLogprobs || f.TopLogprobs) &&
+		(!r.MaxTokens || f.MaxTokens) &&
+		(!r.StopSequence || f.StopSequence) &&
+		(!r.ReportRateLimits || f.ReportRateLimits)
+}
+
 // TestdataFiles embeds the testdata/ directory for use in smoke tests.
 //
 // They are the canonical data to be used to declare the supported modalities.
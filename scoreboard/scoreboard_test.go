@@ -302,6 +302,57 @@ func TestScenario(t *testing.T) {
 	})
 }
 
+func TestRequirements(t *testing.T) {
+	text := map[Modality]ModalCapability{ModalityText: {Inline: true}}
+	vision := map[Modality]ModalCapability{ModalityText: {Inline: true}, ModalityImage: {Inline: true}}
+	sc := &Scenario{
+		Models:    []string{"m"},
+		Reason:    true,
+		In:        vision,
+		Out:       text,
+		GenSync:   &Functionality{Tools: True, JSONSchema: true, Seed: true},
+		GenStream: &Functionality{Tools: Flaky, JSONSchema: true},
+	}
+	tests := []struct {
+		name string
+		req  Requirements
+		want bool
+	}{
+		{"empty", Requirements{}, true},
+		{"tools", Requirements{Tools: true}, true},
+		{"tools_stream_flaky", Requirements{Tools: true, Stream: true}, false},
+		{"json_schema_stream", Requirements{JSONSchema: true, Stream: true}, true},
+		{"seed_stream", Requirements{Seed: true, Stream: true}, false},
+		{"vision", Requirements{In: []Modality{ModalityImage}, Tools: true}, true},
+		{"audio", Requirements{In: []Modality{ModalityAudio}}, false},
+		{"image_out", Requirements{Out: []Modality{ModalityImage}}, false},
+		{"reason", Requirements{Reason: true}, true},
+		{"web_search", Requirements{WebSearch: true}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.req.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tc.req.Match(sc); got != tc.want {
+				t.Fatalf("Match() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+	t.Run("untested", func(t *testing.T) {
+		r := Requirements{}
+		if r.Match(&Scenario{Models: []string{"m"}}) {
+			t.Fatal("untested scenarios must not match")
+		}
+	})
+	t.Run("Validate", func(t *testing.T) {
+		r := Requirements{In: []Modality{"smell"}}
+		if err := r.Validate(); err == nil || err.Error() != `field In: invalid Modality: "smell"` {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
 func TestScore(t *testing.T) {
 	t.Run("Validate", func(t *testing.T) {
 		tests := []*Score{
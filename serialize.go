@@ -0,0 +1,93 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Versioned serialization of conversations.
+
+package genai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// FormatVersion is the version of the format written by Encode.
+//
+// It is incremented on incompatible changes. Decode refuses data written with a newer version.
+const FormatVersion = 1
+
+// Serializable are the types supported by Encode and Decode.
+type Serializable interface {
+	Messages | Message | Result | ToolCall
+}
+
+// Encode writes v as JSON tagged with FormatVersion, so a conversation can be stored, e.g. in a database, and
+// reloaded with Decode.
+//
+// The content of Doc.Src is inlined as base64 and Doc.URL is kept as an external reference. Opaque fields are
+// kept as is, so the conversation can be continued with the same provider after being reloaded.
+func Encode[T Serializable](w io.Writer, v *T) error {
+	e := json.NewEncoder(w)
+	e.SetEscapeHTML(false)
+	return e.Encode(&struct {
+		Version int `json:"version"`
+		Data    *T  `json:"data"`
+	}{Version: FormatVersion, Data: v})
+}
+
+// Decode reads data written by Encode into v, validating it.
+func Decode[T Serializable](r io.Reader, v *T) error {
+	var e struct {
+		Version int             `json:"version"`
+		Data    json.RawMessage `json:"data"`
+	}
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&e); err != nil {
+		return err
+	}
+	if e.Version == 0 {
+		return errors.New("field version: required")
+	}
+	if e.Version > FormatVersion {
+		return fmt.Errorf("unsupported format version %d, max %d", e.Version, FormatVersion)
+	}
+	if len(e.Data) == 0 {
+		return errors.New("field data: required")
+	}
+	return json.Unmarshal(e.Data, v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// It is required since the one from the embedded Message would otherwise be used, ignoring the other fields.
+func (r *Result) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	var rest struct {
+		Usage      Usage
+		Logprobs   [][]Logprob
+		Candidates []Message
+	}
+	if err := json.Unmarshal(b, &rest); err != nil {
+		return err
+	}
+	delete(raw, "Usage")
+	delete(raw, "Logprobs")
+	delete(raw, "Candidates")
+	m, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(m, &r.Message); err != nil {
+		return err
+	}
+	r.Usage = rest.Usage
+	r.Logprobs = rest.Logprobs
+	r.Candidates = rest.Candidates
+	return r.Validate()
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package genai
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	t.Run("Messages", func(t *testing.T) {
+		in := Messages{
+			{Requests: []Request{{Text: "describe"}, {Doc: Doc{Filename: "a.png", Src: strings.NewReader("PNG")}}, {Doc: Doc{URL: "https://example.com/b.jpg"}}}},
+			{Replies: []Reply{{Reasoning: "hmm", Opaque: map[string]any{"signature": "abc"}}, {ToolCall: ToolCall{ID: "1", Name: "tool", Arguments: "{}"}}}},
+			{ToolCallResults: []ToolCallResult{{ID: "1", Name: "tool", Result: "42"}}},
+		}
+		buf := bytes.Buffer{}
+		if err := Encode(&buf, &in); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(buf.String(), `{"version":1,"data":[`) {
+			t.Fatalf("unexpected encoding %s", buf.String())
+		}
+		var out Messages
+		if err := Decode(&buf, &out); err != nil {
+			t.Fatal(err)
+		}
+		if len(out) != 3 {
+			t.Fatalf("got %d messages", len(out))
+		}
+		_, data, err := out[0].Requests[1].Doc.Read(1024)
+		if err != nil || string(data) != "PNG" {
+			t.Fatalf("got %q, %v", data, err)
+		}
+		if got := out[0].Requests[2].Doc.URL; got != "https://example.com/b.jpg" {
+			t.Fatalf("got %q", got)
+		}
+		if got := out[1].Replies[0].Opaque["signature"]; got != "abc" {
+			t.Fatalf("got %v", got)
+		}
+		if got := out[2].ToolCallResults[0].Result; got != "42" {
+			t.Fatalf("got %q", got)
+		}
+	})
+	t.Run("Result", func(t *testing.T) {
+		in := Result{
+			Message:    Message{Replies: []Reply{{Text: "hello"}}},
+			Usage:      Usage{InputTokens: 10, OutputTokens: 2, FinishReason: FinishedStop},
+			Logprobs:   [][]Logprob{{{Text: "hello", Logprob: -0.1}}},
+			Candidates: []Message{{Replies: []Reply{{Text: "hi"}}}},
+		}
+		buf := bytes.Buffer{}
+		if err := Encode(&buf, &in); err != nil {
+			t.Fatal(err)
+		}
+		var out Result
+		if err := Decode(&buf, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "hello" || out.Usage.InputTokens != 10 || out.Usage.FinishReason != FinishedStop {
+			t.Fatalf("unexpected %#v", out)
+		}
+		if len(out.Logprobs) != 1 || len(out.Candidates) != 1 || out.Candidates[0].String() != "hi" {
+			t.Fatalf("unexpected %#v", out)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		tests := []struct {
+			in   string
+			want string
+		}{
+			{`{"data":[]}`, "field version: required"},
+			{`{"version":2,"data":[]}`, "unsupported format version 2, max 1"},
+			{`{"version":1}`, "field data: required"},
+		}
+		for _, tt := range tests {
+			var out Messages
+			if err := Decode(strings.NewReader(tt.in), &out); err == nil || err.Error() != tt.want {
+				t.Errorf("Decode(%s) want error %q, got %v", tt.in, tt.want, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Compatibility shims for names and signatures from older releases.
+
+package v1
+
+import (
+	"context"
+
+	"github.com/maruel/genai"
+)
+
+// OptionsText is the former name of GenOptionText.
+//
+// Deprecated: use GenOptionText.
+type OptionsText = genai.GenOptionText
+
+// OptionsTools is the former name of GenOptionTools.
+//
+// Deprecated: use GenOptionTools.
+type OptionsTools = genai.GenOptionTools
+
+// ContentFragment is the former name of the streamed Reply fragment.
+//
+// Deprecated: use Reply.
+type ContentFragment = genai.Reply
+
+// GenStreamChan runs p.GenStream and sends the fragments to replies, like the former channel based
+// GenStream.
+//
+// It does not close replies. It stops sending when ctx is canceled and returns the context's error.
+//
+// Deprecated: iterate over the fragments returned by Provider.GenStream instead.
+func GenStreamChan(ctx context.Context, p Provider, msgs Messages, replies chan<- Reply, opts ...GenOption) (Result, error) {
+	fragments, finish := p.GenStream(ctx, msgs, opts...)
+	for f := range fragments {
+		select {
+		case replies <- f:
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+	res, err := finish()
+	if err == nil {
+		err = ctx.Err()
+	}
+	return res, err
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package v1 is the stable API surface of genai.
+//
+// The root genai package still evolves: new options, new Provider methods, new Reply fields. Code that only
+// needs to send messages and read replies can depend on this package instead and upgrade genai without
+// rewriting its call sites.
+//
+// The guarantees are:
+//
+//   - Provider's method set is frozen. Any genai.Provider is a v1.Provider, and Wrap converts a v1.Provider
+//     implementation back into a genai.Provider.
+//   - The types re-exported here are aliases to the genai types, so values are interchangeable with the root
+//     package. Fields and methods may be added to them but never removed or changed.
+//   - Deprecated names from older releases are kept in compat.go with their replacement.
+//
+// Incompatible changes are detected by apidiff in CI and by TestFrozen.
+package v1
+
+import (
+	"context"
+	"iter"
+	"net/http"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+	"github.com/maruel/genai/scoreboard"
+)
+
+// Provider is the frozen subset of genai.Provider.
+//
+// It excludes the methods documented as subject to change, like caching, and the asynchronous job methods.
+type Provider interface {
+	// Name returns the name of the provider.
+	Name() string
+	// ModelID returns the model currently used by the provider. It can be an empty string.
+	ModelID() string
+	// OutputModalities returns the output modalities supported by this specific client configuration.
+	OutputModalities() Modalities
+	// HTTPClient returns the underlying http client.
+	HTTPClient() *http.Client
+	// GenSync runs generation synchronously.
+	GenSync(ctx context.Context, msgs Messages, opts ...GenOption) (Result, error)
+	// GenStream runs generation synchronously, yielding the fragments of replies as the server sends them.
+	GenStream(ctx context.Context, msgs Messages, opts ...GenOption) (iter.Seq[Reply], func() (Result, error))
+	// ListModels returns the list of models the provider supports.
+	ListModels(ctx context.Context) ([]Model, error)
+}
+
+// Wrap returns a genai.Provider backed by p.
+//
+// If p already is a genai.Provider, it is returned as-is. Otherwise the methods outside of the v1 surface
+// return base.ErrNotSupported and Scoreboard returns an empty score.
+func Wrap(p Provider) genai.Provider {
+	if g, ok := p.(genai.Provider); ok {
+		return g
+	}
+	return &provider{p: p}
+}
+
+// Type aliases to the stable genai types.
+type (
+	// Doc is an alias to genai.Doc.
+	Doc = genai.Doc
+	// FinishReason is an alias to genai.FinishReason.
+	FinishReason = genai.FinishReason
+	// GenOption is an alias to genai.GenOption.
+	GenOption = genai.GenOption
+	// GenOptionText is an alias to genai.GenOptionText.
+	GenOptionText = genai.GenOptionText
+	// GenOptionTools is an alias to genai.GenOptionTools.
+	GenOptionTools = genai.GenOptionTools
+	// Message is an alias to genai.Message.
+	Message = genai.Message
+	// Messages is an alias to genai.Messages.
+	Messages = genai.Messages
+	// Modalities is an alias to genai.Modalities.
+	Modalities = genai.Modalities
+	// Modality is an alias to genai.Modality.
+	Modality = genai.Modality
+	// Model is an alias to genai.Model.
+	Model = genai.Model
+	// ProviderOption is an alias to genai.ProviderOption.
+	ProviderOption = genai.ProviderOption
+	// Reply is an alias to genai.Reply.
+	Reply = genai.Reply
+	// Request is an alias to genai.Request.
+	Request = genai.Request
+	// Result is an alias to genai.Result.
+	Result = genai.Result
+	// ToolCall is an alias to genai.ToolCall.
+	ToolCall = genai.ToolCall
+	// ToolCallRequest is an alias to genai.ToolCallRequest.
+	ToolCallRequest = genai.ToolCallRequest
+	// ToolCallResult is an alias to genai.ToolCallResult.
+	ToolCallResult = genai.ToolCallResult
+	// ToolDef is an alias to genai.ToolDef.
+	ToolDef = genai.ToolDef
+	// Usage is an alias to genai.Usage.
+	Usage = genai.Usage
+)
+
+// Constants re-exported from genai.
+const (
+	ModalityAudio    = genai.ModalityAudio
+	ModalityDocument = genai.ModalityDocument
+	ModalityImage    = genai.ModalityImage
+	ModalityText     = genai.ModalityText
+	ModalityVideo    = genai.ModalityVideo
+
+	FinishedStop          = genai.FinishedStop
+	FinishedLength        = genai.FinishedLength
+	FinishedToolCalls     = genai.FinishedToolCalls
+	FinishedStopSequence  = genai.FinishedStopSequence
+	FinishedContentFilter = genai.FinishedContentFilter
+
+	ToolCallAny      = genai.ToolCallAny
+	ToolCallRequired = genai.ToolCallRequired
+	ToolCallNone     = genai.ToolCallNone
+)
+
+// NewTextMessage is genai.NewTextMessage.
+func NewTextMessage(text string) Message {
+	return genai.NewTextMessage(text)
+}
+
+//
+
+// Fails to compile if genai.Provider stops implementing the frozen surface.
+var _ Provider = genai.Provider(nil)
+
+// provider adapts a Provider to genai.Provider.
+type provider struct {
+	base.NotImplemented
+	p Provider
+}
+
+func (p *provider) Name() string {
+	return p.p.Name()
+}
+
+func (p *provider) ModelID() string {
+	return p.p.ModelID()
+}
+
+func (p *provider) OutputModalities() genai.Modalities {
+	return p.p.OutputModalities()
+}
+
+func (p *provider) HTTPClient() *http.Client {
+	return p.p.HTTPClient()
+}
+
+func (p *provider) Scoreboard() scoreboard.Score {
+	return scoreboard.Score{}
+}
+
+func (p *provider) GenSync(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (genai.Result, error) {
+	return p.p.GenSync(ctx, msgs, opts...)
+}
+
+func (p *provider) GenStream(ctx context.Context, msgs genai.Messages, opts ...genai.GenOption) (iter.Seq[genai.Reply], func() (genai.Result, error)) {
+	return p.p.GenStream(ctx, msgs, opts...)
+}
+
+func (p *provider) ListModels(ctx context.Context) ([]genai.Model, error) {
+	return p.p.ListModels(ctx)
+}
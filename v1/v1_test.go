@@ -0,0 +1,273 @@
+// Copyright 2026 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Tests for the stable API surface.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maruel/genai"
+	"github.com/maruel/genai/base"
+)
+
+// TestFrozen fails when a field or method of the v1 surface is removed or changed. Additions are fine; add
+// them to frozen when they become part of the stable surface.
+func TestFrozen(t *testing.T) {
+	got := surface()
+	for _, l := range strings.Split(strings.TrimSpace(frozen), "\n") {
+		if !slices.Contains(got, l) {
+			t.Errorf("incompatible change, missing %q", l)
+		}
+	}
+	if t.Failed() {
+		t.Logf("current surface:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestWrap(t *testing.T) {
+	m := &mockProvider{}
+	p := Wrap(m)
+	if p.Name() != "mock" || p.ModelID() != "llm" {
+		t.Fatalf("unexpected %q %q", p.Name(), p.ModelID())
+	}
+	res, err := p.GenSync(t.Context(), Messages{NewTextMessage("hi")})
+	if err != nil || res.String() != "hello" {
+		t.Fatalf("unexpected %q, %v", res.String(), err)
+	}
+	if _, err := p.GenAsync(t.Context(), Messages{NewTextMessage("hi")}); err == nil {
+		t.Fatal("expected error")
+	} else if _, ok := errors.AsType[*base.ErrNotSupported](err); !ok {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var g genai.Provider = p
+	if Wrap(g) != g {
+		t.Fatal("expected genai.Provider to be returned as-is")
+	}
+}
+
+func TestGenStreamChan(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		ch := make(chan Reply, 10)
+		res, err := GenStreamChan(t.Context(), &mockProvider{}, Messages{NewTextMessage("hi")}, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		close(ch)
+		var got []string
+		for r := range ch {
+			got = append(got, r.Text)
+		}
+		if !slices.Equal(got, []string{"hel", "lo"}) || res.String() != "hello" {
+			t.Fatalf("unexpected %q, %q", got, res.String())
+		}
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		if _, err := GenStreamChan(ctx, &mockProvider{}, Messages{NewTextMessage("hi")}, make(chan Reply)); !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
+
+// surface returns the exported fields and methods of the aliased types.
+func surface() []string {
+	var out []string
+	for _, v := range []any{
+		Doc{}, FinishReason(""), GenOptionText{}, GenOptionTools{}, Message{}, Messages{}, Modalities{},
+		Modality(""), Reply{}, Request{}, Result{}, ToolCall{}, ToolCallRequest(0), ToolCallResult{},
+		ToolDef{}, Usage{},
+		(*GenOption)(nil), (*Model)(nil), (*ProviderOption)(nil), (*Provider)(nil),
+	} {
+		tp := reflect.TypeOf(v)
+		if tp.Kind() == reflect.Pointer && tp.Elem().Kind() == reflect.Interface {
+			tp = tp.Elem()
+			for i := range tp.NumMethod() {
+				m := tp.Method(i)
+				out = append(out, fmt.Sprintf("%s.%s %s", tp, m.Name, m.Type))
+			}
+			continue
+		}
+		if tp.Kind() == reflect.Struct {
+			for _, f := range reflect.VisibleFields(tp) {
+				if f.IsExported() && len(f.Index) == 1 {
+					out = append(out, fmt.Sprintf("%s.%s %s", tp, f.Name, f.Type))
+				}
+			}
+		}
+		// The pointer's method set includes the value's.
+		x := reflect.PointerTo(tp)
+		for i := range x.NumMethod() {
+			m := x.Method(i)
+			out = append(out, fmt.Sprintf("%s.%s %s", x, m.Name, m.Type))
+		}
+	}
+	return out
+}
+
+type mockProvider struct{}
+
+func (m *mockProvider) Name() string                 { return "mock" }
+func (m *mockProvider) ModelID() string              { return "llm" }
+func (m *mockProvider) OutputModalities() Modalities { return Modalities{ModalityText} }
+func (m *mockProvider) HTTPClient() *http.Client     { return nil }
+
+func (m *mockProvider) GenSync(ctx context.Context, msgs Messages, opts ...GenOption) (Result, error) {
+	return Result{Message: Message{Replies: []Reply{{Text: "hello"}}}}, nil
+}
+
+func (m *mockProvider) GenStream(ctx context.Context, msgs Messages, opts ...GenOption) (iter.Seq[Reply], func() (Result, error)) {
+	return func(yield func(Reply) bool) {
+			for _, s := range []string{"hel", "lo"} {
+				if !yield(Reply{Text: s}) {
+					return
+				}
+			}
+		}, func() (Result, error) {
+			return m.GenSync(ctx, msgs, opts...)
+		}
+}
+
+func (m *mockProvider) ListModels(ctx context.Context) ([]Model, error) {
+	return nil, nil
+}
+
+// frozen is the v1 surface.
+const frozen = `
+genai.Doc.Filename string
+genai.Doc.Src io.ReadSeeker
+genai.Doc.URL string
+genai.Doc.Detail genai.ImageDetail
+*genai.Doc.GetFilename func(*genai.Doc) string
+*genai.Doc.IsZero func(*genai.Doc) bool
+*genai.Doc.MarshalJSON func(*genai.Doc) ([]uint8, error)
+*genai.Doc.Read func(*genai.Doc, int64) (string, []uint8, error)
+*genai.Doc.UnmarshalJSON func(*genai.Doc, []uint8) error
+*genai.Doc.Validate func(*genai.Doc) error
+genai.GenOptionText.Temperature float64
+genai.GenOptionText.TopP float64
+genai.GenOptionText.MaxTokens int64
+genai.GenOptionText.TopLogprobs int64
+genai.GenOptionText.SystemPrompt string
+genai.GenOptionText.TopK int64
+genai.GenOptionText.Stop []string
+genai.GenOptionText.ReplyAsJSON bool
+genai.GenOptionText.DecodeAs interface {}
+*genai.GenOptionText.DecodeSchema func(*genai.GenOptionText) (jsontext.Value, error)
+*genai.GenOptionText.Validate func(*genai.GenOptionText) error
+genai.GenOptionTools.Tools []genai.ToolDef
+genai.GenOptionTools.Force genai.ToolCallRequest
+genai.GenOptionTools.Concurrency int
+genai.GenOptionTools.Allow []string
+genai.GenOptionTools.Deny []string
+*genai.GenOptionTools.IsAllowed func(*genai.GenOptionTools, string) bool
+*genai.GenOptionTools.Validate func(*genai.GenOptionTools) error
+genai.Message.Requests []genai.Request
+genai.Message.User string
+genai.Message.Replies []genai.Reply
+genai.Message.ToolCallResults []genai.ToolCallResult
+*genai.Message.Accumulate func(*genai.Message, *genai.Reply) error
+*genai.Message.Decode func(*genai.Message, interface {}) error
+*genai.Message.DoToolCalls func(*genai.Message, context.Context, []genai.ToolDef) (genai.Message, error)
+*genai.Message.DoToolCallsConcurrently func(*genai.Message, context.Context, []genai.ToolDef, int) (genai.Message, error)
+*genai.Message.FetchDocs func(*genai.Message, context.Context, *http.Client, int64) error
+*genai.Message.GoString func(*genai.Message) string
+*genai.Message.IsZero func(*genai.Message) bool
+*genai.Message.Reasoning func(*genai.Message) string
+*genai.Message.Role func(*genai.Message) string
+*genai.Message.SaveDocs func(*genai.Message, context.Context, *http.Client, string, string) ([]string, error)
+*genai.Message.String func(*genai.Message) string
+*genai.Message.UnmarshalJSON func(*genai.Message, []uint8) error
+*genai.Message.Validate func(*genai.Message) error
+*genai.Messages.Validate func(*genai.Messages) error
+*genai.Modalities.String func(*genai.Modalities) string
+*genai.Modalities.Validate func(*genai.Modalities) error
+*scoreboard.Modality.Validate func(*scoreboard.Modality) error
+genai.Reply.Text string
+genai.Reply.Doc genai.Doc
+genai.Reply.Citation genai.Citation
+genai.Reply.Reasoning string
+genai.Reply.ToolCall genai.ToolCall
+genai.Reply.Opaque map[string]interface {}
+genai.Reply.Index int64
+*genai.Reply.GoString func(*genai.Reply) string
+*genai.Reply.IsZero func(*genai.Reply) bool
+*genai.Reply.UnmarshalJSON func(*genai.Reply, []uint8) error
+*genai.Reply.Validate func(*genai.Reply) error
+genai.Request.Text string
+genai.Request.Doc genai.Doc
+*genai.Request.UnmarshalJSON func(*genai.Request, []uint8) error
+*genai.Request.Validate func(*genai.Request) error
+genai.Result.Message genai.Message
+genai.Result.Usage genai.Usage
+genai.Result.Logprobs [][]genai.Logprob
+*genai.Result.Accumulate func(*genai.Result, *genai.Reply) error
+*genai.Result.Decode func(*genai.Result, interface {}) error
+*genai.Result.DoToolCalls func(*genai.Result, context.Context, []genai.ToolDef) (genai.Message, error)
+*genai.Result.DoToolCallsConcurrently func(*genai.Result, context.Context, []genai.ToolDef, int) (genai.Message, error)
+*genai.Result.FetchDocs func(*genai.Result, context.Context, *http.Client, int64) error
+*genai.Result.GoString func(*genai.Result) string
+*genai.Result.IsZero func(*genai.Result) bool
+*genai.Result.Reasoning func(*genai.Result) string
+*genai.Result.Role func(*genai.Result) string
+*genai.Result.SaveDocs func(*genai.Result, context.Context, *http.Client, string, string) ([]string, error)
+*genai.Result.String func(*genai.Result) string
+*genai.Result.UnmarshalJSON func(*genai.Result, []uint8) error
+*genai.Result.Validate func(*genai.Result) error
+genai.ToolCall.ID string
+genai.ToolCall.Name string
+genai.ToolCall.Arguments string
+genai.ToolCall.Opaque map[string]interface {}
+*genai.ToolCall.Call func(*genai.ToolCall, context.Context, []genai.ToolDef) (string, error)
+*genai.ToolCall.IsZero func(*genai.ToolCall) bool
+*genai.ToolCall.UnmarshalJSON func(*genai.ToolCall, []uint8) error
+*genai.ToolCall.Validate func(*genai.ToolCall) error
+genai.ToolCallResult.ID string
+genai.ToolCallResult.Name string
+genai.ToolCallResult.Result string
+*genai.ToolCallResult.UnmarshalJSON func(*genai.ToolCallResult, []uint8) error
+*genai.ToolCallResult.Validate func(*genai.ToolCallResult) error
+genai.ToolDef.Name string
+genai.ToolDef.Description string
+genai.ToolDef.Callback interface {}
+genai.ToolDef.InputSchemaOverride jsontext.Value
+genai.ToolDef.Timeout time.Duration
+genai.ToolDef.MaxResultBytes int
+*genai.ToolDef.GetInputSchema func(*genai.ToolDef) (jsontext.Value, error)
+*genai.ToolDef.Validate func(*genai.ToolDef) error
+genai.Usage.InputTokens int64
+genai.Usage.InputCachedTokens int64
+genai.Usage.ReasoningTokens int64
+genai.Usage.OutputTokens int64
+genai.Usage.TotalTokens int64
+genai.Usage.FinishReason genai.FinishReason
+genai.Usage.ServiceTier string
+genai.Usage.WebSearchRequests int64
+genai.Usage.WebFetchRequests int64
+genai.Usage.Limits []genai.RateLimit
+*genai.Usage.Add func(*genai.Usage, *genai.Usage)
+*genai.Usage.String func(*genai.Usage) string
+genai.GenOption.Validate func() error
+genai.Model.Context func() int64
+genai.Model.GetID func() string
+genai.Model.String func() string
+genai.ProviderOption.Validate func() error
+v1.Provider.GenStream func(context.Context, genai.Messages, ...genai.GenOption) (iter.Seq[github.com/maruel/genai.Reply], func() (genai.Result, error))
+v1.Provider.GenSync func(context.Context, genai.Messages, ...genai.GenOption) (genai.Result, error)
+v1.Provider.HTTPClient func() *http.Client
+v1.Provider.ListModels func(context.Context) ([]genai.Model, error)
+v1.Provider.ModelID func() string
+v1.Provider.Name func() string
+v1.Provider.OutputModalities func() genai.Modalities
+`